@@ -0,0 +1,269 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/exit"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/schemadiff"
+	"vitess.io/vitess/go/vt/servenv"
+
+	// Import and register the gRPC vtctldclient client
+	_ "vitess.io/vitess/go/vt/vtctl/grpcvtctldclient"
+)
+
+/*
+  schemadiff loads two schemas, and prints the DDL statements which
+  transition the first schema into the second. A schema is loaded from a
+  SQL file, from the *.sql files of a directory, from a database of a
+  running mysqld, or from a keyspace or a tablet through vtctld.
+
+  schemadiff exits with 0 when the schemas are identical, with 1 when
+  they differ, and with 2 on errors, so that it can be used in pipelines.
+
+  For instance, to diff the checked-in schema of the commerce keyspace
+  against the live keyspace:
+
+  schemadiff \
+        --column-rename-strategy heuristic \
+        --json \
+        vtctld://vtctld-host.my.domain:15999/commerce \
+        schema/commerce/
+*/
+
+const (
+	exitEqual = 0
+	exitDiff  = 1
+	exitError = 2
+)
+
+var (
+	strictIndexOrdering     bool
+	autoIncrementStrategy   = "ignore"
+	rangeRotationStrategy   = "full-spec"
+	constraintNamesStrategy = "ignore-vitess"
+	columnRenameStrategy    = "assume-different"
+	tableRenameStrategy     = "assume-different"
+	fullTextKeyStrategy     = "distinct-statements"
+	jsonOutput              bool
+	planOutput              bool
+	validate                bool
+)
+
+var (
+	autoIncrementStrategies = map[string]int{
+		"ignore":       schemadiff.AutoIncrementIgnore,
+		"apply-higher": schemadiff.AutoIncrementApplyHigher,
+		"apply-always": schemadiff.AutoIncrementApplyAlways,
+	}
+	rangeRotationStrategies = map[string]int{
+		"full-spec":           schemadiff.RangeRotationFullSpec,
+		"distinct-statements": schemadiff.RangeRotationDistinctStatements,
+		"ignore":              schemadiff.RangeRotationIgnore,
+	}
+	constraintNamesStrategies = map[string]int{
+		"ignore-vitess": schemadiff.ConstraintNamesIgnoreVitess,
+		"ignore-all":    schemadiff.ConstraintNamesIgnoreAll,
+		"strict":        schemadiff.ConstraintNamesStrict,
+	}
+	columnRenameStrategies = map[string]int{
+		"assume-different": schemadiff.ColumnRenameAssumeDifferent,
+		"heuristic":        schemadiff.ColumnRenameHeuristicStatement,
+	}
+	tableRenameStrategies = map[string]int{
+		"assume-different": schemadiff.TableRenameAssumeDifferent,
+		"heuristic":        schemadiff.TableRenameHeuristicStatement,
+	}
+	fullTextKeyStrategies = map[string]int{
+		"distinct-statements": schemadiff.FullTextKeyDistinctStatements,
+		"unify-statements":    schemadiff.FullTextKeyUnifyStatements,
+	}
+)
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&strictIndexOrdering, "strict-index-ordering", strictIndexOrdering, "diff the order of the indexes of tables")
+	fs.StringVar(&autoIncrementStrategy, "auto-increment-strategy", autoIncrementStrategy, strategyUsage("how to diff the AUTO_INCREMENT values of tables", autoIncrementStrategies))
+	fs.StringVar(&rangeRotationStrategy, "range-rotation-strategy", rangeRotationStrategy, strategyUsage("how to diff the partitions of tables partitioned by range", rangeRotationStrategies))
+	fs.StringVar(&constraintNamesStrategy, "constraint-names-strategy", constraintNamesStrategy, strategyUsage("how to diff the names of CHECK and FOREIGN KEY constraints", constraintNamesStrategies))
+	fs.StringVar(&columnRenameStrategy, "column-rename-strategy", columnRenameStrategy, strategyUsage("whether to diff a dropped and an added column as a column rename", columnRenameStrategies))
+	fs.StringVar(&tableRenameStrategy, "table-rename-strategy", tableRenameStrategy, strategyUsage("whether to diff a dropped and a created table as a table rename", tableRenameStrategies))
+	fs.StringVar(&fullTextKeyStrategy, "fulltext-key-strategy", fullTextKeyStrategy, strategyUsage("how to diff the addition of multiple FULLTEXT keys", fullTextKeyStrategies))
+	fs.BoolVar(&jsonOutput, "json", jsonOutput, "print the diff as JSON")
+	fs.BoolVar(&planOutput, "plan", planOutput, "order the diff into the steps of a migration plan, by the dependencies between tables, views, triggers and foreign keys")
+	fs.BoolVar(&validate, "validate", validate, "validate both schemas before diffing them, e.g. that views and keys only use existing columns")
+}
+
+func init() {
+	servenv.OnParseFor("schemadiff", registerFlags)
+}
+
+// strategyUsage returns the usage of a strategy flag, along with the possible strategies.
+func strategyUsage(usage string, strategies map[string]int) string {
+	return fmt.Sprintf("%s (%s)", usage, strings.Join(strategyNames(strategies), ", "))
+}
+
+// strategyNames returns the sorted names of the given strategies.
+func strategyNames(strategies map[string]int) []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func main() {
+	defer exit.RecoverAll()
+	defer logutil.Flush()
+
+	args := servenv.ParseFlagsWithArgs("schemadiff")
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: schemadiff [flags] <source> <target>\n")
+		exit.Return(exitError)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	equal, err := run(ctx, os.Stdout, args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "schemadiff failed: %v\n", err)
+		exit.Return(exitError)
+	}
+	if !equal {
+		exit.Return(exitDiff)
+	}
+}
+
+// newDiffHints builds the diff hints from the flags.
+func newDiffHints() (*schemadiff.DiffHints, error) {
+	hints := &schemadiff.DiffHints{StrictIndexOrdering: strictIndexOrdering}
+	strategies := []struct {
+		flag       string
+		value      string
+		strategies map[string]int
+		hint       *int
+	}{
+		{flag: "auto-increment-strategy", value: autoIncrementStrategy, strategies: autoIncrementStrategies, hint: &hints.AutoIncrementStrategy},
+		{flag: "range-rotation-strategy", value: rangeRotationStrategy, strategies: rangeRotationStrategies, hint: &hints.RangeRotationStrategy},
+		{flag: "constraint-names-strategy", value: constraintNamesStrategy, strategies: constraintNamesStrategies, hint: &hints.ConstraintNamesStrategy},
+		{flag: "column-rename-strategy", value: columnRenameStrategy, strategies: columnRenameStrategies, hint: &hints.ColumnRenameStrategy},
+		{flag: "table-rename-strategy", value: tableRenameStrategy, strategies: tableRenameStrategies, hint: &hints.TableRenameStrategy},
+		{flag: "fulltext-key-strategy", value: fullTextKeyStrategy, strategies: fullTextKeyStrategies, hint: &hints.FullTextKeyStrategy},
+	}
+	for _, s := range strategies {
+		strategy, ok := s.strategies[s.value]
+		if !ok {
+			return nil, fmt.Errorf("invalid --%s %q, expected one of: %s", s.flag, s.value, strings.Join(strategyNames(s.strategies), ", "))
+		}
+		*s.hint = strategy
+	}
+	return hints, nil
+}
+
+// diffResult is the JSON output of schemadiff.
+type diffResult struct {
+	Equal bool       `json:"equal"`
+	Diffs []string   `json:"diffs"`
+	Steps [][]string `json:"steps,omitempty"`
+}
+
+// run diffs the source schema against the target schema, prints the diff, and returns true when the schemas are identical.
+func run(ctx context.Context, w io.Writer, source string, target string) (equal bool, err error) {
+	hints, err := newDiffHints()
+	if err != nil {
+		return false, err
+	}
+	sourceSchema, err := loadSchema(ctx, source)
+	if err != nil {
+		return false, fmt.Errorf("cannot load schema from %v: %w", source, err)
+	}
+	targetSchema, err := loadSchema(ctx, target)
+	if err != nil {
+		return false, fmt.Errorf("cannot load schema from %v: %w", target, err)
+	}
+	if validate {
+		if err := sourceSchema.Validate(); err != nil {
+			return false, fmt.Errorf("%v: %w", source, err)
+		}
+		if err := targetSchema.Validate(); err != nil {
+			return false, fmt.Errorf("%v: %w", target, err)
+		}
+	}
+
+	result := &diffResult{Diffs: []string{}}
+	if planOutput {
+		plan, err := sourceSchema.MigrationPlan(targetSchema, hints)
+		if err != nil {
+			return false, err
+		}
+		for _, step := range plan.Steps {
+			statements := make([]string, 0, len(step.Diffs))
+			for _, diff := range step.Diffs {
+				statements = append(statements, diff.CanonicalStatementString())
+			}
+			result.Steps = append(result.Steps, statements)
+			result.Diffs = append(result.Diffs, statements...)
+		}
+	} else {
+		diffs, err := sourceSchema.Diff(targetSchema, hints)
+		if err != nil {
+			return false, err
+		}
+		for _, diff := range diffs {
+			result.Diffs = append(result.Diffs, diff.CanonicalStatementString())
+		}
+	}
+	result.Equal = len(result.Diffs) == 0
+
+	if jsonOutput {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return result.Equal, enc.Encode(result)
+	}
+	if len(result.Steps) > 0 {
+		for i, step := range result.Steps {
+			if _, err := fmt.Fprintf(w, "-- step %d\n", i+1); err != nil {
+				return false, err
+			}
+			for _, statement := range step {
+				if _, err := fmt.Fprintf(w, "%s;\n", statement); err != nil {
+					return false, err
+				}
+			}
+		}
+		return result.Equal, nil
+	}
+	for _, statement := range result.Diffs {
+		if _, err := fmt.Fprintf(w, "%s;\n", statement); err != nil {
+			return false, err
+		}
+	}
+	return result.Equal, nil
+}
@@ -54,6 +54,15 @@ The command-line parameters to vtbackup specify a policy for when a new backup
 is needed, and when old backups should be removed. If the existing backups
 already satisfy the policy, then vtbackup will do nothing and return success
 immediately.
+
+With --verify-backup, vtbackup neither takes nor prunes backups. Instead it
+checks that the most recent complete backup can be restored:
+ 1. Read every file of the backup, and check it against the hash and size
+    recorded in the MANIFEST.
+ 2. With --verify-backup-restore, also restore the backup into a scratch mysqld
+    and run CHECK TABLE on every table.
+
+vtbackup exits with a non-zero code if the backup is not intact.
 */
 package main
 
@@ -106,6 +115,8 @@ var (
 	initialBackup       bool
 	allowFirstBackup    bool
 	restartBeforeBackup bool
+	verifyBackup        bool
+	verifyBackupRestore bool
 	// vttablet-like flags
 	initDbNameOverride string
 	initKeyspace       string
@@ -129,6 +140,8 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&initialBackup, "initial_backup", initialBackup, "Instead of restoring from backup, initialize an empty database with the provided init_db_sql_file and upload a backup of that for the shard, if the shard has no backups yet. This can be used to seed a brand new shard with an initial, empty backup. If any backups already exist for the shard, this will be considered a successful no-op. This can only be done before the shard exists in topology (i.e. before any tablets are deployed).")
 	fs.BoolVar(&allowFirstBackup, "allow_first_backup", allowFirstBackup, "Allow this job to take the first backup of an existing shard.")
 	fs.BoolVar(&restartBeforeBackup, "restart_before_backup", restartBeforeBackup, "Perform a mysqld clean/full restart after applying binlogs, but before taking the backup. Only makes sense to work around xtrabackup bugs.")
+	fs.BoolVar(&verifyBackup, "verify-backup", verifyBackup, "Instead of taking a new backup and pruning old ones, verify that the most recent complete backup is intact by reading all its files and checking them against its MANIFEST.")
+	fs.BoolVar(&verifyBackupRestore, "verify-backup-restore", verifyBackupRestore, "With --verify-backup, also restore the backup into a temporary mysqld and run CHECK TABLE on all its tables.")
 	// vttablet-like flags
 	fs.StringVar(&initDbNameOverride, "init_db_name_override", initDbNameOverride, "(init parameter) override the name of the db used by vttablet")
 	fs.StringVar(&initKeyspace, "init_keyspace", initKeyspace, "(init parameter) keyspace to use for this tablet")
@@ -195,10 +208,19 @@ func main() {
 	topoServer := topo.Open()
 	defer topoServer.Close()
 
+	backupDir := mysqlctl.GetBackupDir(initKeyspace, initShard)
+	if verifyBackup {
+		if err := verifyLastBackup(ctx, backupStorage, backupDir); err != nil {
+			log.Errorf("Failed to verify backup: %v", err)
+			exit.Return(1)
+		}
+		log.Info("Exiting.")
+		return
+	}
+
 	// Try to take a backup, if it's been long enough since the last one.
 	// Skip pruning if backup wasn't fully successful. We don't want to be
 	// deleting things if the backup process is not healthy.
-	backupDir := mysqlctl.GetBackupDir(initKeyspace, initShard)
 	doBackup, err := shouldBackup(ctx, topoServer, backupStorage, backupDir)
 	if err != nil {
 		log.Errorf("Can't take backup: %v", err)
@@ -483,6 +505,132 @@ func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage back
 	return nil
 }
 
+func verifyLastBackup(ctx context.Context, backupStorage backupstorage.BackupStorage, backupDir string) error {
+	backups, err := backupStorage.ListBackups(ctx, backupDir)
+	if err != nil {
+		return fmt.Errorf("can't list backups: %v", err)
+	}
+	backup := lastCompleteBackup(ctx, backups)
+	if backup == nil {
+		return fmt.Errorf("no complete backups to verify in %v", backupDir)
+	}
+	backupTime, err := parseBackupTime(backup.Name())
+	if err != nil {
+		return err
+	}
+
+	// As in takeBackup, use an imaginary tablet alias with a random UID, so
+	// the temporary data dir is unique.
+	bigN, err := rand.Int(rand.Reader, big.NewInt(math.MaxUint32))
+	if err != nil {
+		return fmt.Errorf("can't generate random tablet UID: %v", err)
+	}
+	tabletAlias := &topodatapb.TabletAlias{
+		Cell: "vtbackup",
+		Uid:  uint32(bigN.Uint64()),
+	}
+	extraEnv := map[string]string{
+		"TABLET_ALIAS": topoproto.TabletAliasString(tabletAlias),
+	}
+
+	verifyParams := mysqlctl.VerifyParams{
+		Logger:       logutil.NewConsoleLogger(),
+		Concurrency:  concurrency,
+		HookExtraEnv: extraEnv,
+	}
+	if _, err := mysqlctl.VerifyBackup(ctx, verifyParams, backup); err != nil {
+		return fmt.Errorf("backup %v is not intact: %v", backup.Name(), err)
+	}
+	if !verifyBackupRestore {
+		log.Infof("Backup %v is intact.", backup.Name())
+		return nil
+	}
+
+	// Restore the backup into a scratch mysqld, which is removed when we are
+	// done.
+	tabletDir := mysqlctl.TabletDir(tabletAlias.Uid)
+	defer func() {
+		log.Infof("Removing temporary tablet directory: %v", tabletDir)
+		if err := os.RemoveAll(tabletDir); err != nil {
+			log.Warningf("Failed to remove temporary tablet directory: %v", err)
+		}
+	}()
+
+	mysqld, mycnf, err := mysqlctl.CreateMysqldAndMycnf(tabletAlias.Uid, mysqlSocket, int32(mysqlPort))
+	if err != nil {
+		return fmt.Errorf("failed to initialize mysql config: %v", err)
+	}
+	initCtx, initCancel := context.WithTimeout(ctx, mysqlTimeout)
+	defer initCancel()
+	if err := mysqld.Init(initCtx, mycnf, initDBSQLFile); err != nil {
+		return fmt.Errorf("failed to initialize mysql data dir and start mysqld: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := mysqld.Shutdown(ctx, mycnf, false); err != nil {
+			log.Errorf("failed to shutdown mysqld: %v", err)
+		}
+	}()
+
+	dbName := initDbNameOverride
+	if dbName == "" {
+		dbName = fmt.Sprintf("vt_%s", initKeyspace)
+	}
+	log.Infof("Restoring backup %v from directory %v", backup.Name(), backupDir)
+	params := mysqlctl.RestoreParams{
+		Cnf:                 mycnf,
+		Mysqld:              mysqld,
+		Logger:              logutil.NewConsoleLogger(),
+		Concurrency:         concurrency,
+		HookExtraEnv:        extraEnv,
+		LocalMetadata:       map[string]string{},
+		DeleteBeforeRestore: true,
+		DbName:              dbName,
+		Keyspace:            initKeyspace,
+		Shard:               initShard,
+		StartTime:           backupTime,
+	}
+	if _, err := mysqlctl.Restore(ctx, params); err != nil {
+		return fmt.Errorf("can't restore backup %v: %v", backup.Name(), err)
+	}
+
+	if err := checkTables(ctx, mysqld); err != nil {
+		return fmt.Errorf("backup %v restored, but its tables are not intact: %v", backup.Name(), err)
+	}
+	log.Infof("Backup %v is intact, and was restored successfully.", backup.Name())
+	return nil
+}
+
+// checkTables runs CHECK TABLE on all the tables of the restored databases,
+// and returns an error listing the tables which are not OK.
+func checkTables(ctx context.Context, mysqld mysqlctl.MysqlDaemon) error {
+	qr, err := mysqld.FetchSuperQuery(ctx, "SELECT table_schema, table_name FROM information_schema.tables WHERE table_type = 'BASE TABLE' AND table_schema NOT IN ('mysql', 'sys', 'information_schema', 'performance_schema')")
+	if err != nil {
+		return fmt.Errorf("can't list tables: %v", err)
+	}
+	var failed []string
+	for _, row := range qr.Rows {
+		table := fmt.Sprintf("%s.%s", sqlescape.EscapeID(row[0].ToString()), sqlescape.EscapeID(row[1].ToString()))
+		result, err := mysqld.FetchSuperQuery(ctx, "CHECK TABLE "+table)
+		if err != nil {
+			return fmt.Errorf("can't check table %v: %v", table, err)
+		}
+		// The result has the Table, Op, Msg_type and Msg_text columns.
+		for _, r := range result.Rows {
+			msgType, msgText := r[2].ToString(), r[3].ToString()
+			if msgType == "error" || (msgType == "status" && msgText != "OK") {
+				failed = append(failed, fmt.Sprintf("%v: %v", table, msgText))
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("CHECK TABLE failed for %d tables: %v", len(failed), strings.Join(failed, "; "))
+	}
+	log.Infof("CHECK TABLE succeeded for %d tables.", len(qr.Rows))
+	return nil
+}
+
 func resetReplication(ctx context.Context, pos mysql.Position, mysqld mysqlctl.MysqlDaemon) error {
 	cmds := []string{
 		"STOP SLAVE",
@@ -591,7 +739,7 @@ func pruneBackups(ctx context.Context, backupStorage backupstorage.BackupStorage
 	numBackups := len(backups)
 	if numBackups <= minRetentionCount {
 		log.Infof("Found %v backups. Not pruning any since this is within the min_retention_count of %v.", numBackups, minRetentionCount)
-		return nil
+		return pruneBackupChunks(ctx, backupStorage, backupDir)
 	}
 	// We have more than the minimum retention count, so we could afford to
 	// prune some. See if any are beyond the minimum retention time.
@@ -618,6 +766,15 @@ func pruneBackups(ctx context.Context, backupStorage backupstorage.BackupStorage
 			break
 		}
 	}
+	return pruneBackupChunks(ctx, backupStorage, backupDir)
+}
+
+// pruneBackupChunks removes the chunks of deduplicated backups which are no
+// longer referenced by any remaining backup.
+func pruneBackupChunks(ctx context.Context, backupStorage backupstorage.BackupStorage, backupDir string) error {
+	if err := mysqlctl.PruneBackupChunks(ctx, logutil.NewConsoleLogger(), backupStorage, backupDir); err != nil {
+		return fmt.Errorf("couldn't prune backup chunks: %v", err)
+	}
 	return nil
 }
 
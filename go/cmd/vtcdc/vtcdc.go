@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/exit"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtcdc"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+
+	// Import and register the gRPC vtgateconn client
+	_ "vitess.io/vitess/go/vt/vtgate/grpcvtgateconn"
+)
+
+/*
+  vtcdc streams the changes of a set of tables from the VStream API of
+  vtgate, and writes them as newline-delimited JSON to rotated files or
+  to stdout. The VGtid of the written events is checkpointed after each
+  flushed batch, and the stream resumes from the checkpoint when vtcdc
+  is restarted, including after a reshard.
+
+  For instance, to copy and then stream the customer and corder tables:
+
+  vtcdc \
+        --server vtgate-host.my.domain:15991 \
+        --keyspace customer \
+        --rule customer \
+        --rule 'corder=select order_id, customer_id, price from corder' \
+        --format debezium \
+        --output_dir /var/lib/vtcdc/customer \
+        --checkpoint_file /var/lib/vtcdc/customer.checkpoint
+*/
+
+var (
+	server         string
+	tabletType     = "primary"
+	keyspace       string
+	shards         []string
+	position       string
+	rules          []string
+	minimizeSkew   bool
+	heartbeat      time.Duration
+	stopOnReshard  bool
+	cells          string
+	checkpointFile string
+	format         = vtcdc.FormatJSON
+	name           = "vtcdc"
+	outputDir      string
+	maxFileSize    int64 = 128 * 1024 * 1024
+	maxFileAge           = time.Hour
+	retryDelay           = 5 * time.Second
+)
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&server, "server", server, "vtgate server to connect to")
+	fs.StringVar(&tabletType, "tablet_type", tabletType, "the type of the tablets to stream from")
+	fs.StringVar(&keyspace, "keyspace", keyspace, "the keyspace to stream from, when there is no checkpoint")
+	fs.StringSliceVar(&shards, "shards", shards, "the shards to stream from, when there is no checkpoint (default all the shards of the keyspace)")
+	fs.StringVar(&position, "position", position, "where to start streaming when there is no checkpoint: empty to copy the tables first, 'current' to only stream the new changes, or a GTID position")
+	fs.StringArrayVar(&rules, "rule", rules, "a filter rule, as '<table or /regexp>' or '<table>=<select statement>'; can be repeated (default all the tables)")
+	fs.BoolVar(&minimizeSkew, "minimize_skew", minimizeSkew, "align the streams of the shards on their event timestamps")
+	fs.DurationVar(&heartbeat, "heartbeat_interval", heartbeat, "how often vtgate sends heartbeats when the stream is idle, rounded to seconds (0 for the vtgate default)")
+	fs.BoolVar(&stopOnReshard, "stop_on_reshard", stopOnReshard, "have vtgate end the stream on reshards, which vtcdc then restarts on the new shards")
+	fs.StringVar(&cells, "cells", cells, "comma-separated cells to pick the source tablets from (default the cell of vtgate)")
+	fs.StringVar(&checkpointFile, "checkpoint_file", checkpointFile, "the file the position of the stream is checkpointed to, and resumed from")
+	fs.StringVar(&format, "format", format, "the format of the events: 'json' for the VEvents, or 'debezium' for Debezium change event payloads of the row changes")
+	fs.StringVar(&name, "name", name, "the name of the stream, used as the prefix of the output files and as the Debezium source name")
+	fs.StringVar(&outputDir, "output_dir", outputDir, "the directory of the output files (default stdout)")
+	fs.Int64Var(&maxFileSize, "max_file_size", maxFileSize, "the size in bytes after which an output file is rotated (0 to disable)")
+	fs.DurationVar(&maxFileAge, "max_file_age", maxFileAge, "the age after which an output file is rotated (0 to disable)")
+	fs.DurationVar(&retryDelay, "retry_delay", retryDelay, "how long to wait before restarting a failed stream")
+}
+
+func init() {
+	servenv.OnParseFor("vtcdc", registerFlags)
+}
+
+func main() {
+	defer exit.Recover()
+	defer logutil.Flush()
+
+	servenv.ParseFlags("vtcdc")
+
+	if err := run(); err != nil {
+		log.Errorf("vtcdc failed: %v", err)
+		exit.Return(1)
+	}
+}
+
+func run() error {
+	if server == "" {
+		return fmt.Errorf("--server is required")
+	}
+	config, err := newConfig()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	conn, err := vtgateconn.Dial(ctx, server)
+	if err != nil {
+		return fmt.Errorf("cannot dial vtgate %v: %v", server, err)
+	}
+	defer conn.Close()
+
+	cdc, err := vtcdc.New(config, conn.VStream)
+	if err != nil {
+		return err
+	}
+	defer cdc.Close()
+	return cdc.Run(ctx)
+}
+
+// newConfig builds the configuration of the stream from the flags.
+func newConfig() (*vtcdc.Config, error) {
+	tt, err := topoproto.ParseTabletType(tabletType)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &binlogdatapb.Filter{}
+	for _, rule := range rules {
+		match, sel, _ := strings.Cut(rule, "=")
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{
+			Match:  strings.TrimSpace(match),
+			Filter: strings.TrimSpace(sel),
+		})
+	}
+	if len(filter.Rules) == 0 {
+		filter.Rules = []*binlogdatapb.Rule{{Match: "/.*"}}
+	}
+
+	// The position is only used if there is no checkpoint.
+	var vgtid *binlogdatapb.VGtid
+	if keyspace != "" {
+		vgtid = &binlogdatapb.VGtid{}
+		if len(shards) == 0 {
+			// vtgate streams from all the shards of the keyspace.
+			shards = []string{""}
+		}
+		for _, shard := range shards {
+			vgtid.ShardGtids = append(vgtid.ShardGtids, &binlogdatapb.ShardGtid{
+				Keyspace: keyspace,
+				Shard:    shard,
+				Gtid:     position,
+			})
+		}
+	}
+
+	return &vtcdc.Config{
+		TabletType: tt,
+		Filter:     filter,
+		Flags: &vtgatepb.VStreamFlags{
+			MinimizeSkew:      minimizeSkew,
+			HeartbeatInterval: uint32(heartbeat.Seconds()),
+			StopOnReshard:     stopOnReshard,
+			Cells:             cells,
+		},
+		Position:       vgtid,
+		CheckpointFile: checkpointFile,
+		Format:         format,
+		Name:           name,
+		OutputDir:      outputDir,
+		MaxFileSize:    maxFileSize,
+		MaxFileAge:     maxFileAge,
+		RetryDelay:     retryDelay,
+	}, nil
+}
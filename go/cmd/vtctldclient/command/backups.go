@@ -76,6 +76,37 @@ If no replica-type tablet can be found, the backup can be taken on the primary i
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandRestoreFromBackup,
 	}
+	// SetBackupRateLimits makes a SetBackupRateLimits gRPC call to a vtctld.
+	SetBackupRateLimits = &cobra.Command{
+		Use:   "SetBackupRateLimits [--read-rate <bytes per second>] [--write-rate <bytes per second>] <tablet_alias>",
+		Short: "Changes the rate limits of the backups and restores of the specified tablet, including the ones in progress.",
+		Long: `Changes the rate limits of the backups and restores of the specified tablet, including the ones in progress.
+
+The read rate limits how fast builtin backups read the files of mysqld, and how fast restores read
+from the backup storage. The write rate limits how fast builtin backups write to the backup storage,
+and how fast restores write the files of mysqld. A rate of 0 removes the limit.
+
+The limits last until the tablet restarts, when they are reset to the values of the
+--backup-read-rate-limit and --backup-write-rate-limit flags.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandSetBackupRateLimits,
+	}
+	// VerifyBackup makes a VerifyBackup gRPC call to a vtctld.
+	VerifyBackup = &cobra.Command{
+		Use:   "VerifyBackup [--concurrency <concurrency>] <keyspace/shard> [<backup name> ...]",
+		Short: "Checks that backups of the given shard can be restored, without restoring them.",
+		Long: `Checks that backups of the given shard can be restored, without restoring them.
+
+Every file of each backup is read from the BackupStorage used by vtctld, decrypted and decompressed,
+and compared against the hash and size recorded in the backup's MANIFEST. If no backup names are
+given, the most recent complete backup is verified.
+
+The command fails if any of the backups could not be verified.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(1),
+		RunE:                  commandVerifyBackup,
+	}
 )
 
 var backupOptions = struct {
@@ -252,6 +283,72 @@ func commandRestoreFromBackup(cmd *cobra.Command, args []string) error {
 	}
 }
 
+var setBackupRateLimitsOptions = struct {
+	ReadRate  int64
+	WriteRate int64
+}{}
+
+func commandSetBackupRateLimits(cmd *cobra.Command, args []string) error {
+	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	_, err = client.SetBackupRateLimits(commandCtx, &vtctldatapb.SetBackupRateLimitsRequest{
+		TabletAlias:         alias,
+		ReadBytesPerSecond:  setBackupRateLimitsOptions.ReadRate,
+		WriteBytesPerSecond: setBackupRateLimitsOptions.WriteRate,
+	})
+	return err
+}
+
+var verifyBackupOptions = struct {
+	Concurrency uint64
+}{}
+
+func commandVerifyBackup(cmd *cobra.Command, args []string) error {
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	names := cmd.Flags().Args()[1:]
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VerifyBackup(commandCtx, &vtctldatapb.VerifyBackupRequest{
+		Keyspace:    keyspace,
+		Shard:       shard,
+		BackupNames: names,
+		Concurrency: verifyBackupOptions.Concurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	var failed []string
+	for _, result := range resp.Results {
+		if !result.Verified {
+			failed = append(failed, result.Backup.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to verify backups: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
 func init() {
 	Backup.Flags().BoolVar(&backupOptions.AllowPrimary, "allow-primary", false, "Allow the primary of a shard to be used for the backup. WARNING: If using the builtin backup engine, this will shutdown mysqld on the primary and stop writes for the duration of the backup.")
 	Backup.Flags().Uint64Var(&backupOptions.Concurrency, "concurrency", 4, "Specifies the number of compression/checksum jobs to run simultaneously.")
@@ -269,4 +366,11 @@ func init() {
 
 	RestoreFromBackup.Flags().StringVarP(&restoreFromBackupOptions.BackupTimestamp, "backup-timestamp", "t", "", "Use the backup taken at, or closest before, this timestamp. Omit to use the latest backup. Timestamp format is \"YYYY-mm-DD.HHMMSS\".")
 	Root.AddCommand(RestoreFromBackup)
+
+	SetBackupRateLimits.Flags().Int64Var(&setBackupRateLimitsOptions.ReadRate, "read-rate", 0, "Maximum rate, in bytes per second, at which backups and restores read data. 0 means no limit.")
+	SetBackupRateLimits.Flags().Int64Var(&setBackupRateLimitsOptions.WriteRate, "write-rate", 0, "Maximum rate, in bytes per second, at which backups and restores write data. 0 means no limit.")
+	Root.AddCommand(SetBackupRateLimits)
+
+	VerifyBackup.Flags().Uint64Var(&verifyBackupOptions.Concurrency, "concurrency", 4, "Specifies the number of files to read and checksum simultaneously.")
+	Root.AddCommand(VerifyBackup)
 }
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// GetDriftCheckMismatches makes a GetDriftCheckMismatches gRPC call to a vtctld.
+	GetDriftCheckMismatches = &cobra.Command{
+		Use:   "GetDriftCheckMismatches [--table <table>] [--limit <limit>] <keyspace|keyspace/shard>",
+		Short: "Lists the chunks of tables found to differ between the primaries and the replicas of a keyspace or shard.",
+		Long: `Lists the chunks of tables found to differ between the primaries and the replicas of a keyspace or shard.
+
+The mismatches are detected by the drift checker of the primary tablets, which
+is enabled with the vttablet --enable_drift_check flag. Each mismatch names
+the replica, the primary key range of the chunk and the replication position
+at which the rows were compared.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetDriftCheckMismatches,
+	}
+)
+
+var getDriftCheckMismatchesOptions = struct {
+	Table string
+	Limit uint32
+}{}
+
+func commandGetDriftCheckMismatches(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	shard := ""
+	if strings.Contains(keyspace, "/") {
+		var err error
+		keyspace, shard, err = topoproto.ParseKeyspaceShard(keyspace)
+		if err != nil {
+			return err
+		}
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetDriftCheckMismatches(commandCtx, &vtctldatapb.GetDriftCheckMismatchesRequest{
+		Keyspace:  keyspace,
+		Shard:     shard,
+		TableName: getDriftCheckMismatchesOptions.Table,
+		Limit:     getDriftCheckMismatchesOptions.Limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Mismatches)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	GetDriftCheckMismatches.Flags().StringVar(&getDriftCheckMismatchesOptions.Table, "table", "", "Only list the mismatches of this table.")
+	GetDriftCheckMismatches.Flags().Uint32Var(&getDriftCheckMismatchesOptions.Limit, "limit", 100, "Maximum number of mismatches listed per shard, the most recent first. 0 lists all of them.")
+	Root.AddCommand(GetDriftCheckMismatches)
+}
@@ -59,6 +59,19 @@ For --sql, semi-colons and repeated values may be mixed, for example:
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandApplySchema,
 	}
+	// EstimateSchemaMigration makes an EstimateSchemaMigration gRPC call to a vtctld.
+	EstimateSchemaMigration = &cobra.Command{
+		Use:   "EstimateSchemaMigration [--ddl-strategy <strategy>] {--sql-file <file> | --sql <sql>} <keyspace>",
+		Short: "Estimates, on every shard of the keyspace, the impact of running the schema change as an Online DDL migration, without running it.",
+		Long: `Estimates, on every shard of the keyspace, the impact of running the schema change as an Online DDL migration, without running it.
+
+For each statement and shard, the estimate includes the table's size and estimated row count, whether the change is eligible for INSTANT DDL,
+whether it completes without copying the table given the --ddl-strategy, the disk space required for the shadow table versus the free disk space,
+and the estimated copy time, based on the copy rate of the shard's recently completed vitess migrations.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandEstimateSchemaMigration,
+	}
 	// GetSchema makes a GetSchema gRPC call to a vtctld.
 	GetSchema = &cobra.Command{
 		Use:                   "GetSchema [--tables TABLES ...] [--exclude-tables EXCLUDE_TABLES ...] [{--table-names-only | --table-sizes-only}] [--include-views] alias",
@@ -67,6 +80,19 @@ For --sql, semi-colons and repeated values may be mixed, for example:
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetSchema,
 	}
+	// GetSchemaHistory makes a GetSchemaHistory gRPC call to a vtctld.
+	GetSchemaHistory = &cobra.Command{
+		Use:   "GetSchemaHistory [--position <position>] <tablet_alias> <table>",
+		Short: "Displays the changes to a table's definition recorded by a tablet's schema tracker.",
+		Long: `Displays the changes to a table's definition recorded by a tablet's schema tracker.
+
+Every entry contains the DDL, its replication position, the time it was recorded and the resulting table definition.
+The tablet must be running with --track_schema_versions.
+If --position is given, the definition of the table at that replication position is also displayed.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandGetSchemaHistory,
+	}
 	// ReloadSchema makes a ReloadSchema gRPC call to a vtctld.
 	ReloadSchema = &cobra.Command{
 		Use:                   "ReloadSchema <tablet_alias>",
@@ -155,6 +181,55 @@ func commandApplySchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var estimateSchemaMigrationOptions = struct {
+	SQL         []string
+	SQLFile     string
+	DDLStrategy string
+}{}
+
+func commandEstimateSchemaMigration(cmd *cobra.Command, args []string) error {
+	var allSQL string
+	if estimateSchemaMigrationOptions.SQLFile != "" {
+		if len(estimateSchemaMigrationOptions.SQL) != 0 {
+			return errors.New("Exactly one of --sql and --sql-file must be specified, not both.") // nolint
+		}
+
+		data, err := os.ReadFile(estimateSchemaMigrationOptions.SQLFile)
+		if err != nil {
+			return err
+		}
+
+		allSQL = string(data)
+	} else {
+		allSQL = strings.Join(estimateSchemaMigrationOptions.SQL, ";")
+	}
+
+	parts, err := sqlparser.SplitStatementToPieces(allSQL)
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.EstimateSchemaMigration(commandCtx, &vtctldatapb.EstimateSchemaMigrationRequest{
+		Keyspace:    cmd.Flags().Arg(0),
+		Sql:         parts,
+		DdlStrategy: estimateSchemaMigrationOptions.DDLStrategy,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 var getSchemaOptions = struct {
 	Tables          []string
 	ExcludeTables   []string
@@ -211,6 +286,37 @@ func commandGetSchema(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var getSchemaHistoryOptions = struct {
+	Position string
+}{}
+
+func commandGetSchemaHistory(cmd *cobra.Command, args []string) error {
+	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetSchemaHistory(commandCtx, &vtctldatapb.GetSchemaHistoryRequest{
+		TabletAlias: alias,
+		TableName:   cmd.Flags().Arg(1),
+		Position:    getSchemaHistoryOptions.Position,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func commandReloadSchema(cmd *cobra.Command, args []string) error {
 	tabletAlias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
 	if err != nil {
@@ -298,6 +404,12 @@ func init() {
 
 	Root.AddCommand(ApplySchema)
 
+	EstimateSchemaMigration.Flags().StringVar(&estimateSchemaMigrationOptions.DDLStrategy, "ddl-strategy", string(schema.DDLStrategyVitess), "Online DDL strategy, compatible with @@ddl_strategy session variable (examples: 'vitess', 'vitess --prefer-instant-ddl', 'gh-ost').")
+	EstimateSchemaMigration.Flags().StringArrayVar(&estimateSchemaMigrationOptions.SQL, "sql", nil, "Semicolon-delimited, repeatable SQL commands to estimate. Exactly one of --sql|--sql-file is required.")
+	EstimateSchemaMigration.Flags().StringVar(&estimateSchemaMigrationOptions.SQLFile, "sql-file", "", "Path to a file containing semicolon-delimited SQL commands to estimate. Exactly one of --sql|--sql-file is required.")
+
+	Root.AddCommand(EstimateSchemaMigration)
+
 	GetSchema.Flags().StringSliceVar(&getSchemaOptions.Tables, "tables", nil, "List of tables to display the schema for. Each is either an exact match, or a regular expression of the form `/regexp/`.")
 	GetSchema.Flags().StringSliceVar(&getSchemaOptions.ExcludeTables, "exclude-tables", nil, "List of tables to exclude from the result. Each is either an exact match, or a regular expression of the form `/regexp/`.")
 	GetSchema.Flags().BoolVar(&getSchemaOptions.IncludeViews, "include-views", false, "Includes views in the output in addition to base tables.")
@@ -307,6 +419,9 @@ func init() {
 
 	Root.AddCommand(GetSchema)
 
+	GetSchemaHistory.Flags().StringVar(&getSchemaHistoryOptions.Position, "position", "", "Replication position at which to also display the definition of the table.")
+	Root.AddCommand(GetSchemaHistory)
+
 	Root.AddCommand(ReloadSchema)
 
 	ReloadSchemaKeyspace.Flags().Uint32Var(&reloadSchemaKeyspaceOptions.Concurrency, "concurrency", 10, "Number of tablets to reload in parallel. Set to zero for unbounded concurrency.")
@@ -18,15 +18,66 @@ package command
 
 import (
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vterrors"
 
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 var (
+	// DiffTopology compares topology snapshots, or a snapshot with the
+	// topology, making an ExportTopology gRPC call to a vtctld in the latter
+	// case.
+	DiffTopology = &cobra.Command{
+		Use:   "DiffTopology [--cells <cell> ...] [--path <path> ...] <archive> [<archive>]",
+		Short: "Shows the differences between two topology snapshots, or between a snapshot and the topology.",
+		Long: `Shows the differences between two topology snapshots, or between a snapshot and the topology.
+
+The differences are shown from the first snapshot to the second one, or to the topology if only one
+snapshot is given. Files are compared on their decoded values, and the changed fields are listed for
+each changed file. Versions are not compared.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.RangeArgs(1, 2),
+		RunE:                  commandDiffTopology,
+	}
+	// ExportTopology makes an ExportTopology gRPC call to a vtctld.
+	ExportTopology = &cobra.Command{
+		Use:   "ExportTopology [--cells <cell> ...] <archive>",
+		Short: "Writes a snapshot of the topology to an archive.",
+		Long: `Writes a snapshot of the topology to an archive.
+
+The archive is a JSON file with all the files of the global topology and of the cells, except locks
+and elections. Files with a known type, like keyspaces, shards, tablets or vschemas, are decoded to
+JSON. The archive can be compared with DiffTopology and restored with RestoreTopology.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandExportTopology,
+	}
+	// GetTopologyAuditLog makes a GetTopologyAuditLog gRPC call to a vtctld.
+	GetTopologyAuditLog = &cobra.Command{
+		Use:   "GetTopologyAuditLog [--cells <cell> ...] [--path <path>] [--caller <caller>] [--since <duration>] [--limit <limit>]",
+		Short: "Shows the recent changes made to the topology.",
+		Long: `Shows the recent changes made to the topology, oldest first.
+
+Each change lists the file which was created, updated or deleted, the caller and process which made it,
+its reason, and the contents of the file before and after the change.
+
+The changes are only read from the local audit sink of the vtctld serving the request, which must be
+started with --topo_audit_sink: with the file sink, this is the --topo_audit_file_path of that vtctld.
+Changes made by other processes, including other vtctlds, vttablets and vtorc, are only shown if they
+append to the same file, for instance on a shared volume. Otherwise they are in their own audit files.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetTopologyAuditLog,
+	}
 	// GetTopologyPath makes a GetTopologyPath gRPC call to a vtctld.
 	GetTopologyPath = &cobra.Command{
 		Use:                   "GetTopologyPath <path>",
@@ -35,8 +86,133 @@ var (
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandGetTopologyPath,
 	}
+	// RestoreTopology makes ExportTopology and RestoreTopology gRPC calls to
+	// a vtctld.
+	RestoreTopology = &cobra.Command{
+		Use:   "RestoreTopology [--cells <cell> ...] --path <path> [--path <path> ...] [--delete] [--dry-run] <archive>",
+		Short: "Restores files of the topology from a snapshot archive.",
+		Long: `Restores files of the topology from a snapshot archive.
+
+The files under the given paths which differ between the topology and the snapshot are written back
+with their contents in the snapshot. Use "--path /" to restore all the files. Files which were added
+to the topology after the snapshot are only deleted with --delete.
+
+Each file is only written if it did not change since the topology was compared with the snapshot, so
+concurrent changes are never overwritten: no file is written unless all of them are at the version
+they were compared at. The keyspaces and shards of the files are locked during the restore.
+
+The serving graph is rebuilt afterwards, as with RebuildKeyspaceGraph for the keyspaces whose keyspace
+or shard records were restored, and as with RebuildVSchemaGraph if a keyspace VSchema was restored.
+
+For instance, "--cells global --path /keyspaces/commerce" restores a keyspace which was deleted, and
+"--cells global --path /keyspaces/commerce/VSchema" reverts a bad vschema change.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandRestoreTopology,
+	}
 )
 
+var diffTopologyOptions = struct {
+	Cells []string
+	Paths []string
+}{}
+
+func commandDiffTopology(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	from, err := readTopologySnapshot(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+	var to *topotools.TopologySnapshot
+	if cmd.Flags().NArg() == 2 {
+		to, err = readTopologySnapshot(cmd.Flags().Arg(1))
+	} else {
+		to, err = exportTopologySnapshot(from, diffTopologyOptions.Cells)
+	}
+	if err != nil {
+		return err
+	}
+
+	diffs, err := topotools.DiffTopologySnapshots(from, to)
+	if err != nil {
+		return err
+	}
+	for _, diff := range topotools.FilterTopologyDiffs(diffs, diffTopologyOptions.Cells, diffTopologyOptions.Paths) {
+		fmt.Println(diff)
+	}
+
+	return nil
+}
+
+var exportTopologyOptions = struct {
+	Cells []string
+}{}
+
+func commandExportTopology(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ExportTopology(commandCtx, &vtctldatapb.ExportTopologyRequest{
+		Cells: exportTopologyOptions.Cells,
+	})
+	if err != nil {
+		return err
+	}
+
+	snapshot := topotools.NewTopologySnapshot(resp.Entries)
+	f, err := os.Create(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d files of cells %v to %v\n", len(snapshot.Entries), snapshot.Cells(), cmd.Flags().Arg(0))
+
+	return nil
+}
+
+var getTopologyAuditLogOptions = struct {
+	Cells  []string
+	Path   string
+	Caller string
+	Since  time.Duration
+	Limit  uint32
+}{}
+
+func commandGetTopologyAuditLog(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.GetTopologyAuditLogRequest{
+		Cells:  getTopologyAuditLogOptions.Cells,
+		Path:   getTopologyAuditLogOptions.Path,
+		Caller: getTopologyAuditLogOptions.Caller,
+		Limit:  getTopologyAuditLogOptions.Limit,
+	}
+	if getTopologyAuditLogOptions.Since > 0 {
+		req.Since = protoutil.TimeToProto(time.Now().Add(-getTopologyAuditLogOptions.Since))
+	}
+
+	resp, err := client.GetTopologyAuditLog(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func commandGetTopologyPath(cmd *cobra.Command, args []string) error {
 	path := cmd.Flags().Arg(0)
 
@@ -59,6 +235,109 @@ func commandGetTopologyPath(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var restoreTopologyOptions = struct {
+	Cells  []string
+	Paths  []string
+	Delete bool
+	DryRun bool
+}{}
+
+func commandRestoreTopology(cmd *cobra.Command, args []string) error {
+	if len(restoreTopologyOptions.Paths) == 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "at least one --path must be given, use --path / to restore all the files")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	snapshot, err := readTopologySnapshot(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+	live, err := exportTopologySnapshot(snapshot, restoreTopologyOptions.Cells)
+	if err != nil {
+		return err
+	}
+
+	diffs, err := topotools.DiffTopologySnapshots(live, snapshot)
+	if err != nil {
+		return err
+	}
+	diffs = topotools.FilterTopologyDiffs(diffs, restoreTopologyOptions.Cells, restoreTopologyOptions.Paths)
+	changes, err := topotools.TopologyRestoreChanges(diffs, restoreTopologyOptions.Delete)
+	if err != nil {
+		return err
+	}
+
+	for _, diff := range diffs {
+		if diff.Type == topotools.TopologyDiffRemoved && !restoreTopologyOptions.Delete {
+			continue
+		}
+		fmt.Println(diff)
+	}
+	if restoreTopologyOptions.DryRun || len(changes) == 0 {
+		return nil
+	}
+
+	_, err = client.RestoreTopology(commandCtx, &vtctldatapb.RestoreTopologyRequest{
+		Changes: changes,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored %d files\n", len(changes))
+
+	return nil
+}
+
+func readTopologySnapshot(name string) (*topotools.TopologySnapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return topotools.ReadTopologySnapshot(f)
+}
+
+// exportTopologySnapshot returns a snapshot of the given cells of the
+// topology, or of the cells of the snapshot it will be compared with if no
+// cells are given.
+func exportTopologySnapshot(snapshot *topotools.TopologySnapshot, cells []string) (*topotools.TopologySnapshot, error) {
+	if len(cells) == 0 {
+		cells = snapshot.Cells()
+	}
+
+	resp, err := client.ExportTopology(commandCtx, &vtctldatapb.ExportTopologyRequest{
+		Cells: cells,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return topotools.NewTopologySnapshot(resp.Entries), nil
+}
+
 func init() {
+	DiffTopology.Flags().StringSliceVarP(&diffTopologyOptions.Cells, "cells", "c", nil, "Only compare the files of these cells, \"global\" being the global topology.")
+	DiffTopology.Flags().StringSliceVar(&diffTopologyOptions.Paths, "path", nil, "Only compare the files under these paths.")
+	Root.AddCommand(DiffTopology)
+
+	ExportTopology.Flags().StringSliceVarP(&exportTopologyOptions.Cells, "cells", "c", nil, "Only export the files of these cells, \"global\" being the global topology. By default, the global topology and all the cells are exported.")
+	Root.AddCommand(ExportTopology)
+
+	GetTopologyAuditLog.Flags().StringSliceVarP(&getTopologyAuditLogOptions.Cells, "cells", "c", nil, "Only show the changes of these cells, \"global\" being the global topology.")
+	GetTopologyAuditLog.Flags().StringVar(&getTopologyAuditLogOptions.Path, "path", "", "Only show the changes of this file, or of the files under this directory.")
+	GetTopologyAuditLog.Flags().StringVar(&getTopologyAuditLogOptions.Caller, "caller", "", "Only show the changes made by this caller.")
+	GetTopologyAuditLog.Flags().DurationVar(&getTopologyAuditLogOptions.Since, "since", 0, "Only show the changes made in this duration, for instance 1h. All the changes are shown by default.")
+	GetTopologyAuditLog.Flags().Uint32Var(&getTopologyAuditLogOptions.Limit, "limit", 100, "Maximum number of changes to show, the most recent ones being kept. Use 0 to show all the changes.")
+	Root.AddCommand(GetTopologyAuditLog)
+
 	Root.AddCommand(GetTopologyPath)
+
+	RestoreTopology.Flags().StringSliceVarP(&restoreTopologyOptions.Cells, "cells", "c", nil, "Only restore the files of these cells, \"global\" being the global topology.")
+	RestoreTopology.Flags().StringSliceVar(&restoreTopologyOptions.Paths, "path", nil, "Restore the files under these paths. Required.")
+	RestoreTopology.Flags().BoolVar(&restoreTopologyOptions.Delete, "delete", false, "Also delete the files under the paths which are not in the snapshot.")
+	RestoreTopology.Flags().BoolVar(&restoreTopologyOptions.DryRun, "dry-run", false, "Only show the differences which would be restored.")
+	Root.AddCommand(RestoreTopology)
 }
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// ConcludeTransaction makes a ConcludeTransaction gRPC call to a vtctld.
+	ConcludeTransaction = &cobra.Command{
+		Use:   "ConcludeTransaction [--action {resolve|commit|rollback}] <dtid>",
+		Short: "Concludes an unresolved distributed transaction.",
+		Long: `Concludes an unresolved distributed transaction.
+
+The default action, resolve, completes the transaction the same way vtgate's
+resolver would: transactions with a commit decision are committed on all
+participants, and all other transactions are rolled back. commit and rollback
+fail if they contradict the decision already recorded for the transaction.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandConcludeTransaction,
+	}
+	// ListUnresolvedTransactions makes a ListUnresolvedTransactions gRPC call to a vtctld.
+	ListUnresolvedTransactions = &cobra.Command{
+		Use:                   "ListUnresolvedTransactions [--abandon-age <duration>] <keyspace>",
+		Short:                 "Lists the unresolved distributed transactions managed by the primaries of a keyspace.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandListUnresolvedTransactions,
+	}
+)
+
+var concludeTransactionOptions = struct {
+	Action string
+}{}
+
+func commandConcludeTransaction(cmd *cobra.Command, args []string) error {
+	action, ok := vtctldatapb.ConcludeTransactionRequest_Action_value[strings.ToUpper(concludeTransactionOptions.Action)]
+	if !ok {
+		return fmt.Errorf("invalid --action %s; must be one of resolve, commit or rollback", concludeTransactionOptions.Action)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ConcludeTransaction(commandCtx, &vtctldatapb.ConcludeTransactionRequest{
+		Dtid:   cmd.Flags().Arg(0),
+		Action: vtctldatapb.ConcludeTransactionRequest_Action(action),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var listUnresolvedTransactionsOptions = struct {
+	AbandonAge time.Duration
+}{}
+
+func commandListUnresolvedTransactions(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ListUnresolvedTransactions(commandCtx, &vtctldatapb.ListUnresolvedTransactionsRequest{
+		Keyspace:   cmd.Flags().Arg(0),
+		AbandonAge: int64(listUnresolvedTransactionsOptions.AbandonAge.Seconds()),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp.Transactions)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	ConcludeTransaction.Flags().StringVar(&concludeTransactionOptions.Action, "action", "resolve", "How to conclude the transaction. One of resolve, commit or rollback.")
+	Root.AddCommand(ConcludeTransaction)
+
+	ListUnresolvedTransactions.Flags().DurationVar(&listUnresolvedTransactionsOptions.AbandonAge, "abandon-age", 0, "Only list transactions that were created more than this long ago.")
+	Root.AddCommand(ListUnresolvedTransactions)
+}
@@ -697,6 +697,26 @@ func TestForeignKeys(t *testing.T) {
 				KEY parent_id_idx (parent_id),
 				CONSTRAINT child_parent_fk FOREIGN KEY (parent_id) REFERENCES parent_table(id) ON DELETE CASCADE
 			)
+		`,
+			`
+			CREATE TABLE child_restrict_table (
+				id INT NOT NULL auto_increment,
+				parent_id INT,
+				child_hint_col INT NOT NULL DEFAULT 0,
+				PRIMARY KEY (id),
+				KEY parent_id_idx (parent_id),
+				CONSTRAINT child_restrict_parent_fk FOREIGN KEY (parent_id) REFERENCES parent_table(id) ON DELETE RESTRICT
+			)
+		`,
+			`
+			CREATE TABLE self_ref_table (
+				id INT NOT NULL,
+				parent_id INT,
+				self_ref_hint_col INT NOT NULL DEFAULT 0,
+				PRIMARY KEY (id),
+				KEY parent_id_idx (parent_id),
+				CONSTRAINT self_ref_fk FOREIGN KEY (parent_id) REFERENCES self_ref_table(id)
+			)
 		`,
 			`
 			CREATE TABLE child_nofk_table (
@@ -714,6 +734,8 @@ func TestForeignKeys(t *testing.T) {
 			"insert into child_table (id, parent_id) values(2,43)",
 			"insert into child_table (id, parent_id) values(3,43)",
 			"insert into child_table (id, parent_id) values(4,43)",
+			"insert into child_restrict_table (id, parent_id) values(1,43)",
+			"insert into child_restrict_table (id, parent_id) values(2,43)",
 		}
 		ddlStrategy        = "online --allow-zero-in-date"
 		ddlStrategyAllowFK = ddlStrategy + " --unsafe-allow-foreign-keys"
@@ -724,43 +746,66 @@ func TestForeignKeys(t *testing.T) {
 		sql              string
 		allowForeignKeys bool
 		expectHint       string
+		expectChildHint  string
 	}
 	var testCases = []testCase{
 		{
-			name:             "modify parent, not allowed",
+			// Once the tables are swapped, the child table is made to reference the new parent table
+			name:             "modify parent",
 			sql:              "alter table parent_table engine=innodb",
 			allowForeignKeys: false,
+			expectHint:       "parent_hint_col",
+			expectChildHint:  "REFERENCES `parent_table`",
 		},
 		{
-			name:             "modify child, not allowed",
+			// MySQL does not binlog the rows deleted by ON DELETE CASCADE, and so vreplication would not see them
+			name:             "modify cascading child, not allowed",
 			sql:              "alter table child_table engine=innodb",
 			allowForeignKeys: false,
 		},
 		{
-			name:             "add foreign key to child, not allowed",
+			name:             "modify restricting child",
+			sql:              "alter table child_restrict_table engine=innodb",
+			allowForeignKeys: false,
+			expectHint:       "REFERENCES `parent_table`",
+			expectChildHint:  "REFERENCES `parent_table`",
+		},
+		{
+			name:             "add column to parent",
+			sql:              "alter table parent_table add column parent_new_col int not null default 0",
+			allowForeignKeys: false,
+			expectHint:       "parent_new_col",
+			expectChildHint:  "REFERENCES `parent_table`",
+		},
+		{
+			name:             "add foreign key to cascading child, not allowed",
 			sql:              "alter table child_table add CONSTRAINT another_fk FOREIGN KEY (parent_id) REFERENCES parent_table(id) ON DELETE CASCADE",
 			allowForeignKeys: false,
 		},
 		{
-			name:             "add foreign key to table which wasn't a child before, not allowed",
+			name:             "add foreign key to restricting child",
+			sql:              "alter table child_restrict_table add CONSTRAINT another_fk FOREIGN KEY (parent_id) REFERENCES parent_table(id) ON DELETE CASCADE",
+			allowForeignKeys: false,
+			expectHint:       "another_fk",
+			expectChildHint:  "REFERENCES `parent_table`",
+		},
+		{
+			// The table is not a child until the migration completes, and so no cascaded deletes are missed while copying
+			name:             "add foreign key to table which wasn't a child before",
 			sql:              "alter table child_nofk_table add CONSTRAINT new_fk FOREIGN KEY (parent_id) REFERENCES parent_table(id) ON DELETE CASCADE",
 			allowForeignKeys: false,
+			expectHint:       "new_fk",
+			expectChildHint:  "REFERENCES `parent_table`",
 		},
 		{
-			// on vanilla MySQL, this migration ends with the child_table referencing the old, original table, and not to the new table now called parent_table.
-			// This is a fundamental foreign key limitation, see https://vitess.io/blog/2021-06-15-online-ddl-why-no-fk/
-			// However, this tests is still valid in the sense that it lets us modify the parent table in the first place.
 			name:             "modify parent, trivial",
 			sql:              "alter table parent_table engine=innodb",
 			allowForeignKeys: true,
 			expectHint:       "parent_hint_col",
 		},
 		{
-			// on vanilla MySQL, this migration ends with two tables, the original and the new child_table, both referencing parent_table. This has
-			// the unwanted property of then limiting actions on the parent_table based on what rows exist or do not exist on the now stale old
-			// child table.
-			// This is a fundamental foreign key limitation, see https://vitess.io/blog/2021-06-15-online-ddl-why-no-fk/
-			// However, this tests is still valid in the sense that it lets us modify the child table in the first place.
+			// --unsafe-allow-foreign-keys skips the validation of FOREIGN KEY actions. Rows deleted in child_table by
+			// ON DELETE CASCADE while the migration runs are not applied to the new table.
 			// A valid use case: using FOREIGN_KEY_CHECKS=0  at all times.
 			name:             "modify child, trivial",
 			sql:              "alter table child_table engine=innodb",
@@ -768,23 +813,31 @@ func TestForeignKeys(t *testing.T) {
 			expectHint:       "REFERENCES `parent_table`",
 		},
 		{
-			// on vanilla MySQL, this migration ends with two tables, the original and the new child_table, both referencing parent_table. This has
-			// the unwanted property of then limiting actions on the parent_table based on what rows exist or do not exist on the now stale old
-			// child table.
-			// This is a fundamental foreign key limitation, see https://vitess.io/blog/2021-06-15-online-ddl-why-no-fk/
-			// However, this tests is still valid in the sense that it lets us modify the child table in the first place.
-			// A valid use case: using FOREIGN_KEY_CHECKS=0  at all times.
-			name:             "add foreign key to child",
+			name:             "add foreign key to child, trivial",
 			sql:              "alter table child_table add CONSTRAINT another_fk FOREIGN KEY (parent_id) REFERENCES parent_table(id) ON DELETE CASCADE",
 			allowForeignKeys: true,
 			expectHint:       "another_fk",
 		},
 		{
-			name:             "add foreign key to table which wasn't a child before",
+			name:             "add foreign key to table which wasn't a child before, trivial",
 			sql:              "alter table child_nofk_table add CONSTRAINT new_fk FOREIGN KEY (parent_id) REFERENCES parent_table(id) ON DELETE CASCADE",
 			allowForeignKeys: true,
 			expectHint:       "new_fk",
 		},
+		{
+			name:             "modify self referencing table, not allowed",
+			sql:              "alter table self_ref_table engine=innodb",
+			allowForeignKeys: false,
+		},
+		{
+			// on vanilla MySQL, this migration ends with the table referencing the old, original table.
+			// This is a fundamental foreign key limitation, see https://vitess.io/blog/2021-06-15-online-ddl-why-no-fk/
+			// A valid use case: using FOREIGN_KEY_CHECKS=0  at all times.
+			name:             "modify self referencing table",
+			sql:              "alter table self_ref_table engine=innodb",
+			allowForeignKeys: true,
+			expectHint:       "self_ref_hint_col",
+		},
 	}
 
 	testStatement := func(t *testing.T, sql string, ddlStrategy string, expectHint string, expectError bool) (uuid string) {
@@ -813,16 +866,28 @@ func TestForeignKeys(t *testing.T) {
 			})
 			var uuid string
 			t.Run("run migration", func(t *testing.T) {
-				if testcase.allowForeignKeys {
+				switch {
+				case testcase.allowForeignKeys:
 					uuid = testStatement(t, testcase.sql, ddlStrategyAllowFK, testcase.expectHint, false)
 					onlineddl.CheckMigrationStatus(t, &vtParams, shards, uuid, schema.OnlineDDLStatusComplete)
-				} else {
+				case testcase.expectHint != "":
+					uuid = testStatement(t, testcase.sql, ddlStrategy, testcase.expectHint, false)
+					onlineddl.CheckMigrationStatus(t, &vtParams, shards, uuid, schema.OnlineDDLStatusComplete)
+				default:
 					uuid = testStatement(t, testcase.sql, ddlStrategy, "", true)
 					if uuid != "" {
 						onlineddl.CheckMigrationStatus(t, &vtParams, shards, uuid, schema.OnlineDDLStatusFailed)
 					}
 				}
 			})
+			if testcase.expectChildHint != "" {
+				t.Run("validate child table", func(t *testing.T) {
+					checkMigratedTable(t, "child_table", testcase.expectChildHint)
+					// the child table must not reference the artifact table, which is now the old parent table
+					createStatement := getCreateTableStatement(t, clusterInstance.Keyspaces[0].Shards[0].Vttablets[0], "child_table")
+					assert.NotContains(t, createStatement, "REFERENCES `_")
+				})
+			}
 			t.Run("cleanup", func(t *testing.T) {
 				var artifacts []string
 				if uuid != "" {
@@ -834,7 +899,7 @@ func TestForeignKeys(t *testing.T) {
 					artifacts = textutil.SplitDelimitedList(row.AsString("artifacts", ""))
 				}
 
-				artifacts = append(artifacts, "child_table", "child_nofk_table", "parent_table")
+				artifacts = append(artifacts, "child_table", "child_restrict_table", "child_nofk_table", "self_ref_table", "parent_table")
 				// brute force drop all tables. In MySQL 8.0 you can do a single `DROP TABLE ... <list of all tables>`
 				// which auto-resovled order. But in 5.7 you can't.
 				droppedTables := map[string]bool{}
@@ -0,0 +1,37 @@
+set session foreign_key_checks=0;
+drop table if exists onlineddl_test_child;
+drop table if exists onlineddl_test;
+drop table if exists onlineddl_test_parent;
+set session foreign_key_checks=1;
+create table onlineddl_test (
+  id int auto_increment,
+  ts timestamp,
+  primary key(id)
+);
+create table onlineddl_test_child (
+  id int auto_increment,
+  i int not null,
+  parent_id int not null,
+  primary key(id),
+  constraint test_fk foreign key (parent_id) references onlineddl_test (id) on delete cascade
+) auto_increment=1;
+
+insert into onlineddl_test (id) values (1),(2),(3);
+insert into onlineddl_test_child values (null, 11, 1), (null, 13, 2), (null, 17, 3);
+
+drop event if exists onlineddl_test;
+delimiter ;;
+create event onlineddl_test
+  on schedule every 1 second
+  starts current_timestamp
+  ends current_timestamp + interval 60 second
+  on completion not preserve
+  enable
+  do
+begin
+  insert into onlineddl_test values (null, now());
+  set @parent_id := last_insert_id();
+  insert into onlineddl_test_child values (null, 11, @parent_id);
+  insert into onlineddl_test_child values (null, 13, @parent_id);
+  delete from onlineddl_test where id = @parent_id - 2;
+end ;;
@@ -47,9 +47,11 @@ var (
 	// every vitess binary that makes grpc client-side calls.
 	grpcclientBinaries = []string{
 		"mysqlctld",
+		"schemadiff",
 		"vtadmin",
 		"vtbackup",
 		"vtbench",
+		"vtcdc",
 		"vtclient",
 		"vtctl",
 		"vtctlclient",
@@ -280,6 +280,33 @@ func ShouldRestore(ctx context.Context, params RestoreParams) (bool, error) {
 	return checkNoDB(ctx, params.Mysqld, params.DbName)
 }
 
+// VerifyBackup reads all the files of a backup and checks them against its
+// MANIFEST, without restoring it. It returns the MANIFEST of the backup, and an
+// error if the backup cannot be verified or is corrupt.
+func VerifyBackup(ctx context.Context, params VerifyParams, bh backupstorage.BackupHandle) (*BackupManifest, error) {
+	manifest, err := GetBackupManifest(ctx, bh)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't get backup MANIFEST")
+	}
+	re, err := GetRestoreEngine(ctx, bh)
+	if err != nil {
+		return manifest, err
+	}
+	verifier, ok := re.(BackupVerifier)
+	if !ok {
+		return manifest, vterrors.Errorf(vtrpc.Code_UNIMPLEMENTED, "backups created with %q engine cannot be verified", manifest.BackupMethod)
+	}
+	if params.Concurrency < 1 {
+		params.Concurrency = 1
+	}
+	params.Logger.Infof("Verifying backup %v/%v", bh.Directory(), bh.Name())
+	if err := verifier.VerifyBackup(ctx, params, bh); err != nil {
+		return manifest, err
+	}
+	params.Logger.Infof("Verified backup %v/%v", bh.Directory(), bh.Name())
+	return manifest, nil
+}
+
 // Restore is the main entry point for backup restore.  If there is no
 // appropriate backup on the BackupStorage, Restore logs an error
 // and returns ErrNoBackup. Any other error is returned.
@@ -17,13 +17,21 @@ limitations under the License.
 package mysqlctl
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
 )
 
 func TestFindFilesToBackupWithoutRedoLog(t *testing.T) {
@@ -214,3 +222,78 @@ type forTest []FileEntry
 func (f forTest) Len() int           { return len(f) }
 func (f forTest) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
 func (f forTest) Less(i, j int) bool { return f[i].Base+f[i].Name < f[j].Base+f[j].Name }
+
+func TestVerifyBackup(t *testing.T) {
+	root := t.TempDir()
+	innodbDataDir := path.Join(root, "innodb_data")
+	innodbLogDir := path.Join(root, "innodb_log")
+	dataDir := path.Join(root, "data")
+	dataDbDir := path.Join(dataDir, "vt_db")
+	for _, dir := range []string{innodbDataDir, innodbLogDir, dataDbDir} {
+		require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	}
+	for name, contents := range map[string]string{
+		path.Join(innodbDataDir, "ibdata1"):    strings.Repeat("innodb data", 10000),
+		path.Join(innodbLogDir, "ib_logfile0"): "innodb log",
+		path.Join(dataDbDir, "t1.ibd"):         strings.Repeat("table data", 1000),
+		path.Join(dataDbDir, "empty.ibd"):      "",
+	} {
+		require.NoError(t, os.WriteFile(name, []byte(contents), os.ModePerm))
+	}
+	cnf := &Mycnf{
+		InnodbDataHomeDir:     innodbDataDir,
+		InnodbLogGroupHomeDir: innodbLogDir,
+		DataDir:               dataDir,
+	}
+
+	defer func(root, provider, keyfile string) {
+		filebackupstorage.FileBackupStorageRoot = root
+		BackupEncryptionKeyProvider, BackupEncryptionKeyfile = provider, keyfile
+	}(filebackupstorage.FileBackupStorageRoot, BackupEncryptionKeyProvider, BackupEncryptionKeyfile)
+	filebackupstorage.FileBackupStorageRoot = path.Join(root, "backups")
+	keyfile := path.Join(root, "keyfile")
+	require.NoError(t, os.WriteFile(keyfile, []byte(strings.Repeat("ab", dataKeySize)), 0600))
+
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+	be := &BuiltinBackupEngine{}
+	bs := &filebackupstorage.FileBackupStorage{}
+
+	for _, encrypted := range []bool{false, true} {
+		t.Run(fmt.Sprintf("encrypted=%v", encrypted), func(t *testing.T) {
+			BackupEncryptionKeyProvider, BackupEncryptionKeyfile = "", ""
+			if encrypted {
+				BackupEncryptionKeyProvider, BackupEncryptionKeyfile = KeyfileKeyProvider, keyfile
+			}
+			name := fmt.Sprintf("backup-%v", encrypted)
+			bh, err := bs.StartBackup(ctx, "ks/0", name)
+			require.NoError(t, err)
+			err = be.backupFiles(ctx, BackupParams{Cnf: cnf, Logger: logger, Concurrency: 2}, bh, mysql.Position{}, mysql.Position{}, mysql.Position{}, nil, "")
+			require.NoError(t, err)
+			require.NoError(t, bh.EndBackup(ctx))
+			bhs, err := bs.ListBackups(ctx, "ks/0")
+			require.NoError(t, err)
+			bh = bhs[len(bhs)-1]
+			require.Equal(t, name, bh.Name())
+
+			params := VerifyParams{Logger: logger, Concurrency: 2}
+			manifest, err := VerifyBackup(ctx, params, bh)
+			require.NoError(t, err)
+			assert.Equal(t, builtinBackupEngineName, manifest.BackupMethod)
+
+			// Alter the stored copy of the first file.
+			stored := path.Join(filebackupstorage.FileBackupStorageRoot, "ks/0", name, "0")
+			data, err := os.ReadFile(stored)
+			require.NoError(t, err)
+			data[len(data)/2] ^= 1
+			require.NoError(t, os.WriteFile(stored, data, os.ModePerm))
+			_, err = VerifyBackup(ctx, params, bh)
+			assert.ErrorContains(t, err, "can't verify file 0")
+
+			// Remove it.
+			require.NoError(t, os.Remove(stored))
+			_, err = VerifyBackup(ctx, params, bh)
+			assert.ErrorContains(t, err, "can't open source file for reading")
+		})
+	}
+}
@@ -70,6 +70,11 @@ type BackupParams struct {
 	// Position of last known backup. If non empty, then this value indicates the backup should be incremental
 	// and as of this position
 	IncrementalFromPos string
+	// NewThrottleCheck, if set, is called for each file to back up, and returns
+	// a function which is called before reading from that file. The backup waits
+	// while it returns false. Files are backed up concurrently, hence each of
+	// them gets its own check.
+	NewThrottleCheck func() func(ctx context.Context) bool
 }
 
 // RestoreParams is the struct that holds all params passed to ExecuteRestore
@@ -113,6 +118,21 @@ type RestoreEngine interface {
 	ExecuteRestore(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle) (*BackupManifest, error)
 }
 
+// VerifyParams is the struct that holds all params passed to VerifyBackup
+type VerifyParams struct {
+	Logger logutil.Logger
+	// Concurrency determines how many files are verified in parallel
+	Concurrency int
+	// Extra env variables for the transform hook used to read the backup
+	HookExtraEnv map[string]string
+}
+
+// BackupVerifier is implemented by the engines which can check that a backup
+// is readable and intact, without restoring it.
+type BackupVerifier interface {
+	VerifyBackup(ctx context.Context, params VerifyParams, bh backupstorage.BackupHandle) error
+}
+
 // BackupRestoreEngine is a combination of BackupEngine and RestoreEngine.
 type BackupRestoreEngine interface {
 	BackupEngine
@@ -0,0 +1,293 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/time/rate"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	backupOperation  = "backup"
+	restoreOperation = "restore"
+
+	// maxRateLimitBurst caps the burst of the rate limiters, so that high
+	// limits still smooth out the reads and writes.
+	maxRateLimitBurst = 16 * 1024 * 1024
+)
+
+var (
+	backupReadRateLimit  int64
+	backupWriteRateLimit int64
+
+	// backupThrottleWait is how long to wait before checking the throttler
+	// again, when a backup is throttled.
+	backupThrottleWait = 250 * time.Millisecond
+
+	backupRateLimitersOnce sync.Once
+	backupReadLimiter      = rate.NewLimiter(rate.Inf, maxRateLimitBurst)
+	backupWriteLimiter     = rate.NewLimiter(rate.Inf, maxRateLimitBurst)
+
+	backupProgressMu sync.Mutex
+	backupProgresses = map[string]*backupProgress{}
+
+	statsBackupReadRateLimit = stats.NewGaugeFunc("BackupReadRateLimit", "Maximum rate at which backups and restores read data, in bytes per second (0 = unlimited)", func() int64 {
+		read, _ := BackupRateLimits()
+		return read
+	})
+	statsBackupWriteRateLimit = stats.NewGaugeFunc("BackupWriteRateLimit", "Maximum rate at which backups and restores write data, in bytes per second (0 = unlimited)", func() int64 {
+		_, write := BackupRateLimits()
+		return write
+	})
+	statsBackupProgressBytesDone  = stats.NewGaugesWithSingleLabel("BackupProgressBytesDone", "Bytes processed by the backup or restore in progress, or the last one", "operation")
+	statsBackupProgressBytesTotal = stats.NewGaugesWithSingleLabel("BackupProgressBytesTotal", "Bytes to process by the backup or restore in progress, or the last one", "operation")
+	statsBackupProgressETA        = stats.NewGaugesFuncWithMultiLabels("BackupProgressEtaSeconds", "Estimated time to completion of the backup or restore in progress, in seconds", []string{"operation"}, backupProgressETAs)
+	statsBackupThrottled          = stats.NewCounter("BackupThrottledMs", "Time backups have waited for the throttler, in milliseconds")
+)
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vttablet", "vttestserver", "vtbackup", "vtctld"} {
+		servenv.OnParseFor(cmd, registerBackupRateLimitFlags)
+	}
+}
+
+func registerBackupRateLimitFlags(fs *pflag.FlagSet) {
+	fs.Int64Var(&backupReadRateLimit, "backup-read-rate-limit", backupReadRateLimit, "maximum rate, in bytes per second, at which builtin backups read the files of mysqld, and restores read from the backup storage. 0 means no limit. On vttablet, it can be changed at runtime with the SetBackupRateLimits RPC.")
+	fs.Int64Var(&backupWriteRateLimit, "backup-write-rate-limit", backupWriteRateLimit, "maximum rate, in bytes per second, at which builtin backups write to the backup storage, and restores write the files of mysqld. 0 means no limit. On vttablet, it can be changed at runtime with the SetBackupRateLimits RPC.")
+}
+
+// backupRateLimiters returns the read and write rate limiters, set up from
+// the flags on first use.
+func backupRateLimiters() (read, write *rate.Limiter) {
+	backupRateLimitersOnce.Do(func() {
+		setRateLimit(backupReadLimiter, backupReadRateLimit)
+		setRateLimit(backupWriteLimiter, backupWriteRateLimit)
+	})
+	return backupReadLimiter, backupWriteLimiter
+}
+
+func setRateLimit(limiter *rate.Limiter, bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		limiter.SetLimit(rate.Inf)
+		limiter.SetBurst(maxRateLimitBurst)
+		return
+	}
+	burst := bytesPerSecond
+	if burst > maxRateLimitBurst {
+		burst = maxRateLimitBurst
+	}
+	limiter.SetLimit(rate.Limit(bytesPerSecond))
+	limiter.SetBurst(int(burst))
+}
+
+func rateLimit(limiter *rate.Limiter) int64 {
+	if limiter.Limit() == rate.Inf {
+		return 0
+	}
+	return int64(limiter.Limit())
+}
+
+// SetBackupRateLimits changes the maximum rates, in bytes per second, at
+// which backups and restores read and write data, including for the backups
+// and restores in progress. 0 means no limit.
+func SetBackupRateLimits(read, write int64) error {
+	if read < 0 || write < 0 {
+		return vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "rate limits cannot be negative: read %v, write %v", read, write)
+	}
+	readLimiter, writeLimiter := backupRateLimiters()
+	setRateLimit(readLimiter, read)
+	setRateLimit(writeLimiter, write)
+	return nil
+}
+
+// BackupRateLimits returns the maximum rates, in bytes per second, at which
+// backups and restores read and write data. 0 means no limit.
+func BackupRateLimits() (read, write int64) {
+	readLimiter, writeLimiter := backupRateLimiters()
+	return rateLimit(readLimiter), rateLimit(writeLimiter)
+}
+
+// waitForRateLimit waits until n bytes can be read or written.
+func waitForRateLimit(ctx context.Context, limiter *rate.Limiter, n int) error {
+	for n > 0 {
+		if limiter.Limit() == rate.Inf {
+			return nil
+		}
+		m := n
+		if burst := limiter.Burst(); m > burst {
+			m = burst
+		}
+		if err := limiter.WaitN(ctx, m); err != nil {
+			return err
+		}
+		n -= m
+	}
+	return nil
+}
+
+// backupProgress tracks the progress of a backup or restore.
+type backupProgress struct {
+	operation string
+	started   time.Time
+	done      int64
+	total     int64
+}
+
+// startBackupProgress starts tracking the progress of a backup or restore of
+// total bytes.
+func startBackupProgress(operation string, total int64) *backupProgress {
+	p := &backupProgress{
+		operation: operation,
+		started:   time.Now(),
+		total:     total,
+	}
+	backupProgressMu.Lock()
+	backupProgresses[operation] = p
+	backupProgressMu.Unlock()
+	statsBackupProgressBytesDone.Set(operation, 0)
+	statsBackupProgressBytesTotal.Set(operation, total)
+	return p
+}
+
+func (p *backupProgress) add(n int64) {
+	if p == nil {
+		return
+	}
+	statsBackupProgressBytesDone.Set(p.operation, atomic.AddInt64(&p.done, n))
+}
+
+// finish stops tracking the progress.
+func (p *backupProgress) finish() {
+	if p == nil {
+		return
+	}
+	backupProgressMu.Lock()
+	defer backupProgressMu.Unlock()
+	if backupProgresses[p.operation] == p {
+		delete(backupProgresses, p.operation)
+	}
+}
+
+// eta estimates the time to completion, from the average rate so far. It
+// returns 0 if it cannot be estimated yet.
+func (p *backupProgress) eta() time.Duration {
+	done := atomic.LoadInt64(&p.done)
+	if done == 0 || p.total <= done {
+		return 0
+	}
+	elapsed := time.Since(p.started)
+	return time.Duration(float64(elapsed) * float64(p.total-done) / float64(done))
+}
+
+func backupProgressETAs() map[string]int64 {
+	backupProgressMu.Lock()
+	defer backupProgressMu.Unlock()
+	etas := map[string]int64{}
+	for operation, p := range backupProgresses {
+		etas[operation] = int64(p.eta().Seconds())
+	}
+	return etas
+}
+
+// newThrottleCheck returns the throttle check of a file to back up, or nil if
+// the backup is not throttled.
+func newThrottleCheck(params BackupParams) func(ctx context.Context) bool {
+	if params.NewThrottleCheck == nil {
+		return nil
+	}
+	return params.NewThrottleCheck()
+}
+
+// limitedReader rate limits and counts the data read from r. If throttleCheck
+// is set, it waits before each read until throttleCheck returns true.
+type limitedReader struct {
+	ctx           context.Context
+	r             io.Reader
+	limiter       *rate.Limiter
+	throttleCheck func(ctx context.Context) bool
+	progress      *backupProgress
+}
+
+func newLimitedReader(ctx context.Context, r io.Reader, throttleCheck func(ctx context.Context) bool, progress *backupProgress) *limitedReader {
+	limiter, _ := backupRateLimiters()
+	return &limitedReader{
+		ctx:           ctx,
+		r:             r,
+		limiter:       limiter,
+		throttleCheck: throttleCheck,
+		progress:      progress,
+	}
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if lr.throttleCheck != nil {
+		for !lr.throttleCheck(lr.ctx) {
+			select {
+			case <-lr.ctx.Done():
+				return 0, lr.ctx.Err()
+			case <-time.After(backupThrottleWait):
+				statsBackupThrottled.Add(backupThrottleWait.Milliseconds())
+			}
+		}
+	}
+	n, err := lr.r.Read(p)
+	if n > 0 {
+		lr.progress.add(int64(n))
+		if werr := waitForRateLimit(lr.ctx, lr.limiter, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// limitedWriter rate limits and counts the data written to w.
+type limitedWriter struct {
+	ctx      context.Context
+	w        io.Writer
+	limiter  *rate.Limiter
+	progress *backupProgress
+}
+
+func newLimitedWriter(ctx context.Context, w io.Writer, progress *backupProgress) *limitedWriter {
+	_, limiter := backupRateLimiters()
+	return &limitedWriter{
+		ctx:      ctx,
+		w:        w,
+		limiter:  limiter,
+		progress: progress,
+	}
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if err := waitForRateLimit(lw.ctx, lw.limiter, len(p)); err != nil {
+		return 0, err
+	}
+	n, err := lw.w.Write(p)
+	lw.progress.add(int64(n))
+	return n, err
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRateLimits(t *testing.T) {
+	defer SetBackupRateLimits(0, 0)
+
+	read, write := BackupRateLimits()
+	assert.Zero(t, read)
+	assert.Zero(t, write)
+
+	require.NoError(t, SetBackupRateLimits(1024, 2048))
+	read, write = BackupRateLimits()
+	assert.EqualValues(t, 1024, read)
+	assert.EqualValues(t, 2048, write)
+
+	assert.ErrorContains(t, SetBackupRateLimits(-1, 0), "cannot be negative")
+	read, write = BackupRateLimits()
+	assert.EqualValues(t, 1024, read)
+	assert.EqualValues(t, 2048, write)
+
+	require.NoError(t, SetBackupRateLimits(0, 0))
+	read, write = BackupRateLimits()
+	assert.Zero(t, read)
+	assert.Zero(t, write)
+}
+
+func TestLimitedReaderWriter(t *testing.T) {
+	defer SetBackupRateLimits(0, 0)
+	ctx := context.Background()
+	data := strings.Repeat("x", 4096)
+
+	// Reads and writes are counted as progress.
+	progress := startBackupProgress(backupOperation, 2*int64(len(data)))
+	defer progress.finish()
+	var buf bytes.Buffer
+	_, err := io.Copy(newLimitedWriter(ctx, &buf, progress), newLimitedReader(ctx, strings.NewReader(data), nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, data, buf.String())
+	assert.EqualValues(t, len(data), progress.done)
+	assert.EqualValues(t, len(data), statsBackupProgressBytesDone.Counts()[backupOperation])
+	assert.EqualValues(t, 2*len(data), statsBackupProgressBytesTotal.Counts()[backupOperation])
+	assert.Contains(t, backupProgressETAs(), backupOperation)
+
+	// Reads are slowed down to the rate limit, once the burst is used.
+	require.NoError(t, SetBackupRateLimits(int64(len(data)), 0))
+	start := time.Now()
+	_, err = io.Copy(io.Discard, newLimitedReader(ctx, strings.NewReader(data+data), nil, progress))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+
+	// Writes stop when the context is done.
+	require.NoError(t, SetBackupRateLimits(0, 1))
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = newLimitedWriter(cancelCtx, io.Discard, nil).Write([]byte(data))
+	assert.Error(t, err)
+
+	progress.finish()
+	assert.NotContains(t, backupProgressETAs(), backupOperation)
+}
+
+func TestLimitedReaderThrottled(t *testing.T) {
+	defer func(wait time.Duration) {
+		backupThrottleWait = wait
+	}(backupThrottleWait)
+	backupThrottleWait = 10 * time.Millisecond
+	ctx := context.Background()
+
+	checks := 0
+	throttleCheck := func(ctx context.Context) bool {
+		checks++
+		return checks > 3
+	}
+	data, err := io.ReadAll(newLimitedReader(ctx, strings.NewReader("data"), throttleCheck, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+	assert.GreaterOrEqual(t, checks, 4)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = newLimitedReader(cancelCtx, strings.NewReader("data"), func(ctx context.Context) bool { return false }, nil).Read(make([]byte, 4))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBackupProgressETA(t *testing.T) {
+	p := &backupProgress{
+		operation: "test",
+		started:   time.Now().Add(-10 * time.Second),
+		total:     400,
+	}
+	assert.Zero(t, p.eta())
+
+	p.done = 100
+	assert.InDelta(t, 30*time.Second, p.eta(), float64(time.Second))
+
+	p.done = 400
+	assert.Zero(t, p.eta())
+}
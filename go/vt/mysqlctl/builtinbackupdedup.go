@@ -0,0 +1,558 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// Deduplicated builtin backups split each file into fixed size chunks, and
+// store every chunk once, named by the sha256 of its contents, in a chunk store
+// shared by all the backups of the shard. The chunk store lives next to the
+// backups, in the "<keyspace>/<shard>.chunks" directory of the BackupStorage.
+//
+// Each backup adds the chunks which are not in the store yet to its own chunk
+// pack, a "backup" of the chunk store directory with the same name as the
+// backup. A pack holds one file per chunk, a REFS file written first which
+// lists the packs the backup may reuse chunks from, and an INDEX file written
+// last which lists the chunks of the pack. The MANIFEST of the backup lists the
+// chunks of each file, and the packs they are stored in.
+//
+// PruneBackupChunks removes the packs which are not referenced by any backup.
+
+const (
+	backupChunkDirSuffix = ".chunks"
+	chunkPackIndexFile   = "INDEX"
+	chunkPackRefsFile    = "REFS"
+)
+
+var (
+	// BuiltinBackupDedup enables deduplicated builtin backups.
+	BuiltinBackupDedup = false
+	// BuiltinBackupDedupChunkSize is the size of the chunks files are split
+	// into in deduplicated builtin backups.
+	BuiltinBackupDedupChunkSize = 4 * 1024 * 1024
+	// BuiltinBackupDedupAbandonAfter is how long a deduplicated backup without
+	// a MANIFEST is considered in progress. Older backups are considered
+	// abandoned, and do not keep chunk packs from being pruned.
+	BuiltinBackupDedupAbandonAfter = 24 * time.Hour
+)
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vttablet", "vttestserver", "vtbackup", "vtctld"} {
+		servenv.OnParseFor(cmd, registerBuiltinBackupDedupFlags)
+	}
+}
+
+func registerBuiltinBackupDedupFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&BuiltinBackupDedup, "builtinbackup-dedup", BuiltinBackupDedup, "split the files of full builtin backups into chunks, and only upload the chunks which are not stored yet by previous backups of the shard.")
+	fs.IntVar(&BuiltinBackupDedupChunkSize, "builtinbackup-dedup-chunk-size", BuiltinBackupDedupChunkSize, "size of the chunks in bytes, with --builtinbackup-dedup. Changing it prevents new backups from reusing the chunks of previous ones.")
+	fs.DurationVar(&BuiltinBackupDedupAbandonAfter, "builtinbackup-dedup-abandon-after", BuiltinBackupDedupAbandonAfter, "how long a deduplicated backup without a MANIFEST is considered in progress when pruning chunks. The chunk packs of older such backups, which failed or were aborted, are pruned. Must be longer than any backup takes.")
+}
+
+// backupChunkDir returns the directory of the chunk store of the backups in
+// backupDir.
+func backupChunkDir(backupDir string) string {
+	return backupDir + backupChunkDirSuffix
+}
+
+// chunkPackIndex is the contents of the INDEX file of a chunk pack.
+type chunkPackIndex struct {
+	Chunks []string
+}
+
+// chunkPackRefs is the contents of the REFS file of a chunk pack.
+type chunkPackRefs struct {
+	Packs []string
+}
+
+func readChunkPackFile(ctx context.Context, pack backupstorage.BackupHandle, name string, v any) error {
+	rc, err := pack.ReadFile(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeChunkPackFile(ctx context.Context, pack backupstorage.BackupHandle, name string, v any) (finalErr error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	wc, err := pack.AddFile(ctx, name, int64(len(data)))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := wc.Close(); finalErr == nil {
+			finalErr = closeErr
+		}
+	}()
+	_, err = wc.Write(data)
+	return err
+}
+
+// readChunkIndexes reads the INDEX of the given packs, and returns the pack
+// which stores each chunk. Packs without an INDEX are incomplete, and skipped.
+func readChunkIndexes(ctx context.Context, logger logutil.Logger, packs []backupstorage.BackupHandle) map[string]string {
+	chunks := map[string]string{}
+	for _, pack := range packs {
+		var index chunkPackIndex
+		if err := readChunkPackFile(ctx, pack, chunkPackIndexFile, &index); err != nil {
+			logger.Warningf("Ignoring chunk pack %v/%v without a readable %v: %v", pack.Directory(), pack.Name(), chunkPackIndexFile, err)
+			continue
+		}
+		for _, chunk := range index.Chunks {
+			chunks[chunk] = pack.Name()
+		}
+	}
+	return chunks
+}
+
+// chunkWriter stores the chunks of a deduplicated backup.
+type chunkWriter struct {
+	bs   backupstorage.BackupStorage
+	dir  string
+	pack backupstorage.BackupHandle
+
+	mu sync.Mutex
+	// existing maps the chunks stored by previous backups to their pack.
+	existing map[string]string
+	// added holds the chunks added to the pack of this backup.
+	added map[string]bool
+	// used holds the packs holding chunks of this backup.
+	used map[string]bool
+}
+
+// newChunkWriter loads the index of the chunk store of the backups in
+// backupDir, and starts the chunk pack of the backup with the given name.
+func newChunkWriter(ctx context.Context, logger logutil.Logger, backupDir, name string) (*chunkWriter, error) {
+	if BuiltinBackupDedupChunkSize <= 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid --builtinbackup-dedup-chunk-size: %v", BuiltinBackupDedupChunkSize)
+	}
+	if backupStorageHook != "" {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "--builtinbackup-dedup cannot be used with --backup_storage_hook")
+	}
+	if BackupEncryptionKeyProvider != "" {
+		// Each backup is encrypted with its own data key, so its chunks could
+		// not be shared with other backups.
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "--builtinbackup-dedup cannot be used with --backup-encryption-key-provider")
+	}
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	cw := &chunkWriter{
+		bs:    bs,
+		dir:   backupChunkDir(backupDir),
+		added: map[string]bool{},
+		used:  map[string]bool{},
+	}
+	packs, err := bs.ListBackups(ctx, cw.dir)
+	if err != nil {
+		bs.Close()
+		return nil, vterrors.Wrapf(err, "can't list chunk packs in %v", cw.dir)
+	}
+	cw.existing = readChunkIndexes(ctx, logger, packs)
+
+	if cw.pack, err = bs.StartBackup(ctx, cw.dir, name); err != nil {
+		bs.Close()
+		return nil, vterrors.Wrapf(err, "can't start chunk pack %v/%v", cw.dir, name)
+	}
+	// Record the packs this backup may reuse chunks from, so they are not
+	// pruned while the backup is in progress.
+	refs := chunkPackRefs{Packs: []string{}}
+	for _, pack := range packs {
+		refs.Packs = append(refs.Packs, pack.Name())
+	}
+	if err := writeChunkPackFile(ctx, cw.pack, chunkPackRefsFile, &refs); err != nil {
+		cw.abort(ctx, logger)
+		return nil, vterrors.Wrapf(err, "can't write %v of chunk pack %v/%v", chunkPackRefsFile, cw.dir, name)
+	}
+	logger.Infof("Deduplicating backup against %v chunks in %v packs of %v", len(cw.existing), len(packs), cw.dir)
+	return cw, nil
+}
+
+// reserve returns true if the chunk must be stored by the caller, and false if
+// it is already stored, or being stored by another file of the backup.
+func (cw *chunkWriter) reserve(chunk string) bool {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	if pack, ok := cw.existing[chunk]; ok {
+		cw.used[pack] = true
+		return false
+	}
+	if cw.added[chunk] {
+		return false
+	}
+	cw.added[chunk] = true
+	cw.used[cw.pack.Name()] = true
+	return true
+}
+
+// writeChunk stores a chunk in the pack of the backup, compressed if enabled.
+func (cw *chunkWriter) writeChunk(ctx context.Context, logger logutil.Logger, chunk string, data []byte) (finalErr error) {
+	wc, err := cw.pack.AddFile(ctx, chunk, int64(len(data)))
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot add chunk %v", chunk)
+	}
+	defer func() {
+		if closeErr := wc.Close(); finalErr == nil {
+			finalErr = closeErr
+		}
+	}()
+
+	var writer io.Writer = newLimitedWriter(ctx, wc, nil)
+	var compressor io.WriteCloser
+	if backupStorageCompress {
+		if ExternalCompressorCmd != "" {
+			compressor, err = newExternalCompressor(ctx, ExternalCompressorCmd, writer, logger)
+		} else {
+			compressor, err = newBuiltinCompressor(CompressionEngineName, writer, logger)
+		}
+		if err != nil {
+			return vterrors.Wrap(err, "can't create compressor")
+		}
+		writer = compressor
+	}
+	if _, err := writer.Write(data); err != nil {
+		return vterrors.Wrapf(err, "cannot write chunk %v", chunk)
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return vterrors.Wrap(err, "cannot close compressor")
+		}
+	}
+	return nil
+}
+
+// finish writes the INDEX of the pack of the backup, checks that the packs
+// the backup reuses chunks from still exist, and returns the sorted list of
+// the packs holding chunks of the backup.
+func (cw *chunkWriter) finish(ctx context.Context) ([]string, error) {
+	defer cw.bs.Close()
+
+	index := chunkPackIndex{Chunks: []string{}}
+	for chunk := range cw.added {
+		index.Chunks = append(index.Chunks, chunk)
+	}
+	sort.Strings(index.Chunks)
+	if err := writeChunkPackFile(ctx, cw.pack, chunkPackIndexFile, &index); err != nil {
+		return nil, vterrors.Wrapf(err, "can't write %v of chunk pack %v/%v", chunkPackIndexFile, cw.dir, cw.pack.Name())
+	}
+	if err := cw.pack.EndBackup(ctx); err != nil {
+		return nil, vterrors.Wrapf(err, "can't end chunk pack %v/%v", cw.dir, cw.pack.Name())
+	}
+
+	packs, err := cw.bs.ListBackups(ctx, cw.dir)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't list chunk packs in %v", cw.dir)
+	}
+	stored := map[string]bool{}
+	for _, pack := range packs {
+		stored[pack.Name()] = true
+	}
+	var used []string
+	for pack := range cw.used {
+		if !stored[pack] {
+			return nil, vterrors.Errorf(vtrpc.Code_ABORTED, "chunk pack %v/%v was removed during the backup", cw.dir, pack)
+		}
+		used = append(used, pack)
+	}
+	sort.Strings(used)
+	return used, nil
+}
+
+// abort removes the pack of a failed backup.
+func (cw *chunkWriter) abort(ctx context.Context, logger logutil.Logger) {
+	defer cw.bs.Close()
+	if err := cw.pack.AbortBackup(ctx); err != nil {
+		logger.Errorf2(err, "failed to abort chunk pack %v/%v", cw.dir, cw.pack.Name())
+	}
+}
+
+// backupFileChunks backs up an individual file to the chunk store, and records
+// its chunks, hash and size in the FileEntry.
+func (be *BuiltinBackupEngine) backupFileChunks(ctx context.Context, params BackupParams, cw *chunkWriter, fe *FileEntry, progress *backupProgress) error {
+	source, err := fe.open(params.Cnf, true)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	fi, err := source.Stat()
+	if err != nil {
+		return err
+	}
+
+	params.Logger.Infof("Backing up file in chunks: %v", fe.Name)
+	br := newBackupReader(fe.Name, fi.Size(), newLimitedReader(ctx, source, newThrottleCheck(params), progress))
+	go br.ReportProgress(builtinBackupProgress, params.Logger)
+	defer br.Close()
+
+	fe.Chunks = []string{}
+	buf := make([]byte, BuiltinBackupDedupChunkSize)
+	var size int64
+	var stored int
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := io.ReadFull(br, buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return vterrors.Wrap(err, "cannot read data")
+		}
+		sum := sha256.Sum256(buf[:n])
+		chunk := hex.EncodeToString(sum[:])
+		if cw.reserve(chunk) {
+			if err := cw.writeChunk(ctx, params.Logger, chunk, buf[:n]); err != nil {
+				return err
+			}
+			stored++
+		}
+		fe.Chunks = append(fe.Chunks, chunk)
+		size += int64(n)
+		if err == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+	params.Logger.Infof("Backed up file %v: stored %v of %v chunks", fe.Name, stored, len(fe.Chunks))
+
+	// The hash of a file stored in chunks is the hash of its original
+	// contents.
+	fe.Hash = br.HashString()
+	fe.Size = size
+	return nil
+}
+
+// chunkReader reads the chunks of a deduplicated backup.
+type chunkReader struct {
+	dir    string
+	packs  map[string]backupstorage.BackupHandle
+	chunks map[string]string
+}
+
+// newChunkReader loads the index of the chunk packs of a deduplicated backup.
+func newChunkReader(ctx context.Context, logger logutil.Logger, bm *builtinBackupManifest) (*chunkReader, error) {
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer bs.Close()
+
+	handles, err := bs.ListBackups(ctx, bm.ChunkDir)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't list chunk packs in %v", bm.ChunkDir)
+	}
+	byName := map[string]backupstorage.BackupHandle{}
+	for _, pack := range handles {
+		byName[pack.Name()] = pack
+	}
+	cr := &chunkReader{
+		dir:   bm.ChunkDir,
+		packs: map[string]backupstorage.BackupHandle{},
+	}
+	var packs []backupstorage.BackupHandle
+	for _, name := range bm.ChunkPacks {
+		pack, ok := byName[name]
+		if !ok {
+			return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "chunk pack %v/%v not found", bm.ChunkDir, name)
+		}
+		cr.packs[name] = pack
+		packs = append(packs, pack)
+	}
+	cr.chunks = readChunkIndexes(ctx, logger, packs)
+	return cr, nil
+}
+
+// readFile reads an individual file from its chunks, writes its original
+// contents to dst, and checks each chunk, and the hash and size of the file.
+func (cr *chunkReader) readFile(ctx context.Context, logger logutil.Logger, fe *FileEntry, bm builtinBackupManifest, dst io.Writer) error {
+	crc := crc32.NewIEEE()
+	var size int64
+	for _, chunk := range fe.Chunks {
+		data, err := cr.readChunk(ctx, logger, chunk, bm)
+		if err != nil {
+			return err
+		}
+		_, _ = crc.Write(data)
+		if _, err := dst.Write(data); err != nil {
+			return vterrors.Wrap(err, "failed to copy file contents")
+		}
+		size += int64(len(data))
+	}
+
+	if hash := hex.EncodeToString(crc.Sum(nil)); hash != fe.Hash {
+		return vterrors.Errorf(vtrpc.Code_INTERNAL, "hash mismatch for %v, got %v expected %v", fe.Name, hash, fe.Hash)
+	}
+	if size != fe.Size {
+		return vterrors.Errorf(vtrpc.Code_INTERNAL, "size mismatch for %v, got %v expected %v", fe.Name, size, fe.Size)
+	}
+	return nil
+}
+
+func (cr *chunkReader) readChunk(ctx context.Context, logger logutil.Logger, chunk string, bm builtinBackupManifest) (data []byte, finalErr error) {
+	name, ok := cr.chunks[chunk]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "chunk %v not found in the packs of %v", chunk, cr.dir)
+	}
+	source, err := cr.packs[name].ReadFile(ctx, chunk)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't open chunk %v of pack %v/%v for reading", chunk, cr.dir, name)
+	}
+	defer source.Close()
+
+	var reader io.Reader = newLimitedReader(ctx, source, nil, nil)
+	if !bm.SkipCompress {
+		var decompressor io.ReadCloser
+		if bm.CompressionEngine == ExternalCompressor {
+			if ExternalDecompressorCmd == "" {
+				return nil, fmt.Errorf("%w value: %q", errUnsupportedDeCompressionEngine, ExternalCompressor)
+			}
+			decompressor, err = newExternalDecompressor(ctx, ExternalDecompressorCmd, reader, logger)
+		} else {
+			decompressor, err = newBuiltinDecompressor(bm.CompressionEngine, reader, logger)
+		}
+		if err != nil {
+			return nil, vterrors.Wrap(err, "can't create decompressor")
+		}
+		defer func() {
+			if cerr := decompressor.Close(); cerr != nil && finalErr == nil {
+				finalErr = vterrors.Wrap(cerr, "failed to close decompressor")
+			}
+		}()
+		reader = decompressor
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, reader); err != nil {
+		return nil, vterrors.Wrapf(err, "failed to read chunk %v", chunk)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	if hex.EncodeToString(sum[:]) != chunk {
+		return nil, vterrors.Errorf(vtrpc.Code_INTERNAL, "chunk %v of pack %v/%v is corrupt", chunk, cr.dir, name)
+	}
+	return buf.Bytes(), nil
+}
+
+// PruneBackupChunks removes the chunk packs of the deduplicated backups in
+// backupDir which are no longer referenced: the packs which no complete
+// backup reuses chunks from, and which no backup in progress may reuse
+// chunks from. A backup without a MANIFEST is in progress for
+// BuiltinBackupDedupAbandonAfter after the time in its name; past that, it
+// is considered abandoned.
+func PruneBackupChunks(ctx context.Context, logger logutil.Logger, bs backupstorage.BackupStorage, backupDir string) error {
+	chunkDir := backupChunkDir(backupDir)
+	packs, err := bs.ListBackups(ctx, chunkDir)
+	if err != nil {
+		return vterrors.Wrapf(err, "can't list chunk packs in %v", chunkDir)
+	}
+	if len(packs) == 0 {
+		return nil
+	}
+	backups, err := bs.ListBackups(ctx, backupDir)
+	if err != nil {
+		return vterrors.Wrapf(err, "can't list backups in %v", backupDir)
+	}
+
+	// Count the references to each pack.
+	refs := map[string]int{}
+	inProgress := map[string]bool{}
+	for _, backup := range backups {
+		var bm builtinBackupManifest
+		if err := getBackupManifestInto(ctx, backup, &bm); err != nil {
+			if isAbandonedBackup(backup.Name(), time.Now()) {
+				logger.Infof("Backup %v/%v has no MANIFEST and is older than %v, considering it abandoned", backupDir, backup.Name(), BuiltinBackupDedupAbandonAfter)
+				continue
+			}
+			// The backup is in progress, or failed recently. Keep its pack,
+			// and the packs it may reuse chunks from.
+			inProgress[backup.Name()] = true
+			continue
+		}
+		for _, pack := range bm.ChunkPacks {
+			refs[pack]++
+		}
+	}
+	for _, pack := range packs {
+		if !inProgress[pack.Name()] {
+			continue
+		}
+		refs[pack.Name()]++
+		var packRefs chunkPackRefs
+		if err := readChunkPackFile(ctx, pack, chunkPackRefsFile, &packRefs); err != nil {
+			// The backup may be about to reuse any chunk.
+			logger.Infof("Not pruning chunk packs in %v: can't read %v of chunk pack %v: %v", chunkDir, chunkPackRefsFile, pack.Name(), err)
+			return nil
+		}
+		for _, name := range packRefs.Packs {
+			refs[name]++
+		}
+	}
+
+	var removed int
+	for _, pack := range packs {
+		if refs[pack.Name()] > 0 {
+			continue
+		}
+		logger.Infof("Removing chunk pack %v/%v, which is not referenced by any backup", chunkDir, pack.Name())
+		if err := bs.RemoveBackup(ctx, chunkDir, pack.Name()); err != nil {
+			return vterrors.Wrapf(err, "can't remove chunk pack %v/%v", chunkDir, pack.Name())
+		}
+		removed++
+	}
+	logger.Infof("Removed %v of %v chunk packs in %v", removed, len(packs), chunkDir)
+	return nil
+}
+
+// isAbandonedBackup returns true if the time in the name of a backup without
+// a MANIFEST is older than BuiltinBackupDedupAbandonAfter. A backup whose
+// name has no time is never considered abandoned.
+func isAbandonedBackup(name string, now time.Time) bool {
+	backupTime, _, err := ParseBackupName("", name)
+	if err != nil || backupTime == nil {
+		return false
+	}
+	return now.Sub(*backupTime) > BuiltinBackupDedupAbandonAfter
+}
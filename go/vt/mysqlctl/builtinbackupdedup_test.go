@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+)
+
+func TestDedupBackup(t *testing.T) {
+	root := t.TempDir()
+	newCnf := func(dir string) *Mycnf {
+		return &Mycnf{
+			InnodbDataHomeDir:     path.Join(root, dir, "innodb_data"),
+			InnodbLogGroupHomeDir: path.Join(root, dir, "innodb_log"),
+			DataDir:               path.Join(root, dir, "data"),
+		}
+	}
+	cnf := newCnf("source")
+	files := map[string]string{
+		path.Join(cnf.InnodbDataHomeDir, "ibdata1"):         strings.Repeat("a", 4096) + strings.Repeat("b", 4096),
+		path.Join(cnf.InnodbLogGroupHomeDir, "ib_logfile0"): "innodb log",
+		path.Join(cnf.DataDir, "vt_db", "t1.ibd"):           strings.Repeat("a", 4096) + strings.Repeat("c", 1000),
+		path.Join(cnf.DataDir, "vt_db", "empty.ibd"):        "",
+	}
+	writeFiles := func() {
+		for name, contents := range files {
+			require.NoError(t, os.MkdirAll(path.Dir(name), os.ModePerm))
+			require.NoError(t, os.WriteFile(name, []byte(contents), os.ModePerm))
+		}
+	}
+	writeFiles()
+
+	defer func(root, implementation string, dedup bool, chunkSize int) {
+		filebackupstorage.FileBackupStorageRoot = root
+		backupstorage.BackupStorageImplementation = implementation
+		BuiltinBackupDedup, BuiltinBackupDedupChunkSize = dedup, chunkSize
+	}(filebackupstorage.FileBackupStorageRoot, backupstorage.BackupStorageImplementation, BuiltinBackupDedup, BuiltinBackupDedupChunkSize)
+	filebackupstorage.FileBackupStorageRoot = path.Join(root, "backups")
+	backupstorage.BackupStorageImplementation = "file"
+	BuiltinBackupDedup, BuiltinBackupDedupChunkSize = true, 4096
+
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+	be := &BuiltinBackupEngine{}
+	bs := &filebackupstorage.FileBackupStorage{}
+	chunkDir := path.Join(filebackupstorage.FileBackupStorageRoot, "ks/0.chunks")
+
+	backup := func(name string) backupstorage.BackupHandle {
+		bh, err := bs.StartBackup(ctx, "ks/0", name)
+		require.NoError(t, err)
+		err = be.backupFiles(ctx, BackupParams{Cnf: cnf, Logger: logger, Concurrency: 2}, bh, mysql.Position{}, mysql.Position{}, mysql.Position{}, nil, "")
+		require.NoError(t, err)
+		require.NoError(t, bh.EndBackup(ctx))
+		bhs, err := bs.ListBackups(ctx, "ks/0")
+		require.NoError(t, err)
+		bh = bhs[len(bhs)-1]
+		require.Equal(t, name, bh.Name())
+		return bh
+	}
+	chunkFiles := func(pack string) []string {
+		entries, err := os.ReadDir(path.Join(chunkDir, pack))
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range entries {
+			if entry.Name() != chunkPackIndexFile && entry.Name() != chunkPackRefsFile {
+				names = append(names, entry.Name())
+			}
+		}
+		return names
+	}
+	packs := func() []string {
+		handles, err := bs.ListBackups(ctx, "ks/0.chunks")
+		require.NoError(t, err)
+		var names []string
+		for _, pack := range handles {
+			names = append(names, pack.Name())
+		}
+		return names
+	}
+
+	// The first backup stores each distinct chunk once: "a", "b", "c" and
+	// the log. Only the MANIFEST is stored with the backup.
+	bh1 := backup("backup1")
+	assert.Len(t, chunkFiles("backup1"), 4)
+	entries, err := os.ReadDir(path.Join(filebackupstorage.FileBackupStorageRoot, "ks/0", "backup1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	var bm builtinBackupManifest
+	require.NoError(t, getBackupManifestInto(ctx, bh1, &bm))
+	assert.Equal(t, "ks/0.chunks", bm.ChunkDir)
+	assert.Equal(t, []string{"backup1"}, bm.ChunkPacks)
+
+	// The second backup only stores the changed chunk.
+	files[path.Join(cnf.DataDir, "vt_db", "t1.ibd")] = strings.Repeat("a", 4096) + strings.Repeat("d", 1000)
+	writeFiles()
+	bh2 := backup("backup2")
+	assert.Len(t, chunkFiles("backup2"), 1)
+	require.NoError(t, getBackupManifestInto(ctx, bh2, &bm))
+	assert.Equal(t, []string{"backup1", "backup2"}, bm.ChunkPacks)
+
+	// Both backups can be verified and restored.
+	params := VerifyParams{Logger: logger, Concurrency: 2}
+	_, err = VerifyBackup(ctx, params, bh1)
+	require.NoError(t, err)
+	_, err = VerifyBackup(ctx, params, bh2)
+	require.NoError(t, err)
+
+	restoreCnf := newCnf("restore")
+	_, err = be.restoreFiles(ctx, RestoreParams{Cnf: restoreCnf, Logger: logger, Concurrency: 2}, bh2, bm)
+	require.NoError(t, err)
+	for name, contents := range files {
+		restored, err := os.ReadFile(strings.Replace(name, path.Join(root, "source"), path.Join(root, "restore"), 1))
+		require.NoError(t, err)
+		assert.Equal(t, contents, string(restored))
+	}
+
+	// Corrupt chunks are detected.
+	corrupt := path.Join(chunkDir, "backup2", chunkFiles("backup2")[0])
+	data, err := os.ReadFile(corrupt)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(corrupt, []byte("corrupt"), os.ModePerm))
+	_, err = VerifyBackup(ctx, params, bh2)
+	assert.ErrorContains(t, err, "can't verify file")
+	require.NoError(t, os.WriteFile(corrupt, data, os.ModePerm))
+
+	// Removing the first backup keeps its pack, which the second backup uses.
+	require.NoError(t, bs.RemoveBackup(ctx, "ks/0", "backup1"))
+	require.NoError(t, PruneBackupChunks(ctx, logger, bs, "ks/0"))
+	assert.Equal(t, []string{"backup1", "backup2"}, packs())
+
+	// A backup in progress keeps the packs it may reuse chunks from.
+	bh3, err := bs.StartBackup(ctx, "ks/0", "backup3")
+	require.NoError(t, err)
+	cw, err := newChunkWriter(ctx, logger, "ks/0", "backup3")
+	require.NoError(t, err)
+	require.NoError(t, bs.RemoveBackup(ctx, "ks/0", "backup2"))
+	require.NoError(t, PruneBackupChunks(ctx, logger, bs, "ks/0"))
+	assert.Equal(t, []string{"backup1", "backup2", "backup3"}, packs())
+
+	// Once it is gone, all the packs are removed.
+	cw.abort(ctx, logger)
+	require.NoError(t, bh3.AbortBackup(ctx))
+	require.NoError(t, PruneBackupChunks(ctx, logger, bs, "ks/0"))
+	assert.Empty(t, packs())
+
+	// A backup which failed without cleaning up keeps its packs while it may
+	// still be in progress, and releases them once it is abandoned.
+	defer func(abandonAfter time.Duration) {
+		BuiltinBackupDedupAbandonAfter = abandonAfter
+	}(BuiltinBackupDedupAbandonAfter)
+	failed := time.Now().Add(-time.Hour).UTC().Format(BackupTimestampFormat) + ".zone1-0000000100"
+	_, err = bs.StartBackup(ctx, "ks/0", failed)
+	require.NoError(t, err)
+	_, err = newChunkWriter(ctx, logger, "ks/0", failed)
+	require.NoError(t, err)
+	require.NoError(t, PruneBackupChunks(ctx, logger, bs, "ks/0"))
+	assert.Equal(t, []string{failed}, packs())
+
+	BuiltinBackupDedupAbandonAfter = time.Minute
+	require.NoError(t, PruneBackupChunks(ctx, logger, bs, "ks/0"))
+	assert.Empty(t, packs())
+}
+
+func TestIsAbandonedBackup(t *testing.T) {
+	now := time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)
+	assert.False(t, isAbandonedBackup("2023-01-02.110000.zone1-0000000100", now))
+	assert.True(t, isAbandonedBackup("2023-01-01.110000.zone1-0000000100", now))
+	assert.False(t, isAbandonedBackup("backup1", now))
+	assert.False(t, isAbandonedBackup("notatime.110000.zone1-0000000100", now))
+}
+
+func TestDedupBackupNotSupported(t *testing.T) {
+	defer func(hook, provider string) {
+		backupStorageHook, BackupEncryptionKeyProvider = hook, provider
+	}(backupStorageHook, BackupEncryptionKeyProvider)
+
+	ctx := context.Background()
+	logger := logutil.NewMemoryLogger()
+
+	backupStorageHook, BackupEncryptionKeyProvider = "hook", ""
+	_, err := newChunkWriter(ctx, logger, "ks/0", "backup")
+	assert.ErrorContains(t, err, "cannot be used with --backup_storage_hook")
+
+	backupStorageHook, BackupEncryptionKeyProvider = "", KeyfileKeyProvider
+	_, err = newChunkWriter(ctx, logger, "ks/0", "backup")
+	assert.ErrorContains(t, err, "cannot be used with --backup-encryption-key-provider")
+}
@@ -96,6 +96,17 @@ type builtinBackupManifest struct {
 	// false for backups that were created before the field existed, and those
 	// backups all had compression enabled.
 	SkipCompress bool
+
+	// Encryption describes how the files were encrypted, if they were.
+	Encryption *BackupEncryption `json:",omitempty"`
+
+	// ChunkDir is the directory of the chunk store holding the files, if the
+	// backup is deduplicated.
+	ChunkDir string `json:",omitempty"`
+
+	// ChunkPacks lists the packs of the chunk store holding the chunks of the
+	// files, if the backup is deduplicated.
+	ChunkPacks []string `json:",omitempty"`
 }
 
 // FileEntry is one file to backup
@@ -110,13 +121,22 @@ type FileEntry struct {
 	// Name is the file name, relative to Base
 	Name string
 
-	// Hash is the hash of the final data (transformed and
-	// compressed if specified) stored in the BackupStorage.
+	// Hash is the hash of the final data (transformed, compressed and
+	// encrypted if specified) stored in the BackupStorage. For files stored in
+	// chunks, it is the hash of the original file.
 	Hash string
 
+	// Size is the size of the original file. It is zero for empty files, and
+	// for backups taken before it was recorded.
+	Size int64 `json:",omitempty"`
+
 	// ParentPath is an optional prefix to the Base path. If empty, it is ignored. Useful
 	// for writing files in a temporary directory
 	ParentPath string
+
+	// Chunks lists the sha256 of the chunks of the file, in order, if the
+	// backup is deduplicated.
+	Chunks []string `json:",omitempty"`
 }
 
 func init() {
@@ -472,18 +492,40 @@ func (be *BuiltinBackupEngine) backupFiles(
 ) (finalErr error) {
 
 	// Get the files to backup.
-	// We don't care about totalSize because we add each file separately.
 	var fes []FileEntry
+	var totalSize int64
 	var err error
 	if isIncrementalBackup(params) {
-		fes, _, err = binlogFilesToBackup(params.Cnf, binlogFiles)
+		fes, totalSize, err = binlogFilesToBackup(params.Cnf, binlogFiles)
 	} else {
-		fes, _, err = findFilesToBackup(params.Cnf)
+		fes, totalSize, err = findFilesToBackup(params.Cnf)
 	}
 	if err != nil {
 		return vterrors.Wrap(err, "can't find files to backup")
 	}
 	params.Logger.Infof("found %v files to backup", len(fes))
+	progress := startBackupProgress(backupOperation, totalSize)
+	defer progress.finish()
+
+	// Store the files of full backups in the chunk store, if enabled.
+	var chunks *chunkWriter
+	if BuiltinBackupDedup && !isIncrementalBackup(params) {
+		if chunks, err = newChunkWriter(ctx, params.Logger, bh.Directory(), bh.Name()); err != nil {
+			return vterrors.Wrap(err, "can't set up backup deduplication")
+		}
+	}
+
+	// Generate the data key with which all files are encrypted, if enabled.
+	encryption, dataKey, err := newBackupEncryption(ctx)
+	if err != nil {
+		if chunks != nil {
+			chunks.abort(ctx, params.Logger)
+		}
+		return vterrors.Wrap(err, "can't set up backup encryption")
+	}
+	if encryption != nil {
+		params.Logger.Infof("Encrypting backup using key provider %q (key %v)", encryption.KeyProvider, encryption.KeyID)
+	}
 
 	// Backup with the provided concurrency.
 	sema := sync2.NewSemaphore(params.Concurrency, 0)
@@ -502,8 +544,12 @@ func (be *BuiltinBackupEngine) backupFiles(
 			}
 
 			// Backup the individual file.
+			if chunks != nil {
+				bh.RecordError(be.backupFileChunks(ctx, params, chunks, &fes[i], progress))
+				return
+			}
 			name := fmt.Sprintf("%v", i)
-			bh.RecordError(be.backupFile(ctx, params, bh, &fes[i], name))
+			bh.RecordError(be.backupFile(ctx, params, bh, &fes[i], name, dataKey, progress))
 		}(i)
 	}
 
@@ -518,9 +564,23 @@ func (be *BuiltinBackupEngine) backupFiles(
 	// error were encountered
 	// [here](https://github.com/vitessio/vitess/blob/d26b6c7975b12a87364e471e2e2dfa4e253c2a5b/go/vt/mysqlctl/s3backupstorage/s3.go#L139-L142).
 	if bh.HasErrors() {
+		if chunks != nil {
+			chunks.abort(ctx, params.Logger)
+		}
 		return bh.Error()
 	}
 
+	// Complete the chunk pack, which must be done before the MANIFEST is
+	// written.
+	var chunkDir string
+	var chunkPacks []string
+	if chunks != nil {
+		if chunkPacks, err = chunks.finish(ctx); err != nil {
+			return err
+		}
+		chunkDir = chunks.dir
+	}
+
 	// open the MANIFEST
 	wc, err := bh.AddFile(ctx, backupManifestFileName, backupstorage.FileSizeUnknown)
 	if err != nil {
@@ -554,6 +614,9 @@ func (be *BuiltinBackupEngine) backupFiles(
 		TransformHook:     backupStorageHook,
 		SkipCompress:      !backupStorageCompress,
 		CompressionEngine: CompressionEngineName,
+		Encryption:        encryption,
+		ChunkDir:          chunkDir,
+		ChunkPacks:        chunkPacks,
 	}
 	data, err := json.MarshalIndent(bm, "", "  ")
 	if err != nil {
@@ -650,7 +713,8 @@ func (bp *backupPipe) ReportProgress(period time.Duration, logger logutil.Logger
 }
 
 // backupFile backs up an individual file.
-func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, fe *FileEntry, name string) (finalErr error) {
+// If dataKey is set, the file is encrypted with it.
+func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, fe *FileEntry, name string, dataKey []byte, progress *backupProgress) (finalErr error) {
 	// Open the source file for reading.
 	source, err := fe.open(params.Cnf, true)
 	if err != nil {
@@ -680,12 +744,23 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 		}
 	}(name, fe.Name)
 
-	bw := newBackupWriter(fe.Name, fi.Size(), wc)
-	br := newBackupReader(fe.Name, fi.Size(), source)
+	bw := newBackupWriter(fe.Name, fi.Size(), newLimitedWriter(ctx, wc, nil))
+	br := newBackupReader(fe.Name, fi.Size(), newLimitedReader(ctx, source, newThrottleCheck(params), progress))
 	go br.ReportProgress(builtinBackupProgress, params.Logger)
 
 	var writer io.Writer = bw
 
+	// Create the encryption pipe, if necessary. It is the last stage before
+	// the storage, so that nothing is stored in plaintext.
+	var encryptor io.WriteCloser
+	if dataKey != nil {
+		encryptor, err = newEncryptingWriter(dataKey, writer)
+		if err != nil {
+			return vterrors.Wrap(err, "can't create encryptor")
+		}
+		writer = encryptor
+	}
+
 	// Create the external write pipe, if any.
 	var pipe io.WriteCloser
 	var wait hook.WaitFunc
@@ -715,7 +790,7 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 
 	// Copy from the source file to writer (optional gzip,
 	// optional pipe, tee, output file and hasher).
-	_, err = io.Copy(writer, br)
+	size, err := io.Copy(writer, br)
 	if err != nil {
 		return vterrors.Wrap(err, "cannot copy data")
 	}
@@ -741,6 +816,13 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 		}
 	}
 
+	// Close the encryptor to seal the last chunk.
+	if encryptor != nil {
+		if err := encryptor.Close(); err != nil {
+			return vterrors.Wrap(err, "cannot close encryptor")
+		}
+	}
+
 	// Close the backupPipe to finish writing on destination.
 	if err = bw.Close(); err != nil {
 		return vterrors.Wrapf(err, "cannot flush destination: %v", name)
@@ -750,8 +832,9 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 		return vterrors.Wrap(err, "failed to close the source reader")
 	}
 
-	// Save the hash.
+	// Save the hash and size.
 	fe.Hash = bw.HashString()
+	fe.Size = size
 	return nil
 }
 
@@ -850,6 +933,23 @@ func (be *BuiltinBackupEngine) restoreFiles(ctx context.Context, params RestoreP
 		}()
 	}
 
+	// Unwrap the data key with which all files were encrypted, if they were.
+	var dataKey []byte
+	if bm.Encryption != nil {
+		params.Logger.Infof("Decrypting backup using key provider %q (key %v)", bm.Encryption.KeyProvider, bm.Encryption.KeyID)
+		if dataKey, err = bm.Encryption.dataKey(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	// Load the index of the chunk store, if the backup is deduplicated.
+	var chunks *chunkReader
+	if bm.ChunkDir != "" {
+		if chunks, err = newChunkReader(ctx, params.Logger, &bm); err != nil {
+			return "", err
+		}
+	}
+
 	if bm.Incremental {
 		createdDir, err = os.MkdirTemp("", "restore-incremental-*")
 		if err != nil {
@@ -857,6 +957,12 @@ func (be *BuiltinBackupEngine) restoreFiles(ctx context.Context, params RestoreP
 		}
 	}
 	fes := bm.FileEntries
+	var totalSize int64
+	for _, fe := range fes {
+		totalSize += fe.Size
+	}
+	progress := startBackupProgress(restoreOperation, totalSize)
+	defer progress.finish()
 	sema := sync2.NewSemaphore(params.Concurrency, 0)
 	rec := concurrency.AllErrorRecorder{}
 	wg := sync.WaitGroup{}
@@ -878,7 +984,7 @@ func (be *BuiltinBackupEngine) restoreFiles(ctx context.Context, params RestoreP
 			// And restore the file.
 			name := fmt.Sprintf("%v", i)
 			params.Logger.Infof("Copying file %v: %v", name, fe.Name)
-			err := be.restoreFile(ctx, params, bh, fe, bm, name)
+			err := be.restoreFile(ctx, params, bh, fe, bm, name, dataKey, chunks, progress)
 			if err != nil {
 				rec.RecordError(vterrors.Wrapf(err, "can't restore file %v to %v", name, fe.Name))
 			}
@@ -889,14 +995,9 @@ func (be *BuiltinBackupEngine) restoreFiles(ctx context.Context, params RestoreP
 }
 
 // restoreFile restores an individual file.
-func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle, fe *FileEntry, bm builtinBackupManifest, name string) (finalErr error) {
-	// Open the source file for reading.
-	source, err := bh.ReadFile(ctx, name)
-	if err != nil {
-		return vterrors.Wrap(err, "can't open source file for reading")
-	}
-	defer source.Close()
-
+// If dataKey is set, the file is decrypted with it. If chunks is set, the file
+// is read from the chunk store.
+func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle, fe *FileEntry, bm builtinBackupManifest, name string, dataKey []byte, chunks *chunkReader, progress *backupProgress) (finalErr error) {
 	// Open the destination file for writing.
 	dstFile, err := fe.open(params.Cnf, false)
 	if err != nil {
@@ -913,17 +1014,53 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 		}
 	}()
 
-	bp := newBackupReader(name, 0, source)
-	go bp.ReportProgress(builtinBackupProgress, params.Logger)
+	dst := bufio.NewWriterSize(newLimitedWriter(ctx, dstFile, progress), writerBufferSize)
+	if chunks != nil {
+		err = chunks.readFile(ctx, params.Logger, fe, bm, dst)
+	} else {
+		err = be.readFile(ctx, params.Logger, params.HookExtraEnv, bh, fe, bm, name, dataKey, dst)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Flush the buffer.
+	if err := dst.Flush(); err != nil {
+		return vterrors.Wrap(err, "failed to flush destination buffer")
+	}
+
+	return nil
+}
+
+// readFile reads an individual file from the backup, writes its original
+// contents to dst, and checks its hash and size against the MANIFEST.
+// If dataKey is set, the file is decrypted with it.
+func (be *BuiltinBackupEngine) readFile(ctx context.Context, logger logutil.Logger, hookExtraEnv map[string]string, bh backupstorage.BackupHandle, fe *FileEntry, bm builtinBackupManifest, name string, dataKey []byte, dst io.Writer) (finalErr error) {
+	// Open the source file for reading.
+	source, err := bh.ReadFile(ctx, name)
+	if err != nil {
+		return vterrors.Wrap(err, "can't open source file for reading")
+	}
+	defer source.Close()
+
+	bp := newBackupReader(name, 0, newLimitedReader(ctx, source, nil, nil))
+	go bp.ReportProgress(builtinBackupProgress, logger)
 
-	dst := bufio.NewWriterSize(dstFile, writerBufferSize)
 	var reader io.Reader = bp
 
+	// Create the decryptor if needed.
+	if dataKey != nil {
+		reader, err = newDecryptingReader(dataKey, reader)
+		if err != nil {
+			return vterrors.Wrap(err, "can't create decryptor")
+		}
+	}
+
 	// Create the external read pipe, if any.
 	var wait hook.WaitFunc
 	if bm.TransformHook != "" {
 		h := hook.NewHook(bm.TransformHook, []string{"-operation", "read"})
-		h.ExtraEnv = params.HookExtraEnv
+		h.ExtraEnv = hookExtraEnv
 		reader, wait, _, err = h.ExecuteAsReadPipe(reader)
 		if err != nil {
 			return vterrors.Wrapf(err, "'%v' hook returned error", bm.TransformHook)
@@ -941,15 +1078,15 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 		if ExternalDecompressorCmd != "" {
 			if deCompressionEngine == ExternalCompressor {
 				deCompressionEngine = ExternalDecompressorCmd
-				decompressor, err = newExternalDecompressor(ctx, deCompressionEngine, reader, params.Logger)
+				decompressor, err = newExternalDecompressor(ctx, deCompressionEngine, reader, logger)
 			} else {
-				decompressor, err = newBuiltinDecompressor(deCompressionEngine, reader, params.Logger)
+				decompressor, err = newBuiltinDecompressor(deCompressionEngine, reader, logger)
 			}
 		} else {
 			if deCompressionEngine == ExternalCompressor {
 				return fmt.Errorf("%w value: %q", errUnsupportedDeCompressionEngine, ExternalCompressor)
 			}
-			decompressor, err = newBuiltinDecompressor(deCompressionEngine, reader, params.Logger)
+			decompressor, err = newBuiltinDecompressor(deCompressionEngine, reader, logger)
 		}
 		if err != nil {
 			return vterrors.Wrap(err, "can't create decompressor")
@@ -957,7 +1094,7 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 
 		defer func() {
 			if cerr := decompressor.Close(); cerr != nil {
-				params.Logger.Errorf("failed to close decompressor: %v", cerr)
+				logger.Errorf("failed to close decompressor: %v", cerr)
 				if finalErr != nil {
 					// We already have an error, just log this one.
 					log.Errorf("failed to close decompressor %v: %v", name, cerr)
@@ -970,7 +1107,8 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 	}
 
 	// Copy the data. Will also write to the hasher.
-	if _, err = io.Copy(dst, reader); err != nil {
+	size, err := io.Copy(dst, reader)
+	if err != nil {
 		return vterrors.Wrap(err, "failed to copy file contents")
 	}
 
@@ -991,9 +1129,9 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 		return vterrors.Errorf(vtrpc.Code_INTERNAL, "hash mismatch for %v, got %v expected %v", fe.Name, hash, fe.Hash)
 	}
 
-	// Flush the buffer.
-	if err := dst.Flush(); err != nil {
-		return vterrors.Wrap(err, "failed to flush destination buffer")
+	// Check the size, which older backups did not record.
+	if fe.Size != 0 && size != fe.Size {
+		return vterrors.Errorf(vtrpc.Code_INTERNAL, "size mismatch for %v, got %v expected %v", fe.Name, size, fe.Size)
 	}
 
 	if err := bp.Close(); err != nil {
@@ -1003,6 +1141,69 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 	return nil
 }
 
+// VerifyBackup satisfies the BackupVerifier interface. It reads each file of
+// the backup, decrypting, transforming and decompressing it as a restore would,
+// and checks its hash and size against the MANIFEST.
+func (be *BuiltinBackupEngine) VerifyBackup(ctx context.Context, params VerifyParams, bh backupstorage.BackupHandle) error {
+	var bm builtinBackupManifest
+	if err := getBackupManifestInto(ctx, bh, &bm); err != nil {
+		return err
+	}
+	if bm.CompressionEngine == PargzipCompressor {
+		bm.CompressionEngine = PgzipCompressor
+	}
+
+	var dataKey []byte
+	if bm.Encryption != nil {
+		var err error
+		if dataKey, err = bm.Encryption.dataKey(ctx); err != nil {
+			return err
+		}
+	}
+
+	var chunks *chunkReader
+	if bm.ChunkDir != "" {
+		var err error
+		if chunks, err = newChunkReader(ctx, params.Logger, &bm); err != nil {
+			return err
+		}
+	}
+
+	params.Logger.Infof("Verify: reading %v files", len(bm.FileEntries))
+	fes := bm.FileEntries
+	sema := sync2.NewSemaphore(params.Concurrency, 0)
+	rec := concurrency.AllErrorRecorder{}
+	wg := sync.WaitGroup{}
+	for i := range fes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Wait until we are ready to go, skip if we already
+			// encountered an error.
+			sema.Acquire()
+			defer sema.Release()
+			if rec.HasErrors() {
+				return
+			}
+
+			fe := &fes[i]
+			name := fmt.Sprintf("%v", i)
+			var err error
+			if chunks != nil {
+				err = chunks.readFile(ctx, params.Logger, fe, bm, io.Discard)
+			} else {
+				err = be.readFile(ctx, params.Logger, params.HookExtraEnv, bh, fe, bm, name, dataKey, io.Discard)
+			}
+			if err != nil {
+				rec.RecordError(vterrors.Wrapf(err, "can't verify file %v (%v)", name, fe.Name))
+			}
+		}(i)
+	}
+	wg.Wait()
+	return rec.Error()
+}
+
 // ShouldDrainForBackup satisfies the BackupEngine interface
 // backup requires query service to be stopped, hence true
 func (be *BuiltinBackupEngine) ShouldDrainForBackup() bool {
@@ -0,0 +1,403 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	// KeyfileKeyProvider is the name of the key provider which wraps the data
+	// keys with a key read from a local file.
+	KeyfileKeyProvider = "keyfile"
+
+	// backupEncryptionCipher identifies the format of the encrypted files in
+	// the MANIFEST.
+	backupEncryptionCipher = "aes-256-gcm-chunked"
+
+	// encryptionVersion is the first byte of every encrypted file.
+	encryptionVersion byte = 1
+	// encryptionChunkSize is the size of the plaintext of each sealed chunk.
+	encryptionChunkSize = 64 * 1024
+	// encryptionNoncePrefixSize is the size of the random per-file part of the
+	// chunk nonces. The remaining bytes hold the chunk counter.
+	encryptionNoncePrefixSize = 8
+	// dataKeySize is the size of the per-backup AES-256 data key.
+	dataKeySize = 32
+)
+
+var (
+	// BackupEncryptionKeyProvider specifies which key provider wraps the
+	// per-backup data key. Backups are not encrypted if it is empty.
+	BackupEncryptionKeyProvider string
+	// BackupEncryptionKeyfile is the file holding the key of the "keyfile"
+	// key provider, as 64 hex characters.
+	BackupEncryptionKeyfile string
+
+	// backupKeyProviders holds the registered key providers, by name.
+	backupKeyProviders = map[string]BackupKeyProvider{
+		KeyfileKeyProvider: &keyfileBackupKeyProvider{},
+	}
+
+	errEncryptedBackupTruncated = errors.New("encrypted backup file is truncated")
+)
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vttablet", "vttestserver", "vtbackup", "vtctld"} {
+		servenv.OnParseFor(cmd, registerBackupEncryptionFlags)
+	}
+}
+
+func registerBackupEncryptionFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&BackupEncryptionKeyProvider, "backup-encryption-key-provider", BackupEncryptionKeyProvider, "key provider used to wrap the data key of encrypted builtin backups. Backups are not encrypted if empty. Supported values: 'keyfile'.")
+	fs.StringVar(&BackupEncryptionKeyfile, "backup-encryption-keyfile", BackupEncryptionKeyfile, "file holding the 256 bit key (64 hex characters) used by the 'keyfile' backup encryption key provider.")
+}
+
+// BackupKeyProvider wraps and unwraps the data keys with which backups are
+// encrypted. Implementations typically delegate to a key management service.
+type BackupKeyProvider interface {
+	// WrapKey encrypts the data key. It returns the wrapped key and an ID of
+	// the key used to wrap it, which are both recorded in the MANIFEST.
+	WrapKey(ctx context.Context, dataKey []byte) (wrappedKey []byte, keyID string, err error)
+
+	// UnwrapKey decrypts a data key wrapped by WrapKey.
+	UnwrapKey(ctx context.Context, wrappedKey []byte, keyID string) (dataKey []byte, err error)
+}
+
+// RegisterBackupKeyProvider registers a key provider, which can then be
+// selected with --backup-encryption-key-provider.
+func RegisterBackupKeyProvider(name string, provider BackupKeyProvider) {
+	if _, ok := backupKeyProviders[name]; ok {
+		panic(fmt.Sprintf("backup key provider %v already registered", name))
+	}
+	backupKeyProviders[name] = provider
+}
+
+func getBackupKeyProvider(name string) (BackupKeyProvider, error) {
+	provider, ok := backupKeyProviders[name]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown backup encryption key provider %q", name)
+	}
+	return provider, nil
+}
+
+// BackupEncryption describes how the files of a backup were encrypted.
+type BackupEncryption struct {
+	// Cipher is the format of the encrypted files.
+	Cipher string
+
+	// KeyProvider is the name of the key provider which wrapped the data key.
+	KeyProvider string
+
+	// KeyID identifies the key with which the data key was wrapped.
+	KeyID string
+
+	// WrappedKey is the data key, encrypted by the key provider.
+	WrappedKey []byte
+}
+
+// newBackupEncryption generates a data key for a new backup if encryption is
+// enabled, and returns it along with its description for the MANIFEST. It
+// returns nil if backups are not encrypted.
+func newBackupEncryption(ctx context.Context) (*BackupEncryption, []byte, error) {
+	if BackupEncryptionKeyProvider == "" {
+		return nil, nil, nil
+	}
+	provider, err := getBackupKeyProvider(BackupEncryptionKeyProvider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, nil, vterrors.Wrap(err, "cannot generate backup data key")
+	}
+	wrappedKey, keyID, err := provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, nil, vterrors.Wrapf(err, "cannot wrap backup data key with key provider %q", BackupEncryptionKeyProvider)
+	}
+	return &BackupEncryption{
+		Cipher:      backupEncryptionCipher,
+		KeyProvider: BackupEncryptionKeyProvider,
+		KeyID:       keyID,
+		WrappedKey:  wrappedKey,
+	}, dataKey, nil
+}
+
+// dataKey unwraps the data key of the backup with the key provider recorded
+// in the MANIFEST.
+func (enc *BackupEncryption) dataKey(ctx context.Context) ([]byte, error) {
+	if enc.Cipher != backupEncryptionCipher {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "unsupported backup encryption cipher %q", enc.Cipher)
+	}
+	provider, err := getBackupKeyProvider(enc.KeyProvider)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := provider.UnwrapKey(ctx, enc.WrappedKey, enc.KeyID)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot unwrap backup data key with key provider %q", enc.KeyProvider)
+	}
+	return dataKey, nil
+}
+
+// keyfileBackupKeyProvider wraps the data keys with AES-256-GCM, using the key
+// found in --backup-encryption-keyfile. The key ID is a fingerprint of that
+// key, so that restoring with another key fails with a clear error.
+type keyfileBackupKeyProvider struct{}
+
+func (kp *keyfileBackupKeyProvider) readKey() ([]byte, string, error) {
+	if BackupEncryptionKeyfile == "" {
+		return nil, "", vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "--backup-encryption-keyfile is required by the %q key provider", KeyfileKeyProvider)
+	}
+	data, err := os.ReadFile(BackupEncryptionKeyfile)
+	if err != nil {
+		return nil, "", vterrors.Wrapf(err, "cannot read backup encryption keyfile %v", BackupEncryptionKeyfile)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != dataKeySize {
+		return nil, "", vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup encryption keyfile %v must hold a %d bit key as %d hex characters", BackupEncryptionKeyfile, dataKeySize*8, dataKeySize*2)
+	}
+	fingerprint := sha256.Sum256(key)
+	return key, hex.EncodeToString(fingerprint[:8]), nil
+}
+
+// WrapKey is part of the BackupKeyProvider interface.
+func (kp *keyfileBackupKeyProvider) WrapKey(ctx context.Context, dataKey []byte) ([]byte, string, error) {
+	key, keyID, err := kp.readKey()
+	if err != nil {
+		return nil, "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, "", err
+	}
+	return aead.Seal(nonce, nonce, dataKey, nil), keyID, nil
+}
+
+// UnwrapKey is part of the BackupKeyProvider interface.
+func (kp *keyfileBackupKeyProvider) UnwrapKey(ctx context.Context, wrappedKey []byte, keyID string) ([]byte, error) {
+	key, currentKeyID, err := kp.readKey()
+	if err != nil {
+		return nil, err
+	}
+	if keyID != currentKeyID {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "backup was encrypted with key %v, but keyfile %v holds key %v", keyID, BackupEncryptionKeyfile, currentKeyID)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < aead.NonceSize() {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "invalid wrapped backup data key")
+	}
+	dataKey, err := aead.Open(nil, wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "cannot decrypt backup data key")
+	}
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of the given chunk of a file.
+func chunkNonce(prefix []byte, counter uint32) []byte {
+	nonce := make([]byte, encryptionNoncePrefixSize+4)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encryptionNoncePrefixSize:], counter)
+	return nonce
+}
+
+// chunkAdditionalData authenticates whether a chunk is the last one of a file,
+// so that truncated files are detected.
+func chunkAdditionalData(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// encryptingWriter encrypts a file in chunks of encryptionChunkSize with
+// AES-256-GCM. The file starts with a version byte and a random nonce prefix.
+// Each chunk is sealed separately, and the last one (possibly empty) is
+// flagged as such.
+type encryptingWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+}
+
+// newEncryptingWriter returns a writer that encrypts the data with the given key
+// before writing it to the underlying writer.
+func newEncryptingWriter(dataKey []byte, writer io.Writer) (io.WriteCloser, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "cannot create encryptor")
+	}
+	prefix := make([]byte, encryptionNoncePrefixSize)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, vterrors.Wrap(err, "cannot create encryptor")
+	}
+	if _, err := writer.Write(append([]byte{encryptionVersion}, prefix...)); err != nil {
+		return nil, err
+	}
+	return &encryptingWriter{
+		w:      writer,
+		aead:   aead,
+		prefix: prefix,
+		buf:    make([]byte, 0, encryptionChunkSize),
+	}, nil
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data comes in, as the last
+		// chunk has to be sealed as such on Close().
+		if len(e.buf) == encryptionChunkSize {
+			if err := e.seal(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(e.buf[len(e.buf):encryptionChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+func (e *encryptingWriter) seal(final bool) error {
+	if e.counter == ^uint32(0) {
+		return vterrors.Errorf(vtrpc.Code_OUT_OF_RANGE, "file too large to be encrypted")
+	}
+	sealed := e.aead.Seal(nil, chunkNonce(e.prefix, e.counter), e.buf, chunkAdditionalData(final))
+	e.counter++
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+// Close seals the last chunk. It does not close the underlying writer.
+func (e *encryptingWriter) Close() error {
+	return e.seal(true)
+}
+
+// decryptingReader decrypts a file written by encryptingWriter.
+type decryptingReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	sealed  []byte
+	buf     []byte
+	done    bool
+}
+
+// newDecryptingReader returns a reader that decrypts the data read from the
+// underlying reader with the given key.
+func newDecryptingReader(dataKey []byte, reader io.Reader) (io.Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "cannot create decryptor")
+	}
+	header := make([]byte, 1+encryptionNoncePrefixSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, vterrors.Wrap(errEncryptedBackupTruncated, err.Error())
+	}
+	if header[0] != encryptionVersion {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "unsupported encrypted backup file version %d", header[0])
+	}
+	return &decryptingReader{
+		r:      bufio.NewReaderSize(reader, encryptionChunkSize+aead.Overhead()+1),
+		aead:   aead,
+		prefix: header[1:],
+		sealed: make([]byte, encryptionChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decryptingReader) open() error {
+	n, err := io.ReadFull(d.r, d.sealed)
+	switch err {
+	case nil:
+		// A full chunk is the last one if nothing follows.
+		if _, err := d.r.Peek(1); err == io.EOF {
+			d.done = true
+		} else if err != nil {
+			return err
+		}
+	case io.EOF, io.ErrUnexpectedEOF:
+		d.done = true
+	default:
+		return err
+	}
+	plaintext, err := d.aead.Open(d.sealed[:0], chunkNonce(d.prefix, d.counter), d.sealed[:n], chunkAdditionalData(d.done))
+	if err != nil {
+		if d.done {
+			// The last chunk read was not sealed as the last one.
+			return vterrors.Wrapf(errEncryptedBackupTruncated, "cannot decrypt chunk %d", d.counter)
+		}
+		return vterrors.Wrapf(err, "cannot decrypt chunk %d", d.counter)
+	}
+	d.counter++
+	d.buf = plaintext
+	return nil
+}
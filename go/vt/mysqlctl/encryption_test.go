@@ -0,0 +1,154 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+func encrypt(t *testing.T, dataKey, data []byte) []byte {
+	var encrypted bytes.Buffer
+	encryptor, err := newEncryptingWriter(dataKey, &encrypted)
+	require.NoError(t, err)
+	_, err = io.Copy(encryptor, bytes.NewReader(data))
+	require.NoError(t, err)
+	require.NoError(t, encryptor.Close())
+	return encrypted.Bytes()
+}
+
+func decrypt(dataKey, encrypted []byte) ([]byte, error) {
+	decryptor, err := newDecryptingReader(dataKey, bytes.NewReader(encrypted))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(decryptor)
+}
+
+func TestEncryption(t *testing.T) {
+	dataKey := make([]byte, dataKeySize)
+	_, err := rand.Read(dataKey)
+	require.NoError(t, err)
+
+	for _, size := range []int{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 3 * encryptionChunkSize} {
+		t.Run(fmt.Sprintf("%d bytes", size), func(t *testing.T) {
+			data := make([]byte, size)
+			_, err := rand.Read(data)
+			require.NoError(t, err)
+
+			encrypted := encrypt(t, dataKey, data)
+			if size > 16 {
+				assert.False(t, bytes.Contains(encrypted, data), "encrypted data must not contain the plaintext")
+			}
+
+			decrypted, err := decrypt(dataKey, encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, data, decrypted)
+
+			// Removing the last chunk, or a part of it, is detected.
+			if size > encryptionChunkSize {
+				_, err = decrypt(dataKey, encrypted[:1+encryptionNoncePrefixSize+encryptionChunkSize+16])
+				assert.Equal(t, errEncryptedBackupTruncated, vterrors.RootCause(err))
+			}
+			_, err = decrypt(dataKey, encrypted[:len(encrypted)-1])
+			assert.Error(t, err)
+
+			// Tampering is detected.
+			tampered := append([]byte{}, encrypted...)
+			tampered[len(tampered)-1] ^= 1
+			_, err = decrypt(dataKey, tampered)
+			assert.Error(t, err)
+
+			// Another key cannot decrypt the data.
+			otherKey := append([]byte{}, dataKey...)
+			otherKey[0] ^= 1
+			_, err = decrypt(otherKey, encrypted)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKeyfileBackupKeyProvider(t *testing.T) {
+	defer func(provider, keyfile string) {
+		BackupEncryptionKeyProvider, BackupEncryptionKeyfile = provider, keyfile
+	}(BackupEncryptionKeyProvider, BackupEncryptionKeyfile)
+
+	dir := t.TempDir()
+	writeKeyfile := func(name, key string) string {
+		keyfile := path.Join(dir, name)
+		require.NoError(t, os.WriteFile(keyfile, []byte(key), 0600))
+		return keyfile
+	}
+	keyfile := writeKeyfile("key1", strings.Repeat("ab", dataKeySize)+"\n")
+	otherKeyfile := writeKeyfile("key2", strings.Repeat("cd", dataKeySize))
+	invalidKeyfile := writeKeyfile("invalid", "not a key")
+
+	ctx := context.Background()
+
+	// No encryption by default.
+	BackupEncryptionKeyProvider = ""
+	enc, dataKey, err := newBackupEncryption(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, enc)
+	assert.Nil(t, dataKey)
+
+	BackupEncryptionKeyProvider = "unknown"
+	_, _, err = newBackupEncryption(ctx)
+	assert.ErrorContains(t, err, "unknown backup encryption key provider")
+
+	BackupEncryptionKeyProvider = KeyfileKeyProvider
+	BackupEncryptionKeyfile = invalidKeyfile
+	_, _, err = newBackupEncryption(ctx)
+	assert.ErrorContains(t, err, "must hold a 256 bit key")
+
+	BackupEncryptionKeyfile = keyfile
+	enc, dataKey, err = newBackupEncryption(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, enc)
+	assert.Len(t, dataKey, dataKeySize)
+	assert.Equal(t, KeyfileKeyProvider, enc.KeyProvider)
+	assert.Equal(t, backupEncryptionCipher, enc.Cipher)
+	assert.NotEmpty(t, enc.KeyID)
+	assert.False(t, bytes.Contains(enc.WrappedKey, dataKey))
+
+	// The description survives the MANIFEST, and unwraps to the same key.
+	data, err := json.Marshal(&builtinBackupManifest{Encryption: enc})
+	require.NoError(t, err)
+	var bm builtinBackupManifest
+	require.NoError(t, json.Unmarshal(data, &bm))
+	unwrapped, err := bm.Encryption.dataKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	// Restoring with another key fails.
+	BackupEncryptionKeyfile = otherKeyfile
+	_, err = bm.Encryption.dataKey(ctx)
+	assert.ErrorContains(t, err, "backup was encrypted with key")
+}
@@ -88,7 +88,7 @@ func (fbh *FileBackupHandle) AddFile(ctx context.Context, filename string, files
 	if fbh.readOnly {
 		return nil, fmt.Errorf("AddFile cannot be called on read-only backup")
 	}
-	p := path.Join(FileBackupStorageRoot, fbh.dir, fbh.name, filename)
+	p := path.Join(fbh.fbs.root(), fbh.dir, fbh.name, filename)
 	return os.Create(p)
 }
 
@@ -113,17 +113,28 @@ func (fbh *FileBackupHandle) ReadFile(ctx context.Context, filename string) (io.
 	if !fbh.readOnly {
 		return nil, fmt.Errorf("ReadFile cannot be called on read-write backup")
 	}
-	p := path.Join(FileBackupStorageRoot, fbh.dir, fbh.name, filename)
+	p := path.Join(fbh.fbs.root(), fbh.dir, fbh.name, filename)
 	return os.Open(p)
 }
 
 // FileBackupStorage implements BackupStorage for local file system.
-type FileBackupStorage struct{}
+type FileBackupStorage struct {
+	// Root is where the backups will go. If empty,
+	// --file_backup_storage_root is used.
+	Root string
+}
+
+func (fbs *FileBackupStorage) root() string {
+	if fbs.Root != "" {
+		return fbs.Root
+	}
+	return FileBackupStorageRoot
+}
 
 // ListBackups is part of the BackupStorage interface
 func (fbs *FileBackupStorage) ListBackups(ctx context.Context, dir string) ([]backupstorage.BackupHandle, error) {
 	// ReadDir already sorts the results
-	p := path.Join(FileBackupStorageRoot, dir)
+	p := path.Join(fbs.root(), dir)
 	fi, err := os.ReadDir(p)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -153,7 +164,7 @@ func (fbs *FileBackupStorage) ListBackups(ctx context.Context, dir string) ([]ba
 // StartBackup is part of the BackupStorage interface
 func (fbs *FileBackupStorage) StartBackup(ctx context.Context, dir, name string) (backupstorage.BackupHandle, error) {
 	// Make sure the directory exists.
-	p := path.Join(FileBackupStorageRoot, dir)
+	p := path.Join(fbs.root(), dir)
 	if err := os.MkdirAll(p, os.ModePerm); err != nil {
 		return nil, err
 	}
@@ -174,7 +185,7 @@ func (fbs *FileBackupStorage) StartBackup(ctx context.Context, dir, name string)
 
 // RemoveBackup is part of the BackupStorage interface
 func (fbs *FileBackupStorage) RemoveBackup(ctx context.Context, dir, name string) error {
-	p := path.Join(FileBackupStorageRoot, dir, name)
+	p := path.Join(fbs.root(), dir, name)
 	return os.RemoveAll(p)
 }
 
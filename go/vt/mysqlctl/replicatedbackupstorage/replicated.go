@@ -0,0 +1,513 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replicatedbackupstorage implements the BackupStorage interface
+// on top of other BackupStorage implementations. Backups are written to a
+// primary storage, and copied to secondary storages as they are written.
+package replicatedbackupstorage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// FailurePolicyBestEffort keeps a backup as long as it is written to the
+	// primary storage, whatever the secondary storages that fail.
+	FailurePolicyBestEffort = "best-effort"
+	// FailurePolicyRequireOne fails a backup unless it is written to at
+	// least one secondary storage.
+	FailurePolicyRequireOne = "require-one"
+	// FailurePolicyRequireAll fails a backup if any secondary storage fails.
+	FailurePolicyRequireAll = "require-all"
+
+	implementationName = "replicated"
+)
+
+var (
+	primaryImplementation    string
+	secondaryImplementations []string
+	failurePolicy            = FailurePolicyBestEffort
+
+	statsSecondaryErrors = stats.NewCountersWithSingleLabel("ReplicatedBackupStorageSecondaryErrors", "Errors writing or removing backups in the secondary backup storages", "storage")
+)
+
+func registerFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&primaryImplementation, "replicated_backup_storage_primary", primaryImplementation, "Which backup storage implementation the replicated backup storage writes backups to first, and reads them from.")
+	fs.StringSliceVar(&secondaryImplementations, "replicated_backup_storage_secondaries", secondaryImplementations, "Comma-separated list of backup storage implementations the replicated backup storage copies backups to. Backups are read from them if the primary storage cannot be read.")
+	fs.StringVar(&failurePolicy, "replicated_backup_storage_failure_policy", failurePolicy, "What to do when a backup cannot be written to a secondary storage: best-effort keeps the backup as long as it is written to the primary storage, require-one fails it unless it is written to at least one secondary storage, and require-all fails it.")
+}
+
+func init() {
+	servenv.OnParseFor("vtbackup", registerFlags)
+	servenv.OnParseFor("vtctl", registerFlags)
+	servenv.OnParseFor("vtctld", registerFlags)
+	servenv.OnParseFor("vttablet", registerFlags)
+}
+
+// Location is one of the storages of a ReplicatedBackupStorage.
+type Location struct {
+	// Name identifies the storage in logs and stats.
+	Name    string
+	Storage backupstorage.BackupStorage
+}
+
+// ReplicatedBackupStorage implements BackupStorage by writing backups to a
+// primary storage and to one or more secondary storages. Backups are read
+// from the first storage which can be read.
+type ReplicatedBackupStorage struct {
+	mu        sync.Mutex
+	locations []Location
+	policy    string
+}
+
+// NewBackupStorage returns a ReplicatedBackupStorage on top of the given
+// primary and secondary storages.
+func NewBackupStorage(policy string, primary Location, secondaries ...Location) (*ReplicatedBackupStorage, error) {
+	if err := checkFailurePolicy(policy); err != nil {
+		return nil, err
+	}
+	return &ReplicatedBackupStorage{
+		locations: append([]Location{primary}, secondaries...),
+		policy:    policy,
+	}, nil
+}
+
+func checkFailurePolicy(policy string) error {
+	switch policy {
+	case FailurePolicyBestEffort, FailurePolicyRequireOne, FailurePolicyRequireAll:
+		return nil
+	}
+	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown replicated backup storage failure policy %q", policy)
+}
+
+// getLocations returns the storages and the failure policy, set up from the
+// flags on first use.
+func (rbs *ReplicatedBackupStorage) getLocations() ([]Location, string, error) {
+	rbs.mu.Lock()
+	defer rbs.mu.Unlock()
+	if rbs.locations != nil {
+		return rbs.locations, rbs.policy, nil
+	}
+
+	if err := checkFailurePolicy(failurePolicy); err != nil {
+		return nil, "", err
+	}
+	if primaryImplementation == "" {
+		return nil, "", vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "--replicated_backup_storage_primary is required by the %v backup storage", implementationName)
+	}
+	var locations []Location
+	for _, name := range append([]string{primaryImplementation}, secondaryImplementations...) {
+		if name == implementationName {
+			return nil, "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the %v backup storage cannot replicate to itself", implementationName)
+		}
+		bs, ok := backupstorage.BackupStorageMap[name]
+		if !ok {
+			return nil, "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "no registered implementation of BackupStorage named %q", name)
+		}
+		locations = append(locations, Location{Name: name, Storage: bs})
+	}
+	rbs.locations, rbs.policy = locations, failurePolicy
+	return rbs.locations, rbs.policy, nil
+}
+
+// tolerates returns whether the policy tolerates that failed of the n
+// secondary storages have failed.
+func tolerates(policy string, failed, n int) bool {
+	switch policy {
+	case FailurePolicyRequireOne:
+		return n == 0 || failed < n
+	case FailurePolicyRequireAll:
+		return failed == 0
+	default:
+		return true
+	}
+}
+
+// ListBackups is part of the BackupStorage interface. It lists the backups of
+// the first storage which can be listed.
+func (rbs *ReplicatedBackupStorage) ListBackups(ctx context.Context, dir string) ([]backupstorage.BackupHandle, error) {
+	locations, _, err := rbs.getLocations()
+	if err != nil {
+		return nil, err
+	}
+
+	var firstErr error
+	for i, loc := range locations {
+		bhs, err := loc.Storage.ListBackups(ctx, dir)
+		if err != nil {
+			log.Warningf("cannot list backups in %v from the %v backup storage: %v", dir, loc.Name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		result := make([]backupstorage.BackupHandle, 0, len(bhs))
+		for _, bh := range bhs {
+			result = append(result, &replicatedReadHandle{
+				BackupHandle: bh,
+				locations:    locations,
+				index:        i,
+			})
+		}
+		return result, nil
+	}
+	return nil, firstErr
+}
+
+// StartBackup is part of the BackupStorage interface. The backup must start in
+// the primary storage; the secondary storages which fail are handled according
+// to the failure policy.
+func (rbs *ReplicatedBackupStorage) StartBackup(ctx context.Context, dir, name string) (backupstorage.BackupHandle, error) {
+	locations, policy, err := rbs.getLocations()
+	if err != nil {
+		return nil, err
+	}
+
+	bh, err := locations[0].Storage.StartBackup(ctx, dir, name)
+	if err != nil {
+		return nil, err
+	}
+	rbh := &replicatedBackupHandle{
+		dir:     dir,
+		name:    name,
+		policy:  policy,
+		primary: bh,
+	}
+	for _, loc := range locations[1:] {
+		sh := &secondaryHandle{location: loc}
+		rbh.secondaries = append(rbh.secondaries, sh)
+		sbh, err := loc.Storage.StartBackup(ctx, dir, name)
+		if err != nil {
+			if ferr := rbh.fail(sh, err); ferr != nil {
+				if aerr := rbh.AbortBackup(ctx); aerr != nil {
+					log.Warningf("cannot abort backup %v/%v: %v", dir, name, aerr)
+				}
+				return nil, ferr
+			}
+			continue
+		}
+		sh.bh = sbh
+	}
+	return rbh, nil
+}
+
+// RemoveBackup is part of the BackupStorage interface. It removes the backup
+// from all the storages.
+func (rbs *ReplicatedBackupStorage) RemoveBackup(ctx context.Context, dir, name string) error {
+	locations, policy, err := rbs.getLocations()
+	if err != nil {
+		return err
+	}
+
+	rec := concurrency.AllErrorRecorder{}
+	rec.RecordError(locations[0].Storage.RemoveBackup(ctx, dir, name))
+	failed := 0
+	for _, loc := range locations[1:] {
+		if err := loc.Storage.RemoveBackup(ctx, dir, name); err != nil {
+			log.Warningf("cannot remove backup %v/%v from the %v backup storage: %v", dir, name, loc.Name, err)
+			statsSecondaryErrors.Add(loc.Name, 1)
+			failed++
+			if !tolerates(policy, failed, len(locations)-1) {
+				rec.RecordError(vterrors.Wrapf(err, "cannot remove backup from the %v backup storage", loc.Name))
+			}
+		}
+	}
+	return rec.Error()
+}
+
+// Close is part of the BackupStorage interface.
+func (rbs *ReplicatedBackupStorage) Close() error {
+	rbs.mu.Lock()
+	locations := rbs.locations
+	rbs.mu.Unlock()
+
+	rec := concurrency.AllErrorRecorder{}
+	for _, loc := range locations {
+		rec.RecordError(loc.Storage.Close())
+	}
+	return rec.Error()
+}
+
+// secondaryHandle is the backup in a secondary storage.
+type secondaryHandle struct {
+	location Location
+	// bh is nil if the backup could not be started.
+	bh backupstorage.BackupHandle
+	// err is the first error writing the backup. Once set, nothing more is
+	// written to the storage, and the backup is aborted there.
+	err error
+}
+
+// replicatedBackupHandle implements BackupHandle for a backup being written
+// to all the storages.
+type replicatedBackupHandle struct {
+	dir         string
+	name        string
+	policy      string
+	primary     backupstorage.BackupHandle
+	secondaries []*secondaryHandle
+	errors      concurrency.AllErrorRecorder
+
+	// mu protects the err of secondaries.
+	mu sync.Mutex
+}
+
+// RecordError is part of the concurrency.ErrorRecorder interface.
+func (rbh *replicatedBackupHandle) RecordError(err error) {
+	rbh.errors.RecordError(err)
+}
+
+// HasErrors is part of the concurrency.ErrorRecorder interface.
+func (rbh *replicatedBackupHandle) HasErrors() bool {
+	return rbh.errors.HasErrors()
+}
+
+// Error is part of the concurrency.ErrorRecorder interface.
+func (rbh *replicatedBackupHandle) Error() error {
+	return rbh.errors.Error()
+}
+
+// Directory is part of the BackupHandle interface.
+func (rbh *replicatedBackupHandle) Directory() string {
+	return rbh.dir
+}
+
+// Name is part of the BackupHandle interface.
+func (rbh *replicatedBackupHandle) Name() string {
+	return rbh.name
+}
+
+// fail records that the backup could not be written to a secondary storage.
+// It returns an error if the failure policy does not tolerate it.
+func (rbh *replicatedBackupHandle) fail(sh *secondaryHandle, err error) error {
+	rbh.mu.Lock()
+	defer rbh.mu.Unlock()
+	if sh.err == nil {
+		log.Warningf("cannot write backup %v/%v to the %v backup storage: %v", rbh.dir, rbh.name, sh.location.Name, err)
+		statsSecondaryErrors.Add(sh.location.Name, 1)
+		sh.err = err
+	}
+	failed := 0
+	for _, s := range rbh.secondaries {
+		if s.err != nil {
+			failed++
+		}
+	}
+	if !tolerates(rbh.policy, failed, len(rbh.secondaries)) {
+		return vterrors.Wrapf(err, "cannot write backup to the %v backup storage (failure policy %v)", sh.location.Name, rbh.policy)
+	}
+	return nil
+}
+
+func (rbh *replicatedBackupHandle) failed(sh *secondaryHandle) bool {
+	rbh.mu.Lock()
+	defer rbh.mu.Unlock()
+	return sh.err != nil
+}
+
+// AddFile is part of the BackupHandle interface.
+func (rbh *replicatedBackupHandle) AddFile(ctx context.Context, filename string, filesize int64) (io.WriteCloser, error) {
+	wc, err := rbh.primary.AddFile(ctx, filename, filesize)
+	if err != nil {
+		return nil, err
+	}
+	rwc := &replicatedWriteCloser{
+		rbh:     rbh,
+		primary: wc,
+	}
+	for _, sh := range rbh.secondaries {
+		if rbh.failed(sh) {
+			continue
+		}
+		swc, err := sh.bh.AddFile(ctx, filename, filesize)
+		if err != nil {
+			if ferr := rbh.fail(sh, err); ferr != nil {
+				rwc.Close()
+				return nil, ferr
+			}
+			continue
+		}
+		rwc.secondaries = append(rwc.secondaries, &secondaryWriteCloser{sh: sh, wc: swc})
+	}
+	return rwc, nil
+}
+
+// EndBackup is part of the BackupHandle interface. If the failure policy does
+// not tolerate the secondary storages which failed, the backup is removed.
+func (rbh *replicatedBackupHandle) EndBackup(ctx context.Context) error {
+	if err := rbh.primary.EndBackup(ctx); err != nil {
+		rbh.abortSecondaries(ctx, false)
+		return err
+	}
+
+	var policyErr error
+	for _, sh := range rbh.secondaries {
+		if rbh.failed(sh) {
+			continue
+		}
+		if err := sh.bh.EndBackup(ctx); err != nil {
+			if ferr := rbh.fail(sh, err); ferr != nil && policyErr == nil {
+				policyErr = ferr
+			}
+		}
+	}
+	// Backups which could not be fully written to a secondary storage are
+	// not kept there.
+	rbh.abortSecondaries(ctx, true)
+	if policyErr == nil {
+		return nil
+	}
+
+	if err := rbh.primary.AbortBackup(ctx); err != nil {
+		log.Warningf("cannot remove backup %v/%v from the primary backup storage: %v", rbh.dir, rbh.name, err)
+	}
+	for _, sh := range rbh.secondaries {
+		if rbh.failed(sh) {
+			continue
+		}
+		if err := sh.location.Storage.RemoveBackup(ctx, rbh.dir, rbh.name); err != nil {
+			log.Warningf("cannot remove backup %v/%v from the %v backup storage: %v", rbh.dir, rbh.name, sh.location.Name, err)
+		}
+	}
+	return policyErr
+}
+
+// AbortBackup is part of the BackupHandle interface.
+func (rbh *replicatedBackupHandle) AbortBackup(ctx context.Context) error {
+	rbh.abortSecondaries(ctx, false)
+	return rbh.primary.AbortBackup(ctx)
+}
+
+// abortSecondaries aborts the backup in the secondary storages, or only in
+// the ones which failed.
+func (rbh *replicatedBackupHandle) abortSecondaries(ctx context.Context, onlyFailed bool) {
+	for _, sh := range rbh.secondaries {
+		if sh.bh == nil || (onlyFailed && !rbh.failed(sh)) {
+			continue
+		}
+		if err := sh.bh.AbortBackup(ctx); err != nil {
+			log.Warningf("cannot abort backup %v/%v in the %v backup storage: %v", rbh.dir, rbh.name, sh.location.Name, err)
+		}
+	}
+}
+
+// ReadFile is part of the BackupHandle interface.
+func (rbh *replicatedBackupHandle) ReadFile(ctx context.Context, filename string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("ReadFile cannot be called on read-write backup")
+}
+
+type secondaryWriteCloser struct {
+	sh *secondaryHandle
+	wc io.WriteCloser
+}
+
+// replicatedWriteCloser writes a file to the primary storage, and to the
+// secondary storages which have not failed.
+type replicatedWriteCloser struct {
+	rbh         *replicatedBackupHandle
+	primary     io.WriteCloser
+	secondaries []*secondaryWriteCloser
+}
+
+func (rwc *replicatedWriteCloser) Write(p []byte) (int, error) {
+	n, err := rwc.primary.Write(p)
+	if err != nil {
+		return n, err
+	}
+	for _, swc := range rwc.secondaries {
+		if rwc.rbh.failed(swc.sh) {
+			continue
+		}
+		m, err := swc.wc.Write(p)
+		if err == nil && m < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			if ferr := rwc.rbh.fail(swc.sh, err); ferr != nil {
+				return n, ferr
+			}
+		}
+	}
+	return n, nil
+}
+
+func (rwc *replicatedWriteCloser) Close() error {
+	err := rwc.primary.Close()
+	for _, swc := range rwc.secondaries {
+		if cerr := swc.wc.Close(); cerr != nil && !rwc.rbh.failed(swc.sh) {
+			if ferr := rwc.rbh.fail(swc.sh, cerr); ferr != nil && err == nil {
+				err = ferr
+			}
+		}
+	}
+	return err
+}
+
+// replicatedReadHandle implements BackupHandle for a backup listed in one of
+// the storages. Files which cannot be read from that storage are read from
+// the other storages.
+type replicatedReadHandle struct {
+	backupstorage.BackupHandle
+	locations []Location
+	// index is the location the backup was listed in.
+	index int
+}
+
+// ReadFile is part of the BackupHandle interface.
+func (rrh *replicatedReadHandle) ReadFile(ctx context.Context, filename string) (io.ReadCloser, error) {
+	rc, err := rrh.BackupHandle.ReadFile(ctx, filename)
+	if err == nil {
+		return rc, nil
+	}
+	for i, loc := range rrh.locations {
+		if i == rrh.index {
+			continue
+		}
+		bhs, lerr := loc.Storage.ListBackups(ctx, rrh.Directory())
+		if lerr != nil {
+			continue
+		}
+		for _, bh := range bhs {
+			if bh.Name() != rrh.Name() {
+				continue
+			}
+			if rc, rerr := bh.ReadFile(ctx, filename); rerr == nil {
+				log.Warningf("reading %v of backup %v/%v from the %v backup storage: %v", filename, rrh.Directory(), rrh.Name(), loc.Name, err)
+				return rc, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+func init() {
+	backupstorage.BackupStorageMap[implementationName] = &ReplicatedBackupStorage{}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicatedbackupstorage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+)
+
+const (
+	testDir    = "keyspace/shard"
+	testBackup = "cell-0001-2015-01-14-10-00-00"
+)
+
+var errTest = errors.New("test-triggered error")
+
+// failingBackupStorage wraps a BackupStorage, and fails the selected
+// operations.
+type failingBackupStorage struct {
+	backupstorage.BackupStorage
+	failList  bool
+	failStart bool
+	failWrite bool
+}
+
+func (fbs *failingBackupStorage) ListBackups(ctx context.Context, dir string) ([]backupstorage.BackupHandle, error) {
+	if fbs.failList {
+		return nil, errTest
+	}
+	return fbs.BackupStorage.ListBackups(ctx, dir)
+}
+
+func (fbs *failingBackupStorage) StartBackup(ctx context.Context, dir, name string) (backupstorage.BackupHandle, error) {
+	if fbs.failStart {
+		return nil, errTest
+	}
+	bh, err := fbs.BackupStorage.StartBackup(ctx, dir, name)
+	if err != nil {
+		return nil, err
+	}
+	return &failingBackupHandle{BackupHandle: bh, failWrite: fbs.failWrite}, nil
+}
+
+type failingBackupHandle struct {
+	backupstorage.BackupHandle
+	failWrite bool
+}
+
+func (fbh *failingBackupHandle) AddFile(ctx context.Context, filename string, filesize int64) (io.WriteCloser, error) {
+	wc, err := fbh.BackupHandle.AddFile(ctx, filename, filesize)
+	if err != nil || !fbh.failWrite {
+		return wc, err
+	}
+	return &failingWriteCloser{wc}, nil
+}
+
+type failingWriteCloser struct {
+	io.WriteCloser
+}
+
+func (fwc *failingWriteCloser) Write(p []byte) (int, error) {
+	return 0, errTest
+}
+
+func newLocation(t *testing.T, name string) (Location, string) {
+	root := t.TempDir()
+	return Location{Name: name, Storage: &filebackupstorage.FileBackupStorage{Root: root}}, root
+}
+
+// writeBackup writes a backup with a single file, and returns the error of
+// the first operation which failed.
+func writeBackup(ctx context.Context, bs backupstorage.BackupStorage, contents string) error {
+	bh, err := bs.StartBackup(ctx, testDir, testBackup)
+	if err != nil {
+		return err
+	}
+	wc, err := bh.AddFile(ctx, "file", int64(len(contents)))
+	if err != nil {
+		bh.AbortBackup(ctx)
+		return err
+	}
+	if _, err := io.WriteString(wc, contents); err != nil {
+		wc.Close()
+		bh.AbortBackup(ctx)
+		return err
+	}
+	if err := wc.Close(); err != nil {
+		bh.AbortBackup(ctx)
+		return err
+	}
+	return bh.EndBackup(ctx)
+}
+
+func readBackup(ctx context.Context, t *testing.T, bs backupstorage.BackupStorage) string {
+	bhs, err := bs.ListBackups(ctx, testDir)
+	require.NoError(t, err)
+	require.Len(t, bhs, 1)
+	assert.Equal(t, testBackup, bhs[0].Name())
+	rc, err := bhs[0].ReadFile(ctx, "file")
+	require.NoError(t, err)
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func backupExists(root string) bool {
+	_, err := os.Stat(path.Join(root, testDir, testBackup))
+	return err == nil
+}
+
+func TestReplicatedBackupStorage(t *testing.T) {
+	ctx := context.Background()
+	primary, primaryRoot := newLocation(t, "primary")
+	secondary, secondaryRoot := newLocation(t, "secondary")
+	rbs, err := NewBackupStorage(FailurePolicyRequireAll, primary, secondary)
+	require.NoError(t, err)
+
+	// The backup is written to both storages.
+	require.NoError(t, writeBackup(ctx, rbs, "contents"))
+	for _, root := range []string{primaryRoot, secondaryRoot} {
+		data, err := os.ReadFile(path.Join(root, testDir, testBackup, "file"))
+		require.NoError(t, err)
+		assert.Equal(t, "contents", string(data))
+	}
+	assert.Equal(t, "contents", readBackup(ctx, t, rbs))
+
+	// Files missing in the primary storage are read from the secondary.
+	require.NoError(t, os.Remove(path.Join(primaryRoot, testDir, testBackup, "file")))
+	assert.Equal(t, "contents", readBackup(ctx, t, rbs))
+
+	// Backups are listed from the secondary if the primary fails.
+	failing := &failingBackupStorage{BackupStorage: primary.Storage, failList: true}
+	rbs, err = NewBackupStorage(FailurePolicyRequireAll, Location{Name: "primary", Storage: failing}, secondary)
+	require.NoError(t, err)
+	assert.Equal(t, "contents", readBackup(ctx, t, rbs))
+
+	// Backups are removed from all the storages.
+	require.NoError(t, rbs.RemoveBackup(ctx, testDir, testBackup))
+	assert.False(t, backupExists(primaryRoot))
+	assert.False(t, backupExists(secondaryRoot))
+}
+
+func TestReplicatedBackupStorageFailurePolicy(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		policy    string
+		failStart bool
+		failWrite bool
+		wantErr   bool
+	}{{
+		name:      "best effort, secondary cannot start",
+		policy:    FailurePolicyBestEffort,
+		failStart: true,
+	}, {
+		name:      "best effort, secondary cannot write",
+		policy:    FailurePolicyBestEffort,
+		failWrite: true,
+	}, {
+		name:      "require one, one secondary of two cannot write",
+		policy:    FailurePolicyRequireOne,
+		failWrite: true,
+	}, {
+		name:      "require all, secondary cannot start",
+		policy:    FailurePolicyRequireAll,
+		failStart: true,
+		wantErr:   true,
+	}, {
+		name:      "require all, secondary cannot write",
+		policy:    FailurePolicyRequireAll,
+		failWrite: true,
+		wantErr:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, primaryRoot := newLocation(t, "primary")
+			healthy, healthyRoot := newLocation(t, "healthy")
+			failing, failingRoot := newLocation(t, "failing")
+			failing.Storage = &failingBackupStorage{
+				BackupStorage: failing.Storage,
+				failStart:     tt.failStart,
+				failWrite:     tt.failWrite,
+			}
+			rbs, err := NewBackupStorage(tt.policy, primary, healthy, failing)
+			require.NoError(t, err)
+
+			err = writeBackup(ctx, rbs, "contents")
+			// Partial backups are never kept in a failing storage.
+			assert.False(t, backupExists(failingRoot))
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "cannot write backup to the failing backup storage")
+				assert.False(t, backupExists(primaryRoot))
+				assert.False(t, backupExists(healthyRoot))
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, backupExists(primaryRoot))
+			assert.True(t, backupExists(healthyRoot))
+			assert.Equal(t, "contents", readBackup(ctx, t, rbs))
+		})
+	}
+
+	// require-one fails if all the secondaries fail.
+	primary, _ := newLocation(t, "primary")
+	failing, _ := newLocation(t, "failing")
+	failing.Storage = &failingBackupStorage{BackupStorage: failing.Storage, failWrite: true}
+	rbs, err := NewBackupStorage(FailurePolicyRequireOne, primary, failing)
+	require.NoError(t, err)
+	assert.Error(t, writeBackup(ctx, rbs, "contents"))
+
+	_, err = NewBackupStorage("unknown", primary)
+	assert.ErrorContains(t, err, "unknown replicated backup storage failure policy")
+}
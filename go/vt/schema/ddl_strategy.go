@@ -19,6 +19,7 @@ package schema
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/google/shlex"
 )
@@ -40,6 +41,10 @@ const (
 	fastRangeRotationFlag  = "fast-range-rotation"
 	vreplicationTestSuite  = "vreplication-test-suite"
 	allowForeignKeysFlag   = "unsafe-allow-foreign-keys"
+	synchronizedCutOver    = "synchronized-cutover"
+	copyWindowFlag         = "copy-window"
+	cutOverWindowFlag      = "cutover-window"
+	dryRunFlag             = "dry-run"
 )
 
 // DDLStrategy suggests how an ALTER TABLE should run (e.g. "direct", "online", "gh-ost" or "pt-osc")
@@ -99,6 +104,12 @@ func ParseDDLStrategy(strategyVariable string) (*DDLStrategySetting, error) {
 	default:
 		return nil, fmt.Errorf("Unknown online DDL strategy: '%v'", strategy)
 	}
+	if _, err := setting.CopyWindow(); err != nil {
+		return nil, err
+	}
+	if _, err := setting.CutOverWindow(); err != nil {
+		return nil, err
+	}
 	return setting, nil
 }
 
@@ -113,6 +124,11 @@ func isFlag(s string, name string) bool {
 	return false
 }
 
+// isValueFlag returns true when the given string is a CLI flag of the given name, followed by "=" and a value
+func isValueFlag(s string, name string) bool {
+	return strings.HasPrefix(s, fmt.Sprintf("-%s=", name)) || strings.HasPrefix(s, fmt.Sprintf("--%s=", name))
+}
+
 // hasFlag returns true when Options include named flag
 func (setting *DDLStrategySetting) hasFlag(name string) bool {
 	opts, _ := shlex.Split(setting.Options)
@@ -124,6 +140,19 @@ func (setting *DDLStrategySetting) hasFlag(name string) bool {
 	return false
 }
 
+// flagValue returns the value of the named flag, e.g. "22:00-06:00" for "--copy-window=22:00-06:00", or
+// an empty string when Options do not include the flag
+func (setting *DDLStrategySetting) flagValue(name string) string {
+	opts, _ := shlex.Split(setting.Options)
+	for _, opt := range opts {
+		if isValueFlag(opt, name) {
+			_, value, _ := strings.Cut(opt, "=")
+			return value
+		}
+	}
+	return ""
+}
+
 // IsDeclarative checks if strategy options include --declarative
 func (setting *DDLStrategySetting) IsDeclarative() bool {
 	return setting.hasFlag(declarativeFlag)
@@ -179,6 +208,28 @@ func (setting *DDLStrategySetting) IsAllowForeignKeysFlag() bool {
 	return setting.hasFlag(allowForeignKeysFlag)
 }
 
+// IsSynchronizedCutOver checks if strategy options include --synchronized-cutover
+func (setting *DDLStrategySetting) IsSynchronizedCutOver() bool {
+	return setting.hasFlag(synchronizedCutOver)
+}
+
+// IsDryRun checks if strategy options include --dry-run
+func (setting *DDLStrategySetting) IsDryRun() bool {
+	return setting.hasFlag(dryRunFlag)
+}
+
+// CopyWindow returns the daily time windows given by --copy-window, within which the migration may
+// run its copy phase. An empty list means the migration may run at any time.
+func (setting *DDLStrategySetting) CopyWindow() (TimeWindows, error) {
+	return ParseTimeWindows(setting.flagValue(copyWindowFlag))
+}
+
+// CutOverWindow returns the daily time windows given by --cutover-window, within which the migration may
+// cut over. An empty list means the migration may cut over at any time.
+func (setting *DDLStrategySetting) CutOverWindow() (TimeWindows, error) {
+	return ParseTimeWindows(setting.flagValue(cutOverWindowFlag))
+}
+
 // RuntimeOptions returns the options used as runtime flags for given strategy, removing any internal hint options
 func (setting *DDLStrategySetting) RuntimeOptions() []string {
 	opts, _ := shlex.Split(setting.Options)
@@ -197,6 +248,10 @@ func (setting *DDLStrategySetting) RuntimeOptions() []string {
 		case isFlag(opt, fastRangeRotationFlag):
 		case isFlag(opt, vreplicationTestSuite):
 		case isFlag(opt, allowForeignKeysFlag):
+		case isFlag(opt, synchronizedCutOver):
+		case isFlag(opt, dryRunFlag):
+		case isValueFlag(opt, copyWindowFlag):
+		case isValueFlag(opt, cutOverWindowFlag):
 		default:
 			validOpts = append(validOpts, opt)
 		}
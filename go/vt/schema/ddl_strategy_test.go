@@ -50,6 +50,10 @@ func TestParseDDLStrategy(t *testing.T) {
 		fastOverRevertible   bool
 		fastRangeRotation    bool
 		allowForeignKeys     bool
+		synchronizedCutOver  bool
+		copyWindow           string
+		cutOverWindow        string
+		dryRun               bool
 		runtimeOptions       string
 		err                  error
 	}{
@@ -153,6 +157,37 @@ func TestParseDDLStrategy(t *testing.T) {
 			runtimeOptions:   "",
 			allowForeignKeys: true,
 		},
+		{
+			strategyVariable:     "vitess --synchronized-cutover --postpone-completion",
+			strategy:             DDLStrategyVitess,
+			options:              "--synchronized-cutover --postpone-completion",
+			runtimeOptions:       "",
+			isPostponeCompletion: true,
+			synchronizedCutOver:  true,
+		},
+		{
+			strategyVariable:  "vitess --copy-window=22:00-06:00 --cutover-window='01:00-02:00, 04:00-05:00' --allow-concurrent",
+			strategy:          DDLStrategyVitess,
+			options:           "--copy-window=22:00-06:00 --cutover-window='01:00-02:00, 04:00-05:00' --allow-concurrent",
+			runtimeOptions:    "",
+			isAllowConcurrent: true,
+			copyWindow:        "22:00-06:00",
+			cutOverWindow:     "01:00-02:00,04:00-05:00",
+		},
+		{
+			strategyVariable: "gh-ost --copy-window=01:00-05:00 --max-load=Threads_running=100",
+			strategy:         DDLStrategyGhost,
+			options:          "--copy-window=01:00-05:00 --max-load=Threads_running=100",
+			runtimeOptions:   "--max-load=Threads_running=100",
+			copyWindow:       "01:00-05:00",
+		},
+		{
+			strategyVariable: "vitess --dry-run",
+			strategy:         DDLStrategyVitess,
+			options:          "--dry-run",
+			runtimeOptions:   "",
+			dryRun:           true,
+		},
 	}
 	for _, ts := range tt {
 		t.Run(ts.strategyVariable, func(t *testing.T) {
@@ -168,6 +203,14 @@ func TestParseDDLStrategy(t *testing.T) {
 			assert.Equal(t, ts.fastOverRevertible, setting.IsPreferInstantDDL())
 			assert.Equal(t, ts.fastRangeRotation, setting.IsFastRangeRotationFlag())
 			assert.Equal(t, ts.allowForeignKeys, setting.IsAllowForeignKeysFlag())
+			assert.Equal(t, ts.synchronizedCutOver, setting.IsSynchronizedCutOver())
+			assert.Equal(t, ts.dryRun, setting.IsDryRun())
+			copyWindow, err := setting.CopyWindow()
+			assert.NoError(t, err)
+			assert.Equal(t, ts.copyWindow, copyWindow.String())
+			cutOverWindow, err := setting.CutOverWindow()
+			assert.NoError(t, err)
+			assert.Equal(t, ts.cutOverWindow, cutOverWindow.String())
 
 			runtimeOptions := strings.Join(setting.RuntimeOptions(), " ")
 			assert.Equal(t, ts.runtimeOptions, runtimeOptions)
@@ -177,4 +220,12 @@ func TestParseDDLStrategy(t *testing.T) {
 		_, err := ParseDDLStrategy("other")
 		assert.Error(t, err)
 	}
+	{
+		_, err := ParseDDLStrategy("vitess --copy-window=22:00")
+		assert.Error(t, err)
+	}
+	{
+		_, err := ParseDDLStrategy("vitess --cutover-window=01:00-25:00")
+		assert.Error(t, err)
+	}
 }
@@ -165,6 +165,11 @@ func NewOnlineDDLs(keyspace string, sql string, ddlStmt sqlparser.DDLStatement,
 		if err := appendOnlineDDL(ddlStmt.GetTable().Name.String(), ddlStmt); err != nil {
 			return nil, err
 		}
+	case *sqlparser.CreateTrigger, *sqlparser.CreateRoutine, *sqlparser.CreateEvent, *sqlparser.DropStoredProgram:
+		// The migration's table is the name of the trigger, routine or event
+		if err := appendOnlineDDL(ddlStmt.GetTable().Name.String(), ddlStmt); err != nil {
+			return nil, err
+		}
 	case *sqlparser.DropTable, *sqlparser.DropView:
 		tables := ddlStmt.GetFromTables()
 		for _, table := range tables {
@@ -387,6 +392,19 @@ func (onlineDDL *OnlineDDL) IsView() bool {
 	return false
 }
 
+// IsStoredProgram returns 'true' when the statement affects a TRIGGER, a PROCEDURE, a FUNCTION or an EVENT
+func (onlineDDL *OnlineDDL) IsStoredProgram() bool {
+	stmt, _, err := ParseOnlineDDLStatement(onlineDDL.SQL)
+	if err != nil {
+		return false
+	}
+	switch stmt.(type) {
+	case *sqlparser.CreateTrigger, *sqlparser.CreateRoutine, *sqlparser.CreateEvent, *sqlparser.DropStoredProgram:
+		return true
+	}
+	return false
+}
+
 // GetActionStr returns a string representation of the DDL action
 func (onlineDDL *OnlineDDL) GetActionStr() (action sqlparser.DDLAction, actionStr string, err error) {
 	action, err = onlineDDL.GetAction()
@@ -324,6 +324,58 @@ func TestNewOnlineDDLs(t *testing.T) {
 	}
 }
 
+func TestNewOnlineDDLsStoredPrograms(t *testing.T) {
+	tests := []struct {
+		query string
+		table string
+	}{
+		{
+			query: "create trigger t_bi before insert on t for each row set new.id = 1",
+			table: "t_bi",
+		},
+		{
+			query: "create procedure p() begin select 1; end",
+			table: "p",
+		},
+		{
+			query: "create function f() returns int return 1",
+			table: "f",
+		},
+		{
+			query: "create event e on schedule every 1 day do delete from t",
+			table: "e",
+		},
+		{
+			query: "drop trigger t_bi",
+			table: "t_bi",
+		},
+		{
+			query: "drop procedure if exists p",
+			table: "p",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(tc.query)
+			require.NoError(t, err)
+			ddlStmt, ok := stmt.(sqlparser.DDLStatement)
+			require.True(t, ok)
+
+			onlineDDLs, err := NewOnlineDDLs("test_ks", tc.query, ddlStmt, NewDDLStrategySetting(DDLStrategyVitess, "--declarative"), "", "")
+			require.NoError(t, err)
+			require.Len(t, onlineDDLs, 1)
+			onlineDDL := onlineDDLs[0]
+			assert.Equal(t, tc.table, onlineDDL.Table)
+			assert.True(t, onlineDDL.IsStoredProgram())
+			assert.False(t, onlineDDL.IsView())
+
+			sql, err := onlineDDL.sqlWithoutComments()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.query, sql)
+		})
+	}
+}
+
 func TestNewOnlineDDLsForeignKeys(t *testing.T) {
 	type expect struct {
 		sqls            []string
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const timeWindowLayout = "15:04"
+
+// TimeWindow is a daily window of time, in UTC, e.g. "22:00-06:00". A window whose end precedes its start
+// wraps around midnight.
+type TimeWindow struct {
+	// Start and End are offsets since midnight
+	Start time.Duration
+	End   time.Duration
+}
+
+// TimeWindows is a list of daily windows of time. An empty list stands for "always open".
+type TimeWindows []TimeWindow
+
+// ParseTimeWindows parses a comma separated list of daily windows, e.g. "01:00-05:00,22:00-23:30".
+// Times are in UTC. An empty string returns an empty list.
+func ParseTimeWindows(s string) (TimeWindows, error) {
+	var windows TimeWindows
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		start, end, ok := strings.Cut(token, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid time window: '%s'. Expected format: HH:MM-HH:MM", token)
+		}
+		startTime, err := time.Parse(timeWindowLayout, strings.TrimSpace(start))
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time in time window '%s': %v", token, err)
+		}
+		endTime, err := time.Parse(timeWindowLayout, strings.TrimSpace(end))
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time in time window '%s': %v", token, err)
+		}
+		window := TimeWindow{
+			Start: time.Duration(startTime.Hour())*time.Hour + time.Duration(startTime.Minute())*time.Minute,
+			End:   time.Duration(endTime.Hour())*time.Hour + time.Duration(endTime.Minute())*time.Minute,
+		}
+		if window.Start == window.End {
+			return nil, fmt.Errorf("empty time window: '%s'", token)
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// Contains returns true when the given time falls within this window
+func (w TimeWindow) Contains(t time.Time) bool {
+	offset := sinceMidnight(t)
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	// wraps around midnight
+	return offset >= w.Start || offset < w.End
+}
+
+// String returns the HH:MM-HH:MM representation of this window
+func (w TimeWindow) String() string {
+	midnight := time.Time{}
+	return fmt.Sprintf("%s-%s", midnight.Add(w.Start).Format(timeWindowLayout), midnight.Add(w.End).Format(timeWindowLayout))
+}
+
+// IsEmpty returns true when there are no windows, i.e. when time is not restricted at all
+func (windows TimeWindows) IsEmpty() bool {
+	return len(windows) == 0
+}
+
+// Contains returns true when the given time falls within any of the windows, or when there are no windows
+func (windows TimeWindows) Contains(t time.Time) bool {
+	if windows.IsEmpty() {
+		return true
+	}
+	for _, w := range windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the earliest time, not before the given time, at which any of the windows is open.
+func (windows TimeWindows) NextOpen(t time.Time) time.Time {
+	if windows.Contains(t) {
+		return t
+	}
+	t = t.UTC()
+	midnight := t.Truncate(24 * time.Hour)
+	var next time.Time
+	for _, w := range windows {
+		opens := midnight.Add(w.Start)
+		if opens.Before(t) {
+			opens = opens.Add(24 * time.Hour)
+		}
+		if next.IsZero() || opens.Before(next) {
+			next = opens
+		}
+	}
+	return next
+}
+
+// String returns the comma separated representation of the windows, as accepted by ParseTimeWindows
+func (windows TimeWindows) String() string {
+	tokens := make([]string, 0, len(windows))
+	for _, w := range windows {
+		tokens = append(tokens, w.String())
+	}
+	return strings.Join(tokens, ",")
+}
+
+// sinceMidnight returns the time elapsed since midnight UTC of the given time
+func sinceMidnight(t time.Time) time.Duration {
+	t = t.UTC()
+	return t.Sub(t.Truncate(24 * time.Hour))
+}
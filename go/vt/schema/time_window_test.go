@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeWindows(t *testing.T) {
+	tt := []struct {
+		windows string
+		expect  string
+		isError bool
+	}{
+		{
+			windows: "",
+			expect:  "",
+		},
+		{
+			windows: "22:00-06:00",
+			expect:  "22:00-06:00",
+		},
+		{
+			windows: " 1:30-5:00 , 22:00-23:45,",
+			expect:  "01:30-05:00,22:00-23:45",
+		},
+		{
+			windows: "22:00",
+			isError: true,
+		},
+		{
+			windows: "22:00-24:00",
+			isError: true,
+		},
+		{
+			windows: "10:00-10:00",
+			isError: true,
+		},
+		{
+			windows: "ten-eleven",
+			isError: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.windows, func(t *testing.T) {
+			windows, err := ParseTimeWindows(tc.windows)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, windows.String())
+		})
+	}
+}
+
+func TestTimeWindowsContains(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse(time.RFC3339, s)
+		require.NoError(t, err)
+		return tm
+	}
+	tt := []struct {
+		windows  string
+		tm       string
+		contains bool
+		nextOpen string
+	}{
+		{
+			windows:  "",
+			tm:       "2023-02-01T12:00:00Z",
+			contains: true,
+			nextOpen: "2023-02-01T12:00:00Z",
+		},
+		{
+			windows:  "01:00-05:00",
+			tm:       "2023-02-01T01:00:00Z",
+			contains: true,
+			nextOpen: "2023-02-01T01:00:00Z",
+		},
+		{
+			windows:  "01:00-05:00",
+			tm:       "2023-02-01T05:00:00Z",
+			nextOpen: "2023-02-02T01:00:00Z",
+		},
+		{
+			windows:  "01:00-05:00",
+			tm:       "2023-02-01T00:15:00Z",
+			nextOpen: "2023-02-01T01:00:00Z",
+		},
+		{
+			windows:  "22:00-06:00",
+			tm:       "2023-02-01T23:30:00Z",
+			contains: true,
+			nextOpen: "2023-02-01T23:30:00Z",
+		},
+		{
+			windows:  "22:00-06:00",
+			tm:       "2023-02-01T03:00:00Z",
+			contains: true,
+			nextOpen: "2023-02-01T03:00:00Z",
+		},
+		{
+			windows:  "22:00-06:00",
+			tm:       "2023-02-01T12:00:00Z",
+			nextOpen: "2023-02-01T22:00:00Z",
+		},
+		{
+			windows:  "22:00-06:00,13:00-14:00",
+			tm:       "2023-02-01T12:00:00Z",
+			nextOpen: "2023-02-01T13:00:00Z",
+		},
+		{
+			windows:  "01:00-02:00",
+			tm:       "2023-02-01T03:00:00+02:00",
+			contains: true,
+			nextOpen: "2023-02-01T01:00:00Z",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.windows+" "+tc.tm, func(t *testing.T) {
+			windows, err := ParseTimeWindows(tc.windows)
+			require.NoError(t, err)
+			tm := at(tc.tm)
+			assert.Equal(t, tc.contains, windows.Contains(tm))
+			assert.True(t, at(tc.nextOpen).Equal(windows.NextOpen(tm)))
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package schemadiff
 
 import (
+	"strings"
+
 	"vitess.io/vitess/go/vt/sqlparser"
 )
 
@@ -24,6 +26,42 @@ func AllSubsequent(diff EntityDiff) (diffs []EntityDiff) {
 	return diffs
 }
 
+// equalProgramText compares the text of two stored program definitions token by token. It ignores
+// whitespace, comments, identifier quoting and the letter case of keywords and identifiers.
+func equalProgramText(text1 string, text2 string) bool {
+	tokenizer1 := sqlparser.NewStringTokenizer(text1)
+	tokenizer2 := sqlparser.NewStringTokenizer(text2)
+	next := func(tokenizer *sqlparser.Tokenizer) (int, string) {
+		for {
+			typ, val := tokenizer.Scan()
+			if typ != sqlparser.COMMENT {
+				return typ, val
+			}
+		}
+	}
+	for {
+		typ1, val1 := next(tokenizer1)
+		typ2, val2 := next(tokenizer2)
+		switch {
+		case typ1 != typ2:
+			return false
+		case typ1 == 0:
+			return true
+		case typ1 == sqlparser.LEX_ERROR:
+			// Cannot tokenize further; fall back to a plain comparison
+			return text1 == text2
+		case typ1 == sqlparser.STRING:
+			if val1 != val2 {
+				return false
+			}
+		default:
+			if !strings.EqualFold(val1, val2) {
+				return false
+			}
+		}
+	}
+}
+
 // DiffCreateTablesQueries compares two `CREATE TABLE ...` queries (in string form) and returns the diff from table1 to table2.
 // Either or both of the queries can be empty. Based on this, the diff could be
 // nil, CreateTable, DropTable or AlterTable
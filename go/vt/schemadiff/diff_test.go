@@ -615,6 +615,138 @@ func TestDiffSchemas(t *testing.T) {
 				"CREATE VIEW `v0` AS SELECT * FROM `v2`, `t2`",
 			},
 		},
+		{
+			name: "identical triggers",
+			from: "create table t(id int); create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create trigger if not exists t_bi before insert on t for each row set new.id = 1; create table t(id int)",
+		},
+		{
+			name: "create trigger",
+			from: "create table t(id int)",
+			to:   "create table t(id int); create trigger t_bi before insert on t for each row set new.id = 1",
+			diffs: []string{
+				"create trigger t_bi before insert on t for each row set new.id = 1",
+			},
+			cdiffs: []string{
+				"CREATE TRIGGER `t_bi` BEFORE INSERT ON `t` FOR EACH ROW set new.id = 1",
+			},
+		},
+		{
+			name: "change trigger body",
+			from: "create table t(id int); create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create table t(id int); create trigger t_bi before insert on t for each row begin set new.id = 2; end",
+			diffs: []string{
+				"drop trigger t_bi",
+				"create trigger t_bi before insert on t for each row begin set new.id = 2; end",
+			},
+			cdiffs: []string{
+				"DROP TRIGGER `t_bi`",
+				"CREATE TRIGGER `t_bi` BEFORE INSERT ON `t` FOR EACH ROW begin set new.id = 2; end",
+			},
+		},
+		{
+			name: "drop trigger",
+			from: "create table t(id int); create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create table t(id int)",
+			diffs: []string{
+				"drop trigger t_bi",
+			},
+			cdiffs: []string{
+				"DROP TRIGGER `t_bi`",
+			},
+		},
+		{
+			name: "drop table with trigger",
+			from: "create table t(id int); create table t2(id int); create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create table t2(id int)",
+			diffs: []string{
+				"drop table t",
+			},
+			cdiffs: []string{
+				"DROP TABLE `t`",
+			},
+		},
+		{
+			name: "convert table with trigger to view",
+			from: "create table t(id int); create table t2(id int); create trigger t_bi before insert on t2 for each row set new.id = 1",
+			to:   "create table t(id int); create view t2 as select * from t",
+			diffs: []string{
+				"drop table t2",
+				"create view t2 as select * from t",
+			},
+			cdiffs: []string{
+				"DROP TABLE `t2`",
+				"CREATE VIEW `t2` AS SELECT * FROM `t`",
+			},
+		},
+		{
+			name: "move trigger to new table",
+			from: "create table t(id int); create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create table t2(id int); create trigger t_bi before insert on t2 for each row set new.id = 1",
+			diffs: []string{
+				"drop table t",
+				"create table t2 (\n\tid int\n)",
+				"create trigger t_bi before insert on t2 for each row set new.id = 1",
+			},
+			cdiffs: []string{
+				"DROP TABLE `t`",
+				"CREATE TABLE `t2` (\n\t`id` int\n)",
+				"CREATE TRIGGER `t_bi` BEFORE INSERT ON `t2` FOR EACH ROW set new.id = 1",
+			},
+		},
+		{
+			name: "rename table with trigger",
+			from: "create table t(id int); create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create table t2(id int); create trigger t_bi before insert on t2 for each row set new.id = 1",
+			diffs: []string{
+				"rename table t to t2",
+				"drop trigger t_bi",
+				"create trigger t_bi before insert on t2 for each row set new.id = 1",
+			},
+			cdiffs: []string{
+				"RENAME TABLE `t` TO `t2`",
+				"DROP TRIGGER `t_bi`",
+				"CREATE TRIGGER `t_bi` BEFORE INSERT ON `t2` FOR EACH ROW set new.id = 1",
+			},
+			tableRename: TableRenameHeuristicStatement,
+		},
+		{
+			name:        "create trigger: nonexistent table",
+			from:        "create table t(id int)",
+			to:          "create table t(id int); create trigger t_bi before insert on t2 for each row set new.id = 1",
+			expectError: (&TriggerTableNotFoundError{Trigger: "t_bi", Table: "t2"}).Error(),
+		},
+		{
+			name:        "create trigger: view",
+			from:        "create table t(id int)",
+			to:          "create table t(id int); create view v as select * from t; create trigger t_bi before insert on v for each row set new.id = 1",
+			expectError: (&TriggerTableNotFoundError{Trigger: "t_bi", Table: "v"}).Error(),
+		},
+		{
+			name: "create, alter, drop routines and events",
+			from: "create table t(id int); create procedure p() select 1; create function f(x int) returns int return x; create event e on schedule every 1 day do delete from t",
+			to:   "create table t(id int); create view v as select f(id) from t; create function f(x int) returns int return x * 2; create function p() returns int return 1; create procedure p() select 1",
+			diffs: []string{
+				"drop event e",
+				"drop function f",
+				"create function f(x int) returns int return x * 2",
+				"create function p() returns int return 1",
+				"create view v as select f(id) from t",
+			},
+			cdiffs: []string{
+				"DROP EVENT `e`",
+				"DROP FUNCTION `f`",
+				"CREATE FUNCTION `f`(x int) returns int return x * 2",
+				"CREATE FUNCTION `p`() returns int return 1",
+				"CREATE VIEW `v` AS SELECT f(`id`) FROM `t`",
+			},
+		},
+		{
+			name:        "duplicate procedure",
+			from:        "create table t(id int)",
+			to:          "create procedure p() select 1; create procedure p() select 2",
+			expectError: (&ApplyDuplicateEntityError{Entity: "p"}).Error(),
+		},
 	}
 	for _, ts := range tt {
 		t.Run(ts.name, func(t *testing.T) {
@@ -631,8 +763,10 @@ func TestDiffSchemas(t *testing.T) {
 				statements := []string{}
 				cstatements := []string{}
 				for _, d := range diffs {
-					statements = append(statements, d.StatementString())
-					cstatements = append(cstatements, d.CanonicalStatementString())
+					for _, sd := range AllSubsequent(d) {
+						statements = append(statements, sd.StatementString())
+						cstatements = append(cstatements, sd.CanonicalStatementString())
+					}
 				}
 				if ts.diffs == nil {
 					ts.diffs = []string{}
@@ -707,6 +841,16 @@ func TestSchemaApplyError(t *testing.T) {
 			from: "create table t(id int); create view v1 as select * from t",
 			to:   "create table t(id int); create view v1 as select * from t; create view v2 as select * from t",
 		},
+		{
+			name: "added trigger",
+			from: "create table t(id int)",
+			to:   "create table t(id int); create trigger t_bi before insert on t for each row set new.id = 1",
+		},
+		{
+			name: "added procedure and event",
+			from: "create table t(id int)",
+			to:   "create table t(id int); create procedure p() select 1; create event e on schedule every 1 day do delete from t",
+		},
 	}
 	hints := &DiffHints{}
 	for _, ts := range tt {
@@ -3,6 +3,7 @@ package schemadiff
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"vitess.io/vitess/go/sqlescape"
 )
@@ -75,6 +76,31 @@ func (e *ApplyViewNotFoundError) Error() string {
 	return fmt.Sprintf("view %s not found", sqlescape.EscapeID(e.View))
 }
 
+type ApplyTriggerNotFoundError struct {
+	Trigger string
+}
+
+func (e *ApplyTriggerNotFoundError) Error() string {
+	return fmt.Sprintf("trigger %s not found", sqlescape.EscapeID(e.Trigger))
+}
+
+type ApplyRoutineNotFoundError struct {
+	Type    string
+	Routine string
+}
+
+func (e *ApplyRoutineNotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not found", e.Type, sqlescape.EscapeID(e.Routine))
+}
+
+type ApplyEventNotFoundError struct {
+	Event string
+}
+
+func (e *ApplyEventNotFoundError) Error() string {
+	return fmt.Sprintf("event %s not found", sqlescape.EscapeID(e.Event))
+}
+
 type ApplyKeyNotFoundError struct {
 	Table string
 	Key   string
@@ -255,3 +281,141 @@ type ViewDependencyUnresolvedError struct {
 func (e *ViewDependencyUnresolvedError) Error() string {
 	return fmt.Sprintf("view %s has unresolved/loop dependencies", sqlescape.EscapeID(e.View))
 }
+
+type TriggerTableNotFoundError struct {
+	Trigger string
+	Table   string
+}
+
+func (e *TriggerTableNotFoundError) Error() string {
+	return fmt.Sprintf("trigger %s is defined on nonexistent table %s", sqlescape.EscapeID(e.Trigger), sqlescape.EscapeID(e.Table))
+}
+
+type InvalidColumnInViewError struct {
+	View   string
+	Table  string
+	Column string
+}
+
+func (e *InvalidColumnInViewError) Error() string {
+	column := sqlescape.EscapeID(e.Column)
+	if e.Table != "" {
+		column = sqlescape.EscapeID(e.Table) + "." + column
+	}
+	return fmt.Sprintf("invalid column %s referenced by view %s", column, sqlescape.EscapeID(e.View))
+}
+
+type ForeignKeyReferencedTableNotFoundError struct {
+	Table           string
+	Constraint      string
+	ReferencedTable string
+}
+
+func (e *ForeignKeyReferencedTableNotFoundError) Error() string {
+	return fmt.Sprintf("foreign key constraint %s in table %s references nonexistent table %s",
+		sqlescape.EscapeID(e.Constraint), sqlescape.EscapeID(e.Table), sqlescape.EscapeID(e.ReferencedTable))
+}
+
+type ForeignKeyColumnCountMismatchError struct {
+	Table                 string
+	Constraint            string
+	ColumnCount           int
+	ReferencedTable       string
+	ReferencedColumnCount int
+}
+
+func (e *ForeignKeyColumnCountMismatchError) Error() string {
+	return fmt.Sprintf("mismatching column count %d in foreign key constraint %s in table %s, referencing %d columns in table %s",
+		e.ColumnCount, sqlescape.EscapeID(e.Constraint), sqlescape.EscapeID(e.Table), e.ReferencedColumnCount, sqlescape.EscapeID(e.ReferencedTable))
+}
+
+type InvalidReferencedColumnInForeignKeyConstraintError struct {
+	Table            string
+	Constraint       string
+	ReferencedTable  string
+	ReferencedColumn string
+}
+
+func (e *InvalidReferencedColumnInForeignKeyConstraintError) Error() string {
+	return fmt.Sprintf("invalid column %s in table %s referenced by foreign key constraint %s in table %s",
+		sqlescape.EscapeID(e.ReferencedColumn), sqlescape.EscapeID(e.ReferencedTable), sqlescape.EscapeID(e.Constraint), sqlescape.EscapeID(e.Table))
+}
+
+type ForeignKeyColumnTypeMismatchError struct {
+	Table            string
+	Constraint       string
+	Column           string
+	ReferencedTable  string
+	ReferencedColumn string
+}
+
+func (e *ForeignKeyColumnTypeMismatchError) Error() string {
+	return fmt.Sprintf("column %s in foreign key constraint %s in table %s is incompatible with referenced column %s in table %s",
+		sqlescape.EscapeID(e.Column), sqlescape.EscapeID(e.Constraint), sqlescape.EscapeID(e.Table),
+		sqlescape.EscapeID(e.ReferencedColumn), sqlescape.EscapeID(e.ReferencedTable))
+}
+
+type MissingForeignKeyReferencedIndexError struct {
+	Table           string
+	Constraint      string
+	ReferencedTable string
+}
+
+func (e *MissingForeignKeyReferencedIndexError) Error() string {
+	return fmt.Sprintf("missing index in table %s for columns referenced by foreign key constraint %s in table %s",
+		sqlescape.EscapeID(e.ReferencedTable), sqlescape.EscapeID(e.Constraint), sqlescape.EscapeID(e.Table))
+}
+
+type DuplicateConstraintNameError struct {
+	Table      string
+	Constraint string
+	OtherTable string
+}
+
+func (e *DuplicateConstraintNameError) Error() string {
+	return fmt.Sprintf("duplicate constraint %s in table %s, already defined in table %s",
+		sqlescape.EscapeID(e.Constraint), sqlescape.EscapeID(e.Table), sqlescape.EscapeID(e.OtherTable))
+}
+
+// InvalidSchemaError is returned by Schema.Validate(), and aggregates all problems found in the schema
+type InvalidSchemaError struct {
+	Errors []error
+}
+
+func (e *InvalidSchemaError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("invalid schema: %s", strings.Join(msgs, "; "))
+}
+
+type ConflictingDiffsError struct {
+	Entity     string
+	Statements []string
+}
+
+func (e *ConflictingDiffsError) Error() string {
+	return fmt.Sprintf("conflicting diffs for entity %s: %s", sqlescape.EscapeID(e.Entity), strings.Join(e.Statements, "; "))
+}
+
+type DiffDependencyCycleError struct {
+	Statements []string
+}
+
+func (e *DiffDependencyCycleError) Error() string {
+	return fmt.Sprintf("cyclic dependency among diffs: %s", strings.Join(e.Statements, "; "))
+}
+
+type InvalidMigrationStepError struct {
+	Step int
+	Err  error
+}
+
+func (e *InvalidMigrationStepError) Error() string {
+	return fmt.Sprintf("invalid migration step %d: %v", e.Step, e.Err)
+}
+
+func (e *InvalidMigrationStepError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// AlterEventEntityDiff represents a change to an event. Rather than rewriting the event with ALTER EVENT, the
+// diff is a DROP EVENT statement, followed by a subsequent CREATE EVENT diff.
+type AlterEventEntityDiff struct {
+	from       *CreateEventEntity
+	to         *CreateEventEntity
+	dropEvent  *sqlparser.DropStoredProgram
+	subsequent *CreateEventEntityDiff
+}
+
+// IsEmpty implements EntityDiff
+func (d *AlterEventEntityDiff) IsEmpty() bool {
+	return d.Statement() == nil
+}
+
+// Entities implements EntityDiff
+func (d *AlterEventEntityDiff) Entities() (from Entity, to Entity) {
+	return d.from, d.to
+}
+
+// Statement implements EntityDiff
+func (d *AlterEventEntityDiff) Statement() sqlparser.Statement {
+	if d == nil {
+		return nil
+	}
+	return d.dropEvent
+}
+
+// StatementString implements EntityDiff
+func (d *AlterEventEntityDiff) StatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.String(stmt)
+	}
+	return s
+}
+
+// CanonicalStatementString implements EntityDiff
+func (d *AlterEventEntityDiff) CanonicalStatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.CanonicalString(stmt)
+	}
+	return s
+}
+
+// SubsequentDiff implements EntityDiff
+func (d *AlterEventEntityDiff) SubsequentDiff() EntityDiff {
+	if d == nil || d.subsequent == nil {
+		return nil
+	}
+	return d.subsequent
+}
+
+// SetSubsequentDiff implements EntityDiff
+func (d *AlterEventEntityDiff) SetSubsequentDiff(subDiff EntityDiff) {
+	if d == nil {
+		return
+	}
+	if createDiff, ok := subDiff.(*CreateEventEntityDiff); ok {
+		d.subsequent = createDiff
+	} else {
+		d.subsequent = nil
+	}
+}
+
+type CreateEventEntityDiff struct {
+	createEvent *sqlparser.CreateEvent
+}
+
+// IsEmpty implements EntityDiff
+func (d *CreateEventEntityDiff) IsEmpty() bool {
+	return d.Statement() == nil
+}
+
+// Entities implements EntityDiff
+func (d *CreateEventEntityDiff) Entities() (from Entity, to Entity) {
+	return nil, &CreateEventEntity{CreateEvent: d.createEvent}
+}
+
+// Statement implements EntityDiff
+func (d *CreateEventEntityDiff) Statement() sqlparser.Statement {
+	if d == nil {
+		return nil
+	}
+	return d.createEvent
+}
+
+// CreateEvent returns the underlying sqlparser.CreateEvent that was generated for the diff.
+func (d *CreateEventEntityDiff) CreateEvent() *sqlparser.CreateEvent {
+	if d == nil {
+		return nil
+	}
+	return d.createEvent
+}
+
+// StatementString implements EntityDiff
+func (d *CreateEventEntityDiff) StatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.String(stmt)
+	}
+	return s
+}
+
+// CanonicalStatementString implements EntityDiff
+func (d *CreateEventEntityDiff) CanonicalStatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.CanonicalString(stmt)
+	}
+	return s
+}
+
+// SubsequentDiff implements EntityDiff
+func (d *CreateEventEntityDiff) SubsequentDiff() EntityDiff {
+	return nil
+}
+
+// SetSubsequentDiff implements EntityDiff
+func (d *CreateEventEntityDiff) SetSubsequentDiff(EntityDiff) {
+}
+
+type DropEventEntityDiff struct {
+	from      *CreateEventEntity
+	dropEvent *sqlparser.DropStoredProgram
+}
+
+// IsEmpty implements EntityDiff
+func (d *DropEventEntityDiff) IsEmpty() bool {
+	return d.Statement() == nil
+}
+
+// Entities implements EntityDiff
+func (d *DropEventEntityDiff) Entities() (from Entity, to Entity) {
+	return d.from, nil
+}
+
+// Statement implements EntityDiff
+func (d *DropEventEntityDiff) Statement() sqlparser.Statement {
+	if d == nil {
+		return nil
+	}
+	return d.dropEvent
+}
+
+// DropEvent returns the underlying sqlparser.DropStoredProgram that was generated for the diff.
+func (d *DropEventEntityDiff) DropEvent() *sqlparser.DropStoredProgram {
+	if d == nil {
+		return nil
+	}
+	return d.dropEvent
+}
+
+// StatementString implements EntityDiff
+func (d *DropEventEntityDiff) StatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.String(stmt)
+	}
+	return s
+}
+
+// CanonicalStatementString implements EntityDiff
+func (d *DropEventEntityDiff) CanonicalStatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.CanonicalString(stmt)
+	}
+	return s
+}
+
+// SubsequentDiff implements EntityDiff
+func (d *DropEventEntityDiff) SubsequentDiff() EntityDiff {
+	return nil
+}
+
+// SetSubsequentDiff implements EntityDiff
+func (d *DropEventEntityDiff) SetSubsequentDiff(EntityDiff) {
+}
+
+// CreateEventEntity stands for an EVENT construct. It contains the event's CREATE statement.
+type CreateEventEntity struct {
+	*sqlparser.CreateEvent
+}
+
+func NewCreateEventEntity(c *sqlparser.CreateEvent) (*CreateEventEntity, error) {
+	entity := &CreateEventEntity{CreateEvent: c}
+	entity.normalize()
+	return entity, nil
+}
+
+func (c *CreateEventEntity) normalize() {
+	// IF NOT EXISTS is not part of the event's definition
+	c.CreateEvent.IfNotExists = false
+}
+
+// Name implements Entity interface
+func (c *CreateEventEntity) Name() string {
+	return c.CreateEvent.EventName.Name.String()
+}
+
+// Diff implements Entity interface function
+func (c *CreateEventEntity) Diff(other Entity, hints *DiffHints) (EntityDiff, error) {
+	otherCreateEvent, ok := other.(*CreateEventEntity)
+	if !ok {
+		return nil, ErrEntityTypeMismatch
+	}
+	return c.EventDiff(otherCreateEvent, hints)
+}
+
+// EventDiff compares this event statement with another event statement, and sees what it takes to
+// change this event to look like the other event.
+// It returns an AlterEventEntityDiff, which drops and recreates the event, if changes are found, or nil if not.
+// The event definition is compared token by token, ignoring whitespace, comments, and the letter case of keywords and identifiers.
+func (c *CreateEventEntity) EventDiff(other *CreateEventEntity, _ *DiffHints) (*AlterEventEntityDiff, error) {
+	if c.identical(other) {
+		return nil, nil
+	}
+	diff := &AlterEventEntityDiff{
+		from:       c,
+		to:         other,
+		dropEvent:  c.Drop().(*DropEventEntityDiff).dropEvent,
+		subsequent: other.Create().(*CreateEventEntityDiff),
+	}
+	return diff, nil
+}
+
+// Create implements Entity interface
+func (c *CreateEventEntity) Create() EntityDiff {
+	return &CreateEventEntityDiff{createEvent: c.CreateEvent}
+}
+
+// Drop implements Entity interface
+func (c *CreateEventEntity) Drop() EntityDiff {
+	dropEvent := &sqlparser.DropStoredProgram{
+		Type: sqlparser.EventProgramType,
+		Name: c.EventName,
+	}
+	return &DropEventEntityDiff{from: c, dropEvent: dropEvent}
+}
+
+// Apply attempts to apply given event diff onto the event defined by this entity.
+// This entity is unmodified. If successful, a new CREATE EVENT entity is returned.
+func (c *CreateEventEntity) Apply(diff EntityDiff) (Entity, error) {
+	alterDiff, ok := diff.(*AlterEventEntityDiff)
+	if !ok {
+		return nil, ErrEntityTypeMismatch
+	}
+	dup := alterDiff.to.Clone().(*CreateEventEntity)
+	dup.normalize()
+	return dup, nil
+}
+
+func (c *CreateEventEntity) Clone() Entity {
+	return &CreateEventEntity{CreateEvent: sqlparser.CloneRefOfCreateEvent(c.CreateEvent)}
+}
+
+func (c *CreateEventEntity) identical(other *CreateEventEntity) bool {
+	if other == nil {
+		return false
+	}
+	return c.Name() == other.Name() &&
+		equalProgramText(c.Definition, other.Definition) &&
+		sqlparser.Equals.RefOfDefiner(c.Definer, other.Definer) &&
+		sqlparser.Equals.RefOfParsedComments(c.Comments, other.Comments)
+}
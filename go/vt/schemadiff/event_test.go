@@ -0,0 +1,95 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestCreateEventDiff(t *testing.T) {
+	tt := []struct {
+		name  string
+		from  string
+		to    string
+		diffs []string
+	}{
+		{
+			name: "identical",
+			from: "create event e on schedule every 1 day do delete from t",
+			to:   "create event if not exists e on schedule every 1 day do delete from t",
+		},
+		{
+			name: "schedule",
+			from: "create event e on schedule every 1 day do delete from t",
+			to:   "create event e on schedule every 1 hour do delete from t",
+			diffs: []string{
+				"drop event e",
+				"create event e on schedule every 1 hour do delete from t",
+			},
+		},
+	}
+	hints := &DiffHints{}
+	for _, ts := range tt {
+		t.Run(ts.name, func(t *testing.T) {
+			fromStmt, err := sqlparser.ParseStrictDDL(ts.from)
+			require.NoError(t, err)
+			fromCreateEvent, ok := fromStmt.(*sqlparser.CreateEvent)
+			require.True(t, ok)
+
+			toStmt, err := sqlparser.ParseStrictDDL(ts.to)
+			require.NoError(t, err)
+			toCreateEvent, ok := toStmt.(*sqlparser.CreateEvent)
+			require.True(t, ok)
+
+			c, err := NewCreateEventEntity(fromCreateEvent)
+			require.NoError(t, err)
+			other, err := NewCreateEventEntity(toCreateEvent)
+			require.NoError(t, err)
+			alter, err := c.Diff(other, hints)
+			require.NoError(t, err)
+			if ts.diffs == nil {
+				assert.Nil(t, alter)
+				return
+			}
+			require.NotNil(t, alter)
+			require.False(t, alter.IsEmpty())
+
+			var diffs []string
+			for _, diff := range AllSubsequent(alter) {
+				diffs = append(diffs, diff.StatementString())
+			}
+			assert.Equal(t, ts.diffs, diffs)
+			for _, diff := range diffs {
+				// validate we can parse back the statement
+				_, err := sqlparser.ParseStrictDDL(diff)
+				assert.NoError(t, err)
+			}
+			{ // Validate "apply()" on "from" converges with "to"
+				applied, err := c.Apply(alter)
+				require.NoError(t, err)
+				appliedDiff, err := other.Diff(applied, hints)
+				require.NoError(t, err)
+				assert.Nil(t, appliedDiff)
+			}
+		})
+	}
+}
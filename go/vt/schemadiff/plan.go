@@ -0,0 +1,364 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// MigrationStep is a group of diffs which do not depend on each other, and which may therefore run in parallel.
+type MigrationStep struct {
+	Diffs []EntityDiff
+}
+
+// MigrationPlan is a sequence of steps, which transitions a schema into another schema. Each step may only
+// run once all the previous steps are complete. Each step results in a valid schema.
+type MigrationPlan struct {
+	Steps []*MigrationStep
+}
+
+// Diffs returns all of the plan's diffs, in a valid execution order
+func (p *MigrationPlan) Diffs() (diffs []EntityDiff) {
+	for _, step := range p.Steps {
+		diffs = append(diffs, step.Diffs...)
+	}
+	return diffs
+}
+
+// IsEmpty returns true when the plan has no diffs
+func (p *MigrationPlan) IsEmpty() bool {
+	return len(p.Steps) == 0
+}
+
+// planNode is a diff in a migration plan, along with the entities it changes and depends on
+type planNode struct {
+	diff EntityDiff
+	// from and to are the diff's entities. from is nil for CREATE diffs, and to is nil for DROP diffs.
+	from Entity
+	to   Entity
+	// fromDependencies are the names of the tables and views on which the "from" entity depends,
+	// and toDependencies are the names of the tables and views on which the "to" entity depends.
+	fromDependencies []string
+	toDependencies   []string
+	// next lists the nodes which may only run after this node
+	next     []*planNode
+	inDegree int
+}
+
+// removes returns the key of the entity this node drops or renames away, if any
+func (n *planNode) removes() (string, bool) {
+	if n.from == nil {
+		return "", false
+	}
+	if n.to != nil && entityKey(n.to) == entityKey(n.from) {
+		return "", false
+	}
+	return entityKey(n.from), true
+}
+
+// adds returns the key of the entity this node creates or renames into, if any
+func (n *planNode) adds() (string, bool) {
+	if n.to == nil {
+		return "", false
+	}
+	if n.from != nil && entityKey(n.from) == entityKey(n.to) {
+		return "", false
+	}
+	return entityKey(n.to), true
+}
+
+// modifies returns the key of the entity this node alters, if any
+func (n *planNode) modifies() (string, bool) {
+	if n.from == nil || n.to == nil || entityKey(n.from) != entityKey(n.to) {
+		return "", false
+	}
+	return entityKey(n.from), true
+}
+
+// entityKey returns the key by which an entity is identified in its namespace. Tables and views share
+// a namespace, whereas triggers, procedures, functions and events each have their own.
+func entityKey(e Entity) string {
+	if key, ok := storedProgramKey(e); ok {
+		return key
+	}
+	return e.Name()
+}
+
+// entityDependencies returns the names of the tables and views on which the given entity depends:
+// - tables depend on the tables referenced by their foreign keys
+// - views depend on the tables and views they read from
+// - triggers depend on their tables
+// MySQL does not validate the tables used by stored routines and events, and so those have no dependencies.
+func entityDependencies(e Entity) (names []string, err error) {
+	switch e := e.(type) {
+	case *CreateTableEntity:
+		for _, cs := range e.CreateTable.TableSpec.Constraints {
+			if fk, ok := cs.Details.(*sqlparser.ForeignKeyDefinition); ok {
+				ref := fk.ReferenceDefinition.ReferencedTable
+				if ref.Qualifier.IsEmpty() && ref.Name.String() != e.Name() {
+					names = append(names, ref.Name.String())
+				}
+			}
+		}
+	case *CreateViewEntity:
+		return getViewDependentTableNames(e.CreateView)
+	case *CreateTriggerEntity:
+		names = append(names, e.TableName())
+	}
+	return names, nil
+}
+
+// NewMigrationPlan orders the given diffs into a migration plan for the given schema. Diffs are ordered by
+// their dependencies, such that:
+// - a table or a view is created or altered before the views, triggers and foreign keys which depend on it
+// - a table or a view is dropped, or renamed away, after the views, triggers and foreign keys which depend on it
+// - an entity is dropped before another entity of the same name is created
+// Diffs which do not depend on each other are grouped into the same step. Each step is validated by applying
+// it, along with all previous steps, onto a copy of the schema.
+// An error is returned when diffs conflict with each other, when diffs have cyclic dependencies, or when
+// a step results in an invalid schema.
+func NewMigrationPlan(schema *Schema, diffs []EntityDiff) (*MigrationPlan, error) {
+	var nodes []*planNode
+	for _, diff := range diffs {
+		if diff == nil || diff.IsEmpty() {
+			continue
+		}
+		node := &planNode{diff: diff}
+		node.from, node.to = diff.Entities()
+		if node.from != nil {
+			dependencies, err := entityDependencies(node.from)
+			if err != nil {
+				return nil, err
+			}
+			node.fromDependencies = dependencies
+		}
+		if node.to != nil {
+			dependencies, err := entityDependencies(node.to)
+			if err != nil {
+				return nil, err
+			}
+			node.toDependencies = dependencies
+		}
+		nodes = append(nodes, node)
+	}
+	if err := validatePlanConflicts(nodes); err != nil {
+		return nil, err
+	}
+
+	addEdge := func(before, after *planNode) {
+		for _, n := range before.next {
+			if n == after {
+				return
+			}
+		}
+		before.next = append(before.next, after)
+		after.inDegree++
+	}
+	contains := func(names []string, name string) bool {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+	// The columns of the schema's tables and views are used to order a table's alteration with the
+	// alteration of a view which reads from that table
+	validator := newViewsValidator(schema)
+	for _, view := range schema.Views() {
+		validator.validateView(view)
+	}
+	for _, a := range nodes {
+		for _, b := range nodes {
+			if a == b {
+				continue
+			}
+			// a creates or alters an entity which b's resulting entity depends on
+			if added, ok := a.adds(); ok && contains(b.toDependencies, added) {
+				addEdge(a, b)
+			}
+			if modified, ok := a.modifies(); ok && contains(b.toDependencies, modified) {
+				readsNewColumns, readsRemovedColumns := viewColumnDependencies(a, b, validator.columns)
+				if readsNewColumns || !readsRemovedColumns {
+					addEdge(a, b)
+				}
+				if readsRemovedColumns {
+					// b must stop reading columns before a removes them. If b also reads new columns
+					// added by a, then this is a cycle.
+					addEdge(b, a)
+				}
+			}
+			// a's original entity depends on an entity which b drops, or renames away. Triggers move along with
+			// their renamed tables, and so are unaffected by renames.
+			if removed, ok := b.removes(); ok && contains(a.fromDependencies, removed) {
+				_, isTrigger := a.from.(*CreateTriggerEntity)
+				_, isRename := b.diff.(*RenameTableEntityDiff)
+				if !(isTrigger && isRename) {
+					addEdge(a, b)
+				}
+			}
+			// a's original entity depends on an entity which b alters, and a's resulting entity no longer depends on it
+			if modified, ok := b.modifies(); ok && contains(a.fromDependencies, modified) && !contains(a.toDependencies, modified) {
+				addEdge(a, b)
+			}
+			// a drops, or renames away, an entity which b creates anew
+			if removed, ok := a.removes(); ok {
+				if added, ok := b.adds(); ok && removed == added {
+					addEdge(a, b)
+				}
+			}
+		}
+	}
+
+	// Group nodes into steps: each step has the nodes whose preceding nodes are all in previous steps
+	plan := &MigrationPlan{}
+	pending := nodes
+	for len(pending) > 0 {
+		step := &MigrationStep{}
+		var ready []*planNode
+		var remaining []*planNode
+		for _, node := range pending {
+			if node.inDegree == 0 {
+				ready = append(ready, node)
+			} else {
+				remaining = append(remaining, node)
+			}
+		}
+		if len(ready) == 0 {
+			cycleErr := &DiffDependencyCycleError{}
+			for _, node := range remaining {
+				cycleErr.Statements = append(cycleErr.Statements, node.diff.CanonicalStatementString())
+			}
+			return nil, cycleErr
+		}
+		for _, node := range ready {
+			step.Diffs = append(step.Diffs, node.diff)
+			for _, next := range node.next {
+				next.inDegree--
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+		pending = remaining
+	}
+
+	// Validate each step
+	for i, step := range plan.Steps {
+		var err error
+		schema, err = schema.Apply(step.Diffs)
+		if err != nil {
+			return nil, &InvalidMigrationStepError{Step: i, Err: err}
+		}
+	}
+	return plan, nil
+}
+
+// viewColumnDependencies analyzes a table alteration and an alteration of a view which reads from the table,
+// both before and after the alteration. It returns true for readsNewColumns if the altered view reads columns
+// which the altered table adds, and true for readsRemovedColumns if the original view reads columns which
+// the altered table removes.
+func viewColumnDependencies(tableNode, viewNode *planNode, columns map[string][]string) (readsNewColumns bool, readsRemovedColumns bool) {
+	fromTable, ok := tableNode.from.(*CreateTableEntity)
+	if !ok {
+		return true, false
+	}
+	toTable, ok := tableNode.to.(*CreateTableEntity)
+	if !ok {
+		return true, false
+	}
+	fromView, ok := viewNode.from.(*CreateViewEntity)
+	if !ok {
+		return true, false
+	}
+	toView, ok := viewNode.to.(*CreateViewEntity)
+	if !ok {
+		return true, false
+	}
+	countInvalidColumns := func(view *CreateViewEntity, table *CreateTableEntity) int {
+		v := &viewsValidator{
+			columns:  make(map[string][]string, len(columns)),
+			view:     view.Name(),
+			reported: map[string]bool{},
+		}
+		for name, entityColumns := range columns {
+			v.columns[name] = entityColumns
+		}
+		v.columns[table.Name()] = table.columnNames()
+		v.validateSelectStatement(view.Select, nil)
+		return len(v.errs)
+	}
+	readsNewColumns = countInvalidColumns(toView, fromTable) > countInvalidColumns(toView, toTable)
+	readsRemovedColumns = countInvalidColumns(fromView, toTable) > countInvalidColumns(fromView, fromTable)
+	return readsNewColumns, readsRemovedColumns
+}
+
+// validatePlanConflicts validates that no two diffs change the same entity, except for an entity which is
+// dropped or renamed away, and then created anew.
+func validatePlanConflicts(nodes []*planNode) error {
+	changes := map[string][]*planNode{}
+	var keys []string
+	track := func(key string, node *planNode) {
+		if _, ok := changes[key]; !ok {
+			keys = append(keys, key)
+		}
+		changes[key] = append(changes[key], node)
+	}
+	for _, node := range nodes {
+		if key, ok := node.removes(); ok {
+			track(key, node)
+		}
+		if key, ok := node.adds(); ok {
+			track(key, node)
+		}
+		if key, ok := node.modifies(); ok {
+			track(key, node)
+		}
+	}
+	for _, key := range keys {
+		changed := changes[key]
+		if len(changed) == 1 {
+			continue
+		}
+		if len(changed) == 2 && (isRemoveThenAdd(key, changed[0], changed[1]) || isRemoveThenAdd(key, changed[1], changed[0])) {
+			continue
+		}
+		conflictErr := &ConflictingDiffsError{Entity: key}
+		for _, node := range changed {
+			conflictErr.Statements = append(conflictErr.Statements, node.diff.CanonicalStatementString())
+		}
+		return conflictErr
+	}
+	return nil
+}
+
+// isRemoveThenAdd returns true when the first node drops or renames away the given entity, and the second node creates it
+func isRemoveThenAdd(key string, first, second *planNode) bool {
+	removed, ok := first.removes()
+	if !ok || removed != key {
+		return false
+	}
+	added, ok := second.adds()
+	return ok && added == key
+}
+
+// MigrationPlan returns a plan which migrates this schema into the other schema. See NewMigrationPlan.
+func (s *Schema) MigrationPlan(other *Schema, hints *DiffHints) (*MigrationPlan, error) {
+	diffs, err := s.Diff(other, hints)
+	if err != nil {
+		return nil, err
+	}
+	return NewMigrationPlan(s, diffs)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationPlan(t *testing.T) {
+	tt := []struct {
+		name    string
+		from    string
+		to      string
+		steps   [][]string
+		isError error
+	}{
+		{
+			name: "identical schemas",
+			from: "create table t(id int primary key)",
+			to:   "create table t(id int primary key)",
+		},
+		{
+			name: "foreign key parent created first",
+			to:   "create table child(id int primary key, parent_id int, constraint child_parent_fk foreign key (parent_id) references parent(id)); create table parent(id int primary key)",
+			steps: [][]string{
+				{"CREATE TABLE `parent` (\n\t`id` int,\n\tPRIMARY KEY (`id`)\n)"},
+				{"CREATE TABLE `child` (\n\t`id` int,\n\t`parent_id` int,\n\tPRIMARY KEY (`id`),\n\tCONSTRAINT `child_parent_fk` FOREIGN KEY (`parent_id`) REFERENCES `parent` (`id`)\n)"},
+			},
+		},
+		{
+			name: "foreign key child dropped first",
+			from: "create table child(id int primary key, parent_id int, constraint child_parent_fk foreign key (parent_id) references parent(id)); create table parent(id int primary key); create table t(id int primary key)",
+			to:   "create table t(id int primary key)",
+			steps: [][]string{
+				{"DROP TABLE `child`"},
+				{"DROP TABLE `parent`"},
+			},
+		},
+		{
+			name: "foreign key added to new parent",
+			from: "create table child(id int primary key, parent_id int)",
+			to:   "create table child(id int primary key, parent_id int, constraint child_parent_fk foreign key (parent_id) references parent(id)); create table parent(id int primary key)",
+			steps: [][]string{
+				{"CREATE TABLE `parent` (\n\t`id` int,\n\tPRIMARY KEY (`id`)\n)"},
+				{"ALTER TABLE `child` ADD CONSTRAINT `child_parent_fk` FOREIGN KEY (`parent_id`) REFERENCES `parent` (`id`)"},
+			},
+		},
+		{
+			name: "views created by dependency level, independent diffs grouped",
+			to:   "create view v2 as select * from v1; create view v1 as select * from t; create table t(id int); create table u(id int)",
+			steps: [][]string{
+				{"CREATE TABLE `t` (\n\t`id` int\n)", "CREATE TABLE `u` (\n\t`id` int\n)"},
+				{"CREATE VIEW `v1` AS SELECT * FROM `t`"},
+				{"CREATE VIEW `v2` AS SELECT * FROM `v1`"},
+			},
+		},
+		{
+			name: "views dropped before their tables",
+			from: "create view v2 as select * from v1; create view v1 as select * from t; create table t(id int); create table u(id int)",
+			to:   "create table u(id int)",
+			steps: [][]string{
+				{"DROP VIEW `v2`"},
+				{"DROP VIEW `v1`"},
+				{"DROP TABLE `t`"},
+			},
+		},
+		{
+			name: "view reads a new column",
+			from: "create table t(id int); create view v as select id from t",
+			to:   "create table t(id int, i int); create view v as select id, i from t",
+			steps: [][]string{
+				{"ALTER TABLE `t` ADD COLUMN `i` int"},
+				{"ALTER VIEW `v` AS SELECT `id`, `i` FROM `t`"},
+			},
+		},
+		{
+			name: "view stops reading a removed column",
+			from: "create table t(id int, i int); create view v as select id, i from t",
+			to:   "create table t(id int); create view v as select id from t",
+			steps: [][]string{
+				{"ALTER VIEW `v` AS SELECT `id` FROM `t`"},
+				{"ALTER TABLE `t` DROP COLUMN `i`"},
+			},
+		},
+		{
+			name: "view stops reading a dropped table",
+			from: "create table t1(id int); create table t2(id int); create view v as select id from t1",
+			to:   "create table t2(id int); create view v as select id from t2",
+			steps: [][]string{
+				{"ALTER VIEW `v` AS SELECT `id` FROM `t2`"},
+				{"DROP TABLE `t1`"},
+			},
+		},
+		{
+			name:    "view reads both a new column and a removed column",
+			from:    "create table t(id int, i int); create view v as select id, i from t",
+			to:      "create table t(id int, j int); create view v as select id, j from t",
+			isError: &DiffDependencyCycleError{},
+		},
+		{
+			name: "view replaced by a table",
+			from: "create view v as select 1 as id",
+			to:   "create table v(id int)",
+			steps: [][]string{
+				{"DROP VIEW `v`"},
+				{"CREATE TABLE `v` (\n\t`id` int\n)"},
+			},
+		},
+		{
+			name: "trigger created after its table",
+			to:   "create table t(id int); create trigger t_bi before insert on t for each row set new.id = 1",
+			steps: [][]string{
+				{"CREATE TABLE `t` (\n\t`id` int\n)"},
+				{"CREATE TRIGGER `t_bi` BEFORE INSERT ON `t` FOR EACH ROW set new.id = 1"},
+			},
+		},
+		{
+			name:    "invalid target schema",
+			to:      "create table t(id int, key i_idx (i))",
+			isError: &InvalidMigrationStepError{},
+		},
+	}
+	hints := &DiffHints{}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			fromSchema, err := NewSchemaFromSQL(tc.from)
+			require.NoError(t, err)
+			toSchema, err := NewSchemaFromSQL(tc.to)
+			require.NoError(t, err)
+
+			plan, err := fromSchema.MigrationPlan(toSchema, hints)
+			if tc.isError != nil {
+				require.Error(t, err)
+				assert.IsType(t, tc.isError, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var steps [][]string
+			for _, step := range plan.Steps {
+				var statements []string
+				for _, diff := range step.Diffs {
+					statements = append(statements, diff.CanonicalStatementString())
+				}
+				steps = append(steps, statements)
+			}
+			assert.Equal(t, tc.steps, steps)
+			assert.Equal(t, len(tc.steps) == 0, plan.IsEmpty())
+
+			// Applying the plan converges with the target schema
+			applied, err := fromSchema.Apply(plan.Diffs())
+			require.NoError(t, err)
+			diffs, err := applied.Diff(toSchema, hints)
+			require.NoError(t, err)
+			assert.Empty(t, diffs)
+		})
+	}
+}
+
+func TestMigrationPlanConflicts(t *testing.T) {
+	fromSchema, err := NewSchemaFromSQL("create table t(id int)")
+	require.NoError(t, err)
+	toSchema, err := NewSchemaFromSQL("create table t(id int, i int)")
+	require.NoError(t, err)
+	diffs, err := fromSchema.Diff(toSchema, &DiffHints{})
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+
+	_, err = NewMigrationPlan(fromSchema, append(diffs, diffs[0]))
+	require.Error(t, err)
+	var conflictErr *ConflictingDiffsError
+	require.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, "t", conflictErr.Entity)
+	assert.Len(t, conflictErr.Statements, 2)
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// AlterRoutineEntityDiff represents a change to a stored procedure or function. MySQL's ALTER PROCEDURE and
+// ALTER FUNCTION statements cannot change a routine's parameters or body, and so the diff is a DROP statement,
+// followed by a subsequent CREATE diff.
+type AlterRoutineEntityDiff struct {
+	from        *CreateRoutineEntity
+	to          *CreateRoutineEntity
+	dropRoutine *sqlparser.DropStoredProgram
+	subsequent  *CreateRoutineEntityDiff
+}
+
+// IsEmpty implements EntityDiff
+func (d *AlterRoutineEntityDiff) IsEmpty() bool {
+	return d.Statement() == nil
+}
+
+// Entities implements EntityDiff
+func (d *AlterRoutineEntityDiff) Entities() (from Entity, to Entity) {
+	return d.from, d.to
+}
+
+// Statement implements EntityDiff
+func (d *AlterRoutineEntityDiff) Statement() sqlparser.Statement {
+	if d == nil {
+		return nil
+	}
+	return d.dropRoutine
+}
+
+// StatementString implements EntityDiff
+func (d *AlterRoutineEntityDiff) StatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.String(stmt)
+	}
+	return s
+}
+
+// CanonicalStatementString implements EntityDiff
+func (d *AlterRoutineEntityDiff) CanonicalStatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.CanonicalString(stmt)
+	}
+	return s
+}
+
+// SubsequentDiff implements EntityDiff
+func (d *AlterRoutineEntityDiff) SubsequentDiff() EntityDiff {
+	if d == nil || d.subsequent == nil {
+		return nil
+	}
+	return d.subsequent
+}
+
+// SetSubsequentDiff implements EntityDiff
+func (d *AlterRoutineEntityDiff) SetSubsequentDiff(subDiff EntityDiff) {
+	if d == nil {
+		return
+	}
+	if createDiff, ok := subDiff.(*CreateRoutineEntityDiff); ok {
+		d.subsequent = createDiff
+	} else {
+		d.subsequent = nil
+	}
+}
+
+type CreateRoutineEntityDiff struct {
+	createRoutine *sqlparser.CreateRoutine
+}
+
+// IsEmpty implements EntityDiff
+func (d *CreateRoutineEntityDiff) IsEmpty() bool {
+	return d.Statement() == nil
+}
+
+// Entities implements EntityDiff
+func (d *CreateRoutineEntityDiff) Entities() (from Entity, to Entity) {
+	return nil, &CreateRoutineEntity{CreateRoutine: d.createRoutine}
+}
+
+// Statement implements EntityDiff
+func (d *CreateRoutineEntityDiff) Statement() sqlparser.Statement {
+	if d == nil {
+		return nil
+	}
+	return d.createRoutine
+}
+
+// CreateRoutine returns the underlying sqlparser.CreateRoutine that was generated for the diff.
+func (d *CreateRoutineEntityDiff) CreateRoutine() *sqlparser.CreateRoutine {
+	if d == nil {
+		return nil
+	}
+	return d.createRoutine
+}
+
+// StatementString implements EntityDiff
+func (d *CreateRoutineEntityDiff) StatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.String(stmt)
+	}
+	return s
+}
+
+// CanonicalStatementString implements EntityDiff
+func (d *CreateRoutineEntityDiff) CanonicalStatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.CanonicalString(stmt)
+	}
+	return s
+}
+
+// SubsequentDiff implements EntityDiff
+func (d *CreateRoutineEntityDiff) SubsequentDiff() EntityDiff {
+	return nil
+}
+
+// SetSubsequentDiff implements EntityDiff
+func (d *CreateRoutineEntityDiff) SetSubsequentDiff(EntityDiff) {
+}
+
+type DropRoutineEntityDiff struct {
+	from        *CreateRoutineEntity
+	dropRoutine *sqlparser.DropStoredProgram
+}
+
+// IsEmpty implements EntityDiff
+func (d *DropRoutineEntityDiff) IsEmpty() bool {
+	return d.Statement() == nil
+}
+
+// Entities implements EntityDiff
+func (d *DropRoutineEntityDiff) Entities() (from Entity, to Entity) {
+	return d.from, nil
+}
+
+// Statement implements EntityDiff
+func (d *DropRoutineEntityDiff) Statement() sqlparser.Statement {
+	if d == nil {
+		return nil
+	}
+	return d.dropRoutine
+}
+
+// DropRoutine returns the underlying sqlparser.DropStoredProgram that was generated for the diff.
+func (d *DropRoutineEntityDiff) DropRoutine() *sqlparser.DropStoredProgram {
+	if d == nil {
+		return nil
+	}
+	return d.dropRoutine
+}
+
+// StatementString implements EntityDiff
+func (d *DropRoutineEntityDiff) StatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.String(stmt)
+	}
+	return s
+}
+
+// CanonicalStatementString implements EntityDiff
+func (d *DropRoutineEntityDiff) CanonicalStatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.CanonicalString(stmt)
+	}
+	return s
+}
+
+// SubsequentDiff implements EntityDiff
+func (d *DropRoutineEntityDiff) SubsequentDiff() EntityDiff {
+	return nil
+}
+
+// SetSubsequentDiff implements EntityDiff
+func (d *DropRoutineEntityDiff) SetSubsequentDiff(EntityDiff) {
+}
+
+// CreateRoutineEntity stands for a PROCEDURE or a FUNCTION construct. It contains the routine's CREATE statement.
+type CreateRoutineEntity struct {
+	*sqlparser.CreateRoutine
+}
+
+func NewCreateRoutineEntity(c *sqlparser.CreateRoutine) (*CreateRoutineEntity, error) {
+	entity := &CreateRoutineEntity{CreateRoutine: c}
+	entity.normalize()
+	return entity, nil
+}
+
+func (c *CreateRoutineEntity) normalize() {
+	// IF NOT EXISTS is not part of the routine's definition
+	c.CreateRoutine.IfNotExists = false
+}
+
+// Name implements Entity interface
+func (c *CreateRoutineEntity) Name() string {
+	return c.CreateRoutine.RoutineName.Name.String()
+}
+
+// IsFunction returns true when this routine is a stored function, and false when it is a stored procedure
+func (c *CreateRoutineEntity) IsFunction() bool {
+	return c.CreateRoutine.Type == sqlparser.FunctionProgramType
+}
+
+// Diff implements Entity interface function
+func (c *CreateRoutineEntity) Diff(other Entity, hints *DiffHints) (EntityDiff, error) {
+	otherCreateRoutine, ok := other.(*CreateRoutineEntity)
+	if !ok {
+		return nil, ErrEntityTypeMismatch
+	}
+	if c.Type != otherCreateRoutine.Type {
+		return nil, ErrEntityTypeMismatch
+	}
+	return c.RoutineDiff(otherCreateRoutine, hints)
+}
+
+// RoutineDiff compares this routine statement with another routine statement, and sees what it takes to
+// change this routine to look like the other routine.
+// It returns an AlterRoutineEntityDiff, which drops and recreates the routine, if changes are found, or nil if not.
+// The routine definition is compared token by token, ignoring whitespace, comments, and the letter case of keywords and identifiers.
+func (c *CreateRoutineEntity) RoutineDiff(other *CreateRoutineEntity, _ *DiffHints) (*AlterRoutineEntityDiff, error) {
+	if c.identical(other) {
+		return nil, nil
+	}
+	diff := &AlterRoutineEntityDiff{
+		from:        c,
+		to:          other,
+		dropRoutine: c.Drop().(*DropRoutineEntityDiff).dropRoutine,
+		subsequent:  other.Create().(*CreateRoutineEntityDiff),
+	}
+	return diff, nil
+}
+
+// Create implements Entity interface
+func (c *CreateRoutineEntity) Create() EntityDiff {
+	return &CreateRoutineEntityDiff{createRoutine: c.CreateRoutine}
+}
+
+// Drop implements Entity interface
+func (c *CreateRoutineEntity) Drop() EntityDiff {
+	dropRoutine := &sqlparser.DropStoredProgram{
+		Type: c.Type,
+		Name: c.RoutineName,
+	}
+	return &DropRoutineEntityDiff{from: c, dropRoutine: dropRoutine}
+}
+
+// Apply attempts to apply given routine diff onto the routine defined by this entity.
+// This entity is unmodified. If successful, a new CREATE PROCEDURE or CREATE FUNCTION entity is returned.
+func (c *CreateRoutineEntity) Apply(diff EntityDiff) (Entity, error) {
+	alterDiff, ok := diff.(*AlterRoutineEntityDiff)
+	if !ok {
+		return nil, ErrEntityTypeMismatch
+	}
+	dup := alterDiff.to.Clone().(*CreateRoutineEntity)
+	dup.normalize()
+	return dup, nil
+}
+
+func (c *CreateRoutineEntity) Clone() Entity {
+	return &CreateRoutineEntity{CreateRoutine: sqlparser.CloneRefOfCreateRoutine(c.CreateRoutine)}
+}
+
+func (c *CreateRoutineEntity) identical(other *CreateRoutineEntity) bool {
+	if other == nil {
+		return false
+	}
+	return c.Type == other.Type &&
+		c.Name() == other.Name() &&
+		equalProgramText(c.Definition, other.Definition) &&
+		sqlparser.Equals.RefOfDefiner(c.Definer, other.Definer) &&
+		sqlparser.Equals.RefOfParsedComments(c.Comments, other.Comments)
+}
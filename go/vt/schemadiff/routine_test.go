@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestCreateRoutineDiff(t *testing.T) {
+	tt := []struct {
+		name  string
+		from  string
+		to    string
+		diffs []string
+	}{
+		{
+			name: "identical",
+			from: "create procedure p(x int) begin select x; end",
+			to:   "create procedure if not exists p(x int) begin select x; end",
+		},
+		{
+			name: "identical, as reported by SHOW CREATE FUNCTION",
+			from: "create function f(x int) returns int deterministic return x * 2",
+			to:   "CREATE FUNCTION `f`(x int) RETURNS int\n    DETERMINISTIC\nreturn x * 2",
+		},
+		{
+			name: "parameters",
+			from: "create procedure p(x int) begin select x; end",
+			to:   "create procedure p(x bigint) begin select x; end",
+			diffs: []string{
+				"drop procedure p",
+				"create procedure p(x bigint) begin select x; end",
+			},
+		},
+		{
+			name: "function body",
+			from: "create function f(x int) returns int deterministic return x",
+			to:   "create function f(x int) returns int deterministic return x + 1",
+			diffs: []string{
+				"drop function f",
+				"create function f(x int) returns int deterministic return x + 1",
+			},
+		},
+	}
+	hints := &DiffHints{}
+	for _, ts := range tt {
+		t.Run(ts.name, func(t *testing.T) {
+			fromStmt, err := sqlparser.ParseStrictDDL(ts.from)
+			require.NoError(t, err)
+			fromCreateRoutine, ok := fromStmt.(*sqlparser.CreateRoutine)
+			require.True(t, ok)
+
+			toStmt, err := sqlparser.ParseStrictDDL(ts.to)
+			require.NoError(t, err)
+			toCreateRoutine, ok := toStmt.(*sqlparser.CreateRoutine)
+			require.True(t, ok)
+
+			c, err := NewCreateRoutineEntity(fromCreateRoutine)
+			require.NoError(t, err)
+			other, err := NewCreateRoutineEntity(toCreateRoutine)
+			require.NoError(t, err)
+			alter, err := c.Diff(other, hints)
+			require.NoError(t, err)
+			if ts.diffs == nil {
+				assert.Nil(t, alter)
+				return
+			}
+			require.NotNil(t, alter)
+			require.False(t, alter.IsEmpty())
+
+			var diffs []string
+			for _, diff := range AllSubsequent(alter) {
+				diffs = append(diffs, diff.StatementString())
+			}
+			assert.Equal(t, ts.diffs, diffs)
+			for _, diff := range diffs {
+				// validate we can parse back the statement
+				_, err := sqlparser.ParseStrictDDL(diff)
+				assert.NoError(t, err)
+			}
+			{ // Validate "apply()" on "from" converges with "to"
+				applied, err := c.Apply(alter)
+				require.NoError(t, err)
+				appliedDiff, err := other.Diff(applied, hints)
+				require.NoError(t, err)
+				assert.Nil(t, appliedDiff)
+			}
+		})
+	}
+}
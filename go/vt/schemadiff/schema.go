@@ -27,27 +27,63 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 )
 
-// Schema represents a database schema, which may contain entities such as tables and views.
+// Schema represents a database schema, which may contain entities such as tables, views, triggers,
+// stored routines and events.
 // Schema is not in itself an Entity, since it is more of a collection of entities.
 type Schema struct {
-	tables []*CreateTableEntity
-	views  []*CreateViewEntity
+	tables   []*CreateTableEntity
+	views    []*CreateViewEntity
+	triggers []*CreateTriggerEntity
+	routines []*CreateRoutineEntity
+	events   []*CreateEventEntity
 
-	named  map[string]Entity
-	sorted []Entity
+	// named maps tables and views, which share a namespace, by name.
+	named map[string]Entity
+	// programs maps triggers, procedures, functions and events. Each of these has its own namespace,
+	// and so they are mapped by storedProgramKey().
+	programs map[string]Entity
+	sorted   []Entity
 }
 
 // newEmptySchema is used internally to initialize a Schema object
 func newEmptySchema() *Schema {
 	schema := &Schema{
-		tables: []*CreateTableEntity{},
-		views:  []*CreateViewEntity{},
-		named:  map[string]Entity{},
-		sorted: []Entity{},
+		tables:   []*CreateTableEntity{},
+		views:    []*CreateViewEntity{},
+		triggers: []*CreateTriggerEntity{},
+		routines: []*CreateRoutineEntity{},
+		events:   []*CreateEventEntity{},
+		named:    map[string]Entity{},
+		programs: map[string]Entity{},
+		sorted:   []Entity{},
 	}
 	return schema
 }
 
+// storedProgramKey returns the key by which a trigger, a routine or an event is mapped in the schema.
+// It returns false for any other entity.
+func storedProgramKey(e Entity) (key string, ok bool) {
+	switch e := e.(type) {
+	case *CreateTriggerEntity:
+		return sqlparser.TriggerProgramStr + ":" + e.Name(), true
+	case *CreateRoutineEntity:
+		return e.Type.ToString() + ":" + e.Name(), true
+	case *CreateEventEntity:
+		return sqlparser.EventProgramStr + ":" + e.Name(), true
+	}
+	return "", false
+}
+
+// lookup finds an entity of the same kind and name as the given entity
+func (s *Schema) lookup(e Entity) (Entity, bool) {
+	if key, ok := storedProgramKey(e); ok {
+		entity, ok := s.programs[key]
+		return entity, ok
+	}
+	entity, ok := s.named[e.Name()]
+	return entity, ok
+}
+
 // NewSchemaFromEntities creates a valid and normalized schema based on list of entities
 func NewSchemaFromEntities(entities []Entity) (*Schema, error) {
 	schema := newEmptySchema()
@@ -57,6 +93,12 @@ func NewSchemaFromEntities(entities []Entity) (*Schema, error) {
 			schema.tables = append(schema.tables, c)
 		case *CreateViewEntity:
 			schema.views = append(schema.views, c)
+		case *CreateTriggerEntity:
+			schema.triggers = append(schema.triggers, c)
+		case *CreateRoutineEntity:
+			schema.routines = append(schema.routines, c)
+		case *CreateEventEntity:
+			schema.events = append(schema.events, c)
 		default:
 			return nil, &UnsupportedEntityError{Entity: c.Name(), Statement: c.Create().CanonicalStatementString()}
 		}
@@ -84,6 +126,24 @@ func NewSchemaFromStatements(statements []sqlparser.Statement) (*Schema, error)
 				return nil, err
 			}
 			entities = append(entities, v)
+		case *sqlparser.CreateTrigger:
+			t, err := NewCreateTriggerEntity(stmt)
+			if err != nil {
+				return nil, err
+			}
+			entities = append(entities, t)
+		case *sqlparser.CreateRoutine:
+			r, err := NewCreateRoutineEntity(stmt)
+			if err != nil {
+				return nil, err
+			}
+			entities = append(entities, r)
+		case *sqlparser.CreateEvent:
+			e, err := NewCreateEventEntity(stmt)
+			if err != nil {
+				return nil, err
+			}
+			entities = append(entities, e)
 		default:
 			return nil, &UnsupportedStatementError{Statement: sqlparser.CanonicalString(s)}
 		}
@@ -105,7 +165,7 @@ func NewSchemaFromQueries(queries []string) (*Schema, error) {
 }
 
 // NewSchemaFromSQL creates a valid and normalized schema based on a SQL blob that contains
-// CREATE statements for various objects (tables, views)
+// CREATE statements for various objects (tables, views, triggers, routines, events)
 func NewSchemaFromSQL(sql string) (*Schema, error) {
 	var statements []sqlparser.Statement
 	tokenizer := sqlparser.NewStringTokenizer(sql)
@@ -144,7 +204,8 @@ func getViewDependentTableNames(createView *sqlparser.CreateView) (names []strin
 // It validates some cross-entity constraints, and orders entity based on dependencies (e.g. tables, views that read from tables, 2nd level views, etc.)
 func (s *Schema) normalize() error {
 	s.named = make(map[string]Entity, len(s.tables)+len(s.views))
-	s.sorted = make([]Entity, 0, len(s.tables)+len(s.views))
+	s.programs = make(map[string]Entity, len(s.triggers)+len(s.routines)+len(s.events))
+	s.sorted = make([]Entity, 0, len(s.tables)+len(s.views)+len(s.triggers)+len(s.routines)+len(s.events))
 	// Verify no two entities share same name
 	for _, t := range s.tables {
 		name := t.Name()
@@ -160,25 +221,60 @@ func (s *Schema) normalize() error {
 		}
 		s.named[name] = v
 	}
+	// Triggers, procedures, functions and events each have their own namespace
+	programs := make([]Entity, 0, len(s.triggers)+len(s.routines)+len(s.events))
+	for _, t := range s.triggers {
+		programs = append(programs, t)
+	}
+	for _, r := range s.routines {
+		programs = append(programs, r)
+	}
+	for _, e := range s.events {
+		programs = append(programs, e)
+	}
+	for _, p := range programs {
+		key, _ := storedProgramKey(p)
+		if _, ok := s.programs[key]; ok {
+			return &ApplyDuplicateEntityError{Entity: p.Name()}
+		}
+		s.programs[key] = p
+	}
 
-	// Generally speaking, we want tables and views to be sorted alphabetically
+	// Generally speaking, we want entities to be sorted alphabetically
 	sort.SliceStable(s.tables, func(i, j int) bool {
 		return s.tables[i].Name() < s.tables[j].Name()
 	})
 	sort.SliceStable(s.views, func(i, j int) bool {
 		return s.views[i].Name() < s.views[j].Name()
 	})
+	sort.SliceStable(s.triggers, func(i, j int) bool {
+		return s.triggers[i].Name() < s.triggers[j].Name()
+	})
+	sort.SliceStable(s.routines, func(i, j int) bool {
+		if s.routines[i].Type != s.routines[j].Type {
+			return s.routines[i].Type < s.routines[j].Type
+		}
+		return s.routines[i].Name() < s.routines[j].Name()
+	})
+	sort.SliceStable(s.events, func(i, j int) bool {
+		return s.events[i].Name() < s.events[j].Name()
+	})
 
 	// More importantly, we want tables and views to be sorted in applicable order.
 	// For example, if a view v reads from table t, then t must be defined before v.
-	// We actually prioritise all tables first, then views.
+	// We actually prioritise all tables first, then routines, then views.
 	// If a view v1 depends on v2, then v2 must come before v1, even though v1
 	// precedes v2 alphabetically
+	// Routines come before views because a view may call a stored function. MySQL does not validate
+	// the tables used in a routine's body upon creation, and so routines may come before views.
 	dependencyLevels := make(map[string]int, len(s.tables)+len(s.views))
 	for _, t := range s.tables {
 		s.sorted = append(s.sorted, t)
 		dependencyLevels[t.Name()] = 0
 	}
+	for _, r := range s.routines {
+		s.sorted = append(s.sorted, r)
+	}
 
 	allNamesFoundInLowerLevel := func(names []string, level int) bool {
 		for _, name := range names {
@@ -226,7 +322,7 @@ func (s *Schema) normalize() error {
 			break
 		}
 	}
-	if len(s.sorted) != len(s.tables)+len(s.views) {
+	if len(s.sorted) != len(s.tables)+len(s.routines)+len(s.views) {
 		// We have leftover views. This can happen if the schema definition is invalid:
 		// - a view depends on a nonexistent table
 		// - two views have a circular dependency
@@ -238,6 +334,16 @@ func (s *Schema) normalize() error {
 			}
 		}
 	}
+	// Triggers come after their tables. Events come last, as MySQL does not validate their body upon creation.
+	for _, t := range s.triggers {
+		if _, ok := s.named[t.TableName()].(*CreateTableEntity); !ok {
+			return &TriggerTableNotFoundError{Trigger: t.Name(), Table: t.TableName()}
+		}
+		s.sorted = append(s.sorted, t)
+	}
+	for _, e := range s.events {
+		s.sorted = append(s.sorted, e)
+	}
 	return nil
 }
 
@@ -295,13 +401,77 @@ func (s *Schema) ViewNames() []string {
 	return names
 }
 
+// Triggers returns this schema's triggers in good order (may be applied without error)
+func (s *Schema) Triggers() []*CreateTriggerEntity {
+	var triggers []*CreateTriggerEntity
+	for _, entity := range s.sorted {
+		if trigger, ok := entity.(*CreateTriggerEntity); ok {
+			triggers = append(triggers, trigger)
+		}
+	}
+	return triggers
+}
+
+// TriggerNames is a convenience function that returns just the names of triggers, in good order
+func (s *Schema) TriggerNames() []string {
+	var names []string
+	for _, e := range s.Triggers() {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// Routines returns this schema's procedures and functions in good order (may be applied without error)
+func (s *Schema) Routines() []*CreateRoutineEntity {
+	var routines []*CreateRoutineEntity
+	for _, entity := range s.sorted {
+		if routine, ok := entity.(*CreateRoutineEntity); ok {
+			routines = append(routines, routine)
+		}
+	}
+	return routines
+}
+
+// RoutineNames is a convenience function that returns just the names of procedures and functions, in good order
+func (s *Schema) RoutineNames() []string {
+	var names []string
+	for _, e := range s.Routines() {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// Events returns this schema's events in good order (may be applied without error)
+func (s *Schema) Events() []*CreateEventEntity {
+	var events []*CreateEventEntity
+	for _, entity := range s.sorted {
+		if event, ok := entity.(*CreateEventEntity); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// EventNames is a convenience function that returns just the names of events, in good order
+func (s *Schema) EventNames() []string {
+	var names []string
+	for _, e := range s.Events() {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
 // Diff compares this schema with another schema, and sees what it takes to make this schema look
 // like the other. It returns a list of diffs.
 func (s *Schema) Diff(other *Schema, hints *DiffHints) (diffs []EntityDiff, err error) {
 	// dropped entities
 	var dropDiffs []EntityDiff
 	for _, e := range s.Entities() {
-		if _, ok := other.named[e.Name()]; !ok {
+		if _, ok := e.(*CreateTriggerEntity); ok {
+			// Triggers are handled below, once we know which tables are dropped
+			continue
+		}
+		if _, ok := other.lookup(e); !ok {
 			// other schema does not have the entity
 			dropDiffs = append(dropDiffs, e.Drop())
 		}
@@ -311,7 +481,10 @@ func (s *Schema) Diff(other *Schema, hints *DiffHints) (diffs []EntityDiff, err
 	var alterDiffs []EntityDiff
 	var createDiffs []EntityDiff
 	for _, e := range other.Entities() {
-		if fromEntity, ok := s.named[e.Name()]; ok {
+		if _, ok := e.(*CreateTriggerEntity); ok {
+			continue
+		}
+		if fromEntity, ok := s.lookup(e); ok {
 			// entities exist by same name in both schemas. Let's diff them.
 			diff, err := fromEntity.Diff(e, hints)
 
@@ -341,10 +514,43 @@ func (s *Schema) Diff(other *Schema, hints *DiffHints) (diffs []EntityDiff, err
 		}
 	}
 	dropDiffs, createDiffs, renameDiffs := s.heuristicallyDetectTableRenames(dropDiffs, createDiffs, hints)
+
+	// Triggers. Dropping a table implicitly drops its triggers, and renaming a table implicitly
+	// moves its triggers. Hence, triggers are dropped first, and are altered or created last.
+	droppedTables := map[string]bool{}
+	for _, diff := range dropDiffs {
+		if dropTableDiff, ok := diff.(*DropTableEntityDiff); ok {
+			droppedTables[dropTableDiff.from.Name()] = true
+		}
+	}
+	var dropTriggerDiffs []EntityDiff
+	for _, t := range s.Triggers() {
+		if _, ok := other.lookup(t); !ok && !droppedTables[t.TableName()] {
+			dropTriggerDiffs = append(dropTriggerDiffs, t.Drop())
+		}
+	}
+	var triggerDiffs []EntityDiff
+	for _, t := range other.Triggers() {
+		fromEntity, ok := s.lookup(t)
+		if !ok || droppedTables[fromEntity.(*CreateTriggerEntity).TableName()] {
+			triggerDiffs = append(triggerDiffs, t.Create())
+			continue
+		}
+		diff, err := fromEntity.Diff(t, hints)
+		if err != nil {
+			return nil, err
+		}
+		if diff != nil && !diff.IsEmpty() {
+			triggerDiffs = append(triggerDiffs, diff)
+		}
+	}
+
+	diffs = append(diffs, dropTriggerDiffs...)
 	diffs = append(diffs, dropDiffs...)
 	diffs = append(diffs, alterDiffs...)
 	diffs = append(diffs, createDiffs...)
 	diffs = append(diffs, renameDiffs...)
+	diffs = append(diffs, triggerDiffs...)
 
 	return diffs, err
 }
@@ -441,6 +647,38 @@ func (s *Schema) View(name string) *CreateViewEntity {
 	return nil
 }
 
+// Trigger returns a trigger by name, or nil if nonexistent
+func (s *Schema) Trigger(name string) *CreateTriggerEntity {
+	if trigger, ok := s.programs[sqlparser.TriggerProgramStr+":"+name].(*CreateTriggerEntity); ok {
+		return trigger
+	}
+	return nil
+}
+
+// Procedure returns a stored procedure by name, or nil if nonexistent
+func (s *Schema) Procedure(name string) *CreateRoutineEntity {
+	if routine, ok := s.programs[sqlparser.ProcedureProgramStr+":"+name].(*CreateRoutineEntity); ok {
+		return routine
+	}
+	return nil
+}
+
+// Function returns a stored function by name, or nil if nonexistent
+func (s *Schema) Function(name string) *CreateRoutineEntity {
+	if routine, ok := s.programs[sqlparser.FunctionProgramStr+":"+name].(*CreateRoutineEntity); ok {
+		return routine
+	}
+	return nil
+}
+
+// Event returns an event by name, or nil if nonexistent
+func (s *Schema) Event(name string) *CreateEventEntity {
+	if event, ok := s.programs[sqlparser.EventProgramStr+":"+name].(*CreateEventEntity); ok {
+		return event
+	}
+	return nil
+}
+
 // ToStatements returns an ordered list of statements which can be applied to create the schema
 func (s *Schema) ToStatements() []sqlparser.Statement {
 	stmts := make([]sqlparser.Statement, 0, len(s.Entities()))
@@ -477,17 +715,27 @@ func (s *Schema) copy() *Schema {
 	copy(dup.tables, s.tables)
 	dup.views = make([]*CreateViewEntity, len(s.views))
 	copy(dup.views, s.views)
+	dup.triggers = make([]*CreateTriggerEntity, len(s.triggers))
+	copy(dup.triggers, s.triggers)
+	dup.routines = make([]*CreateRoutineEntity, len(s.routines))
+	copy(dup.routines, s.routines)
+	dup.events = make([]*CreateEventEntity, len(s.events))
+	copy(dup.events, s.events)
 	dup.named = make(map[string]Entity, len(s.named))
 	for k, v := range s.named {
 		dup.named[k] = v
 	}
+	dup.programs = make(map[string]Entity, len(s.programs))
+	for k, v := range s.programs {
+		dup.programs[k] = v
+	}
 	dup.sorted = make([]Entity, len(s.sorted))
 	copy(dup.sorted, s.sorted)
 	return dup
 }
 
 // apply attempts to apply given list of diffs to this object.
-// These diffs are CREATE/DROP/ALTER TABLE/VIEW.
+// These diffs are CREATE/DROP/ALTER TABLE/VIEW, and CREATE/DROP/ALTER (i.e. recreate) TRIGGER/PROCEDURE/FUNCTION/EVENT.
 func (s *Schema) apply(diffs []EntityDiff) error {
 	for _, diff := range diffs {
 		switch diff := diff.(type) {
@@ -514,6 +762,7 @@ func (s *Schema) apply(diffs []EntityDiff) error {
 				if name := t.Table.Name.String(); name == diff.from.Table.Name.String() {
 					s.tables = append(s.tables[0:i], s.tables[i+1:]...)
 					delete(s.named, name)
+					s.dropTableTriggers(name)
 					found = true
 					break
 				}
@@ -587,6 +836,7 @@ func (s *Schema) apply(diffs []EntityDiff) error {
 					s.tables[i] = diff.to
 					delete(s.named, name)
 					s.named[diff.to.Table.Name.String()] = diff.to
+					s.renameTableTriggers(name, diff.to.Table.Name)
 					found = true
 					break
 				}
@@ -594,6 +844,136 @@ func (s *Schema) apply(diffs []EntityDiff) error {
 			if !found {
 				return &ApplyTableNotFoundError{Table: diff.from.Table.Name.String()}
 			}
+		case *CreateTriggerEntityDiff, *CreateRoutineEntityDiff, *CreateEventEntityDiff:
+			// We expect the stored program to not exist
+			_, to := diff.Entities()
+			key, _ := storedProgramKey(to)
+			if _, ok := s.programs[key]; ok {
+				return &ApplyDuplicateEntityError{Entity: to.Name()}
+			}
+			switch to := to.(type) {
+			case *CreateTriggerEntity:
+				s.triggers = append(s.triggers, to)
+			case *CreateRoutineEntity:
+				s.routines = append(s.routines, to)
+			case *CreateEventEntity:
+				s.events = append(s.events, to)
+			}
+			s.programs[key] = to
+		case *DropTriggerEntityDiff:
+			// We expect the trigger to exist
+			found := false
+			for i, t := range s.triggers {
+				if t.Name() == diff.from.Name() {
+					s.triggers = append(s.triggers[0:i], s.triggers[i+1:]...)
+					key, _ := storedProgramKey(t)
+					delete(s.programs, key)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return &ApplyTriggerNotFoundError{Trigger: diff.from.Name()}
+			}
+		case *DropRoutineEntityDiff:
+			// We expect the routine to exist
+			found := false
+			for i, r := range s.routines {
+				if r.Type == diff.from.Type && r.Name() == diff.from.Name() {
+					s.routines = append(s.routines[0:i], s.routines[i+1:]...)
+					key, _ := storedProgramKey(r)
+					delete(s.programs, key)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return &ApplyRoutineNotFoundError{Type: diff.from.Type.ToString(), Routine: diff.from.Name()}
+			}
+		case *DropEventEntityDiff:
+			// We expect the event to exist
+			found := false
+			for i, e := range s.events {
+				if e.Name() == diff.from.Name() {
+					s.events = append(s.events[0:i], s.events[i+1:]...)
+					key, _ := storedProgramKey(e)
+					delete(s.programs, key)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return &ApplyEventNotFoundError{Event: diff.from.Name()}
+			}
+		case *AlterTriggerEntityDiff:
+			// We expect the trigger to exist
+			found := false
+			for i, t := range s.triggers {
+				if t.Name() == diff.from.Name() {
+					to, err := t.Apply(diff)
+					if err != nil {
+						return err
+					}
+					toCreateTriggerEntity, ok := to.(*CreateTriggerEntity)
+					if !ok {
+						return ErrEntityTypeMismatch
+					}
+					s.triggers[i] = toCreateTriggerEntity
+					key, _ := storedProgramKey(t)
+					s.programs[key] = toCreateTriggerEntity
+					found = true
+					break
+				}
+			}
+			if !found {
+				return &ApplyTriggerNotFoundError{Trigger: diff.from.Name()}
+			}
+		case *AlterRoutineEntityDiff:
+			// We expect the routine to exist
+			found := false
+			for i, r := range s.routines {
+				if r.Type == diff.from.Type && r.Name() == diff.from.Name() {
+					to, err := r.Apply(diff)
+					if err != nil {
+						return err
+					}
+					toCreateRoutineEntity, ok := to.(*CreateRoutineEntity)
+					if !ok {
+						return ErrEntityTypeMismatch
+					}
+					s.routines[i] = toCreateRoutineEntity
+					key, _ := storedProgramKey(r)
+					s.programs[key] = toCreateRoutineEntity
+					found = true
+					break
+				}
+			}
+			if !found {
+				return &ApplyRoutineNotFoundError{Type: diff.from.Type.ToString(), Routine: diff.from.Name()}
+			}
+		case *AlterEventEntityDiff:
+			// We expect the event to exist
+			found := false
+			for i, e := range s.events {
+				if e.Name() == diff.from.Name() {
+					to, err := e.Apply(diff)
+					if err != nil {
+						return err
+					}
+					toCreateEventEntity, ok := to.(*CreateEventEntity)
+					if !ok {
+						return ErrEntityTypeMismatch
+					}
+					s.events[i] = toCreateEventEntity
+					key, _ := storedProgramKey(e)
+					s.programs[key] = toCreateEventEntity
+					found = true
+					break
+				}
+			}
+			if !found {
+				return &ApplyEventNotFoundError{Event: diff.from.Name()}
+			}
 		default:
 			return &UnsupportedApplyOperationError{Statement: diff.CanonicalStatementString()}
 		}
@@ -604,9 +984,39 @@ func (s *Schema) apply(diffs []EntityDiff) error {
 	return nil
 }
 
+// dropTableTriggers removes the triggers defined on the given table, as MySQL implicitly drops them along with the table
+func (s *Schema) dropTableTriggers(tableName string) {
+	triggers := s.triggers[:0]
+	for _, t := range s.triggers {
+		if t.TableName() == tableName {
+			key, _ := storedProgramKey(t)
+			delete(s.programs, key)
+			continue
+		}
+		triggers = append(triggers, t)
+	}
+	s.triggers = triggers
+}
+
+// renameTableTriggers points the triggers defined on the given table to its new name, as MySQL
+// implicitly moves them along with the table
+func (s *Schema) renameTableTriggers(tableName string, newName sqlparser.IdentifierCS) {
+	for i, t := range s.triggers {
+		if t.TableName() != tableName {
+			continue
+		}
+		renamed := t.Clone().(*CreateTriggerEntity)
+		renamed.Table.Name = newName
+		s.triggers[i] = renamed
+		key, _ := storedProgramKey(renamed)
+		s.programs[key] = renamed
+	}
+}
+
 // Apply attempts to apply given list of diffs to the schema described by this object.
-// These diffs are CREATE/DROP/ALTER TABLE/VIEW.
+// These diffs are CREATE/DROP/ALTER TABLE/VIEW, and CREATE/DROP/ALTER (i.e. recreate) TRIGGER/PROCEDURE/FUNCTION/EVENT.
 // The operation does not modify this object. Instead, if successful, a new (modified) Schema is returned.
+// The resulting schema is validated, and an InvalidSchemaError is returned if the diffs make for an invalid schema.
 func (s *Schema) Apply(diffs []EntityDiff) (*Schema, error) {
 	dup := s.copy()
 	for k, v := range s.named {
@@ -615,5 +1025,8 @@ func (s *Schema) Apply(diffs []EntityDiff) (*Schema, error) {
 	if err := dup.apply(diffs); err != nil {
 		return nil, err
 	}
+	if err := dup.Validate(); err != nil {
+		return nil, err
+	}
 	return dup, nil
 }
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var createQueries = []string{
@@ -153,3 +154,40 @@ func TestCopy(t *testing.T) {
 	assert.Equal(t, schema.ToSQL(), schemaClone.ToSQL())
 	assert.False(t, schema == schemaClone)
 }
+
+func TestNewSchemaFromQueriesStoredPrograms(t *testing.T) {
+	queries := append(createQueries,
+		"create trigger t1_bi before insert on t1 for each row set new.id = 1",
+		"create event e1 on schedule every 1 day do delete from t1",
+		"create function f1() returns int return 1",
+		"create trigger t2_bi before insert on t2 for each row begin set new.id = 2; end",
+		"create procedure p1() select * from v1",
+		"create procedure f1() select 1",
+	)
+	schema, err := NewSchemaFromQueries(queries)
+	require.NoError(t, err)
+
+	// tables, then routines, then views, then triggers, then events
+	expectNames := append([]string{}, expectSortedTableNames...)
+	expectNames = append(expectNames, "f1", "p1", "f1")
+	expectNames = append(expectNames, expectSortedViewNames...)
+	expectNames = append(expectNames, "t1_bi", "t2_bi", "e1")
+	assert.Equal(t, expectNames, schema.EntityNames())
+	assert.Equal(t, []string{"t1_bi", "t2_bi"}, schema.TriggerNames())
+	assert.Equal(t, []string{"f1", "p1", "f1"}, schema.RoutineNames())
+	assert.Equal(t, []string{"e1"}, schema.EventNames())
+
+	assert.NotNil(t, schema.Trigger("t1_bi"))
+	assert.Nil(t, schema.Trigger("t1"))
+	assert.Nil(t, schema.Entity("t1_bi"))
+	assert.NotNil(t, schema.Procedure("p1"))
+	assert.Nil(t, schema.Function("p1"))
+	assert.True(t, schema.Function("f1").IsFunction())
+	assert.False(t, schema.Procedure("f1").IsFunction())
+	assert.NotNil(t, schema.Event("e1"))
+
+	// round trip
+	schema2, err := NewSchemaFromSQL(schema.ToSQL())
+	require.NoError(t, err)
+	assert.Equal(t, schema.ToSQL(), schema2.ToSQL())
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// AlterTriggerEntityDiff represents a change to a trigger. MySQL has no ALTER TRIGGER statement, and so
+// the diff is a DROP TRIGGER statement, followed by a subsequent CREATE TRIGGER diff.
+type AlterTriggerEntityDiff struct {
+	from        *CreateTriggerEntity
+	to          *CreateTriggerEntity
+	dropTrigger *sqlparser.DropStoredProgram
+	subsequent  *CreateTriggerEntityDiff
+}
+
+// IsEmpty implements EntityDiff
+func (d *AlterTriggerEntityDiff) IsEmpty() bool {
+	return d.Statement() == nil
+}
+
+// Entities implements EntityDiff
+func (d *AlterTriggerEntityDiff) Entities() (from Entity, to Entity) {
+	return d.from, d.to
+}
+
+// Statement implements EntityDiff
+func (d *AlterTriggerEntityDiff) Statement() sqlparser.Statement {
+	if d == nil {
+		return nil
+	}
+	return d.dropTrigger
+}
+
+// StatementString implements EntityDiff
+func (d *AlterTriggerEntityDiff) StatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.String(stmt)
+	}
+	return s
+}
+
+// CanonicalStatementString implements EntityDiff
+func (d *AlterTriggerEntityDiff) CanonicalStatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.CanonicalString(stmt)
+	}
+	return s
+}
+
+// SubsequentDiff implements EntityDiff
+func (d *AlterTriggerEntityDiff) SubsequentDiff() EntityDiff {
+	if d == nil || d.subsequent == nil {
+		return nil
+	}
+	return d.subsequent
+}
+
+// SetSubsequentDiff implements EntityDiff
+func (d *AlterTriggerEntityDiff) SetSubsequentDiff(subDiff EntityDiff) {
+	if d == nil {
+		return
+	}
+	if createDiff, ok := subDiff.(*CreateTriggerEntityDiff); ok {
+		d.subsequent = createDiff
+	} else {
+		d.subsequent = nil
+	}
+}
+
+type CreateTriggerEntityDiff struct {
+	createTrigger *sqlparser.CreateTrigger
+}
+
+// IsEmpty implements EntityDiff
+func (d *CreateTriggerEntityDiff) IsEmpty() bool {
+	return d.Statement() == nil
+}
+
+// Entities implements EntityDiff
+func (d *CreateTriggerEntityDiff) Entities() (from Entity, to Entity) {
+	return nil, &CreateTriggerEntity{CreateTrigger: d.createTrigger}
+}
+
+// Statement implements EntityDiff
+func (d *CreateTriggerEntityDiff) Statement() sqlparser.Statement {
+	if d == nil {
+		return nil
+	}
+	return d.createTrigger
+}
+
+// CreateTrigger returns the underlying sqlparser.CreateTrigger that was generated for the diff.
+func (d *CreateTriggerEntityDiff) CreateTrigger() *sqlparser.CreateTrigger {
+	if d == nil {
+		return nil
+	}
+	return d.createTrigger
+}
+
+// StatementString implements EntityDiff
+func (d *CreateTriggerEntityDiff) StatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.String(stmt)
+	}
+	return s
+}
+
+// CanonicalStatementString implements EntityDiff
+func (d *CreateTriggerEntityDiff) CanonicalStatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.CanonicalString(stmt)
+	}
+	return s
+}
+
+// SubsequentDiff implements EntityDiff
+func (d *CreateTriggerEntityDiff) SubsequentDiff() EntityDiff {
+	return nil
+}
+
+// SetSubsequentDiff implements EntityDiff
+func (d *CreateTriggerEntityDiff) SetSubsequentDiff(EntityDiff) {
+}
+
+type DropTriggerEntityDiff struct {
+	from        *CreateTriggerEntity
+	dropTrigger *sqlparser.DropStoredProgram
+}
+
+// IsEmpty implements EntityDiff
+func (d *DropTriggerEntityDiff) IsEmpty() bool {
+	return d.Statement() == nil
+}
+
+// Entities implements EntityDiff
+func (d *DropTriggerEntityDiff) Entities() (from Entity, to Entity) {
+	return d.from, nil
+}
+
+// Statement implements EntityDiff
+func (d *DropTriggerEntityDiff) Statement() sqlparser.Statement {
+	if d == nil {
+		return nil
+	}
+	return d.dropTrigger
+}
+
+// DropTrigger returns the underlying sqlparser.DropStoredProgram that was generated for the diff.
+func (d *DropTriggerEntityDiff) DropTrigger() *sqlparser.DropStoredProgram {
+	if d == nil {
+		return nil
+	}
+	return d.dropTrigger
+}
+
+// StatementString implements EntityDiff
+func (d *DropTriggerEntityDiff) StatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.String(stmt)
+	}
+	return s
+}
+
+// CanonicalStatementString implements EntityDiff
+func (d *DropTriggerEntityDiff) CanonicalStatementString() (s string) {
+	if stmt := d.Statement(); stmt != nil {
+		s = sqlparser.CanonicalString(stmt)
+	}
+	return s
+}
+
+// SubsequentDiff implements EntityDiff
+func (d *DropTriggerEntityDiff) SubsequentDiff() EntityDiff {
+	return nil
+}
+
+// SetSubsequentDiff implements EntityDiff
+func (d *DropTriggerEntityDiff) SetSubsequentDiff(EntityDiff) {
+}
+
+// CreateTriggerEntity stands for a TRIGGER construct. It contains the trigger's CREATE statement.
+type CreateTriggerEntity struct {
+	*sqlparser.CreateTrigger
+}
+
+func NewCreateTriggerEntity(c *sqlparser.CreateTrigger) (*CreateTriggerEntity, error) {
+	entity := &CreateTriggerEntity{CreateTrigger: c}
+	entity.normalize()
+	return entity, nil
+}
+
+func (c *CreateTriggerEntity) normalize() {
+	// IF NOT EXISTS is not part of the trigger's definition
+	c.CreateTrigger.IfNotExists = false
+}
+
+// Name implements Entity interface
+func (c *CreateTriggerEntity) Name() string {
+	return c.CreateTrigger.TriggerName.Name.String()
+}
+
+// TableName returns the name of the table on which the trigger is defined
+func (c *CreateTriggerEntity) TableName() string {
+	return c.CreateTrigger.Table.Name.String()
+}
+
+// Diff implements Entity interface function
+func (c *CreateTriggerEntity) Diff(other Entity, hints *DiffHints) (EntityDiff, error) {
+	otherCreateTrigger, ok := other.(*CreateTriggerEntity)
+	if !ok {
+		return nil, ErrEntityTypeMismatch
+	}
+	return c.TriggerDiff(otherCreateTrigger, hints)
+}
+
+// TriggerDiff compares this trigger statement with another trigger statement, and sees what it takes to
+// change this trigger to look like the other trigger.
+// It returns an AlterTriggerEntityDiff, which drops and recreates the trigger, if changes are found, or nil if not.
+// The trigger body is compared token by token, ignoring whitespace, comments, and the letter case of keywords and identifiers.
+func (c *CreateTriggerEntity) TriggerDiff(other *CreateTriggerEntity, _ *DiffHints) (*AlterTriggerEntityDiff, error) {
+	if c.identical(other) {
+		return nil, nil
+	}
+	diff := &AlterTriggerEntityDiff{
+		from:        c,
+		to:          other,
+		dropTrigger: c.Drop().(*DropTriggerEntityDiff).dropTrigger,
+		subsequent:  other.Create().(*CreateTriggerEntityDiff),
+	}
+	return diff, nil
+}
+
+// Create implements Entity interface
+func (c *CreateTriggerEntity) Create() EntityDiff {
+	return &CreateTriggerEntityDiff{createTrigger: c.CreateTrigger}
+}
+
+// Drop implements Entity interface
+func (c *CreateTriggerEntity) Drop() EntityDiff {
+	dropTrigger := &sqlparser.DropStoredProgram{
+		Type: sqlparser.TriggerProgramType,
+		Name: c.TriggerName,
+	}
+	return &DropTriggerEntityDiff{from: c, dropTrigger: dropTrigger}
+}
+
+// Apply attempts to apply given trigger diff onto the trigger defined by this entity.
+// This entity is unmodified. If successful, a new CREATE TRIGGER entity is returned.
+func (c *CreateTriggerEntity) Apply(diff EntityDiff) (Entity, error) {
+	alterDiff, ok := diff.(*AlterTriggerEntityDiff)
+	if !ok {
+		return nil, ErrEntityTypeMismatch
+	}
+	dup := alterDiff.to.Clone().(*CreateTriggerEntity)
+	dup.normalize()
+	return dup, nil
+}
+
+func (c *CreateTriggerEntity) Clone() Entity {
+	return &CreateTriggerEntity{CreateTrigger: sqlparser.CloneRefOfCreateTrigger(c.CreateTrigger)}
+}
+
+func (c *CreateTriggerEntity) identical(other *CreateTriggerEntity) bool {
+	if other == nil {
+		return false
+	}
+	return c.Name() == other.Name() &&
+		c.Timing == other.Timing &&
+		c.Event == other.Event &&
+		equalProgramText(c.Body, other.Body) &&
+		sqlparser.Equals.TableName(c.Table, other.Table) &&
+		sqlparser.Equals.RefOfDefiner(c.Definer, other.Definer) &&
+		sqlparser.Equals.RefOfParsedComments(c.Comments, other.Comments)
+}
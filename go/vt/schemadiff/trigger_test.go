@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestCreateTriggerDiff(t *testing.T) {
+	tt := []struct {
+		name  string
+		from  string
+		to    string
+		diffs []string
+	}{
+		{
+			name: "identical",
+			from: "create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create trigger t_bi before insert on t for each row set new.id = 1",
+		},
+		{
+			name: "identical, if not exists",
+			from: "create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create trigger if not exists t_bi before insert on t for each row set new.id = 1",
+		},
+		{
+			name: "identical, case change in keywords",
+			from: "create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "CREATE TRIGGER t_bi BEFORE INSERT ON t FOR EACH ROW set new.id = 1",
+		},
+		{
+			name: "identical, whitespace, comments and quoting in body",
+			from: "create trigger t_bi before insert on t for each row begin set new.id = 1; set @x = 'a'; end",
+			to:   "create trigger t_bi before insert on t for each row BEGIN\n  -- comment\n  SET NEW.`id` = 1;\n  set @x='a';\nEND",
+		},
+		{
+			name: "string literal case",
+			from: "create trigger t_bi before insert on t for each row set @x = 'a'",
+			to:   "create trigger t_bi before insert on t for each row set @x = 'A'",
+			diffs: []string{
+				"drop trigger t_bi",
+				"create trigger t_bi before insert on t for each row set @x = 'A'",
+			},
+		},
+		{
+			name: "body",
+			from: "create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create trigger t_bi before insert on t for each row set new.id = 2",
+			diffs: []string{
+				"drop trigger t_bi",
+				"create trigger t_bi before insert on t for each row set new.id = 2",
+			},
+		},
+		{
+			name: "timing and event",
+			from: "create trigger t_bi before insert on t for each row set @x = 1",
+			to:   "create trigger t_bi after delete on t for each row set @x = 1",
+			diffs: []string{
+				"drop trigger t_bi",
+				"create trigger t_bi after delete on t for each row set @x = 1",
+			},
+		},
+		{
+			name: "definer",
+			from: "create trigger t_bi before insert on t for each row set new.id = 1",
+			to:   "create definer = 'root'@'localhost' trigger t_bi before insert on t for each row set new.id = 1",
+			diffs: []string{
+				"drop trigger t_bi",
+				"create definer = 'root'@'localhost' trigger t_bi before insert on t for each row set new.id = 1",
+			},
+		},
+	}
+	hints := &DiffHints{}
+	for _, ts := range tt {
+		t.Run(ts.name, func(t *testing.T) {
+			fromStmt, err := sqlparser.ParseStrictDDL(ts.from)
+			require.NoError(t, err)
+			fromCreateTrigger, ok := fromStmt.(*sqlparser.CreateTrigger)
+			require.True(t, ok)
+
+			toStmt, err := sqlparser.ParseStrictDDL(ts.to)
+			require.NoError(t, err)
+			toCreateTrigger, ok := toStmt.(*sqlparser.CreateTrigger)
+			require.True(t, ok)
+
+			c, err := NewCreateTriggerEntity(fromCreateTrigger)
+			require.NoError(t, err)
+			other, err := NewCreateTriggerEntity(toCreateTrigger)
+			require.NoError(t, err)
+			alter, err := c.Diff(other, hints)
+			require.NoError(t, err)
+			if ts.diffs == nil {
+				assert.Nil(t, alter)
+				return
+			}
+			require.NotNil(t, alter)
+			require.False(t, alter.IsEmpty())
+
+			var diffs []string
+			for _, diff := range AllSubsequent(alter) {
+				diffs = append(diffs, diff.StatementString())
+			}
+			assert.Equal(t, ts.diffs, diffs)
+			for _, diff := range diffs {
+				// validate we can parse back the statement
+				_, err := sqlparser.ParseStrictDDL(diff)
+				assert.NoError(t, err)
+			}
+			{ // Validate "apply()" on "from" converges with "to"
+				applied, err := c.Apply(alter)
+				require.NoError(t, err)
+				appliedDiff, err := other.Diff(applied, hints)
+				require.NoError(t, err)
+				assert.Nil(t, appliedDiff)
+			}
+		})
+	}
+}
@@ -0,0 +1,542 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// columnTypeAliases maps column types to the type MySQL actually uses
+var columnTypeAliases = map[string]string{
+	"integer":   "int",
+	"bool":      "tinyint",
+	"boolean":   "tinyint",
+	"dec":       "decimal",
+	"numeric":   "decimal",
+	"fixed":     "decimal",
+	"varchar":   "char",
+	"varbinary": "binary",
+}
+
+// Validate runs a semantic validation of the schema, looking for problems which MySQL would reject:
+//   - keys, generated columns, partitions and constraints referencing nonexistent columns
+//   - views referencing nonexistent columns
+//   - foreign keys referencing nonexistent tables or columns, or referencing columns of incompatible types,
+//     or referencing columns which are not indexed
+//   - constraint names used more than once
+//
+// It returns nil if the schema is valid, or otherwise an InvalidSchemaError, which lists all problems found.
+func (s *Schema) Validate() error {
+	var errs []error
+	errs = append(errs, s.validateTables()...)
+	errs = append(errs, s.validateForeignKeys()...)
+	errs = append(errs, s.validateConstraintNames()...)
+	errs = append(errs, s.validateViews()...)
+	if len(errs) > 0 {
+		return &InvalidSchemaError{Errors: errs}
+	}
+	return nil
+}
+
+// validateTables validates each table's structure on its own
+func (s *Schema) validateTables() (errs []error) {
+	for _, t := range s.tables {
+		if err := t.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateConstraintNames validates that no two constraints share a name. In MySQL, the names of
+// foreign key constraints, as well as the names of check constraints, are unique per schema.
+func (s *Schema) validateConstraintNames() (errs []error) {
+	foreignKeyNames := map[string]string{}
+	checkNames := map[string]string{}
+	for _, t := range s.Tables() {
+		for _, cs := range t.CreateTable.TableSpec.Constraints {
+			names := checkNames
+			if _, ok := cs.Details.(*sqlparser.ForeignKeyDefinition); ok {
+				names = foreignKeyNames
+			}
+			name := cs.Name.Lowered()
+			if otherTable, ok := names[name]; ok {
+				errs = append(errs, &DuplicateConstraintNameError{Table: t.Name(), Constraint: cs.Name.String(), OtherTable: otherTable})
+				continue
+			}
+			names[name] = t.Name()
+		}
+	}
+	return errs
+}
+
+// validateForeignKeys validates that foreign keys reference existing tables and columns, that the referenced
+// columns are of compatible types, and that they are indexed. Foreign keys referencing a table in another
+// schema are not validated.
+func (s *Schema) validateForeignKeys() (errs []error) {
+	for _, t := range s.Tables() {
+		for _, cs := range t.CreateTable.TableSpec.Constraints {
+			fk, ok := cs.Details.(*sqlparser.ForeignKeyDefinition)
+			if !ok {
+				continue
+			}
+			ref := fk.ReferenceDefinition
+			if !ref.ReferencedTable.Qualifier.IsEmpty() {
+				continue
+			}
+			referencedTableName := ref.ReferencedTable.Name.String()
+			referencedTable, ok := s.named[referencedTableName].(*CreateTableEntity)
+			if !ok {
+				errs = append(errs, &ForeignKeyReferencedTableNotFoundError{Table: t.Name(), Constraint: cs.Name.String(), ReferencedTable: referencedTableName})
+				continue
+			}
+			if len(fk.Source) != len(ref.ReferencedColumns) {
+				errs = append(errs, &ForeignKeyColumnCountMismatchError{
+					Table:                 t.Name(),
+					Constraint:            cs.Name.String(),
+					ColumnCount:           len(fk.Source),
+					ReferencedTable:       referencedTableName,
+					ReferencedColumnCount: len(ref.ReferencedColumns),
+				})
+				continue
+			}
+			columnsValid := true
+			for i, referencedColName := range ref.ReferencedColumns {
+				referencedCol := referencedTable.column(referencedColName.String())
+				if referencedCol == nil {
+					errs = append(errs, &InvalidReferencedColumnInForeignKeyConstraintError{
+						Table:            t.Name(),
+						Constraint:       cs.Name.String(),
+						ReferencedTable:  referencedTableName,
+						ReferencedColumn: referencedColName.String(),
+					})
+					columnsValid = false
+					continue
+				}
+				col := t.column(fk.Source[i].String())
+				if col == nil {
+					// Already reported by the table's own validation
+					columnsValid = false
+					continue
+				}
+				if !t.columnTypeCompatible(col, referencedTable, referencedCol) {
+					errs = append(errs, &ForeignKeyColumnTypeMismatchError{
+						Table:            t.Name(),
+						Constraint:       cs.Name.String(),
+						Column:           col.Name.String(),
+						ReferencedTable:  referencedTableName,
+						ReferencedColumn: referencedCol.Name.String(),
+					})
+				}
+			}
+			if columnsValid && !referencedTable.hasIndexPrefix(ref.ReferencedColumns) {
+				errs = append(errs, &MissingForeignKeyReferencedIndexError{Table: t.Name(), Constraint: cs.Name.String(), ReferencedTable: referencedTableName})
+			}
+		}
+	}
+	return errs
+}
+
+// column returns the definition of the named column, or nil if there is no such column
+func (c *CreateTableEntity) column(name string) *sqlparser.ColumnDefinition {
+	for _, col := range c.CreateTable.TableSpec.Columns {
+		if strings.EqualFold(col.Name.String(), name) {
+			return col
+		}
+	}
+	return nil
+}
+
+// hasIndexPrefix returns true when the table has a key whose first columns are the given columns, in order
+func (c *CreateTableEntity) hasIndexPrefix(columns sqlparser.Columns) bool {
+	for _, key := range c.CreateTable.TableSpec.Indexes {
+		if len(key.Columns) < len(columns) {
+			continue
+		}
+		prefixFound := true
+		for i, col := range columns {
+			if key.Columns[i].Column.Lowered() != col.Lowered() {
+				prefixFound = false
+				break
+			}
+		}
+		if prefixFound {
+			return true
+		}
+	}
+	if len(columns) == 1 {
+		// A column may be indexed by its own UNIQUE KEY option
+		if col := c.column(columns[0].String()); col != nil && col.Type.Options != nil && col.Type.Options.KeyOpt != sqlparser.ColKeyNone {
+			return true
+		}
+	}
+	return false
+}
+
+// columnCollation returns the collation of a textual column, which is either explicitly defined
+// by the column, or else inherited from the table
+func (c *CreateTableEntity) columnCollation(col *sqlparser.ColumnDefinition) string {
+	if col.Type.Options != nil && col.Type.Options.Collate != "" {
+		return col.Type.Options.Collate
+	}
+	if col.Type.Charset.Name != "" {
+		return defaultCharsetCollation(col.Type.Charset.Name)
+	}
+	tableCharset := defaultCharset()
+	tableCollation := ""
+	for _, option := range c.CreateTable.TableSpec.Options {
+		switch strings.ToUpper(option.Name) {
+		case "CHARSET":
+			tableCharset = option.String
+		case "COLLATE":
+			tableCollation = option.String
+		}
+	}
+	if tableCollation == "" {
+		tableCollation = defaultCharsetCollation(tableCharset)
+	}
+	return tableCollation
+}
+
+// columnTypeCompatible returns true when the given column of this table may reference the given column of
+// the referenced table in a foreign key constraint. See https://dev.mysql.com/doc/refman/8.0/en/create-table-foreign-keys.html:
+// the size and sign of integer types must be the same, the precision and scale of decimal types must be the same,
+// the length of string types need not be the same, and textual columns must have the same collation.
+func (c *CreateTableEntity) columnTypeCompatible(col *sqlparser.ColumnDefinition, referencedTable *CreateTableEntity, referencedCol *sqlparser.ColumnDefinition) bool {
+	baseType := func(colType string) string {
+		t := strings.ToLower(colType)
+		if alias, ok := columnTypeAliases[t]; ok {
+			return alias
+		}
+		return t
+	}
+	colType := baseType(col.Type.Type)
+	if colType != baseType(referencedCol.Type.Type) {
+		return false
+	}
+	if col.Type.Unsigned != referencedCol.Type.Unsigned {
+		return false
+	}
+	switch colType {
+	case "decimal":
+		if !sqlparser.Equals.RefOfLiteral(col.Type.Length, referencedCol.Type.Length) ||
+			!sqlparser.Equals.RefOfLiteral(col.Type.Scale, referencedCol.Type.Scale) {
+			return false
+		}
+	}
+	if charsetTypes[strings.ToLower(col.Type.Type)] {
+		if c.columnCollation(col) != referencedTable.columnCollation(referencedCol) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateViews validates that views only reference existing columns of the tables and views they read from.
+// Views are validated in dependency order, so that the columns of a view are known by the time another
+// view reads from it.
+func (s *Schema) validateViews() (errs []error) {
+	v := newViewsValidator(s)
+	for _, view := range s.Views() {
+		v.validateView(view)
+	}
+	return v.errs
+}
+
+// newViewsValidator returns a validator which knows the columns of the schema's tables
+func newViewsValidator(s *Schema) *viewsValidator {
+	v := &viewsValidator{
+		columns: map[string][]string{},
+	}
+	for _, t := range s.Tables() {
+		v.columns[t.Name()] = t.columnNames()
+	}
+	return v
+}
+
+// columnNames returns the names of the table's columns, in order
+func (c *CreateTableEntity) columnNames() (names []string) {
+	for _, col := range c.CreateTable.TableSpec.Columns {
+		names = append(names, col.Name.String())
+	}
+	return names
+}
+
+// validateView validates the column references of a view, and then maps the view's own columns
+func (v *viewsValidator) validateView(view *CreateViewEntity) {
+	v.view = view.Name()
+	v.reported = map[string]bool{}
+	v.validateSelectStatement(view.Select, nil)
+	if columns, ok := v.selectStatementColumns(view.Select, nil); ok {
+		if len(view.Columns) > 0 {
+			columns = nil
+			for _, col := range view.Columns {
+				columns = append(columns, col.String())
+			}
+		}
+		v.columns[view.Name()] = columns
+	}
+}
+
+// viewsValidator resolves the columns referenced by views
+type viewsValidator struct {
+	// columns maps tables and views by name to their columns. A view whose columns
+	// cannot be determined (e.g. because it reads from a derived table with a star expression) is not mapped.
+	columns  map[string][]string
+	view     string
+	reported map[string]bool
+	errs     []error
+}
+
+// selectScope lists the table expressions visible by a SELECT, mapped by their aliases
+type selectScope struct {
+	sources map[string]*scopeSource
+	ordered []*scopeSource
+	// unknown is true when the scope has a table expression which cannot be resolved
+	unknown bool
+	outer   *selectScope
+}
+
+// scopeSource is a table, a view or a derived table, read by a SELECT
+type scopeSource struct {
+	columns []string
+	// known is false when the source's columns cannot be determined
+	known bool
+}
+
+func (s *scopeSource) hasColumn(name string) bool {
+	for _, col := range s.columns {
+		if strings.EqualFold(col, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *viewsValidator) addSource(scope *selectScope, alias string, source *scopeSource) {
+	scope.sources[alias] = source
+	scope.ordered = append(scope.ordered, source)
+}
+
+// addTableExpr adds the sources of a FROM clause expression to the scope
+func (v *viewsValidator) addTableExpr(scope *selectScope, tableExpr sqlparser.TableExpr) {
+	switch tableExpr := tableExpr.(type) {
+	case *sqlparser.AliasedTableExpr:
+		switch expr := tableExpr.Expr.(type) {
+		case sqlparser.TableName:
+			alias := expr.Name.String()
+			if !tableExpr.As.IsEmpty() {
+				alias = tableExpr.As.String()
+			}
+			source := &scopeSource{}
+			if expr.Qualifier.IsEmpty() {
+				source.columns, source.known = v.columns[expr.Name.String()]
+			}
+			v.addSource(scope, alias, source)
+		case *sqlparser.DerivedTable:
+			source := &scopeSource{}
+			source.columns, source.known = v.selectStatementColumns(expr.Select, scope.outer)
+			if len(tableExpr.Columns) > 0 {
+				source.columns = nil
+				for _, col := range tableExpr.Columns {
+					source.columns = append(source.columns, col.String())
+				}
+				source.known = true
+			}
+			v.addSource(scope, tableExpr.As.String(), source)
+		default:
+			scope.unknown = true
+		}
+	case *sqlparser.ParenTableExpr:
+		for _, expr := range tableExpr.Exprs {
+			v.addTableExpr(scope, expr)
+		}
+	case *sqlparser.JoinTableExpr:
+		v.addTableExpr(scope, tableExpr.LeftExpr)
+		v.addTableExpr(scope, tableExpr.RightExpr)
+	default:
+		scope.unknown = true
+	}
+}
+
+func (v *viewsValidator) newSelectScope(sel *sqlparser.Select, outer *selectScope) *selectScope {
+	scope := &selectScope{
+		sources: map[string]*scopeSource{},
+		outer:   outer,
+	}
+	for _, tableExpr := range sel.From {
+		v.addTableExpr(scope, tableExpr)
+	}
+	return scope
+}
+
+// selectStatementColumns returns the names of the columns produced by a SELECT statement. It returns false
+// if the columns cannot be determined.
+func (v *viewsValidator) selectStatementColumns(stmt sqlparser.SelectStatement, outer *selectScope) ([]string, bool) {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Union:
+		return v.selectStatementColumns(stmt.Left, outer)
+	case *sqlparser.Select:
+		scope := v.newSelectScope(stmt, outer)
+		var columns []string
+		for _, selectExpr := range stmt.SelectExprs {
+			switch selectExpr := selectExpr.(type) {
+			case *sqlparser.AliasedExpr:
+				switch {
+				case !selectExpr.As.IsEmpty():
+					columns = append(columns, selectExpr.As.String())
+				default:
+					if colName, ok := selectExpr.Expr.(*sqlparser.ColName); ok {
+						columns = append(columns, colName.Name.String())
+					} else {
+						columns = append(columns, sqlparser.String(selectExpr.Expr))
+					}
+				}
+			case *sqlparser.StarExpr:
+				if scope.unknown {
+					return nil, false
+				}
+				sources := scope.ordered
+				if !selectExpr.TableName.IsEmpty() {
+					source, ok := scope.sources[selectExpr.TableName.Name.String()]
+					if !ok {
+						return nil, false
+					}
+					sources = []*scopeSource{source}
+				}
+				for _, source := range sources {
+					if !source.known {
+						return nil, false
+					}
+					columns = append(columns, source.columns...)
+				}
+			default:
+				return nil, false
+			}
+		}
+		return columns, true
+	}
+	return nil, false
+}
+
+// validateSelectStatement validates the column references of a SELECT statement, including its subqueries
+// and derived tables. Columns may also reference the given outer scope, as in correlated subqueries.
+func (v *viewsValidator) validateSelectStatement(stmt sqlparser.SelectStatement, outer *selectScope) {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Union:
+		v.validateSelectStatement(stmt.Left, outer)
+		v.validateSelectStatement(stmt.Right, outer)
+	case *sqlparser.Select:
+		scope := v.newSelectScope(stmt, outer)
+		aliases := map[string]bool{}
+		for _, selectExpr := range stmt.SelectExprs {
+			if aliasedExpr, ok := selectExpr.(*sqlparser.AliasedExpr); ok && !aliasedExpr.As.IsEmpty() {
+				aliases[aliasedExpr.As.Lowered()] = true
+			}
+		}
+		validate := func(node sqlparser.SQLNode, allowAliases bool) {
+			if node == nil {
+				return
+			}
+			_ = sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
+				switch node := node.(type) {
+				case *sqlparser.Subquery:
+					v.validateSelectStatement(node.Select, scope)
+					return false, nil
+				case *sqlparser.ColName:
+					if allowAliases && node.Qualifier.IsEmpty() && aliases[node.Name.Lowered()] {
+						return true, nil
+					}
+					v.validateColumn(node, scope)
+				}
+				return true, nil
+			}, node)
+		}
+		var validateTableExpr func(tableExpr sqlparser.TableExpr)
+		validateTableExpr = func(tableExpr sqlparser.TableExpr) {
+			switch tableExpr := tableExpr.(type) {
+			case *sqlparser.AliasedTableExpr:
+				if derivedTable, ok := tableExpr.Expr.(*sqlparser.DerivedTable); ok {
+					if derivedTable.Lateral {
+						v.validateSelectStatement(derivedTable.Select, scope)
+					} else {
+						v.validateSelectStatement(derivedTable.Select, outer)
+					}
+				}
+			case *sqlparser.ParenTableExpr:
+				for _, expr := range tableExpr.Exprs {
+					validateTableExpr(expr)
+				}
+			case *sqlparser.JoinTableExpr:
+				validateTableExpr(tableExpr.LeftExpr)
+				validateTableExpr(tableExpr.RightExpr)
+				if tableExpr.Condition != nil {
+					validate(tableExpr.Condition.On, false)
+				}
+			}
+		}
+		for _, tableExpr := range stmt.From {
+			validateTableExpr(tableExpr)
+		}
+		validate(stmt.SelectExprs, false)
+		if stmt.Where != nil {
+			validate(stmt.Where.Expr, false)
+		}
+		validate(stmt.GroupBy, true)
+		if stmt.Having != nil {
+			validate(stmt.Having.Expr, true)
+		}
+		validate(stmt.OrderBy, true)
+	}
+}
+
+// validateColumn validates that a column reference resolves to a column of a table expression in scope
+func (v *viewsValidator) validateColumn(colName *sqlparser.ColName, scope *selectScope) {
+	qualifier := colName.Qualifier.Name.String()
+	for s := scope; s != nil; s = s.outer {
+		if s.unknown {
+			return
+		}
+		if qualifier != "" {
+			if source, ok := s.sources[qualifier]; ok {
+				if !source.known || source.hasColumn(colName.Name.String()) {
+					return
+				}
+				v.reportInvalidColumn(qualifier, colName.Name.String())
+				return
+			}
+			continue
+		}
+		for _, source := range s.ordered {
+			if !source.known || source.hasColumn(colName.Name.String()) {
+				return
+			}
+		}
+	}
+	v.reportInvalidColumn(qualifier, colName.Name.String())
+}
+
+func (v *viewsValidator) reportInvalidColumn(table string, column string) {
+	key := strings.ToLower(table + "." + column)
+	if v.reported[key] {
+		return
+	}
+	v.reported[key] = true
+	v.errs = append(v.errs, &InvalidColumnInViewError{View: v.view, Table: table, Column: column})
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemadiff
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchema(t *testing.T) {
+	tt := []struct {
+		name    string
+		queries []string
+		errs    []error
+	}{
+		{
+			name:    "valid schema",
+			queries: createQueries,
+		},
+		{
+			name: "key on nonexistent column",
+			queries: []string{
+				"create table t(id int primary key, i int, key i_idx (i, z))",
+			},
+			errs: []error{
+				&InvalidColumnInKeyError{Table: "t", Column: "z", Key: "i_idx"},
+			},
+		},
+		{
+			name: "view columns",
+			queries: []string{
+				"create table t1(id int primary key, i int)",
+				"create table t2(id int primary key, t1_id int, name varchar(32))",
+				"create view v1 as select t1.id, t1.i as i1, t2.name, count(*) as cnt from t1 join t2 on (t1.id = t2.t1_id) where i > 0 group by t1.id, i1, t2.name having cnt > 1 order by i1",
+				"create view v2 (x, y, z, w) as select * from v1",
+				"create view v3 as select x, y, d.total from v2, (select sum(id) as total from t1) as d where v2.z in (select name from t2 where t2.id = v2.x)",
+				"create view v4 as select * from t1 union select id, t1_id from t2",
+			},
+		},
+		{
+			name: "view with nonexistent columns",
+			queries: []string{
+				"create table t1(id int primary key, i int)",
+				"create table t2(id int primary key, t1_id int)",
+				"create view v1 as select t1.id, t1.z, w from t1 join t2 on (t1.id = t2.nonexistent) where w > 0",
+				"create view v2 as select id, i1 from v1",
+				"create view v3 as select id from t1 where exists (select 1 from t2 where t2.t1_id = t1.z2)",
+				"create view v4 as select d.id, d.i from (select id from t1) as d",
+			},
+			errs: []error{
+				&InvalidColumnInViewError{View: "v1", Table: "t2", Column: "nonexistent"},
+				&InvalidColumnInViewError{View: "v1", Table: "t1", Column: "z"},
+				&InvalidColumnInViewError{View: "v1", Column: "w"},
+				&InvalidColumnInViewError{View: "v3", Table: "t1", Column: "z2"},
+				&InvalidColumnInViewError{View: "v4", Table: "d", Column: "i"},
+				&InvalidColumnInViewError{View: "v2", Column: "i1"},
+			},
+		},
+		{
+			name: "valid foreign keys",
+			queries: []string{
+				"create table parent(id int primary key, uuid varchar(40) charset ascii, code varchar(16), key uuid_code_idx(uuid, code))",
+				"create table child(id int primary key, parent_id int, uuid varchar(64) charset ascii, code char(16), constraint child_parent_fk foreign key (parent_id) references parent(id), constraint child_uuid_fk foreign key (uuid, code) references parent(uuid, code))",
+				"create table node(id bigint unsigned primary key, parent_id bigint unsigned, constraint node_parent_fk foreign key (parent_id) references node(id))",
+				"create table remote(id int primary key, parent_id int, constraint remote_fk foreign key (parent_id) references other_schema.parent(id))",
+			},
+		},
+		{
+			name: "invalid foreign keys",
+			queries: []string{
+				"create table parent(id int primary key, i bigint, uuid varchar(40) charset ascii, code varchar(16))",
+				"create table child1(id int primary key, parent_id int, constraint child1_fk foreign key (parent_id) references nonexistent(id))",
+				"create table child2(id int primary key, parent_id int unsigned, constraint child2_fk foreign key (parent_id) references parent(id))",
+				"create table child3(id int primary key, uuid varchar(40) charset utf8mb4, constraint child3_fk foreign key (uuid) references parent(uuid))",
+				"create table child4(id int primary key, parent_id int, constraint child4_fk foreign key (parent_id) references parent(z))",
+				"create table child5(id int primary key, i bigint, constraint child5_fk foreign key (i) references parent(i))",
+				"create table child6(id int primary key, parent_id int, constraint child6_fk foreign key (id, parent_id) references parent(id))",
+				"create view v as select 1",
+				"create table child7(id int primary key, v_id int, constraint child7_fk foreign key (v_id) references v(id))",
+			},
+			errs: []error{
+				&ForeignKeyReferencedTableNotFoundError{Table: "child1", Constraint: "child1_fk", ReferencedTable: "nonexistent"},
+				&ForeignKeyColumnTypeMismatchError{Table: "child2", Constraint: "child2_fk", Column: "parent_id", ReferencedTable: "parent", ReferencedColumn: "id"},
+				&ForeignKeyColumnTypeMismatchError{Table: "child3", Constraint: "child3_fk", Column: "uuid", ReferencedTable: "parent", ReferencedColumn: "uuid"},
+				&MissingForeignKeyReferencedIndexError{Table: "child3", Constraint: "child3_fk", ReferencedTable: "parent"},
+				&InvalidReferencedColumnInForeignKeyConstraintError{Table: "child4", Constraint: "child4_fk", ReferencedTable: "parent", ReferencedColumn: "z"},
+				&MissingForeignKeyReferencedIndexError{Table: "child5", Constraint: "child5_fk", ReferencedTable: "parent"},
+				&ForeignKeyColumnCountMismatchError{Table: "child6", Constraint: "child6_fk", ColumnCount: 2, ReferencedTable: "parent", ReferencedColumnCount: 1},
+				&ForeignKeyReferencedTableNotFoundError{Table: "child7", Constraint: "child7_fk", ReferencedTable: "v"},
+			},
+		},
+		{
+			name: "duplicate constraint names",
+			queries: []string{
+				"create table t1(id int primary key, i int, constraint c_chk check (i > 0), constraint c_fk foreign key (i) references t1(id))",
+				"create table t2(id int primary key, i int, constraint c_chk check (i > 0), constraint c_fk foreign key (i) references t1(id))",
+				"create table t3(id int primary key, i int, check (i > 0), foreign key (i) references t1(id))",
+			},
+			errs: []error{
+				&DuplicateConstraintNameError{Table: "t2", Constraint: "c_chk", OtherTable: "t1"},
+				&DuplicateConstraintNameError{Table: "t2", Constraint: "c_fk", OtherTable: "t1"},
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			schema, err := NewSchemaFromQueries(tc.queries)
+			require.NoError(t, err)
+			err = schema.Validate()
+			if len(tc.errs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			var invalidSchemaErr *InvalidSchemaError
+			require.True(t, errors.As(err, &invalidSchemaErr))
+			assert.Equal(t, tc.errs, invalidSchemaErr.Errors)
+		})
+	}
+}
+
+func TestSchemaApplyValidation(t *testing.T) {
+	schema1, err := NewSchemaFromSQL("create table t(id int primary key, i int); create view v as select id, i from t")
+	require.NoError(t, err)
+	// schema2's view reads a column which its table does not have, and is thus invalid
+	schema2, err := NewSchemaFromSQL("create table t(id int primary key); create view v as select id, i from t")
+	require.NoError(t, err)
+	assert.Error(t, schema2.Validate())
+
+	diffs, err := schema1.Diff(schema2, &DiffHints{})
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.True(t, strings.HasPrefix(diffs[0].CanonicalStatementString(), "ALTER TABLE `t` DROP COLUMN `i`"))
+
+	_, err = schema1.Apply(diffs)
+	assert.EqualError(t, err, (&InvalidSchemaError{Errors: []error{&InvalidColumnInViewError{View: "v", Column: "i"}}}).Error())
+}
@@ -379,7 +379,9 @@ func init() {
 
 	// These are the binaries that make gRPC calls.
 	for _, cmd := range []string{
+		"schemadiff",
 		"vtbackup",
+		"vtcdc",
 		"vtcombo",
 		"vtctl",
 		"vtctlclient",
@@ -539,6 +539,57 @@ type (
 		Comments    *ParsedComments
 	}
 
+	// CreateTrigger represents a CREATE TRIGGER statement.
+	// The trigger body, including its optional FOLLOWS or PRECEDES clause, is not parsed, and is kept as written.
+	CreateTrigger struct {
+		TriggerName TableName
+		Definer     *Definer
+		IfNotExists bool
+		Timing      TriggerTiming
+		Event       TriggerEvent
+		Table       TableName
+		Body        string
+		Comments    *ParsedComments
+	}
+
+	// TriggerTiming is an enum for CreateTrigger.Timing
+	TriggerTiming int8
+
+	// TriggerEvent is an enum for CreateTrigger.Event
+	TriggerEvent int8
+
+	// CreateRoutine represents a CREATE PROCEDURE or a CREATE FUNCTION statement.
+	// The routine's parameters, characteristics and body are not parsed, and are kept as written.
+	CreateRoutine struct {
+		Type        StoredProgramType
+		RoutineName TableName
+		Definer     *Definer
+		IfNotExists bool
+		Definition  string
+		Comments    *ParsedComments
+	}
+
+	// CreateEvent represents a CREATE EVENT statement.
+	// The event's schedule, options and body are not parsed, and are kept as written.
+	CreateEvent struct {
+		EventName   TableName
+		Definer     *Definer
+		IfNotExists bool
+		Definition  string
+		Comments    *ParsedComments
+	}
+
+	// DropStoredProgram represents a DROP TRIGGER, DROP PROCEDURE, DROP FUNCTION or DROP EVENT statement.
+	DropStoredProgram struct {
+		Type     StoredProgramType
+		Name     TableName
+		IfExists bool
+		Comments *ParsedComments
+	}
+
+	// StoredProgramType is an enum for the type of a stored program: a trigger, a procedure, a function or an event
+	StoredProgramType int8
+
 	// Definer stores the user for AlterView and CreateView definers
 	Definer struct {
 		Name    string
@@ -719,6 +770,10 @@ func (*AlterDatabase) iStatement()       {}
 func (*CreateTable) iStatement()         {}
 func (*CreateView) iStatement()          {}
 func (*AlterView) iStatement()           {}
+func (*CreateTrigger) iStatement()       {}
+func (*CreateRoutine) iStatement()       {}
+func (*CreateEvent) iStatement()         {}
+func (*DropStoredProgram) iStatement()   {}
 func (*LockTables) iStatement()          {}
 func (*UnlockTables) iStatement()        {}
 func (*AlterTable) iStatement()          {}
@@ -749,6 +804,11 @@ func (*AlterTable) iDDLStatement()    {}
 func (*TruncateTable) iDDLStatement() {}
 func (*RenameTable) iDDLStatement()   {}
 
+func (*CreateTrigger) iDDLStatement()     {}
+func (*CreateRoutine) iDDLStatement()     {}
+func (*CreateEvent) iDDLStatement()       {}
+func (*DropStoredProgram) iDDLStatement() {}
+
 func (*AddConstraintDefinition) iAlterOption() {}
 func (*AddIndexDefinition) iAlterOption()      {}
 func (*AddColumns) iAlterOption()              {}
@@ -832,9 +892,41 @@ func (node *DropView) IsFullyParsed() bool {
 	return true
 }
 
+// IsFullyParsed implements the DDLStatement interface
+func (node *CreateTrigger) IsFullyParsed() bool {
+	return true
+}
+
+// IsFullyParsed implements the DDLStatement interface
+func (node *CreateRoutine) IsFullyParsed() bool {
+	return true
+}
+
+// IsFullyParsed implements the DDLStatement interface
+func (node *CreateEvent) IsFullyParsed() bool {
+	return true
+}
+
+// IsFullyParsed implements the DDLStatement interface
+func (node *DropStoredProgram) IsFullyParsed() bool {
+	return true
+}
+
 // SetFullyParsed implements the DDLStatement interface
 func (node *DropView) SetFullyParsed(fullyParsed bool) {}
 
+// SetFullyParsed implements the DDLStatement interface
+func (node *CreateTrigger) SetFullyParsed(fullyParsed bool) {}
+
+// SetFullyParsed implements the DDLStatement interface
+func (node *CreateRoutine) SetFullyParsed(fullyParsed bool) {}
+
+// SetFullyParsed implements the DDLStatement interface
+func (node *CreateEvent) SetFullyParsed(fullyParsed bool) {}
+
+// SetFullyParsed implements the DDLStatement interface
+func (node *DropStoredProgram) SetFullyParsed(fullyParsed bool) {}
+
 // IsFullyParsed implements the DDLStatement interface
 func (node *DropTable) IsFullyParsed() bool {
 	return true
@@ -881,6 +973,26 @@ func (node *DropView) IsTemporary() bool {
 	return false
 }
 
+// IsTemporary implements the DDLStatement interface
+func (node *CreateTrigger) IsTemporary() bool {
+	return false
+}
+
+// IsTemporary implements the DDLStatement interface
+func (node *CreateRoutine) IsTemporary() bool {
+	return false
+}
+
+// IsTemporary implements the DDLStatement interface
+func (node *CreateEvent) IsTemporary() bool {
+	return false
+}
+
+// IsTemporary implements the DDLStatement interface
+func (node *DropStoredProgram) IsTemporary() bool {
+	return false
+}
+
 // IsTemporary implements the DDLStatement interface
 func (node *DropTable) IsTemporary() bool {
 	return node.Temp
@@ -921,6 +1033,26 @@ func (node *DropView) GetTable() TableName {
 	return TableName{}
 }
 
+// GetTable implements the DDLStatement interface
+func (node *CreateTrigger) GetTable() TableName {
+	return node.TriggerName
+}
+
+// GetTable implements the DDLStatement interface
+func (node *CreateRoutine) GetTable() TableName {
+	return node.RoutineName
+}
+
+// GetTable implements the DDLStatement interface
+func (node *CreateEvent) GetTable() TableName {
+	return node.EventName
+}
+
+// GetTable implements the DDLStatement interface
+func (node *DropStoredProgram) GetTable() TableName {
+	return node.Name
+}
+
 // GetTable implements the DDLStatement interface
 func (node *DropTable) GetTable() TableName {
 	return TableName{}
@@ -971,6 +1103,26 @@ func (node *DropView) GetAction() DDLAction {
 	return DropDDLAction
 }
 
+// GetAction implements the DDLStatement interface
+func (node *CreateTrigger) GetAction() DDLAction {
+	return CreateDDLAction
+}
+
+// GetAction implements the DDLStatement interface
+func (node *CreateRoutine) GetAction() DDLAction {
+	return CreateDDLAction
+}
+
+// GetAction implements the DDLStatement interface
+func (node *CreateEvent) GetAction() DDLAction {
+	return CreateDDLAction
+}
+
+// GetAction implements the DDLStatement interface
+func (node *DropStoredProgram) GetAction() DDLAction {
+	return DropDDLAction
+}
+
 // GetOptLike implements the DDLStatement interface
 func (node *CreateTable) GetOptLike() *OptLike {
 	return node.OptLike
@@ -1011,6 +1163,26 @@ func (node *DropView) GetOptLike() *OptLike {
 	return nil
 }
 
+// GetOptLike implements the DDLStatement interface
+func (node *CreateTrigger) GetOptLike() *OptLike {
+	return nil
+}
+
+// GetOptLike implements the DDLStatement interface
+func (node *CreateRoutine) GetOptLike() *OptLike {
+	return nil
+}
+
+// GetOptLike implements the DDLStatement interface
+func (node *CreateEvent) GetOptLike() *OptLike {
+	return nil
+}
+
+// GetOptLike implements the DDLStatement interface
+func (node *DropStoredProgram) GetOptLike() *OptLike {
+	return nil
+}
+
 // GetIfExists implements the DDLStatement interface
 func (node *RenameTable) GetIfExists() bool {
 	return false
@@ -1051,6 +1223,26 @@ func (node *DropView) GetIfExists() bool {
 	return node.IfExists
 }
 
+// GetIfExists implements the DDLStatement interface
+func (node *CreateTrigger) GetIfExists() bool {
+	return false
+}
+
+// GetIfExists implements the DDLStatement interface
+func (node *CreateRoutine) GetIfExists() bool {
+	return false
+}
+
+// GetIfExists implements the DDLStatement interface
+func (node *CreateEvent) GetIfExists() bool {
+	return false
+}
+
+// GetIfExists implements the DDLStatement interface
+func (node *DropStoredProgram) GetIfExists() bool {
+	return node.IfExists
+}
+
 // GetIfNotExists implements the DDLStatement interface
 func (node *RenameTable) GetIfNotExists() bool {
 	return false
@@ -1091,6 +1283,26 @@ func (node *DropView) GetIfNotExists() bool {
 	return false
 }
 
+// GetIfNotExists implements the DDLStatement interface
+func (node *CreateTrigger) GetIfNotExists() bool {
+	return node.IfNotExists
+}
+
+// GetIfNotExists implements the DDLStatement interface
+func (node *CreateRoutine) GetIfNotExists() bool {
+	return node.IfNotExists
+}
+
+// GetIfNotExists implements the DDLStatement interface
+func (node *CreateEvent) GetIfNotExists() bool {
+	return node.IfNotExists
+}
+
+// GetIfNotExists implements the DDLStatement interface
+func (node *DropStoredProgram) GetIfNotExists() bool {
+	return false
+}
+
 // GetIsReplace implements the DDLStatement interface
 func (node *RenameTable) GetIsReplace() bool {
 	return false
@@ -1131,6 +1343,26 @@ func (node *DropView) GetIsReplace() bool {
 	return false
 }
 
+// GetIsReplace implements the DDLStatement interface
+func (node *CreateTrigger) GetIsReplace() bool {
+	return false
+}
+
+// GetIsReplace implements the DDLStatement interface
+func (node *CreateRoutine) GetIsReplace() bool {
+	return false
+}
+
+// GetIsReplace implements the DDLStatement interface
+func (node *CreateEvent) GetIsReplace() bool {
+	return false
+}
+
+// GetIsReplace implements the DDLStatement interface
+func (node *DropStoredProgram) GetIsReplace() bool {
+	return false
+}
+
 // GetTableSpec implements the DDLStatement interface
 func (node *CreateTable) GetTableSpec() *TableSpec {
 	return node.TableSpec
@@ -1171,6 +1403,26 @@ func (node *DropView) GetTableSpec() *TableSpec {
 	return nil
 }
 
+// GetTableSpec implements the DDLStatement interface
+func (node *CreateTrigger) GetTableSpec() *TableSpec {
+	return nil
+}
+
+// GetTableSpec implements the DDLStatement interface
+func (node *CreateRoutine) GetTableSpec() *TableSpec {
+	return nil
+}
+
+// GetTableSpec implements the DDLStatement interface
+func (node *CreateEvent) GetTableSpec() *TableSpec {
+	return nil
+}
+
+// GetTableSpec implements the DDLStatement interface
+func (node *DropStoredProgram) GetTableSpec() *TableSpec {
+	return nil
+}
+
 // GetFromTables implements the DDLStatement interface
 func (node *RenameTable) GetFromTables() TableNames {
 	var fromTables TableNames
@@ -1210,6 +1462,26 @@ func (node *DropView) GetFromTables() TableNames {
 	return node.FromTables
 }
 
+// GetFromTables implements the DDLStatement interface
+func (node *CreateTrigger) GetFromTables() TableNames {
+	return nil
+}
+
+// GetFromTables implements the DDLStatement interface
+func (node *CreateRoutine) GetFromTables() TableNames {
+	return nil
+}
+
+// GetFromTables implements the DDLStatement interface
+func (node *CreateEvent) GetFromTables() TableNames {
+	return nil
+}
+
+// GetFromTables implements the DDLStatement interface
+func (node *DropStoredProgram) GetFromTables() TableNames {
+	return nil
+}
+
 // GetFromTables implements the DDLStatement interface
 func (node *AlterView) GetFromTables() TableNames {
 	return nil
@@ -1255,6 +1527,26 @@ func (node *DropView) SetFromTables(tables TableNames) {
 	node.FromTables = tables
 }
 
+// SetFromTables implements DDLStatement.
+func (node *CreateTrigger) SetFromTables(tables TableNames) {
+	// irrelevant
+}
+
+// SetFromTables implements DDLStatement.
+func (node *CreateRoutine) SetFromTables(tables TableNames) {
+	// irrelevant
+}
+
+// SetFromTables implements DDLStatement.
+func (node *CreateEvent) SetFromTables(tables TableNames) {
+	// irrelevant
+}
+
+// SetFromTables implements DDLStatement.
+func (node *DropStoredProgram) SetFromTables(tables TableNames) {
+	// irrelevant
+}
+
 // SetFromTables implements DDLStatement.
 func (node *AlterView) SetFromTables(tables TableNames) {
 	// irrelevant
@@ -1305,6 +1597,26 @@ func (node *DropView) SetComments(comments Comments) {
 	node.Comments = comments.Parsed()
 }
 
+// SetComments implements Commented interface.
+func (node *CreateTrigger) SetComments(comments Comments) {
+	node.Comments = comments.Parsed()
+}
+
+// SetComments implements Commented interface.
+func (node *CreateRoutine) SetComments(comments Comments) {
+	node.Comments = comments.Parsed()
+}
+
+// SetComments implements Commented interface.
+func (node *CreateEvent) SetComments(comments Comments) {
+	node.Comments = comments.Parsed()
+}
+
+// SetComments implements Commented interface.
+func (node *DropStoredProgram) SetComments(comments Comments) {
+	node.Comments = comments.Parsed()
+}
+
 // SetComments implements Commented interface.
 func (node *AlterView) SetComments(comments Comments) {
 	node.Comments = comments.Parsed()
@@ -1387,6 +1699,26 @@ func (node *DropView) GetParsedComments() *ParsedComments {
 	return node.Comments
 }
 
+// GetParsedComments implements Commented interface.
+func (node *CreateTrigger) GetParsedComments() *ParsedComments {
+	return node.Comments
+}
+
+// GetParsedComments implements Commented interface.
+func (node *CreateRoutine) GetParsedComments() *ParsedComments {
+	return node.Comments
+}
+
+// GetParsedComments implements Commented interface.
+func (node *CreateEvent) GetParsedComments() *ParsedComments {
+	return node.Comments
+}
+
+// GetParsedComments implements Commented interface.
+func (node *DropStoredProgram) GetParsedComments() *ParsedComments {
+	return node.Comments
+}
+
 // GetParsedComments implements Commented interface.
 func (node *AlterView) GetParsedComments() *ParsedComments {
 	return node.Comments
@@ -1467,6 +1799,26 @@ func (node *DropView) GetToTables() TableNames {
 	return nil
 }
 
+// GetToTables implements the DDLStatement interface
+func (node *CreateTrigger) GetToTables() TableNames {
+	return nil
+}
+
+// GetToTables implements the DDLStatement interface
+func (node *CreateRoutine) GetToTables() TableNames {
+	return nil
+}
+
+// GetToTables implements the DDLStatement interface
+func (node *CreateEvent) GetToTables() TableNames {
+	return nil
+}
+
+// GetToTables implements the DDLStatement interface
+func (node *DropStoredProgram) GetToTables() TableNames {
+	return nil
+}
+
 // AffectedTables returns the list table names affected by the DDLStatement.
 func (node *RenameTable) AffectedTables() TableNames {
 	list := make(TableNames, 0, 2*len(node.TablePairs))
@@ -1519,6 +1871,26 @@ func (node *DropView) AffectedTables() TableNames {
 	return node.FromTables
 }
 
+// AffectedTables implements DDLStatement.
+func (node *CreateTrigger) AffectedTables() TableNames {
+	return TableNames{node.Table}
+}
+
+// AffectedTables implements DDLStatement.
+func (node *CreateRoutine) AffectedTables() TableNames {
+	return nil
+}
+
+// AffectedTables implements DDLStatement.
+func (node *CreateEvent) AffectedTables() TableNames {
+	return nil
+}
+
+// AffectedTables implements DDLStatement.
+func (node *DropStoredProgram) AffectedTables() TableNames {
+	return nil
+}
+
 // SetTable implements DDLStatement.
 func (node *TruncateTable) SetTable(qualifier string, name string) {
 	node.Table.Qualifier = NewIdentifierCS(qualifier)
@@ -1558,6 +1930,30 @@ func (node *DropTable) SetTable(qualifier string, name string) {}
 // SetTable implements DDLStatement.
 func (node *DropView) SetTable(qualifier string, name string) {}
 
+// SetTable implements DDLStatement.
+func (node *CreateTrigger) SetTable(qualifier string, name string) {
+	node.TriggerName.Qualifier = NewIdentifierCS(qualifier)
+	node.TriggerName.Name = NewIdentifierCS(name)
+}
+
+// SetTable implements DDLStatement.
+func (node *CreateRoutine) SetTable(qualifier string, name string) {
+	node.RoutineName.Qualifier = NewIdentifierCS(qualifier)
+	node.RoutineName.Name = NewIdentifierCS(name)
+}
+
+// SetTable implements DDLStatement.
+func (node *CreateEvent) SetTable(qualifier string, name string) {
+	node.EventName.Qualifier = NewIdentifierCS(qualifier)
+	node.EventName.Name = NewIdentifierCS(name)
+}
+
+// SetTable implements DDLStatement.
+func (node *DropStoredProgram) SetTable(qualifier string, name string) {
+	node.Name.Qualifier = NewIdentifierCS(qualifier)
+	node.Name.Name = NewIdentifierCS(name)
+}
+
 func (*DropDatabase) iDBDDLStatement()   {}
 func (*CreateDatabase) iDBDDLStatement() {}
 func (*AlterDatabase) iDBDDLStatement()  {}
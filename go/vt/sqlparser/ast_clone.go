@@ -121,8 +121,14 @@ func CloneSQLNode(in SQLNode) SQLNode {
 		return CloneRefOfCountStar(in)
 	case *CreateDatabase:
 		return CloneRefOfCreateDatabase(in)
+	case *CreateEvent:
+		return CloneRefOfCreateEvent(in)
+	case *CreateRoutine:
+		return CloneRefOfCreateRoutine(in)
 	case *CreateTable:
 		return CloneRefOfCreateTable(in)
+	case *CreateTrigger:
+		return CloneRefOfCreateTrigger(in)
 	case *CreateView:
 		return CloneRefOfCreateView(in)
 	case *CurTimeFuncExpr:
@@ -143,6 +149,8 @@ func CloneSQLNode(in SQLNode) SQLNode {
 		return CloneRefOfDropDatabase(in)
 	case *DropKey:
 		return CloneRefOfDropKey(in)
+	case *DropStoredProgram:
+		return CloneRefOfDropStoredProgram(in)
 	case *DropTable:
 		return CloneRefOfDropTable(in)
 	case *DropView:
@@ -1017,6 +1025,30 @@ func CloneRefOfCreateDatabase(n *CreateDatabase) *CreateDatabase {
 	return &out
 }
 
+// CloneRefOfCreateEvent creates a deep clone of the input.
+func CloneRefOfCreateEvent(n *CreateEvent) *CreateEvent {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	out.EventName = CloneTableName(n.EventName)
+	out.Definer = CloneRefOfDefiner(n.Definer)
+	out.Comments = CloneRefOfParsedComments(n.Comments)
+	return &out
+}
+
+// CloneRefOfCreateRoutine creates a deep clone of the input.
+func CloneRefOfCreateRoutine(n *CreateRoutine) *CreateRoutine {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	out.RoutineName = CloneTableName(n.RoutineName)
+	out.Definer = CloneRefOfDefiner(n.Definer)
+	out.Comments = CloneRefOfParsedComments(n.Comments)
+	return &out
+}
+
 // CloneRefOfCreateTable creates a deep clone of the input.
 func CloneRefOfCreateTable(n *CreateTable) *CreateTable {
 	if n == nil {
@@ -1030,6 +1062,19 @@ func CloneRefOfCreateTable(n *CreateTable) *CreateTable {
 	return &out
 }
 
+// CloneRefOfCreateTrigger creates a deep clone of the input.
+func CloneRefOfCreateTrigger(n *CreateTrigger) *CreateTrigger {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	out.TriggerName = CloneTableName(n.TriggerName)
+	out.Definer = CloneRefOfDefiner(n.Definer)
+	out.Table = CloneTableName(n.Table)
+	out.Comments = CloneRefOfParsedComments(n.Comments)
+	return &out
+}
+
 // CloneRefOfCreateView creates a deep clone of the input.
 func CloneRefOfCreateView(n *CreateView) *CreateView {
 	if n == nil {
@@ -1142,6 +1187,17 @@ func CloneRefOfDropKey(n *DropKey) *DropKey {
 	return &out
 }
 
+// CloneRefOfDropStoredProgram creates a deep clone of the input.
+func CloneRefOfDropStoredProgram(n *DropStoredProgram) *DropStoredProgram {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	out.Name = CloneTableName(n.Name)
+	out.Comments = CloneRefOfParsedComments(n.Comments)
+	return &out
+}
+
 // CloneRefOfDropTable creates a deep clone of the input.
 func CloneRefOfDropTable(n *DropTable) *DropTable {
 	if n == nil {
@@ -3405,10 +3461,18 @@ func CloneDDLStatement(in DDLStatement) DDLStatement {
 		return CloneRefOfAlterTable(in)
 	case *AlterView:
 		return CloneRefOfAlterView(in)
+	case *CreateEvent:
+		return CloneRefOfCreateEvent(in)
+	case *CreateRoutine:
+		return CloneRefOfCreateRoutine(in)
 	case *CreateTable:
 		return CloneRefOfCreateTable(in)
+	case *CreateTrigger:
+		return CloneRefOfCreateTrigger(in)
 	case *CreateView:
 		return CloneRefOfCreateView(in)
+	case *DropStoredProgram:
+		return CloneRefOfDropStoredProgram(in)
 	case *DropTable:
 		return CloneRefOfDropTable(in)
 	case *DropView:
@@ -3753,8 +3817,14 @@ func CloneStatement(in Statement) Statement {
 		return CloneRefOfCommit(in)
 	case *CreateDatabase:
 		return CloneRefOfCreateDatabase(in)
+	case *CreateEvent:
+		return CloneRefOfCreateEvent(in)
+	case *CreateRoutine:
+		return CloneRefOfCreateRoutine(in)
 	case *CreateTable:
 		return CloneRefOfCreateTable(in)
+	case *CreateTrigger:
+		return CloneRefOfCreateTrigger(in)
 	case *CreateView:
 		return CloneRefOfCreateView(in)
 	case *DeallocateStmt:
@@ -3763,6 +3833,8 @@ func CloneStatement(in Statement) Statement {
 		return CloneRefOfDelete(in)
 	case *DropDatabase:
 		return CloneRefOfDropDatabase(in)
+	case *DropStoredProgram:
+		return CloneRefOfDropStoredProgram(in)
 	case *DropTable:
 		return CloneRefOfDropTable(in)
 	case *DropView:
@@ -320,12 +320,30 @@ func (cmp *Comparator) SQLNode(inA, inB SQLNode) bool {
 			return false
 		}
 		return cmp.RefOfCreateDatabase(a, b)
+	case *CreateEvent:
+		b, ok := inB.(*CreateEvent)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfCreateEvent(a, b)
+	case *CreateRoutine:
+		b, ok := inB.(*CreateRoutine)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfCreateRoutine(a, b)
 	case *CreateTable:
 		b, ok := inB.(*CreateTable)
 		if !ok {
 			return false
 		}
 		return cmp.RefOfCreateTable(a, b)
+	case *CreateTrigger:
+		b, ok := inB.(*CreateTrigger)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfCreateTrigger(a, b)
 	case *CreateView:
 		b, ok := inB.(*CreateView)
 		if !ok {
@@ -386,6 +404,12 @@ func (cmp *Comparator) SQLNode(inA, inB SQLNode) bool {
 			return false
 		}
 		return cmp.RefOfDropKey(a, b)
+	case *DropStoredProgram:
+		b, ok := inB.(*DropStoredProgram)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfDropStoredProgram(a, b)
 	case *DropTable:
 		b, ok := inB.(*DropTable)
 		if !ok {
@@ -2088,6 +2112,37 @@ func (cmp *Comparator) RefOfCreateDatabase(a, b *CreateDatabase) bool {
 		cmp.SliceOfDatabaseOption(a.CreateOptions, b.CreateOptions)
 }
 
+// RefOfCreateEvent does deep equals between the two objects.
+func (cmp *Comparator) RefOfCreateEvent(a, b *CreateEvent) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.IfNotExists == b.IfNotExists &&
+		a.Definition == b.Definition &&
+		cmp.TableName(a.EventName, b.EventName) &&
+		cmp.RefOfDefiner(a.Definer, b.Definer) &&
+		cmp.RefOfParsedComments(a.Comments, b.Comments)
+}
+
+// RefOfCreateRoutine does deep equals between the two objects.
+func (cmp *Comparator) RefOfCreateRoutine(a, b *CreateRoutine) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.IfNotExists == b.IfNotExists &&
+		a.Definition == b.Definition &&
+		a.Type == b.Type &&
+		cmp.TableName(a.RoutineName, b.RoutineName) &&
+		cmp.RefOfDefiner(a.Definer, b.Definer) &&
+		cmp.RefOfParsedComments(a.Comments, b.Comments)
+}
+
 // RefOfCreateTable does deep equals between the two objects.
 func (cmp *Comparator) RefOfCreateTable(a, b *CreateTable) bool {
 	if a == b {
@@ -2105,6 +2160,24 @@ func (cmp *Comparator) RefOfCreateTable(a, b *CreateTable) bool {
 		cmp.RefOfParsedComments(a.Comments, b.Comments)
 }
 
+// RefOfCreateTrigger does deep equals between the two objects.
+func (cmp *Comparator) RefOfCreateTrigger(a, b *CreateTrigger) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.IfNotExists == b.IfNotExists &&
+		a.Body == b.Body &&
+		cmp.TableName(a.TriggerName, b.TriggerName) &&
+		cmp.RefOfDefiner(a.Definer, b.Definer) &&
+		a.Timing == b.Timing &&
+		a.Event == b.Event &&
+		cmp.TableName(a.Table, b.Table) &&
+		cmp.RefOfParsedComments(a.Comments, b.Comments)
+}
+
 // RefOfCreateView does deep equals between the two objects.
 func (cmp *Comparator) RefOfCreateView(a, b *CreateView) bool {
 	if a == b {
@@ -2239,6 +2312,20 @@ func (cmp *Comparator) RefOfDropKey(a, b *DropKey) bool {
 		cmp.IdentifierCI(a.Name, b.Name)
 }
 
+// RefOfDropStoredProgram does deep equals between the two objects.
+func (cmp *Comparator) RefOfDropStoredProgram(a, b *DropStoredProgram) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.IfExists == b.IfExists &&
+		a.Type == b.Type &&
+		cmp.TableName(a.Name, b.Name) &&
+		cmp.RefOfParsedComments(a.Comments, b.Comments)
+}
+
 // RefOfDropTable does deep equals between the two objects.
 func (cmp *Comparator) RefOfDropTable(a, b *DropTable) bool {
 	if a == b {
@@ -5237,18 +5324,42 @@ func (cmp *Comparator) DDLStatement(inA, inB DDLStatement) bool {
 			return false
 		}
 		return cmp.RefOfAlterView(a, b)
+	case *CreateEvent:
+		b, ok := inB.(*CreateEvent)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfCreateEvent(a, b)
+	case *CreateRoutine:
+		b, ok := inB.(*CreateRoutine)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfCreateRoutine(a, b)
 	case *CreateTable:
 		b, ok := inB.(*CreateTable)
 		if !ok {
 			return false
 		}
 		return cmp.RefOfCreateTable(a, b)
+	case *CreateTrigger:
+		b, ok := inB.(*CreateTrigger)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfCreateTrigger(a, b)
 	case *CreateView:
 		b, ok := inB.(*CreateView)
 		if !ok {
 			return false
 		}
 		return cmp.RefOfCreateView(a, b)
+	case *DropStoredProgram:
+		b, ok := inB.(*DropStoredProgram)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfDropStoredProgram(a, b)
 	case *DropTable:
 		b, ok := inB.(*DropTable)
 		if !ok {
@@ -6113,12 +6224,30 @@ func (cmp *Comparator) Statement(inA, inB Statement) bool {
 			return false
 		}
 		return cmp.RefOfCreateDatabase(a, b)
+	case *CreateEvent:
+		b, ok := inB.(*CreateEvent)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfCreateEvent(a, b)
+	case *CreateRoutine:
+		b, ok := inB.(*CreateRoutine)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfCreateRoutine(a, b)
 	case *CreateTable:
 		b, ok := inB.(*CreateTable)
 		if !ok {
 			return false
 		}
 		return cmp.RefOfCreateTable(a, b)
+	case *CreateTrigger:
+		b, ok := inB.(*CreateTrigger)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfCreateTrigger(a, b)
 	case *CreateView:
 		b, ok := inB.(*CreateView)
 		if !ok {
@@ -6143,6 +6272,12 @@ func (cmp *Comparator) Statement(inA, inB Statement) bool {
 			return false
 		}
 		return cmp.RefOfDropDatabase(a, b)
+	case *DropStoredProgram:
+		b, ok := inB.(*DropStoredProgram)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfDropStoredProgram(a, b)
 	case *DropTable:
 		b, ok := inB.(*DropTable)
 		if !ok {
@@ -2073,6 +2073,45 @@ func (node *CreateView) Format(buf *TrackedBuffer) {
 	}
 }
 
+// Format formats the node.
+func (node *CreateTrigger) Format(buf *TrackedBuffer) {
+	buf.astPrintf(node, "create %v", node.Comments)
+	if node.Definer != nil {
+		buf.astPrintf(node, "definer = %v ", node.Definer)
+	}
+	buf.literal("trigger ")
+	if node.IfNotExists {
+		buf.literal("if not exists ")
+	}
+	buf.astPrintf(node, "%v %s %s on %v for each row %#s", node.TriggerName, node.Timing.ToString(), node.Event.ToString(), node.Table, node.Body)
+}
+
+// Format formats the node.
+func (node *CreateRoutine) Format(buf *TrackedBuffer) {
+	buf.astPrintf(node, "create %v", node.Comments)
+	if node.Definer != nil {
+		buf.astPrintf(node, "definer = %v ", node.Definer)
+	}
+	buf.astPrintf(node, "%s ", node.Type.ToString())
+	if node.IfNotExists {
+		buf.literal("if not exists ")
+	}
+	buf.astPrintf(node, "%v%#s", node.RoutineName, node.Definition)
+}
+
+// Format formats the node.
+func (node *CreateEvent) Format(buf *TrackedBuffer) {
+	buf.astPrintf(node, "create %v", node.Comments)
+	if node.Definer != nil {
+		buf.astPrintf(node, "definer = %v ", node.Definer)
+	}
+	buf.literal("event ")
+	if node.IfNotExists {
+		buf.literal("if not exists ")
+	}
+	buf.astPrintf(node, "%v %#s", node.EventName, node.Definition)
+}
+
 // Format formats the LockTables node.
 func (node *LockTables) Format(buf *TrackedBuffer) {
 	buf.astPrintf(node, "lock tables %v %s", node.Tables[0].Table, node.Tables[0].Lock.ToString())
@@ -2135,6 +2174,15 @@ func (node *DropView) Format(buf *TrackedBuffer) {
 	buf.astPrintf(node, "view%s %v", exists, node.FromTables)
 }
 
+// Format formats the node.
+func (node *DropStoredProgram) Format(buf *TrackedBuffer) {
+	buf.astPrintf(node, "drop %v%s", node.Comments, node.Type.ToString())
+	if node.IfExists {
+		buf.literal(" if exists")
+	}
+	buf.astPrintf(node, " %v", node.Name)
+}
+
 // Format formats the AlterTable node.
 func (node *AlterTable) Format(buf *TrackedBuffer) {
 	buf.astPrintf(node, "alter %vtable %v", node.Comments, node.Table)
@@ -2728,6 +2728,66 @@ func (node *CreateView) formatFast(buf *TrackedBuffer) {
 	}
 }
 
+// formatFast formats the node.
+func (node *CreateTrigger) formatFast(buf *TrackedBuffer) {
+	buf.WriteString("create ")
+	node.Comments.formatFast(buf)
+	if node.Definer != nil {
+		buf.WriteString("definer = ")
+		node.Definer.formatFast(buf)
+		buf.WriteByte(' ')
+	}
+	buf.WriteString("trigger ")
+	if node.IfNotExists {
+		buf.WriteString("if not exists ")
+	}
+	node.TriggerName.formatFast(buf)
+	buf.WriteByte(' ')
+	buf.WriteString(node.Timing.ToString())
+	buf.WriteByte(' ')
+	buf.WriteString(node.Event.ToString())
+	buf.WriteString(" on ")
+	node.Table.formatFast(buf)
+	buf.WriteString(" for each row ")
+	buf.WriteString(node.Body)
+}
+
+// formatFast formats the node.
+func (node *CreateRoutine) formatFast(buf *TrackedBuffer) {
+	buf.WriteString("create ")
+	node.Comments.formatFast(buf)
+	if node.Definer != nil {
+		buf.WriteString("definer = ")
+		node.Definer.formatFast(buf)
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(node.Type.ToString())
+	buf.WriteByte(' ')
+	if node.IfNotExists {
+		buf.WriteString("if not exists ")
+	}
+	node.RoutineName.formatFast(buf)
+	buf.WriteString(node.Definition)
+}
+
+// formatFast formats the node.
+func (node *CreateEvent) formatFast(buf *TrackedBuffer) {
+	buf.WriteString("create ")
+	node.Comments.formatFast(buf)
+	if node.Definer != nil {
+		buf.WriteString("definer = ")
+		node.Definer.formatFast(buf)
+		buf.WriteByte(' ')
+	}
+	buf.WriteString("event ")
+	if node.IfNotExists {
+		buf.WriteString("if not exists ")
+	}
+	node.EventName.formatFast(buf)
+	buf.WriteByte(' ')
+	buf.WriteString(node.Definition)
+}
+
 // formatFast formats the LockTables node.
 func (node *LockTables) formatFast(buf *TrackedBuffer) {
 	buf.WriteString("lock tables ")
@@ -2819,6 +2879,18 @@ func (node *DropView) formatFast(buf *TrackedBuffer) {
 	node.FromTables.formatFast(buf)
 }
 
+// formatFast formats the node.
+func (node *DropStoredProgram) formatFast(buf *TrackedBuffer) {
+	buf.WriteString("drop ")
+	node.Comments.formatFast(buf)
+	buf.WriteString(node.Type.ToString())
+	if node.IfExists {
+		buf.WriteString(" if exists")
+	}
+	buf.WriteByte(' ')
+	node.Name.formatFast(buf)
+}
+
 // formatFast formats the AlterTable node.
 func (node *AlterTable) formatFast(buf *TrackedBuffer) {
 	buf.WriteString("alter ")
@@ -1405,6 +1405,48 @@ func (ty IndexHintType) ToString() string {
 	}
 }
 
+// ToString returns the timing as a string
+func (timing TriggerTiming) ToString() string {
+	switch timing {
+	case BeforeTriggerTiming:
+		return BeforeStr
+	case AfterTriggerTiming:
+		return AfterStr
+	default:
+		return "Unknown TriggerTiming"
+	}
+}
+
+// ToString returns the event as a string
+func (event TriggerEvent) ToString() string {
+	switch event {
+	case InsertTriggerEvent:
+		return TriggerInsertStr
+	case UpdateTriggerEvent:
+		return TriggerUpdateStr
+	case DeleteTriggerEvent:
+		return TriggerDeleteStr
+	default:
+		return "Unknown TriggerEvent"
+	}
+}
+
+// ToString returns the type as a string
+func (ty StoredProgramType) ToString() string {
+	switch ty {
+	case TriggerProgramType:
+		return TriggerProgramStr
+	case ProcedureProgramType:
+		return ProcedureProgramStr
+	case FunctionProgramType:
+		return FunctionProgramStr
+	case EventProgramType:
+		return EventProgramStr
+	default:
+		return "Unknown StoredProgramType"
+	}
+}
+
 // ToString returns the type as a string
 func (ty DeallocateStmtType) ToString() string {
 	switch ty {
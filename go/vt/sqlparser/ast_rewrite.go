@@ -120,8 +120,14 @@ func (a *application) rewriteSQLNode(parent SQLNode, node SQLNode, replacer repl
 		return a.rewriteRefOfCountStar(parent, node, replacer)
 	case *CreateDatabase:
 		return a.rewriteRefOfCreateDatabase(parent, node, replacer)
+	case *CreateEvent:
+		return a.rewriteRefOfCreateEvent(parent, node, replacer)
+	case *CreateRoutine:
+		return a.rewriteRefOfCreateRoutine(parent, node, replacer)
 	case *CreateTable:
 		return a.rewriteRefOfCreateTable(parent, node, replacer)
+	case *CreateTrigger:
+		return a.rewriteRefOfCreateTrigger(parent, node, replacer)
 	case *CreateView:
 		return a.rewriteRefOfCreateView(parent, node, replacer)
 	case *CurTimeFuncExpr:
@@ -142,6 +148,8 @@ func (a *application) rewriteSQLNode(parent SQLNode, node SQLNode, replacer repl
 		return a.rewriteRefOfDropDatabase(parent, node, replacer)
 	case *DropKey:
 		return a.rewriteRefOfDropKey(parent, node, replacer)
+	case *DropStoredProgram:
+		return a.rewriteRefOfDropStoredProgram(parent, node, replacer)
 	case *DropTable:
 		return a.rewriteRefOfDropTable(parent, node, replacer)
 	case *DropView:
@@ -1963,6 +1971,80 @@ func (a *application) rewriteRefOfCreateDatabase(parent SQLNode, node *CreateDat
 	}
 	return true
 }
+func (a *application) rewriteRefOfCreateEvent(parent SQLNode, node *CreateEvent, replacer replacerFunc) bool {
+	if node == nil {
+		return true
+	}
+	if a.pre != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.pre(&a.cur) {
+			return true
+		}
+	}
+	if !a.rewriteTableName(node, node.EventName, func(newNode, parent SQLNode) {
+		parent.(*CreateEvent).EventName = newNode.(TableName)
+	}) {
+		return false
+	}
+	if !a.rewriteRefOfDefiner(node, node.Definer, func(newNode, parent SQLNode) {
+		parent.(*CreateEvent).Definer = newNode.(*Definer)
+	}) {
+		return false
+	}
+	if !a.rewriteRefOfParsedComments(node, node.Comments, func(newNode, parent SQLNode) {
+		parent.(*CreateEvent).Comments = newNode.(*ParsedComments)
+	}) {
+		return false
+	}
+	if a.post != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.post(&a.cur) {
+			return false
+		}
+	}
+	return true
+}
+func (a *application) rewriteRefOfCreateRoutine(parent SQLNode, node *CreateRoutine, replacer replacerFunc) bool {
+	if node == nil {
+		return true
+	}
+	if a.pre != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.pre(&a.cur) {
+			return true
+		}
+	}
+	if !a.rewriteTableName(node, node.RoutineName, func(newNode, parent SQLNode) {
+		parent.(*CreateRoutine).RoutineName = newNode.(TableName)
+	}) {
+		return false
+	}
+	if !a.rewriteRefOfDefiner(node, node.Definer, func(newNode, parent SQLNode) {
+		parent.(*CreateRoutine).Definer = newNode.(*Definer)
+	}) {
+		return false
+	}
+	if !a.rewriteRefOfParsedComments(node, node.Comments, func(newNode, parent SQLNode) {
+		parent.(*CreateRoutine).Comments = newNode.(*ParsedComments)
+	}) {
+		return false
+	}
+	if a.post != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.post(&a.cur) {
+			return false
+		}
+	}
+	return true
+}
 func (a *application) rewriteRefOfCreateTable(parent SQLNode, node *CreateTable, replacer replacerFunc) bool {
 	if node == nil {
 		return true
@@ -2005,6 +2087,48 @@ func (a *application) rewriteRefOfCreateTable(parent SQLNode, node *CreateTable,
 	}
 	return true
 }
+func (a *application) rewriteRefOfCreateTrigger(parent SQLNode, node *CreateTrigger, replacer replacerFunc) bool {
+	if node == nil {
+		return true
+	}
+	if a.pre != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.pre(&a.cur) {
+			return true
+		}
+	}
+	if !a.rewriteTableName(node, node.TriggerName, func(newNode, parent SQLNode) {
+		parent.(*CreateTrigger).TriggerName = newNode.(TableName)
+	}) {
+		return false
+	}
+	if !a.rewriteRefOfDefiner(node, node.Definer, func(newNode, parent SQLNode) {
+		parent.(*CreateTrigger).Definer = newNode.(*Definer)
+	}) {
+		return false
+	}
+	if !a.rewriteTableName(node, node.Table, func(newNode, parent SQLNode) {
+		parent.(*CreateTrigger).Table = newNode.(TableName)
+	}) {
+		return false
+	}
+	if !a.rewriteRefOfParsedComments(node, node.Comments, func(newNode, parent SQLNode) {
+		parent.(*CreateTrigger).Comments = newNode.(*ParsedComments)
+	}) {
+		return false
+	}
+	if a.post != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.post(&a.cur) {
+			return false
+		}
+	}
+	return true
+}
 func (a *application) rewriteRefOfCreateView(parent SQLNode, node *CreateView, replacer replacerFunc) bool {
 	if node == nil {
 		return true
@@ -2339,6 +2463,38 @@ func (a *application) rewriteRefOfDropKey(parent SQLNode, node *DropKey, replace
 	}
 	return true
 }
+func (a *application) rewriteRefOfDropStoredProgram(parent SQLNode, node *DropStoredProgram, replacer replacerFunc) bool {
+	if node == nil {
+		return true
+	}
+	if a.pre != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.pre(&a.cur) {
+			return true
+		}
+	}
+	if !a.rewriteTableName(node, node.Name, func(newNode, parent SQLNode) {
+		parent.(*DropStoredProgram).Name = newNode.(TableName)
+	}) {
+		return false
+	}
+	if !a.rewriteRefOfParsedComments(node, node.Comments, func(newNode, parent SQLNode) {
+		parent.(*DropStoredProgram).Comments = newNode.(*ParsedComments)
+	}) {
+		return false
+	}
+	if a.post != nil {
+		a.cur.replacer = replacer
+		a.cur.parent = parent
+		a.cur.node = node
+		if !a.post(&a.cur) {
+			return false
+		}
+	}
+	return true
+}
 func (a *application) rewriteRefOfDropTable(parent SQLNode, node *DropTable, replacer replacerFunc) bool {
 	if node == nil {
 		return true
@@ -8497,10 +8653,18 @@ func (a *application) rewriteDDLStatement(parent SQLNode, node DDLStatement, rep
 		return a.rewriteRefOfAlterTable(parent, node, replacer)
 	case *AlterView:
 		return a.rewriteRefOfAlterView(parent, node, replacer)
+	case *CreateEvent:
+		return a.rewriteRefOfCreateEvent(parent, node, replacer)
+	case *CreateRoutine:
+		return a.rewriteRefOfCreateRoutine(parent, node, replacer)
 	case *CreateTable:
 		return a.rewriteRefOfCreateTable(parent, node, replacer)
+	case *CreateTrigger:
+		return a.rewriteRefOfCreateTrigger(parent, node, replacer)
 	case *CreateView:
 		return a.rewriteRefOfCreateView(parent, node, replacer)
+	case *DropStoredProgram:
+		return a.rewriteRefOfDropStoredProgram(parent, node, replacer)
 	case *DropTable:
 		return a.rewriteRefOfDropTable(parent, node, replacer)
 	case *DropView:
@@ -8829,8 +8993,14 @@ func (a *application) rewriteStatement(parent SQLNode, node Statement, replacer
 		return a.rewriteRefOfCommit(parent, node, replacer)
 	case *CreateDatabase:
 		return a.rewriteRefOfCreateDatabase(parent, node, replacer)
+	case *CreateEvent:
+		return a.rewriteRefOfCreateEvent(parent, node, replacer)
+	case *CreateRoutine:
+		return a.rewriteRefOfCreateRoutine(parent, node, replacer)
 	case *CreateTable:
 		return a.rewriteRefOfCreateTable(parent, node, replacer)
+	case *CreateTrigger:
+		return a.rewriteRefOfCreateTrigger(parent, node, replacer)
 	case *CreateView:
 		return a.rewriteRefOfCreateView(parent, node, replacer)
 	case *DeallocateStmt:
@@ -8839,6 +9009,8 @@ func (a *application) rewriteStatement(parent SQLNode, node Statement, replacer
 		return a.rewriteRefOfDelete(parent, node, replacer)
 	case *DropDatabase:
 		return a.rewriteRefOfDropDatabase(parent, node, replacer)
+	case *DropStoredProgram:
+		return a.rewriteRefOfDropStoredProgram(parent, node, replacer)
 	case *DropTable:
 		return a.rewriteRefOfDropTable(parent, node, replacer)
 	case *DropView:
@@ -120,8 +120,14 @@ func VisitSQLNode(in SQLNode, f Visit) error {
 		return VisitRefOfCountStar(in, f)
 	case *CreateDatabase:
 		return VisitRefOfCreateDatabase(in, f)
+	case *CreateEvent:
+		return VisitRefOfCreateEvent(in, f)
+	case *CreateRoutine:
+		return VisitRefOfCreateRoutine(in, f)
 	case *CreateTable:
 		return VisitRefOfCreateTable(in, f)
+	case *CreateTrigger:
+		return VisitRefOfCreateTrigger(in, f)
 	case *CreateView:
 		return VisitRefOfCreateView(in, f)
 	case *CurTimeFuncExpr:
@@ -142,6 +148,8 @@ func VisitSQLNode(in SQLNode, f Visit) error {
 		return VisitRefOfDropDatabase(in, f)
 	case *DropKey:
 		return VisitRefOfDropKey(in, f)
+	case *DropStoredProgram:
+		return VisitRefOfDropStoredProgram(in, f)
 	case *DropTable:
 		return VisitRefOfDropTable(in, f)
 	case *DropView:
@@ -1179,6 +1187,42 @@ func VisitRefOfCreateDatabase(in *CreateDatabase, f Visit) error {
 	}
 	return nil
 }
+func VisitRefOfCreateEvent(in *CreateEvent, f Visit) error {
+	if in == nil {
+		return nil
+	}
+	if cont, err := f(in); err != nil || !cont {
+		return err
+	}
+	if err := VisitTableName(in.EventName, f); err != nil {
+		return err
+	}
+	if err := VisitRefOfDefiner(in.Definer, f); err != nil {
+		return err
+	}
+	if err := VisitRefOfParsedComments(in.Comments, f); err != nil {
+		return err
+	}
+	return nil
+}
+func VisitRefOfCreateRoutine(in *CreateRoutine, f Visit) error {
+	if in == nil {
+		return nil
+	}
+	if cont, err := f(in); err != nil || !cont {
+		return err
+	}
+	if err := VisitTableName(in.RoutineName, f); err != nil {
+		return err
+	}
+	if err := VisitRefOfDefiner(in.Definer, f); err != nil {
+		return err
+	}
+	if err := VisitRefOfParsedComments(in.Comments, f); err != nil {
+		return err
+	}
+	return nil
+}
 func VisitRefOfCreateTable(in *CreateTable, f Visit) error {
 	if in == nil {
 		return nil
@@ -1200,6 +1244,27 @@ func VisitRefOfCreateTable(in *CreateTable, f Visit) error {
 	}
 	return nil
 }
+func VisitRefOfCreateTrigger(in *CreateTrigger, f Visit) error {
+	if in == nil {
+		return nil
+	}
+	if cont, err := f(in); err != nil || !cont {
+		return err
+	}
+	if err := VisitTableName(in.TriggerName, f); err != nil {
+		return err
+	}
+	if err := VisitRefOfDefiner(in.Definer, f); err != nil {
+		return err
+	}
+	if err := VisitTableName(in.Table, f); err != nil {
+		return err
+	}
+	if err := VisitRefOfParsedComments(in.Comments, f); err != nil {
+		return err
+	}
+	return nil
+}
 func VisitRefOfCreateView(in *CreateView, f Visit) error {
 	if in == nil {
 		return nil
@@ -1356,6 +1421,21 @@ func VisitRefOfDropKey(in *DropKey, f Visit) error {
 	}
 	return nil
 }
+func VisitRefOfDropStoredProgram(in *DropStoredProgram, f Visit) error {
+	if in == nil {
+		return nil
+	}
+	if cont, err := f(in); err != nil || !cont {
+		return err
+	}
+	if err := VisitTableName(in.Name, f); err != nil {
+		return err
+	}
+	if err := VisitRefOfParsedComments(in.Comments, f); err != nil {
+		return err
+	}
+	return nil
+}
 func VisitRefOfDropTable(in *DropTable, f Visit) error {
 	if in == nil {
 		return nil
@@ -4282,10 +4362,18 @@ func VisitDDLStatement(in DDLStatement, f Visit) error {
 		return VisitRefOfAlterTable(in, f)
 	case *AlterView:
 		return VisitRefOfAlterView(in, f)
+	case *CreateEvent:
+		return VisitRefOfCreateEvent(in, f)
+	case *CreateRoutine:
+		return VisitRefOfCreateRoutine(in, f)
 	case *CreateTable:
 		return VisitRefOfCreateTable(in, f)
+	case *CreateTrigger:
+		return VisitRefOfCreateTrigger(in, f)
 	case *CreateView:
 		return VisitRefOfCreateView(in, f)
+	case *DropStoredProgram:
+		return VisitRefOfDropStoredProgram(in, f)
 	case *DropTable:
 		return VisitRefOfDropTable(in, f)
 	case *DropView:
@@ -4614,8 +4702,14 @@ func VisitStatement(in Statement, f Visit) error {
 		return VisitRefOfCommit(in, f)
 	case *CreateDatabase:
 		return VisitRefOfCreateDatabase(in, f)
+	case *CreateEvent:
+		return VisitRefOfCreateEvent(in, f)
+	case *CreateRoutine:
+		return VisitRefOfCreateRoutine(in, f)
 	case *CreateTable:
 		return VisitRefOfCreateTable(in, f)
+	case *CreateTrigger:
+		return VisitRefOfCreateTrigger(in, f)
 	case *CreateView:
 		return VisitRefOfCreateView(in, f)
 	case *DeallocateStmt:
@@ -4624,6 +4718,8 @@ func VisitStatement(in Statement, f Visit) error {
 		return VisitRefOfDelete(in, f)
 	case *DropDatabase:
 		return VisitRefOfDropDatabase(in, f)
+	case *DropStoredProgram:
+		return VisitRefOfDropStoredProgram(in, f)
 	case *DropTable:
 		return VisitRefOfDropTable(in, f)
 	case *DropView:
@@ -42,6 +42,21 @@ const (
 	InsertStr  = "insert"
 	ReplaceStr = "replace"
 
+	// CreateTrigger.Timing
+	BeforeStr = "before"
+	AfterStr  = "after"
+
+	// CreateTrigger.Event
+	TriggerInsertStr = "insert"
+	TriggerUpdateStr = "update"
+	TriggerDeleteStr = "delete"
+
+	// Stored program types
+	TriggerProgramStr   = "trigger"
+	ProcedureProgramStr = "procedure"
+	FunctionProgramStr  = "function"
+	EventProgramStr     = "event"
+
 	// Set.Scope or Show.Scope
 	SessionStr        = "session"
 	GlobalStr         = "global"
@@ -738,6 +753,27 @@ const (
 	DropType
 )
 
+// Constant for Enum Type - TriggerTiming
+const (
+	BeforeTriggerTiming TriggerTiming = iota
+	AfterTriggerTiming
+)
+
+// Constant for Enum Type - TriggerEvent
+const (
+	InsertTriggerEvent TriggerEvent = iota
+	UpdateTriggerEvent
+	DeleteTriggerEvent
+)
+
+// Constant for Enum Type - StoredProgramType
+const (
+	TriggerProgramType StoredProgramType = iota
+	ProcedureProgramType
+	FunctionProgramType
+	EventProgramType
+)
+
 // Constant for Enum Type - JtOnResponseType
 const (
 	ErrorJSONType JtOnResponseType = iota
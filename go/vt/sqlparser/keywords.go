@@ -134,7 +134,7 @@ var keywords = []keyword{
 	{"autoextend_size", AUTOEXTEND_SIZE},
 	{"avg", AVG},
 	{"avg_row_length", AVG_ROW_LENGTH},
-	{"before", UNUSED},
+	{"before", BEFORE},
 	{"begin", BEGIN},
 	{"between", BETWEEN},
 	{"bigint", BIGINT},
@@ -236,7 +236,7 @@ var keywords = []keyword{
 	{"dumpfile", DUMPFILE},
 	{"duplicate", DUPLICATE},
 	{"dynamic", DYNAMIC},
-	{"each", UNUSED},
+	{"each", EACH},
 	{"else", ELSE},
 	{"elseif", UNUSED},
 	{"empty", EMPTY},
@@ -138,6 +138,14 @@ func TestParseNextEdgeCases(t *testing.T) {
 		name:  "Partial DDL",
 		input: "create table a ignore me this is garbage; select 1 from a",
 		want:  []string{"create table a", "select 1 from a"},
+	}, {
+		name:  "Compound stored program body",
+		input: "create trigger t_bi before insert on a for each row begin set new.b = 1; if new.c then set new.d = case when 1 then 2 end; end if; end; select 1 from a",
+		want:  []string{"create trigger t_bi before insert on a for each row begin set new.b = 1; if new.c then set new.d = case when 1 then 2 end; end if; end", "select 1 from a"},
+	}, {
+		name:  "Labeled stored program body",
+		input: "create procedure p() l1: loop leave l1; end loop l1; select 1 from a",
+		want:  []string{"create procedure p() l1: loop leave l1; end loop l1", "select 1 from a"},
 	}}
 
 	for _, test := range tests {
@@ -1765,6 +1765,39 @@ var (
 	}, {
 		input:  "drop view if exists a cascade",
 		output: "drop view if exists a",
+	}, {
+		input: "create trigger t_bi before insert on t for each row set new.a = 1",
+	}, {
+		input:  "CREATE DEFINER=`root`@`localhost` TRIGGER `t_bi` BEFORE INSERT ON `t` FOR EACH ROW SET NEW.a = 1",
+		output: "create definer = root@localhost trigger t_bi before insert on t for each row SET NEW.a = 1",
+	}, {
+		input: "create trigger if not exists t_au after update on db.t for each row follows t_bu begin if new.a > 0 then set @x = 1; end if; insert into log values (new.a); end",
+	}, {
+		input: "create /* comment */ trigger t_bd before delete on t for each row set @x = 'a;b'",
+	}, {
+		input: "create procedure p(in x int) begin select x; end",
+	}, {
+		input:  "create definer = current_user procedure if not exists p  (x int)  select x",
+		output: "create definer = current_user procedure if not exists p(x int)  select x",
+	}, {
+		input: "create function f(x int) returns int deterministic return x * 2",
+	}, {
+		input:  "create function `db`.`f` () returns int return 1",
+		output: "create function db.f() returns int return 1",
+	}, {
+		input: "create event if not exists db.e on schedule every 1 day do delete from t where ts < now()",
+	}, {
+		input: "create event e on schedule every 1 day do delete from t where ts < now()",
+	}, {
+		input: "drop trigger t_bi",
+	}, {
+		input: "drop trigger if exists db.t_bi",
+	}, {
+		input: "drop procedure if exists p",
+	}, {
+		input: "drop function f",
+	}, {
+		input: "drop /* comment */ event e",
 	}, {
 		input:  "drop index b on a lock = none algorithm default",
 		output: "alter table a drop key b, lock none, algorithm = default",
@@ -3558,6 +3591,18 @@ func TestInvalid(t *testing.T) {
 	}, {
 		input: "SELECT 0b2 FROM user",
 		err:   "syntax error at position 11",
+	}, {
+		input: "create trigger t_bi before insert on t for each row",
+		err:   "syntax error: missing stored program body",
+	}, {
+		input: "create or replace trigger t_bi before insert on t for each row set new.a = 1",
+		err:   "syntax error: OR REPLACE and ALGORITHM are not supported for triggers",
+	}, {
+		input: "create trigger t_bi before select on t for each row set new.a = 1",
+		err:   "syntax error at position 34 near 'select'",
+	}, {
+		input: "create procedure db.() select 1",
+		err:   "syntax error: invalid stored program name",
 	},
 	}
 
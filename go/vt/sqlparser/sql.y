@@ -193,6 +193,8 @@ func bindVariable(yylex yyLexer, bvar string) {
 
   columnStorage ColumnStorage
   columnFormat ColumnFormat
+  triggerTiming TriggerTiming
+  triggerEvent TriggerEvent
 
   boolean bool
   boolVal BoolVal
@@ -308,6 +310,7 @@ func bindVariable(yylex yyLexer, bvar string) {
 %token <str> ACTION CASCADE CONSTRAINT FOREIGN NO REFERENCES RESTRICT
 %token <str> SHOW DESCRIBE EXPLAIN DATE ESCAPE REPAIR OPTIMIZE TRUNCATE COALESCE EXCHANGE REBUILD PARTITIONING REMOVE PREPARE EXECUTE
 %token <str> MAXVALUE PARTITION REORGANIZE LESS THAN PROCEDURE TRIGGER
+%token <str> BEFORE EACH
 %token <str> VINDEX VINDEXES DIRECTORY NAME UPGRADE
 %token <str> STATUS VARIABLES WARNINGS CASCADED DEFINER OPTION SQL UNDEFINED
 %token <str> SEQUENCE MERGE TEMPORARY TEMPTABLE INVOKER SECURITY FIRST AFTER LAST
@@ -467,6 +470,9 @@ func bindVariable(yylex yyLexer, bvar string) {
 %type <str> select_option algorithm_view security_view security_view_opt
 %type <str> generated_always_opt user_username address_opt
 %type <definer> definer_opt user
+%type <str> stored_program_body
+%type <triggerTiming> trigger_timing
+%type <triggerEvent> trigger_event
 %type <expr> expression frame_expression signed_literal signed_literal_or_null null_as_literal now_or_signed_literal signed_literal bit_expr regular_expressions xml_expressions
 %type <expr> interval_value simple_expr literal NUM_literal text_literal text_literal_or_arg bool_pri literal_or_null now predicate tuple_expression null_int_variable_arg performance_schema_function_expressions gtid_function_expressions
 %type <tableExprs> from_opt table_references from_clause
@@ -1149,6 +1155,53 @@ create_statement:
   {
     $$ = &CreateView{ViewName: $8.ToViewName(), Comments: Comments($2).Parsed(), IsReplace:$3, Algorithm:$4, Definer: $5 ,Security:$6, Columns:$9, Select: $11, CheckOption: $12 }
   }
+| CREATE comment_opt replace_opt algorithm_view definer_opt TRIGGER not_exists_opt table_name trigger_timing trigger_event ON table_name FOR EACH ROW stored_program_body
+  {
+    if $3 || $4 != "" {
+      yylex.Error("syntax error: OR REPLACE and ALGORITHM are not supported for triggers")
+      return 1
+    }
+    $$ = &CreateTrigger{TriggerName: $8, Comments: Comments($2).Parsed(), Definer: $5, IfNotExists: $7, Timing: $9, Event: $10, Table: $12, Body: $16}
+  }
+| CREATE comment_opt replace_opt algorithm_view definer_opt PROCEDURE not_exists_opt table_id stored_program_body
+  {
+    if $3 || $4 != "" {
+      yylex.Error("syntax error: OR REPLACE and ALGORITHM are not supported for procedures")
+      return 1
+    }
+    name, definition, ok := splitStoredProgramName($8, $9)
+    if !ok {
+      yylex.Error("syntax error: invalid stored program name")
+      return 1
+    }
+    $$ = &CreateRoutine{Type: ProcedureProgramType, RoutineName: name, Comments: Comments($2).Parsed(), Definer: $5, IfNotExists: $7, Definition: definition}
+  }
+| CREATE comment_opt replace_opt algorithm_view definer_opt FUNCTION not_exists_opt table_id stored_program_body
+  {
+    if $3 || $4 != "" {
+      yylex.Error("syntax error: OR REPLACE and ALGORITHM are not supported for functions")
+      return 1
+    }
+    name, definition, ok := splitStoredProgramName($8, $9)
+    if !ok {
+      yylex.Error("syntax error: invalid stored program name")
+      return 1
+    }
+    $$ = &CreateRoutine{Type: FunctionProgramType, RoutineName: name, Comments: Comments($2).Parsed(), Definer: $5, IfNotExists: $7, Definition: definition}
+  }
+| CREATE comment_opt replace_opt algorithm_view definer_opt EVENT not_exists_opt table_id stored_program_body
+  {
+    if $3 || $4 != "" {
+      yylex.Error("syntax error: OR REPLACE and ALGORITHM are not supported for events")
+      return 1
+    }
+    name, definition, ok := splitStoredProgramName($8, $9)
+    if !ok {
+      yylex.Error("syntax error: invalid stored program name")
+      return 1
+    }
+    $$ = &CreateEvent{EventName: name, Comments: Comments($2).Parsed(), Definer: $5, IfNotExists: $7, Definition: definition}
+  }
 | create_database_prefix create_options_opt
   {
     $1.FullyParsed = true
@@ -1156,6 +1209,41 @@ create_statement:
     $$ = $1
   }
 
+trigger_timing:
+  BEFORE
+  {
+    $$ = BeforeTriggerTiming
+  }
+| AFTER
+  {
+    $$ = AfterTriggerTiming
+  }
+
+trigger_event:
+  INSERT
+  {
+    $$ = InsertTriggerEvent
+  }
+| UPDATE
+  {
+    $$ = UpdateTriggerEvent
+  }
+| DELETE
+  {
+    $$ = DeleteTriggerEvent
+  }
+
+// stored_program_body reads the remainder of a stored program definition as is, without parsing it.
+// It must only follow a token which is shifted without a lookahead.
+stored_program_body:
+  {
+    $$ = yylex.(*Tokenizer).scanStoredProgramBody()
+    if $$ == "" {
+      yylex.Error("syntax error: missing stored program body")
+      return 1
+    }
+  }
+
 replace_opt:
   {
     $$ = false
@@ -3935,6 +4023,22 @@ drop_statement:
   {
     $$ = &DropDatabase{Comments: Comments($2).Parsed(), DBName: $5, IfExists: $4}
   }
+| DROP comment_opt TRIGGER exists_opt table_name
+  {
+    $$ = &DropStoredProgram{Type: TriggerProgramType, Comments: Comments($2).Parsed(), IfExists: $4, Name: $5}
+  }
+| DROP comment_opt PROCEDURE exists_opt table_name
+  {
+    $$ = &DropStoredProgram{Type: ProcedureProgramType, Comments: Comments($2).Parsed(), IfExists: $4, Name: $5}
+  }
+| DROP comment_opt FUNCTION exists_opt table_name
+  {
+    $$ = &DropStoredProgram{Type: FunctionProgramType, Comments: Comments($2).Parsed(), IfExists: $4, Name: $5}
+  }
+| DROP comment_opt EVENT exists_opt table_name
+  {
+    $$ = &DropStoredProgram{Type: EventProgramType, Comments: Comments($2).Parsed(), IfExists: $4, Name: $5}
+  }
 
 truncate_statement:
   TRUNCATE TABLE table_name
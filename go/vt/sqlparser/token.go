@@ -305,6 +305,97 @@ func (tkn *Tokenizer) skipStatement() int {
 	}
 }
 
+// scanStoredProgramBody scans the remainder of a stored program definition: the body of a trigger, the
+// parameters, characteristics and body of a routine, or the schedule and body of an event. The text is
+// not parsed, and is returned as written. The body may be a compound statement, whose inner statements
+// are terminated by ';'. The scan ends at the first ';' that is not nested within a compound statement,
+// or at the end of the input. The terminating ';' is left to be read as the next token.
+func (tkn *Tokenizer) scanStoredProgramBody() string {
+	// ';' must be scanned as a token even when parsing multiple statements
+	multi := tkn.multi
+	tkn.multi = false
+	defer func() {
+		tkn.multi = multi
+	}()
+
+	tkn.skipBlank()
+	start := tkn.Pos
+	end := start
+
+	depth := 0
+	statementStart := true
+	afterEnd := false
+	for {
+		pos := tkn.Pos
+		typ, val := tkn.Scan()
+		if typ == LEX_ERROR && val == ":" {
+			// A label, e.g. `label: LOOP ... END LOOP label`
+			typ = ':'
+		}
+		if typ == 0 || typ == LEX_ERROR || (typ == ';' && depth == 0) {
+			tkn.Pos = pos
+			break
+		}
+		end = tkn.Pos
+		if typ == COMMENT {
+			continue
+		}
+		switch typ {
+		case BEGIN, CASE:
+			// CASE also opens CASE expressions, which are terminated by END as well.
+			if !afterEnd {
+				depth++
+			}
+		case IF:
+			// IF at the start of a statement opens an IF statement. Otherwise, it is the IF() function.
+			if statementStart && !afterEnd {
+				depth++
+			}
+		case UNUSED:
+			switch strings.ToLower(val) {
+			case "loop", "while", "repeat":
+				if statementStart && !afterEnd {
+					depth++
+				}
+			}
+		case END:
+			if depth > 0 {
+				depth--
+			}
+		}
+		afterEnd = typ == END
+		switch typ {
+		case ';', ':', BEGIN, THEN, ELSE, DO:
+			statementStart = true
+		case UNUSED:
+			lowered := strings.ToLower(val)
+			statementStart = lowered == "loop" || lowered == "repeat"
+		default:
+			statementStart = false
+		}
+	}
+	return tkn.buf[start:end]
+}
+
+// splitStoredProgramName completes the name of a stored routine or event. The grammar cannot look
+// past the first identifier of the name without consuming the body, so a qualified name such as
+// `db.p` arrives as the identifier `db` followed by a body starting with `.p`.
+func splitStoredProgramName(name IdentifierCS, body string) (TableName, string, bool) {
+	if !strings.HasPrefix(body, ".") {
+		return TableName{Name: name}, body, true
+	}
+	tkn := NewStringTokenizer(body[1:])
+	typ, val := tkn.Scan()
+	if typ != ID {
+		return TableName{}, "", false
+	}
+	definition := strings.TrimLeft(tkn.buf[tkn.Pos:], " \n\r\t")
+	if definition == "" {
+		return TableName{}, "", false
+	}
+	return TableName{Qualifier: name, Name: NewIdentifierCS(val)}, definition, true
+}
+
 // skipBlank skips the cursor while it finds whitespace
 func (tkn *Tokenizer) skipBlank() {
 	ch := tkn.cur()
@@ -0,0 +1,379 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"os"
+	"os/user"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// AuditEvent describes a single change of a topology file.
+// It needs to be public as we JSON-serialize it.
+type AuditEvent struct {
+	Time time.Time
+	Cell string
+	Path string
+
+	// Operation is one of Create, Update or Delete.
+	Operation string
+
+	// Version is the version of the file after the change. It is
+	// empty for deletions.
+	Version string
+
+	// Caller is the effective caller of the change, if the context
+	// has one. HostName and UserName identify the process which
+	// made the change.
+	Caller   string
+	HostName string
+	UserName string
+
+	// Reason is the reason set with WithAuditReason, or the actions
+	// of the locks held while making the change.
+	Reason string
+
+	// Before and After are the contents of the file, as JSON for the
+	// known topology records. They are empty for files which don't
+	// exist.
+	Before string
+	After  string
+}
+
+// AuditFilter selects audit events. Its zero value selects all the
+// events.
+type AuditFilter struct {
+	// Cells restricts the events to these cells.
+	Cells []string
+
+	// Path restricts the events to this file, or to the files under
+	// this directory.
+	Path string
+
+	// Caller restricts the events to this caller.
+	Caller string
+
+	// Since excludes the events older than this time.
+	Since time.Time
+
+	// Limit is the maximum number of events to return, keeping the
+	// most recent ones. Zero means no limit.
+	Limit int
+}
+
+// Matches returns true if the filter selects the event.
+func (f *AuditFilter) Matches(event *AuditEvent) bool {
+	if len(f.Cells) > 0 {
+		found := false
+		for _, cell := range f.Cells {
+			if cell == event.Cell {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Path != "" {
+		prefix := path.Clean("/" + f.Path)
+		eventPath := path.Clean("/" + event.Path)
+		if prefix != "/" && eventPath != prefix && !strings.HasPrefix(eventPath, prefix+"/") {
+			return false
+		}
+	}
+	if f.Caller != "" && f.Caller != event.Caller {
+		return false
+	}
+	if !f.Since.IsZero() && event.Time.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// AuditSink stores the audit events of the topology changes.
+type AuditSink interface {
+	// Record stores an event. Errors are logged, but don't fail the
+	// topology change which was made already.
+	Record(ctx context.Context, event *AuditEvent) error
+
+	// Events returns the stored events selected by the filter, oldest
+	// first.
+	Events(ctx context.Context, filter *AuditFilter) ([]*AuditEvent, error)
+
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// AuditSinkFactory creates an AuditSink from the command line flags.
+type AuditSinkFactory func() (AuditSink, error)
+
+var (
+	// topoAuditSink is the flag for which audit sink to use. The
+	// topology changes are not audited if it is empty.
+	topoAuditSink string
+
+	// auditSinkFactories has the factories for the AuditSink objects.
+	auditSinkFactories = make(map[string]AuditSinkFactory)
+
+	topoAuditEvents = stats.NewCountersWithMultiLabels(
+		"TopologyAuditEvents",
+		"Number of topology changes recorded in the audit sink",
+		[]string{"Operation", "Cell"})
+
+	topoAuditErrors = stats.NewCountersWithMultiLabels(
+		"TopologyAuditErrors",
+		"Number of topology changes which could not be recorded in the audit sink",
+		[]string{"Operation", "Cell"})
+)
+
+func init() {
+	for _, cmd := range FlagBinaries {
+		servenv.OnParseFor(cmd, registerTopoAuditFlags)
+	}
+}
+
+func registerTopoAuditFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&topoAuditSink, "topo_audit_sink", topoAuditSink, "the sink recording the changes made to the topology, for instance 'file' (empty to disable the audit)")
+}
+
+// RegisterAuditSinkFactory registers an AuditSinkFactory for an
+// implementation of AuditSink. If an implementation with that name
+// already exists, it log.Fatals out.
+func RegisterAuditSinkFactory(name string, factory AuditSinkFactory) {
+	if auditSinkFactories[name] != nil {
+		log.Fatalf("Duplicate topo.AuditSinkFactory registration for %v", name)
+	}
+	auditSinkFactories[name] = factory
+}
+
+// openAuditSink returns the AuditSink selected by the flags, or nil if
+// the topology changes are not audited.
+func openAuditSink() (AuditSink, error) {
+	if topoAuditSink == "" {
+		return nil, nil
+	}
+	factory, ok := auditSinkFactories[topoAuditSink]
+	if !ok {
+		return nil, NewError(NoImplementation, topoAuditSink)
+	}
+	return factory()
+}
+
+// SetAuditSink makes the server record all the changes made to the
+// topology in the sink, which is closed with the server. A nil sink
+// disables the audit.
+func (ts *Server) SetAuditSink(sink AuditSink) {
+	ts.auditMu.Lock()
+	defer ts.auditMu.Unlock()
+	ts.auditSink = sink
+}
+
+// AuditSink returns the sink recording the changes made to the
+// topology, or nil if they are not audited.
+func (ts *Server) AuditSink() AuditSink {
+	ts.auditMu.RLock()
+	defer ts.auditMu.RUnlock()
+	return ts.auditSink
+}
+
+// Context glue
+type auditReasonKeyType int
+
+var auditReasonKey auditReasonKeyType
+
+// WithAuditReason returns a context whose topology changes are audited
+// with the given reason.
+func WithAuditReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, auditReasonKey, reason)
+}
+
+// auditReason returns the reason of the changes made with the context:
+// the one set with WithAuditReason, or else the actions of the locks
+// which are held.
+func auditReason(ctx context.Context) string {
+	if reason, ok := ctx.Value(auditReasonKey).(string); ok {
+		return reason
+	}
+	i, ok := ctx.Value(locksKey).(*locksInfo)
+	if !ok {
+		return ""
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var actions []string
+	for name, li := range i.info {
+		actions = append(actions, name+": "+li.actionNode.Action)
+	}
+	sort.Strings(actions)
+	return strings.Join(actions, ", ")
+}
+
+// auditCaller returns the effective caller of the context, if any.
+func auditCaller(ctx context.Context) string {
+	ef := callerid.EffectiveCallerIDFromContext(ctx)
+	if ef == nil {
+		return ""
+	}
+	if component := callerid.GetComponent(ef); component != "" {
+		return callerid.GetPrincipal(ef) + "@" + component
+	}
+	return callerid.GetPrincipal(ef)
+}
+
+var (
+	processIdentityOnce sync.Once
+	processHostName     = "unknown"
+	processUserName     = "unknown"
+)
+
+// processIdentity returns the host and user names of this process.
+func processIdentity() (string, string) {
+	processIdentityOnce.Do(func() {
+		if h, err := os.Hostname(); err == nil {
+			processHostName = h
+		}
+		if u, err := user.Current(); err == nil {
+			processUserName = u.Username
+		}
+	})
+	return processHostName, processUserName
+}
+
+// auditValue returns the contents of a file as they are audited: as JSON
+// for the known topology records, and as is otherwise.
+func auditValue(filePath string, data []byte) string {
+	if data == nil {
+		return ""
+	}
+	if value, err := DecodeContent(filePath, data, true /*json*/); err == nil {
+		return value
+	}
+	return string(data)
+}
+
+var _ Conn = (*auditConn)(nil)
+
+// auditConn is a wrapper for a Conn that records all the changes in the
+// audit sink of the server, if it has one.
+type auditConn struct {
+	Conn
+	cell string
+	ts   *Server
+}
+
+// newAuditConn returns an auditConn.
+func newAuditConn(cell string, conn Conn, ts *Server) *auditConn {
+	return &auditConn{
+		Conn: conn,
+		cell: cell,
+		ts:   ts,
+	}
+}
+
+// record stores the event of a successful change in the sink.
+func (ac *auditConn) record(ctx context.Context, sink AuditSink, operation, filePath string, version Version, before, after []byte) {
+	hostName, userName := processIdentity()
+	event := &AuditEvent{
+		Time:      time.Now(),
+		Cell:      ac.cell,
+		Path:      filePath,
+		Operation: operation,
+		Caller:    auditCaller(ctx),
+		HostName:  hostName,
+		UserName:  userName,
+		Reason:    auditReason(ctx),
+		Before:    auditValue(filePath, before),
+		After:     auditValue(filePath, after),
+	}
+	if version != nil {
+		event.Version = version.String()
+	}
+
+	statsKey := []string{operation, ac.cell}
+	if err := sink.Record(ctx, event); err != nil {
+		topoAuditErrors.Add(statsKey, 1)
+		log.Warningf("cannot record the %v of %v in cell %v in the topology audit sink: %v", operation, filePath, ac.cell, err)
+		return
+	}
+	topoAuditEvents.Add(statsKey, 1)
+}
+
+// previous returns the contents of a file before it is changed, or nil
+// if they cannot be read.
+func (ac *auditConn) previous(ctx context.Context, filePath string) []byte {
+	contents, _, err := ac.Conn.Get(ctx, filePath)
+	if err != nil {
+		return nil
+	}
+	return contents
+}
+
+// Create is part of the Conn interface
+func (ac *auditConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	sink := ac.ts.AuditSink()
+	version, err := ac.Conn.Create(ctx, filePath, contents)
+	if err == nil && sink != nil {
+		ac.record(ctx, sink, "Create", filePath, version, nil, contents)
+	}
+	return version, err
+}
+
+// Update is part of the Conn interface
+func (ac *auditConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	sink := ac.ts.AuditSink()
+	if sink == nil {
+		return ac.Conn.Update(ctx, filePath, contents, version)
+	}
+	before := ac.previous(ctx, filePath)
+	newVersion, err := ac.Conn.Update(ctx, filePath, contents, version)
+	if err == nil {
+		operation := "Update"
+		if before == nil {
+			// Update with a nil version creates missing files.
+			operation = "Create"
+		}
+		ac.record(ctx, sink, operation, filePath, newVersion, before, contents)
+	}
+	return newVersion, err
+}
+
+// Delete is part of the Conn interface
+func (ac *auditConn) Delete(ctx context.Context, filePath string, version Version) error {
+	sink := ac.ts.AuditSink()
+	if sink == nil {
+		return ac.Conn.Delete(ctx, filePath, version)
+	}
+	before := ac.previous(ctx, filePath)
+	err := ac.Conn.Delete(ctx, filePath, version)
+	if err == nil {
+		ac.record(ctx, sink, "Delete", filePath, nil, before, nil)
+	}
+	return err
+}
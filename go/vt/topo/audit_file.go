@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// topoAuditFilePath is the file the 'file' audit sink appends to.
+var topoAuditFilePath string
+
+func init() {
+	for _, cmd := range FlagBinaries {
+		servenv.OnParseFor(cmd, registerTopoAuditFileFlags)
+	}
+
+	RegisterAuditSinkFactory("file", func() (AuditSink, error) {
+		if topoAuditFilePath == "" {
+			return nil, vterrors.New(vtrpc.Code_INVALID_ARGUMENT, "topo_audit_file_path must be set to use the file topology audit sink")
+		}
+		return NewFileAuditSink(topoAuditFilePath)
+	})
+}
+
+func registerTopoAuditFileFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&topoAuditFilePath, "topo_audit_file_path", topoAuditFilePath, "the file the topology changes are appended to, one JSON object per line, with --topo_audit_sink=file")
+}
+
+// FileAuditSink is an AuditSink appending the events to a file, one
+// JSON object per line. Several processes may share the same file.
+type FileAuditSink struct {
+	path string
+
+	// mu protects the writes to the file.
+	mu   sync.Mutex
+	file *os.File
+}
+
+var _ AuditSink = (*FileAuditSink)(nil)
+
+// NewFileAuditSink returns a FileAuditSink appending to the given file,
+// which is created if it doesn't exist.
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot open topology audit file %v", path)
+	}
+	return &FileAuditSink{
+		path: path,
+		file: file,
+	}, nil
+}
+
+// Record is part of the AuditSink interface.
+func (s *FileAuditSink) Record(ctx context.Context, event *AuditEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot JSON-marshal audit event")
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A single write, so the lines of concurrent writers on the same
+	// file don't interleave.
+	_, err = s.file.Write(data)
+	return err
+}
+
+// Events is part of the AuditSink interface.
+func (s *FileAuditSink) Events(ctx context.Context, filter *AuditFilter) ([]*AuditEvent, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot open topology audit file %v", s.path)
+	}
+	defer file.Close()
+
+	var events []*AuditEvent
+	scanner := bufio.NewScanner(file)
+	// The events hold whole topology records, which may be long lines.
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		event := &AuditEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot parse topology audit file %v", s.path)
+		}
+		if !filter.Matches(event) {
+			continue
+		}
+		events = append(events, event)
+		if filter.Limit > 0 && len(events) > 2*filter.Limit {
+			// Only keep the most recent events.
+			events = append(events[:0], events[len(events)-filter.Limit:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot read topology audit file %v", s.path)
+	}
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[len(events)-filter.Limit:]
+	}
+	return events, nil
+}
+
+// Close is part of the AuditSink interface.
+func (s *FileAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
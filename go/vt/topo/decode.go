@@ -31,40 +31,12 @@ import (
 // DecodeContent uses the filename to imply a type, and proto-decodes
 // the right object, then echoes it as a string.
 func DecodeContent(filename string, data []byte, json bool) (string, error) {
-	name := path.Base(filename)
-	dir := path.Dir(filename)
-	var p proto.Message
-	switch name {
-	case CellInfoFile:
-		p = new(topodatapb.CellInfo)
-	case KeyspaceFile:
-		p = new(topodatapb.Keyspace)
-	case ShardFile:
-		p = new(topodatapb.Shard)
-	case VSchemaFile:
-		p = new(vschemapb.Keyspace)
-	case ShardReplicationFile:
-		p = new(topodatapb.ShardReplication)
-	case TabletFile:
-		p = new(topodatapb.Tablet)
-	case SrvVSchemaFile:
-		p = new(vschemapb.SrvVSchema)
-	case SrvKeyspaceFile:
-		p = new(topodatapb.SrvKeyspace)
-	case RoutingRulesFile:
-		p = new(vschemapb.RoutingRules)
-	default:
-		switch dir {
-		case "/" + GetExternalVitessClusterDir():
-			p = new(topodatapb.ExternalVitessCluster)
-		default:
-		}
-		if p == nil {
-			if json {
-				return "", fmt.Errorf("unknown topo protobuf type for %v", name)
-			}
-			return string(data), nil
+	p := newContentMessage(filename)
+	if p == nil {
+		if json {
+			return "", fmt.Errorf("unknown topo protobuf type for %v", path.Base(filename))
 		}
+		return string(data), nil
 	}
 
 	if err := proto.Unmarshal(data, p); err != nil {
@@ -80,3 +52,52 @@ func DecodeContent(filename string, data []byte, json bool) (string, error) {
 	}
 	return string(marshalled), err
 }
+
+// EncodeContent is the reverse of DecodeContent with json set: it uses
+// the filename to imply a type, parses the JSON object, and returns its
+// proto encoding.
+func EncodeContent(filename string, data []byte) ([]byte, error) {
+	p := newContentMessage(filename)
+	if p == nil {
+		return nil, fmt.Errorf("unknown topo protobuf type for %v", path.Base(filename))
+	}
+	if err := protojson.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(p)
+}
+
+// newContentMessage returns a new proto message of the type stored in
+// filename, or nil if the type is unknown.
+func newContentMessage(filename string) proto.Message {
+	name := path.Base(filename)
+	dir := path.Dir(filename)
+	switch name {
+	case CellInfoFile:
+		return new(topodatapb.CellInfo)
+	case CellsAliasFile:
+		return new(topodatapb.CellsAlias)
+	case KeyspaceFile:
+		return new(topodatapb.Keyspace)
+	case ShardFile:
+		return new(topodatapb.Shard)
+	case VSchemaFile:
+		return new(vschemapb.Keyspace)
+	case ShardReplicationFile:
+		return new(topodatapb.ShardReplication)
+	case TabletFile:
+		return new(topodatapb.Tablet)
+	case SrvVSchemaFile:
+		return new(vschemapb.SrvVSchema)
+	case SrvKeyspaceFile:
+		return new(topodatapb.SrvKeyspace)
+	case RoutingRulesFile:
+		return new(vschemapb.RoutingRules)
+	case ShardRoutingRulesFile:
+		return new(vschemapb.ShardRoutingRules)
+	}
+	if dir == "/"+GetExternalVitessClusterDir() {
+		return new(topodatapb.ExternalVitessCluster)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqltopo
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// ListDir is part of the topo.Conn interface.
+func (s *Server) ListDir(ctx context.Context, dirPath string, full bool) ([]topo.DirEntry, error) {
+	nodePath := path.Join(s.root, dirPath) + "/"
+	if nodePath == "//" {
+		// Special case where s.root is "/", dirPath is empty,
+		// we would end up with "//". in that case, we want "/".
+		nodePath = "/"
+	}
+
+	start, end := prefixRange(nodePath)
+	rows, err := s.db.QueryContext(ctx, "SELECT path, lease_id FROM topo_files WHERE path >= ? AND path < ? AND "+s.liveRows()+" ORDER BY path", start, end)
+	if err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	defer rows.Close()
+
+	prefixLen := len(nodePath)
+	var result []topo.DirEntry
+	for rows.Next() {
+		var key []byte
+		var leaseID string
+		if err := rows.Scan(&key, &leaseID); err != nil {
+			return nil, convertError(err, nodePath)
+		}
+
+		// Remove the prefix, base path.
+		p := string(key)[prefixLen:]
+
+		// Keep only the part until the first '/'.
+		t := topo.TypeFile
+		if i := strings.Index(p, "/"); i >= 0 {
+			p = p[:i]
+			t = topo.TypeDirectory
+		}
+
+		// Remove duplicates, add to list.
+		if len(result) == 0 || result[len(result)-1].Name != p {
+			e := topo.DirEntry{
+				Name: p,
+			}
+			if full {
+				e.Type = t
+				if leaseID != "" {
+					// Only locks and elections have a lease.
+					e.Ephemeral = true
+				}
+			}
+			result = append(result, e)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	if len(result) == 0 {
+		// No file starts with this prefix, means the directory
+		// doesn't exist.
+		return nil, topo.NewError(topo.NoNode, nodePath)
+	}
+	return result, nil
+}
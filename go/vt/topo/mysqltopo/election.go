@@ -0,0 +1,190 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqltopo
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// NewLeaderParticipation is part of the topo.Server interface
+func (s *Server) NewLeaderParticipation(name, id string) (topo.LeaderParticipation, error) {
+	return &mysqlLeaderParticipation{
+		s:    s,
+		name: name,
+		id:   id,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}, nil
+}
+
+// mysqlLeaderParticipation implements topo.LeaderParticipation.
+//
+// We use the lock of a directory (in global election path, with the
+// name), that contains the id of the leader.
+type mysqlLeaderParticipation struct {
+	// s is our parent mysql topo Server
+	s *Server
+
+	// name is the name of this LeaderParticipation
+	name string
+
+	// id is the process's current id.
+	id string
+
+	// stop is a channel closed when Stop is called.
+	stop chan struct{}
+
+	// done is a channel closed when we're done processing the Stop
+	done chan struct{}
+
+	// mu protects ld.
+	mu sync.Mutex
+	// ld is the lock we hold while we are the leader.
+	ld *mysqlLockDescriptor
+}
+
+// WaitForLeadership is part of the topo.LeaderParticipation interface.
+func (mp *mysqlLeaderParticipation) WaitForLeadership() (context.Context, error) {
+	// If Stop was already called, mp.done is closed, so we are interrupted.
+	select {
+	case <-mp.done:
+		return nil, topo.NewError(topo.Interrupted, "Leadership")
+	default:
+	}
+
+	electionPath := path.Join(electionsPath, mp.name)
+
+	// We use a cancelable context here. If stop is closed,
+	// we just cancel that context.
+	lockCtx, lockCancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-mp.s.running:
+			return
+		case <-mp.stop:
+		}
+		lockCancel()
+		mp.mu.Lock()
+		if mp.ld != nil {
+			if err := mp.ld.Unlock(context.Background()); err != nil {
+				log.Errorf("failed to unlock electionPath %v: %v", electionPath, err)
+			}
+			mp.ld = nil
+		}
+		mp.mu.Unlock()
+		close(mp.done)
+	}()
+
+	// Try to get the primaryship, by getting a lock.
+	ld, err := mp.s.lock(lockCtx, electionPath, mp.id)
+	if err != nil {
+		// It can be that we were interrupted.
+		return nil, err
+	}
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if lockCtx.Err() != nil {
+		// Stop was called while we were getting the lock.
+		if err := ld.Unlock(context.Background()); err != nil {
+			log.Errorf("failed to unlock electionPath %v: %v", electionPath, err)
+		}
+		return nil, topo.NewError(topo.Interrupted, "Leadership")
+	}
+	mp.ld = ld
+
+	// We got the lock. Return the lockContext. If Stop() is called,
+	// it will cancel the lockCtx, and cancel the returned context.
+	return lockCtx, nil
+}
+
+// Stop is part of the topo.LeaderParticipation interface
+func (mp *mysqlLeaderParticipation) Stop() {
+	close(mp.stop)
+	<-mp.done
+}
+
+// GetCurrentLeaderID is part of the topo.LeaderParticipation interface
+func (mp *mysqlLeaderParticipation) GetCurrentLeaderID(ctx context.Context) (string, error) {
+	lockPath := path.Join(mp.s.root, electionsPath, mp.name, locksPath)
+
+	var id []byte
+	err := mp.s.db.QueryRowContext(ctx, "SELECT contents FROM topo_files WHERE path = ? AND "+mp.s.liveRows(), []byte(lockPath)).Scan(&id)
+	switch {
+	case err == nil:
+		return string(id), nil
+	case topo.IsErrType(convertError(err, lockPath), topo.NoNode):
+		// Nobody holds the lock, means nobody is the primary.
+		return "", nil
+	default:
+		return "", convertError(err, lockPath)
+	}
+}
+
+// WaitForNewLeader is part of the topo.LeaderParticipation interface
+func (mp *mysqlLeaderParticipation) WaitForNewLeader(ctx context.Context) (<-chan string, error) {
+	// Get the current leader
+	leader, err := mp.GetCurrentLeaderID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make(chan string, 8)
+	if leader != "" {
+		notifications <- leader
+	}
+
+	go func() {
+		defer close(notifications)
+
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-mp.s.running:
+				return
+			case <-mp.done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			currentLeader, err := mp.GetCurrentLeaderID(ctx)
+			if err != nil || currentLeader == "" || currentLeader == leader {
+				continue
+			}
+			leader = currentLeader
+			select {
+			case notifications <- leader:
+			case <-mp.s.running:
+				return
+			case <-mp.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return notifications, nil
+}
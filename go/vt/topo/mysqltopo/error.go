@@ -0,0 +1,52 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqltopo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// convertError converts a database/sql error into a topo error. All
+// errors are either application-level errors, or context errors.
+func convertError(err error, nodePath string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return topo.NewError(topo.NoNode, nodePath)
+	case errors.Is(err, context.Canceled):
+		return topo.NewError(topo.Interrupted, nodePath)
+	case errors.Is(err, context.DeadlineExceeded):
+		return topo.NewError(topo.Timeout, nodePath)
+	default:
+		return err
+	}
+}
+
+// convertContextError returns the converted context error if ctx is
+// done, and the converted err otherwise. Some drivers return their own
+// errors when a query is interrupted by the context.
+func convertContextError(ctx context.Context, err error, nodePath string) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return convertError(ctxErr, nodePath)
+	}
+	return convertError(err, nodePath)
+}
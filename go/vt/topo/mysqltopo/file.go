@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqltopo
+
+import (
+	"context"
+	"database/sql"
+	"path"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// getVersion returns the version of a file in a write transaction.
+func getVersion(ctx context.Context, tx *sql.Tx, nodePath string) (int64, error) {
+	var version int64
+	err := tx.QueryRowContext(ctx, "SELECT version FROM topo_files WHERE path = ?", []byte(nodePath)).Scan(&version)
+	return version, convertError(err, nodePath)
+}
+
+// Create is part of the topo.Conn interface.
+func (s *Server) Create(ctx context.Context, filePath string, contents []byte) (topo.Version, error) {
+	nodePath := path.Join(s.root, filePath)
+
+	var version int64
+	err := s.write(ctx, nodePath, func(tx *sql.Tx, revision int64) error {
+		_, err := getVersion(ctx, tx, nodePath)
+		switch {
+		case err == nil:
+			return topo.NewError(topo.NodeExists, nodePath)
+		case !topo.IsErrType(err, topo.NoNode):
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO topo_files (path, contents, version) VALUES (?, ?, ?)", []byte(nodePath), nonNil(contents), revision); err != nil {
+			return convertError(err, nodePath)
+		}
+		version = revision
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return MySQLVersion(version), nil
+}
+
+// Update is part of the topo.Conn interface.
+func (s *Server) Update(ctx context.Context, filePath string, contents []byte, version topo.Version) (topo.Version, error) {
+	nodePath := path.Join(s.root, filePath)
+
+	var newVersion int64
+	err := s.write(ctx, nodePath, func(tx *sql.Tx, revision int64) error {
+		current, err := getVersion(ctx, tx, nodePath)
+		switch {
+		case err == nil:
+			if version != nil && current != int64(version.(MySQLVersion)) {
+				return topo.NewError(topo.BadVersion, nodePath)
+			}
+			_, err = tx.ExecContext(ctx, "UPDATE topo_files SET contents = ?, version = ? WHERE path = ?", nonNil(contents), revision, []byte(nodePath))
+		case topo.IsErrType(err, topo.NoNode):
+			if version != nil {
+				// There is nothing to compare the version with.
+				return topo.NewError(topo.BadVersion, nodePath)
+			}
+			_, err = tx.ExecContext(ctx, "INSERT INTO topo_files (path, contents, version) VALUES (?, ?, ?)", []byte(nodePath), nonNil(contents), revision)
+		default:
+			return err
+		}
+		if err != nil {
+			return convertError(err, nodePath)
+		}
+		newVersion = revision
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return MySQLVersion(newVersion), nil
+}
+
+// Get is part of the topo.Conn interface.
+func (s *Server) Get(ctx context.Context, filePath string) ([]byte, topo.Version, error) {
+	nodePath := path.Join(s.root, filePath)
+
+	var contents []byte
+	var version int64
+	if err := s.db.QueryRowContext(ctx, "SELECT contents, version FROM topo_files WHERE path = ?", []byte(nodePath)).Scan(&contents, &version); err != nil {
+		return nil, nil, convertError(err, nodePath)
+	}
+	return contents, MySQLVersion(version), nil
+}
+
+// List is part of the topo.Conn interface.
+func (s *Server) List(ctx context.Context, filePathPrefix string) ([]topo.KVInfo, error) {
+	nodePathPrefix := path.Join(s.root, filePathPrefix)
+
+	start, end := prefixRange(nodePathPrefix)
+	rows, err := s.db.QueryContext(ctx, "SELECT path, contents, version FROM topo_files WHERE path >= ? AND path < ? ORDER BY path", start, end)
+	if err != nil {
+		return []topo.KVInfo{}, convertError(err, nodePathPrefix)
+	}
+	defer rows.Close()
+
+	var results []topo.KVInfo
+	for rows.Next() {
+		var kv topo.KVInfo
+		var version int64
+		if err := rows.Scan(&kv.Key, &kv.Value, &version); err != nil {
+			return []topo.KVInfo{}, convertError(err, nodePathPrefix)
+		}
+		kv.Version = MySQLVersion(version)
+		results = append(results, kv)
+	}
+	if err := rows.Err(); err != nil {
+		return []topo.KVInfo{}, convertError(err, nodePathPrefix)
+	}
+	if len(results) == 0 {
+		return []topo.KVInfo{}, topo.NewError(topo.NoNode, nodePathPrefix)
+	}
+	return results, nil
+}
+
+// Delete is part of the topo.Conn interface.
+func (s *Server) Delete(ctx context.Context, filePath string, version topo.Version) error {
+	nodePath := path.Join(s.root, filePath)
+
+	return s.write(ctx, nodePath, func(tx *sql.Tx, revision int64) error {
+		current, err := getVersion(ctx, tx, nodePath)
+		if err != nil {
+			return err
+		}
+		if version != nil && current != int64(version.(MySQLVersion)) {
+			return topo.NewError(topo.BadVersion, nodePath)
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM topo_files WHERE path = ?", []byte(nodePath))
+		return convertError(err, nodePath)
+	})
+}
+
+// nonNil returns an empty slice for nil contents, as the contents
+// column cannot be NULL.
+func nonNil(contents []byte) []byte {
+	if contents == nil {
+		return []byte{}
+	}
+	return contents
+}
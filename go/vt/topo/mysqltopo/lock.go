@@ -0,0 +1,212 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqltopo
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// mysqlLockDescriptor implements topo.LockDescriptor.
+type mysqlLockDescriptor struct {
+	s        *Server
+	nodePath string
+	leaseID  string
+
+	// stop is closed by Unlock, to stop the lease keepalive.
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// newLeaseID returns a random lease identifier.
+func newLeaseID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// leaseSeconds returns the lease TTL, in seconds.
+func leaseSeconds() int64 {
+	if seconds := int64(leaseTTL / time.Second); seconds > 0 {
+		return seconds
+	}
+	return 1
+}
+
+// TryLock is part of the topo.Conn interface.
+func (s *Server) TryLock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+	// We list the directory first to make sure it exists.
+	if _, err := s.ListDir(ctx, dirPath, false /*full*/); err != nil {
+		return nil, convertError(err, dirPath)
+	}
+
+	nodePath := path.Join(s.root, dirPath, locksPath)
+	ld, acquired, err := s.tryAcquire(ctx, nodePath, contents)
+	if err != nil {
+		return nil, convertContextError(ctx, err, nodePath)
+	}
+	if !acquired {
+		return nil, topo.NewError(topo.NodeExists, fmt.Sprintf("lock already exists at path %s", dirPath))
+	}
+	return ld, nil
+}
+
+// Lock is part of the topo.Conn interface.
+func (s *Server) Lock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
+	// We list the directory first to make sure it exists.
+	if _, err := s.ListDir(ctx, dirPath, false /*full*/); err != nil {
+		return nil, convertError(err, dirPath)
+	}
+	return s.lock(ctx, dirPath, contents)
+}
+
+// lock is used by both Lock() and primary election. It polls the lock
+// until it is released, or the context is done.
+func (s *Server) lock(ctx context.Context, dirPath, contents string) (*mysqlLockDescriptor, error) {
+	nodePath := path.Join(s.root, dirPath, locksPath)
+	for {
+		ld, acquired, err := s.tryAcquire(ctx, nodePath, contents)
+		if err != nil {
+			return nil, convertContextError(ctx, err, nodePath)
+		}
+		if acquired {
+			return ld, nil
+		}
+
+		t := time.NewTimer(lockPollInterval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, convertError(ctx.Err(), nodePath)
+		case <-s.running:
+			t.Stop()
+			return nil, topo.NewError(topo.Interrupted, nodePath)
+		case <-t.C:
+		}
+	}
+}
+
+// tryAcquire creates the ephemeral lock file, unless it is held by a
+// lease that hasn't expired yet. It returns false if the lock is held.
+func (s *Server) tryAcquire(ctx context.Context, nodePath, contents string) (*mysqlLockDescriptor, bool, error) {
+	leaseID, err := newLeaseID()
+	if err != nil {
+		return nil, false, err
+	}
+
+	acquired := false
+	err = s.write(ctx, nodePath, func(tx *sql.Tx, revision int64) error {
+		// Take over the lock if its lease expired.
+		if _, err := tx.ExecContext(ctx, "DELETE FROM topo_files WHERE path = ? AND lease_id != '' AND lease_expiry < UNIX_TIMESTAMP()", []byte(nodePath)); err != nil {
+			return convertError(err, nodePath)
+		}
+		_, err := getVersion(ctx, tx, nodePath)
+		switch {
+		case err == nil:
+			// Someone else holds the lock.
+			return nil
+		case !topo.IsErrType(err, topo.NoNode):
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO topo_files (path, contents, version, lease_id, lease_expiry) VALUES (?, ?, ?, ?, UNIX_TIMESTAMP() + ?)", []byte(nodePath), []byte(contents), revision, leaseID, leaseSeconds()); err != nil {
+			return convertError(err, nodePath)
+		}
+		acquired = true
+		return nil
+	})
+	if err != nil || !acquired {
+		return nil, false, err
+	}
+
+	ld := &mysqlLockDescriptor{
+		s:        s,
+		nodePath: nodePath,
+		leaseID:  leaseID,
+		stop:     make(chan struct{}),
+	}
+	go ld.keepAlive()
+	return ld, true, nil
+}
+
+// keepAlive extends the lease until the lock is released.
+func (ld *mysqlLockDescriptor) keepAlive() {
+	ticker := time.NewTicker(leaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ld.stop:
+			return
+		case <-ld.s.running:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+		_, err := ld.s.db.ExecContext(ctx, "UPDATE topo_files SET lease_expiry = UNIX_TIMESTAMP() + ? WHERE path = ? AND lease_id = ?", leaseSeconds(), []byte(ld.nodePath), ld.leaseID)
+		cancel()
+		if err != nil {
+			log.Warningf("failed to extend the lease of lock %v: %v", ld.nodePath, err)
+		}
+	}
+}
+
+// stopKeepAlive stops extending the lease.
+func (ld *mysqlLockDescriptor) stopKeepAlive() {
+	ld.stopOnce.Do(func() {
+		close(ld.stop)
+	})
+}
+
+// Check is part of the topo.LockDescriptor interface.
+// We make sure the lock file still has our unexpired lease.
+func (ld *mysqlLockDescriptor) Check(ctx context.Context) error {
+	var leaseID string
+	err := ld.s.db.QueryRowContext(ctx, "SELECT lease_id FROM topo_files WHERE path = ? AND lease_id = ? AND lease_expiry >= UNIX_TIMESTAMP()", []byte(ld.nodePath), ld.leaseID).Scan(&leaseID)
+	return convertError(err, ld.nodePath)
+}
+
+// Unlock is part of the topo.LockDescriptor interface.
+func (ld *mysqlLockDescriptor) Unlock(ctx context.Context) error {
+	ld.stopKeepAlive()
+	return ld.s.write(ctx, ld.nodePath, func(tx *sql.Tx, revision int64) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM topo_files WHERE path = ? AND lease_id = ?", []byte(ld.nodePath), ld.leaseID)
+		if err != nil {
+			return convertError(err, ld.nodePath)
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return convertError(err, ld.nodePath)
+		}
+		if deleted != 1 {
+			// The lease expired and the lock was taken over, or
+			// it was already released.
+			return topo.NewError(topo.NoNode, ld.nodePath)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package mysqltopo implements topo.Server with a MySQL database as the backend.
+
+The server address is a go-sql-driver/mysql DSN, for instance
+"user:password@tcp(host:3306)/topo". The tables are created in the
+database of the DSN if they do not exist yet, so the user needs the
+CREATE privilege on first use.
+
+We follow these conventions within this package:
+  - All the files are rows of the topo_files table, keyed by their full
+    path, including the root.
+  - Every write runs in a transaction which first bumps the single row of
+    the topo_revision table. This serializes writes, and gives every
+    change a new, monotonic revision that is used as the file version.
+  - Locks and elections are ephemeral rows, with a lease that is kept
+    alive while they are held. Expired leases can be taken over.
+  - Watches are implemented by polling the table.
+  - Call convertError(err) on any errors returned from database/sql.
+    Functions defined in this package can be assumed to have already
+    converted errors as necessary.
+*/
+package mysqltopo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	// Register the MySQL driver for database/sql.
+	_ "github.com/go-sql-driver/mysql"
+
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	// locksPath is the name of the ephemeral file holding a lock,
+	// inside the locked directory.
+	locksPath = "locks"
+
+	// electionsPath is the directory used for leader elections.
+	electionsPath = "elections"
+)
+
+var (
+	leaseTTL          = 30 * time.Second
+	lockPollInterval  = 100 * time.Millisecond
+	watchPollInterval = time.Second
+)
+
+// createTables are run when a Server is created.
+var createTables = []string{
+	`CREATE TABLE IF NOT EXISTS topo_files (
+  path VARBINARY(1024) NOT NULL,
+  contents LONGBLOB NOT NULL,
+  version BIGINT NOT NULL,
+  lease_id VARCHAR(64) NOT NULL DEFAULT '',
+  lease_expiry BIGINT NOT NULL DEFAULT 0,
+  PRIMARY KEY (path)
+) ENGINE=InnoDB`,
+	`CREATE TABLE IF NOT EXISTS topo_revision (
+  id INT NOT NULL,
+  revision BIGINT NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB`,
+	`INSERT IGNORE INTO topo_revision (id, revision) VALUES (1, 0)`,
+}
+
+// Factory is the mysql topo.Factory implementation.
+type Factory struct{}
+
+// HasGlobalReadOnlyCell is part of the topo.Factory interface.
+func (f Factory) HasGlobalReadOnlyCell(serverAddr, root string) bool {
+	return false
+}
+
+// Create is part of the topo.Factory interface.
+func (f Factory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	return NewServer(serverAddr, root)
+}
+
+// Server is the implementation of topo.Server for MySQL.
+type Server struct {
+	// db is the connection pool to the database.
+	db *sql.DB
+
+	// root is the root path for this client.
+	root string
+
+	running chan struct{}
+}
+
+func init() {
+	for _, cmd := range topo.FlagBinaries {
+		servenv.OnParseFor(cmd, registerMySQLTopoFlags)
+	}
+	topo.RegisterFactory("mysql", Factory{})
+}
+
+func registerMySQLTopoFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&leaseTTL, "topo_mysql_lease_ttl", leaseTTL, "Lease TTL for locks and leader election in the mysql topo server. The client keeps the lease alive while it holds the lock.")
+	fs.DurationVar(&lockPollInterval, "topo_mysql_lock_poll_interval", lockPollInterval, "How often a client waiting for a lock in the mysql topo server checks if it was released.")
+	fs.DurationVar(&watchPollInterval, "topo_mysql_watch_poll_interval", watchPollInterval, "How often watches poll the mysql topo server for changes.")
+}
+
+// NewServer returns a new mysqltopo.Server, connected with the provided
+// go-sql-driver/mysql DSN.
+func NewServer(serverAddr, root string) (*Server, error) {
+	db, err := sql.Open("mysql", serverAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), topo.RemoteOperationTimeout)
+	defer cancel()
+	for _, query := range createTables {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			db.Close()
+			return nil, vterrors.Wrapf(err, "cannot create the topo tables")
+		}
+	}
+
+	return &Server{
+		db:      db,
+		root:    root,
+		running: make(chan struct{}),
+	}, nil
+}
+
+// Close implements topo.Server.Close.
+// It will nil out the db field, so any attempt to re-use this server
+// will panic.
+func (s *Server) Close() {
+	close(s.running)
+	s.db.Close()
+	s.db = nil
+}
+
+// write runs f in a transaction, after bumping the global revision.
+// f is given the new revision, to use as the version of the files it
+// changes. The transaction is committed if f returns no error.
+func (s *Server) write(ctx context.Context, nodePath string, f func(tx *sql.Tx, revision int64) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return convertError(err, nodePath)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE topo_revision SET revision = revision + 1 WHERE id = 1"); err != nil {
+		return convertError(err, nodePath)
+	}
+	var revision int64
+	if err := tx.QueryRowContext(ctx, "SELECT revision FROM topo_revision WHERE id = 1").Scan(&revision); err != nil {
+		return convertError(err, nodePath)
+	}
+	if err := f(tx, revision); err != nil {
+		return err
+	}
+	return convertError(tx.Commit(), nodePath)
+}
+
+// liveRows is the condition selecting the rows which are not expired
+// ephemeral files. Lease expiries are compared with UNIX_TIMESTAMP(),
+// evaluated by the database, so they don't depend on the clocks of the
+// clients.
+func (s *Server) liveRows() string {
+	return "(lease_id = '' OR lease_expiry >= UNIX_TIMESTAMP())"
+}
+
+// prefixRange returns the bounds of the paths starting with prefix, to
+// scan them with "path >= start AND path < end".
+func prefixRange(prefix string) ([]byte, []byte) {
+	start := []byte(prefix)
+	end := make([]byte, len(start))
+	copy(end, start)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return start, end[:i+1]
+		}
+	}
+	// The prefix is only made of 0xff bytes, there is no upper bound
+	// that is a string. It cannot happen with the paths we use.
+	return start, append(start, 0xff)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqltopo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/test"
+	"vitess.io/vitess/go/vt/vttest"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vttestpb "vitess.io/vitess/go/vt/proto/vttest"
+)
+
+// setFastPolling makes the locks and watches poll quickly, so the
+// tests don't take too long.
+func setFastPolling(t *testing.T) {
+	oldLock, oldWatch := lockPollInterval, watchPollInterval
+	t.Cleanup(func() {
+		lockPollInterval, watchPollInterval = oldLock, oldWatch
+	})
+	lockPollInterval = 10 * time.Millisecond
+	watchPollInterval = 20 * time.Millisecond
+}
+
+func runTopoServerTestSuite(t *testing.T, factory topo.Factory, serverAddr string) {
+	testIndex := 0
+	newServer := func() *topo.Server {
+		// Each test will use its own sub-directories.
+		testRoot := fmt.Sprintf("/test-%v", testIndex)
+		testIndex++
+
+		// Create the server on the new root.
+		ts, err := topo.NewWithFactory(factory, serverAddr, path.Join(testRoot, topo.GlobalCell))
+		if err != nil {
+			t.Fatalf("NewWithFactory() failed: %v", err)
+		}
+
+		// Create the CellInfo.
+		if err := ts.CreateCellInfo(context.Background(), test.LocalCellName, &topodatapb.CellInfo{
+			ServerAddress: serverAddr,
+			Root:          path.Join(testRoot, test.LocalCellName),
+		}); err != nil {
+			t.Fatalf("CreateCellInfo() failed: %v", err)
+		}
+
+		return ts
+	}
+
+	// Run the TopoServerTestSuite tests.
+	test.TopoServerTestSuite(t, newServer, []string{})
+}
+
+// startMySQL launches a mysqld with vttest, and returns the DSN to
+// connect to it. The test is skipped if mysqld cannot be launched.
+func startMySQL(t *testing.T) string {
+	// We need a Keyspace in the topology, so the DbName is set.
+	// We need a Shard too, so the database 'vttest' is created.
+	cfg := vttest.Config{
+		Topology: &vttestpb.VTTestTopology{
+			Keyspaces: []*vttestpb.Keyspace{
+				{
+					Name: "vttest",
+					Shards: []*vttestpb.Shard{
+						{
+							Name:           "0",
+							DbNameOverride: "vttest",
+						},
+					},
+				},
+			},
+		},
+		OnlyMySQL: true,
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(cfg.SchemaDir)
+	})
+	cluster := vttest.LocalCluster{
+		Config: cfg,
+	}
+	if err := cluster.Setup(); err != nil {
+		t.Skipf("could not launch mysql: %v", err)
+	}
+	t.Cleanup(func() {
+		cluster.TearDown()
+	})
+
+	connParams := cluster.MySQLConnParams()
+	return fmt.Sprintf("%v@unix(%v)/%v", connParams.Uname, connParams.UnixSocket, connParams.DbName)
+}
+
+func TestMySQLTopo(t *testing.T) {
+	setFastPolling(t)
+	runTopoServerTestSuite(t, Factory{}, startMySQL(t))
+}
+
+func TestLockExpiry(t *testing.T) {
+	setFastPolling(t)
+	oldTTL := leaseTTL
+	defer func() {
+		leaseTTL = oldTTL
+	}()
+	leaseTTL = time.Second
+
+	dsn := startMySQL(t)
+	ctx := context.Background()
+	s, err := NewServer(dsn, "/root")
+	require.NoError(t, err)
+	defer s.Close()
+	_, err = s.Create(ctx, "/keyspaces/ks/Keyspace", []byte("ks"))
+	require.NoError(t, err)
+
+	ld, err := s.Lock(ctx, "/keyspaces/ks", "first")
+	require.NoError(t, err)
+	entries, err := s.ListDir(ctx, "/keyspaces/ks", true /*full*/)
+	require.NoError(t, err)
+	assert.Equal(t, []topo.DirEntry{
+		{Name: "Keyspace", Type: topo.TypeFile},
+		{Name: locksPath, Type: topo.TypeFile, Ephemeral: true},
+	}, entries)
+
+	// The lease is kept alive while the lock is held.
+	time.Sleep(2500 * time.Millisecond)
+	require.NoError(t, ld.Check(ctx))
+	_, err = s.TryLock(ctx, "/keyspaces/ks", "second")
+	assert.True(t, topo.IsErrType(err, topo.NodeExists), "TryLock: %v", err)
+
+	// A lock whose lease is not extended anymore expires, and can be
+	// taken over.
+	ld.(*mysqlLockDescriptor).stopKeepAlive()
+	lockCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	ld2, err := s.Lock(lockCtx, "/keyspaces/ks", "second")
+	require.NoError(t, err)
+	assert.Error(t, ld.Check(ctx))
+	assert.Error(t, ld.Unlock(ctx))
+	require.NoError(t, ld2.Unlock(ctx))
+
+	_, err = s.ListDir(ctx, "/keyspaces/ks/"+locksPath, false /*full*/)
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "ListDir: %v", err)
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqltopo
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo"
+)
+
+// Watch is part of the topo.Conn interface.
+func (s *Server) Watch(ctx context.Context, filePath string) (*topo.WatchData, <-chan *topo.WatchData, error) {
+	nodePath := path.Join(s.root, filePath)
+
+	// Get the initial version of the file.
+	initialCtx, initialCancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+	defer initialCancel()
+	contents, version, err := s.Get(initialCtx, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	wd := &topo.WatchData{
+		Contents: contents,
+		Version:  version,
+	}
+
+	// Create the notifications channel, send updates to it.
+	notifications := make(chan *topo.WatchData, 10)
+	go func() {
+		defer close(notifications)
+
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.running:
+				return
+			case <-ctx.Done():
+				// This includes context cancellation errors.
+				notifications <- &topo.WatchData{
+					Err: convertError(ctx.Err(), nodePath),
+				}
+				return
+			case <-ticker.C:
+			}
+
+			pollCtx, pollCancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+			contents, newVersion, err := s.Get(pollCtx, filePath)
+			pollCancel()
+			switch {
+			case topo.IsErrType(err, topo.NoNode):
+				// Node is gone, send a final notice.
+				notifications <- &topo.WatchData{
+					Err: err,
+				}
+				return
+			case err != nil:
+				// Interruptions are handled at the next iteration,
+				// other errors are retried.
+				if ctx.Err() == nil {
+					log.Warningf("polling watch %v failed, will retry: %v", nodePath, err)
+				}
+				continue
+			}
+			if newVersion.(MySQLVersion) == version.(MySQLVersion) {
+				continue
+			}
+			version = newVersion
+			notifications <- &topo.WatchData{
+				Contents: contents,
+				Version:  newVersion,
+			}
+		}
+	}()
+
+	return wd, notifications, nil
+}
+
+// WatchRecursive is part of the topo.Conn interface.
+func (s *Server) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.WatchDataRecursive, <-chan *topo.WatchDataRecursive, error) {
+	nodePath := path.Join(s.root, dirpath)
+	if !strings.HasSuffix(nodePath, "/") {
+		nodePath = nodePath + "/"
+	}
+
+	// Get the initial version of the files.
+	initial, err := s.listRecursive(ctx, nodePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	versions := make(map[string]topo.Version, len(initial))
+	for _, wd := range initial {
+		versions[wd.Path] = wd.Version
+	}
+
+	// Create the notifications channel, send updates to it.
+	notifications := make(chan *topo.WatchDataRecursive, 10)
+	go func() {
+		defer close(notifications)
+
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.running:
+				return
+			case <-ctx.Done():
+				// This includes context cancellation errors.
+				notifications <- &topo.WatchDataRecursive{
+					WatchData: topo.WatchData{Err: convertError(ctx.Err(), nodePath)},
+				}
+				return
+			case <-ticker.C:
+			}
+
+			pollCtx, pollCancel := context.WithTimeout(ctx, topo.RemoteOperationTimeout)
+			current, err := s.listRecursive(pollCtx, nodePath)
+			pollCancel()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Warningf("polling watch %v failed, will retry: %v", nodePath, err)
+				}
+				continue
+			}
+
+			seen := make(map[string]bool, len(current))
+			for _, wd := range current {
+				seen[wd.Path] = true
+				if version, ok := versions[wd.Path]; ok && version.(MySQLVersion) == wd.Version.(MySQLVersion) {
+					continue
+				}
+				versions[wd.Path] = wd.Version
+				notifications <- wd
+			}
+			for p := range versions {
+				if seen[p] {
+					continue
+				}
+				delete(versions, p)
+				notifications <- &topo.WatchDataRecursive{
+					Path: p,
+					WatchData: topo.WatchData{
+						Err: topo.NewError(topo.NoNode, p),
+					},
+				}
+			}
+		}
+	}()
+
+	return initial, notifications, nil
+}
+
+// listRecursive returns all the files under nodePath, which ends
+// with a '/'.
+func (s *Server) listRecursive(ctx context.Context, nodePath string) ([]*topo.WatchDataRecursive, error) {
+	start, end := prefixRange(nodePath)
+	rows, err := s.db.QueryContext(ctx, "SELECT path, contents, version FROM topo_files WHERE path >= ? AND path < ? ORDER BY path", start, end)
+	if err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	defer rows.Close()
+
+	var result []*topo.WatchDataRecursive
+	for rows.Next() {
+		var key, contents []byte
+		var version int64
+		if err := rows.Scan(&key, &contents, &version); err != nil {
+			return nil, convertError(err, nodePath)
+		}
+		result = append(result, &topo.WatchDataRecursive{
+			Path: string(key),
+			WatchData: topo.WatchData{
+				Contents: contents,
+				Version:  MySQLVersion(version),
+			},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, convertError(err, nodePath)
+	}
+	return result, nil
+}
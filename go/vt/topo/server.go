@@ -141,6 +141,11 @@ type Server struct {
 	// will read the list of addresses for that cell from the
 	// global cluster and create clients as needed.
 	cellConns map[string]cellConn
+
+	// auditMu protects auditSink.
+	auditMu sync.RWMutex
+	// auditSink records the changes made to the topology, if set.
+	auditSink AuditSink
 }
 
 type cellConn struct {
@@ -208,10 +213,20 @@ func NewWithFactory(factory Factory, serverAddress, root string) (*Server, error
 	}
 	conn = NewStatsConn(GlobalCell, conn)
 
+	auditSink, err := openAuditSink()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
 	var connReadOnly Conn
 	if factory.HasGlobalReadOnlyCell(serverAddress, root) {
 		connReadOnly, err = factory.Create(GlobalReadOnlyCell, serverAddress, root)
 		if err != nil {
+			conn.Close()
+			if auditSink != nil {
+				auditSink.Close()
+			}
 			return nil, err
 		}
 		connReadOnly = NewStatsConn(GlobalReadOnlyCell, connReadOnly)
@@ -219,12 +234,17 @@ func NewWithFactory(factory Factory, serverAddress, root string) (*Server, error
 		connReadOnly = conn
 	}
 
-	return &Server{
-		globalCell:         conn,
+	ts := &Server{
 		globalReadOnlyCell: connReadOnly,
 		factory:            factory,
 		cellConns:          make(map[string]cellConn),
-	}, nil
+		auditSink:          auditSink,
+	}
+	ts.globalCell = newAuditConn(GlobalCell, conn, ts)
+	if connReadOnly == conn {
+		ts.globalReadOnlyCell = ts.globalCell
+	}
+	return ts, nil
 }
 
 // OpenServer returns a Server using the provided implementation,
@@ -295,7 +315,7 @@ func (ts *Server) ConnForCell(ctx context.Context, cell string) (Conn, error) {
 	conn, err := ts.factory.Create(cell, ci.ServerAddress, ci.Root)
 	switch {
 	case err == nil:
-		conn = NewStatsConn(cell, conn)
+		conn = newAuditConn(cell, NewStatsConn(cell, conn), ts)
 		ts.cellConns[cell] = cellConn{ci, conn}
 		return conn, nil
 	case IsErrType(err, NoNode):
@@ -349,6 +369,13 @@ func (ts *Server) Close() {
 		cc.conn.Close()
 	}
 	ts.cellConns = make(map[string]cellConn)
+
+	if sink := ts.AuditSink(); sink != nil {
+		if err := sink.Close(); err != nil {
+			log.Warningf("cannot close the topology audit sink: %v", err)
+		}
+		ts.SetAuditSink(nil)
+	}
 }
 
 func (ts *Server) clearCellAliasesCache() {
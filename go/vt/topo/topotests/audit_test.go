@@ -0,0 +1,131 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestAudit(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	defer ts.Close()
+
+	// Nothing is recorded until the sink is set.
+	require.NoError(t, ts.CreateKeyspace(ctx, "unaudited", &topodatapb.Keyspace{}))
+	assert.Nil(t, ts.AuditSink())
+
+	sink, err := topo.NewFileAuditSink(path.Join(t.TempDir(), "audit.log"))
+	require.NoError(t, err)
+	ts.SetAuditSink(sink)
+
+	start := time.Now()
+	callerCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("alice", "vtctld", ""), nil)
+	require.NoError(t, ts.CreateKeyspace(topo.WithAuditReason(callerCtx, "new keyspace"), "ks", &topodatapb.Keyspace{DurabilityPolicy: "none"}))
+
+	lockCtx, unlock, err := ts.LockKeyspace(ctx, "ks", "SetDurability")
+	require.NoError(t, err)
+	ki, err := ts.GetKeyspace(lockCtx, "ks")
+	require.NoError(t, err)
+	ki.DurabilityPolicy = "semi_sync"
+	require.NoError(t, ts.UpdateKeyspace(lockCtx, ki))
+	unlock(&err)
+	require.NoError(t, err)
+
+	require.NoError(t, ts.UpdateCellInfoFields(ctx, "zone1", func(ci *topodatapb.CellInfo) error {
+		ci.Root = "/zone1"
+		return nil
+	}))
+	require.NoError(t, ts.DeleteKeyspace(ctx, "unaudited"))
+
+	events, err := sink.Events(ctx, &topo.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	assert.Equal(t, topo.GlobalCell, events[0].Cell)
+	assert.Equal(t, "keyspaces/ks/Keyspace", events[0].Path)
+	assert.Equal(t, "Create", events[0].Operation)
+	assert.NotEmpty(t, events[0].Version)
+	assert.Equal(t, "alice@vtctld", events[0].Caller)
+	assert.NotEmpty(t, events[0].HostName)
+	assert.Equal(t, "new keyspace", events[0].Reason)
+	assert.Empty(t, events[0].Before)
+	assert.JSONEq(t, `{"durabilityPolicy": "none"}`, events[0].After)
+	assert.False(t, events[0].Time.Before(start))
+
+	assert.Equal(t, "Update", events[1].Operation)
+	assert.Empty(t, events[1].Caller)
+	assert.Equal(t, "ks: SetDurability", events[1].Reason)
+	assert.JSONEq(t, `{"durabilityPolicy": "none"}`, events[1].Before)
+	assert.JSONEq(t, `{"durabilityPolicy": "semi_sync"}`, events[1].After)
+
+	assert.Equal(t, "cells/zone1/CellInfo", events[2].Path)
+	assert.Equal(t, "Update", events[2].Operation)
+	assert.Empty(t, events[2].Reason)
+
+	assert.Equal(t, "keyspaces/unaudited/Keyspace", events[3].Path)
+	assert.Equal(t, "Delete", events[3].Operation)
+	assert.Empty(t, events[3].Version)
+	assert.JSONEq(t, `{}`, events[3].Before)
+	assert.Empty(t, events[3].After)
+
+	// The events can be filtered.
+	for _, tcase := range []struct {
+		filter   *topo.AuditFilter
+		expected []*topo.AuditEvent
+	}{{
+		filter:   &topo.AuditFilter{Path: "/keyspaces/ks"},
+		expected: events[:2],
+	}, {
+		filter:   &topo.AuditFilter{Path: "keyspaces/k"},
+		expected: nil,
+	}, {
+		filter:   &topo.AuditFilter{Caller: "alice@vtctld"},
+		expected: events[:1],
+	}, {
+		filter:   &topo.AuditFilter{Cells: []string{"zone1"}},
+		expected: nil,
+	}, {
+		filter:   &topo.AuditFilter{Since: time.Now()},
+		expected: nil,
+	}, {
+		filter:   &topo.AuditFilter{Limit: 1},
+		expected: events[3:],
+	}} {
+		got, err := sink.Events(ctx, tcase.filter)
+		require.NoError(t, err)
+		assert.Equal(t, tcase.expected, got, "filter %+v", tcase.filter)
+	}
+
+	// A failed change is not recorded.
+	require.Error(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{}))
+	events, err = sink.Events(ctx, &topo.AuditFilter{})
+	require.NoError(t, err)
+	assert.Len(t, events, 4)
+}
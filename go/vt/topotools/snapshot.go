@@ -0,0 +1,641 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// ExportTopology returns all the files of the given cells, "global" being
+// the global topology. If cells is empty, the global topology and all the
+// cells are exported. Ephemeral files, like locks, are skipped.
+func ExportTopology(ctx context.Context, ts *topo.Server, cells []string) ([]*vtctldatapb.TopologyEntry, error) {
+	if len(cells) == 0 {
+		knownCells, err := ts.GetKnownCells(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cells = append([]string{topo.GlobalCell}, knownCells...)
+	}
+
+	var entries []*vtctldatapb.TopologyEntry
+	for _, cell := range cells {
+		conn, err := ts.ConnForCell(ctx, cell)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot export cell %v", cell)
+		}
+		if err := exportDir(ctx, conn, cell, "/", &entries); err != nil {
+			return nil, vterrors.Wrapf(err, "cannot export cell %v", cell)
+		}
+	}
+	return entries, nil
+}
+
+func exportDir(ctx context.Context, conn topo.Conn, cell, dir string, entries *[]*vtctldatapb.TopologyEntry) error {
+	dirEntries, err := conn.ListDir(ctx, dir, true /*full*/)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		// The directory is empty, or was deleted since it was listed.
+		return nil
+	case err != nil:
+		return err
+	}
+
+	for _, e := range dirEntries {
+		if e.Ephemeral {
+			continue
+		}
+		p := path.Join(dir, e.Name)
+		if e.Type == topo.TypeDirectory {
+			if err := exportDir(ctx, conn, cell, p, entries); err != nil {
+				return err
+			}
+			continue
+		}
+
+		contents, version, err := conn.Get(ctx, p)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			continue
+		case err != nil:
+			return err
+		}
+		*entries = append(*entries, &vtctldatapb.TopologyEntry{
+			Cell:     cell,
+			Path:     p,
+			Contents: contents,
+			Version:  version.String(),
+		})
+	}
+	return nil
+}
+
+// RestoreTopology applies the changes in order, and rebuilds the serving
+// graphs they affect. Each file must be at the expected version of its
+// change, or not exist if the expected version is empty, and no change is
+// applied unless all of them are. The keyspaces and shards of the changed
+// files are locked while the changes are checked and applied.
+//
+// The keyspace graph is rebuilt for the keyspaces whose keyspace or shard
+// records changed, and the VSchema graph if a keyspace VSchema changed, as
+// the serving graph is not derived from those records otherwise.
+func RestoreTopology(ctx context.Context, logger logutil.Logger, ts *topo.Server, changes []*vtctldatapb.TopologyChange) error {
+	if err := restoreTopologyLocked(ctx, ts, changes); err != nil {
+		return err
+	}
+	return rebuildRestoredTopology(ctx, logger, ts, changes)
+}
+
+func restoreTopologyLocked(ctx context.Context, ts *topo.Server, changes []*vtctldatapb.TopologyChange) (err error) {
+	// unlockRestored releases a lock. Unlocking a keyspace or a shard
+	// which the restore deleted fails, which is not an error of the
+	// restore.
+	unlockRestored := func(unlock func(*error)) {
+		origErr := err
+		unlock(&err)
+		if origErr == nil && topo.IsErrType(err, topo.NoNode) {
+			err = nil
+		}
+	}
+
+	keyspaces, shards := restoredKeyspacesAndShards(changes)
+	for _, keyspace := range keyspaces {
+		lockCtx, unlock, lockErr := ts.LockKeyspace(ctx, keyspace, "RestoreTopology")
+		if topo.IsErrType(lockErr, topo.NoNode) {
+			// The keyspace does not exist, it is being restored.
+			continue
+		}
+		if lockErr != nil {
+			return lockErr
+		}
+		ctx = lockCtx
+		defer unlockRestored(unlock)
+	}
+	for _, shard := range shards {
+		lockCtx, unlock, lockErr := ts.LockShard(ctx, shard[0], shard[1], "RestoreTopology")
+		if topo.IsErrType(lockErr, topo.NoNode) {
+			continue
+		}
+		if lockErr != nil {
+			return lockErr
+		}
+		ctx = lockCtx
+		defer unlockRestored(unlock)
+	}
+
+	versions := make([]topo.Version, len(changes))
+	for i, change := range changes {
+		version, err := checkTopologyChange(ctx, ts, change)
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot restore %v in cell %v, no change was applied", change.Path, change.Cell)
+		}
+		versions[i] = version
+	}
+	for i, change := range changes {
+		if err := applyTopologyChange(ctx, ts, change, versions[i]); err != nil {
+			return vterrors.Wrapf(err, "cannot restore %v in cell %v, after applying %d of %d changes", change.Path, change.Cell, i, len(changes))
+		}
+	}
+	return nil
+}
+
+// restoredKeyspacesAndShards returns the sorted keyspaces, and the sorted
+// keyspace and shard pairs, of the files of the changes.
+func restoredKeyspacesAndShards(changes []*vtctldatapb.TopologyChange) (keyspaces []string, shards [][2]string) {
+	keyspaceSet := map[string]bool{}
+	shardSet := map[[2]string]bool{}
+	for _, change := range changes {
+		parts := strings.Split(strings.TrimPrefix(change.Path, "/"), "/")
+		if len(parts) < 2 || parts[0] != topo.KeyspacesPath {
+			continue
+		}
+		keyspaceSet[parts[1]] = true
+		if len(parts) >= 4 && parts[2] == topo.ShardsPath {
+			shardSet[[2]string{parts[1], parts[3]}] = true
+		}
+	}
+	for keyspace := range keyspaceSet {
+		keyspaces = append(keyspaces, keyspace)
+	}
+	sort.Strings(keyspaces)
+	for shard := range shardSet {
+		shards = append(shards, shard)
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		if shards[i][0] != shards[j][0] {
+			return shards[i][0] < shards[j][0]
+		}
+		return shards[i][1] < shards[j][1]
+	})
+	return keyspaces, shards
+}
+
+// checkTopologyChange checks that the file of the change is at its expected
+// version, and returns that version, or nil if the file does not exist.
+func checkTopologyChange(ctx context.Context, ts *topo.Server, change *vtctldatapb.TopologyChange) (topo.Version, error) {
+	conn, err := ts.ConnForCell(ctx, change.Cell)
+	if err != nil {
+		return nil, err
+	}
+
+	_, version, err := conn.Get(ctx, change.Path)
+	switch {
+	case topo.IsErrType(err, topo.NoNode):
+		if change.ExpectedVersion != "" {
+			return nil, topo.NewError(topo.BadVersion, fmt.Sprintf("%v does not exist anymore, expected version %v", change.Path, change.ExpectedVersion))
+		}
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if change.ExpectedVersion == "" {
+		return nil, topo.NewError(topo.NodeExists, fmt.Sprintf("%v was created, at version %v", change.Path, version))
+	}
+	if change.ExpectedVersion != version.String() {
+		return nil, topo.NewError(topo.BadVersion, fmt.Sprintf("%v is at version %v, expected version %q", change.Path, version, change.ExpectedVersion))
+	}
+	return version, nil
+}
+
+// applyTopologyChange applies the change to its file, which was checked to
+// be at the given version. The write is done with that version, so it
+// fails if the file changed since it was checked.
+func applyTopologyChange(ctx context.Context, ts *topo.Server, change *vtctldatapb.TopologyChange, version topo.Version) error {
+	conn, err := ts.ConnForCell(ctx, change.Cell)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version == nil && change.Delete:
+		return nil
+	case version == nil:
+		_, err = conn.Create(ctx, change.Path, change.Contents)
+		return err
+	case change.Delete:
+		return conn.Delete(ctx, change.Path, version)
+	}
+	_, err = conn.Update(ctx, change.Path, change.Contents, version)
+	return err
+}
+
+// rebuildRestoredTopology rebuilds the keyspace graph of the keyspaces whose
+// global keyspace or shard records were restored, unless the keyspace was
+// deleted, and the VSchema graph if a keyspace VSchema was restored.
+func rebuildRestoredTopology(ctx context.Context, logger logutil.Logger, ts *topo.Server, changes []*vtctldatapb.TopologyChange) error {
+	var keyspaces []string
+	rebuildKeyspaces := map[string]bool{}
+	rebuildVSchema := false
+	for _, change := range changes {
+		parts := strings.Split(strings.TrimPrefix(change.Path, "/"), "/")
+		if change.Cell != topo.GlobalCell || len(parts) < 3 || parts[0] != topo.KeyspacesPath {
+			continue
+		}
+		keyspace := parts[1]
+		switch {
+		case len(parts) == 3 && parts[2] == topo.VSchemaFile:
+			rebuildVSchema = true
+		case len(parts) == 3 && parts[2] == topo.KeyspaceFile && change.Delete:
+			rebuildKeyspaces[keyspace] = false
+		case len(parts) == 3 && parts[2] == topo.KeyspaceFile, len(parts) == 5 && parts[2] == topo.ShardsPath && parts[4] == topo.ShardFile:
+			if _, ok := rebuildKeyspaces[keyspace]; !ok {
+				keyspaces = append(keyspaces, keyspace)
+				rebuildKeyspaces[keyspace] = true
+			}
+		}
+	}
+
+	sort.Strings(keyspaces)
+	for _, keyspace := range keyspaces {
+		if !rebuildKeyspaces[keyspace] {
+			continue
+		}
+		if err := RebuildKeyspace(ctx, logger, ts, keyspace, nil, false); err != nil {
+			return vterrors.Wrapf(err, "restored the topology, but cannot rebuild the keyspace graph of %v, run RebuildKeyspaceGraph", keyspace)
+		}
+	}
+	if rebuildVSchema {
+		if err := ts.RebuildSrvVSchema(ctx, nil); err != nil {
+			return vterrors.Wrapf(err, "restored the topology, but cannot rebuild the VSchema graph, run RebuildVSchemaGraph")
+		}
+	}
+	return nil
+}
+
+// TopologySnapshot is a point-in-time copy of the topology, as stored in a
+// snapshot archive.
+type TopologySnapshot struct {
+	// Time is when the snapshot was taken.
+	Time time.Time `json:"time"`
+	// Entries are sorted by cell and path.
+	Entries []*TopologySnapshotEntry `json:"entries"`
+}
+
+// TopologySnapshotEntry is a file of a TopologySnapshot. The contents of the
+// files whose proto type is known are decoded as JSON in Value, so they can
+// be read and edited. The other files are kept as is in Contents.
+type TopologySnapshotEntry struct {
+	Cell     string          `json:"cell"`
+	Path     string          `json:"path"`
+	Version  string          `json:"version,omitempty"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Contents []byte          `json:"contents,omitempty"`
+}
+
+// NewTopologySnapshot decodes the exported topology entries into a
+// snapshot.
+func NewTopologySnapshot(entries []*vtctldatapb.TopologyEntry) *TopologySnapshot {
+	snapshot := &TopologySnapshot{
+		Time:    time.Now().UTC(),
+		Entries: make([]*TopologySnapshotEntry, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		e := &TopologySnapshotEntry{
+			Cell:    entry.Cell,
+			Path:    entry.Path,
+			Version: entry.Version,
+		}
+		if value, err := topo.DecodeContent(entry.Path, entry.Contents, true /*json*/); err == nil {
+			e.Value = json.RawMessage(value)
+		} else {
+			e.Contents = entry.Contents
+		}
+		snapshot.Entries = append(snapshot.Entries, e)
+	}
+	snapshot.sort()
+	return snapshot
+}
+
+// ReadTopologySnapshot reads a snapshot written by TopologySnapshot.Write.
+func ReadTopologySnapshot(r io.Reader) (*TopologySnapshot, error) {
+	snapshot := &TopologySnapshot{}
+	if err := json.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot read topology snapshot")
+	}
+	snapshot.sort()
+	return snapshot, nil
+}
+
+// Write writes the snapshot as indented JSON.
+func (s *TopologySnapshot) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Cells returns the cells of the snapshot entries.
+func (s *TopologySnapshot) Cells() []string {
+	var cells []string
+	for _, e := range s.Entries {
+		if len(cells) == 0 || cells[len(cells)-1] != e.Cell {
+			cells = append(cells, e.Cell)
+		}
+	}
+	return cells
+}
+
+func (s *TopologySnapshot) sort() {
+	sort.Slice(s.Entries, func(i, j int) bool {
+		if s.Entries[i].Cell != s.Entries[j].Cell {
+			return s.Entries[i].Cell < s.Entries[j].Cell
+		}
+		return s.Entries[i].Path < s.Entries[j].Path
+	})
+}
+
+// RawContents returns the contents of the file in the topology, encoding
+// the decoded value back to a proto if needed.
+func (e *TopologySnapshotEntry) RawContents() ([]byte, error) {
+	if e.Value == nil {
+		return e.Contents, nil
+	}
+	return topo.EncodeContent(e.Path, e.Value)
+}
+
+// TopologyDiffType is the type of a difference between two snapshots.
+type TopologyDiffType string
+
+// The types of differences between two snapshots.
+const (
+	TopologyDiffAdded   = TopologyDiffType("added")
+	TopologyDiffRemoved = TopologyDiffType("removed")
+	TopologyDiffChanged = TopologyDiffType("changed")
+)
+
+// TopologyDiff is a file which differs between two snapshots.
+type TopologyDiff struct {
+	Cell string
+	Path string
+	Type TopologyDiffType
+	// From is nil for added files.
+	From *TopologySnapshotEntry
+	// To is nil for removed files.
+	To *TopologySnapshotEntry
+	// Changes describe the changed fields of a changed file, when it was
+	// decoded in both snapshots.
+	Changes []string
+}
+
+// String returns the diff as text, one line per changed field.
+func (d *TopologyDiff) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%v %v:%v", d.Type, d.Cell, d.Path)
+	for _, change := range d.Changes {
+		fmt.Fprintf(&buf, "\n  %v", change)
+	}
+	return buf.String()
+}
+
+// DiffTopologySnapshots returns the files which differ from one snapshot to
+// the other, sorted by cell and path. The files are compared on their
+// decoded values, so an encoding difference or a version change alone is
+// not a difference.
+func DiffTopologySnapshots(from, to *TopologySnapshot) ([]*TopologyDiff, error) {
+	type key struct {
+		cell string
+		path string
+	}
+	fromEntries := make(map[key]*TopologySnapshotEntry, len(from.Entries))
+	for _, e := range from.Entries {
+		fromEntries[key{e.Cell, e.Path}] = e
+	}
+
+	var diffs []*TopologyDiff
+	for _, toEntry := range to.Entries {
+		k := key{toEntry.Cell, toEntry.Path}
+		fromEntry, ok := fromEntries[k]
+		delete(fromEntries, k)
+		if !ok {
+			diffs = append(diffs, &TopologyDiff{
+				Cell: toEntry.Cell,
+				Path: toEntry.Path,
+				Type: TopologyDiffAdded,
+				To:   toEntry,
+			})
+			continue
+		}
+		diff, err := diffTopologySnapshotEntries(fromEntry, toEntry)
+		if err != nil {
+			return nil, err
+		}
+		if diff != nil {
+			diffs = append(diffs, diff)
+		}
+	}
+	for _, fromEntry := range fromEntries {
+		diffs = append(diffs, &TopologyDiff{
+			Cell: fromEntry.Cell,
+			Path: fromEntry.Path,
+			Type: TopologyDiffRemoved,
+			From: fromEntry,
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Cell != diffs[j].Cell {
+			return diffs[i].Cell < diffs[j].Cell
+		}
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs, nil
+}
+
+func diffTopologySnapshotEntries(from, to *TopologySnapshotEntry) (*TopologyDiff, error) {
+	diff := &TopologyDiff{
+		Cell: to.Cell,
+		Path: to.Path,
+		Type: TopologyDiffChanged,
+		From: from,
+		To:   to,
+	}
+
+	if from.Value == nil || to.Value == nil {
+		fromContents, err := from.RawContents()
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot encode %v:%v", from.Cell, from.Path)
+		}
+		toContents, err := to.RawContents()
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot encode %v:%v", to.Cell, to.Path)
+		}
+		if bytes.Equal(fromContents, toContents) {
+			return nil, nil
+		}
+		return diff, nil
+	}
+
+	var fromValue, toValue any
+	if err := json.Unmarshal(from.Value, &fromValue); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot decode %v:%v", from.Cell, from.Path)
+	}
+	if err := json.Unmarshal(to.Value, &toValue); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot decode %v:%v", to.Cell, to.Path)
+	}
+	diffValues("", fromValue, toValue, &diff.Changes)
+	if len(diff.Changes) == 0 {
+		return nil, nil
+	}
+	return diff, nil
+}
+
+// diffValues appends a description of the differences between two decoded
+// JSON values to changes.
+func diffValues(field string, from, to any, changes *[]string) {
+	fromMap, fromIsMap := from.(map[string]any)
+	toMap, toIsMap := to.(map[string]any)
+	if fromIsMap && toIsMap {
+		keys := make([]string, 0, len(fromMap)+len(toMap))
+		for k := range fromMap {
+			keys = append(keys, k)
+		}
+		for k := range toMap {
+			if _, ok := fromMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			diffValues(field+"."+k, fromMap[k], toMap[k], changes)
+		}
+		return
+	}
+
+	fromList, fromIsList := from.([]any)
+	toList, toIsList := to.([]any)
+	if fromIsList && toIsList {
+		for i := 0; i < len(fromList) || i < len(toList); i++ {
+			var fromItem, toItem any
+			if i < len(fromList) {
+				fromItem = fromList[i]
+			}
+			if i < len(toList) {
+				toItem = toList[i]
+			}
+			diffValues(fmt.Sprintf("%v[%d]", field, i), fromItem, toItem, changes)
+		}
+		return
+	}
+
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+	if field == "" {
+		field = "."
+	}
+	*changes = append(*changes, fmt.Sprintf("%v: %v -> %v", field, describeValue(from), describeValue(to)))
+}
+
+func describeValue(value any) string {
+	if value == nil {
+		return "<unset>"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// FilterTopologyDiffs returns the diffs in the given cells, and under the
+// given paths. Empty cells or paths match all the diffs.
+func FilterTopologyDiffs(diffs []*TopologyDiff, cells []string, paths []string) []*TopologyDiff {
+	var result []*TopologyDiff
+	for _, diff := range diffs {
+		if len(cells) > 0 && !containsString(cells, diff.Cell) {
+			continue
+		}
+		if len(paths) > 0 && !matchesAnyPath(paths, diff.Path) {
+			continue
+		}
+		result = append(result, diff)
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyPath(paths []string, p string) bool {
+	for _, prefix := range paths {
+		prefix = path.Join("/", prefix)
+		if p == prefix || prefix == "/" || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// TopologyRestoreChanges returns the changes restoring the files of the
+// diffs to their state in the To snapshot, with the From snapshot being the
+// live topology. Files missing from the To snapshot are only deleted if
+// deleteRemoved is set.
+func TopologyRestoreChanges(diffs []*TopologyDiff, deleteRemoved bool) ([]*vtctldatapb.TopologyChange, error) {
+	var changes []*vtctldatapb.TopologyChange
+	for _, diff := range diffs {
+		change := &vtctldatapb.TopologyChange{
+			Cell: diff.Cell,
+			Path: diff.Path,
+		}
+		switch diff.Type {
+		case TopologyDiffRemoved:
+			if !deleteRemoved {
+				continue
+			}
+			change.ExpectedVersion = diff.From.Version
+			change.Delete = true
+		case TopologyDiffAdded, TopologyDiffChanged:
+			contents, err := diff.To.RawContents()
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "cannot encode %v:%v", diff.Cell, diff.Path)
+			}
+			change.Contents = contents
+			if diff.From != nil {
+				change.ExpectedVersion = diff.From.Version
+			}
+		default:
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unknown topology diff type %v", diff.Type)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotools
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func exportSnapshot(ctx context.Context, t *testing.T, ts *topo.Server, cells ...string) *TopologySnapshot {
+	entries, err := ExportTopology(ctx, ts, cells)
+	require.NoError(t, err)
+	return NewTopologySnapshot(entries)
+}
+
+func TestTopologySnapshot(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{DurabilityPolicy: "none"}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "0"))
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{Sharded: false}))
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, "/metadata/unknown", []byte("raw"))
+	require.NoError(t, err)
+
+	// Locked directories are exported without their locks.
+	lockCtx, unlock, err := ts.LockKeyspace(ctx, "ks", "test")
+	require.NoError(t, err)
+	snapshot := exportSnapshot(ctx, t, ts)
+	unlock(&err)
+	require.NoError(t, lockCtx.Err())
+
+	var paths []string
+	for _, e := range snapshot.Entries {
+		paths = append(paths, e.Cell+":"+e.Path)
+	}
+	assert.Equal(t, []string{
+		"global:/cells/zone1/CellInfo",
+		"global:/keyspaces/ks/Keyspace",
+		"global:/keyspaces/ks/VSchema",
+		"global:/keyspaces/ks/shards/0/Shard",
+		"global:/metadata/unknown",
+	}, paths)
+	assert.Equal(t, []string{"global"}, snapshot.Cells())
+
+	// Known files are decoded, the others are kept raw.
+	assert.JSONEq(t, `{"durabilityPolicy": "none"}`, string(snapshot.Entries[1].Value))
+	assert.Nil(t, snapshot.Entries[4].Value)
+	assert.Equal(t, []byte("raw"), snapshot.Entries[4].Contents)
+
+	// The snapshot survives a round trip through an archive.
+	var buf bytes.Buffer
+	require.NoError(t, snapshot.Write(&buf))
+	read, err := ReadTopologySnapshot(&buf)
+	require.NoError(t, err)
+	diffs, err := DiffTopologySnapshots(snapshot, read)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+	for _, e := range read.Entries {
+		contents, err := e.RawContents()
+		require.NoError(t, err)
+		live, _, err := conn.Get(ctx, e.Path)
+		require.NoError(t, err)
+		assert.Equal(t, live, contents, e.Path)
+	}
+
+	// Rewriting a file with the same value is not a difference.
+	contents, version, err := conn.Get(ctx, "/keyspaces/ks/Keyspace")
+	require.NoError(t, err)
+	_, err = conn.Update(ctx, "/keyspaces/ks/Keyspace", contents, version)
+	require.NoError(t, err)
+	diffs, err = DiffTopologySnapshots(snapshot, exportSnapshot(ctx, t, ts))
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestDiffTopologySnapshots(t *testing.T) {
+	from := &TopologySnapshot{
+		Entries: []*TopologySnapshotEntry{
+			{Cell: "global", Path: "/keyspaces/ks/Keyspace", Version: "1", Value: []byte(`{"durabilityPolicy": "none"}`)},
+			{Cell: "global", Path: "/keyspaces/ks/VSchema", Version: "2", Value: []byte(`{"tables": {"t1": {}, "t2": {"columnVindexes": [{"column": "id"}]}}}`)},
+			{Cell: "global", Path: "/metadata/a", Version: "3", Contents: []byte("a")},
+			{Cell: "zone1", Path: "/metadata/b", Version: "4", Contents: []byte("b")},
+		},
+	}
+	to := &TopologySnapshot{
+		Entries: []*TopologySnapshotEntry{
+			{Cell: "global", Path: "/keyspaces/ks/Keyspace", Version: "5", Value: []byte(`{"durabilityPolicy": "semi_sync"}`)},
+			{Cell: "global", Path: "/keyspaces/ks/VSchema", Version: "2", Value: []byte(`{"tables": {"t2": {"columnVindexes": [{"column": "id", "name": "hash"}]}, "t3": {}}}`)},
+			{Cell: "global", Path: "/metadata/a", Version: "6", Contents: []byte("a")},
+			{Cell: "zone1", Path: "/metadata/c", Version: "7", Contents: []byte("c")},
+		},
+	}
+
+	diffs, err := DiffTopologySnapshots(from, to)
+	require.NoError(t, err)
+	var got []string
+	for _, diff := range diffs {
+		got = append(got, diff.String())
+	}
+	assert.Equal(t, []string{
+		"changed global:/keyspaces/ks/Keyspace\n  .durabilityPolicy: \"none\" -> \"semi_sync\"",
+		"changed global:/keyspaces/ks/VSchema\n  .tables.t1: {} -> <unset>\n  .tables.t2.columnVindexes[0].name: <unset> -> \"hash\"\n  .tables.t3: <unset> -> {}",
+		"removed zone1:/metadata/b",
+		"added zone1:/metadata/c",
+	}, got)
+
+	assert.Len(t, FilterTopologyDiffs(diffs, []string{"zone1"}, nil), 2)
+	assert.Len(t, FilterTopologyDiffs(diffs, nil, []string{"/keyspaces/ks"}), 2)
+	assert.Len(t, FilterTopologyDiffs(diffs, nil, []string{"keyspaces/ks/VSchema"}), 1)
+	assert.Len(t, FilterTopologyDiffs(diffs, nil, []string{"/keyspaces/k"}), 0)
+	assert.Len(t, FilterTopologyDiffs(diffs, []string{"global"}, []string{"/"}), 2)
+}
+
+func TestRestoreTopology(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	require.NoError(t, ts.CreateKeyspace(ctx, "ks", &topodatapb.Keyspace{DurabilityPolicy: "none"}))
+	require.NoError(t, ts.CreateShard(ctx, "ks", "0"))
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{Sharded: true}))
+	snapshot := exportSnapshot(ctx, t, ts, topo.GlobalCell)
+
+	// Break the topology: a bad vschema, a deleted shard and a new
+	// keyspace.
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{Sharded: false}))
+	require.NoError(t, ts.DeleteShard(ctx, "ks", "0"))
+	require.NoError(t, ts.CreateKeyspace(ctx, "other", &topodatapb.Keyspace{}))
+
+	restore := func(deleteRemoved bool, paths ...string) error {
+		diffs, err := DiffTopologySnapshots(exportSnapshot(ctx, t, ts, topo.GlobalCell), snapshot)
+		require.NoError(t, err)
+		changes, err := TopologyRestoreChanges(FilterTopologyDiffs(diffs, nil, paths), deleteRemoved)
+		require.NoError(t, err)
+		return RestoreTopology(ctx, logutil.NewMemoryLogger(), ts, changes)
+	}
+
+	// Only the selected paths are restored, and the VSchema graph is
+	// rebuilt.
+	require.NoError(t, restore(false, "/keyspaces/ks/VSchema"))
+	vschema, err := ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	assert.True(t, vschema.Sharded)
+	srvVSchema, err := ts.GetSrvVSchema(ctx, "zone1")
+	require.NoError(t, err)
+	assert.True(t, srvVSchema.Keyspaces["ks"].Sharded)
+	_, err = ts.GetShard(ctx, "ks", "0")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "GetShard: %v", err)
+
+	// Added files are only deleted if asked to.
+	require.NoError(t, restore(false, "/"))
+	_, err = ts.GetShard(ctx, "ks", "0")
+	require.NoError(t, err)
+	srvKeyspace, err := ts.GetSrvKeyspace(ctx, "zone1", "ks")
+	require.NoError(t, err)
+	assert.NotEmpty(t, srvKeyspace.Partitions)
+	_, err = ts.GetKeyspace(ctx, "other")
+	require.NoError(t, err)
+	require.NoError(t, restore(true, "/"))
+	_, err = ts.GetKeyspace(ctx, "other")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "GetKeyspace: %v", err)
+
+	diffs, err := DiffTopologySnapshots(exportSnapshot(ctx, t, ts, topo.GlobalCell), snapshot)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	// Files which changed since the diff are not overwritten.
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{Sharded: false}))
+	diffs, err = DiffTopologySnapshots(exportSnapshot(ctx, t, ts, topo.GlobalCell), snapshot)
+	require.NoError(t, err)
+	changes, err := TopologyRestoreChanges(diffs, false)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.NoError(t, ts.SaveVSchema(ctx, "ks", &vschemapb.Keyspace{Sharded: false, RequireExplicitRouting: true}))
+	err = RestoreTopology(ctx, logutil.NewMemoryLogger(), ts, changes)
+	assert.True(t, topo.IsErrType(vterrors.RootCause(err), topo.BadVersion), "RestoreTopology: %v", err)
+	vschema, err = ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	assert.True(t, vschema.RequireExplicitRouting)
+
+	// Files which were created since the diff are not overwritten either.
+	changes[0].Path = "/keyspaces/ks/shards/0/Shard"
+	changes[0].ExpectedVersion = ""
+	err = RestoreTopology(ctx, logutil.NewMemoryLogger(), ts, changes)
+	assert.True(t, topo.IsErrType(vterrors.RootCause(err), topo.NodeExists), "RestoreTopology: %v", err)
+
+	// No change is applied if any file is not at its expected version.
+	diffs, err = DiffTopologySnapshots(exportSnapshot(ctx, t, ts, topo.GlobalCell), snapshot)
+	require.NoError(t, err)
+	changes, err = TopologyRestoreChanges(diffs, false)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	changes = append(changes, &vtctldatapb.TopologyChange{
+		Cell:            topo.GlobalCell,
+		Path:            "/keyspaces/ks/Keyspace",
+		Contents:        []byte{},
+		ExpectedVersion: "12345",
+	})
+	err = RestoreTopology(ctx, logutil.NewMemoryLogger(), ts, changes)
+	assert.True(t, topo.IsErrType(vterrors.RootCause(err), topo.BadVersion), "RestoreTopology: %v", err)
+	vschema, err = ts.GetVSchema(ctx, "ks")
+	require.NoError(t, err)
+	assert.True(t, vschema.RequireExplicitRouting)
+}
@@ -43,6 +43,71 @@ import (
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
+func TestConcludeTransaction(t *testing.T) {
+	t.Parallel()
+
+	opts := vtadmin.Options{
+		RBAC: &rbac.Config{
+			Rules: []*struct {
+				Resource string
+				Actions  []string
+				Subjects []string
+				Clusters []string
+			}{
+				{
+					Resource: "Transaction",
+					Actions:  []string{"put"},
+					Subjects: []string{"user:allowed"},
+					Clusters: []string{"*"},
+				},
+			},
+		},
+	}
+	err := opts.RBAC.Reify()
+	require.NoError(t, err, "failed to reify authorization rules: %+v", opts.RBAC.Rules)
+
+	api := vtadmin.NewAPI(testClusters(t), opts)
+	t.Cleanup(func() {
+		if err := api.Close(); err != nil {
+			t.Logf("api did not close cleanly: %s", err.Error())
+		}
+	})
+
+	t.Run("unauthorized actor", func(t *testing.T) {
+		t.Parallel()
+
+		actor := &rbac.Actor{Name: "other"}
+		ctx := context.Background()
+		if actor != nil {
+			ctx = rbac.NewContext(ctx, actor)
+		}
+
+		resp, err := api.ConcludeTransaction(ctx, &vtadminpb.ConcludeTransactionRequest{
+			ClusterId: "test",
+			Dtid:      "test:0:1",
+		})
+		require.NoError(t, err)
+		assert.Nil(t, resp, "actor %+v should not be permitted to ConcludeTransaction", actor)
+	})
+
+	t.Run("authorized actor", func(t *testing.T) {
+		t.Parallel()
+
+		actor := &rbac.Actor{Name: "allowed"}
+		ctx := context.Background()
+		if actor != nil {
+			ctx = rbac.NewContext(ctx, actor)
+		}
+
+		resp, err := api.ConcludeTransaction(ctx, &vtadminpb.ConcludeTransactionRequest{
+			ClusterId: "test",
+			Dtid:      "test:0:1",
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, resp, "actor %+v should be permitted to ConcludeTransaction", actor)
+	})
+}
+
 func TestCreateKeyspace(t *testing.T) {
 	t.Parallel()
 
@@ -1733,6 +1798,71 @@ func TestGetTablets(t *testing.T) {
 	})
 }
 
+func TestGetUnresolvedTransactions(t *testing.T) {
+	t.Parallel()
+
+	opts := vtadmin.Options{
+		RBAC: &rbac.Config{
+			Rules: []*struct {
+				Resource string
+				Actions  []string
+				Subjects []string
+				Clusters []string
+			}{
+				{
+					Resource: "Transaction",
+					Actions:  []string{"get"},
+					Subjects: []string{"user:allowed"},
+					Clusters: []string{"*"},
+				},
+			},
+		},
+	}
+	err := opts.RBAC.Reify()
+	require.NoError(t, err, "failed to reify authorization rules: %+v", opts.RBAC.Rules)
+
+	api := vtadmin.NewAPI(testClusters(t), opts)
+	t.Cleanup(func() {
+		if err := api.Close(); err != nil {
+			t.Logf("api did not close cleanly: %s", err.Error())
+		}
+	})
+
+	t.Run("unauthorized actor", func(t *testing.T) {
+		t.Parallel()
+
+		actor := &rbac.Actor{Name: "other"}
+		ctx := context.Background()
+		if actor != nil {
+			ctx = rbac.NewContext(ctx, actor)
+		}
+
+		resp, err := api.GetUnresolvedTransactions(ctx, &vtadminpb.GetUnresolvedTransactionsRequest{
+			ClusterId: "test",
+			Keyspace:  "test",
+		})
+		require.NoError(t, err)
+		assert.Nil(t, resp, "actor %+v should not be permitted to GetUnresolvedTransactions", actor)
+	})
+
+	t.Run("authorized actor", func(t *testing.T) {
+		t.Parallel()
+
+		actor := &rbac.Actor{Name: "allowed"}
+		ctx := context.Background()
+		if actor != nil {
+			ctx = rbac.NewContext(ctx, actor)
+		}
+
+		resp, err := api.GetUnresolvedTransactions(ctx, &vtadminpb.GetUnresolvedTransactionsRequest{
+			ClusterId: "test",
+			Keyspace:  "test",
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, resp, "actor %+v should be permitted to GetUnresolvedTransactions", actor)
+	})
+}
+
 func TestGetVSchema(t *testing.T) {
 	t.Parallel()
 
@@ -3184,6 +3314,14 @@ func testClusters(t testing.TB) []*cluster.Cluster {
 				Name: "test",
 			},
 			VtctldClient: &fakevtctldclient.VtctldClient{
+				ConcludeTransactionResults: map[string]struct {
+					Response *vtctldatapb.ConcludeTransactionResponse
+					Error    error
+				}{
+					"test:0:1": {
+						Response: &vtctldatapb.ConcludeTransactionResponse{},
+					},
+				},
 				DeleteShardsResults: map[string]error{
 					"test/-": nil,
 				},
@@ -3341,6 +3479,14 @@ func testClusters(t testing.TB) []*cluster.Cluster {
 							},
 						}},
 				},
+				ListUnresolvedTransactionsResults: map[string]struct {
+					Response *vtctldatapb.ListUnresolvedTransactionsResponse
+					Error    error
+				}{
+					"test": {
+						Response: &vtctldatapb.ListUnresolvedTransactionsResponse{},
+					},
+				},
 				PingTabletResults: map[string]error{
 					"zone1-0000000100": nil,
 				},
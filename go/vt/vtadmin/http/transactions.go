@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/vt/vtadmin/errors"
+
+	vtadminpb "vitess.io/vitess/go/vt/proto/vtadmin"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// ConcludeTransaction implements the http wrapper for
+// PUT /transaction/{cluster_id}/{dtid}/conclude.
+//
+// The body is a JSON object with an optional "action" of "resolve" (the
+// default), "commit" or "rollback".
+func ConcludeTransaction(ctx context.Context, r Request, api *API) *JSONResponse {
+	vars := r.Vars()
+	decoder := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+
+	var params struct {
+		Action string `json:"action"`
+	}
+
+	if err := decoder.Decode(&params); err != nil {
+		return NewJSONResponse(nil, &errors.BadRequest{
+			Err: err,
+		})
+	}
+
+	action := vtctldatapb.ConcludeTransactionRequest_RESOLVE
+	if params.Action != "" {
+		value, ok := vtctldatapb.ConcludeTransactionRequest_Action_value[strings.ToUpper(params.Action)]
+		if !ok {
+			return NewJSONResponse(nil, &errors.BadRequest{
+				Err: fmt.Errorf("invalid action %q", params.Action),
+			})
+		}
+
+		action = vtctldatapb.ConcludeTransactionRequest_Action(value)
+	}
+
+	res, err := api.server.ConcludeTransaction(ctx, &vtadminpb.ConcludeTransactionRequest{
+		ClusterId: vars["cluster_id"],
+		Dtid:      vars["dtid"],
+		Action:    action,
+	})
+
+	return NewJSONResponse(res, err)
+}
+
+// GetUnresolvedTransactions implements the http wrapper for
+// /keyspace/{cluster_id}/{name}/transactions[?abandon_age=].
+func GetUnresolvedTransactions(ctx context.Context, r Request, api *API) *JSONResponse {
+	vars := r.Vars()
+
+	abandonAge, err := r.ParseQueryParamAsUint32("abandon_age", 0)
+	if err != nil {
+		return NewJSONResponse(nil, err)
+	}
+
+	res, err := api.server.GetUnresolvedTransactions(ctx, &vtadminpb.GetUnresolvedTransactionsRequest{
+		ClusterId:  vars["cluster_id"],
+		Keyspace:   vars["name"],
+		AbandonAge: int64(abandonAge),
+	})
+
+	return NewJSONResponse(res, err)
+}
@@ -113,6 +113,7 @@ const (
 	BackupResource                   Resource = "Backup"
 	SchemaResource                   Resource = "Schema"
 	ShardReplicationPositionResource Resource = "ShardReplicationPosition"
+	TransactionResource              Resource = "Transaction"
 	WorkflowResource                 Resource = "Workflow"
 
 	VTExplainResource Resource = "VTExplain"
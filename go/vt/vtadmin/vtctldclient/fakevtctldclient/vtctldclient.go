@@ -38,6 +38,11 @@ import (
 type VtctldClient struct {
 	vtctldclient.VtctldClient
 
+	// Keyed by dtid.
+	ConcludeTransactionResults map[string]struct {
+		Response *vtctldatapb.ConcludeTransactionResponse
+		Error    error
+	}
 	CreateKeyspaceShouldErr bool
 	CreateShardShouldErr    bool
 	DeleteKeyspaceShouldErr bool
@@ -93,6 +98,11 @@ type VtctldClient struct {
 		Response *vtctldatapb.GetWorkflowsResponse
 		Error    error
 	}
+	// Keyed by keyspace.
+	ListUnresolvedTransactionsResults map[string]struct {
+		Response *vtctldatapb.ListUnresolvedTransactionsResponse
+		Error    error
+	}
 	PingTabletResults           map[string]error
 	PlannedReparentShardResults map[string]struct {
 		Response *vtctldatapb.PlannedReparentShardResponse
@@ -148,6 +158,20 @@ var _ vtctldclient.VtctldClient = (*VtctldClient)(nil)
 // Close is part of the vtctldclient.VtctldClient interface.
 func (fake *VtctldClient) Close() error { return nil }
 
+// ConcludeTransaction is part of the vtctldclient.VtctldClient interface.
+func (fake *VtctldClient) ConcludeTransaction(ctx context.Context, req *vtctldatapb.ConcludeTransactionRequest, opts ...grpc.CallOption) (*vtctldatapb.ConcludeTransactionResponse, error) {
+	if fake.ConcludeTransactionResults == nil {
+		return nil, fmt.Errorf("%w: ConcludeTransactionResults not set on fake vtctldclient", assert.AnError)
+	}
+
+	key := req.Dtid
+	if result, ok := fake.ConcludeTransactionResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no result set for %s", assert.AnError, key)
+}
+
 // CreateKeyspace is part of the vtctldclient.VtctldClient interface.
 func (fake *VtctldClient) CreateKeyspace(ctx context.Context, req *vtctldatapb.CreateKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.CreateKeyspaceResponse, error) {
 	if fake.CreateKeyspaceShouldErr {
@@ -425,6 +449,20 @@ func (fake *VtctldClient) GetWorkflows(ctx context.Context, req *vtctldatapb.Get
 	return nil, fmt.Errorf("%w: no result set for keyspace %s", assert.AnError, req.Keyspace)
 }
 
+// ListUnresolvedTransactions is part of the vtctldclient.VtctldClient interface.
+func (fake *VtctldClient) ListUnresolvedTransactions(ctx context.Context, req *vtctldatapb.ListUnresolvedTransactionsRequest, opts ...grpc.CallOption) (*vtctldatapb.ListUnresolvedTransactionsResponse, error) {
+	if fake.ListUnresolvedTransactionsResults == nil {
+		return nil, fmt.Errorf("%w: ListUnresolvedTransactionsResults not set on fake vtctldclient", assert.AnError)
+	}
+
+	key := req.Keyspace
+	if result, ok := fake.ListUnresolvedTransactionsResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no result set for %s", assert.AnError, key)
+}
+
 // PingTablet is part of the vtctldclient.VtctldClient interface.
 func (fake *VtctldClient) PingTablet(ctx context.Context, req *vtctldatapb.PingTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.PingTabletResponse, error) {
 	if fake.PingTabletResults == nil {
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// LoadCheckpoint reads the VGtid saved by SaveCheckpoint. It returns nil
+// if the file doesn't exist, i.e. the stream never checkpointed.
+func LoadCheckpoint(path string) (*binlogdatapb.VGtid, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "cannot read checkpoint %v", path)
+	}
+	vgtid := &binlogdatapb.VGtid{}
+	if err := protojson.Unmarshal(data, vgtid); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot parse checkpoint %v", path)
+	}
+	return vgtid, nil
+}
+
+// SaveCheckpoint atomically replaces the checkpoint file with the given
+// VGtid: the new contents are written and synced to a temporary file
+// which is then renamed over the checkpoint, so a crash leaves either the
+// previous or the new checkpoint, never a truncated one.
+func SaveCheckpoint(path string, vgtid *binlogdatapb.VGtid) error {
+	data, err := protojson.MarshalOptions{Multiline: true}.Marshal(vgtid)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot marshal checkpoint")
+	}
+
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, base+".tmp*")
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot create temporary checkpoint in %v", dir)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return vterrors.Wrapf(err, "cannot write checkpoint %v", tmp.Name())
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return vterrors.Wrapf(err, "cannot sync checkpoint %v", tmp.Name())
+	}
+	if err := tmp.Close(); err != nil {
+		return vterrors.Wrapf(err, "cannot close checkpoint %v", tmp.Name())
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return vterrors.Wrapf(err, "cannot rename checkpoint to %v", path)
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes a rename in the directory durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot open directory %v", dir)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return vterrors.Wrapf(err, "cannot sync directory %v", dir)
+	}
+	return nil
+}
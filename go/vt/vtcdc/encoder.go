@@ -0,0 +1,247 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// FormatJSON writes every VEvent as a JSON object.
+	FormatJSON = "json"
+
+	// FormatDebezium writes every row change as the payload of a Debezium
+	// change event, like the Debezium Vitess connector does with the
+	// schemas disabled. The other events are not written.
+	FormatDebezium = "debezium"
+)
+
+// encoder turns the VEvents into lines of the output.
+type encoder struct {
+	format string
+
+	// name is the logical name of the stream in the Debezium source.
+	name string
+
+	// fields are the fields of the tables, by qualified table name, as
+	// sent by the FIELD events.
+	fields map[string][]*querypb.Field
+
+	// copying has the keyspace/shard of the shards which are being
+	// copied: their inserts are reported as snapshot reads.
+	copying map[string]bool
+
+	// vgtid is the JSON of the last VGtid, in the format of the Debezium
+	// source.
+	vgtid string
+
+	// now is time.Now, except in tests.
+	now func() time.Time
+}
+
+func newEncoder(format, name string) (*encoder, error) {
+	switch format {
+	case FormatJSON, FormatDebezium:
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown output format %v, expected %v or %v", format, FormatJSON, FormatDebezium)
+	}
+	return &encoder{
+		format:  format,
+		name:    name,
+		fields:  make(map[string][]*querypb.Field),
+		copying: make(map[string]bool),
+		now:     time.Now,
+	}, nil
+}
+
+// start resets the state of the encoder for a stream starting at the
+// given position. The shards without a position, or with tables left to
+// copy, are copied first.
+func (e *encoder) start(vgtid *binlogdatapb.VGtid) {
+	e.copying = make(map[string]bool)
+	for _, sgtid := range vgtid.GetShardGtids() {
+		if sgtid.Gtid == "" || len(sgtid.TablePKs) > 0 {
+			e.copying[sgtid.Keyspace+"/"+sgtid.Shard] = true
+		}
+	}
+	e.setVGtid(vgtid)
+}
+
+// debeziumShardGtid is the position of a shard in the vgtid of the
+// Debezium source.
+type debeziumShardGtid struct {
+	Keyspace string                      `json:"keyspace"`
+	Shard    string                      `json:"shard"`
+	Gtid     string                      `json:"gtid"`
+	TablePKs []*binlogdatapb.TableLastPK `json:"table_p_ks"`
+}
+
+func (e *encoder) setVGtid(vgtid *binlogdatapb.VGtid) {
+	sgtids := make([]debeziumShardGtid, 0, len(vgtid.GetShardGtids()))
+	for _, sgtid := range vgtid.GetShardGtids() {
+		sgtids = append(sgtids, debeziumShardGtid{
+			Keyspace: sgtid.Keyspace,
+			Shard:    sgtid.Shard,
+			Gtid:     sgtid.Gtid,
+			TablePKs: sgtid.TablePKs,
+		})
+	}
+	data, err := json.Marshal(sgtids)
+	if err != nil {
+		// Unreachable: a VGtid can always be marshaled.
+		return
+	}
+	e.vgtid = string(data)
+}
+
+// encode returns the lines for an event, which may be none.
+func (e *encoder) encode(event *binlogdatapb.VEvent) ([][]byte, error) {
+	switch event.Type {
+	case binlogdatapb.VEventType_FIELD:
+		e.fields[event.FieldEvent.TableName] = event.FieldEvent.Fields
+	case binlogdatapb.VEventType_VGTID:
+		e.setVGtid(event.Vgtid)
+	case binlogdatapb.VEventType_COPY_COMPLETED:
+		if event.Keyspace == "" {
+			// The copy of all the shards is completed.
+			e.copying = make(map[string]bool)
+		} else {
+			delete(e.copying, event.Keyspace+"/"+event.Shard)
+		}
+	}
+
+	if e.format == FormatJSON {
+		line, err := protojson.Marshal(event)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot marshal %v event", event.Type)
+		}
+		return [][]byte{line}, nil
+	}
+
+	if event.Type != binlogdatapb.VEventType_ROW {
+		return nil, nil
+	}
+	return e.encodeDebezium(event)
+}
+
+// debeziumSource is the source block of a Debezium change event.
+type debeziumSource struct {
+	Connector string `json:"connector"`
+	Name      string `json:"name"`
+	TsMs      int64  `json:"ts_ms"`
+	Snapshot  string `json:"snapshot"`
+	DB        string `json:"db"`
+	Keyspace  string `json:"keyspace"`
+	Table     string `json:"table"`
+	Shard     string `json:"shard"`
+	Vgtid     string `json:"vgtid"`
+}
+
+// debeziumEnvelope is the payload of a Debezium change event.
+type debeziumEnvelope struct {
+	Before map[string]any  `json:"before"`
+	After  map[string]any  `json:"after"`
+	Source *debeziumSource `json:"source"`
+	Op     string          `json:"op"`
+	TsMs   int64           `json:"ts_ms"`
+}
+
+func (e *encoder) encodeDebezium(event *binlogdatapb.VEvent) ([][]byte, error) {
+	rowEvent := event.RowEvent
+	fields, ok := e.fields[rowEvent.TableName]
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "no fields received for table %v", rowEvent.TableName)
+	}
+
+	// The table names are qualified by vtgate with the keyspace.
+	table := strings.TrimPrefix(rowEvent.TableName, event.Keyspace+".")
+	snapshot := e.copying[event.Keyspace+"/"+event.Shard]
+	source := &debeziumSource{
+		Connector: "vitess",
+		Name:      e.name,
+		TsMs:      event.Timestamp * 1000,
+		Snapshot:  "false",
+		DB:        event.Keyspace,
+		Keyspace:  event.Keyspace,
+		Table:     table,
+		Shard:     event.Shard,
+		Vgtid:     e.vgtid,
+	}
+	if snapshot {
+		source.Snapshot = "true"
+	}
+
+	lines := make([][]byte, 0, len(rowEvent.RowChanges))
+	for _, change := range rowEvent.RowChanges {
+		envelope := &debeziumEnvelope{
+			Before: rowValues(fields, change.Before),
+			After:  rowValues(fields, change.After),
+			Source: source,
+			TsMs:   e.now().UnixMilli(),
+		}
+		switch {
+		case change.Before == nil && snapshot:
+			envelope.Op = "r"
+		case change.Before == nil:
+			envelope.Op = "c"
+		case change.After == nil:
+			envelope.Op = "d"
+		default:
+			envelope.Op = "u"
+		}
+		line, err := json.Marshal(envelope)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "cannot marshal row of %v", rowEvent.TableName)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// rowValues returns the values of a row by column name: numbers for the
+// numeric columns, base64 for the binary ones and strings otherwise.
+func rowValues(fields []*querypb.Field, row *querypb.Row) map[string]any {
+	if row == nil {
+		return nil
+	}
+	values := make(map[string]any, len(fields))
+	for i, value := range sqltypes.MakeRowTrusted(fields, row) {
+		name := fields[i].Name
+		switch {
+		case value.IsNull():
+			values[name] = nil
+		case value.IsIntegral() || value.IsFloat():
+			values[name] = json.Number(value.ToString())
+		case value.IsBinary():
+			values[name] = value.Raw()
+		default:
+			values[name] = value.ToString()
+		}
+	}
+	return values
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var testFields = []*querypb.Field{{
+	Name: "id",
+	Type: sqltypes.Int64,
+}, {
+	Name: "name",
+	Type: sqltypes.VarChar,
+}, {
+	Name: "data",
+	Type: sqltypes.VarBinary,
+}}
+
+func fieldEvent(shard string) *binlogdatapb.VEvent {
+	return &binlogdatapb.VEvent{
+		Type:     binlogdatapb.VEventType_FIELD,
+		Keyspace: "ks",
+		Shard:    shard,
+		FieldEvent: &binlogdatapb.FieldEvent{
+			TableName: "ks.t1",
+			Fields:    testFields,
+		},
+	}
+}
+
+func rowEvent(shard string, changes ...*binlogdatapb.RowChange) *binlogdatapb.VEvent {
+	return &binlogdatapb.VEvent{
+		Type:      binlogdatapb.VEventType_ROW,
+		Timestamp: 1672531200,
+		Keyspace:  "ks",
+		Shard:     shard,
+		RowEvent: &binlogdatapb.RowEvent{
+			TableName:  "ks.t1",
+			RowChanges: changes,
+		},
+	}
+}
+
+func testRow(id int64, name string) *querypb.Row {
+	values := []sqltypes.Value{sqltypes.NewInt64(id), sqltypes.NULL, sqltypes.MakeTrusted(sqltypes.VarBinary, []byte("\x01"))}
+	if name != "" {
+		values[1] = sqltypes.NewVarChar(name)
+	}
+	return sqltypes.RowToProto3(values)
+}
+
+func TestEncoderJSON(t *testing.T) {
+	e, err := newEncoder(FormatJSON, "test")
+	require.NoError(t, err)
+
+	lines, err := e.encode(&binlogdatapb.VEvent{Type: binlogdatapb.VEventType_COMMIT, Keyspace: "ks", Shard: "0"})
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.JSONEq(t, `{"type": "COMMIT", "keyspace": "ks", "shard": "0"}`, string(lines[0]))
+
+	_, err = newEncoder("avro", "test")
+	assert.EqualError(t, err, "unknown output format avro, expected json or debezium")
+}
+
+func TestEncoderDebezium(t *testing.T) {
+	e, err := newEncoder(FormatDebezium, "test")
+	require.NoError(t, err)
+	e.now = func() time.Time { return time.UnixMilli(1672531201000) }
+
+	// Shard -80 is copied, 80- streams from a position.
+	e.start(&binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{
+		{Keyspace: "ks", Shard: "-80"},
+		{Keyspace: "ks", Shard: "80-", Gtid: "MySQL56/uuid:1-10"},
+	}})
+
+	lines, err := e.encode(fieldEvent("-80"))
+	require.NoError(t, err)
+	assert.Empty(t, lines)
+
+	source := `"source": {"connector": "vitess", "name": "test", "ts_ms": 1672531200000, "db": "ks", "keyspace": "ks", "table": "t1", "shard": "%s", "snapshot": "%s", "vgtid": "[{\"keyspace\":\"ks\",\"shard\":\"-80\",\"gtid\":\"\",\"table_p_ks\":null},{\"keyspace\":\"ks\",\"shard\":\"80-\",\"gtid\":\"MySQL56/uuid:1-10\",\"table_p_ks\":null}]"}`
+	for _, tcase := range []struct {
+		event    *binlogdatapb.VEvent
+		expected []string
+	}{{
+		event: rowEvent("-80", &binlogdatapb.RowChange{After: testRow(1, "a")}),
+		expected: []string{
+			`{"before": null, "after": {"id": 1, "name": "a", "data": "AQ=="}, ` + fmt.Sprintf(source, "-80", "true") + `, "op": "r", "ts_ms": 1672531201000}`,
+		},
+	}, {
+		event: rowEvent("80-",
+			&binlogdatapb.RowChange{After: testRow(2, "")},
+			&binlogdatapb.RowChange{Before: testRow(2, ""), After: testRow(2, "b")},
+			&binlogdatapb.RowChange{Before: testRow(2, "b")},
+		),
+		expected: []string{
+			`{"before": null, "after": {"id": 2, "name": null, "data": "AQ=="}, ` + fmt.Sprintf(source, "80-", "false") + `, "op": "c", "ts_ms": 1672531201000}`,
+			`{"before": {"id": 2, "name": null, "data": "AQ=="}, "after": {"id": 2, "name": "b", "data": "AQ=="}, ` + fmt.Sprintf(source, "80-", "false") + `, "op": "u", "ts_ms": 1672531201000}`,
+			`{"before": {"id": 2, "name": "b", "data": "AQ=="}, "after": null, ` + fmt.Sprintf(source, "80-", "false") + `, "op": "d", "ts_ms": 1672531201000}`,
+		},
+	}} {
+		lines, err := e.encode(tcase.event)
+		require.NoError(t, err)
+		require.Len(t, lines, len(tcase.expected))
+		for i, line := range lines {
+			assert.JSONEq(t, tcase.expected[i], string(line))
+		}
+	}
+
+	// Once copied, the inserts are creations.
+	_, err = e.encode(&binlogdatapb.VEvent{Type: binlogdatapb.VEventType_COPY_COMPLETED, Keyspace: "ks", Shard: "-80"})
+	require.NoError(t, err)
+	lines, err = e.encode(rowEvent("-80", &binlogdatapb.RowChange{After: testRow(3, "c")}))
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Contains(t, string(lines[0]), `"op":"c"`)
+
+	// The fields must be known.
+	e.fields = make(map[string][]*querypb.Field)
+	_, err = e.encode(rowEvent("-80", &binlogdatapb.RowChange{After: testRow(3, "c")}))
+	assert.EqualError(t, err, "no fields received for table ks.t1")
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	// fileExtension is the extension of the complete output files.
+	fileExtension = ".jsonl"
+
+	// partialExtension is appended to the name of the output file which
+	// is being written.
+	partialExtension = ".partial"
+)
+
+// output receives the encoded events, one line at a time.
+type output interface {
+	// Write buffers a line. The newline is added by the output.
+	Write(line []byte) error
+
+	// Flush makes the buffered lines durable.
+	Flush() error
+
+	// Close flushes and releases the output.
+	Close() error
+}
+
+// streamOutput writes the lines to a stream, typically stdout.
+type streamOutput struct {
+	w *bufio.Writer
+}
+
+func newStreamOutput(w io.Writer) *streamOutput {
+	return &streamOutput{w: bufio.NewWriter(w)}
+}
+
+// Write is part of the output interface.
+func (o *streamOutput) Write(line []byte) error {
+	if _, err := o.w.Write(line); err != nil {
+		return err
+	}
+	return o.w.WriteByte('\n')
+}
+
+// Flush is part of the output interface.
+func (o *streamOutput) Flush() error {
+	return o.w.Flush()
+}
+
+// Close is part of the output interface.
+func (o *streamOutput) Close() error {
+	return o.Flush()
+}
+
+// fileOutput writes the lines to files in a directory, rotated by size
+// and age. The file being written has the partialExtension, which is
+// removed once it is rotated out, so consumers only pick up complete
+// files. Files are only rotated when flushed, so the events of a
+// checkpointed batch never span a partial file.
+type fileOutput struct {
+	dir     string
+	prefix  string
+	maxSize int64
+	maxAge  time.Duration
+
+	// now is time.Now, except in tests.
+	now func() time.Time
+
+	file    *os.File
+	w       *bufio.Writer
+	size    int64
+	created time.Time
+}
+
+// newFileOutput returns a fileOutput writing to dir, after completing the
+// partial files left by a previous run.
+func newFileOutput(dir, prefix string, maxSize int64, maxAge time.Duration) (*fileOutput, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, vterrors.Wrapf(err, "cannot create output directory %v", dir)
+	}
+	o := &fileOutput{
+		dir:     dir,
+		prefix:  prefix,
+		maxSize: maxSize,
+		maxAge:  maxAge,
+		now:     time.Now,
+	}
+	if err := o.recoverPartialFiles(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// recoverPartialFiles completes the files a previous run was writing
+// when it stopped. Their last line may have been cut by a crash, in
+// which case it is removed: it was never checkpointed, so it is streamed
+// again.
+func (o *fileOutput) recoverPartialFiles() error {
+	partials, err := filepath.Glob(filepath.Join(o.dir, o.prefix+"-*"+fileExtension+partialExtension))
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot list partial output files")
+	}
+	for _, partial := range partials {
+		data, err := os.ReadFile(partial)
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot read partial output file %v", partial)
+		}
+		if complete := int64(bytes.LastIndexByte(data, '\n') + 1); complete != int64(len(data)) {
+			log.Warningf("Removing the incomplete last line of %v", partial)
+			if err := os.Truncate(partial, complete); err != nil {
+				return vterrors.Wrapf(err, "cannot truncate partial output file %v", partial)
+			}
+		}
+		if err := os.Rename(partial, strings.TrimSuffix(partial, partialExtension)); err != nil {
+			return vterrors.Wrapf(err, "cannot complete partial output file %v", partial)
+		}
+	}
+	return nil
+}
+
+// Write is part of the output interface.
+func (o *fileOutput) Write(line []byte) error {
+	if o.file == nil {
+		if err := o.open(); err != nil {
+			return err
+		}
+	}
+	if _, err := o.w.Write(line); err != nil {
+		return vterrors.Wrapf(err, "cannot write to %v", o.file.Name())
+	}
+	if err := o.w.WriteByte('\n'); err != nil {
+		return vterrors.Wrapf(err, "cannot write to %v", o.file.Name())
+	}
+	o.size += int64(len(line)) + 1
+	return nil
+}
+
+// open starts a new file, named after its creation time.
+func (o *fileOutput) open() error {
+	o.created = o.now()
+	name := filepath.Join(o.dir, fmt.Sprintf("%s-%s%s%s", o.prefix, o.created.UTC().Format("20060102T150405.000000000Z"), fileExtension, partialExtension))
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot create output file %v", name)
+	}
+	o.file = file
+	o.w = bufio.NewWriter(file)
+	o.size = 0
+	return nil
+}
+
+// Flush is part of the output interface. It rotates the file if it is
+// big or old enough.
+func (o *fileOutput) Flush() error {
+	if o.file == nil {
+		return nil
+	}
+	if err := o.w.Flush(); err != nil {
+		return vterrors.Wrapf(err, "cannot write to %v", o.file.Name())
+	}
+	if err := o.file.Sync(); err != nil {
+		return vterrors.Wrapf(err, "cannot sync %v", o.file.Name())
+	}
+	if (o.maxSize > 0 && o.size >= o.maxSize) || (o.maxAge > 0 && o.now().Sub(o.created) >= o.maxAge) {
+		return o.rotate()
+	}
+	return nil
+}
+
+// rotate closes the current file and removes its partialExtension.
+func (o *fileOutput) rotate() error {
+	name := o.file.Name()
+	if err := o.file.Close(); err != nil {
+		return vterrors.Wrapf(err, "cannot close %v", name)
+	}
+	o.file = nil
+	o.w = nil
+	if err := os.Rename(name, strings.TrimSuffix(name, partialExtension)); err != nil {
+		return vterrors.Wrapf(err, "cannot complete output file %v", name)
+	}
+	return syncDir(o.dir)
+}
+
+// Close is part of the output interface.
+func (o *fileOutput) Close() error {
+	if o.file == nil {
+		return nil
+	}
+	if err := o.w.Flush(); err != nil {
+		return vterrors.Wrapf(err, "cannot write to %v", o.file.Name())
+	}
+	if err := o.file.Sync(); err != nil {
+		return vterrors.Wrapf(err, "cannot sync %v", o.file.Name())
+	}
+	return o.rotate()
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readOutput returns the names and contents of the files in dir, sorted
+// by name.
+func readOutput(t *testing.T, dir string) ([]string, []string) {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names, contents []string
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		names = append(names, entry.Name())
+		contents = append(contents, string(data))
+	}
+	return names, contents
+}
+
+func TestStreamOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	o := newStreamOutput(buf)
+	require.NoError(t, o.Write([]byte("a")))
+	require.NoError(t, o.Write([]byte("b")))
+	assert.Empty(t, buf.String())
+	require.NoError(t, o.Flush())
+	assert.Equal(t, "a\nb\n", buf.String())
+}
+
+func TestFileOutput(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	o, err := newFileOutput(dir, "test", 4, time.Minute)
+	require.NoError(t, err)
+	o.now = func() time.Time { return now }
+
+	// Nothing is written until there is a line.
+	require.NoError(t, o.Flush())
+	names, _ := readOutput(t, dir)
+	assert.Empty(t, names)
+
+	// The file is partial until it is rotated.
+	require.NoError(t, o.Write([]byte("a")))
+	require.NoError(t, o.Flush())
+	names, contents := readOutput(t, dir)
+	assert.Equal(t, []string{"test-20230102T030405.000000000Z.jsonl.partial"}, names)
+	assert.Equal(t, []string{"a\n"}, contents)
+
+	// The file is rotated by size, when flushed.
+	require.NoError(t, o.Write([]byte("bc")))
+	require.NoError(t, o.Write([]byte("d")))
+	names, _ = readOutput(t, dir)
+	assert.Equal(t, []string{"test-20230102T030405.000000000Z.jsonl.partial"}, names)
+	require.NoError(t, o.Flush())
+	names, contents = readOutput(t, dir)
+	assert.Equal(t, []string{"test-20230102T030405.000000000Z.jsonl"}, names)
+	assert.Equal(t, []string{"a\nbc\nd\n"}, contents)
+
+	// And by age.
+	now = now.Add(time.Second)
+	require.NoError(t, o.Write([]byte("e")))
+	require.NoError(t, o.Flush())
+	now = now.Add(time.Minute)
+	require.NoError(t, o.Flush())
+	names, _ = readOutput(t, dir)
+	assert.Equal(t, []string{"test-20230102T030405.000000000Z.jsonl", "test-20230102T030406.000000000Z.jsonl"}, names)
+
+	// Close completes the current file.
+	require.NoError(t, o.Write([]byte("f")))
+	require.NoError(t, o.Close())
+	names, _ = readOutput(t, dir)
+	assert.Equal(t, []string{"test-20230102T030405.000000000Z.jsonl", "test-20230102T030406.000000000Z.jsonl", "test-20230102T030506.000000000Z.jsonl"}, names)
+}
+
+func TestFileOutputRecovery(t *testing.T) {
+	dir := t.TempDir()
+	// A crash left a partial file with an incomplete line, and a file of
+	// another stream.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test-20230102T030405.000000000Z.jsonl.partial"), []byte("a\nb\nc"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other-20230102T030405.000000000Z.jsonl.partial"), []byte("x"), 0o644))
+
+	_, err := newFileOutput(dir, "test", 0, 0)
+	require.NoError(t, err)
+	names, contents := readOutput(t, dir)
+	assert.Equal(t, []string{"other-20230102T030405.000000000Z.jsonl.partial", "test-20230102T030405.000000000Z.jsonl"}, names)
+	assert.Equal(t, []string{"x", "a\nb\n"}, contents)
+}
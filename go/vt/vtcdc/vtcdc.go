@@ -0,0 +1,296 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vtcdc streams the changes of a set of tables from the VStream
+// API of vtgate to files or stdout, as newline-delimited JSON. The VGtid
+// of the written events is checkpointed after each flushed batch, so the
+// stream resumes where it stopped after a restart, including across
+// reshards. The events are delivered at least once: the events written
+// after the last checkpoint are written again after a restart.
+package vtcdc
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// VStreamFunc starts a VStream, like vtgateconn.VTGateConn.VStream.
+type VStreamFunc func(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (vtgateconn.VStreamReader, error)
+
+// Config is the configuration of a CDC stream.
+type Config struct {
+	TabletType topodatapb.TabletType
+	Filter     *binlogdatapb.Filter
+	Flags      *vtgatepb.VStreamFlags
+
+	// Position is where the stream starts if there is no checkpoint.
+	Position *binlogdatapb.VGtid
+
+	// CheckpointFile is the file the VGtid is checkpointed to. The
+	// stream is not checkpointed if it is empty.
+	CheckpointFile string
+
+	// Format is FormatJSON or FormatDebezium.
+	Format string
+
+	// Name is the logical name of the stream, used as the prefix of the
+	// output files and as the name of the Debezium source.
+	Name string
+
+	// OutputDir is the directory of the output files. The events are
+	// written to stdout if it is empty.
+	OutputDir string
+
+	// MaxFileSize and MaxFileAge trigger the rotation of the output
+	// files. Zero disables the rotation on that criterion.
+	MaxFileSize int64
+	MaxFileAge  time.Duration
+
+	// RetryDelay is the time to wait before restarting a failed stream.
+	RetryDelay time.Duration
+}
+
+// CDC runs a VStream and writes its events.
+type CDC struct {
+	config  *Config
+	vstream VStreamFunc
+	encoder *encoder
+	output  output
+
+	// position is the VGtid up to which all the events were written, and
+	// which is checkpointed when the output is flushed.
+	position *binlogdatapb.VGtid
+
+	// pending is the last VGtid received, which becomes the position
+	// once its transaction is committed.
+	pending *binlogdatapb.VGtid
+
+	// dirty is true if position changed since the last checkpoint.
+	dirty bool
+
+	// journals tracks the participants of a reshard which reached the
+	// journal, by journal id, when the flags stop the stream on reshards.
+	journals map[int64]map[string]bool
+
+	// resharded is true if the stream ended because of a reshard, and
+	// has to be restarted on the new shards.
+	resharded bool
+}
+
+// New returns a CDC stream, starting at the checkpoint if there is one.
+func New(config *Config, vstream VStreamFunc) (*CDC, error) {
+	enc, err := newEncoder(config.Format, config.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	position := config.Position
+	if config.CheckpointFile != "" {
+		checkpoint, err := LoadCheckpoint(config.CheckpointFile)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil {
+			log.Infof("Resuming from the checkpoint in %v: %v", config.CheckpointFile, checkpoint)
+			position = checkpoint
+		}
+	}
+	if len(position.GetShardGtids()) == 0 {
+		return nil, vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "no checkpoint and no position to start streaming from")
+	}
+
+	var out output
+	if config.OutputDir == "" {
+		out = newStreamOutput(os.Stdout)
+	} else {
+		out, err = newFileOutput(config.OutputDir, config.Name, config.MaxFileSize, config.MaxFileAge)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &CDC{
+		config:   config,
+		vstream:  vstream,
+		encoder:  enc,
+		output:   out,
+		position: proto.Clone(position).(*binlogdatapb.VGtid),
+		journals: make(map[int64]map[string]bool),
+	}, nil
+}
+
+// Position returns the VGtid up to which all the events were written.
+func (c *CDC) Position() *binlogdatapb.VGtid {
+	return c.position
+}
+
+// Run streams the events until the context is canceled or the stream
+// ends. Failed streams are restarted from the last position after the
+// retry delay. Errors writing the events or the checkpoint end the run.
+func (c *CDC) Run(ctx context.Context) error {
+	for {
+		retry, err := c.stream(ctx)
+		if ferr := c.flush(); ferr != nil {
+			return ferr
+		}
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && !retry:
+			return err
+		case c.resharded:
+			log.Infof("Restarting the stream after a reshard: %v", c.position)
+			c.resharded = false
+			continue
+		case err == nil:
+			return nil
+		}
+
+		log.Warningf("VStream failed, restarting from %v in %v: %v", c.position, c.config.RetryDelay, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.config.RetryDelay):
+		}
+	}
+}
+
+// Close releases the output.
+func (c *CDC) Close() error {
+	return c.output.Close()
+}
+
+// stream runs one VStream from the current position. It returns nil when
+// the stream ends, and whether it can be retried when it fails.
+func (c *CDC) stream(ctx context.Context) (bool, error) {
+	c.pending = nil
+	c.encoder.start(c.position)
+	reader, err := c.vstream(ctx, c.config.TabletType, c.position, c.config.Filter, c.config.Flags)
+	if err != nil {
+		return true, err
+	}
+	for {
+		events, err := reader.Recv()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return true, err
+		}
+		for _, event := range events {
+			if err := c.process(event); err != nil {
+				return false, err
+			}
+		}
+		if err := c.flush(); err != nil {
+			return false, err
+		}
+	}
+}
+
+// process writes an event and tracks the position.
+func (c *CDC) process(event *binlogdatapb.VEvent) error {
+	switch event.Type {
+	case binlogdatapb.VEventType_VGTID:
+		c.pending = event.Vgtid
+	case binlogdatapb.VEventType_COMMIT, binlogdatapb.VEventType_DDL, binlogdatapb.VEventType_OTHER, binlogdatapb.VEventType_COPY_COMPLETED:
+		if c.pending != nil {
+			c.position = c.pending
+			c.pending = nil
+			c.dirty = true
+		}
+	case binlogdatapb.VEventType_JOURNAL:
+		c.journal(event)
+	}
+
+	lines, err := c.encoder.encode(event)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if err := c.output.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// journal handles the journal events, which are only sent when the flags
+// stop the stream on reshards. Once all the participants of the reshard
+// reached the journal, the position moves to the new shards. Otherwise
+// vtgate moves the stream to the new shards itself, and sends their VGtid.
+func (c *CDC) journal(event *binlogdatapb.VEvent) {
+	journal := event.Journal
+	if journal.MigrationType != binlogdatapb.MigrationType_SHARDS {
+		return
+	}
+	reached, ok := c.journals[journal.Id]
+	if !ok {
+		reached = make(map[string]bool)
+		c.journals[journal.Id] = reached
+	}
+	reached[event.Keyspace+"/"+event.Shard] = true
+	for _, participant := range journal.Participants {
+		if !reached[participant.Keyspace+"/"+participant.Shard] {
+			return
+		}
+	}
+	delete(c.journals, journal.Id)
+
+	position := &binlogdatapb.VGtid{}
+	for _, sgtid := range c.position.ShardGtids {
+		if !reached[sgtid.Keyspace+"/"+sgtid.Shard] {
+			position.ShardGtids = append(position.ShardGtids, sgtid)
+		}
+	}
+	for _, sgtid := range journal.ShardGtids {
+		position.ShardGtids = append(position.ShardGtids, proto.Clone(sgtid).(*binlogdatapb.ShardGtid))
+	}
+	log.Infof("Reshard journal %v reached by all the participants, moving from %v to %v", journal.Id, c.position, position)
+	c.position = position
+	c.pending = nil
+	c.dirty = true
+	c.resharded = true
+}
+
+// flush makes the written events durable, and then checkpoints their
+// position.
+func (c *CDC) flush() error {
+	if err := c.output.Flush(); err != nil {
+		return err
+	}
+	if !c.dirty || c.config.CheckpointFile == "" {
+		return nil
+	}
+	if err := SaveCheckpoint(c.config.CheckpointFile, c.position); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// fakeReader returns its batches of events, and then its error.
+type fakeReader struct {
+	batches [][]*binlogdatapb.VEvent
+	err     error
+}
+
+func (r *fakeReader) Recv() ([]*binlogdatapb.VEvent, error) {
+	if len(r.batches) == 0 {
+		return nil, r.err
+	}
+	batch := r.batches[0]
+	r.batches = r.batches[1:]
+	return batch, nil
+}
+
+// fakeVStream returns its readers in order, and records the positions
+// the streams start from.
+type fakeVStream struct {
+	readers   []*fakeReader
+	positions []*binlogdatapb.VGtid
+}
+
+func (f *fakeVStream) vstream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (vtgateconn.VStreamReader, error) {
+	f.positions = append(f.positions, proto.Clone(vgtid).(*binlogdatapb.VGtid))
+	if len(f.readers) == 0 {
+		return nil, errors.New("no more streams")
+	}
+	reader := f.readers[0]
+	f.readers = f.readers[1:]
+	return reader, nil
+}
+
+func vgtid(shardGtids ...string) *binlogdatapb.VGtid {
+	vgtid := &binlogdatapb.VGtid{}
+	for i := 0; i < len(shardGtids); i += 2 {
+		vgtid.ShardGtids = append(vgtid.ShardGtids, &binlogdatapb.ShardGtid{
+			Keyspace: "ks",
+			Shard:    shardGtids[i],
+			Gtid:     shardGtids[i+1],
+		})
+	}
+	return vgtid
+}
+
+func vgtidEvent(vgtid *binlogdatapb.VGtid) *binlogdatapb.VEvent {
+	return &binlogdatapb.VEvent{Type: binlogdatapb.VEventType_VGTID, Vgtid: vgtid}
+}
+
+func typedEvent(typ binlogdatapb.VEventType) *binlogdatapb.VEvent {
+	return &binlogdatapb.VEvent{Type: typ, Keyspace: "ks", Shard: "0"}
+}
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+
+	loaded, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+
+	for _, position := range []*binlogdatapb.VGtid{vgtid("0", "MySQL56/uuid:1-10"), vgtid("-80", "MySQL56/uuid:1-20", "80-", "MySQL56/uuid:1-30")} {
+		require.NoError(t, SaveCheckpoint(path, position))
+		loaded, err = LoadCheckpoint(path)
+		require.NoError(t, err)
+		utils.MustMatch(t, position, loaded)
+	}
+
+	// The temporary files are removed.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, err = LoadCheckpoint(path)
+	assert.ErrorContains(t, err, "cannot parse checkpoint")
+}
+
+func TestCDC(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	checkpoint := filepath.Join(dir, "checkpoint")
+	config := &Config{
+		Position:       vgtid("0", ""),
+		CheckpointFile: checkpoint,
+		Format:         FormatJSON,
+		Name:           "test",
+		OutputDir:      filepath.Join(dir, "output"),
+	}
+
+	p1 := vgtid("0", "MySQL56/uuid:1-10")
+	p2 := vgtid("0", "MySQL56/uuid:1-11")
+	fake := &fakeVStream{readers: []*fakeReader{{
+		batches: [][]*binlogdatapb.VEvent{{
+			typedEvent(binlogdatapb.VEventType_BEGIN),
+			fieldEvent("0"),
+			rowEvent("0", &binlogdatapb.RowChange{After: testRow(1, "a")}),
+			vgtidEvent(p1),
+			typedEvent(binlogdatapb.VEventType_COMMIT),
+		}, {
+			// The stream fails in the middle of this transaction.
+			vgtidEvent(p2),
+			typedEvent(binlogdatapb.VEventType_BEGIN),
+			rowEvent("0", &binlogdatapb.RowChange{After: testRow(2, "b")}),
+		}},
+		err: errors.New("connection reset"),
+	}, {
+		batches: [][]*binlogdatapb.VEvent{{
+			vgtidEvent(p2),
+			typedEvent(binlogdatapb.VEventType_BEGIN),
+			fieldEvent("0"),
+			rowEvent("0", &binlogdatapb.RowChange{After: testRow(2, "b")}),
+			typedEvent(binlogdatapb.VEventType_COMMIT),
+		}},
+		err: io.EOF,
+	}}}
+
+	cdc, err := New(config, fake.vstream)
+	require.NoError(t, err)
+	require.NoError(t, cdc.Run(ctx))
+	require.NoError(t, cdc.Close())
+
+	// The failed stream restarted from the last committed transaction.
+	require.Len(t, fake.positions, 2)
+	utils.MustMatch(t, config.Position, fake.positions[0])
+	utils.MustMatch(t, p1, fake.positions[1])
+	utils.MustMatch(t, p2, cdc.Position())
+
+	loaded, err := LoadCheckpoint(checkpoint)
+	require.NoError(t, err)
+	utils.MustMatch(t, p2, loaded)
+
+	// The uncommitted events were written again.
+	names, contents := readOutput(t, config.OutputDir)
+	require.Len(t, names, 1)
+	assert.True(t, strings.HasPrefix(names[0], "test-"), names[0])
+	assert.True(t, strings.HasSuffix(names[0], fileExtension), names[0])
+	assert.Equal(t, 13, strings.Count(contents[0], "\n"))
+
+	// A new run resumes from the checkpoint.
+	fake = &fakeVStream{readers: []*fakeReader{{err: io.EOF}}}
+	cdc, err = New(config, fake.vstream)
+	require.NoError(t, err)
+	require.NoError(t, cdc.Run(ctx))
+	require.NoError(t, cdc.Close())
+	require.Len(t, fake.positions, 1)
+	utils.MustMatch(t, p2, fake.positions[0])
+
+	// Output errors are not retried.
+	config.Format = FormatDebezium
+	fake = &fakeVStream{readers: []*fakeReader{{
+		batches: [][]*binlogdatapb.VEvent{{
+			rowEvent("0", &binlogdatapb.RowChange{After: testRow(3, "c")}),
+		}},
+	}}}
+	cdc, err = New(config, fake.vstream)
+	require.NoError(t, err)
+	assert.EqualError(t, cdc.Run(ctx), "no fields received for table ks.t1")
+	require.NoError(t, cdc.Close())
+
+	// Without a checkpoint, a position is needed.
+	_, err = New(&Config{Format: FormatJSON}, fake.vstream)
+	assert.EqualError(t, err, "no checkpoint and no position to start streaming from")
+}
+
+func TestCDCStopOnReshard(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	config := &Config{
+		Flags:          &vtgatepb.VStreamFlags{StopOnReshard: true},
+		Position:       vgtid("-80", "MySQL56/uuid1:1-10", "80-", "MySQL56/uuid2:1-20"),
+		CheckpointFile: filepath.Join(dir, "checkpoint"),
+		Format:         FormatJSON,
+		Name:           "test",
+		OutputDir:      filepath.Join(dir, "output"),
+	}
+
+	journal := &binlogdatapb.Journal{
+		Id:            1,
+		MigrationType: binlogdatapb.MigrationType_SHARDS,
+		Participants: []*binlogdatapb.KeyspaceShard{
+			{Keyspace: "ks", Shard: "-80"},
+			{Keyspace: "ks", Shard: "80-"},
+		},
+		ShardGtids: vgtid("0", "MySQL56/uuid3:1-5").ShardGtids,
+	}
+	journalEvent := func(shard string) *binlogdatapb.VEvent {
+		return &binlogdatapb.VEvent{Type: binlogdatapb.VEventType_JOURNAL, Keyspace: "ks", Shard: shard, Journal: journal}
+	}
+	fake := &fakeVStream{readers: []*fakeReader{{
+		batches: [][]*binlogdatapb.VEvent{
+			{journalEvent("-80")},
+			{journalEvent("80-")},
+		},
+		err: io.EOF,
+	}, {
+		err: io.EOF,
+	}}}
+
+	cdc, err := New(config, fake.vstream)
+	require.NoError(t, err)
+	require.NoError(t, cdc.Run(ctx))
+	require.NoError(t, cdc.Close())
+
+	// The stream restarted on the new shards once all the participants
+	// reached the journal.
+	require.Len(t, fake.positions, 2)
+	utils.MustMatch(t, vgtid("0", "MySQL56/uuid3:1-5"), fake.positions[1])
+	loaded, err := LoadCheckpoint(config.CheckpointFile)
+	require.NoError(t, err)
+	utils.MustMatch(t, vgtid("0", "MySQL56/uuid3:1-5"), loaded)
+}
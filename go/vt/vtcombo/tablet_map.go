@@ -748,6 +748,14 @@ func (itmc *internalTabletManagerClient) GetSchema(
 	return t.tm.GetSchema(ctx, request)
 }
 
+func (itmc *internalTabletManagerClient) GetSchemaHistory(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaHistoryRequest) (*tabletmanagerdatapb.GetSchemaHistoryResponse, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.GetSchemaHistory(ctx, request)
+}
+
 func (itmc *internalTabletManagerClient) GetPermissions(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.Permissions, error) {
 	t, ok := tabletMap[tablet.Alias.Uid]
 	if !ok {
@@ -827,6 +835,14 @@ func (itmc *internalTabletManagerClient) ApplySchema(ctx context.Context, tablet
 	return t.tm.ApplySchema(ctx, change)
 }
 
+func (itmc *internalTabletManagerClient) UpdateGCTable(ctx context.Context, tablet *topodatapb.Tablet, tableName string, action tabletmanagerdatapb.UpdateGCTableRequest_Action) error {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.UpdateGCTable(ctx, tableName, action)
+}
+
 func (itmc *internalTabletManagerClient) ExecuteQuery(context.Context, *topodatapb.Tablet, *tabletmanagerdatapb.ExecuteQueryRequest) (*querypb.QueryResult, error) {
 	return nil, fmt.Errorf("not implemented in vtcombo")
 }
@@ -907,6 +923,14 @@ func (itmc *internalTabletManagerClient) RestoreFromBackup(context.Context, *top
 	return nil, fmt.Errorf("not implemented in vtcombo")
 }
 
+func (itmc *internalTabletManagerClient) SetBackupRateLimits(ctx context.Context, tablet *topodatapb.Tablet, read, write int64) error {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.SetBackupRateLimits(ctx, read, write)
+}
+
 func (itmc *internalTabletManagerClient) Close() {
 }
 
@@ -918,6 +942,26 @@ func (itmc *internalTabletManagerClient) FullStatus(context.Context, *topodatapb
 	return nil, fmt.Errorf("not implemented in vtcombo")
 }
 
+func (itmc *internalTabletManagerClient) GetUnresolvedTransactions(context.Context, *topodatapb.Tablet, int64) ([]*querypb.TransactionMetadata, error) {
+	return nil, fmt.Errorf("not implemented in vtcombo")
+}
+
+func (itmc *internalTabletManagerClient) GetGCTables(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.GCTable, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.GetGCTables(ctx)
+}
+
+func (itmc *internalTabletManagerClient) EstimateSchemaMigration(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.EstimateSchemaMigrationRequest) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	t, ok := tabletMap[tablet.Alias.Uid]
+	if !ok {
+		return nil, fmt.Errorf("tmclient: cannot find tablet %v", tablet.Alias.Uid)
+	}
+	return t.tm.EstimateSchemaMigration(ctx, request)
+}
+
 func (itmc *internalTabletManagerClient) StopReplication(context.Context, *topodatapb.Tablet) error {
 	return fmt.Errorf("not implemented in vtcombo")
 }
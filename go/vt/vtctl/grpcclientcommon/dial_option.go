@@ -29,6 +29,7 @@ import (
 var cert, key, ca, crl, name string
 
 func init() {
+	servenv.OnParseFor("schemadiff", RegisterFlags)
 	servenv.OnParseFor("vtctl", RegisterFlags)
 	servenv.OnParseFor("vttestserver", RegisterFlags)
 	servenv.OnParseFor("vtctlclient", RegisterFlags)
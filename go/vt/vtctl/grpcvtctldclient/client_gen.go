@@ -110,6 +110,15 @@ func (client *gRPCVtctldClient) ChangeTabletType(ctx context.Context, in *vtctld
 	return client.c.ChangeTabletType(ctx, in, opts...)
 }
 
+// ConcludeTransaction is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ConcludeTransaction(ctx context.Context, in *vtctldatapb.ConcludeTransactionRequest, opts ...grpc.CallOption) (*vtctldatapb.ConcludeTransactionResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ConcludeTransaction(ctx, in, opts...)
+}
+
 // CreateKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) CreateKeyspace(ctx context.Context, in *vtctldatapb.CreateKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.CreateKeyspaceResponse, error) {
 	if client.c == nil {
@@ -191,6 +200,15 @@ func (client *gRPCVtctldClient) EmergencyReparentShard(ctx context.Context, in *
 	return client.c.EmergencyReparentShard(ctx, in, opts...)
 }
 
+// EstimateSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) EstimateSchemaMigration(ctx context.Context, in *vtctldatapb.EstimateSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.EstimateSchemaMigrationResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.EstimateSchemaMigration(ctx, in, opts...)
+}
+
 // ExecuteFetchAsApp is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) ExecuteFetchAsApp(ctx context.Context, in *vtctldatapb.ExecuteFetchAsAppRequest, opts ...grpc.CallOption) (*vtctldatapb.ExecuteFetchAsAppResponse, error) {
 	if client.c == nil {
@@ -218,6 +236,15 @@ func (client *gRPCVtctldClient) ExecuteHook(ctx context.Context, in *vtctldatapb
 	return client.c.ExecuteHook(ctx, in, opts...)
 }
 
+// ExportTopology is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ExportTopology(ctx context.Context, in *vtctldatapb.ExportTopologyRequest, opts ...grpc.CallOption) (*vtctldatapb.ExportTopologyResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ExportTopology(ctx, in, opts...)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	if client.c == nil {
@@ -263,6 +290,15 @@ func (client *gRPCVtctldClient) GetCellsAliases(ctx context.Context, in *vtctlda
 	return client.c.GetCellsAliases(ctx, in, opts...)
 }
 
+// GetDriftCheckMismatches is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetDriftCheckMismatches(ctx context.Context, in *vtctldatapb.GetDriftCheckMismatchesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetDriftCheckMismatchesResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetDriftCheckMismatches(ctx, in, opts...)
+}
+
 // GetFullStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetFullStatus(ctx context.Context, in *vtctldatapb.GetFullStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetFullStatusResponse, error) {
 	if client.c == nil {
@@ -317,6 +353,15 @@ func (client *gRPCVtctldClient) GetSchema(ctx context.Context, in *vtctldatapb.G
 	return client.c.GetSchema(ctx, in, opts...)
 }
 
+// GetSchemaHistory is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetSchemaHistory(ctx context.Context, in *vtctldatapb.GetSchemaHistoryRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaHistoryResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetSchemaHistory(ctx, in, opts...)
+}
+
 // GetShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetShard(ctx context.Context, in *vtctldatapb.GetShardRequest, opts ...grpc.CallOption) (*vtctldatapb.GetShardResponse, error) {
 	if client.c == nil {
@@ -389,6 +434,15 @@ func (client *gRPCVtctldClient) GetTablets(ctx context.Context, in *vtctldatapb.
 	return client.c.GetTablets(ctx, in, opts...)
 }
 
+// GetTopologyAuditLog is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetTopologyAuditLog(ctx context.Context, in *vtctldatapb.GetTopologyAuditLogRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTopologyAuditLogResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetTopologyAuditLog(ctx, in, opts...)
+}
+
 // GetTopologyPath is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetTopologyPath(ctx context.Context, in *vtctldatapb.GetTopologyPathRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTopologyPathResponse, error) {
 	if client.c == nil {
@@ -434,6 +488,15 @@ func (client *gRPCVtctldClient) InitShardPrimary(ctx context.Context, in *vtctld
 	return client.c.InitShardPrimary(ctx, in, opts...)
 }
 
+// ListUnresolvedTransactions is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ListUnresolvedTransactions(ctx context.Context, in *vtctldatapb.ListUnresolvedTransactionsRequest, opts ...grpc.CallOption) (*vtctldatapb.ListUnresolvedTransactionsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ListUnresolvedTransactions(ctx, in, opts...)
+}
+
 // PingTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) PingTablet(ctx context.Context, in *vtctldatapb.PingTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.PingTabletResponse, error) {
 	if client.c == nil {
@@ -560,6 +623,15 @@ func (client *gRPCVtctldClient) RestoreFromBackup(ctx context.Context, in *vtctl
 	return client.c.RestoreFromBackup(ctx, in, opts...)
 }
 
+// RestoreTopology is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) RestoreTopology(ctx context.Context, in *vtctldatapb.RestoreTopologyRequest, opts ...grpc.CallOption) (*vtctldatapb.RestoreTopologyResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.RestoreTopology(ctx, in, opts...)
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldatapb.RunHealthCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.RunHealthCheckResponse, error) {
 	if client.c == nil {
@@ -569,6 +641,15 @@ func (client *gRPCVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldat
 	return client.c.RunHealthCheck(ctx, in, opts...)
 }
 
+// SetBackupRateLimits is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) SetBackupRateLimits(ctx context.Context, in *vtctldatapb.SetBackupRateLimitsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetBackupRateLimitsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.SetBackupRateLimits(ctx, in, opts...)
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context, in *vtctldatapb.SetKeyspaceDurabilityPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceDurabilityPolicyResponse, error) {
 	if client.c == nil {
@@ -784,3 +865,12 @@ func (client *gRPCVtctldClient) ValidateVersionShard(ctx context.Context, in *vt
 
 	return client.c.ValidateVersionShard(ctx, in, opts...)
 }
+
+// VerifyBackup is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) VerifyBackup(ctx context.Context, in *vtctldatapb.VerifyBackupRequest, opts ...grpc.CallOption) (*vtctldatapb.VerifyBackupResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.VerifyBackup(ctx, in, opts...)
+}
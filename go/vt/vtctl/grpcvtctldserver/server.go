@@ -34,13 +34,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"vitess.io/vitess/go/event"
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/netutil"
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/dtids"
+	"vitess.io/vitess/go/vt/grpcclient"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -60,6 +64,8 @@ import (
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
@@ -611,6 +617,130 @@ func (s *VtctldServer) ChangeTabletType(ctx context.Context, req *vtctldatapb.Ch
 	}, nil
 }
 
+// ConcludeTransaction is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ConcludeTransaction(ctx context.Context, req *vtctldatapb.ConcludeTransactionRequest) (resp *vtctldatapb.ConcludeTransactionResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ConcludeTransaction")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("dtid", req.Dtid)
+	span.Annotate("action", req.Action.String())
+
+	mmShard, err := dtids.ShardSession(req.Dtid)
+	if err != nil {
+		return nil, err
+	}
+
+	mm, err := s.dialShardPrimary(ctx, mmShard.Target.Keyspace, mmShard.Target.Shard)
+	if err != nil {
+		return nil, err
+	}
+	defer mm.Close(ctx)
+
+	transaction, err := mm.ReadTransaction(ctx, mmShard.Target, req.Dtid)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil || transaction.Dtid == "" {
+		// It was already resolved.
+		return &vtctldatapb.ConcludeTransactionResponse{
+			State: querypb.TransactionState_UNKNOWN,
+		}, nil
+	}
+
+	span.Annotate("state", transaction.State.String())
+
+	switch transaction.State {
+	case querypb.TransactionState_PREPARE:
+		if req.Action == vtctldatapb.ConcludeTransactionRequest_COMMIT {
+			err = vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "transaction %s has no commit decision and cannot be committed", req.Dtid)
+			return nil, err
+		}
+		// If state is PREPARE, make a decision to rollback and
+		// fallthrough to the rollback workflow.
+		if err = mm.SetRollback(ctx, mmShard.Target, transaction.Dtid, mmShard.TransactionId); err != nil {
+			return nil, err
+		}
+		fallthrough
+	case querypb.TransactionState_ROLLBACK:
+		if req.Action == vtctldatapb.ConcludeTransactionRequest_COMMIT {
+			err = vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "transaction %s is being rolled back and cannot be committed", req.Dtid)
+			return nil, err
+		}
+		err = s.runParticipants(ctx, transaction.Participants, func(qs queryservice.QueryService, target *querypb.Target) error {
+			return qs.RollbackPrepared(ctx, target, transaction.Dtid, 0)
+		})
+	case querypb.TransactionState_COMMIT:
+		if req.Action == vtctldatapb.ConcludeTransactionRequest_ROLLBACK {
+			err = vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "transaction %s is being committed and cannot be rolled back", req.Dtid)
+			return nil, err
+		}
+		err = s.runParticipants(ctx, transaction.Participants, func(qs queryservice.QueryService, target *querypb.Target) error {
+			return qs.CommitPrepared(ctx, target, transaction.Dtid)
+		})
+	default:
+		// Should never happen.
+		err = vterrors.Errorf(vtrpc.Code_INTERNAL, "invalid state: %v", transaction.State)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = mm.ConcludeTransaction(ctx, mmShard.Target, transaction.Dtid); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ConcludeTransactionResponse{
+		State: transaction.State,
+	}, nil
+}
+
+// runParticipants runs action against the primary of every participant of a
+// distributed transaction in parallel.
+func (s *VtctldServer) runParticipants(ctx context.Context, participants []*querypb.Target, action func(qs queryservice.QueryService, target *querypb.Target) error) error {
+	var (
+		wg  sync.WaitGroup
+		rec concurrency.AllErrorRecorder
+	)
+	for _, target := range participants {
+		wg.Add(1)
+		go func(target *querypb.Target) {
+			defer wg.Done()
+
+			qs, err := s.dialShardPrimary(ctx, target.Keyspace, target.Shard)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			defer qs.Close(ctx)
+
+			if err := action(qs, target); err != nil {
+				rec.RecordError(fmt.Errorf("%v/%v: %w", target.Keyspace, target.Shard, err))
+			}
+		}(target)
+	}
+	wg.Wait()
+	return rec.Error()
+}
+
+// dialShardPrimary opens a query service connection to the primary tablet of
+// the given shard.
+func (s *VtctldServer) dialShardPrimary(ctx context.Context, keyspace string, shard string) (queryservice.QueryService, error) {
+	si, err := s.ts.GetShard(ctx, keyspace, shard)
+	if err != nil {
+		return nil, err
+	}
+	if !si.HasPrimary() {
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no primary tablet for shard %v/%v", keyspace, shard)
+	}
+	ti, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		return nil, err
+	}
+	return tabletconn.GetDialer()(ti.Tablet, grpcclient.FailFast(false))
+}
+
 // CreateKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) CreateKeyspace(ctx context.Context, req *vtctldatapb.CreateKeyspaceRequest) (resp *vtctldatapb.CreateKeyspaceResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.CreateKeyspace")
@@ -1036,6 +1166,93 @@ func (s *VtctldServer) EmergencyReparentShard(ctx context.Context, req *vtctldat
 	return resp, err
 }
 
+// EstimateSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) EstimateSchemaMigration(ctx context.Context, req *vtctldatapb.EstimateSchemaMigrationRequest) (resp *vtctldatapb.EstimateSchemaMigrationResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.EstimateSchemaMigration")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("ddl_strategy", req.DdlStrategy)
+	span.Annotate("num_sql", len(req.Sql))
+
+	if len(req.Sql) == 0 {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "no SQL statements to estimate")
+	}
+	ddlStrategySetting, err := schema.ParseDDLStrategy(req.DdlStrategy)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid ddl strategy %s: %v", req.DdlStrategy, err)
+	}
+	if ddlStrategySetting.Strategy.IsDirect() {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "cannot estimate a migration with %s strategy", ddlStrategySetting.Strategy)
+	}
+
+	shards, err := s.ts.FindAllShardsInKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m   sync.Mutex
+		wg  sync.WaitGroup
+		rec concurrency.AllErrorRecorder
+	)
+	estimates := make([][]*vtctldatapb.ShardSchemaMigrationEstimate, len(req.Sql))
+
+	for _, si := range shards {
+		if !si.HasPrimary() {
+			rec.RecordError(vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no primary tablet for shard %v/%v", si.Keyspace(), si.ShardName()))
+			continue
+		}
+
+		wg.Add(1)
+		go func(si *topo.ShardInfo) {
+			defer wg.Done()
+
+			ti, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			for i, sql := range req.Sql {
+				estimate, err := s.tmc.EstimateSchemaMigration(ctx, ti.Tablet, &tabletmanagerdatapb.EstimateSchemaMigrationRequest{
+					Sql:         sql,
+					DdlStrategy: req.DdlStrategy,
+				})
+				if err != nil {
+					rec.RecordError(fmt.Errorf("EstimateSchemaMigration(%v, %s) failed: %w", topoproto.TabletAliasString(si.PrimaryAlias), sql, err))
+					return
+				}
+
+				m.Lock()
+				estimates[i] = append(estimates[i], &vtctldatapb.ShardSchemaMigrationEstimate{
+					Shard:    si.ShardName(),
+					Sql:      sql,
+					Estimate: estimate,
+				})
+				m.Unlock()
+			}
+		}(si)
+	}
+	wg.Wait()
+
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+
+	resp = &vtctldatapb.EstimateSchemaMigrationResponse{}
+	for _, statementEstimates := range estimates {
+		sort.Slice(statementEstimates, func(i, j int) bool {
+			return statementEstimates[i].Shard < statementEstimates[j].Shard
+		})
+		resp.Estimates = append(resp.Estimates, statementEstimates...)
+	}
+
+	return resp, nil
+}
+
 // ExecuteFetchAsApp is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) ExecuteFetchAsApp(ctx context.Context, req *vtctldatapb.ExecuteFetchAsAppRequest) (resp *vtctldatapb.ExecuteFetchAsAppResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExecuteFetchAsApp")
@@ -1132,6 +1349,25 @@ func (s *VtctldServer) ExecuteHook(ctx context.Context, req *vtctldatapb.Execute
 	}}, nil
 }
 
+// ExportTopology is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ExportTopology(ctx context.Context, req *vtctldatapb.ExportTopologyRequest) (resp *vtctldatapb.ExportTopologyResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ExportTopology")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+
+	entries, err := topotools.ExportTopology(ctx, s.ts, req.Cells)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ExportTopologyResponse{
+		Entries: entries,
+	}, nil
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) FindAllShardsInKeyspace(ctx context.Context, req *vtctldatapb.FindAllShardsInKeyspaceRequest) (resp *vtctldatapb.FindAllShardsInKeyspaceResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.FindAllShardsInKeyspace")
@@ -1281,6 +1517,149 @@ func (s *VtctldServer) GetCellsAliases(ctx context.Context, req *vtctldatapb.Get
 	return &vtctldatapb.GetCellsAliasesResponse{Aliases: aliases}, nil
 }
 
+// GetDriftCheckMismatches is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetDriftCheckMismatches(ctx context.Context, req *vtctldatapb.GetDriftCheckMismatchesRequest) (resp *vtctldatapb.GetDriftCheckMismatchesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetDriftCheckMismatches")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("table_name", req.TableName)
+	span.Annotate("limit", req.Limit)
+
+	var shards []*topo.ShardInfo
+	if req.Shard != "" {
+		si, err := s.ts.GetShard(ctx, req.Keyspace, req.Shard)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, si)
+	} else {
+		shardMap, err := s.ts.FindAllShardsInKeyspace(ctx, req.Keyspace)
+		if err != nil {
+			return nil, err
+		}
+		for _, si := range shardMap {
+			shards = append(shards, si)
+		}
+	}
+
+	query := "select table_name, lower_bound, upper_bound, tablet_alias, position, primary_row_count, primary_checksum, replica_row_count, replica_checksum, unix_timestamp(detected_timestamp) from _vt.drift_check_mismatches"
+	if req.TableName != "" {
+		query += " where table_name = " + sqltypes.EncodeStringSQL(req.TableName)
+	}
+	query += " order by id desc"
+	if req.Limit > 0 {
+		query += fmt.Sprintf(" limit %d", req.Limit)
+	}
+
+	var (
+		m   sync.Mutex
+		wg  sync.WaitGroup
+		rec concurrency.AllErrorRecorder
+	)
+	resp = &vtctldatapb.GetDriftCheckMismatchesResponse{}
+
+	for _, si := range shards {
+		if !si.HasPrimary() {
+			rec.RecordError(vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no primary tablet for shard %v/%v", si.Keyspace(), si.ShardName()))
+			continue
+		}
+
+		wg.Add(1)
+		go func(si *topo.ShardInfo) {
+			defer wg.Done()
+
+			ti, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			qr, err := s.tmc.ExecuteFetchAsDba(ctx, ti.Tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+				Query:   []byte(query),
+				DbName:  ti.DbName(),
+				MaxRows: uint64(req.Limit),
+			})
+			if err != nil {
+				// The table does not exist until the drift checker of the primary has run.
+				if sqlErr, ok := mysql.NewSQLErrorFromError(err).(*mysql.SQLError); ok && sqlErr.Num == mysql.ERNoSuchTable {
+					return
+				}
+				rec.RecordError(fmt.Errorf("ExecuteFetchAsDba(%v) failed: %w", topoproto.TabletAliasString(si.PrimaryAlias), err))
+				return
+			}
+
+			mismatches, err := driftCheckMismatchesFromResult(si.Keyspace(), si.ShardName(), sqltypes.Proto3ToResult(qr))
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			resp.Mismatches = append(resp.Mismatches, mismatches...)
+		}(si)
+	}
+	wg.Wait()
+
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+
+	sort.SliceStable(resp.Mismatches, func(i, j int) bool {
+		if resp.Mismatches[i].Shard != resp.Mismatches[j].Shard {
+			return resp.Mismatches[i].Shard < resp.Mismatches[j].Shard
+		}
+		return resp.Mismatches[i].DetectedAt.Seconds > resp.Mismatches[j].DetectedAt.Seconds
+	})
+
+	return resp, nil
+}
+
+// driftCheckMismatchesFromResult converts the rows of _vt.drift_check_mismatches to DriftCheckMismatch protos.
+func driftCheckMismatchesFromResult(keyspace string, shard string, qr *sqltypes.Result) ([]*vtctldatapb.DriftCheckMismatch, error) {
+	mismatches := make([]*vtctldatapb.DriftCheckMismatch, 0, len(qr.Rows))
+	for _, row := range qr.Rows {
+		if len(row) != 10 {
+			return nil, vterrors.Errorf(vtrpc.Code_INTERNAL, "unexpected row in drift check mismatches: %v", row)
+		}
+		alias, err := topoproto.ParseTabletAlias(row[3].ToString())
+		if err != nil {
+			return nil, err
+		}
+		primaryRowCount, err := row[5].ToUint64()
+		if err != nil {
+			return nil, err
+		}
+		replicaRowCount, err := row[7].ToUint64()
+		if err != nil {
+			return nil, err
+		}
+		detectedAt, err := row[9].ToInt64()
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, &vtctldatapb.DriftCheckMismatch{
+			Keyspace:        keyspace,
+			Shard:           shard,
+			TableName:       row[0].ToString(),
+			LowerBound:      row[1].ToString(),
+			UpperBound:      row[2].ToString(),
+			TabletAlias:     alias,
+			Position:        row[4].ToString(),
+			PrimaryRowCount: primaryRowCount,
+			PrimaryChecksum: row[6].ToString(),
+			ReplicaRowCount: replicaRowCount,
+			ReplicaChecksum: row[8].ToString(),
+			DetectedAt:      protoutil.TimeToProto(time.Unix(detectedAt, 0)),
+		})
+	}
+	return mismatches, nil
+}
+
 // GetFullStatus is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetFullStatus(ctx context.Context, req *vtctldatapb.GetFullStatusRequest) (resp *vtctldatapb.GetFullStatusResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetFullStatus")
@@ -1461,6 +1840,42 @@ func (s *VtctldServer) GetSchema(ctx context.Context, req *vtctldatapb.GetSchema
 	}, nil
 }
 
+// GetSchemaHistory is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetSchemaHistory(ctx context.Context, req *vtctldatapb.GetSchemaHistoryRequest) (resp *vtctldatapb.GetSchemaHistoryResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSchemaHistory")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("table_name", req.TableName)
+	span.Annotate("position", req.Position)
+
+	if req.TableName == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "table name is required")
+		return nil, err
+	}
+
+	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
+	if err != nil {
+		err = vterrors.Errorf(vtrpc.Code_NOT_FOUND, "Failed to get tablet %v: %v", req.TabletAlias, err)
+		return nil, err
+	}
+
+	history, err := s.tmc.GetSchemaHistory(ctx, ti.Tablet, &tabletmanagerdatapb.GetSchemaHistoryRequest{
+		TableName: req.TableName,
+		Position:  req.Position,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.GetSchemaHistoryResponse{
+		History:         history.History,
+		TableAtPosition: history.TableAtPosition,
+	}, nil
+}
+
 // GetShard is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetShard(ctx context.Context, req *vtctldatapb.GetShardRequest) (resp *vtctldatapb.GetShardResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetShard")
@@ -1884,6 +2299,57 @@ func (s *VtctldServer) GetTablets(ctx context.Context, req *vtctldatapb.GetTable
 	}, nil
 }
 
+// GetTopologyAuditLog is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetTopologyAuditLog(ctx context.Context, req *vtctldatapb.GetTopologyAuditLogRequest) (resp *vtctldatapb.GetTopologyAuditLogResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetTopologyAuditLog")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("cells", strings.Join(req.Cells, ","))
+	span.Annotate("path", req.Path)
+	span.Annotate("caller", req.Caller)
+	span.Annotate("limit", req.Limit)
+
+	sink := s.ts.AuditSink()
+	if sink == nil {
+		err = vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "the topology changes are not audited by this vtctld, see --topo_audit_sink")
+		return nil, err
+	}
+
+	events, err := sink.Events(ctx, &topo.AuditFilter{
+		Cells:  req.Cells,
+		Path:   req.Path,
+		Caller: req.Caller,
+		Since:  protoutil.TimeFromProto(req.Since),
+		Limit:  int(req.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.GetTopologyAuditLogResponse{
+		Events: make([]*vtctldatapb.TopologyAuditEvent, 0, len(events)),
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, &vtctldatapb.TopologyAuditEvent{
+			Time:      protoutil.TimeToProto(event.Time),
+			Cell:      event.Cell,
+			Path:      event.Path,
+			Operation: event.Operation,
+			Version:   event.Version,
+			Caller:    event.Caller,
+			HostName:  event.HostName,
+			UserName:  event.UserName,
+			Reason:    event.Reason,
+			Before:    event.Before,
+			After:     event.After,
+		})
+	}
+
+	return resp, nil
+}
+
 // GetTopologyPath is part of the vtctlservicepb.VtctldServer interface.
 // It returns the cell located at the provided path in the topology server.
 func (s *VtctldServer) GetTopologyPath(ctx context.Context, req *vtctldatapb.GetTopologyPathRequest) (*vtctldatapb.GetTopologyPathResponse, error) {
@@ -2249,6 +2715,68 @@ func (s *VtctldServer) InitShardPrimaryLocked(
 	return nil
 }
 
+// ListUnresolvedTransactions is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ListUnresolvedTransactions(ctx context.Context, req *vtctldatapb.ListUnresolvedTransactionsRequest) (resp *vtctldatapb.ListUnresolvedTransactionsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ListUnresolvedTransactions")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("abandon_age", req.AbandonAge)
+
+	shards, err := s.ts.FindAllShardsInKeyspace(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m   sync.Mutex
+		wg  sync.WaitGroup
+		rec concurrency.AllErrorRecorder
+	)
+	resp = &vtctldatapb.ListUnresolvedTransactionsResponse{}
+
+	for _, si := range shards {
+		if !si.HasPrimary() {
+			rec.RecordError(vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no primary tablet for shard %v/%v", si.Keyspace(), si.ShardName()))
+			continue
+		}
+
+		wg.Add(1)
+		go func(si *topo.ShardInfo) {
+			defer wg.Done()
+
+			ti, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			transactions, err := s.tmc.GetUnresolvedTransactions(ctx, ti.Tablet, req.AbandonAge)
+			if err != nil {
+				rec.RecordError(fmt.Errorf("GetUnresolvedTransactions(%v) failed: %w", topoproto.TabletAliasString(si.PrimaryAlias), err))
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			resp.Transactions = append(resp.Transactions, transactions...)
+		}(si)
+	}
+	wg.Wait()
+
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+
+	sort.Slice(resp.Transactions, func(i, j int) bool {
+		return resp.Transactions[i].TimeCreated < resp.Transactions[j].TimeCreated
+	})
+
+	return resp, nil
+}
+
 // PingTablet is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) PingTablet(ctx context.Context, req *vtctldatapb.PingTabletRequest) (resp *vtctldatapb.PingTabletResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.PingTablet")
@@ -2784,6 +3312,25 @@ func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupReque
 	}
 }
 
+// RestoreTopology is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RestoreTopology(ctx context.Context, req *vtctldatapb.RestoreTopologyRequest) (resp *vtctldatapb.RestoreTopologyResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RestoreTopology")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("changes", len(req.Changes))
+
+	for _, change := range req.Changes {
+		log.Infof("Restoring topology file %v in cell %v (expected version %q, delete %v)", change.Path, change.Cell, change.ExpectedVersion, change.Delete)
+	}
+	if err := topotools.RestoreTopology(ctx, logutil.NewCallbackLogger(func(e *logutilpb.Event) {}), s.ts, req.Changes); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.RestoreTopologyResponse{}, nil
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) RunHealthCheck(ctx context.Context, req *vtctldatapb.RunHealthCheckRequest) (resp *vtctldatapb.RunHealthCheckResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.RunHealthCheck")
@@ -2806,6 +3353,30 @@ func (s *VtctldServer) RunHealthCheck(ctx context.Context, req *vtctldatapb.RunH
 	return &vtctldatapb.RunHealthCheckResponse{}, nil
 }
 
+// SetBackupRateLimits is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) SetBackupRateLimits(ctx context.Context, req *vtctldatapb.SetBackupRateLimitsRequest) (resp *vtctldatapb.SetBackupRateLimitsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetBackupRateLimits")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("read_bytes_per_second", req.ReadBytesPerSecond)
+	span.Annotate("write_bytes_per_second", req.WriteBytesPerSecond)
+
+	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tmc.SetBackupRateLimits(ctx, ti.Tablet, req.ReadBytesPerSecond, req.WriteBytesPerSecond)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.SetBackupRateLimitsResponse{}, nil
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtctldatapb.SetKeyspaceDurabilityPolicyRequest) (resp *vtctldatapb.SetKeyspaceDurabilityPolicyResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.SetKeyspaceDurabilityPolicy")
@@ -4346,6 +4917,82 @@ func (s *VtctldServer) ValidateVSchema(ctx context.Context, req *vtctldatapb.Val
 	return resp, err
 }
 
+// VerifyBackup is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VerifyBackup(ctx context.Context, req *vtctldatapb.VerifyBackupRequest) (resp *vtctldatapb.VerifyBackupResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VerifyBackup")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	bucket := filepath.Join(req.Keyspace, req.Shard)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("backup_path", bucket)
+	span.Annotate("backup_names", strings.Join(req.BackupNames, ","))
+	span.Annotate("concurrency", req.Concurrency)
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer bs.Close()
+
+	bhs, err := bs.ListBackups(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logutil.NewConsoleLogger()
+	var toVerify []backupstorage.BackupHandle
+	if len(req.BackupNames) == 0 {
+		bh, _, err := mysqlctl.FindLatestSuccessfulBackup(ctx, logger, bhs)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "no backup to verify in %v", bucket)
+		}
+		toVerify = append(toVerify, bh)
+	} else {
+		byName := make(map[string]backupstorage.BackupHandle, len(bhs))
+		for _, bh := range bhs {
+			byName[bh.Name()] = bh
+		}
+		for _, name := range req.BackupNames {
+			bh, ok := byName[name]
+			if !ok {
+				return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "backup %v not found in %v", name, bucket)
+			}
+			toVerify = append(toVerify, bh)
+		}
+	}
+
+	params := mysqlctl.VerifyParams{
+		Logger:       logger,
+		Concurrency:  int(req.Concurrency),
+		HookExtraEnv: map[string]string{},
+	}
+	resp = &vtctldatapb.VerifyBackupResponse{}
+	for _, bh := range toVerify {
+		bi := mysqlctlproto.BackupHandleToProto(bh)
+		bi.Keyspace = req.Keyspace
+		bi.Shard = req.Shard
+
+		result := &vtctldatapb.VerifyBackupResponse_BackupVerification{Backup: bi}
+		manifest, err := mysqlctl.VerifyBackup(ctx, params, bh)
+		if manifest != nil {
+			bi.Engine = manifest.BackupMethod
+		}
+		if err != nil {
+			log.Errorf("Backup %v/%v failed verification: %v", bucket, bh.Name(), err)
+			result.Error = err.Error()
+		} else {
+			result.Verified = true
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	return resp, nil
+}
+
 // StartServer registers a VtctldServer for RPCs on the given gRPC server.
 func StartServer(s *grpc.Server, ts *topo.Server) {
 	vtctlservicepb.RegisterVtctldServer(s, NewVtctldServer(ts))
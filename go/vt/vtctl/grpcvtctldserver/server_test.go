@@ -21,7 +21,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,6 +35,7 @@ import (
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/grpcclient"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
@@ -41,9 +44,14 @@ import (
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
 	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
+	"vitess.io/vitess/go/vt/vttablet/tabletconntest"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/vttablet/tmclienttest"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
 	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -70,6 +78,31 @@ func init() {
 	tmclient.RegisterTabletManagerClientFactory("grpcvtctldserver.test", func() tmclient.TabletManagerClient {
 		return nil
 	})
+
+	// Tests that need to reach a tablet's query service should register a
+	// connection in testQueryServices, keyed by tablet alias.
+	tabletconntest.SetProtocol("go.vt.vtctl.grpcvtctldserver", "grpcvtctldserver.test")
+	tabletconn.RegisterDialer("grpcvtctldserver.test", func(tablet *topodatapb.Tablet, failFast grpcclient.FailFast) (queryservice.QueryService, error) {
+		testQueryServicesMu.Lock()
+		defer testQueryServicesMu.Unlock()
+
+		key := topoproto.TabletAliasString(tablet.Alias)
+		if qs, ok := testQueryServices[key]; ok {
+			return qs, nil
+		}
+		return nil, fmt.Errorf("no query service registered for tablet %s", key)
+	})
+}
+
+var (
+	testQueryServicesMu sync.Mutex
+	testQueryServices   = map[string]queryservice.QueryService{}
+)
+
+func setTestQueryServices(conns map[string]queryservice.QueryService) {
+	testQueryServicesMu.Lock()
+	defer testQueryServicesMu.Unlock()
+	testQueryServices = conns
 }
 
 func TestPanicHandler(t *testing.T) {
@@ -1305,6 +1338,143 @@ func TestChangeTabletType(t *testing.T) {
 	})
 }
 
+func TestConcludeTransaction(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "testkeyspace",
+		Shard:    "-80",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+		Keyspace: "testkeyspace",
+		Shard:    "80-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	})
+
+	dtid := "testkeyspace:-80:1234"
+	participants := []*querypb.Target{{
+		Keyspace:   "testkeyspace",
+		Shard:      "80-",
+		TabletType: topodatapb.TabletType_PRIMARY,
+	}}
+
+	tests := []struct {
+		name     string
+		state    querypb.TransactionState
+		action   vtctldatapb.ConcludeTransactionRequest_Action
+		expected querypb.TransactionState
+		// counts of the calls made to the metadata manager and participant.
+		setRollback, rollbackPrepared, commitPrepared, conclude int64
+		shouldErr                                               bool
+	}{
+		{
+			name:             "resolve prepared",
+			state:            querypb.TransactionState_PREPARE,
+			action:           vtctldatapb.ConcludeTransactionRequest_RESOLVE,
+			expected:         querypb.TransactionState_PREPARE,
+			setRollback:      1,
+			rollbackPrepared: 1,
+			conclude:         1,
+		},
+		{
+			name:           "resolve committing",
+			state:          querypb.TransactionState_COMMIT,
+			action:         vtctldatapb.ConcludeTransactionRequest_RESOLVE,
+			expected:       querypb.TransactionState_COMMIT,
+			commitPrepared: 1,
+			conclude:       1,
+		},
+		{
+			name:           "commit",
+			state:          querypb.TransactionState_COMMIT,
+			action:         vtctldatapb.ConcludeTransactionRequest_COMMIT,
+			expected:       querypb.TransactionState_COMMIT,
+			commitPrepared: 1,
+			conclude:       1,
+		},
+		{
+			name:             "rollback",
+			state:            querypb.TransactionState_ROLLBACK,
+			action:           vtctldatapb.ConcludeTransactionRequest_ROLLBACK,
+			expected:         querypb.TransactionState_ROLLBACK,
+			rollbackPrepared: 1,
+			conclude:         1,
+		},
+		{
+			name:      "cannot commit prepared",
+			state:     querypb.TransactionState_PREPARE,
+			action:    vtctldatapb.ConcludeTransactionRequest_COMMIT,
+			shouldErr: true,
+		},
+		{
+			name:      "cannot rollback committing",
+			state:     querypb.TransactionState_COMMIT,
+			action:    vtctldatapb.ConcludeTransactionRequest_ROLLBACK,
+			shouldErr: true,
+		},
+		{
+			name:     "already resolved",
+			state:    querypb.TransactionState_UNKNOWN,
+			action:   vtctldatapb.ConcludeTransactionRequest_RESOLVE,
+			expected: querypb.TransactionState_UNKNOWN,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			mm := sandboxconn.NewSandboxConn(&topodatapb.Tablet{})
+			rm := sandboxconn.NewSandboxConn(&topodatapb.Tablet{})
+			if tt.state != querypb.TransactionState_UNKNOWN {
+				mm.ReadTransactionResults = []*querypb.TransactionMetadata{{
+					Dtid:         dtid,
+					State:        tt.state,
+					Participants: participants,
+				}}
+			}
+			setTestQueryServices(map[string]queryservice.QueryService{
+				"zone1-0000000100": mm,
+				"zone1-0000000200": rm,
+			})
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+			resp, err := vtctld.ConcludeTransaction(ctx, &vtctldatapb.ConcludeTransactionRequest{
+				Dtid:   dtid,
+				Action: tt.action,
+			})
+			if tt.shouldErr {
+				assert.Error(t, err)
+				assert.Nil(t, resp)
+				assert.EqualValues(t, 0, mm.ConcludeTransactionCount.Get())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.State)
+			assert.Equal(t, tt.setRollback, mm.SetRollbackCount.Get(), "SetRollback calls")
+			assert.Equal(t, tt.rollbackPrepared, rm.RollbackPreparedCount.Get(), "RollbackPrepared calls")
+			assert.Equal(t, tt.commitPrepared, rm.CommitPreparedCount.Get(), "CommitPrepared calls")
+			assert.Equal(t, tt.conclude, mm.ConcludeTransactionCount.Get(), "ConcludeTransaction calls")
+		})
+	}
+
+	t.Run("invalid dtid", func(t *testing.T) {
+		vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+			return NewVtctldServer(ts)
+		})
+		_, err := vtctld.ConcludeTransaction(ctx, &vtctldatapb.ConcludeTransactionRequest{
+			Dtid: "testkeyspace:-80",
+		})
+		assert.Error(t, err)
+	})
+}
+
 func TestCreateKeyspace(t *testing.T) {
 	t.Parallel()
 
@@ -3550,6 +3720,149 @@ func TestEmergencyReparentShard(t *testing.T) {
 	}
 }
 
+func TestEstimateSchemaMigration(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "testkeyspace",
+		Shard:    "-80",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+		Keyspace: "testkeyspace",
+		Shard:    "80-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	})
+
+	estimate1 := &tabletmanagerdatapb.SchemaMigrationEstimate{
+		Table:                "t",
+		DdlAction:            "alter",
+		TableRows:            1000,
+		TableSizeBytes:       65536,
+		RequiredDiskBytes:    65536,
+		FreeDiskBytes:        1 << 30,
+		CopyRowsPerSecond:    100,
+		EstimatedCopySeconds: 10,
+	}
+	estimate2 := &tabletmanagerdatapb.SchemaMigrationEstimate{
+		Table:                "t",
+		DdlAction:            "alter",
+		TableRows:            2000,
+		TableSizeBytes:       131072,
+		RequiredDiskBytes:    131072,
+		FreeDiskBytes:        1 << 30,
+		CopyRowsPerSecond:    100,
+		EstimatedCopySeconds: 20,
+	}
+
+	tests := []struct {
+		name      string
+		tmc       testutil.TabletManagerClient
+		req       *vtctldatapb.EstimateSchemaMigrationRequest
+		expected  *vtctldatapb.EstimateSchemaMigrationResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tmc: testutil.TabletManagerClient{
+				EstimateSchemaMigrationResults: map[string]struct {
+					Estimate *tabletmanagerdatapb.SchemaMigrationEstimate
+					Error    error
+				}{
+					"zone1-0000000100": {Estimate: estimate1},
+					"zone1-0000000200": {Estimate: estimate2},
+				},
+			},
+			req: &vtctldatapb.EstimateSchemaMigrationRequest{
+				Keyspace:    "testkeyspace",
+				Sql:         []string{"alter table t add column i int", "alter table t add column j int"},
+				DdlStrategy: "vitess",
+			},
+			expected: &vtctldatapb.EstimateSchemaMigrationResponse{
+				Estimates: []*vtctldatapb.ShardSchemaMigrationEstimate{
+					{Shard: "-80", Sql: "alter table t add column i int", Estimate: estimate1},
+					{Shard: "80-", Sql: "alter table t add column i int", Estimate: estimate2},
+					{Shard: "-80", Sql: "alter table t add column j int", Estimate: estimate1},
+					{Shard: "80-", Sql: "alter table t add column j int", Estimate: estimate2},
+				},
+			},
+		},
+		{
+			name: "tablet error",
+			tmc: testutil.TabletManagerClient{
+				EstimateSchemaMigrationResults: map[string]struct {
+					Estimate *tabletmanagerdatapb.SchemaMigrationEstimate
+					Error    error
+				}{
+					"zone1-0000000100": {Estimate: estimate1},
+					"zone1-0000000200": {Error: assert.AnError},
+				},
+			},
+			req: &vtctldatapb.EstimateSchemaMigrationRequest{
+				Keyspace:    "testkeyspace",
+				Sql:         []string{"alter table t add column i int"},
+				DdlStrategy: "vitess",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "direct strategy",
+			tmc:  testutil.TabletManagerClient{},
+			req: &vtctldatapb.EstimateSchemaMigrationRequest{
+				Keyspace:    "testkeyspace",
+				Sql:         []string{"alter table t add column i int"},
+				DdlStrategy: "direct",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "no sql",
+			tmc:  testutil.TabletManagerClient{},
+			req: &vtctldatapb.EstimateSchemaMigrationRequest{
+				Keyspace:    "testkeyspace",
+				DdlStrategy: "vitess",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "keyspace not found",
+			tmc:  testutil.TabletManagerClient{},
+			req: &vtctldatapb.EstimateSchemaMigrationRequest{
+				Keyspace:    "notfound",
+				Sql:         []string{"alter table t add column i int"},
+				DdlStrategy: "vitess",
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			resp, err := vtctld.EstimateSchemaMigration(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				assert.Nil(t, resp)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestExecuteFetchAsApp(t *testing.T) {
 	t.Parallel()
 
@@ -3988,6 +4301,46 @@ func TestExecuteHook(t *testing.T) {
 	}
 }
 
+func TestExportTopology(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name:     "testkeyspace",
+		Keyspace: &topodatapb.Keyspace{},
+	})
+	_, err := ts.GetOrCreateShard(ctx, "testkeyspace", "-")
+	require.NoError(t, err)
+
+	resp, err := vtctld.ExportTopology(ctx, &vtctldatapb.ExportTopologyRequest{
+		Cells: []string{topo.GlobalCell},
+	})
+	require.NoError(t, err)
+
+	var paths []string
+	for _, entry := range resp.Entries {
+		assert.Equal(t, topo.GlobalCell, entry.Cell)
+		assert.NotEmpty(t, entry.Version)
+		paths = append(paths, entry.Path)
+	}
+	assert.Equal(t, []string{
+		"/cells/zone1/CellInfo",
+		"/keyspaces/testkeyspace/Keyspace",
+		"/keyspaces/testkeyspace/VSchema",
+		"/keyspaces/testkeyspace/shards/-/Shard",
+	}, paths)
+
+	_, err = vtctld.ExportTopology(ctx, &vtctldatapb.ExportTopologyRequest{
+		Cells: []string{"nonexistent"},
+	})
+	assert.Error(t, err)
+}
+
 func TestFindAllShardsInKeyspace(t *testing.T) {
 	t.Parallel()
 
@@ -4261,6 +4614,94 @@ func TestGetCellsAliases(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetDriftCheckMismatches(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "testkeyspace",
+		Shard:    "-80",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+		Keyspace: "testkeyspace",
+		Shard:    "80-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	})
+	testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{Keyspace: "otherkeyspace", Name: "0"})
+
+	fields := sqltypes.MakeTestFields(
+		"table_name|lower_bound|upper_bound|tablet_alias|position|primary_row_count|primary_checksum|replica_row_count|replica_checksum|unix_timestamp(detected_timestamp)",
+		"varbinary|text|text|varbinary|text|uint64|varbinary|uint64|varbinary|int64",
+	)
+	tmc := &testutil.TabletManagerClient{
+		ExecuteFetchAsDbaResults: map[string]struct {
+			Response *querypb.QueryResult
+			Error    error
+		}{
+			"zone1-0000000100": {
+				Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields,
+					"t1|(1000)||zone1-0000000101|MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10|2|123|1|777|1650000100",
+					"t1||(1000)|zone1-0000000102|MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-5|1000|456|1000|789|1650000000",
+				)),
+			},
+			// The drift checker of the primary has not run yet.
+			"zone1-0000000200": {
+				Error: mysql.NewSQLError(mysql.ERNoSuchTable, mysql.SSUnknownTable, "Table '_vt.drift_check_mismatches' doesn't exist"),
+			},
+		},
+	}
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	resp, err := vtctld.GetDriftCheckMismatches(ctx, &vtctldatapb.GetDriftCheckMismatchesRequest{Keyspace: "testkeyspace"})
+	require.NoError(t, err)
+	expected := &vtctldatapb.GetDriftCheckMismatchesResponse{
+		Mismatches: []*vtctldatapb.DriftCheckMismatch{{
+			Keyspace:        "testkeyspace",
+			Shard:           "-80",
+			TableName:       "t1",
+			LowerBound:      "(1000)",
+			TabletAlias:     &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+			Position:        "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10",
+			PrimaryRowCount: 2,
+			PrimaryChecksum: "123",
+			ReplicaRowCount: 1,
+			ReplicaChecksum: "777",
+			DetectedAt:      protoutil.TimeToProto(time.Unix(1650000100, 0)),
+		}, {
+			Keyspace:        "testkeyspace",
+			Shard:           "-80",
+			TableName:       "t1",
+			UpperBound:      "(1000)",
+			TabletAlias:     &topodatapb.TabletAlias{Cell: "zone1", Uid: 102},
+			Position:        "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-5",
+			PrimaryRowCount: 1000,
+			PrimaryChecksum: "456",
+			ReplicaRowCount: 1000,
+			ReplicaChecksum: "789",
+			DetectedAt:      protoutil.TimeToProto(time.Unix(1650000000, 0)),
+		}},
+	}
+	utils.MustMatch(t, expected, resp)
+
+	resp, err = vtctld.GetDriftCheckMismatches(ctx, &vtctldatapb.GetDriftCheckMismatchesRequest{Keyspace: "testkeyspace", Shard: "80-"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Mismatches)
+
+	// A shard without a primary cannot be checked.
+	_, err = vtctld.GetDriftCheckMismatches(ctx, &vtctldatapb.GetDriftCheckMismatchesRequest{Keyspace: "otherkeyspace"})
+	assert.Error(t, err)
+
+	_, err = vtctld.GetDriftCheckMismatches(ctx, &vtctldatapb.GetDriftCheckMismatchesRequest{Keyspace: "testkeyspace", Shard: "-40"})
+	assert.Error(t, err)
+}
+
 func TestGetFullStatus(t *testing.T) {
 	t.Parallel()
 
@@ -4803,6 +5244,115 @@ func TestGetSchema(t *testing.T) {
 	}
 }
 
+func TestGetSchemaHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablet(ctx, t, ts, &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone1",
+			Uid:  100,
+		},
+		Keyspace: "testkeyspace",
+		Shard:    "-",
+	}, nil)
+
+	history := &tabletmanagerdatapb.GetSchemaHistoryResponse{
+		History: []*tabletmanagerdatapb.SchemaHistoryEntry{{
+			Id:          1,
+			Position:    "MySQL56/7b04699f-f5e9-11e9-bf88-9cb6d089e1c3:1-10",
+			Ddl:         "create table t1 (id int)",
+			TimeUpdated: 1000,
+			Table:       &binlogdatapb.MinimalTable{Name: "t1"},
+		}},
+		TableAtPosition: &binlogdatapb.MinimalTable{Name: "t1"},
+	}
+
+	tests := []struct {
+		name      string
+		tmc       testutil.TabletManagerClient
+		req       *vtctldatapb.GetSchemaHistoryRequest
+		expected  *vtctldatapb.GetSchemaHistoryResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tmc: testutil.TabletManagerClient{
+				GetSchemaHistoryResults: map[string]struct {
+					Response *tabletmanagerdatapb.GetSchemaHistoryResponse
+					Error    error
+				}{
+					"zone1-0000000100": {Response: history},
+				},
+			},
+			req: &vtctldatapb.GetSchemaHistoryRequest{
+				TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+				TableName:   "t1",
+				Position:    "MySQL56/7b04699f-f5e9-11e9-bf88-9cb6d089e1c3:1-12",
+			},
+			expected: &vtctldatapb.GetSchemaHistoryResponse{
+				History:         history.History,
+				TableAtPosition: history.TableAtPosition,
+			},
+		},
+		{
+			name: "no table name",
+			tmc:  testutil.TabletManagerClient{},
+			req: &vtctldatapb.GetSchemaHistoryRequest{
+				TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "tablet not found",
+			tmc:  testutil.TabletManagerClient{},
+			req: &vtctldatapb.GetSchemaHistoryRequest{
+				TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 404},
+				TableName:   "t1",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "tablet error",
+			tmc: testutil.TabletManagerClient{
+				GetSchemaHistoryResults: map[string]struct {
+					Response *tabletmanagerdatapb.GetSchemaHistoryResponse
+					Error    error
+				}{
+					"zone1-0000000100": {Error: assert.AnError},
+				},
+			},
+			req: &vtctldatapb.GetSchemaHistoryRequest{
+				TabletAlias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+				TableName:   "t1",
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			resp, err := vtctld.GetSchemaHistory(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				assert.Nil(t, resp)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestGetShard(t *testing.T) {
 	t.Parallel()
 
@@ -5957,6 +6507,46 @@ func TestGetTablets(t *testing.T) {
 	}
 }
 
+func TestGetTopologyAuditLog(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	_, err := vtctld.GetTopologyAuditLog(ctx, &vtctldatapb.GetTopologyAuditLogRequest{})
+	assert.Error(t, err, "the topology changes are not audited")
+
+	sink, err := topo.NewFileAuditSink(path.Join(t.TempDir(), "audit.log"))
+	require.NoError(t, err)
+	ts.SetAuditSink(sink)
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name:     "testkeyspace",
+		Keyspace: &topodatapb.Keyspace{},
+	})
+	_, err = ts.GetOrCreateShard(ctx, "testkeyspace", "-")
+	require.NoError(t, err)
+
+	resp, err := vtctld.GetTopologyAuditLog(ctx, &vtctldatapb.GetTopologyAuditLogRequest{
+		Path: "/keyspaces/testkeyspace/shards",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, topo.GlobalCell, resp.Events[0].Cell)
+	assert.Equal(t, "keyspaces/testkeyspace/shards/-/Shard", resp.Events[0].Path)
+	assert.Equal(t, "Create", resp.Events[0].Operation)
+	assert.NotNil(t, resp.Events[0].Time)
+
+	resp, err = vtctld.GetTopologyAuditLog(ctx, &vtctldatapb.GetTopologyAuditLogRequest{
+		Limit: 2,
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.Events, 2)
+}
+
 func TestGetTopologyPath(t *testing.T) {
 	t.Parallel()
 
@@ -6095,6 +6685,120 @@ func TestGetVSchema(t *testing.T) {
 	})
 }
 
+func TestListUnresolvedTransactions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+		AlsoSetShardPrimary: true,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "testkeyspace",
+		Shard:    "-80",
+		Type:     topodatapb.TabletType_PRIMARY,
+	}, &topodatapb.Tablet{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+		Keyspace: "testkeyspace",
+		Shard:    "80-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	})
+
+	tx1 := &querypb.TransactionMetadata{
+		Dtid:        "testkeyspace:-80:1",
+		State:       querypb.TransactionState_PREPARE,
+		TimeCreated: 200,
+		Participants: []*querypb.Target{{
+			Keyspace:   "testkeyspace",
+			Shard:      "80-",
+			TabletType: topodatapb.TabletType_PRIMARY,
+		}},
+	}
+	tx2 := &querypb.TransactionMetadata{
+		Dtid:        "testkeyspace:80-:2",
+		State:       querypb.TransactionState_COMMIT,
+		TimeCreated: 100,
+		Participants: []*querypb.Target{{
+			Keyspace:   "testkeyspace",
+			Shard:      "-80",
+			TabletType: topodatapb.TabletType_PRIMARY,
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		tmc       testutil.TabletManagerClient
+		req       *vtctldatapb.ListUnresolvedTransactionsRequest
+		expected  *vtctldatapb.ListUnresolvedTransactionsResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tmc: testutil.TabletManagerClient{
+				GetUnresolvedTransactionsResults: map[string]struct {
+					Transactions []*querypb.TransactionMetadata
+					Error        error
+				}{
+					"zone1-0000000100": {Transactions: []*querypb.TransactionMetadata{tx1}},
+					"zone1-0000000200": {Transactions: []*querypb.TransactionMetadata{tx2}},
+				},
+			},
+			req: &vtctldatapb.ListUnresolvedTransactionsRequest{
+				Keyspace:   "testkeyspace",
+				AbandonAge: 30,
+			},
+			expected: &vtctldatapb.ListUnresolvedTransactionsResponse{
+				Transactions: []*querypb.TransactionMetadata{tx2, tx1},
+			},
+		},
+		{
+			name: "tablet error",
+			tmc: testutil.TabletManagerClient{
+				GetUnresolvedTransactionsResults: map[string]struct {
+					Transactions []*querypb.TransactionMetadata
+					Error        error
+				}{
+					"zone1-0000000100": {Transactions: []*querypb.TransactionMetadata{tx1}},
+					"zone1-0000000200": {Error: assert.AnError},
+				},
+			},
+			req: &vtctldatapb.ListUnresolvedTransactionsRequest{
+				Keyspace: "testkeyspace",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "keyspace not found",
+			tmc:  testutil.TabletManagerClient{},
+			req: &vtctldatapb.ListUnresolvedTransactionsRequest{
+				Keyspace: "notfound",
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+
+			resp, err := vtctld.ListUnresolvedTransactions(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				assert.Nil(t, resp)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestPingTablet(t *testing.T) {
 	t.Parallel()
 
@@ -8394,6 +9098,63 @@ func TestRestoreFromBackup(t *testing.T) {
 	}
 }
 
+func TestRestoreTopology(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+		Name:     "testkeyspace",
+		Keyspace: &topodatapb.Keyspace{DurabilityPolicy: "none"},
+	})
+	resp, err := vtctld.ExportTopology(ctx, &vtctldatapb.ExportTopologyRequest{
+		Cells: []string{topo.GlobalCell},
+	})
+	require.NoError(t, err)
+	var keyspaceEntry *vtctldatapb.TopologyEntry
+	for _, entry := range resp.Entries {
+		if entry.Path == "/keyspaces/testkeyspace/Keyspace" {
+			keyspaceEntry = entry
+		}
+	}
+	require.NotNil(t, keyspaceEntry)
+
+	// Change the keyspace, then restore it to its exported value.
+	ki, err := ts.GetKeyspace(ctx, "testkeyspace")
+	require.NoError(t, err)
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	contents, err := ki.Keyspace.MarshalVT()
+	require.NoError(t, err)
+	ki.Keyspace.DurabilityPolicy = "semi_sync"
+	changed, err := ki.Keyspace.MarshalVT()
+	require.NoError(t, err)
+	_, version, err := conn.Get(ctx, keyspaceEntry.Path)
+	require.NoError(t, err)
+	version, err = conn.Update(ctx, keyspaceEntry.Path, changed, version)
+	require.NoError(t, err)
+
+	changes := []*vtctldatapb.TopologyChange{{
+		Cell:            topo.GlobalCell,
+		Path:            keyspaceEntry.Path,
+		Contents:        contents,
+		ExpectedVersion: version.String(),
+	}}
+	_, err = vtctld.RestoreTopology(ctx, &vtctldatapb.RestoreTopologyRequest{Changes: changes})
+	require.NoError(t, err)
+	ki, err = ts.GetKeyspace(ctx, "testkeyspace")
+	require.NoError(t, err)
+	assert.Equal(t, "none", ki.DurabilityPolicy)
+
+	// The version is now stale, so the same change is refused.
+	_, err = vtctld.RestoreTopology(ctx, &vtctldatapb.RestoreTopologyRequest{Changes: changes})
+	assert.Error(t, err)
+}
+
 func TestRunHealthCheck(t *testing.T) {
 	t.Parallel()
 
@@ -8497,6 +9258,111 @@ func TestRunHealthCheck(t *testing.T) {
 	}
 }
 
+func TestSetBackupRateLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		tablets   []*topodatapb.Tablet
+		tmc       testutil.TabletManagerClient
+		req       *vtctldatapb.SetBackupRateLimitsRequest
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  100,
+					},
+				},
+			},
+			tmc: testutil.TabletManagerClient{
+				SetBackupRateLimitsResults: map[string]error{
+					"zone1-0000000100": nil,
+				},
+			},
+			req: &vtctldatapb.SetBackupRateLimitsRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+				ReadBytesPerSecond:  10 * 1024 * 1024,
+				WriteBytesPerSecond: 20 * 1024 * 1024,
+			},
+		},
+		{
+			name: "no tablet",
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  404,
+					},
+				},
+			},
+			tmc: testutil.TabletManagerClient{
+				SetBackupRateLimitsResults: map[string]error{
+					"zone1-0000000100": nil,
+				},
+			},
+			req: &vtctldatapb.SetBackupRateLimitsRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "tmc call failed",
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  100,
+					},
+				},
+			},
+			tmc: testutil.TabletManagerClient{
+				SetBackupRateLimitsResults: map[string]error{
+					"zone1-0000000100": assert.AnError,
+				},
+			},
+			req: &vtctldatapb.SetBackupRateLimitsRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := memorytopo.NewServer("zone1")
+			testutil.AddTablets(ctx, t, ts, nil, tt.tablets...)
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+			_, err := vtctld.SetBackupRateLimits(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 	t.Parallel()
 
@@ -11924,3 +12790,54 @@ func TestValidateShard(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyBackup(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer()
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	testutil.BackupStorage.Backups = map[string][]string{
+		"testkeyspace/-": {"backup1", "backup2"},
+	}
+
+	t.Run("no backupstorage", func(t *testing.T) {
+		backupstorage.BackupStorageImplementation = "doesnotexist"
+		defer func() { backupstorage.BackupStorageImplementation = testutil.BackupStorageImplementation }()
+
+		_, err := vtctld.VerifyBackup(ctx, &vtctldatapb.VerifyBackupRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("listbackups error", func(t *testing.T) {
+		testutil.BackupStorage.ListBackupsError = assert.AnError
+		defer func() { testutil.BackupStorage.ListBackupsError = nil }()
+
+		_, err := vtctld.VerifyBackup(ctx, &vtctldatapb.VerifyBackupRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("backup not found", func(t *testing.T) {
+		_, err := vtctld.VerifyBackup(ctx, &vtctldatapb.VerifyBackupRequest{
+			Keyspace:    "testkeyspace",
+			Shard:       "-",
+			BackupNames: []string{"backup1", "backup3"},
+		})
+		assert.ErrorContains(t, err, "backup backup3 not found")
+	})
+
+	t.Run("no backups", func(t *testing.T) {
+		_, err := vtctld.VerifyBackup(ctx, &vtctldatapb.VerifyBackupRequest{
+			Keyspace: "otherkeyspace",
+			Shard:    "-",
+		})
+		assert.ErrorContains(t, err, "no backup to verify")
+	})
+}
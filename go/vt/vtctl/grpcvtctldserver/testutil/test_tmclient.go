@@ -194,6 +194,11 @@ type TabletManagerClient struct {
 		Error  error
 	}
 	// keyed by tablet alias.
+	EstimateSchemaMigrationResults map[string]struct {
+		Estimate *tabletmanagerdatapb.SchemaMigrationEstimate
+		Error    error
+	}
+	// keyed by tablet alias.
 	ExecuteFetchAsAppDelays map[string]time.Duration
 	// keyed by tablet alias.
 	ExecuteFetchAsAppResults map[string]struct {
@@ -224,6 +229,11 @@ type TabletManagerClient struct {
 		Error       error
 	}
 	// keyed by tablet alias.
+	GetUnresolvedTransactionsResults map[string]struct {
+		Transactions []*querypb.TransactionMetadata
+		Error        error
+	}
+	// keyed by tablet alias.
 	GetReplicasResults map[string]struct {
 		Replicas []string
 		Error    error
@@ -236,6 +246,11 @@ type TabletManagerClient struct {
 		Error  error
 	}
 	// keyed by tablet alias.
+	GetSchemaHistoryResults map[string]struct {
+		Response *tabletmanagerdatapb.GetSchemaHistoryResponse
+		Error    error
+	}
+	// keyed by tablet alias.
 	InitPrimaryDelays map[string]time.Duration
 	// keyed by tablet alias. injects a sleep to the end of the function
 	// regardless of parent context timeout or error result.
@@ -291,6 +306,8 @@ type TabletManagerClient struct {
 	RunHealthCheckDelays map[string]time.Duration
 	// keyed by tablet alias
 	RunHealthCheckResults map[string]error
+	// keyed by tablet alias
+	SetBackupRateLimitsResults map[string]error
 	// keyed by tablet alias.
 	SetReplicationSourceDelays map[string]time.Duration
 	// keyed by tablet alias.
@@ -487,6 +504,20 @@ func (fake *TabletManagerClient) DemotePrimary(ctx context.Context, tablet *topo
 	return nil, assert.AnError
 }
 
+// EstimateSchemaMigration is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) EstimateSchemaMigration(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.EstimateSchemaMigrationRequest) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	if fake.EstimateSchemaMigrationResults == nil {
+		return nil, fmt.Errorf("%w: no EstimateSchemaMigration results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.EstimateSchemaMigrationResults[key]; ok {
+		return result.Estimate, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no EstimateSchemaMigration result set for tablet %s", assert.AnError, key)
+}
+
 // ExecuteFetchAsApp is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) ExecuteFetchAsApp(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, req *tabletmanagerdatapb.ExecuteFetchAsAppRequest) (*querypb.QueryResult, error) {
 	if fake.ExecuteFetchAsAppResults == nil {
@@ -602,6 +633,20 @@ func (fake *TabletManagerClient) GetPermissions(ctx context.Context, tablet *top
 	return nil, fmt.Errorf("%w: no permissions for %s", assert.AnError, key)
 }
 
+// GetUnresolvedTransactions is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) GetUnresolvedTransactions(ctx context.Context, tablet *topodatapb.Tablet, abandonAge int64) ([]*querypb.TransactionMetadata, error) {
+	if fake.GetUnresolvedTransactionsResults == nil {
+		return nil, fmt.Errorf("%w: no GetUnresolvedTransactions results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.GetUnresolvedTransactionsResults[key]; ok {
+		return result.Transactions, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no GetUnresolvedTransactions result set for tablet %s", assert.AnError, key)
+}
+
 // GetReplicas is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) GetReplicas(ctx context.Context, tablet *topodatapb.Tablet) ([]string, error) {
 	if fake.GetReplicasResults == nil {
@@ -646,6 +691,20 @@ func (fake *TabletManagerClient) GetSchema(ctx context.Context, tablet *topodata
 	return nil, fmt.Errorf("%w: no schemas for %s", assert.AnError, key)
 }
 
+// GetSchemaHistory is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) GetSchemaHistory(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaHistoryRequest) (*tabletmanagerdatapb.GetSchemaHistoryResponse, error) {
+	if fake.GetSchemaHistoryResults == nil {
+		return nil, fmt.Errorf("%w: no GetSchemaHistory results on fake TabletManagerClient", assert.AnError)
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if result, ok := fake.GetSchemaHistoryResults[key]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no GetSchemaHistory result set for tablet %s", assert.AnError, key)
+}
+
 // InitPrimary is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) InitPrimary(ctx context.Context, tablet *topodatapb.Tablet, semiSync bool) (string, error) {
 	if fake.InitPrimaryResults == nil {
@@ -994,6 +1053,20 @@ func (fake *TabletManagerClient) RunHealthCheck(ctx context.Context, tablet *top
 	return fmt.Errorf("%w: no result for key %s", assert.AnError, key)
 }
 
+// SetBackupRateLimits is part of the tmclient.TabletManagerClient interface.
+func (fake *TabletManagerClient) SetBackupRateLimits(ctx context.Context, tablet *topodatapb.Tablet, read, write int64) error {
+	if fake.SetBackupRateLimitsResults == nil {
+		return assert.AnError
+	}
+
+	key := topoproto.TabletAliasString(tablet.Alias)
+	if err, ok := fake.SetBackupRateLimitsResults[key]; ok {
+		return err
+	}
+
+	return fmt.Errorf("%w: no result for key %s", assert.AnError, key)
+}
+
 // SetReplicationSource is part of the tmclient.TabletManagerClient interface.
 func (fake *TabletManagerClient) SetReplicationSource(ctx context.Context, tablet *topodatapb.Tablet, parent *topodatapb.TabletAlias, timeCreatedNS int64, waitPosition string, forceStartReplication bool, semiSync bool) error {
 	if fake.SetReplicationSourceResults == nil {
@@ -166,6 +166,11 @@ func (client *localVtctldClient) ChangeTabletType(ctx context.Context, in *vtctl
 	return client.s.ChangeTabletType(ctx, in)
 }
 
+// ConcludeTransaction is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ConcludeTransaction(ctx context.Context, in *vtctldatapb.ConcludeTransactionRequest, opts ...grpc.CallOption) (*vtctldatapb.ConcludeTransactionResponse, error) {
+	return client.s.ConcludeTransaction(ctx, in)
+}
+
 // CreateKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) CreateKeyspace(ctx context.Context, in *vtctldatapb.CreateKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.CreateKeyspaceResponse, error) {
 	return client.s.CreateKeyspace(ctx, in)
@@ -211,6 +216,11 @@ func (client *localVtctldClient) EmergencyReparentShard(ctx context.Context, in
 	return client.s.EmergencyReparentShard(ctx, in)
 }
 
+// EstimateSchemaMigration is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) EstimateSchemaMigration(ctx context.Context, in *vtctldatapb.EstimateSchemaMigrationRequest, opts ...grpc.CallOption) (*vtctldatapb.EstimateSchemaMigrationResponse, error) {
+	return client.s.EstimateSchemaMigration(ctx, in)
+}
+
 // ExecuteFetchAsApp is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) ExecuteFetchAsApp(ctx context.Context, in *vtctldatapb.ExecuteFetchAsAppRequest, opts ...grpc.CallOption) (*vtctldatapb.ExecuteFetchAsAppResponse, error) {
 	return client.s.ExecuteFetchAsApp(ctx, in)
@@ -226,6 +236,11 @@ func (client *localVtctldClient) ExecuteHook(ctx context.Context, in *vtctldatap
 	return client.s.ExecuteHook(ctx, in)
 }
 
+// ExportTopology is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ExportTopology(ctx context.Context, in *vtctldatapb.ExportTopologyRequest, opts ...grpc.CallOption) (*vtctldatapb.ExportTopologyResponse, error) {
+	return client.s.ExportTopology(ctx, in)
+}
+
 // FindAllShardsInKeyspace is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) FindAllShardsInKeyspace(ctx context.Context, in *vtctldatapb.FindAllShardsInKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.FindAllShardsInKeyspaceResponse, error) {
 	return client.s.FindAllShardsInKeyspace(ctx, in)
@@ -251,6 +266,11 @@ func (client *localVtctldClient) GetCellsAliases(ctx context.Context, in *vtctld
 	return client.s.GetCellsAliases(ctx, in)
 }
 
+// GetDriftCheckMismatches is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetDriftCheckMismatches(ctx context.Context, in *vtctldatapb.GetDriftCheckMismatchesRequest, opts ...grpc.CallOption) (*vtctldatapb.GetDriftCheckMismatchesResponse, error) {
+	return client.s.GetDriftCheckMismatches(ctx, in)
+}
+
 // GetFullStatus is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetFullStatus(ctx context.Context, in *vtctldatapb.GetFullStatusRequest, opts ...grpc.CallOption) (*vtctldatapb.GetFullStatusResponse, error) {
 	return client.s.GetFullStatus(ctx, in)
@@ -281,6 +301,11 @@ func (client *localVtctldClient) GetSchema(ctx context.Context, in *vtctldatapb.
 	return client.s.GetSchema(ctx, in)
 }
 
+// GetSchemaHistory is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetSchemaHistory(ctx context.Context, in *vtctldatapb.GetSchemaHistoryRequest, opts ...grpc.CallOption) (*vtctldatapb.GetSchemaHistoryResponse, error) {
+	return client.s.GetSchemaHistory(ctx, in)
+}
+
 // GetShard is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetShard(ctx context.Context, in *vtctldatapb.GetShardRequest, opts ...grpc.CallOption) (*vtctldatapb.GetShardResponse, error) {
 	return client.s.GetShard(ctx, in)
@@ -321,6 +346,11 @@ func (client *localVtctldClient) GetTablets(ctx context.Context, in *vtctldatapb
 	return client.s.GetTablets(ctx, in)
 }
 
+// GetTopologyAuditLog is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetTopologyAuditLog(ctx context.Context, in *vtctldatapb.GetTopologyAuditLogRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTopologyAuditLogResponse, error) {
+	return client.s.GetTopologyAuditLog(ctx, in)
+}
+
 // GetTopologyPath is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetTopologyPath(ctx context.Context, in *vtctldatapb.GetTopologyPathRequest, opts ...grpc.CallOption) (*vtctldatapb.GetTopologyPathResponse, error) {
 	return client.s.GetTopologyPath(ctx, in)
@@ -346,6 +376,11 @@ func (client *localVtctldClient) InitShardPrimary(ctx context.Context, in *vtctl
 	return client.s.InitShardPrimary(ctx, in)
 }
 
+// ListUnresolvedTransactions is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ListUnresolvedTransactions(ctx context.Context, in *vtctldatapb.ListUnresolvedTransactionsRequest, opts ...grpc.CallOption) (*vtctldatapb.ListUnresolvedTransactionsResponse, error) {
+	return client.s.ListUnresolvedTransactions(ctx, in)
+}
+
 // PingTablet is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) PingTablet(ctx context.Context, in *vtctldatapb.PingTabletRequest, opts ...grpc.CallOption) (*vtctldatapb.PingTabletResponse, error) {
 	return client.s.PingTablet(ctx, in)
@@ -462,11 +497,21 @@ func (client *localVtctldClient) RestoreFromBackup(ctx context.Context, in *vtct
 	return stream, nil
 }
 
+// RestoreTopology is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) RestoreTopology(ctx context.Context, in *vtctldatapb.RestoreTopologyRequest, opts ...grpc.CallOption) (*vtctldatapb.RestoreTopologyResponse, error) {
+	return client.s.RestoreTopology(ctx, in)
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) RunHealthCheck(ctx context.Context, in *vtctldatapb.RunHealthCheckRequest, opts ...grpc.CallOption) (*vtctldatapb.RunHealthCheckResponse, error) {
 	return client.s.RunHealthCheck(ctx, in)
 }
 
+// SetBackupRateLimits is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) SetBackupRateLimits(ctx context.Context, in *vtctldatapb.SetBackupRateLimitsRequest, opts ...grpc.CallOption) (*vtctldatapb.SetBackupRateLimitsResponse, error) {
+	return client.s.SetBackupRateLimits(ctx, in)
+}
+
 // SetKeyspaceDurabilityPolicy is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) SetKeyspaceDurabilityPolicy(ctx context.Context, in *vtctldatapb.SetKeyspaceDurabilityPolicyRequest, opts ...grpc.CallOption) (*vtctldatapb.SetKeyspaceDurabilityPolicyResponse, error) {
 	return client.s.SetKeyspaceDurabilityPolicy(ctx, in)
@@ -586,3 +631,8 @@ func (client *localVtctldClient) ValidateVersionKeyspace(ctx context.Context, in
 func (client *localVtctldClient) ValidateVersionShard(ctx context.Context, in *vtctldatapb.ValidateVersionShardRequest, opts ...grpc.CallOption) (*vtctldatapb.ValidateVersionShardResponse, error) {
 	return client.s.ValidateVersionShard(ctx, in)
 }
+
+// VerifyBackup is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) VerifyBackup(ctx context.Context, in *vtctldatapb.VerifyBackupRequest, opts ...grpc.CallOption) (*vtctldatapb.VerifyBackupResponse, error) {
+	return client.s.VerifyBackup(ctx, in)
+}
@@ -62,8 +62,8 @@ func commandVDiff2(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.F
 	maxExtraRowsToCompare := subFlags.Int64("max_extra_rows_to_compare", 1000, "If there are collation differences between the source and target, you can have rows that are identical but simply returned in a different order from MySQL. We will do a second pass to compare the rows for any actual differences in this case and this flag allows you to control the resources used for this operation.")
 
 	autoRetry := subFlags.Bool("auto-retry", true, "Should this vdiff automatically retry and continue in case of recoverable errors")
-	checksum := subFlags.Bool("checksum", false, "Use row-level checksums to compare, not yet implemented")
-	samplePct := subFlags.Int64("sample_pct", 100, "How many rows to sample, not yet implemented")
+	checksum := subFlags.Bool("checksum", false, "Compare checksums of chunks of rows, and only compare the rows of the chunks whose checksums differ")
+	samplePct := subFlags.Int64("sample_pct", 100, "The percentage of chunks of rows to compare, chosen at random (1 to 100)")
 	verbose := subFlags.Bool("verbose", false, "Show verbose vdiff output in summaries")
 	wait := subFlags.Bool("wait", false, "When creating or resuming a vdiff, wait for it to finish before exiting")
 	waitUpdateInterval := subFlags.Duration("wait-update-interval", time.Duration(1*time.Minute), "When waiting on a vdiff to finish, check and display the current status this often")
@@ -102,6 +102,9 @@ func commandVDiff2(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.F
 	if *maxRows <= 0 {
 		return fmt.Errorf("invalid --limit value (%d), maximum number of rows to compare needs to be greater than 0", *maxRows)
 	}
+	if *samplePct < 1 || *samplePct > 100 {
+		return fmt.Errorf("invalid --sample_pct value (%d), the percentage of rows to compare needs to be between 1 and 100", *samplePct)
+	}
 
 	options := &tabletmanagerdatapb.VDiffOptions{
 		PickerOptions: &tabletmanagerdatapb.VDiffPickerOptions{
@@ -231,6 +234,7 @@ type vdiffTableSummary struct {
 	MismatchedRows  int64
 	ExtraRowsSource int64
 	ExtraRowsTarget int64
+	SkippedRows     int64  `json:"SkippedRows,omitempty"`
 	LastUpdated     string `json:"LastUpdated,omitempty"`
 }
 type vdiffSummary struct {
@@ -269,7 +273,8 @@ Table {{$table.TableName}}:
 	MatchingRows:     {{$table.MatchingRows}}
 {{if $table.MismatchedRows}}	MismatchedRows:   {{$table.MismatchedRows}}{{end}}
 {{if $table.ExtraRowsSource}}	ExtraRowsSource:  {{$table.ExtraRowsSource}}{{end}}
-{{if $table.ExtraRowsTarget}}	ExtraRowsTarget:  {{$table.ExtraRowsTarget}}{{end}}
+{{if $table.ExtraRowsTarget}}	ExtraRowsTarget:  {{$table.ExtraRowsTarget}}{{end}}{{if $table.SkippedRows}}
+	SkippedRows:      {{$table.SkippedRows}}{{end}}
 {{end}}
  
 Use "--format=json" for more detailed output.
@@ -559,6 +564,7 @@ func buildVDiff2SingleSummary(wr *wrangler.Wrangler, keyspace, workflow, uuid st
 						ts.MatchingRows += dr.MatchingRows
 						ts.ExtraRowsTarget += dr.ExtraRowsTarget
 						ts.ExtraRowsSource += dr.ExtraRowsSource
+						ts.SkippedRows += dr.SkippedRows
 					}
 					if _, ok := reports[table]; !ok {
 						reports[table] = make(map[string]vdiff.DiffReport)
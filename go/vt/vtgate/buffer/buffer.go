@@ -20,6 +20,10 @@ limitations under the License.
 // becomes unavailable), the buffer will automatically retry buffered requests
 // after the end of the failover was detected.
 //
+// Similarly, requests to tables whose traffic is switched to another keyspace
+// (e.g. by MoveTables SwitchTraffic for writes) are buffered until the new
+// routing rules are seen, and then re-planned and retried.
+//
 // Buffering (stalling) requests will increase the number of requests in flight
 // within vtgate and at upstream layers. Therefore, it is important to limit
 // the size of the buffer and the buffering duration (window) per request.
@@ -29,6 +33,7 @@ package buffer
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"vitess.io/vitess/go/sync2"
@@ -60,6 +65,10 @@ const (
 	bufferModeDryRun
 )
 
+// deniedTablesRule is the description of the query rule with which vttablet
+// denies tables on a shard, e.g. while MoveTables switches writes.
+const deniedTablesRule = "enforce denied tables"
+
 // RetryDoneFunc will be returned for each buffered request and must be called
 // after the buffered request was retried.
 // Without this signal, the buffer would not know how many buffered requests are
@@ -74,6 +83,13 @@ func CausedByFailover(err error) bool {
 	return vterrors.Code(err) == vtrpcpb.Code_CLUSTER_EVENT
 }
 
+// CausedByDeniedTables returns true if "err" was caused by a table being
+// denied on the primary of its keyspace, as is the case while its traffic is
+// switched to another keyspace (e.g. by MoveTables SwitchTraffic for writes).
+func CausedByDeniedTables(err error) bool {
+	return vterrors.Code(err) == vtrpcpb.Code_FAILED_PRECONDITION && strings.Contains(err.Error(), deniedTablesRule)
+}
+
 // Buffer is used to track ongoing PRIMARY tablet failovers and buffer
 // requests while the PRIMARY tablet is unavailable.
 // Once the new PRIMARY starts accepting requests, buffering stops and requests
@@ -100,6 +116,10 @@ type Buffer struct {
 	// progress.
 	// Key Format: "<keyspace>/<shard>"
 	buffers map[string]*shardBuffer
+	// tableBuffers holds a tableBuffer object per table for which a table switch
+	// was seen, even if no switch is in progress.
+	// Key Format: "<keyspace>.<table>"
+	tableBuffers map[string]*tableBuffer
 	// stopped is true after Shutdown() was run.
 	stopped bool
 }
@@ -110,6 +130,7 @@ func New(cfg *Config) *Buffer {
 		config:         cfg,
 		bufferSizeSema: sync2.NewSemaphore(cfg.Size, 0),
 		buffers:        make(map[string]*shardBuffer),
+		tableBuffers:   make(map[string]*tableBuffer),
 	}
 }
 
@@ -171,6 +192,84 @@ func (b *Buffer) HandleKeyspaceEvent(ksevent *discovery.KeyspaceEvent) {
 	}
 }
 
+// WaitForRoutingRulesChange blocks a request which failed because one of its
+// tables was denied, until the routing rules of one of these tables change
+// (see HandleRoutingRulesChange). The tables are given as "<keyspace>.<table>",
+// as found in the plan of the request.
+// If "err" is not caused by denied tables, the request is not buffered.
+// It returns an error if buffering failed (e.g. buffer full).
+// If it does not return an error, it may return a RetryDoneFunc which must be
+// called after the request was re-planned and retried.
+func (b *Buffer) WaitForRoutingRulesChange(ctx context.Context, tables []string, err error) (RetryDoneFunc, error) {
+	if !CausedByDeniedTables(err) {
+		return nil, nil
+	}
+
+	var tbs []*tableBuffer
+	for _, table := range tables {
+		keyspace, name, ok := strings.Cut(table, ".")
+		if !ok {
+			continue
+		}
+		tb := b.getOrCreateTableBuffer(keyspace, name)
+		if tb == nil {
+			// Buffer is shut down. Ignore all calls.
+			tableRequestsSkipped.Add([]string{keyspace, name, skippedShutdown}, 1)
+			return nil, nil
+		}
+		if tb.mode == bufferModeDisabled {
+			tableRequestsSkipped.Add([]string{keyspace, name, skippedDisabled}, 1)
+			continue
+		}
+		tbs = append(tbs, tb)
+	}
+	if len(tbs) == 0 {
+		return nil, nil
+	}
+
+	return b.waitForRoutingRulesChange(ctx, tbs, err)
+}
+
+// HandleRoutingRulesChange notifies the buffer that the routing rules of the
+// given tables ("<keyspace>.<table>") changed, and ends any buffering for them.
+func (b *Buffer) HandleRoutingRulesChange(tables []string) {
+	for _, table := range tables {
+		b.mu.RLock()
+		tb, ok := b.tableBuffers[table]
+		b.mu.RUnlock()
+		if ok {
+			tb.recordRoutingRulesChange()
+		}
+	}
+}
+
+// getOrCreateTableBuffer returns the tableBuffer for the given keyspace and table.
+// It returns nil if Buffer is shut down and all calls should be ignored.
+func (b *Buffer) getOrCreateTableBuffer(keyspace, table string) *tableBuffer {
+	key := keyspace + "." + table
+	b.mu.RLock()
+	tb, ok := b.tableBuffers[key]
+	stopped := b.stopped
+	b.mu.RUnlock()
+
+	if stopped {
+		return nil
+	}
+	if ok {
+		return tb
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Look it up again because it could have been created in the meantime.
+	tb, ok = b.tableBuffers[key]
+	if !ok {
+		tb = newTableBuffer(b, b.config.tableBufferingMode(keyspace), keyspace, table)
+		b.tableBuffers[key] = tb
+	}
+	return tb
+}
+
 // getOrCreateBuffer returns the ShardBuffer for the given keyspace and shard.
 // It returns nil if Buffer is shut down and all calls should be ignored.
 func (b *Buffer) getOrCreateBuffer(keyspace, shard string) *shardBuffer {
@@ -213,6 +312,9 @@ func (b *Buffer) shutdown() {
 	for _, sb := range b.buffers {
 		sb.shutdown()
 	}
+	for _, tb := range b.tableBuffers {
+		tb.shutdown()
+	}
 	b.stopped = true
 }
 
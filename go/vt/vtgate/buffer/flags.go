@@ -37,6 +37,7 @@ var (
 	bufferSize                    = 1000
 	bufferMaxFailoverDuration     = 20 * time.Second
 	bufferMinTimeBetweenFailovers = time.Minute
+	bufferMaxTableSwitchDuration  = 20 * time.Second
 
 	bufferDrainConcurrency = 1
 	bufferKeyspaceShards   string
@@ -50,6 +51,7 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&bufferSize, "buffer_size", 1000, "Maximum number of buffered requests in flight (across all ongoing failovers).")
 	fs.DurationVar(&bufferMaxFailoverDuration, "buffer_max_failover_duration", 20*time.Second, "Stop buffering completely if a failover takes longer than this duration.")
 	fs.DurationVar(&bufferMinTimeBetweenFailovers, "buffer_min_time_between_failovers", 1*time.Minute, "Minimum time between the end of a failover and the start of the next one (tracked per shard). Faster consecutive failovers will not trigger buffering.")
+	fs.DurationVar(&bufferMaxTableSwitchDuration, "buffer_max_table_switch_duration", 20*time.Second, "Stop buffering requests to a table completely if switching its traffic to another keyspace (e.g. MoveTables SwitchTraffic for writes) takes longer than this duration.")
 
 	fs.IntVar(&bufferDrainConcurrency, "buffer_drain_concurrency", 1, "Maximum number of requests retried simultaneously. More concurrency will increase the load on the PRIMARY vttablet when draining the buffer.")
 	fs.StringVar(&bufferKeyspaceShards, "buffer_keyspace_shards", "", "If not empty, limit buffering to these entries (comma separated). Entry format: keyspace or keyspace/shard. Requires --enable_buffer=true.")
@@ -74,6 +76,10 @@ func verifyFlags() error {
 		return fmt.Errorf("--buffer_min_time_between_failovers should be at least twice the length of --buffer_max_failover_duration: %v vs. %v", bufferMinTimeBetweenFailovers, bufferMaxFailoverDuration)
 	}
 
+	if bufferMaxTableSwitchDuration < 1*time.Second {
+		return fmt.Errorf("--buffer_max_table_switch_duration must be >= 1s (specified value: %v)", bufferMaxTableSwitchDuration)
+	}
+
 	if bufferDrainConcurrency < 1 {
 		return fmt.Errorf("--buffer_drain_concurrency must be >= 1 (specified value: %d)", bufferDrainConcurrency)
 	}
@@ -139,6 +145,9 @@ type Config struct {
 	Size                    int
 	MaxFailoverDuration     time.Duration
 	MinTimeBetweenFailovers time.Duration
+	// MaxTableSwitchDuration is the maximum duration of buffering while
+	// switching a table's traffic to another keyspace.
+	MaxTableSwitchDuration time.Duration
 
 	DrainConcurrency int
 
@@ -160,6 +169,7 @@ func NewDefaultConfig() *Config {
 		Window:                  10 * time.Second,
 		MaxFailoverDuration:     20 * time.Second,
 		MinTimeBetweenFailovers: 1 * time.Minute,
+		MaxTableSwitchDuration:  20 * time.Second,
 		DrainConcurrency:        1,
 		now:                     time.Now,
 	}
@@ -213,6 +223,7 @@ func NewConfigFromFlags() *Config {
 		Size:                    bufferSize,
 		MaxFailoverDuration:     bufferMaxFailoverDuration,
 		MinTimeBetweenFailovers: bufferMinTimeBetweenFailovers,
+		MaxTableSwitchDuration:  bufferMaxTableSwitchDuration,
 
 		DrainConcurrency: bufferDrainConcurrency,
 
@@ -246,3 +257,26 @@ func (cfg *Config) bufferingMode(keyspace, shard string) bufferMode {
 
 	return bufferModeDisabled
 }
+
+// tableBufferingMode returns how requests to the tables of the given keyspace
+// are buffered during table switches. Buffering is enabled if it is enabled for
+// all keyspaces, for this keyspace, or for any of its shards.
+func (cfg *Config) tableBufferingMode(keyspace string) bufferMode {
+	if cfg.Enabled && len(cfg.Keyspaces) == 0 && len(cfg.Shards) == 0 {
+		return bufferModeEnabled
+	}
+	if cfg.Keyspaces[keyspace] {
+		return bufferModeEnabled
+	}
+	for keyspaceShard := range cfg.Shards {
+		if ks, _, err := topoproto.ParseKeyspaceShard(keyspaceShard); err == nil && ks == keyspace {
+			return bufferModeEnabled
+		}
+	}
+
+	if cfg.DryRun {
+		return bufferModeDryRun
+	}
+
+	return bufferModeDisabled
+}
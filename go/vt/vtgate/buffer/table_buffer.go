@@ -0,0 +1,254 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buffer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/errorsanitizer"
+)
+
+// tableBuffer buffers requests for a particular table while its traffic is
+// switched to another keyspace, e.g. by MoveTables SwitchTraffic for writes.
+// The switch denies the table on the primaries of its current keyspace before
+// the routing rules are updated. Requests which fail in the meantime are held
+// until the vtgate sees the new routing rules, and are then retried.
+//
+// Unlike shardBuffer, there is no queue and no draining phase: all buffered
+// requests are unblocked at once when buffering stops. The number of buffered
+// requests is bounded by the buffer size shared with the shard buffers.
+type tableBuffer struct {
+	// Immutable fields set at construction.
+	buf      *Buffer
+	mode     bufferMode
+	keyspace string
+	table    string
+
+	// statsKey is used to update the stats variables.
+	statsKey []string
+
+	// mu guards the fields below.
+	mu    sync.Mutex
+	state bufferState
+	// released is closed when buffering stops, to unblock all buffered requests.
+	released chan struct{}
+	// lastStart is the last time we started buffering.
+	lastStart time.Time
+	// lastEnd is the last time we stopped buffering.
+	lastEnd time.Time
+	// maxDuration enforces that buffering stops after
+	// --buffer_max_table_switch_duration at most.
+	maxDuration *time.Timer
+}
+
+func newTableBuffer(buf *Buffer, mode bufferMode, keyspace, table string) *tableBuffer {
+	statsKey := []string{keyspace, table}
+	initVariablesForTable(statsKey)
+
+	return &tableBuffer{
+		buf:      buf,
+		mode:     mode,
+		keyspace: keyspace,
+		table:    table,
+		statsKey: statsKey,
+		state:    stateIdle,
+	}
+}
+
+func (tb *tableBuffer) timeNow() time.Time {
+	return tb.buf.config.now()
+}
+
+// startBuffering starts buffering if not yet done, and returns the channel which
+// will be closed when buffering stops. It returns nil if the request should not
+// be buffered.
+func (tb *tableBuffer) startBuffering(err error) <-chan struct{} {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	if tb.state == stateIdle {
+		// Do not buffer if the last switch is too recent. This is the case when
+		// buffering stopped because of --buffer_max_table_switch_duration and the
+		// table is still denied, e.g. because the switch was canceled.
+		now := tb.timeNow()
+		minTimeBetweenSwitches := tb.buf.config.MinTimeBetweenFailovers
+		lastBufferingStopped := now.Sub(tb.lastEnd)
+		if !tb.lastEnd.IsZero() && lastBufferingStopped < minTimeBetweenSwitches {
+			statsKeyWithReason := append(tb.statsKey, string(skippedLastFailoverTooRecent))
+			tableRequestsSkipped.Add(statsKeyWithReason, 1)
+			return nil
+		}
+		tb.startBufferingLocked(err)
+	}
+
+	if tb.mode == bufferModeDryRun {
+		// Dry-run. Do not actually buffer the request.
+		tableRequestsBufferedDryRun.Add(tb.statsKey, 1)
+		return nil
+	}
+	return tb.released
+}
+
+func (tb *tableBuffer) startBufferingLocked(err error) {
+	tb.lastStart = tb.timeNow()
+	tb.state = stateBuffering
+	tb.released = make(chan struct{})
+	tb.maxDuration = time.AfterFunc(tb.buf.config.MaxTableSwitchDuration, tb.stopBufferingDueToMaxDuration)
+
+	msg := "Starting buffering"
+	if tb.mode == bufferModeDryRun {
+		msg = "Dry-run: Would have started buffering"
+	}
+	tableStarts.Add(tb.statsKey, 1)
+	log.Infof("%v for table: %s.%s (window: %v, size: %v, max table switch duration: %v) (A table switch was detected by this seen error: %v.)",
+		msg,
+		tb.keyspace, tb.table,
+		tb.buf.config.Window,
+		tb.buf.config.Size,
+		tb.buf.config.MaxTableSwitchDuration,
+		errorsanitizer.NormalizeError(err.Error()),
+	)
+}
+
+func (tb *tableBuffer) stopBufferingDueToMaxDuration() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.stopBufferingLocked(stopMaxFailoverDurationExceeded,
+		fmt.Sprintf("stopping buffering because the table switch did not finish in time (%v)", tb.buf.config.MaxTableSwitchDuration))
+}
+
+func (tb *tableBuffer) recordRoutingRulesChange() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.stopBufferingLocked(stopRoutingRulesChanged, "new routing rules have been seen")
+}
+
+func (tb *tableBuffer) stopBufferingLocked(reason stopReason, details string) {
+	if tb.state != stateBuffering {
+		return
+	}
+
+	tb.lastEnd = tb.timeNow()
+	d := tb.lastEnd.Sub(tb.lastStart)
+	tb.maxDuration.Stop()
+
+	statsKeyWithReason := append(tb.statsKey, string(reason))
+	tableStops.Add(statsKeyWithReason, 1)
+	tableLastSwitchDurationMs.Set(tb.statsKey, int64(d/time.Millisecond))
+
+	msg := "Stopping buffering"
+	if tb.mode == bufferModeDryRun {
+		msg = "Dry-run: Would have stopped buffering"
+	}
+	log.Infof("%v for table: %s.%s after: %.1f seconds due to: %v.", msg, tb.keyspace, tb.table, d.Seconds(), details)
+
+	tb.state = stateIdle
+	close(tb.released)
+}
+
+func (tb *tableBuffer) shutdown() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.stopBufferingLocked(stopShutdown, "shutdown")
+}
+
+// testGetState is used by unit tests only to probe the current state.
+func (tb *tableBuffer) testGetState() bufferState {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return tb.state
+}
+
+// waitForRoutingRulesChange blocks until buffering stops for any of the given
+// tables, or until the request exceeds its buffering window.
+// See Buffer.WaitForRoutingRulesChange() for the API contract of the return values.
+func (b *Buffer) waitForRoutingRulesChange(ctx context.Context, tbs []*tableBuffer, err error) (RetryDoneFunc, error) {
+	var buffering []*tableBuffer
+	var released []<-chan struct{}
+	for _, tb := range tbs {
+		if ch := tb.startBuffering(err); ch != nil {
+			buffering = append(buffering, tb)
+			released = append(released, ch)
+		}
+	}
+	if len(released) == 0 {
+		return nil, nil
+	}
+
+	if !b.bufferSizeSema.TryAcquire() {
+		for _, tb := range buffering {
+			statsKeyWithReason := append(tb.statsKey, string(skippedBufferFull))
+			tableRequestsSkipped.Add(statsKeyWithReason, 1)
+		}
+		return nil, bufferFullError
+	}
+	for _, tb := range buffering {
+		tableRequestsBuffered.Add(tb.statsKey, 1)
+	}
+
+	// Merge the channels of all tables: the request can be retried as soon as
+	// one of its tables was switched.
+	anyReleased := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	var once sync.Once
+	for _, ch := range released {
+		go func(ch <-chan struct{}) {
+			select {
+			case <-ch:
+				once.Do(func() { close(anyReleased) })
+			case <-done:
+			}
+		}(ch)
+	}
+
+	window := time.NewTimer(b.config.Window)
+	defer window.Stop()
+
+	var evictReason evictedReason
+	select {
+	case <-ctx.Done():
+		b.bufferSizeSema.Release()
+		for _, tb := range buffering {
+			statsKeyWithReason := append(tb.statsKey, string(evictedContextDone))
+			tableRequestsEvicted.Add(statsKeyWithReason, 1)
+		}
+		return nil, vterrors.Errorf(vterrors.Code(contextCanceledError), "%v: %v", contextCanceledError, ctx.Err())
+	case <-window.C:
+		evictReason = evictedWindowExceeded
+	case <-anyReleased:
+	}
+
+	for _, tb := range buffering {
+		if evictReason != "" {
+			statsKeyWithReason := append(tb.statsKey, string(evictReason))
+			tableRequestsEvicted.Add(statsKeyWithReason, 1)
+		} else {
+			tableRequestsDrained.Add(tb.statsKey, 1)
+		}
+	}
+	// The slot is released once the request was retried.
+	return func() { b.bufferSizeSema.Release() }, nil
+}
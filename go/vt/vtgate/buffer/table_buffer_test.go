@@ -0,0 +1,243 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buffer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	table  = "t1"
+	table2 = "t2"
+)
+
+var (
+	deniedTablesErr = vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "disallowed due to rule: %s", deniedTablesRule)
+	tableKey        = keyspace + "." + table
+	table2Key       = keyspace + "." + table2
+)
+
+func resetTableVariables() {
+	tableStarts.ResetAll()
+	tableStops.ResetAll()
+	tableRequestsBuffered.ResetAll()
+	tableRequestsBufferedDryRun.ResetAll()
+	tableRequestsDrained.ResetAll()
+	tableRequestsEvicted.ResetAll()
+	tableRequestsSkipped.ResetAll()
+	tableLastSwitchDurationMs.ResetAll()
+}
+
+// issueTableRequest simulates executing a request to the given tables which
+// goes through the buffer. If the buffering returned an error, it will be sent
+// on the returned channel.
+func issueTableRequest(ctx context.Context, b *Buffer, tables []string) chan error {
+	bufferingStopped := make(chan error, 1)
+	go func() {
+		defer close(bufferingStopped)
+		retryDone, err := b.WaitForRoutingRulesChange(ctx, tables, deniedTablesErr)
+		if err != nil {
+			bufferingStopped <- err
+		}
+		if retryDone != nil {
+			retryDone()
+		}
+	}()
+	return bufferingStopped
+}
+
+// waitForTableState polls the table buffer for up to 10 seconds and returns an
+// error if it doesn't have the wanted state by then.
+func waitForTableState(b *Buffer, table string, want bufferState) error {
+	tb := b.getOrCreateTableBuffer(keyspace, table)
+	start := time.Now()
+	for {
+		got := tb.testGetState()
+		if got == want {
+			return nil
+		}
+
+		if time.Since(start) > 10*time.Second {
+			return fmt.Errorf("wrong table buffer state: got = %v, want = %v", got, want)
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+}
+
+func TestCausedByDeniedTables(t *testing.T) {
+	if !CausedByDeniedTables(deniedTablesErr) {
+		t.Errorf("CausedByDeniedTables(%v) = false, want true", deniedTablesErr)
+	}
+	if CausedByDeniedTables(failoverErr) {
+		t.Errorf("CausedByDeniedTables(%v) = true, want false", failoverErr)
+	}
+	otherErr := vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "disallowed due to rule: other")
+	if CausedByDeniedTables(otherErr) {
+		t.Errorf("CausedByDeniedTables(%v) = true, want false", otherErr)
+	}
+}
+
+func TestTableBuffering(t *testing.T) {
+	resetTableVariables()
+
+	cfg := NewDefaultConfig()
+	cfg.Enabled = true
+	b := New(cfg)
+	defer b.Shutdown()
+
+	// Errors which are not caused by denied tables are not buffered.
+	if retryDone, err := b.WaitForRoutingRulesChange(context.Background(), []string{tableKey}, failoverErr); retryDone != nil || err != nil {
+		t.Fatalf("request with a non table switch error must not be buffered: %v", err)
+	}
+
+	stopped1 := issueTableRequest(context.Background(), b, []string{tableKey, table2Key})
+	stopped2 := issueTableRequest(context.Background(), b, []string{tableKey})
+	if err := waitForTableState(b, table, stateBuffering); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitForPoolSlots(b, cfg.Size-2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Routing rules of other tables do not release the requests.
+	b.HandleRoutingRulesChange([]string{keyspace + ".t3"})
+	select {
+	case <-stopped1:
+		t.Fatal("request must still be buffered")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// A change for one of the tables of a request releases it.
+	b.HandleRoutingRulesChange([]string{table2Key})
+	if err := <-stopped1; err != nil {
+		t.Fatalf("buffering failed unexpectedly: %v", err)
+	}
+	b.HandleRoutingRulesChange([]string{tableKey})
+	if err := <-stopped2; err != nil {
+		t.Fatalf("buffering failed unexpectedly: %v", err)
+	}
+	if err := waitForTableState(b, table, stateIdle); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitForPoolSlots(b, cfg.Size); err != nil {
+		t.Fatal(err)
+	}
+
+	statsKeyJoined := strings.Join([]string{keyspace, table}, ".")
+	if got, want := tableRequestsBuffered.Counts()[statsKeyJoined], int64(2); got != want {
+		t.Errorf("wrong number of buffered requests: got = %v, want = %v", got, want)
+	}
+	if got, want := tableStops.Counts()[statsKeyJoined+"."+string(stopRoutingRulesChanged)], int64(1); got != want {
+		t.Errorf("wrong number of stops: got = %v, want = %v", got, want)
+	}
+}
+
+func TestTableBufferingDisabledAndDryRun(t *testing.T) {
+	resetTableVariables()
+
+	// Buffering is limited to another keyspace.
+	cfg := NewDefaultConfig()
+	cfg.Enabled = true
+	cfg.Keyspaces = map[string]bool{"other": true}
+	b := New(cfg)
+	defer b.Shutdown()
+	if retryDone, err := b.WaitForRoutingRulesChange(context.Background(), []string{tableKey}, deniedTablesErr); retryDone != nil || err != nil {
+		t.Fatalf("request must not be buffered when buffering is disabled: %v", err)
+	}
+	statsKeyJoined := strings.Join([]string{keyspace, table, string(skippedDisabled)}, ".")
+	if got, want := tableRequestsSkipped.Counts()[statsKeyJoined], int64(1); got != want {
+		t.Errorf("skipped request was not tracked: got = %v, want = %v", got, want)
+	}
+
+	cfg = NewDefaultConfig()
+	cfg.DryRun = true
+	b = New(cfg)
+	defer b.Shutdown()
+	if retryDone, err := b.WaitForRoutingRulesChange(context.Background(), []string{tableKey}, deniedTablesErr); retryDone != nil || err != nil {
+		t.Fatalf("request must not be buffered in dry-run mode: %v", err)
+	}
+	if err := waitForTableState(b, table, stateBuffering); err != nil {
+		t.Fatal(err)
+	}
+	statsKeyJoined = strings.Join([]string{keyspace, table}, ".")
+	if got, want := tableRequestsBufferedDryRun.Counts()[statsKeyJoined], int64(1); got != want {
+		t.Errorf("dry-run request was not tracked: got = %v, want = %v", got, want)
+	}
+}
+
+func TestTableBufferingWindowAndMaxDuration(t *testing.T) {
+	resetTableVariables()
+
+	cfg := NewDefaultConfig()
+	cfg.Enabled = true
+	cfg.Window = 10 * time.Millisecond
+	cfg.MaxTableSwitchDuration = 100 * time.Millisecond
+	b := New(cfg)
+	defer b.Shutdown()
+
+	// The request exceeds its window and is retried without an error.
+	if err := <-issueTableRequest(context.Background(), b, []string{tableKey}); err != nil {
+		t.Fatalf("buffering should have stopped after exceeding the window without an error: %v", err)
+	}
+	statsKeyJoined := strings.Join([]string{keyspace, table, string(evictedWindowExceeded)}, ".")
+	if got, want := tableRequestsEvicted.Counts()[statsKeyJoined], int64(1); got != want {
+		t.Errorf("window exceeded request was not tracked: got = %v, want = %v", got, want)
+	}
+
+	// Buffering stops by itself after the max table switch duration.
+	if err := waitForTableState(b, table, stateIdle); err != nil {
+		t.Fatal(err)
+	}
+	statsKeyJoined = strings.Join([]string{keyspace, table, string(stopMaxFailoverDurationExceeded)}, ".")
+	if got, want := tableStops.Counts()[statsKeyJoined], int64(1); got != want {
+		t.Errorf("wrong number of stops: got = %v, want = %v", got, want)
+	}
+
+	// The table is still denied: do not buffer again right away.
+	if retryDone, err := b.WaitForRoutingRulesChange(context.Background(), []string{tableKey}, deniedTablesErr); retryDone != nil || err != nil {
+		t.Fatalf("request must not be buffered right after the last table switch: %v", err)
+	}
+}
+
+func TestTableBufferingContextCanceled(t *testing.T) {
+	resetTableVariables()
+
+	cfg := NewDefaultConfig()
+	cfg.Enabled = true
+	b := New(cfg)
+	defer b.Shutdown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := issueTableRequest(ctx, b, []string{tableKey})
+	if err := waitForTableState(b, table, stateBuffering); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := isCanceledError(<-stopped); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitForPoolSlots(b, cfg.Size); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -102,16 +102,58 @@ var (
 		[]string{"Keyspace", "ShardName", "Reason"})
 )
 
+// The variables below track buffering during table switches (see tableBuffer).
+// They are the per table counterparts of the shard variables above, and the
+// same invariants hold.
+var (
+	tableStarts = stats.NewCountersWithMultiLabels(
+		"BufferTableSwitchStarts",
+		"Buffering operation starts during table switches, including dry-run",
+		[]string{"Keyspace", "TableName"})
+	tableStops = stats.NewCountersWithMultiLabels(
+		"BufferTableSwitchStops",
+		"Buffering operation stops during table switches, including dry-runs",
+		[]string{"Keyspace", "TableName", "Reason"})
+	tableRequestsBuffered = stats.NewCountersWithMultiLabels(
+		"BufferTableSwitchRequestsBuffered",
+		"Buffered requests during table switches",
+		[]string{"Keyspace", "TableName"})
+	tableRequestsBufferedDryRun = stats.NewCountersWithMultiLabels(
+		"BufferTableSwitchRequestsBufferedDryRun",
+		"Buffered requests during table switches (dry-run)",
+		[]string{"Keyspace", "TableName"})
+	tableRequestsDrained = stats.NewCountersWithMultiLabels(
+		"BufferTableSwitchRequestsDrained",
+		"Drained buffered requests after table switches",
+		[]string{"Keyspace", "TableName"})
+	tableRequestsEvicted = stats.NewCountersWithMultiLabels(
+		"BufferTableSwitchRequestsEvicted",
+		"Evicted buffered requests during table switches",
+		[]string{"Keyspace", "TableName", "Reason"})
+	tableRequestsSkipped = stats.NewCountersWithMultiLabels(
+		"BufferTableSwitchRequestsSkipped",
+		"Skipped buffering requests during table switches (incl. dry-run)",
+		[]string{"Keyspace", "TableName", "Reason"})
+	tableLastSwitchDurationMs = stats.NewGaugesWithMultiLabels(
+		"BufferTableSwitchLastDurationMs",
+		"Buffering duration of the last table switch. The value for a given table will be reset at the next switch.",
+		[]string{"Keyspace", "TableName"})
+)
+
 // stopReason is used in "stopsByReason" as "Reason" label.
 type stopReason string
 
 var stopReasons = []stopReason{stopShardMissing, stopFailoverEndDetected, stopMaxFailoverDurationExceeded, stopShutdown}
 
+var tableStopReasons = []stopReason{stopRoutingRulesChanged, stopMaxFailoverDurationExceeded, stopShutdown}
+
 const (
 	stopShardMissing                stopReason = "ReshardingComplete"
 	stopFailoverEndDetected         stopReason = "NewPrimarySeen"
 	stopMaxFailoverDurationExceeded stopReason = "MaxDurationExceeded"
 	stopShutdown                    stopReason = "Shutdown"
+	// stopRoutingRulesChanged is used when a table switch ends.
+	stopRoutingRulesChanged stopReason = "RoutingRulesChanged"
 )
 
 // evictedReason is used in "requestsEvicted" as "Reason" label.
@@ -174,6 +216,29 @@ func initVariablesForShard(statsKey []string) {
 	}
 }
 
+// initVariablesForTable is the equivalent of initVariablesForShard for tables.
+// "statsKey" should have two members for keyspace and table.
+func initVariablesForTable(statsKey []string) {
+	tableStarts.Reset(statsKey)
+	for _, reason := range tableStopReasons {
+		key := append(statsKey, string(reason))
+		tableStops.Reset(key)
+	}
+
+	tableRequestsBuffered.Reset(statsKey)
+	tableRequestsBufferedDryRun.Reset(statsKey)
+	tableRequestsDrained.Reset(statsKey)
+	for _, reason := range []evictedReason{evictedContextDone, evictedWindowExceeded} {
+		key := append(statsKey, string(reason))
+		tableRequestsEvicted.Reset(key)
+	}
+	for _, reason := range []skippedReason{skippedBufferFull, skippedDisabled, skippedShutdown, skippedLastFailoverTooRecent} {
+		key := append(statsKey, string(reason))
+		tableRequestsSkipped.Reset(key)
+	}
+	tableLastSwitchDurationMs.Set(statsKey, 0)
+}
+
 // TODO(mberlin): Remove the gauge values below once we store them
 // internally and have a /bufferz page where we can show this.
 var (
@@ -208,6 +208,8 @@ func (env *ExpressionEnv) typecheck(expr Expr) {
 		env.typecheckUnary(expr.Inner)
 	case *IsExpr:
 		env.typecheckUnary(expr.Inner)
+	case *NotExpr:
+		env.typecheckUnary(expr.Inner)
 	case *BitwiseNotExpr:
 		env.typecheckUnary(expr.Inner)
 	case *WeightStringCallExpr:
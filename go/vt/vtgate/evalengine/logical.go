@@ -167,7 +167,9 @@ func (l *LogicalExpr) eval(env *ExpressionEnv, out *EvalResult) {
 func (l *LogicalExpr) typeof(env *ExpressionEnv) (sqltypes.Type, flag) {
 	_, f1 := l.Left.typeof(env)
 	_, f2 := l.Right.typeof(env)
-	return sqltypes.Uint64, f1 | f2
+	// A NULL operand doesn't make the result NULL (NULL OR TRUE is TRUE),
+	// so the result can only be nullable.
+	return sqltypes.Uint64, (f1 | f2) & flagNullable
 }
 
 func (i *IsExpr) eval(env *ExpressionEnv, result *EvalResult) {
@@ -248,6 +248,18 @@ func TestEvaluate(t *testing.T) {
 	}, {
 		expression: "true is true",
 		expected:   True,
+	}, {
+		expression: "not :exp",
+		expected:   False,
+	}, {
+		expression: "not (:exp = 42)",
+		expected:   True,
+	}, {
+		expression: "(null or :exp) is true",
+		expected:   True,
+	}, {
+		expression: "(null and :exp) is null",
+		expected:   True,
 	}, {
 		expression: "null is false",
 		expected:   False,
@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -49,6 +50,7 @@ import (
 	"vitess.io/vitess/go/vt/sysvars"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/buffer"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/logstats"
@@ -935,7 +937,9 @@ func (e *Executor) VSchema() *vindexes.VSchema {
 func (e *Executor) SaveVSchema(vschema *vindexes.VSchema, stats *VSchemaStats) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	var switchedTables []string
 	if vschema != nil {
+		switchedTables = routingRulesChangedTables(e.vschema, vschema)
 		e.vschema = vschema
 	}
 	e.vschemaStats = stats
@@ -945,6 +949,79 @@ func (e *Executor) SaveVSchema(vschema *vindexes.VSchema, stats *VSchemaStats) {
 		vschemaCounters.Add("Reload", 1)
 	}
 
+	// Release the requests buffered while these tables were switched, now
+	// that they can be planned with the new routing rules.
+	if buf := e.buffer(); buf != nil && len(switchedTables) > 0 {
+		buf.HandleRoutingRulesChange(switchedTables)
+	}
+}
+
+// routingRulesChangedTables returns the tables ("<keyspace>.<table>") which
+// are the target of a routing rule that differs between the two vschemas.
+func routingRulesChangedTables(old, new *vindexes.VSchema) []string {
+	if old == nil {
+		return nil
+	}
+	tables := map[string]bool{}
+	addTargets := func(rr *vindexes.RoutingRule) {
+		if rr == nil {
+			return
+		}
+		for _, t := range rr.Tables {
+			tables[t.String()] = true
+		}
+	}
+	for from, oldRule := range old.RoutingRules {
+		if newRule := new.RoutingRules[from]; !routingRulesEqual(oldRule, newRule) {
+			addTargets(oldRule)
+			addTargets(newRule)
+		}
+	}
+	for from, newRule := range new.RoutingRules {
+		if _, ok := old.RoutingRules[from]; !ok {
+			addTargets(newRule)
+		}
+	}
+
+	result := make([]string, 0, len(tables))
+	for t := range tables {
+		result = append(result, t)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func routingRulesEqual(a, b *vindexes.RoutingRule) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if (a.Error == nil) != (b.Error == nil) || len(a.Tables) != len(b.Tables) {
+		return false
+	}
+	for i := range a.Tables {
+		if a.Tables[i].String() != b.Tables[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+// buffer returns the buffer of the tablet gateway, if any.
+func (e *Executor) buffer() *buffer.Buffer {
+	if e.scatterConn == nil || e.scatterConn.gateway == nil {
+		return nil
+	}
+	return e.scatterConn.gateway.buffer
+}
+
+// waitForRoutingRulesChange buffers a request which failed because its tables
+// are being switched to another keyspace. See buffer.Buffer.WaitForRoutingRulesChange.
+func (e *Executor) waitForRoutingRulesChange(ctx context.Context, tables []string, err error) (buffer.RetryDoneFunc, error) {
+	buf := e.buffer()
+	if buf == nil {
+		return nil, nil
+	}
+	return buf.WaitForRoutingRulesChange(ctx, tables, err)
 }
 
 // ParseDestinationTarget parses destination target string and sets default keyspace if possible.
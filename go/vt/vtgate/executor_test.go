@@ -2563,6 +2563,34 @@ func TestExecutorStartTxnStmt(t *testing.T) {
 	}
 }
 
+func TestRoutingRulesChangedTables(t *testing.T) {
+	source := &vindexes.Keyspace{Name: "source"}
+	target := &vindexes.Keyspace{Name: "target"}
+	table := func(ks *vindexes.Keyspace, name string) *vindexes.Table {
+		return &vindexes.Table{Keyspace: ks, Name: sqlparser.NewIdentifierCS(name)}
+	}
+	rule := func(tables ...*vindexes.Table) *vindexes.RoutingRule {
+		return &vindexes.RoutingRule{Tables: tables}
+	}
+
+	old := &vindexes.VSchema{RoutingRules: map[string]*vindexes.RoutingRule{
+		"t1":        rule(table(source, "t1")),
+		"target.t1": rule(table(source, "t1")),
+		"t2":        rule(table(source, "t2")),
+		"t3":        rule(table(source, "t3")),
+	}}
+	new := &vindexes.VSchema{RoutingRules: map[string]*vindexes.RoutingRule{
+		"t1":        rule(table(target, "t1")),
+		"target.t1": rule(table(target, "t1")),
+		"t2":        rule(table(source, "t2")),
+		"t4":        rule(table(target, "t4")),
+	}}
+
+	assert.Equal(t, []string{"source.t1", "source.t3", "target.t1", "target.t4"}, routingRulesChangedTables(old, new))
+	assert.Empty(t, routingRulesChangedTables(old, old))
+	assert.Empty(t, routingRulesChangedTables(nil, new))
+}
+
 func exec(executor *Executor, session *SafeSession, sql string) (*sqltypes.Result, error) {
 	return executor.Execute(context.Background(), "TestExecute", session, sql, nil)
 }
@@ -27,6 +27,7 @@ import (
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/buffer"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 )
 
@@ -54,53 +55,77 @@ func (e *Executor) newExecute(
 		bindVars = make(map[string]*querypb.BindVariable)
 	}
 
+	// Requests which fail because their tables are being switched to another
+	// keyspace are only buffered when not in a transaction, as they have to be
+	// re-planned and retried once the new routing rules are seen.
+	inTransaction := safeSession.InTransaction()
 	query, comments := sqlparser.SplitMarginComments(sql)
-	vcursor, err := newVCursorImpl(safeSession, comments, e, logStats, e.vm, e.VSchema(), e.resolver.resolver, e.serv, e.warnShardedOnly, e.pv)
-	if err != nil {
-		return err
-	}
+	bufferedOnce := false
+	for {
+		vcursor, err := newVCursorImpl(safeSession, comments, e, logStats, e.vm, e.VSchema(), e.resolver.resolver, e.serv, e.warnShardedOnly, e.pv)
+		if err != nil {
+			return err
+		}
 
-	// 2: Create a plan for the query
-	plan, stmt, err := e.getPlan(ctx, vcursor, query, comments, bindVars, safeSession, logStats)
-	execStart := e.logPlanningFinished(logStats, plan)
+		// 2: Create a plan for the query
+		plan, stmt, err := e.getPlan(ctx, vcursor, query, comments, bindVars, safeSession, logStats)
+		execStart := e.logPlanningFinished(logStats, plan)
 
-	if err != nil {
-		safeSession.ClearWarnings()
-		return err
-	}
+		if err != nil {
+			safeSession.ClearWarnings()
+			return err
+		}
 
-	if plan.Type != sqlparser.StmtShow {
-		safeSession.ClearWarnings()
-	}
+		if plan.Type != sqlparser.StmtShow {
+			safeSession.ClearWarnings()
+		}
 
-	// add any warnings that the planner wants to add
-	for _, warning := range plan.Warnings {
-		safeSession.RecordWarning(warning)
-	}
+		// add any warnings that the planner wants to add
+		for _, warning := range plan.Warnings {
+			safeSession.RecordWarning(warning)
+		}
 
-	result, err := e.handleTransactions(ctx, safeSession, plan, logStats, vcursor, stmt)
-	if err != nil {
-		return err
-	}
-	if result != nil {
-		return recResult(plan.Type, result)
-	}
+		result, err := e.handleTransactions(ctx, safeSession, plan, logStats, vcursor, stmt)
+		if err != nil {
+			return err
+		}
+		if result != nil {
+			return recResult(plan.Type, result)
+		}
 
-	// 3: Prepare for execution
-	err = e.addNeededBindVars(plan.BindVarNeeds, bindVars, safeSession)
-	if err != nil {
-		logStats.Error = err
-		return err
-	}
+		// 3: Prepare for execution
+		err = e.addNeededBindVars(plan.BindVarNeeds, bindVars, safeSession)
+		if err != nil {
+			logStats.Error = err
+			return err
+		}
 
-	if plan.Instructions.NeedsTransaction() {
-		return e.insideTransaction(ctx, safeSession, logStats,
-			func() error {
-				return execPlan(ctx, plan, vcursor, bindVars, execStart)
-			})
-	}
+		if plan.Instructions.NeedsTransaction() {
+			err = e.insideTransaction(ctx, safeSession, logStats,
+				func() error {
+					return execPlan(ctx, plan, vcursor, bindVars, execStart)
+				})
+		} else {
+			err = execPlan(ctx, plan, vcursor, bindVars, execStart)
+		}
 
-	return execPlan(ctx, plan, vcursor, bindVars, execStart)
+		// 4: Buffer the request if its tables are being switched, and retry it
+		if !bufferedOnce && !inTransaction && buffer.CausedByDeniedTables(err) {
+			retryDone, bufferErr := e.waitForRoutingRulesChange(ctx, plan.TablesUsed, err)
+			if bufferErr != nil {
+				return vterrors.Wrapf(bufferErr,
+					"failed to automatically buffer and retry failed request during table switch. original err (type=%T): %v",
+					err, err)
+			}
+			if retryDone != nil {
+				// The plan is rebuilt with the new routing rules.
+				defer retryDone()
+				bufferedOnce = true
+				continue
+			}
+		}
+		return err
+	}
 }
 
 // handleTransactions deals with transactional queries: begin, commit, rollback and savepoint management
@@ -130,6 +130,10 @@ func buildDDLPlans(sql string, ddlStatement sqlparser.DDLStatement, reservedVars
 		destination, keyspace, err = buildDropTable(vschema, ddlStatement)
 	case *sqlparser.RenameTable:
 		destination, keyspace, err = buildRenameTable(vschema, ddl)
+	case *sqlparser.CreateTrigger:
+		destination, keyspace, err = buildCreateTrigger(vschema, ddl)
+	case *sqlparser.CreateRoutine, *sqlparser.CreateEvent, *sqlparser.DropStoredProgram:
+		destination, keyspace, err = buildStoredProgramDDL(vschema, ddlStatement)
 	default:
 		return nil, nil, vterrors.VT13001(fmt.Sprintf("unexpected DDL statement type: %T", ddlStatement))
 	}
@@ -403,6 +407,48 @@ func buildRenameTable(vschema plancontext.VSchema, renameTable *sqlparser.Rename
 	return destination, keyspace, nil
 }
 
+func buildCreateTrigger(vschema plancontext.VSchema, ddl *sqlparser.CreateTrigger) (key.Destination, *vindexes.Keyspace, error) {
+	// A trigger lives alongside its table, and so we route the query to the table's keyspace
+	table, _, _, _, destination, err := vschema.FindTableOrVindex(ddl.Table)
+	if err != nil {
+		_, isNotFound := err.(vindexes.NotFoundError)
+		if !isNotFound {
+			return nil, nil, err
+		}
+	}
+	var keyspace *vindexes.Keyspace
+	if table == nil {
+		qualifier := ddl.TriggerName.Qualifier.String()
+		if qualifier == "" {
+			qualifier = ddl.Table.Qualifier.String()
+		}
+		destination, keyspace, _, err = vschema.TargetDestination(qualifier)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		keyspace = table.Keyspace
+		ddl.Table.Name = table.Name
+	}
+	if !ddl.TriggerName.Qualifier.IsEmpty() && ddl.TriggerName.Qualifier.String() != keyspace.Name {
+		return nil, nil, vterrors.VT12001("trigger and its table in different keyspaces")
+	}
+	ddl.Table.Qualifier = sqlparser.NewIdentifierCS("")
+	ddl.SetTable("", ddl.TriggerName.Name.String())
+	return destination, keyspace, nil
+}
+
+func buildStoredProgramDDL(vschema plancontext.VSchema, ddlStatement sqlparser.DDLStatement) (key.Destination, *vindexes.Keyspace, error) {
+	// Procedures, functions and events are not tracked by the vschema. They are routed by their qualifier,
+	// or else to the default keyspace.
+	destination, keyspace, _, err := vschema.TargetDestination(ddlStatement.GetTable().Qualifier.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	ddlStatement.SetTable("", ddlStatement.GetTable().Name.String())
+	return destination, keyspace, nil
+}
+
 func tryToGetRoutePlan(selectPlan engine.Primitive) (valid bool, keyspaceName string, opCode engine.Opcode) {
 	switch plan := selectPlan.(type) {
 	case *engine.Route:
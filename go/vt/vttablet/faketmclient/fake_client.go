@@ -91,6 +91,11 @@ func (client *FakeTabletManagerClient) GetSchema(ctx context.Context, tablet *to
 	return client.tmc.GetSchema(ctx, tablet, request)
 }
 
+// GetSchemaHistory is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) GetSchemaHistory(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaHistoryRequest) (*tabletmanagerdatapb.GetSchemaHistoryResponse, error) {
+	return &tabletmanagerdatapb.GetSchemaHistoryResponse{}, nil
+}
+
 // GetPermissions is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) GetPermissions(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.Permissions, error) {
 	return &tabletmanagerdatapb.Permissions{}, nil
@@ -150,6 +155,11 @@ func (client *FakeTabletManagerClient) ApplySchema(ctx context.Context, tablet *
 	return &tabletmanagerdatapb.SchemaChangeResult{}, nil
 }
 
+// UpdateGCTable is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) UpdateGCTable(ctx context.Context, tablet *topodatapb.Tablet, tableName string, action tabletmanagerdatapb.UpdateGCTableRequest_Action) error {
+	return nil
+}
+
 // ExecuteQuery is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) ExecuteQuery(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.ExecuteQueryRequest) (*querypb.QueryResult, error) {
 	return &querypb.QueryResult{}, nil
@@ -184,6 +194,21 @@ func (client *FakeTabletManagerClient) FullStatus(ctx context.Context, tablet *t
 	return &replicationdatapb.FullStatus{}, nil
 }
 
+// GetUnresolvedTransactions is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) GetUnresolvedTransactions(ctx context.Context, tablet *topodatapb.Tablet, abandonAge int64) ([]*querypb.TransactionMetadata, error) {
+	return nil, nil
+}
+
+// GetGCTables is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) GetGCTables(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.GCTable, error) {
+	return nil, nil
+}
+
+// EstimateSchemaMigration is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) EstimateSchemaMigration(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.EstimateSchemaMigrationRequest) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	return &tabletmanagerdatapb.SchemaMigrationEstimate{}, nil
+}
+
 // StopReplication is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) StopReplication(ctx context.Context, tablet *topodatapb.Tablet) error {
 	return nil
@@ -333,6 +358,11 @@ func (client *FakeTabletManagerClient) RestoreFromBackup(ctx context.Context, ta
 	return &eofEventStream{}, nil
 }
 
+// SetBackupRateLimits is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) SetBackupRateLimits(ctx context.Context, tablet *topodatapb.Tablet, read, write int64) error {
+	return nil
+}
+
 //
 // Management related methods
 //
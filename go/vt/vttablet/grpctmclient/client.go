@@ -274,6 +274,16 @@ func (client *Client) GetSchema(ctx context.Context, tablet *topodatapb.Tablet,
 	return response.SchemaDefinition, nil
 }
 
+// GetSchemaHistory is part of the tmclient.TabletManagerClient interface.
+func (client *Client) GetSchemaHistory(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaHistoryRequest) (*tabletmanagerdatapb.GetSchemaHistoryResponse, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return c.GetSchemaHistory(ctx, request)
+}
+
 // GetPermissions is part of the tmclient.TabletManagerClient interface.
 func (client *Client) GetPermissions(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.Permissions, error) {
 	c, closer, err := client.dialer.dial(ctx, tablet)
@@ -288,6 +298,50 @@ func (client *Client) GetPermissions(ctx context.Context, tablet *topodatapb.Tab
 	return response.Permissions, nil
 }
 
+// GetUnresolvedTransactions is part of the tmclient.TabletManagerClient interface.
+func (client *Client) GetUnresolvedTransactions(ctx context.Context, tablet *topodatapb.Tablet, abandonAge int64) ([]*querypb.TransactionMetadata, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	response, err := c.GetUnresolvedTransactions(ctx, &tabletmanagerdatapb.GetUnresolvedTransactionsRequest{
+		AbandonAge: abandonAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response.Transactions, nil
+}
+
+// GetGCTables is part of the tmclient.TabletManagerClient interface.
+func (client *Client) GetGCTables(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.GCTable, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	response, err := c.GetGCTables(ctx, &tabletmanagerdatapb.GetGCTablesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return response.Tables, nil
+}
+
+// EstimateSchemaMigration is part of the tmclient.TabletManagerClient interface.
+func (client *Client) EstimateSchemaMigration(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.EstimateSchemaMigrationRequest) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	response, err := c.EstimateSchemaMigration(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return response.Estimate, nil
+}
+
 //
 // Various read-write methods
 //
@@ -405,6 +459,20 @@ func (client *Client) ApplySchema(ctx context.Context, tablet *topodatapb.Tablet
 	}, nil
 }
 
+// UpdateGCTable is part of the tmclient.TabletManagerClient interface.
+func (client *Client) UpdateGCTable(ctx context.Context, tablet *topodatapb.Tablet, tableName string, action tabletmanagerdatapb.UpdateGCTableRequest_Action) error {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	_, err = c.UpdateGCTable(ctx, &tabletmanagerdatapb.UpdateGCTableRequest{
+		TableName: tableName,
+		Action:    action,
+	})
+	return err
+}
+
 // LockTables is part of the tmclient.TabletManagerClient interface.
 func (client *Client) LockTables(ctx context.Context, tablet *topodatapb.Tablet) error {
 	c, closer, err := client.dialer.dial(ctx, tablet)
@@ -980,6 +1048,20 @@ func (client *Client) RestoreFromBackup(ctx context.Context, tablet *topodatapb.
 	}, nil
 }
 
+// SetBackupRateLimits is part of the tmclient.TabletManagerClient interface.
+func (client *Client) SetBackupRateLimits(ctx context.Context, tablet *topodatapb.Tablet, read, write int64) error {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	_, err = c.SetBackupRateLimits(ctx, &tabletmanagerdatapb.SetBackupRateLimitsRequest{
+		ReadBytesPerSecond:  read,
+		WriteBytesPerSecond: write,
+	})
+	return err
+}
+
 // Close is part of the tmclient.TabletManagerClient interface.
 func (client *Client) Close() {
 	client.dialer.Close()
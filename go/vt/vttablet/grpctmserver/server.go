@@ -89,6 +89,12 @@ func (s *server) GetSchema(ctx context.Context, request *tabletmanagerdatapb.Get
 	return response, err
 }
 
+func (s *server) GetSchemaHistory(ctx context.Context, request *tabletmanagerdatapb.GetSchemaHistoryRequest) (response *tabletmanagerdatapb.GetSchemaHistoryResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "GetSchemaHistory", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	return s.tm.GetSchemaHistory(ctx, request)
+}
+
 func (s *server) GetPermissions(ctx context.Context, request *tabletmanagerdatapb.GetPermissionsRequest) (response *tabletmanagerdatapb.GetPermissionsResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "GetPermissions", request, response, false /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
@@ -100,6 +106,39 @@ func (s *server) GetPermissions(ctx context.Context, request *tabletmanagerdatap
 	return response, err
 }
 
+func (s *server) GetUnresolvedTransactions(ctx context.Context, request *tabletmanagerdatapb.GetUnresolvedTransactionsRequest) (response *tabletmanagerdatapb.GetUnresolvedTransactionsResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "GetUnresolvedTransactions", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.GetUnresolvedTransactionsResponse{}
+	transactions, err := s.tm.GetUnresolvedTransactions(ctx, request.AbandonAge)
+	if err == nil {
+		response.Transactions = transactions
+	}
+	return response, err
+}
+
+func (s *server) GetGCTables(ctx context.Context, request *tabletmanagerdatapb.GetGCTablesRequest) (response *tabletmanagerdatapb.GetGCTablesResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "GetGCTables", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.GetGCTablesResponse{}
+	tables, err := s.tm.GetGCTables(ctx)
+	if err == nil {
+		response.Tables = tables
+	}
+	return response, err
+}
+
+func (s *server) EstimateSchemaMigration(ctx context.Context, request *tabletmanagerdatapb.EstimateSchemaMigrationRequest) (response *tabletmanagerdatapb.EstimateSchemaMigrationResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "EstimateSchemaMigration", request, response, false /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.EstimateSchemaMigrationResponse{}
+	estimate, err := s.tm.EstimateSchemaMigration(ctx, request)
+	if err == nil {
+		response.Estimate = estimate
+	}
+	return response, err
+}
+
 //
 // Various read-write methods
 //
@@ -177,6 +216,14 @@ func (s *server) ApplySchema(ctx context.Context, request *tabletmanagerdatapb.A
 	return response, err
 }
 
+func (s *server) UpdateGCTable(ctx context.Context, request *tabletmanagerdatapb.UpdateGCTableRequest) (response *tabletmanagerdatapb.UpdateGCTableResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "UpdateGCTable", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.UpdateGCTableResponse{}
+	err = s.tm.UpdateGCTable(ctx, request.TableName, request.Action)
+	return response, err
+}
+
 func (s *server) LockTables(ctx context.Context, req *tabletmanagerdatapb.LockTablesRequest) (*tabletmanagerdatapb.LockTablesResponse, error) {
 	err := s.tm.LockTables(ctx)
 	if err != nil {
@@ -515,6 +562,14 @@ func (s *server) RestoreFromBackup(request *tabletmanagerdatapb.RestoreFromBacku
 	return s.tm.RestoreFromBackup(ctx, logger, request)
 }
 
+func (s *server) SetBackupRateLimits(ctx context.Context, request *tabletmanagerdatapb.SetBackupRateLimitsRequest) (response *tabletmanagerdatapb.SetBackupRateLimitsResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "SetBackupRateLimits", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.SetBackupRateLimitsResponse{}
+	err = s.tm.SetBackupRateLimits(ctx, request.ReadBytesPerSecond, request.WriteBytesPerSecond)
+	return response, err
+}
+
 // registration glue
 
 func init() {
@@ -0,0 +1,301 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Migration estimates answer "what would this migration cost?" without running it. An estimate reports the
+table's size and row count, whether the change is eligible for INSTANT DDL or otherwise completes without
+copying the table, the disk space required for the shadow table versus the free space in MySQL's data
+directory, and the expected copy time, based on the copy rate of recently completed vitess migrations.
+
+Estimates are requested via the EstimateSchemaMigration RPC, or by submitting a migration with --dry-run.
+A dry run migration is estimated when reviewed, its estimate is recorded in the estimate column, and it is
+then cancelled without ever running.
+*/
+
+package onlineddl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"syscall"
+
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// recentCopyRatesLimit is the number of recently completed migrations the copy rate is computed from
+	recentCopyRatesLimit = 10
+	dryRunMessage        = "dry run: migration was estimated and not executed"
+)
+
+// EstimateMigration estimates the impact of running the given DDL statement, with the given ddl strategy,
+// as an Online DDL migration on this tablet. The migration is not submitted.
+func (e *Executor) EstimateMigration(ctx context.Context, sql string, ddlStrategy string) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	if !e.isOpen {
+		return nil, vterrors.New(vtrpcpb.Code_FAILED_PRECONDITION, "online ddl is disabled")
+	}
+	ddlStrategySetting, err := schema.ParseDDLStrategy(ddlStrategy)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid ddl strategy %s: %v", ddlStrategy, err)
+	}
+	if ddlStrategySetting.Strategy.IsDirect() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "cannot estimate a migration with %s strategy", ddlStrategySetting.Strategy)
+	}
+	ddlStmt, _, err := schema.ParseOnlineDDLStatement(sql)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "error parsing statement %s: %v", sql, err)
+	}
+	onlineDDLs, err := schema.NewOnlineDDLs(e.keyspace, sql, ddlStmt, ddlStrategySetting, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if len(onlineDDLs) != 1 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "expected a statement on a single table, found %d tables in: %s", len(onlineDDLs), sql)
+	}
+	onlineDDL := onlineDDLs[0]
+	_, ddlAction, err := onlineDDL.GetActionStr()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dbconnpool.NewDBConnection(ctx, e.env.Config().DB.DbaWithDB())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_, capableOf, _ := mysql.GetFlavor(conn.ServerVersion, nil)
+
+	return e.estimateMigration(ctx, capableOf, onlineDDL, ddlAction, false, onlineDDL.IsView())
+}
+
+// estimateMigration estimates the impact of running the given migration
+func (e *Executor) estimateMigration(ctx context.Context, capableOf mysql.CapableOf, onlineDDL *schema.OnlineDDL, ddlAction string, isRevert bool, isView bool) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	estimate := &tabletmanagerdatapb.SchemaMigrationEstimate{
+		Table:                onlineDDL.Table,
+		DdlAction:            ddlAction,
+		FreeDiskBytes:        -1,
+		EstimatedCopySeconds: -1,
+	}
+	if ddlAction == sqlparser.AlterStr && !isView {
+		exists, err := e.tableExists(ctx, onlineDDL.Table)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "table %s does not exist", onlineDDL.Table)
+		}
+		if err := e.readTableSize(ctx, onlineDDL.Table, estimate); err != nil {
+			return nil, err
+		}
+		if !isRevert {
+			isInstantEligible, err := e.isInstantEligible(ctx, capableOf, onlineDDL)
+			if err != nil {
+				return nil, err
+			}
+			estimate.IsInstantEligible = isInstantEligible
+		}
+	}
+	isImmediate, err := e.reviewImmediateOperations(ctx, capableOf, onlineDDL, ddlAction, isRevert, isView)
+	if err != nil {
+		return nil, err
+	}
+	estimate.IsImmediateOperation = isImmediate
+	if estimate.IsInstantEligible && !isImmediate {
+		estimate.Notes = append(estimate.Notes, "the migration is eligible for INSTANT DDL, and completes without copying the table when submitted with --prefer-instant-ddl")
+	}
+	if !isImmediate {
+		freeDiskBytes, err := e.readFreeDiskBytes(ctx)
+		if err != nil {
+			log.Errorf("Executor.estimateMigration: cannot read free disk space: %v", err)
+			estimate.Notes = append(estimate.Notes, fmt.Sprintf("free disk space is unknown: %v", err))
+		} else {
+			estimate.FreeDiskBytes = freeDiskBytes
+		}
+		copyRowsPerSecond, err := e.readRecentCopyRate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		estimate.CopyRowsPerSecond = copyRowsPerSecond
+	}
+	concludeEstimate(estimate)
+	return estimate, nil
+}
+
+// concludeEstimate computes the required disk space and the copy time of an estimate, based on the table's size,
+// the free disk space and the copy rate
+func concludeEstimate(estimate *tabletmanagerdatapb.SchemaMigrationEstimate) {
+	if estimate.IsImmediateOperation {
+		estimate.RequiredDiskBytes = 0
+		estimate.EstimatedCopySeconds = 0
+		return
+	}
+	// The shadow table holds a full copy of the table's rows and indexes
+	estimate.RequiredDiskBytes = estimate.TableSizeBytes
+	if estimate.FreeDiskBytes >= 0 && estimate.RequiredDiskBytes > estimate.FreeDiskBytes {
+		estimate.Notes = append(estimate.Notes, fmt.Sprintf("insufficient disk space: the migration requires %d bytes, but only %d bytes are free", estimate.RequiredDiskBytes, estimate.FreeDiskBytes))
+	}
+	switch {
+	case estimate.TableRows == 0:
+		estimate.EstimatedCopySeconds = 0
+	case estimate.CopyRowsPerSecond > 0:
+		estimate.EstimatedCopySeconds = int64(math.Ceil(float64(estimate.TableRows) / estimate.CopyRowsPerSecond))
+	default:
+		estimate.EstimatedCopySeconds = -1
+		estimate.Notes = append(estimate.Notes, "copy time is unknown: there are no recently completed vitess migrations to compute a copy rate from")
+	}
+}
+
+// copyRate returns the overall rate, in rows per second, at which the given migrations copied their rows,
+// or zero if unknown
+func copyRate(rowsCopied []int64, copySeconds []int64) float64 {
+	var totalRows, totalSeconds int64
+	for i := range rowsCopied {
+		if copySeconds[i] <= 0 {
+			// Too fast to measure
+			continue
+		}
+		totalRows += rowsCopied[i]
+		totalSeconds += copySeconds[i]
+	}
+	if totalSeconds == 0 {
+		return 0
+	}
+	return float64(totalRows) / float64(totalSeconds)
+}
+
+// readTableSize reads the row count and size of a table into the given estimate
+func (e *Executor) readTableSize(ctx context.Context, tableName string, estimate *tabletmanagerdatapb.SchemaMigrationEstimate) error {
+	query, err := sqlparser.ParseAndBind(sqlSelectTableSize,
+		sqltypes.StringBindVariable(e.dbName),
+		sqltypes.StringBindVariable(tableName),
+	)
+	if err != nil {
+		return err
+	}
+	rs, err := e.execQuery(ctx, query)
+	if err != nil {
+		return err
+	}
+	row := rs.Named().Row()
+	if row == nil {
+		return vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "table %s not found in INFORMATION_SCHEMA.TABLES", tableName)
+	}
+	estimate.TableRows = row.AsInt64("table_rows", 0)
+	estimate.TableSizeBytes = row.AsInt64("table_size", 0)
+	return nil
+}
+
+// isInstantEligible checks whether the ALTER TABLE migration is able to run with ALGORITHM=INSTANT, regardless
+// of its ddl strategy
+func (e *Executor) isInstantEligible(ctx context.Context, capableOf mysql.CapableOf, onlineDDL *schema.OnlineDDL) (bool, error) {
+	ddlStmt, _, err := schema.ParseOnlineDDLStatement(onlineDDL.SQL)
+	if err != nil {
+		return false, err
+	}
+	alterTable, ok := ddlStmt.(*sqlparser.AlterTable)
+	if !ok {
+		return false, nil
+	}
+	createTable, err := e.getCreateTableStatement(ctx, onlineDDL.Table)
+	if err != nil {
+		return false, err
+	}
+	plan, err := AnalyzeInstantDDL(alterTable, createTable, capableOf)
+	if err != nil {
+		return false, err
+	}
+	return plan != nil, nil
+}
+
+// readFreeDiskBytes returns the free disk space, available to MySQL, in its data directory. It assumes
+// MySQL runs on the same host as this tablet.
+func (e *Executor) readFreeDiskBytes(ctx context.Context) (int64, error) {
+	rs, err := e.execQuery(ctx, sqlSelectDataDir)
+	if err != nil {
+		return 0, err
+	}
+	row := rs.Named().Row()
+	if row == nil {
+		return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for %s", sqlSelectDataDir)
+	}
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(row.AsString("datadir", ""), &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+// readRecentCopyRate returns the rate, in rows per second, at which recently completed vitess migrations copied
+// their tables, or zero if there are no such migrations
+func (e *Executor) readRecentCopyRate(ctx context.Context) (float64, error) {
+	query, err := sqlparser.ParseAndBind(sqlSelectRecentCopyRates,
+		sqltypes.Int64BindVariable(recentCopyRatesLimit),
+	)
+	if err != nil {
+		return 0, err
+	}
+	rs, err := e.execQuery(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	var rowsCopied, copySeconds []int64
+	for _, row := range rs.Named().Rows {
+		rowsCopied = append(rowsCopied, row.AsInt64("rows_copied", 0))
+		copySeconds = append(copySeconds, row.AsInt64("copy_seconds", 0))
+	}
+	return copyRate(rowsCopied, copySeconds), nil
+}
+
+// concludeDryRunMigration estimates a dry run migration, records the estimate, and cancels the migration
+func (e *Executor) concludeDryRunMigration(ctx context.Context, capableOf mysql.CapableOf, onlineDDL *schema.OnlineDDL, ddlAction string, isRevert bool, isView bool) error {
+	estimate, err := e.estimateMigration(ctx, capableOf, onlineDDL, ddlAction, isRevert, isView)
+	if err != nil {
+		_ = e.failMigration(ctx, onlineDDL, err)
+		return nil
+	}
+	estimateJSON, err := json2.MarshalPB(estimate)
+	if err != nil {
+		return err
+	}
+	query, err := sqlparser.ParseAndBind(sqlUpdateMigrationEstimate,
+		sqltypes.StringBindVariable(string(estimateJSON)),
+		sqltypes.StringBindVariable(onlineDDL.UUID),
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := e.execQuery(ctx, query); err != nil {
+		return err
+	}
+	if err := e.updateMigrationTimestamp(ctx, "cancelled_timestamp", onlineDDL.UUID); err != nil {
+		return err
+	}
+	if err := e.updateMigrationMessage(ctx, onlineDDL.UUID, dryRunMessage); err != nil {
+		return err
+	}
+	return e.updateMigrationStatus(ctx, onlineDDL.UUID, schema.OnlineDDLStatusCancelled)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+func TestCopyRate(t *testing.T) {
+	tt := []struct {
+		name        string
+		rowsCopied  []int64
+		copySeconds []int64
+		expect      float64
+	}{
+		{
+			name: "no migrations",
+		},
+		{
+			name:        "single migration",
+			rowsCopied:  []int64{1000},
+			copySeconds: []int64{10},
+			expect:      100,
+		},
+		{
+			name:        "multiple migrations",
+			rowsCopied:  []int64{1000, 5000},
+			copySeconds: []int64{10, 20},
+			expect:      200,
+		},
+		{
+			name:        "instantaneous migrations are ignored",
+			rowsCopied:  []int64{1000, 5, 3000},
+			copySeconds: []int64{10, 0, 10},
+			expect:      200,
+		},
+		{
+			name:        "only instantaneous migrations",
+			rowsCopied:  []int64{5},
+			copySeconds: []int64{0},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, copyRate(tc.rowsCopied, tc.copySeconds))
+		})
+	}
+}
+
+func TestConcludeEstimate(t *testing.T) {
+	tt := []struct {
+		name                   string
+		estimate               *tabletmanagerdatapb.SchemaMigrationEstimate
+		expectRequiredDisk     int64
+		expectCopySeconds      int64
+		expectNotesCount       int
+		expectInsufficientDisk bool
+	}{
+		{
+			name: "immediate operation",
+			estimate: &tabletmanagerdatapb.SchemaMigrationEstimate{
+				TableRows:            1000,
+				TableSizeBytes:       65536,
+				IsImmediateOperation: true,
+				FreeDiskBytes:        -1,
+			},
+		},
+		{
+			name: "copy",
+			estimate: &tabletmanagerdatapb.SchemaMigrationEstimate{
+				TableRows:         1000,
+				TableSizeBytes:    65536,
+				FreeDiskBytes:     1 << 30,
+				CopyRowsPerSecond: 300,
+			},
+			expectRequiredDisk: 65536,
+			expectCopySeconds:  4,
+		},
+		{
+			name: "insufficient disk space",
+			estimate: &tabletmanagerdatapb.SchemaMigrationEstimate{
+				TableRows:         1000,
+				TableSizeBytes:    65536,
+				FreeDiskBytes:     1024,
+				CopyRowsPerSecond: 100,
+			},
+			expectRequiredDisk:     65536,
+			expectCopySeconds:      10,
+			expectNotesCount:       1,
+			expectInsufficientDisk: true,
+		},
+		{
+			name: "unknown free disk space",
+			estimate: &tabletmanagerdatapb.SchemaMigrationEstimate{
+				TableRows:         1000,
+				TableSizeBytes:    65536,
+				FreeDiskBytes:     -1,
+				CopyRowsPerSecond: 100,
+			},
+			expectRequiredDisk: 65536,
+			expectCopySeconds:  10,
+		},
+		{
+			name: "unknown copy rate",
+			estimate: &tabletmanagerdatapb.SchemaMigrationEstimate{
+				TableRows:      1000,
+				TableSizeBytes: 65536,
+				FreeDiskBytes:  1 << 30,
+			},
+			expectRequiredDisk: 65536,
+			expectCopySeconds:  -1,
+			expectNotesCount:   1,
+		},
+		{
+			name: "empty table, unknown copy rate",
+			estimate: &tabletmanagerdatapb.SchemaMigrationEstimate{
+				TableSizeBytes: 16384,
+				FreeDiskBytes:  1 << 30,
+			},
+			expectRequiredDisk: 16384,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			concludeEstimate(tc.estimate)
+			assert.Equal(t, tc.expectRequiredDisk, tc.estimate.RequiredDiskBytes)
+			assert.Equal(t, tc.expectCopySeconds, tc.estimate.EstimatedCopySeconds)
+			assert.Len(t, tc.estimate.Notes, tc.expectNotesCount)
+			if tc.expectInsufficientDisk {
+				assert.Contains(t, tc.estimate.Notes[0], "insufficient disk space")
+			}
+		})
+	}
+}
@@ -75,6 +75,8 @@ var (
 	ErrExecutorMigrationAlreadyRunning = errors.New("cannot run migration since a migration is already running")
 	// ErrMigrationNotFound is returned by readMigration when given UUI cannot be found
 	ErrMigrationNotFound = errors.New("migration not found")
+	// ErrForeignKeyOrphanedRows is returned at cut-over when rows of a child table do not reference existing rows of the migrated table
+	ErrForeignKeyOrphanedRows = errors.New("orphaned FOREIGN KEY child rows")
 )
 
 var vexecUpdateTemplates = []string{
@@ -111,6 +113,11 @@ var (
 	migrationCheckInterval  = 1 * time.Minute
 	retainOnlineDDLTables   = 24 * time.Hour
 	maxConcurrentOnlineDDLs = 256
+
+	synchronizedCutOverWindow = 30 * time.Second
+
+	defaultCopyWindow    string
+	defaultCutOverWindow string
 )
 
 func init() {
@@ -124,6 +131,9 @@ func registerOnlineDDLFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&migrationCheckInterval, "migration_check_interval", migrationCheckInterval, "Interval between migration checks")
 	fs.DurationVar(&retainOnlineDDLTables, "retain_online_ddl_tables", retainOnlineDDLTables, "How long should vttablet keep an old migrated table before purging it")
 	fs.IntVar(&maxConcurrentOnlineDDLs, "max_concurrent_online_ddl", maxConcurrentOnlineDDLs, "Maximum number of online DDL changes that may run concurrently")
+	fs.DurationVar(&synchronizedCutOverWindow, "synchronized_cutover_window", synchronizedCutOverWindow, "How long the shards of a --synchronized-cutover migration have to cut over once it is ready on all of them, before the migration is rolled back on all shards")
+	fs.StringVar(&defaultCopyWindow, "online_ddl_copy_window", defaultCopyWindow, "Default daily time windows, in UTC, within which migrations may run their copy phase, e.g. '22:00-06:00'. Applies to migrations which do not specify --copy-window, and is per-tablet: set it identically on all tablets of a keyspace. Empty means no restriction")
+	fs.StringVar(&defaultCutOverWindow, "online_ddl_cutover_window", defaultCutOverWindow, "Default daily time windows, in UTC, within which vitess migrations may cut over, e.g. '01:00-05:00'. Applies to migrations which do not specify --cutover-window, and is per-tablet: set it identically on all tablets of a keyspace. Empty means no restriction")
 }
 
 var migrationNextCheckIntervals = []time.Duration{1 * time.Second, 5 * time.Second, 10 * time.Second, 20 * time.Second}
@@ -691,42 +701,20 @@ func (e *Executor) doesConnectionInfoMatch(ctx context.Context, connID int64, su
 	return len(rs.Rows) == 1, nil
 }
 
-// tableParticipatesInForeignKeyRelationship checks if a given table is either a parent or a child in at least one foreign key constraint
-func (e *Executor) tableParticipatesInForeignKeyRelationship(ctx context.Context, schema string, table string) (bool, error) {
-	for _, fkQuery := range []string{selSelectCountFKParentConstraints, selSelectCountFKChildConstraints} {
-		query, err := sqlparser.ParseAndBind(fkQuery,
-			sqltypes.StringBindVariable(schema),
-			sqltypes.StringBindVariable(table),
-		)
-		if err != nil {
-			return false, err
-		}
-		r, err := e.execQuery(ctx, query)
-		if err != nil {
-			return false, err
-		}
-		row := r.Named().Row()
-		if row == nil {
-			return false, vterrors.Errorf(vtrpcpb.Code_UNKNOWN, "unexpected result from INFORMATION_SCHEMA.KEY_COLUMN_USAGE query: %s", query)
-		}
-		countFKConstraints := row.AsInt64("num_fk_constraints", 0)
-		if countFKConstraints > 0 {
-			return true, nil
-		}
-	}
-	return false, nil
-}
-
 // validateTableForAlterAction checks whether a table is good to undergo a ALTER operation. It returns detailed error if not.
 func (e *Executor) validateTableForAlterAction(ctx context.Context, onlineDDL *schema.OnlineDDL) (err error) {
 	if !onlineDDL.StrategySetting().IsAllowForeignKeysFlag() {
-		// Validate table does not participate in foreign key relationship:
-		participates, err := e.tableParticipatesInForeignKeyRelationship(ctx, onlineDDL.Schema, onlineDDL.Table)
+		// Tables which are parents or children in FOREIGN KEY relationships are supported. Self referencing tables are not.
+		selfReferencing, err := e.tableHasSelfReferencingForeignKey(ctx, onlineDDL.Schema, onlineDDL.Table)
 		if err != nil {
-			return vterrors.Wrapf(err, "error while attempting to validate whether table %s participates in FOREIGN KEY constraint", onlineDDL.Table)
+			return vterrors.Wrapf(err, "error while attempting to validate whether table %s has a self referencing FOREIGN KEY constraint", onlineDDL.Table)
+		}
+		if selfReferencing {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "table %s has a self referencing FOREIGN KEY constraint, which is not supported in Online DDL unless the *experimental and unsafe* --unsafe-allow-foreign-keys strategy flag is specified", onlineDDL.Table)
 		}
-		if participates {
-			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "table %s participates in a FOREIGN KEY constraint and FOREIGN KEY constraints are not supported in Online DDL unless the *experimental and unsafe* --unsafe-allow-foreign-keys strategy flag is specified", onlineDDL.Table)
+		// Child tables are supported as long as their FOREIGN KEY actions do not change their rows. See foreign_keys.go
+		if err := e.validateForeignKeyActions(ctx, onlineDDL.Table); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -793,7 +781,7 @@ func (e *Executor) cutOverVReplMigration(ctx context.Context, s *VReplStream) er
 	}
 
 	// information about source tablet
-	onlineDDL, _, err := e.readMigration(ctx, s.workflow)
+	onlineDDL, migrationRow, err := e.readMigration(ctx, s.workflow)
 	if err != nil {
 		return err
 	}
@@ -824,6 +812,11 @@ func (e *Executor) cutOverVReplMigration(ctx context.Context, s *VReplStream) er
 			return nil
 		}
 
+		// The vrepl table gets its FOREIGN KEY constraints just before it is swapped in. See foreign_keys.go
+		if err := e.attachVReplForeignKeys(ctx, migrationRow, vreplTable); err != nil {
+			return err
+		}
+
 		// We create the sentry table before toggling writes, because this involves a WaitForPos, which takes some time. We
 		// don't want to overload the buffering time with this excessive wait.
 
@@ -1038,6 +1031,35 @@ func (e *Executor) cutOverVReplMigration(ctx context.Context, s *VReplStream) er
 					return err
 				}
 			}
+			// Tables are swapped, and queries on the migrated table are still buffered. The child tables now reference
+			// the artifact table, and are rewired to the migrated table before the buffering is released. See foreign_keys.go
+			e.updateMigrationStage(ctx, onlineDDL.UUID, "rewiring foreign keys")
+			rewireErr := e.rewireForeignKeyChildTables(ctx, onlineDDL, vreplTable, onlineDDL.Table)
+			if rewireErr == nil {
+				e.updateMigrationStage(ctx, onlineDDL.UUID, "validating foreign key child rows")
+				rewireErr = e.validateForeignKeyChildRows(ctx, onlineDDL.Table)
+			}
+			if rewireErr != nil {
+				log.Errorf("cutOverVReplMigration %v: failed rewiring foreign keys, swapping tables back: %v", s.workflow, rewireErr)
+				e.updateMigrationStage(ctx, onlineDDL.UUID, "swapping tables back")
+				if _, err := e.execQuery(ctx, renameQuery.Query); err != nil {
+					return vterrors.Wrapf(err, "failed swapping tables back after failing to rewire foreign keys: %v", rewireErr)
+				}
+				// Child tables which were rewired followed the migrated table back to the vrepl table name
+				if err := e.rewireForeignKeyChildTables(ctx, onlineDDL, vreplTable, onlineDDL.Table); err != nil {
+					return vterrors.Wrapf(err, "failed restoring foreign keys after failing to rewire them: %v", rewireErr)
+				}
+				if _, err := e.vreplicationExec(ctx, tablet.Tablet, binlogplayer.StartVReplication(uint32(s.id))); err != nil {
+					return vterrors.Wrapf(err, "failed restarting vreplication after failing to rewire foreign keys: %v", rewireErr)
+				}
+				return rewireErr
+			}
+			// The artifact table no longer serves, and so a failure to detach its constraints does not fail the cut-over.
+			// It is reported in the migration's message.
+			if err := e.detachArtifactForeignKeys(ctx, onlineDDL, vreplTable); err != nil {
+				log.Errorf("cutOverVReplMigration %v: failed detaching foreign keys of artifact table: %v", s.workflow, err)
+				_ = e.updateMigrationMessage(ctx, onlineDDL.UUID, fmt.Sprintf("failed detaching foreign keys of artifact table: %v", err))
+			}
 		}
 	}
 	e.updateMigrationStage(ctx, onlineDDL.UUID, "cut-over complete")
@@ -1144,18 +1166,13 @@ func (e *Executor) newConstraintName(onlineDDL *schema.OnlineDDL, constraintType
 }
 
 // validateAndEditCreateTableStatement inspects the CreateTable AST and does the following:
-// - extra validation (no FKs for now...)
-// - generate new and unique names for all constraints (CHECK and FK; yes, why not handle FK names; even as we don't support FKs today, we may in the future)
+// - generate new and unique names for all constraints (CHECK and FK)
 func (e *Executor) validateAndEditCreateTableStatement(ctx context.Context, onlineDDL *schema.OnlineDDL, createTable *sqlparser.CreateTable) (constraintMap map[string]string, err error) {
 	constraintMap = map[string]string{}
 	hashExists := map[string]bool{}
 
 	validateWalk := func(node sqlparser.SQLNode) (kontinue bool, err error) {
 		switch node := node.(type) {
-		case *sqlparser.ForeignKeyDefinition:
-			if !onlineDDL.StrategySetting().IsAllowForeignKeysFlag() {
-				return false, schema.ErrForeignKeyFound
-			}
 		case *sqlparser.ConstraintDefinition:
 			oldName := node.Name.String()
 			newName := e.newConstraintName(onlineDDL, GetConstraintType(node.Details), hashExists, sqlparser.CanonicalString(node.Details), oldName)
@@ -1178,8 +1195,8 @@ func (e *Executor) validateAndEditAlterTableStatement(ctx context.Context, onlin
 	validateWalk := func(node sqlparser.SQLNode) (kontinue bool, err error) {
 		switch node := node.(type) {
 		case *sqlparser.DropKey:
-			if node.Type == sqlparser.CheckKeyType {
-				// drop a check constraint
+			if node.Type == sqlparser.CheckKeyType || node.Type == sqlparser.ForeignKeyType {
+				// drop a check or a foreign key constraint
 				mappedName, ok := constraintMap[node.Name.String()]
 				if !ok {
 					return false, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Found DROP CONSTRAINT: %v, but could not find constraint name in map", sqlparser.CanonicalString(node))
@@ -1301,6 +1318,12 @@ func (e *Executor) initVreplicationOriginalMigration(ctx context.Context, online
 			}
 		}
 	}
+	if err := e.detachVReplForeignKeys(ctx, onlineDDL, vreplTableName, conn); err != nil {
+		return v, err
+	}
+	if err := e.validateForeignKeyChildTables(ctx, onlineDDL, vreplTableName); err != nil {
+		return v, err
+	}
 	v = NewVRepl(onlineDDL.UUID, e.keyspace, e.shard, e.dbName, onlineDDL.Table, vreplTableName, onlineDDL.SQL)
 	return v, nil
 }
@@ -1355,6 +1378,23 @@ func (e *Executor) initVreplicationRevertMigration(ctx context.Context, onlineDD
 	if err := e.updateArtifacts(ctx, onlineDDL.UUID, vreplTableName); err != nil {
 		return v, err
 	}
+	// Changes to the table are vreplicated back into the artifact table, and so its FOREIGN KEY actions must not change rows
+	if !onlineDDL.StrategySetting().IsAllowForeignKeysFlag() {
+		if err := e.validateForeignKeyActions(ctx, onlineDDL.Table); err != nil {
+			return v, err
+		}
+	}
+	// The FOREIGN KEY constraints detached from the reverted table at cut-over are attached back at this migration's cut-over
+	_, revertedRow, err := e.readMigration(ctx, revertMigration.UUID)
+	if err != nil {
+		return v, err
+	}
+	if err := e.updateMigrationForeignKeys(ctx, sqlUpdateVReplForeignKeys, onlineDDL.UUID, revertedRow.AsString("artifact_foreign_keys", "")); err != nil {
+		return v, err
+	}
+	if err := e.validateForeignKeyChildTables(ctx, onlineDDL, vreplTableName); err != nil {
+		return v, err
+	}
 	v = NewVRepl(onlineDDL.UUID, e.keyspace, e.shard, e.dbName, onlineDDL.Table, vreplTableName, "")
 	v.pos = revertStream.pos
 	return v, nil
@@ -2205,6 +2245,14 @@ func (e *Executor) scheduleNextMigration(ctx context.Context) error {
 		}
 
 		if !(isImmediateOperation && postponeCompletion) {
+			isWindowOpen, err := e.reviewQueuedMigrationWindow(ctx, row, isImmediateOperation)
+			if err != nil {
+				return err
+			}
+			if !isWindowOpen {
+				// The migration will be scheduled once its window opens
+				continue
+			}
 			// Any non-postponed migration can be scheduled
 			// postponed ALTER can be scheduled (because gh-ost or vreplication will postpone the cut-over)
 			// We only schedule a single migration in the execution of this function
@@ -2305,6 +2353,7 @@ func (e *Executor) reviewImmediateOperations(ctx context.Context, capableOf mysq
 // The function analyzes the queued migration and fills in some blanks:
 // - If this is a REVERT migration, what table is affected? What's the operation?
 // - Is this migration an "immediate operation"?
+// - Is this a dry run migration? If so, it is estimated and cancelled.
 func (e *Executor) reviewQueuedMigrations(ctx context.Context) error {
 	conn, err := dbconnpool.NewDBConnection(ctx, e.env.Config().DB.DbaWithDB())
 	if err != nil {
@@ -2355,6 +2404,12 @@ func (e *Executor) reviewQueuedMigrations(ctx context.Context) error {
 				return err
 			}
 		}
+		if onlineDDL.StrategySetting().IsDryRun() {
+			// A dry run migration is estimated, then cancelled. It never runs.
+			if err := e.concludeDryRunMigration(ctx, capableOf, onlineDDL, ddlAction, isRevert, isView); err != nil {
+				return err
+			}
+		}
 		// The review is complete. We've backfilled details on the migration row. We mark
 		// the migration as having been reviewed. The function scheduleNextMigration() will then
 		// have access to this row.
@@ -2366,6 +2421,9 @@ func (e *Executor) reviewQueuedMigrations(ctx context.Context) error {
 }
 
 func (e *Executor) validateMigrationRevertible(ctx context.Context, revertMigration *schema.OnlineDDL, revertingMigrationUUID string) (err error) {
+	if revertMigration.IsStoredProgram() {
+		return fmt.Errorf("cannot revert migration %s: triggers, routines and events cannot be reverted", revertMigration.UUID)
+	}
 	// Validation: migration to revert exists and is in complete state
 	action, actionStr, err := revertMigration.GetActionStr()
 	if err != nil {
@@ -3105,6 +3163,14 @@ func (e *Executor) executeMigration(ctx context.Context, onlineDDL *schema.Onlin
 		}
 	}
 
+	if onlineDDL.IsStoredProgram() {
+		// Triggers, routines and events have no data to copy. They are handled separately, including in declarative mode
+		go func() error {
+			return e.executeStoredProgramMigration(ctx, onlineDDL)
+		}()
+		return nil
+	}
+
 	if onlineDDL.StrategySetting().IsDeclarative() {
 		switch ddlAction {
 		case sqlparser.RevertDDLAction:
@@ -3504,6 +3570,8 @@ func (e *Executor) reviewRunningMigrations(ctx context.Context) (countRunnning i
 			return countRunnning, cancellable, err
 		}
 		postponeCompletion := row.AsBool("postpone_completion", false)
+		synchronizedCutOver := row.AsBool("synchronized_cutover", false)
+		cutOverWindowOpen := row.AsBool("cutover_window_open", false)
 		elapsedSeconds := row.AsInt64("elapsed_seconds", 0)
 
 		if stowawayTable := row.AsString("stowaway_table", ""); stowawayTable != "" {
@@ -3533,6 +3601,20 @@ func (e *Executor) reviewRunningMigrations(ctx context.Context) (countRunnning i
 		uuidsFoundRunning[uuid] = true
 
 		_ = e.updateMigrationUserThrottleRatio(ctx, uuid, currentUserThrottleRatio)
+
+		copyWindow, cutOverWindow, err := readMigrationWindows(row)
+		if err != nil {
+			return countRunnning, cancellable, err
+		}
+		now := time.Now()
+		newWindowStatus, windowOpensAt := windowStatusOpen, time.Time{}
+		if !row.AsBool("ready_to_complete", false) {
+			// The migration is copying. Outside its copy window, we hold it back.
+			newWindowStatus, windowOpensAt = windowStatus(copyWindow, windowStatusCopyWindowClosed, now)
+			if newWindowStatus != windowStatusOpen {
+				e.throttleUntilWindowOpens(uuid, windowOpensAt)
+			}
+		}
 		switch onlineDDL.StrategySetting().Strategy {
 		case schema.DDLStrategyOnline, schema.DDLStrategyVitess:
 			{
@@ -3576,6 +3658,13 @@ func (e *Executor) reviewRunningMigrations(ctx context.Context) (countRunnning i
 							isReady = false
 						}
 					}
+					cutOverWindowStatus, cutOverWindowOpensAt := windowStatus(cutOverWindow, windowStatusCutOverWindowClosed, now)
+					isCutOverWindowClosed := isReady && cutOverWindowStatus != windowStatusOpen
+					if isCutOverWindowClosed && synchronizedCutOver {
+						// The coordinating shard opens the synchronized cut-over window once all shards are ready.
+						// A shard outside its cut-over window does not claim to be ready, lest it fails the cut-over on all shards.
+						isReady = false
+					}
 					// Indicate to outside observers whether the migration is generally ready to complete.
 					// In the case of a postponed migration, we will not complete it, but the user will
 					// understand whether "now is a good time" or "not there yet"
@@ -3584,6 +3673,19 @@ func (e *Executor) reviewRunningMigrations(ctx context.Context) (countRunnning i
 						// override. Even if migration is ready, we do not complete it.
 						isReady = false
 					}
+					if isCutOverWindowClosed {
+						// override. The migration cuts over once its cut-over window opens.
+						isReady = false
+						newWindowStatus, windowOpensAt = cutOverWindowStatus, cutOverWindowOpensAt
+					}
+					if isReady && synchronizedCutOver {
+						// The migration cuts over along with the other shards, while the cut-over window opened by the
+						// coordinating shard is open. The window is short, so we review the migration often.
+						e.triggerNextCheckInterval()
+						if !cutOverWindowOpen {
+							isReady = false
+						}
+					}
 					if isReady {
 						if err := e.cutOverVReplMigration(ctx, s); err != nil {
 							_ = e.updateMigrationMessage(ctx, uuid, err.Error())
@@ -3594,6 +3696,10 @@ func (e *Executor) reviewRunningMigrations(ctx context.Context) (countRunnning i
 									go e.CancelMigration(ctx, uuid, err.Error(), false)
 								}
 							}
+							if errors.Is(err, ErrForeignKeyOrphanedRows) {
+								// The migrated table does not satisfy the child tables, and would not on any further attempt
+								go e.CancelMigration(ctx, uuid, err.Error(), false)
+							}
 							return countRunnning, cancellable, err
 						}
 					}
@@ -3650,6 +3756,7 @@ func (e *Executor) reviewRunningMigrations(ctx context.Context) (countRunnning i
 				}
 			}
 		}
+		_ = e.updateMigrationWindowStatus(ctx, uuid, row.AsString("window_status", ""), newWindowStatus, windowOpensAt)
 		countRunnning++
 	}
 	{
@@ -3930,6 +4037,9 @@ func (e *Executor) onMigrationCheckTick() {
 	} else if err := e.cancelMigrations(ctx, cancellable, false); err != nil {
 		log.Error(err)
 	}
+	if err := e.reviewSynchronizedCutOvers(ctx); err != nil {
+		log.Error(err)
+	}
 	if err := e.reviewStaleMigrations(ctx); err != nil {
 		log.Error(err)
 	}
@@ -4592,6 +4702,18 @@ func (e *Executor) SubmitMigration(
 	log.Infof("SubmitMigration: request to submit migration %s; action=%s, table=%s", onlineDDL.UUID, actionStr, onlineDDL.Table)
 
 	revertedUUID, _ := onlineDDL.GetRevertUUID() // Empty value if the migration is not actually a REVERT. Safe to ignore error.
+	synchronizedCutOver := onlineDDL.StrategySetting().IsSynchronizedCutOver()
+	if synchronizedCutOver {
+		switch onlineDDL.Strategy {
+		case schema.DDLStrategyOnline, schema.DDLStrategyVitess:
+		default:
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "--synchronized-cutover is only supported by the vitess strategy, found: %v", onlineDDL.Strategy)
+		}
+	}
+	copyWindow, cutOverWindow, err := submissionWindows(onlineDDL)
+	if err != nil {
+		return nil, err
+	}
 	retainArtifactsSeconds := int64((retainOnlineDDLTables).Seconds())
 	_, allowConcurrentMigration := e.allowConcurrentMigration(onlineDDL)
 	query, err := sqlparser.ParseAndBind(sqlInsertMigration,
@@ -4613,6 +4735,9 @@ func (e *Executor) SubmitMigration(
 		sqltypes.BoolBindVariable(allowConcurrentMigration),
 		sqltypes.StringBindVariable(revertedUUID),
 		sqltypes.BoolBindVariable(onlineDDL.IsView()),
+		sqltypes.BoolBindVariable(synchronizedCutOver),
+		sqltypes.StringBindVariable(copyWindow.String()),
+		sqltypes.StringBindVariable(cutOverWindow.String()),
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,457 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+VReplication migrations support tables which are children or parents in FOREIGN KEY relationships.
+
+A child table has FOREIGN KEY constraints of its own:
+
+- The vrepl table is created with the table's constraints, and the migration's ALTER TABLE applies to them.
+  The constraints are then detached from the vrepl table, and recorded in the migration's vrepl_foreign_keys.
+  Were the constraints in place while vreplication populates the vrepl table, a lagging vrepl table would
+  fail vreplication on rows whose parent is since deleted, and would block deletes on the parent table.
+- At cut-over, just before the tables are swapped, the recorded constraints are attached to the vrepl table.
+- Once swapped, the constraints of the artifact table (the original table) are recorded in the migration's
+  artifact_foreign_keys, and detached from the artifact table, so that it does not restrict its parent tables.
+  A REVERT of the migration attaches these constraints back as it swaps the artifact table back in.
+
+A parent table is referenced by the constraints of child tables. As the tables are swapped, MySQL renames
+these references along with the original table, so that the child tables end up referencing the artifact
+table. The child constraints cannot be rewritten while the tables are locked: altering a child table takes
+metadata locks on the tables it references, which LOCK TABLES holds until the RENAME runs. They are rewritten
+to reference the migrated table right after the RENAME completes, while queries on the migrated table are
+still buffered. Until then, no row is added to nor removed from the migrated table, and the artifact table,
+which the child tables reference, holds the very same rows. Writes which bypass vttablet are not buffered,
+and are not covered. The child rows are then validated against the migrated table, which is a full scan of
+the child tables. Should the rewrite or the validation fail, the tables are swapped back, the child
+constraints are pointed at the original table again, and vreplication resumes. Orphaned child rows, e.g.
+because the migration changed the values of referenced columns, fail the migration.
+
+All constraints are attached and rewritten with foreign_key_checks=0, which MySQL applies in-place, without
+validating existing rows and without rebuilding the tables. VReplication applies events to the vrepl table with
+foreign_key_checks=0 as well, up to the cut-over: the attached constraints must not fail a row whose parent row
+was deleted after the row was written to the original table.
+
+Migrating a child table is only supported when all of its constraints are ON DELETE and ON UPDATE RESTRICT or
+NO ACTION. MySQL applies CASCADE, SET NULL and SET DEFAULT actions within InnoDB, and does not write the rows
+they change to the binary log. VReplication would never see these changes, and the vrepl table would silently
+diverge from the original table. Such migrations, and reverts of such tables, are refused up front, unless
+--unsafe-allow-foreign-keys is given. Parent tables are not affected: the actions change rows of the child
+tables, which are not migrated.
+*/
+
+package onlineddl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// foreignKeyConstraints returns the FOREIGN KEY constraints of a CREATE TABLE statement
+func foreignKeyConstraints(createTable *sqlparser.CreateTable) (constraints []*sqlparser.ConstraintDefinition) {
+	for _, constraint := range createTable.TableSpec.Constraints {
+		if _, ok := constraint.Details.(*sqlparser.ForeignKeyDefinition); ok {
+			constraints = append(constraints, constraint)
+		}
+	}
+	return constraints
+}
+
+// referencedTableName returns the name of the table referenced by a FOREIGN KEY constraint
+func referencedTableName(constraint *sqlparser.ConstraintDefinition) string {
+	fk, ok := constraint.Details.(*sqlparser.ForeignKeyDefinition)
+	if !ok {
+		return ""
+	}
+	return fk.ReferenceDefinition.ReferencedTable.Name.String()
+}
+
+// isRestrictingReferenceAction returns true when a FOREIGN KEY action only restricts changes to the parent table,
+// and does not itself change rows of the child table
+func isRestrictingReferenceAction(action sqlparser.ReferenceAction) bool {
+	switch action {
+	case sqlparser.DefaultAction, sqlparser.Restrict, sqlparser.NoAction:
+		return true
+	}
+	return false
+}
+
+// nonRestrictingForeignKeyConstraint returns the first FOREIGN KEY constraint of a CREATE TABLE statement with
+// an ON DELETE or ON UPDATE action that changes rows of the table, e.g. CASCADE, or nil if there is none
+func nonRestrictingForeignKeyConstraint(createTable *sqlparser.CreateTable) *sqlparser.ConstraintDefinition {
+	for _, constraint := range foreignKeyConstraints(createTable) {
+		referenceDefinition := constraint.Details.(*sqlparser.ForeignKeyDefinition).ReferenceDefinition
+		if !isRestrictingReferenceAction(referenceDefinition.OnDelete) || !isRestrictingReferenceAction(referenceDefinition.OnUpdate) {
+			return constraint
+		}
+	}
+	return nil
+}
+
+// addForeignKeysStatement returns an ALTER TABLE statement which adds the given constraints to the given table,
+// or an empty string if there are no constraints
+func addForeignKeysStatement(tableName string, constraints []*sqlparser.ConstraintDefinition) string {
+	if len(constraints) == 0 {
+		return ""
+	}
+	alterTable := &sqlparser.AlterTable{Table: sqlparser.TableName{Name: sqlparser.NewIdentifierCS(tableName)}}
+	for _, constraint := range constraints {
+		alterTable.AlterOptions = append(alterTable.AlterOptions, &sqlparser.AddConstraintDefinition{ConstraintDefinition: constraint})
+	}
+	return sqlparser.CanonicalString(alterTable)
+}
+
+// dropForeignKeysStatement returns an ALTER TABLE statement which drops the given constraints from the given table,
+// or an empty string if there are no constraints
+func dropForeignKeysStatement(tableName string, constraints []*sqlparser.ConstraintDefinition) string {
+	if len(constraints) == 0 {
+		return ""
+	}
+	alterTable := &sqlparser.AlterTable{Table: sqlparser.TableName{Name: sqlparser.NewIdentifierCS(tableName)}}
+	for _, constraint := range constraints {
+		alterTable.AlterOptions = append(alterTable.AlterOptions, &sqlparser.DropKey{Type: sqlparser.ForeignKeyType, Name: constraint.Name})
+	}
+	return sqlparser.CanonicalString(alterTable)
+}
+
+// rewriteReferencesStatement returns an ALTER TABLE statement which rewrites the constraints of a child table that
+// reference fromTableName, to reference toTableName instead. It returns an empty string if there are no such constraints.
+func rewriteReferencesStatement(childTable *sqlparser.CreateTable, fromTableName string, toTableName string) string {
+	alterTable := &sqlparser.AlterTable{Table: sqlparser.TableName{Name: childTable.GetTable().Name}}
+	for _, constraint := range foreignKeyConstraints(childTable) {
+		if referencedTableName(constraint) != fromTableName {
+			continue
+		}
+		rewritten := sqlparser.CloneRefOfConstraintDefinition(constraint)
+		rewritten.Details.(*sqlparser.ForeignKeyDefinition).ReferenceDefinition.ReferencedTable = sqlparser.TableName{Name: sqlparser.NewIdentifierCS(toTableName)}
+		// MySQL supports dropping and adding a FOREIGN KEY in the same in-place ALTER TABLE statement
+		alterTable.AlterOptions = append(alterTable.AlterOptions,
+			&sqlparser.DropKey{Type: sqlparser.ForeignKeyType, Name: constraint.Name},
+			&sqlparser.AddConstraintDefinition{ConstraintDefinition: rewritten},
+		)
+	}
+	if len(alterTable.AlterOptions) == 0 {
+		return ""
+	}
+	return sqlparser.CanonicalString(alterTable)
+}
+
+// orphanedChildRowsQuery returns a query which reads a row of the child table, if any, whose FOREIGN KEY constraint
+// does not reference an existing row of the parent table. As in MySQL, rows with a NULL in any of the constraint's
+// columns reference nothing, and are not checked.
+func orphanedChildRowsQuery(childTableName string, constraint *sqlparser.ConstraintDefinition, parentTableName string) string {
+	fk := constraint.Details.(*sqlparser.ForeignKeyDefinition)
+	child, parent := sqlescape.EscapeID(childTableName), sqlescape.EscapeID(parentTableName)
+	var notNull, references []string
+	for i, column := range fk.Source {
+		childColumn := fmt.Sprintf("%s.%s", child, sqlescape.EscapeID(column.String()))
+		notNull = append(notNull, fmt.Sprintf("%s is not null", childColumn))
+		references = append(references, fmt.Sprintf("%s.%s = %s", parent, sqlescape.EscapeID(fk.ReferenceDefinition.ReferencedColumns[i].String()), childColumn))
+	}
+	return fmt.Sprintf("select 1 from %s where %s and not exists (select 1 from %s where %s) limit 1",
+		child, strings.Join(notNull, " and "), parent, strings.Join(references, " and "))
+}
+
+// indexCoversColumns returns true when the table has an index whose leftmost columns are the given columns,
+// in any order. This is what MySQL requires of a table referenced by a FOREIGN KEY constraint.
+func indexCoversColumns(createTable *sqlparser.CreateTable, columns sqlparser.Columns) bool {
+	for _, index := range createTable.TableSpec.Indexes {
+		if len(index.Columns) < len(columns) {
+			continue
+		}
+		prefix := map[string]bool{}
+		for _, indexColumn := range index.Columns[0:len(columns)] {
+			prefix[indexColumn.Column.Lowered()] = true
+		}
+		covered := true
+		for _, column := range columns {
+			if !prefix[column.Lowered()] {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// readCreateTable reads and parses the CREATE TABLE statement of the given table
+func (e *Executor) readCreateTable(ctx context.Context, tableName string) (*sqlparser.CreateTable, error) {
+	showCreateTable, err := e.showCreateTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := sqlparser.ParseStrictDDL(showCreateTable)
+	if err != nil {
+		return nil, err
+	}
+	createTable, ok := stmt.(*sqlparser.CreateTable)
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "expected CreateTable statement, got: %v", sqlparser.CanonicalString(stmt))
+	}
+	return createTable, nil
+}
+
+// readForeignKeyChildTables returns the names of the tables, other than the given table itself, with FOREIGN KEY
+// constraints referencing the given table
+func (e *Executor) readForeignKeyChildTables(ctx context.Context, tableName string) (childTables []string, err error) {
+	query, err := sqlparser.ParseAndBind(sqlSelectFKChildTables,
+		sqltypes.StringBindVariable(e.dbName),
+		sqltypes.StringBindVariable(tableName),
+	)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.execQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range r.Named().Rows {
+		childTables = append(childTables, row.AsString("table_name", ""))
+	}
+	return childTables, nil
+}
+
+// tableHasSelfReferencingForeignKey checks if a given table has a FOREIGN KEY constraint referencing the table itself
+func (e *Executor) tableHasSelfReferencingForeignKey(ctx context.Context, schema string, table string) (bool, error) {
+	query, err := sqlparser.ParseAndBind(selSelectCountFKSelfReferencingConstraints,
+		sqltypes.StringBindVariable(schema),
+		sqltypes.StringBindVariable(table),
+	)
+	if err != nil {
+		return false, err
+	}
+	r, err := e.execQuery(ctx, query)
+	if err != nil {
+		return false, err
+	}
+	row := r.Named().Row()
+	if row == nil {
+		return false, vterrors.Errorf(vtrpcpb.Code_UNKNOWN, "unexpected result from INFORMATION_SCHEMA.KEY_COLUMN_USAGE query: %s", query)
+	}
+	return row.AsInt64("num_fk_constraints", 0) > 0, nil
+}
+
+// validateForeignKeyActions checks that the FOREIGN KEY constraints of the given table do not change its rows, which
+// MySQL does not write to the binary log, and which vreplication therefore cannot apply to the vrepl table
+func (e *Executor) validateForeignKeyActions(ctx context.Context, tableName string) error {
+	createTable, err := e.readCreateTable(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	if constraint := nonRestrictingForeignKeyConstraint(createTable); constraint != nil {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table %s has FOREIGN KEY constraint %s with a cascading action, whose changes are not written to the binary log. Only RESTRICT and NO ACTION are supported in Online DDL unless the *experimental and unsafe* --unsafe-allow-foreign-keys strategy flag is specified: %s",
+			tableName, constraint.Name.String(), sqlparser.CanonicalString(constraint))
+	}
+	return nil
+}
+
+// execWithoutForeignKeyChecks runs the given statements on a dedicated connection, with foreign_key_checks=0
+func (e *Executor) execWithoutForeignKeyChecks(ctx context.Context, statements ...string) error {
+	conn, err := dbconnpool.NewDBConnection(ctx, e.env.Config().DB.DbaWithDB())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecuteFetch(sqlDisableForeignKeyChecks, 0, false); err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if statement == "" {
+			continue
+		}
+		if _, err := conn.ExecuteFetch(statement, 0, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Executor) updateMigrationForeignKeys(ctx context.Context, sqlQuery string, uuid string, statement string) error {
+	query, err := sqlparser.ParseAndBind(sqlQuery,
+		sqltypes.StringBindVariable(statement),
+		sqltypes.StringBindVariable(uuid),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = e.execQuery(ctx, query)
+	return err
+}
+
+// detachVReplForeignKeys removes the FOREIGN KEY constraints of a newly created and altered vrepl table, and records
+// them in the migration, so that they are attached back at cut-over
+func (e *Executor) detachVReplForeignKeys(ctx context.Context, onlineDDL *schema.OnlineDDL, vreplTableName string, conn *dbconnpool.DBConnection) error {
+	createTable, err := e.readCreateTable(ctx, vreplTableName)
+	if err != nil {
+		return err
+	}
+	constraints := foreignKeyConstraints(createTable)
+	if len(constraints) == 0 {
+		return nil
+	}
+	if err := e.updateMigrationForeignKeys(ctx, sqlUpdateVReplForeignKeys, onlineDDL.UUID, addForeignKeysStatement(vreplTableName, constraints)); err != nil {
+		return err
+	}
+	_, err = conn.ExecuteFetch(dropForeignKeysStatement(vreplTableName, constraints), 0, false)
+	return err
+}
+
+// validateForeignKeyChildTables checks that the FOREIGN KEY constraints of the child tables of the migrated table
+// are able to reference the given vrepl table, once it is swapped in at cut-over
+func (e *Executor) validateForeignKeyChildTables(ctx context.Context, onlineDDL *schema.OnlineDDL, vreplTableName string) error {
+	childTables, err := e.readForeignKeyChildTables(ctx, onlineDDL.Table)
+	if err != nil {
+		return err
+	}
+	if len(childTables) == 0 {
+		return nil
+	}
+	vreplCreateTable, err := e.readCreateTable(ctx, vreplTableName)
+	if err != nil {
+		return err
+	}
+	for _, childTable := range childTables {
+		childCreateTable, err := e.readCreateTable(ctx, childTable)
+		if err != nil {
+			return err
+		}
+		for _, constraint := range foreignKeyConstraints(childCreateTable) {
+			if referencedTableName(constraint) != onlineDDL.Table {
+				continue
+			}
+			referencedColumns := constraint.Details.(*sqlparser.ForeignKeyDefinition).ReferenceDefinition.ReferencedColumns
+			if !indexCoversColumns(vreplCreateTable, referencedColumns) {
+				return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "FOREIGN KEY constraint %s of child table %s references columns (%s), which are not indexed in the migrated table %s",
+					constraint.Name.String(), childTable, sqlparser.String(referencedColumns), onlineDDL.Table)
+			}
+		}
+	}
+	return nil
+}
+
+// attachVReplForeignKeys adds the FOREIGN KEY constraints recorded for the migration to the vrepl table. It is called
+// at cut-over, before the tables are swapped. Constraints already attached by a previous cut-over attempt are skipped.
+func (e *Executor) attachVReplForeignKeys(ctx context.Context, migrationRow sqltypes.RowNamedValues, vreplTableName string) error {
+	statement := migrationRow.AsString("vrepl_foreign_keys", "")
+	if statement == "" {
+		return nil
+	}
+	stmt, err := sqlparser.ParseStrictDDL(statement)
+	if err != nil {
+		return err
+	}
+	alterTable, ok := stmt.(*sqlparser.AlterTable)
+	if !ok {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "expected AlterTable statement, got: %v", sqlparser.CanonicalString(stmt))
+	}
+	createTable, err := e.readCreateTable(ctx, vreplTableName)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, constraint := range foreignKeyConstraints(createTable) {
+		existing[constraint.Name.Lowered()] = true
+	}
+	var constraints []*sqlparser.ConstraintDefinition
+	for _, option := range alterTable.AlterOptions {
+		if addConstraint, ok := option.(*sqlparser.AddConstraintDefinition); ok && !existing[addConstraint.ConstraintDefinition.Name.Lowered()] {
+			constraints = append(constraints, addConstraint.ConstraintDefinition)
+		}
+	}
+	return e.execWithoutForeignKeyChecks(ctx, addForeignKeysStatement(vreplTableName, constraints))
+}
+
+// rewireForeignKeyChildTables rewrites the constraints of the child tables of fromTableName to reference toTableName
+func (e *Executor) rewireForeignKeyChildTables(ctx context.Context, onlineDDL *schema.OnlineDDL, fromTableName string, toTableName string) error {
+	childTables, err := e.readForeignKeyChildTables(ctx, fromTableName)
+	if err != nil {
+		return err
+	}
+	if len(childTables) == 0 {
+		return nil
+	}
+	var statements []string
+	for _, childTable := range childTables {
+		childCreateTable, err := e.readCreateTable(ctx, childTable)
+		if err != nil {
+			return err
+		}
+		statements = append(statements, rewriteReferencesStatement(childCreateTable, fromTableName, toTableName))
+	}
+	log.Infof("rewiring foreign keys of child tables %s from %s to %s in migration %s", strings.Join(childTables, ","), fromTableName, toTableName, onlineDDL.UUID)
+	return e.execWithoutForeignKeyChecks(ctx, statements...)
+}
+
+// validateForeignKeyChildRows checks that all rows of the child tables of the given table reference existing rows
+// of the table. The constraints are attached and rewritten without checks, and so this is where their rows are checked.
+func (e *Executor) validateForeignKeyChildRows(ctx context.Context, tableName string) error {
+	childTables, err := e.readForeignKeyChildTables(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	for _, childTable := range childTables {
+		childCreateTable, err := e.readCreateTable(ctx, childTable)
+		if err != nil {
+			return err
+		}
+		for _, constraint := range foreignKeyConstraints(childCreateTable) {
+			if referencedTableName(constraint) != tableName {
+				continue
+			}
+			r, err := e.execQuery(ctx, orphanedChildRowsQuery(childTable, constraint, tableName))
+			if err != nil {
+				return err
+			}
+			if len(r.Rows) > 0 {
+				return fmt.Errorf("%w: FOREIGN KEY constraint %s of child table %s references rows which do not exist in %s",
+					ErrForeignKeyOrphanedRows, constraint.Name.String(), childTable, tableName)
+			}
+		}
+	}
+	return nil
+}
+
+// detachArtifactForeignKeys is called at cut-over, once the tables are swapped. It records and detaches the constraints
+// of the artifact table, so that the artifact table does not restrict its parent tables.
+func (e *Executor) detachArtifactForeignKeys(ctx context.Context, onlineDDL *schema.OnlineDDL, artifactTableName string) error {
+	artifactCreateTable, err := e.readCreateTable(ctx, artifactTableName)
+	if err != nil {
+		return err
+	}
+	artifactConstraints := foreignKeyConstraints(artifactCreateTable)
+	if len(artifactConstraints) == 0 {
+		return nil
+	}
+	if err := e.updateMigrationForeignKeys(ctx, sqlUpdateArtifactForeignKeys, onlineDDL.UUID, addForeignKeysStatement(artifactTableName, artifactConstraints)); err != nil {
+		return err
+	}
+	return e.execWithoutForeignKeyChecks(ctx, dropForeignKeysStatement(artifactTableName, artifactConstraints))
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func parseCreateTable(t *testing.T, sql string) *sqlparser.CreateTable {
+	stmt, err := sqlparser.ParseStrictDDL(sql)
+	require.NoError(t, err)
+	createTable, ok := stmt.(*sqlparser.CreateTable)
+	require.True(t, ok)
+	return createTable
+}
+
+func TestForeignKeysStatements(t *testing.T) {
+	createTable := parseCreateTable(t, `
+		create table child (
+			id int,
+			parent_id int,
+			other_id int,
+			primary key(id),
+			key parent_idx (parent_id),
+			constraint child_parent_fk foreign key (parent_id) references parent (id) on delete cascade,
+			constraint child_other_fk foreign key (other_id) references other (id),
+			constraint child_chk check (id > 0)
+		)`)
+	constraints := foreignKeyConstraints(createTable)
+	require.Len(t, constraints, 2)
+	assert.Equal(t, "parent", referencedTableName(constraints[0]))
+	assert.Equal(t, "other", referencedTableName(constraints[1]))
+
+	t.Run("add", func(t *testing.T) {
+		assert.Empty(t, addForeignKeysStatement("child", nil))
+		assert.Equal(t,
+			"ALTER TABLE `child` ADD CONSTRAINT `child_parent_fk` FOREIGN KEY (`parent_id`) REFERENCES `parent` (`id`) ON DELETE CASCADE, ADD CONSTRAINT `child_other_fk` FOREIGN KEY (`other_id`) REFERENCES `other` (`id`)",
+			addForeignKeysStatement("child", constraints),
+		)
+	})
+	t.Run("drop", func(t *testing.T) {
+		assert.Empty(t, dropForeignKeysStatement("child", nil))
+		assert.Equal(t,
+			"ALTER TABLE `child` DROP FOREIGN KEY `child_parent_fk`, DROP FOREIGN KEY `child_other_fk`",
+			dropForeignKeysStatement("child", constraints),
+		)
+	})
+	t.Run("rewrite", func(t *testing.T) {
+		assert.Empty(t, rewriteReferencesStatement(createTable, "no_such_table", "parent_new"))
+		assert.Equal(t,
+			"ALTER TABLE `child` DROP FOREIGN KEY `child_parent_fk`, ADD CONSTRAINT `child_parent_fk` FOREIGN KEY (`parent_id`) REFERENCES `parent_new` (`id`) ON DELETE CASCADE",
+			rewriteReferencesStatement(createTable, "parent", "parent_new"),
+		)
+		// the original statement is left intact
+		assert.Equal(t, "parent", referencedTableName(foreignKeyConstraints(createTable)[0]))
+	})
+	t.Run("orphaned rows", func(t *testing.T) {
+		assert.Equal(t,
+			"select 1 from `child` where `child`.`parent_id` is not null and not exists (select 1 from `parent_new` where `parent_new`.`id` = `child`.`parent_id`) limit 1",
+			orphanedChildRowsQuery("child", constraints[0], "parent_new"),
+		)
+		multiColumn := parseCreateTable(t, `
+			create table child (
+				id int,
+				a int,
+				b int,
+				primary key(id),
+				key ab_idx (a, b),
+				constraint child_ab_fk foreign key (a, b) references parent (pa, pb)
+			)`)
+		assert.Equal(t,
+			"select 1 from `child` where `child`.`a` is not null and `child`.`b` is not null and not exists (select 1 from `parent` where `parent`.`pa` = `child`.`a` and `parent`.`pb` = `child`.`b`) limit 1",
+			orphanedChildRowsQuery("child", foreignKeyConstraints(multiColumn)[0], "parent"),
+		)
+	})
+}
+
+func TestIndexCoversColumns(t *testing.T) {
+	createTable := parseCreateTable(t, `
+		create table parent (
+			id int,
+			a int,
+			b int,
+			c int,
+			primary key(id),
+			key ab_idx (a, b)
+		)`)
+	tt := []struct {
+		columns []string
+		expect  bool
+	}{
+		{columns: []string{"id"}, expect: true},
+		{columns: []string{"ID"}, expect: true},
+		{columns: []string{"a"}, expect: true},
+		{columns: []string{"a", "b"}, expect: true},
+		{columns: []string{"b", "a"}, expect: true},
+		{columns: []string{"b"}, expect: false},
+		{columns: []string{"c"}, expect: false},
+		{columns: []string{"a", "b", "c"}, expect: false},
+	}
+	for _, tc := range tt {
+		var columns sqlparser.Columns
+		for _, column := range tc.columns {
+			columns = append(columns, sqlparser.NewIdentifierCI(column))
+		}
+		t.Run(sqlparser.String(columns), func(t *testing.T) {
+			assert.Equal(t, tc.expect, indexCoversColumns(createTable, columns))
+		})
+	}
+}
+
+func TestNonRestrictingForeignKeyConstraint(t *testing.T) {
+	tt := []struct {
+		constraint string
+		expect     bool
+	}{
+		{constraint: "", expect: false},
+		{constraint: "constraint child_fk foreign key (parent_id) references parent (id)", expect: false},
+		{constraint: "constraint child_fk foreign key (parent_id) references parent (id) on delete restrict", expect: false},
+		{constraint: "constraint child_fk foreign key (parent_id) references parent (id) on delete no action on update restrict", expect: false},
+		{constraint: "constraint child_fk foreign key (parent_id) references parent (id) on delete cascade", expect: true},
+		{constraint: "constraint child_fk foreign key (parent_id) references parent (id) on update cascade", expect: true},
+		{constraint: "constraint child_fk foreign key (parent_id) references parent (id) on delete set null", expect: true},
+		{constraint: "constraint child_fk foreign key (parent_id) references parent (id) on update set default", expect: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.constraint, func(t *testing.T) {
+			sql := "create table child (id int, parent_id int, primary key(id), key parent_idx (parent_id))"
+			if tc.constraint != "" {
+				sql = "create table child (id int, parent_id int, primary key(id), key parent_idx (parent_id), " + tc.constraint + ")"
+			}
+			constraint := nonRestrictingForeignKeyConstraint(parseCreateTable(t, sql))
+			if !tc.expect {
+				assert.Nil(t, constraint)
+				return
+			}
+			require.NotNil(t, constraint)
+			assert.Equal(t, "child_fk", constraint.Name.String())
+		})
+	}
+}
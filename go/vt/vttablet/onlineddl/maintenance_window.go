@@ -0,0 +1,165 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Maintenance windows restrict the times of day at which a migration may do its heavy lifting:
+
+- The copy window (--copy-window) restricts when a migration may run its copy phase. A queued migration is
+  only scheduled within the window. A running migration which is not yet ready to complete is throttled,
+  via the tablet throttler, for as long as the window is closed.
+- The cut-over window (--cutover-window) restricts when a migration may cut over. It only applies to the
+  vitess strategy, where the executor controls the cut-over. CREATE, DROP and VIEW operations, which take
+  effect the moment they run, are only scheduled within the cut-over window.
+
+A migration which does not specify windows of its own inherits the --online_ddl_copy_window and
+--online_ddl_cutover_window of the shard primary which receives it. These defaults are per-tablet: they are
+not stored in the topo nor in _vt, and so they must be set identically on all tablets of a keyspace for the
+keyspace's shards to agree, including on replicas which may be promoted. The effective windows are recorded
+on the migration row at submission, so that a later change of the flags, or a reparent, does not affect
+migrations already submitted. The migration's window_status indicates whether it is held back by a closed
+window, and until when.
+*/
+
+package onlineddl
+
+import (
+	"context"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	windowStatusOpen                = ""
+	windowStatusCopyWindowClosed    = "copy_window_closed"
+	windowStatusCutOverWindowClosed = "cutover_window_closed"
+)
+
+// submissionWindows returns the copy and cut-over windows for a newly submitted migration. These are the
+// migration's own windows, or, if it has none, this tablet's default windows.
+func submissionWindows(onlineDDL *schema.OnlineDDL) (copyWindow schema.TimeWindows, cutOverWindow schema.TimeWindows, err error) {
+	copyWindow, err = onlineDDL.StrategySetting().CopyWindow()
+	if err != nil {
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid --copy-window: %v", err)
+	}
+	cutOverWindow, err = onlineDDL.StrategySetting().CutOverWindow()
+	if err != nil {
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid --cutover-window: %v", err)
+	}
+	isVitessStrategy := false
+	switch onlineDDL.Strategy {
+	case schema.DDLStrategyOnline, schema.DDLStrategyVitess:
+		isVitessStrategy = true
+	}
+	if !cutOverWindow.IsEmpty() && !isVitessStrategy {
+		return nil, nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "--cutover-window is only supported by the vitess strategy, found: %v", onlineDDL.Strategy)
+	}
+	if copyWindow.IsEmpty() {
+		if copyWindow, err = schema.ParseTimeWindows(defaultCopyWindow); err != nil {
+			return nil, nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "invalid --online_ddl_copy_window: %v", err)
+		}
+	}
+	if cutOverWindow.IsEmpty() && isVitessStrategy {
+		if cutOverWindow, err = schema.ParseTimeWindows(defaultCutOverWindow); err != nil {
+			return nil, nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "invalid --online_ddl_cutover_window: %v", err)
+		}
+	}
+	return copyWindow, cutOverWindow, nil
+}
+
+// readMigrationWindows reads the copy and cut-over windows recorded for a migration
+func readMigrationWindows(row sqltypes.RowNamedValues) (copyWindow schema.TimeWindows, cutOverWindow schema.TimeWindows, err error) {
+	copyWindow, err = schema.ParseTimeWindows(row.AsString("copy_window", ""))
+	if err != nil {
+		return nil, nil, err
+	}
+	cutOverWindow, err = schema.ParseTimeWindows(row.AsString("cutover_window", ""))
+	if err != nil {
+		return nil, nil, err
+	}
+	return copyWindow, cutOverWindow, nil
+}
+
+// windowStatus returns the window status of a migration held back by the given windows at the given time,
+// along with the time the windows open. The status is windowStatusOpen when the windows are open.
+func windowStatus(windows schema.TimeWindows, closedStatus string, now time.Time) (status string, opensAt time.Time) {
+	if windows.Contains(now) {
+		return windowStatusOpen, time.Time{}
+	}
+	return closedStatus, windows.NextOpen(now)
+}
+
+// updateMigrationWindowStatus updates the window status of a migration, if changed
+func (e *Executor) updateMigrationWindowStatus(ctx context.Context, uuid string, currentStatus string, status string, opensAt time.Time) error {
+	if status == currentStatus {
+		return nil
+	}
+	var opensAtUnix int64
+	if !opensAt.IsZero() {
+		opensAtUnix = opensAt.Unix()
+	}
+	query, err := sqlparser.ParseAndBind(sqlUpdateMigrationWindowStatus,
+		sqltypes.StringBindVariable(status),
+		sqltypes.Int64BindVariable(opensAtUnix),
+		sqltypes.StringBindVariable(uuid),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = e.execQuery(ctx, query)
+	return err
+}
+
+// reviewQueuedMigrationWindow checks whether a queued migration may be scheduled at this time, and updates its
+// window status. CREATE, DROP and VIEW operations take effect the moment they run, and are therefore subject to
+// the cut-over window. Any other migration is subject to the copy window.
+func (e *Executor) reviewQueuedMigrationWindow(ctx context.Context, row sqltypes.RowNamedValues, isImmediateOperation bool) (isOpen bool, err error) {
+	copyWindow, cutOverWindow, err := readMigrationWindows(row)
+	if err != nil {
+		return false, err
+	}
+	windows, closedStatus := copyWindow, windowStatusCopyWindowClosed
+	if isImmediateOperation {
+		windows, closedStatus = cutOverWindow, windowStatusCutOverWindowClosed
+	}
+	status, opensAt := windowStatus(windows, closedStatus, time.Now())
+	if err := e.updateMigrationWindowStatus(ctx, row.AsString("migration_uuid", ""), row.AsString("window_status", ""), status, opensAt); err != nil {
+		return false, err
+	}
+	return status == windowStatusOpen, nil
+}
+
+// throttleUntilWindowOpens throttles a running migration until its copy window opens. An existing throttle
+// of the migration which already covers that time is left intact.
+func (e *Executor) throttleUntilWindowOpens(uuid string, opensAt time.Time) {
+	if err := e.lagThrottler.CheckIsReady(); err != nil {
+		// Without the throttler, a running migration cannot be paused, and keeps running outside its window.
+		return
+	}
+	for _, app := range e.lagThrottler.ThrottledApps() {
+		if app.AppName == uuid && app.Ratio >= 1 && !app.ExpireAt.Before(opensAt) {
+			return
+		}
+	}
+	log.Infof("Executor: throttling migration %s until its copy window opens at %v", uuid, opensAt)
+	_ = e.lagThrottler.ThrottleApp(uuid, opensAt, 1)
+}
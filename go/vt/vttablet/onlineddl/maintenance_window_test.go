@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/schema"
+)
+
+func TestSubmissionWindows(t *testing.T) {
+	defer func(copyWindow, cutOverWindow string) {
+		defaultCopyWindow, defaultCutOverWindow = copyWindow, cutOverWindow
+	}(defaultCopyWindow, defaultCutOverWindow)
+	defaultCopyWindow = "22:00-06:00"
+	defaultCutOverWindow = "02:00-04:00"
+
+	tt := []struct {
+		strategy      string
+		copyWindow    string
+		cutOverWindow string
+		isError       bool
+	}{
+		{
+			strategy:      "vitess",
+			copyWindow:    "22:00-06:00",
+			cutOverWindow: "02:00-04:00",
+		},
+		{
+			strategy:      "vitess --copy-window=00:00-05:00",
+			copyWindow:    "00:00-05:00",
+			cutOverWindow: "02:00-04:00",
+		},
+		{
+			strategy:      "online --cutover-window=01:00-01:30",
+			copyWindow:    "22:00-06:00",
+			cutOverWindow: "01:00-01:30",
+		},
+		{
+			strategy:   "gh-ost",
+			copyWindow: "22:00-06:00",
+		},
+		{
+			strategy: "gh-ost --cutover-window=01:00-01:30",
+			isError:  true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.strategy, func(t *testing.T) {
+			setting, err := schema.ParseDDLStrategy(tc.strategy)
+			require.NoError(t, err)
+			onlineDDL, err := schema.NewOnlineDDL("ks", "t", "alter table t engine=innodb", setting, "", "")
+			require.NoError(t, err)
+
+			copyWindow, cutOverWindow, err := submissionWindows(onlineDDL)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.copyWindow, copyWindow.String())
+			assert.Equal(t, tc.cutOverWindow, cutOverWindow.String())
+		})
+	}
+}
+
+func TestWindowStatus(t *testing.T) {
+	windows, err := schema.ParseTimeWindows("22:00-06:00")
+	require.NoError(t, err)
+
+	status, opensAt := windowStatus(windows, windowStatusCopyWindowClosed, time.Date(2023, 2, 1, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, windowStatusOpen, status)
+	assert.True(t, opensAt.IsZero())
+
+	status, opensAt = windowStatus(windows, windowStatusCopyWindowClosed, time.Date(2023, 2, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, windowStatusCopyWindowClosed, status)
+	assert.Equal(t, time.Date(2023, 2, 1, 22, 0, 0, 0, time.UTC), opensAt)
+
+	status, _ = windowStatus(nil, windowStatusCutOverWindowClosed, time.Date(2023, 2, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, windowStatusOpen, status)
+}
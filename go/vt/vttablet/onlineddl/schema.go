@@ -85,6 +85,16 @@ const (
 	alterSchemaMigrationsCutoverAttempts               = "ALTER TABLE _vt.schema_migrations add column cutover_attempts int unsigned NOT NULL DEFAULT 0"
 	alterSchemaMigrationsTableImmediateOperation       = "ALTER TABLE _vt.schema_migrations add column is_immediate_operation tinyint unsigned NOT NULL DEFAULT 0"
 	alterSchemaMigrationsReviewedTimestamp             = "ALTER TABLE _vt.schema_migrations add column reviewed_timestamp timestamp NULL DEFAULT NULL"
+	alterSchemaMigrationsTableSynchronizedCutOver      = "ALTER TABLE _vt.schema_migrations add column synchronized_cutover tinyint unsigned NOT NULL DEFAULT 0"
+	alterSchemaMigrationsCutOverWindowTimestamp        = "ALTER TABLE _vt.schema_migrations add column cutover_window_timestamp timestamp NULL DEFAULT NULL"
+	alterSchemaMigrationsCutOverConcludedTimestamp     = "ALTER TABLE _vt.schema_migrations add column cutover_concluded_timestamp timestamp NULL DEFAULT NULL"
+	alterSchemaMigrationsCopyWindow                    = "ALTER TABLE _vt.schema_migrations add column copy_window tinytext NOT NULL"
+	alterSchemaMigrationsCutOverWindow                 = "ALTER TABLE _vt.schema_migrations add column cutover_window tinytext NOT NULL"
+	alterSchemaMigrationsWindowStatus                  = "ALTER TABLE _vt.schema_migrations add column window_status varchar(32) NOT NULL DEFAULT ''"
+	alterSchemaMigrationsWindowOpensTimestamp          = "ALTER TABLE _vt.schema_migrations add column window_opens_timestamp timestamp NULL DEFAULT NULL"
+	alterSchemaMigrationsVReplForeignKeys              = "ALTER TABLE _vt.schema_migrations add column vrepl_foreign_keys text NOT NULL"
+	alterSchemaMigrationsArtifactForeignKeys           = "ALTER TABLE _vt.schema_migrations add column artifact_foreign_keys text NOT NULL"
+	alterSchemaMigrationsEstimate                      = "ALTER TABLE _vt.schema_migrations add column estimate text NOT NULL"
 
 	sqlInsertMigration = `INSERT IGNORE INTO _vt.schema_migrations (
 		migration_uuid,
@@ -105,9 +115,12 @@ const (
 		postpone_completion,
 		allow_concurrent,
 		reverted_uuid,
-		is_view
+		is_view,
+		synchronized_cutover,
+		copy_window,
+		cutover_window
 	) VALUES (
-		%a, %a, %a, %a, %a, %a, %a, %a, %a, NOW(), %a, %a, %a, %a, %a, %a, %a, %a, %a
+		%a, %a, %a, %a, %a, %a, %a, %a, %a, NOW(), %a, %a, %a, %a, %a, %a, %a, %a, %a, %a, %a, %a
 	)`
 
 	sqlSelectQueuedMigrations = `SELECT
@@ -117,7 +130,10 @@ const (
 			is_immediate_operation,
 			postpone_launch,
 			postpone_completion,
-			ready_to_complete
+			ready_to_complete,
+			copy_window,
+			cutover_window,
+			window_status
 		FROM _vt.schema_migrations
 		WHERE
 			migration_status='queued'
@@ -168,6 +184,11 @@ const (
 		WHERE
 			migration_uuid=%a
 	`
+	sqlUpdateMigrationWindowStatus = `UPDATE _vt.schema_migrations
+			SET window_status=%a, window_opens_timestamp=FROM_UNIXTIME(NULLIF(%a, 0))
+		WHERE
+			migration_uuid=%a
+	`
 	sqlUpdateMigrationStowawayTable = `UPDATE _vt.schema_migrations
 			SET stowaway_table=%a
 		WHERE
@@ -199,6 +220,21 @@ const (
 		WHERE
 			migration_uuid=%a
 	`
+	sqlUpdateVReplForeignKeys = `UPDATE _vt.schema_migrations
+			SET vrepl_foreign_keys=%a
+		WHERE
+			migration_uuid=%a
+	`
+	sqlUpdateArtifactForeignKeys = `UPDATE _vt.schema_migrations
+			SET artifact_foreign_keys=%a
+		WHERE
+			migration_uuid=%a
+	`
+	sqlUpdateMigrationEstimate = `UPDATE _vt.schema_migrations
+			SET estimate=%a
+		WHERE
+			migration_uuid=%a
+	`
 	sqlUpdateArtifacts = `UPDATE _vt.schema_migrations
 			SET artifacts=concat(%a, ',', artifacts), cleanup_timestamp=NULL
 		WHERE
@@ -241,6 +277,17 @@ const (
 			migration_uuid=%a
 			AND postpone_completion != 0
 	`
+	sqlUpdateCutOverWindow = `UPDATE _vt.schema_migrations
+			SET cutover_window_timestamp=NOW() + INTERVAL %a SECOND
+		WHERE
+			migration_uuid=%a
+			AND cutover_window_timestamp IS NULL
+	`
+	sqlUpdateCutOverConcluded = `UPDATE _vt.schema_migrations
+			SET cutover_concluded_timestamp=NOW()
+		WHERE
+			migration_uuid=%a
+	`
 	sqlUpdateTablet = `UPDATE _vt.schema_migrations
 			SET tablet=%a
 		WHERE
@@ -308,6 +355,8 @@ const (
 			message='',
 			stage='',
 			cutover_attempts=0,
+			cutover_window_timestamp=NULL,
+			cutover_concluded_timestamp=NULL,
 			ready_timestamp=NULL,
 			started_timestamp=NULL,
 			liveness_timestamp=NULL,
@@ -328,6 +377,8 @@ const (
 			message='',
 			stage='',
 			cutover_attempts=0,
+			cutover_window_timestamp=NULL,
+			cutover_concluded_timestamp=NULL,
 			ready_timestamp=NULL,
 			started_timestamp=NULL,
 			liveness_timestamp=NULL,
@@ -347,11 +398,43 @@ const (
 			migration_uuid,
 			postpone_completion,
 			stowaway_table,
+			synchronized_cutover,
+			IFNULL(cutover_window_timestamp > NOW(), 0) as cutover_window_open,
+			ready_to_complete,
+			copy_window,
+			cutover_window,
+			window_status,
 			timestampdiff(second, started_timestamp, now()) as elapsed_seconds
 		FROM _vt.schema_migrations
 		WHERE
 			migration_status='running'
 	`
+	sqlSelectSynchronizedCutOverMigrations = `SELECT
+			migration_uuid
+		FROM _vt.schema_migrations
+		WHERE
+			synchronized_cutover=1
+			AND cutover_concluded_timestamp IS NULL
+	`
+	sqlSelectSynchronizedCutOverState = `SELECT
+			migration_status,
+			ready_to_complete,
+			postpone_completion,
+			cutover_window_timestamp IS NOT NULL as cutover_window_opened,
+			IFNULL(cutover_window_timestamp > NOW(), 0) as cutover_window_open
+		FROM _vt.schema_migrations
+		WHERE
+			migration_uuid=%a
+	`
+	sqlSelectRevertMigration = `SELECT
+			migration_uuid
+		FROM _vt.schema_migrations
+		WHERE
+			reverted_uuid=%a
+		ORDER BY
+			id DESC
+		LIMIT 1
+	`
 	sqlSelectCompleteMigrationsOnTable = `SELECT
 			migration_uuid,
 			strategy
@@ -456,7 +539,9 @@ const (
 			cancelled_timestamp,
 			component_throttled,
 			postpone_launch,
-			postpone_completion
+			postpone_completion,
+			vrepl_foreign_keys,
+			artifact_foreign_keys
 		FROM _vt.schema_migrations
 		WHERE
 			migration_uuid=%a
@@ -488,21 +573,21 @@ const (
 				table_schema=%a
 				and table_name=%a
 		`
-	selSelectCountFKParentConstraints = `
+	selSelectCountFKSelfReferencingConstraints = `
 		SELECT
 			COUNT(*) as num_fk_constraints
 		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
 		WHERE
-			REFERENCED_TABLE_SCHEMA=%a AND REFERENCED_TABLE_NAME=%a
-			AND REFERENCED_TABLE_NAME IS NOT NULL
+			TABLE_SCHEMA=%a AND TABLE_NAME=%a
+			AND REFERENCED_TABLE_SCHEMA=TABLE_SCHEMA AND REFERENCED_TABLE_NAME=TABLE_NAME
 		`
-	selSelectCountFKChildConstraints = `
+	sqlSelectFKChildTables = `
 		SELECT
-			COUNT(*) as num_fk_constraints
+			DISTINCT TABLE_NAME as table_name
 		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
 		WHERE
-			TABLE_SCHEMA=%a AND TABLE_NAME=%a
-			AND REFERENCED_TABLE_NAME IS NOT NULL
+			REFERENCED_TABLE_SCHEMA=%a AND REFERENCED_TABLE_NAME=%a
+			AND TABLE_SCHEMA=REFERENCED_TABLE_SCHEMA AND TABLE_NAME!=REFERENCED_TABLE_NAME
 		`
 	sqlSelectUniqueKeys = `
 	SELECT
@@ -562,6 +647,51 @@ const (
 		END,
 		COUNT_COLUMN_IN_INDEX
 	`
+	sqlSelectTableSize = `
+		SELECT
+			IFNULL(TABLE_ROWS, 0) AS table_rows,
+			IFNULL(DATA_LENGTH, 0) + IFNULL(INDEX_LENGTH, 0) AS table_size
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE
+			TABLES.TABLE_SCHEMA=%a
+			AND TABLES.TABLE_NAME=%a
+		`
+	sqlSelectDataDir         = "SELECT @@global.datadir AS datadir"
+	sqlSelectRecentCopyRates = `SELECT
+			rows_copied,
+			TIMESTAMPDIFF(SECOND, started_timestamp, completed_timestamp) AS copy_seconds
+		FROM _vt.schema_migrations
+		WHERE
+			migration_status='complete'
+			AND strategy IN ('online', 'vitess')
+			AND ddl_action='alter'
+			AND rows_copied > 0
+			AND started_timestamp IS NOT NULL
+		ORDER BY completed_timestamp DESC
+		LIMIT %a
+	`
+	sqlSelectTriggerExists = `SELECT
+			TRIGGER_NAME
+		FROM INFORMATION_SCHEMA.TRIGGERS
+		WHERE
+			TRIGGER_SCHEMA=%a
+			AND TRIGGER_NAME=%a
+		`
+	sqlSelectRoutineExists = `SELECT
+			ROUTINE_NAME
+		FROM INFORMATION_SCHEMA.ROUTINES
+		WHERE
+			ROUTINE_SCHEMA=%a
+			AND ROUTINE_TYPE=%a
+			AND ROUTINE_NAME=%a
+		`
+	sqlSelectEventExists = `SELECT
+			EVENT_NAME
+		FROM INFORMATION_SCHEMA.EVENTS
+		WHERE
+			EVENT_SCHEMA=%a
+			AND EVENT_NAME=%a
+		`
 	sqlDropTrigger      = "DROP TRIGGER IF EXISTS `%a`.`%a`"
 	sqlShowTablesLike   = "SHOW TABLES LIKE '%a'"
 	sqlDropTable        = "DROP TABLE `%a`"
@@ -609,10 +739,14 @@ const (
 		`
 	sqlSwapTables         = "RENAME TABLE `%a` TO `%a`, `%a` TO `%a`, `%a` TO `%a`"
 	sqlRenameTable        = "RENAME TABLE `%a` TO `%a`"
+	sqlLockTableWrite     = "LOCK TABLES `%a` WRITE"
 	sqlLockTwoTablesWrite = "LOCK TABLES `%a` WRITE, `%a` WRITE"
 	sqlUnlockTables       = "UNLOCK TABLES"
 	sqlCreateSentryTable  = "CREATE TABLE IF NOT EXISTS `%a` (id INT PRIMARY KEY)"
 	sqlFindProcess        = "SELECT id, Info as info FROM information_schema.processlist WHERE id=%a AND Info LIKE %a"
+
+	sqlDisableForeignKeyChecks = "SET @@session.foreign_key_checks=0"
+	sqlShowCreateStoredProgram = "SHOW CREATE %s `%a`"
 )
 
 const (
@@ -681,4 +815,14 @@ var ApplyDDL = []string{
 	alterSchemaMigrationsCutoverAttempts,
 	alterSchemaMigrationsTableImmediateOperation,
 	alterSchemaMigrationsReviewedTimestamp,
+	alterSchemaMigrationsTableSynchronizedCutOver,
+	alterSchemaMigrationsCutOverWindowTimestamp,
+	alterSchemaMigrationsCutOverConcludedTimestamp,
+	alterSchemaMigrationsCopyWindow,
+	alterSchemaMigrationsCutOverWindow,
+	alterSchemaMigrationsWindowStatus,
+	alterSchemaMigrationsWindowOpensTimestamp,
+	alterSchemaMigrationsVReplForeignKeys,
+	alterSchemaMigrationsArtifactForeignKeys,
+	alterSchemaMigrationsEstimate,
 }
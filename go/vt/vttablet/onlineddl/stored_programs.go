@@ -0,0 +1,314 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Online DDL supports CREATE and DROP statements for stored programs: triggers, procedures, functions and events.
+Stored programs have no data to copy, and so these migrations run directly, whatever the strategy.
+
+In declarative mode:
+
+- A CREATE is a noop if the stored program exists and is identical to the statement. If it exists and is
+  different, it is dropped and then created anew, as MySQL cannot change a stored program's body in place.
+  The two statements are not atomic: if the CREATE fails, the stored program is restored from its previous
+  definition. A trigger's table is locked for write while its trigger is replaced, so that no write runs
+  against the table without the trigger.
+  The stored program is compared, via schemadiff, with what SHOW CREATE reports. When the statement has no
+  DEFINER clause, the existing stored program's definer is ignored, since MySQL assigns one upon creation.
+- A DROP is a noop if the stored program does not exist.
+
+Stored program migrations cannot be reverted.
+*/
+
+package onlineddl
+
+import (
+	"context"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/dbconnpool"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/schemadiff"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// storedProgramType returns the type of stored program created or dropped by the given statement
+func storedProgramType(ddlStmt sqlparser.DDLStatement) (sqlparser.StoredProgramType, error) {
+	switch ddlStmt := ddlStmt.(type) {
+	case *sqlparser.CreateTrigger:
+		return sqlparser.TriggerProgramType, nil
+	case *sqlparser.CreateRoutine:
+		return ddlStmt.Type, nil
+	case *sqlparser.CreateEvent:
+		return sqlparser.EventProgramType, nil
+	case *sqlparser.DropStoredProgram:
+		return ddlStmt.Type, nil
+	}
+	return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "not a stored program statement: %v", sqlparser.String(ddlStmt))
+}
+
+// showCreateStoredProgram returns the CREATE statement of an existing trigger, procedure, function or event,
+// or an empty string if it does not exist
+func (e *Executor) showCreateStoredProgram(ctx context.Context, programType sqlparser.StoredProgramType, name string) (string, error) {
+	var query string
+	var err error
+	switch programType {
+	case sqlparser.TriggerProgramType:
+		query, err = sqlparser.ParseAndBind(sqlSelectTriggerExists,
+			sqltypes.StringBindVariable(e.dbName),
+			sqltypes.StringBindVariable(name),
+		)
+	case sqlparser.ProcedureProgramType, sqlparser.FunctionProgramType:
+		query, err = sqlparser.ParseAndBind(sqlSelectRoutineExists,
+			sqltypes.StringBindVariable(e.dbName),
+			sqltypes.StringBindVariable(strings.ToUpper(programType.ToString())),
+			sqltypes.StringBindVariable(name),
+		)
+	case sqlparser.EventProgramType:
+		query, err = sqlparser.ParseAndBind(sqlSelectEventExists,
+			sqltypes.StringBindVariable(e.dbName),
+			sqltypes.StringBindVariable(name),
+		)
+	}
+	if err != nil {
+		return "", err
+	}
+	rs, err := e.execQuery(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	if len(rs.Rows) == 0 {
+		return "", nil
+	}
+
+	parsed := sqlparser.BuildParsedQuery(sqlShowCreateStoredProgram, strings.ToUpper(programType.ToString()), name)
+	rs, err = e.execQuery(ctx, parsed.Query)
+	if err != nil {
+		return "", err
+	}
+	row := rs.Named().Row()
+	if row == nil {
+		return "", nil
+	}
+	switch programType {
+	case sqlparser.TriggerProgramType:
+		return row.AsString("SQL Original Statement", ""), nil
+	case sqlparser.ProcedureProgramType:
+		return row.AsString("Create Procedure", ""), nil
+	case sqlparser.FunctionProgramType:
+		return row.AsString("Create Function", ""), nil
+	default:
+		return row.AsString("Create Event", ""), nil
+	}
+}
+
+// declarativeStoredProgramDiff compares an existing stored program, given by its SHOW CREATE statement, with
+// the stored program defined by a declarative CREATE statement. An empty diff means the two are identical.
+func declarativeStoredProgramDiff(existingCreate string, ddlStmt sqlparser.DDLStatement) (schemadiff.EntityDiff, error) {
+	stmt, err := sqlparser.ParseStrictDDL(existingCreate)
+	if err != nil {
+		return nil, err
+	}
+	existingStmt, ok := stmt.(sqlparser.DDLStatement)
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected statement: %v", existingCreate)
+	}
+	// Comments carry the migration's directives, and are not part of the stored program
+	existingStmt.SetComments(nil)
+	ddlStmt = sqlparser.CloneDDLStatement(ddlStmt)
+	ddlStmt.SetComments(nil)
+
+	mismatchError := vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected: %v does not match %v", existingCreate, sqlparser.String(ddlStmt))
+	hints := &schemadiff.DiffHints{}
+	switch desired := ddlStmt.(type) {
+	case *sqlparser.CreateTrigger:
+		existing, ok := existingStmt.(*sqlparser.CreateTrigger)
+		if !ok {
+			return nil, mismatchError
+		}
+		if desired.Definer == nil {
+			existing.Definer = nil
+		}
+		from, err := schemadiff.NewCreateTriggerEntity(existing)
+		if err != nil {
+			return nil, err
+		}
+		to, err := schemadiff.NewCreateTriggerEntity(desired)
+		if err != nil {
+			return nil, err
+		}
+		return from.Diff(to, hints)
+	case *sqlparser.CreateRoutine:
+		existing, ok := existingStmt.(*sqlparser.CreateRoutine)
+		if !ok {
+			return nil, mismatchError
+		}
+		if desired.Definer == nil {
+			existing.Definer = nil
+		}
+		from, err := schemadiff.NewCreateRoutineEntity(existing)
+		if err != nil {
+			return nil, err
+		}
+		to, err := schemadiff.NewCreateRoutineEntity(desired)
+		if err != nil {
+			return nil, err
+		}
+		return from.Diff(to, hints)
+	case *sqlparser.CreateEvent:
+		existing, ok := existingStmt.(*sqlparser.CreateEvent)
+		if !ok {
+			return nil, mismatchError
+		}
+		if desired.Definer == nil {
+			existing.Definer = nil
+		}
+		from, err := schemadiff.NewCreateEventEntity(existing)
+		if err != nil {
+			return nil, err
+		}
+		to, err := schemadiff.NewCreateEventEntity(desired)
+		if err != nil {
+			return nil, err
+		}
+		return from.Diff(to, hints)
+	}
+	return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "expected CREATE TRIGGER, PROCEDURE, FUNCTION or EVENT in online DDL statement: %v", sqlparser.String(ddlStmt))
+}
+
+// replaceStoredProgram drops a stored program and creates it anew, running the queries with exec, on a single
+// connection. Should the CREATE fail, the stored program is recreated with restoreQuery, its previous definition.
+// lockQuery, when not empty, runs first, and the tables it locks are released once done.
+func replaceStoredProgram(exec func(query string) error, lockQuery, dropQuery, createQuery, restoreQuery string) error {
+	if lockQuery != "" {
+		if err := exec(lockQuery); err != nil {
+			return err
+		}
+		defer exec(sqlUnlockTables)
+	}
+	if err := exec(dropQuery); err != nil {
+		return err
+	}
+	if err := exec(createQuery); err != nil {
+		if restoreErr := exec(restoreQuery); restoreErr != nil {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "%v; and the previous definition could not be restored: %v", err, restoreErr)
+		}
+		return vterrors.Wrapf(err, "the previous definition was restored")
+	}
+	return nil
+}
+
+// replaceStoredProgramDirectly replaces an existing stored program, given by its SHOW CREATE statement, with the
+// one created by the migration. dropQuery drops the existing stored program.
+func (e *Executor) replaceStoredProgramDirectly(ctx context.Context, onlineDDL *schema.OnlineDDL, ddlStmt sqlparser.DDLStatement, dropQuery string, existingCreate string) error {
+	conn, err := dbconnpool.NewDBConnection(ctx, e.env.Config().DB.DbaWithDB())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	restoreSQLModeFunc, err := e.initMigrationSQLMode(ctx, onlineDDL, conn)
+	defer restoreSQLModeFunc()
+	if err != nil {
+		return err
+	}
+
+	var lockQuery string
+	if createTrigger, ok := ddlStmt.(*sqlparser.CreateTrigger); ok {
+		lockQuery = sqlparser.BuildParsedQuery(sqlLockTableWrite, createTrigger.Table.Name.String()).Query
+	}
+	exec := func(query string) error {
+		_, err := conn.ExecuteFetch(query, 0, false)
+		return err
+	}
+	_ = e.onSchemaMigrationStatus(ctx, onlineDDL.UUID, schema.OnlineDDLStatusRunning, false, progressPctStarted, etaSecondsUnknown, rowsCopiedUnknown, emptyHint)
+	if err := replaceStoredProgram(exec, lockQuery, dropQuery, onlineDDL.SQL, existingCreate); err != nil {
+		return err
+	}
+	defer e.reloadSchema(ctx)
+	_ = e.onSchemaMigrationStatus(ctx, onlineDDL.UUID, schema.OnlineDDLStatusComplete, false, progressPctFull, etaSecondsNow, rowsCopiedUnknown, emptyHint)
+	return nil
+}
+
+// executeStoredProgramMigration runs a CREATE or DROP migration for a trigger, a procedure, a function or an event
+func (e *Executor) executeStoredProgramMigration(ctx context.Context, onlineDDL *schema.OnlineDDL) error {
+	failMigration := func(err error) error {
+		return e.failMigration(ctx, onlineDDL, err)
+	}
+	e.migrationMutex.Lock()
+	defer e.migrationMutex.Unlock()
+
+	ddlStmt, ddlAction, err := schema.ParseOnlineDDLStatement(onlineDDL.SQL)
+	if err != nil {
+		return failMigration(err)
+	}
+	if onlineDDL.StrategySetting().IsDeclarative() {
+		// Sanity: reject IF EXISTS and IF NOT EXISTS statements, because they don't make sense (or are ambiguous) in declarative mode
+		if ddlStmt.GetIfExists() {
+			return failMigration(vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "strategy is declarative. IF EXISTS does not work in declarative mode for migration %v", onlineDDL.UUID))
+		}
+		if ddlStmt.GetIfNotExists() {
+			return failMigration(vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "strategy is declarative. IF NOT EXISTS does not work in declarative mode for migration %v", onlineDDL.UUID))
+		}
+		programType, err := storedProgramType(ddlStmt)
+		if err != nil {
+			return failMigration(err)
+		}
+		existingCreate, err := e.showCreateStoredProgram(ctx, programType, onlineDDL.Table)
+		if err != nil {
+			return failMigration(err)
+		}
+		noChange := func() error {
+			_ = e.onSchemaMigrationStatus(ctx, onlineDDL.UUID, schema.OnlineDDLStatusComplete, false, progressPctFull, etaSecondsNow, rowsCopiedUnknown, emptyHint)
+			_ = e.updateMigrationMessage(ctx, onlineDDL.UUID, "no change")
+			return nil
+		}
+		switch ddlAction {
+		case sqlparser.DropDDLAction:
+			if existingCreate == "" {
+				// stored program does not exist. We mark this DROP as implicitly sucessful
+				return noChange()
+			}
+		case sqlparser.CreateDDLAction:
+			if existingCreate != "" {
+				diff, err := declarativeStoredProgramDiff(existingCreate, ddlStmt)
+				if err != nil {
+					return failMigration(err)
+				}
+				if diff == nil || diff.IsEmpty() {
+					// No diff! We mark this CREATE as implicitly sucessful
+					return noChange()
+				}
+				// The stored program is dropped, and is then created by this migration's statement
+				if err := e.updateDDLAction(ctx, onlineDDL.UUID, sqlparser.AlterStr); err != nil {
+					return failMigration(err)
+				}
+				_ = e.updateMigrationMessage(ctx, onlineDDL.UUID, diff.CanonicalStatementString())
+				if err := e.replaceStoredProgramDirectly(ctx, onlineDDL, ddlStmt, diff.CanonicalStatementString(), existingCreate); err != nil {
+					return failMigration(err)
+				}
+				return nil
+			}
+		}
+	}
+	if _, err := e.executeDirectly(ctx, onlineDDL); err != nil {
+		return failMigration(err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/schemadiff"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestDeclarativeStoredProgramDiff(t *testing.T) {
+	tt := []struct {
+		name     string
+		existing string
+		desired  string
+		diffs    []string
+		isError  bool
+	}{
+		{
+			name:     "identical trigger, implicit definer",
+			existing: "CREATE DEFINER=`root`@`localhost` TRIGGER `t_bi` BEFORE INSERT ON `t` FOR EACH ROW set new.id = 1",
+			desired:  "create /*vt+ uuid=\"1234\" */ trigger t_bi before insert on t for each row set new.id = 1",
+		},
+		{
+			name:     "identical function, as reported by SHOW CREATE FUNCTION",
+			existing: "CREATE DEFINER=`root`@`localhost` FUNCTION `f`(x int) RETURNS int\n    DETERMINISTIC\nreturn x * 2",
+			desired:  "create function f(x int) returns int deterministic return x * 2",
+		},
+		{
+			name:     "changed trigger body",
+			existing: "CREATE DEFINER=`root`@`localhost` TRIGGER `t_bi` BEFORE INSERT ON `t` FOR EACH ROW set new.id = 1",
+			desired:  "create trigger t_bi before insert on t for each row set new.id = 2",
+			diffs: []string{
+				"DROP TRIGGER `t_bi`",
+				"CREATE TRIGGER `t_bi` BEFORE INSERT ON `t` FOR EACH ROW set new.id = 2",
+			},
+		},
+		{
+			name:     "changed definer",
+			existing: "CREATE DEFINER=`root`@`localhost` PROCEDURE `p`()\nbegin select 1; end",
+			desired:  "create definer = `app`@`%` procedure p() begin select 1; end",
+			diffs: []string{
+				"DROP PROCEDURE `p`",
+				"CREATE DEFINER = app@`%` PROCEDURE `p`() begin select 1; end",
+			},
+		},
+		{
+			name:     "changed event schedule",
+			existing: "CREATE DEFINER=`root`@`localhost` EVENT `e` ON SCHEDULE EVERY 1 DAY STARTS '2023-01-01 00:00:00' ON COMPLETION NOT PRESERVE ENABLE DO delete from t",
+			desired:  "create event e on schedule every 1 hour do delete from t",
+			diffs: []string{
+				"DROP EVENT `e`",
+				"CREATE EVENT `e` on schedule every 1 hour do delete from t",
+			},
+		},
+		{
+			name:     "mismatched stored program type",
+			existing: "CREATE DEFINER=`root`@`localhost` PROCEDURE `p`()\nbegin select 1; end",
+			desired:  "create event p on schedule every 1 hour do delete from t",
+			isError:  true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(tc.desired)
+			require.NoError(t, err)
+			ddlStmt, ok := stmt.(sqlparser.DDLStatement)
+			require.True(t, ok)
+
+			diff, err := declarativeStoredProgramDiff(tc.existing, ddlStmt)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var diffs []string
+			for _, d := range schemadiff.AllSubsequent(diff) {
+				diffs = append(diffs, d.CanonicalStatementString())
+			}
+			assert.Equal(t, tc.diffs, diffs)
+		})
+	}
+}
+
+func TestReplaceStoredProgram(t *testing.T) {
+	const (
+		lockQuery    = "LOCK TABLES `t` WRITE"
+		dropQuery    = "DROP TRIGGER `t_bi`"
+		createQuery  = "create trigger t_bi before insert on t for each row set new.id = 2"
+		restoreQuery = "CREATE DEFINER=`root`@`localhost` TRIGGER `t_bi` BEFORE INSERT ON `t` FOR EACH ROW set new.id = 1"
+	)
+	tt := []struct {
+		name      string
+		lockQuery string
+		failing   map[string]bool
+		queries   []string
+		isError   bool
+	}{
+		{
+			name:      "replaced",
+			lockQuery: lockQuery,
+			queries:   []string{lockQuery, dropQuery, createQuery, sqlUnlockTables},
+		},
+		{
+			name:    "replaced without lock",
+			queries: []string{dropQuery, createQuery},
+		},
+		{
+			name:      "create fails, previous definition restored",
+			lockQuery: lockQuery,
+			failing:   map[string]bool{createQuery: true},
+			queries:   []string{lockQuery, dropQuery, createQuery, restoreQuery, sqlUnlockTables},
+			isError:   true,
+		},
+		{
+			name:      "create and restore fail",
+			lockQuery: lockQuery,
+			failing:   map[string]bool{createQuery: true, restoreQuery: true},
+			queries:   []string{lockQuery, dropQuery, createQuery, restoreQuery, sqlUnlockTables},
+			isError:   true,
+		},
+		{
+			name:      "drop fails",
+			lockQuery: lockQuery,
+			failing:   map[string]bool{dropQuery: true},
+			queries:   []string{lockQuery, dropQuery, sqlUnlockTables},
+			isError:   true,
+		},
+		{
+			name:      "lock fails",
+			lockQuery: lockQuery,
+			failing:   map[string]bool{lockQuery: true},
+			queries:   []string{lockQuery},
+			isError:   true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var queries []string
+			exec := func(query string) error {
+				queries = append(queries, query)
+				if tc.failing[query] {
+					return fmt.Errorf("failed: %s", query)
+				}
+				return nil
+			}
+			err := replaceStoredProgram(exec, tc.lockQuery, dropQuery, createQuery, restoreQuery)
+			if tc.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.queries, queries)
+		})
+	}
+}
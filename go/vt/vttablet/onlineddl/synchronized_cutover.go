@@ -0,0 +1,351 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+/*
+	A --synchronized-cutover migration does not cut over on a shard as soon as the shard is ready. Instead, the primary
+	of the first serving shard of the keyspace coordinates the cut-over of all the shards:
+
+	- Once the migration is running and ready to complete on all the shards, and its completion is not postponed on
+	  any of them, the coordinator opens a cut-over window on all the shards, by writing its deadline in their
+	  cutover_window_timestamp column.
+	- Each shard cuts over while its window is open, retrying on failure, and does not cut over once it is closed.
+	- If the migration fails or is cancelled on any shard, or if any shard did not cut over by the end of the window,
+	  the coordinator rolls back the migration: it cancels the migration on the shards which did not cut over, and
+	  reverts it on the shards which did.
+	- Once the migration is complete on all the shards, or rolled back, the coordinator marks it as concluded on all
+	  the shards.
+
+	The state of the migration on the other shards is read and written through their _vt.schema_migrations table, and
+	the migrations are cancelled and reverted with ALTER VITESS_MIGRATION and REVERT VITESS_MIGRATION statements.
+*/
+
+// synchronizedCutOverShard is the state of a --synchronized-cutover migration on a shard, as seen by the coordinator
+type synchronizedCutOverShard struct {
+	name   string
+	tablet *topodatapb.Tablet
+
+	found              bool
+	status             schema.OnlineDDLStatus
+	readyToComplete    bool
+	postponeCompletion bool
+	windowOpened       bool
+	windowOpen         bool
+}
+
+type synchronizedCutOverAction int
+
+const (
+	synchronizedCutOverWait synchronizedCutOverAction = iota
+	synchronizedCutOverOpenWindow
+	synchronizedCutOverRollback
+	synchronizedCutOverConclude
+)
+
+// decideSynchronizedCutOver returns what the coordinator should do next with a migration, given its state on all the
+// shards, and the reason of a rollback
+func decideSynchronizedCutOver(shards []*synchronizedCutOverShard) (action synchronizedCutOverAction, reason string) {
+	allComplete := true
+	allReady := true
+	anyWindowOpened := false
+	anyWindowOpen := false
+	for _, shard := range shards {
+		if !shard.found {
+			// The migration was not submitted on this shard yet
+			return synchronizedCutOverWait, ""
+		}
+		switch shard.status {
+		case schema.OnlineDDLStatusFailed, schema.OnlineDDLStatusCancelled:
+			return synchronizedCutOverRollback, fmt.Sprintf("migration is %s on shard %s", shard.status, shard.name)
+		}
+		if shard.status != schema.OnlineDDLStatusComplete {
+			allComplete = false
+		}
+		if shard.status != schema.OnlineDDLStatusRunning || !shard.readyToComplete || shard.postponeCompletion {
+			allReady = false
+		}
+		anyWindowOpened = anyWindowOpened || shard.windowOpened
+		anyWindowOpen = anyWindowOpen || shard.windowOpen
+	}
+	switch {
+	case allComplete:
+		return synchronizedCutOverConclude, ""
+	case anyWindowOpen:
+		// Shards are cutting over
+		return synchronizedCutOverWait, ""
+	case anyWindowOpened:
+		for _, shard := range shards {
+			if shard.status != schema.OnlineDDLStatusComplete {
+				return synchronizedCutOverRollback, fmt.Sprintf("shard %s did not cut over within the cut-over window", shard.name)
+			}
+		}
+	case allReady:
+		return synchronizedCutOverOpenWindow, ""
+	}
+	return synchronizedCutOverWait, ""
+}
+
+// synchronizedCutOverShards returns the serving shards of the keyspace and their primary tablets, sorted by name.
+// The first shard coordinates the synchronized cut-overs.
+func (e *Executor) synchronizedCutOverShards(ctx context.Context) ([]*synchronizedCutOverShard, error) {
+	shardInfos, err := e.ts.FindAllShardsInKeyspace(ctx, e.keyspace)
+	if err != nil {
+		return nil, err
+	}
+	var shards []*synchronizedCutOverShard
+	for name, si := range shardInfos {
+		if !si.IsPrimaryServing {
+			continue
+		}
+		if !si.HasPrimary() {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no primary tablet for shard %v/%v", e.keyspace, name)
+		}
+		tablet, err := e.ts.GetTablet(ctx, si.PrimaryAlias)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, &synchronizedCutOverShard{name: name, tablet: tablet.Tablet})
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].name < shards[j].name
+	})
+	return shards, nil
+}
+
+// reviewSynchronizedCutOvers coordinates the cut-over of the --synchronized-cutover migrations which are not
+// concluded yet, if this tablet is the primary of the coordinating shard
+func (e *Executor) reviewSynchronizedCutOvers(ctx context.Context) error {
+	r, err := e.execQuery(ctx, sqlSelectSynchronizedCutOverMigrations)
+	if err != nil {
+		return err
+	}
+	if len(r.Rows) == 0 {
+		return nil
+	}
+	shards, err := e.synchronizedCutOverShards(ctx)
+	if err != nil {
+		return err
+	}
+	if len(shards) == 0 || shards[0].name != e.shard {
+		// Another shard coordinates the cut-overs
+		return nil
+	}
+
+	tmClient := e.tabletManagerClient()
+	defer tmClient.Close()
+
+	for _, row := range r.Named().Rows {
+		uuid := row["migration_uuid"].ToString()
+		if err := e.coordinateSynchronizedCutOver(ctx, tmClient, shards, uuid); err != nil {
+			log.Errorf("coordinateSynchronizedCutOver: migration %s: %v", uuid, err)
+		}
+	}
+	return nil
+}
+
+// fetchOnShard runs a query on the _vt.schema_migrations table of the primary of a shard
+func fetchOnShard(ctx context.Context, tmClient tmclient.TabletManagerClient, shard *synchronizedCutOverShard, query string) (*sqltypes.Result, error) {
+	qr, err := tmClient.ExecuteFetchAsDba(ctx, shard.tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+		Query:   []byte(query),
+		DbName:  topoproto.TabletDbName(shard.tablet),
+		MaxRows: 1,
+	})
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "shard %s", shard.name)
+	}
+	return sqltypes.Proto3ToResult(qr), nil
+}
+
+// executeOnShard runs an online DDL statement on the primary of a shard
+func executeOnShard(ctx context.Context, tmClient tmclient.TabletManagerClient, shard *synchronizedCutOverShard, query string) error {
+	if _, err := tmClient.ExecuteQuery(ctx, shard.tablet, &tabletmanagerdatapb.ExecuteQueryRequest{
+		Query:   []byte(query),
+		MaxRows: 10,
+	}); err != nil {
+		return vterrors.Wrapf(err, "shard %s", shard.name)
+	}
+	return nil
+}
+
+// coordinateSynchronizedCutOver reads the state of a migration on all the shards, and takes the next step of its
+// synchronized cut-over
+func (e *Executor) coordinateSynchronizedCutOver(ctx context.Context, tmClient tmclient.TabletManagerClient, keyspaceShards []*synchronizedCutOverShard, uuid string) error {
+	query, err := sqlparser.ParseAndBind(sqlSelectSynchronizedCutOverState,
+		sqltypes.StringBindVariable(uuid),
+	)
+	if err != nil {
+		return err
+	}
+	shards := make([]*synchronizedCutOverShard, 0, len(keyspaceShards))
+	for _, keyspaceShard := range keyspaceShards {
+		shard := &synchronizedCutOverShard{name: keyspaceShard.name, tablet: keyspaceShard.tablet}
+		r, err := fetchOnShard(ctx, tmClient, shard, query)
+		if err != nil {
+			return err
+		}
+		if row := r.Named().Row(); row != nil {
+			shard.found = true
+			shard.status = schema.OnlineDDLStatus(row.AsString("migration_status", ""))
+			shard.readyToComplete = row.AsBool("ready_to_complete", false)
+			shard.postponeCompletion = row.AsBool("postpone_completion", false)
+			shard.windowOpened = row.AsBool("cutover_window_opened", false)
+			shard.windowOpen = row.AsBool("cutover_window_open", false)
+		}
+		shards = append(shards, shard)
+	}
+
+	action, reason := decideSynchronizedCutOver(shards)
+	switch action {
+	case synchronizedCutOverOpenWindow:
+		log.Infof("coordinateSynchronizedCutOver: migration %s is ready on all shards; opening a %v cut-over window", uuid, synchronizedCutOverWindow)
+		query, err := sqlparser.ParseAndBind(sqlUpdateCutOverWindow,
+			sqltypes.Int64BindVariable(int64(synchronizedCutOverWindow.Seconds())),
+			sqltypes.StringBindVariable(uuid),
+		)
+		if err != nil {
+			return err
+		}
+		for _, shard := range shards {
+			if _, err := fetchOnShard(ctx, tmClient, shard, query); err != nil {
+				return err
+			}
+		}
+	case synchronizedCutOverRollback:
+		log.Infof("coordinateSynchronizedCutOver: rolling back migration %s: %s", uuid, reason)
+		rolledBack, err := e.rollbackSynchronizedCutOver(ctx, tmClient, shards, uuid, reason)
+		if err != nil || !rolledBack {
+			// Migrations being cancelled are checked again on the next review
+			return err
+		}
+		return e.concludeSynchronizedCutOver(ctx, tmClient, shards, uuid)
+	case synchronizedCutOverConclude:
+		log.Infof("coordinateSynchronizedCutOver: migration %s is complete on all shards", uuid)
+		return e.concludeSynchronizedCutOver(ctx, tmClient, shards, uuid)
+	}
+	return nil
+}
+
+// rollbackSynchronizedCutOver cancels the migration on the shards where it is pending, and reverts it on the shards
+// where it is complete. It returns true once the migration is either failed, cancelled or reverted on all the shards.
+func (e *Executor) rollbackSynchronizedCutOver(ctx context.Context, tmClient tmclient.TabletManagerClient, shards []*synchronizedCutOverShard, uuid string, reason string) (rolledBack bool, err error) {
+	selectRevertQuery, err := sqlparser.ParseAndBind(sqlSelectRevertMigration,
+		sqltypes.StringBindVariable(uuid),
+	)
+	if err != nil {
+		return false, err
+	}
+	// The same revert migration is submitted on all the shards. It may have been submitted already on some of them.
+	var revertUUID string
+	reverted := map[string]bool{}
+	for _, shard := range shards {
+		if shard.status != schema.OnlineDDLStatusComplete {
+			continue
+		}
+		r, err := fetchOnShard(ctx, tmClient, shard, selectRevertQuery)
+		if err != nil {
+			return false, err
+		}
+		if row := r.Named().Row(); row != nil {
+			revertUUID = row.AsString("migration_uuid", "")
+			reverted[shard.name] = true
+		}
+	}
+
+	message := fmt.Sprintf("synchronized cut-over rolled back: %s", reason)
+	rolledBack = true
+	for _, shard := range shards {
+		switch shard.status {
+		case schema.OnlineDDLStatusFailed, schema.OnlineDDLStatusCancelled:
+			// Nothing to roll back
+		case schema.OnlineDDLStatusComplete:
+			if reverted[shard.name] {
+				continue
+			}
+			if revertUUID == "" {
+				if revertUUID, err = schema.CreateOnlineDDLUUID(); err != nil {
+					return false, err
+				}
+			}
+			onlineDDL, _, err := e.readMigration(ctx, uuid)
+			if err != nil {
+				return false, err
+			}
+			revert, err := schema.NewOnlineDDL(e.keyspace, onlineDDL.Table, fmt.Sprintf("revert vitess_migration '%s'", uuid),
+				schema.NewDDLStrategySetting(schema.DDLStrategyVitess, ""), onlineDDL.MigrationContext, revertUUID)
+			if err != nil {
+				return false, err
+			}
+			if err := executeOnShard(ctx, tmClient, shard, revert.SQL); err != nil {
+				return false, err
+			}
+			log.Infof("rollbackSynchronizedCutOver: submitted revert %s of migration %s on shard %s", revertUUID, uuid, shard.name)
+		default:
+			cancel := &sqlparser.AlterMigration{Type: sqlparser.CancelMigrationType, UUID: uuid}
+			if err := executeOnShard(ctx, tmClient, shard, sqlparser.String(cancel)); err != nil {
+				return false, err
+			}
+			query, err := sqlparser.ParseAndBind(sqlUpdateMessage,
+				sqltypes.StringBindVariable(message),
+				sqltypes.StringBindVariable(uuid),
+			)
+			if err != nil {
+				return false, err
+			}
+			if _, err := fetchOnShard(ctx, tmClient, shard, query); err != nil {
+				return false, err
+			}
+			log.Infof("rollbackSynchronizedCutOver: cancelled migration %s on shard %s", uuid, shard.name)
+			// The migration may have cut over while being cancelled, in which case it is reverted on the next review
+			rolledBack = false
+		}
+	}
+	return rolledBack, nil
+}
+
+// concludeSynchronizedCutOver marks the migration as concluded on all the shards, so that it is not reviewed anymore
+func (e *Executor) concludeSynchronizedCutOver(ctx context.Context, tmClient tmclient.TabletManagerClient, shards []*synchronizedCutOverShard, uuid string) error {
+	query, err := sqlparser.ParseAndBind(sqlUpdateCutOverConcluded,
+		sqltypes.StringBindVariable(uuid),
+	)
+	if err != nil {
+		return err
+	}
+	for _, shard := range shards {
+		if _, err := fetchOnShard(ctx, tmClient, shard, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/vt/schema"
+)
+
+func TestDecideSynchronizedCutOver(t *testing.T) {
+	ready := func(name string) *synchronizedCutOverShard {
+		return &synchronizedCutOverShard{name: name, found: true, status: schema.OnlineDDLStatusRunning, readyToComplete: true}
+	}
+	tt := []struct {
+		name   string
+		shards func() []*synchronizedCutOverShard
+		action synchronizedCutOverAction
+		reason string
+	}{
+		{
+			name: "all ready",
+			shards: func() []*synchronizedCutOverShard {
+				return []*synchronizedCutOverShard{ready("-80"), ready("80-")}
+			},
+			action: synchronizedCutOverOpenWindow,
+		},
+		{
+			name: "one not ready",
+			shards: func() []*synchronizedCutOverShard {
+				notReady := ready("80-")
+				notReady.readyToComplete = false
+				return []*synchronizedCutOverShard{ready("-80"), notReady}
+			},
+			action: synchronizedCutOverWait,
+		},
+		{
+			name: "one postponed",
+			shards: func() []*synchronizedCutOverShard {
+				postponed := ready("80-")
+				postponed.postponeCompletion = true
+				return []*synchronizedCutOverShard{ready("-80"), postponed}
+			},
+			action: synchronizedCutOverWait,
+		},
+		{
+			name: "one not submitted",
+			shards: func() []*synchronizedCutOverShard {
+				return []*synchronizedCutOverShard{ready("-80"), {name: "80-"}}
+			},
+			action: synchronizedCutOverWait,
+		},
+		{
+			name: "one queued",
+			shards: func() []*synchronizedCutOverShard {
+				queued := ready("80-")
+				queued.status = schema.OnlineDDLStatusQueued
+				queued.readyToComplete = false
+				return []*synchronizedCutOverShard{ready("-80"), queued}
+			},
+			action: synchronizedCutOverWait,
+		},
+		{
+			name: "one failed",
+			shards: func() []*synchronizedCutOverShard {
+				failed := ready("80-")
+				failed.status = schema.OnlineDDLStatusFailed
+				return []*synchronizedCutOverShard{ready("-80"), failed}
+			},
+			action: synchronizedCutOverRollback,
+			reason: "migration is failed on shard 80-",
+		},
+		{
+			name: "cutting over",
+			shards: func() []*synchronizedCutOverShard {
+				complete := &synchronizedCutOverShard{name: "-80", found: true, status: schema.OnlineDDLStatusComplete, windowOpened: true, windowOpen: true}
+				cuttingOver := ready("80-")
+				cuttingOver.windowOpened = true
+				cuttingOver.windowOpen = true
+				return []*synchronizedCutOverShard{complete, cuttingOver}
+			},
+			action: synchronizedCutOverWait,
+		},
+		{
+			name: "window closed before cut-over",
+			shards: func() []*synchronizedCutOverShard {
+				complete := &synchronizedCutOverShard{name: "-80", found: true, status: schema.OnlineDDLStatusComplete, windowOpened: true}
+				late := ready("80-")
+				late.windowOpened = true
+				return []*synchronizedCutOverShard{complete, late}
+			},
+			action: synchronizedCutOverRollback,
+			reason: "shard 80- did not cut over within the cut-over window",
+		},
+		{
+			name: "all complete",
+			shards: func() []*synchronizedCutOverShard {
+				return []*synchronizedCutOverShard{
+					{name: "-80", found: true, status: schema.OnlineDDLStatusComplete, windowOpened: true},
+					{name: "80-", found: true, status: schema.OnlineDDLStatusComplete, windowOpened: true, windowOpen: true},
+				}
+			},
+			action: synchronizedCutOverConclude,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			action, reason := decideSynchronizedCutOver(tc.shards())
+			assert.Equal(t, tc.action, action)
+			assert.Equal(t, tc.reason, reason)
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/topotools"
 
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
@@ -66,6 +67,12 @@ func (tm *TabletManager) GetPermissions(ctx context.Context) (*tabletmanagerdata
 	return mysqlctl.GetPermissions(tm.MysqlDaemon)
 }
 
+// GetUnresolvedTransactions returns the unresolved distributed transactions
+// that were created more than abandonAge seconds ago.
+func (tm *TabletManager) GetUnresolvedTransactions(ctx context.Context, abandonAge int64) ([]*querypb.TransactionMetadata, error) {
+	return tm.QueryServiceControl.UnresolvedTransactions(ctx, time.Duration(abandonAge)*time.Second)
+}
+
 // SetReadOnly makes the mysql instance read-only or read-write.
 func (tm *TabletManager) SetReadOnly(ctx context.Context, rdonly bool) error {
 	if err := tm.lock(ctx); err != nil {
@@ -41,8 +41,16 @@ type RPCTM interface {
 
 	GetSchema(ctx context.Context, request *tabletmanagerdatapb.GetSchemaRequest) (*tabletmanagerdatapb.SchemaDefinition, error)
 
+	GetSchemaHistory(ctx context.Context, request *tabletmanagerdatapb.GetSchemaHistoryRequest) (*tabletmanagerdatapb.GetSchemaHistoryResponse, error)
+
 	GetPermissions(ctx context.Context) (*tabletmanagerdatapb.Permissions, error)
 
+	GetUnresolvedTransactions(ctx context.Context, abandonAge int64) ([]*querypb.TransactionMetadata, error)
+
+	GetGCTables(ctx context.Context) ([]*tabletmanagerdatapb.GCTable, error)
+
+	EstimateSchemaMigration(ctx context.Context, request *tabletmanagerdatapb.EstimateSchemaMigrationRequest) (*tabletmanagerdatapb.SchemaMigrationEstimate, error)
+
 	// Various read-write methods
 
 	SetReadOnly(ctx context.Context, rdonly bool) error
@@ -63,6 +71,8 @@ type RPCTM interface {
 
 	ApplySchema(ctx context.Context, change *tmutils.SchemaChange) (*tabletmanagerdatapb.SchemaChangeResult, error)
 
+	UpdateGCTable(ctx context.Context, tableName string, action tabletmanagerdatapb.UpdateGCTableRequest_Action) error
+
 	LockTables(ctx context.Context) error
 
 	UnlockTables(ctx context.Context) error
@@ -138,6 +148,8 @@ type RPCTM interface {
 
 	RestoreFromBackup(ctx context.Context, logger logutil.Logger, request *tabletmanagerdatapb.RestoreFromBackupRequest) error
 
+	SetBackupRateLimits(ctx context.Context, read, write int64) error
+
 	// HandleRPCPanic is to be called in a defer statement in each
 	// RPC input point.
 	HandleRPCPanic(ctx context.Context, name string, args, reply any, verbose bool, err *error)
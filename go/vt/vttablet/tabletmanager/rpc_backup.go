@@ -21,10 +21,14 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/spf13/pflag"
+
 	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -33,8 +37,21 @@ import (
 const (
 	backupModeOnline  = "online"
 	backupModeOffline = "offline"
+
+	backupThrottlerAppName = "backup"
 )
 
+var backupCheckThrottler bool
+
+func registerBackupFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&backupCheckThrottler, "backup-check-throttler", backupCheckThrottler, "if set, builtin backups check the tablet throttler with the backup app name, and pause reading files while the tablet is throttled")
+}
+
+func init() {
+	servenv.OnParseFor("vtcombo", registerBackupFlags)
+	servenv.OnParseFor("vttablet", registerBackupFlags)
+}
+
 // Backup takes a db backup and sends it to the BackupStorage
 func (tm *TabletManager) Backup(ctx context.Context, logger logutil.Logger, req *tabletmanagerdatapb.BackupRequest) error {
 	if tm.Cnf == nil {
@@ -106,6 +123,9 @@ func (tm *TabletManager) Backup(ctx context.Context, logger logutil.Logger, req
 		TabletAlias:        topoproto.TabletAliasString(tablet.Alias),
 		BackupTime:         time.Now(),
 	}
+	if backupCheckThrottler {
+		backupParams.NewThrottleCheck = tm.newBackupThrottleCheck
+	}
 
 	returnErr := mysqlctl.Backup(ctx, backupParams)
 
@@ -158,6 +178,23 @@ func (tm *TabletManager) RestoreFromBackup(ctx context.Context, logger logutil.L
 	return err
 }
 
+// SetBackupRateLimits changes the rate limits of the backups and restores of
+// this tablet, including the ones in progress.
+func (tm *TabletManager) SetBackupRateLimits(ctx context.Context, read, write int64) error {
+	return mysqlctl.SetBackupRateLimits(read, write)
+}
+
+// newBackupThrottleCheck returns a function which checks the throttler of
+// this tablet with the backup app name. The throttler client caches its
+// results and is not safe for concurrent use, hence a new one is created for
+// each file.
+func (tm *TabletManager) newBackupThrottleCheck() func(ctx context.Context) bool {
+	client := throttle.NewBackgroundClient(tm.QueryServiceControl.LagThrottler(), backupThrottlerAppName, throttle.ThrottleCheckSelf)
+	return func(ctx context.Context) bool {
+		return client.ThrottleCheckOK(ctx, "")
+	}
+}
+
 func (tm *TabletManager) beginBackup(backupMode string) error {
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
@@ -22,11 +22,14 @@ import (
 	"context"
 
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 // GetSchema returns the schema.
@@ -34,6 +37,36 @@ func (tm *TabletManager) GetSchema(ctx context.Context, request *tabletmanagerda
 	return tm.MysqlDaemon.GetSchema(ctx, topoproto.TabletDbName(tm.Tablet()), request)
 }
 
+// GetSchemaHistory returns the changes to a table's definition recorded by
+// the schema tracker and, if a position is given, the table's definition at
+// that position.
+func (tm *TabletManager) GetSchemaHistory(ctx context.Context, request *tabletmanagerdatapb.GetSchemaHistoryRequest) (*tabletmanagerdatapb.GetSchemaHistoryResponse, error) {
+	se := tm.QueryServiceControl.SchemaEngine()
+	versions, err := se.GetTableHistory(ctx, request.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &tabletmanagerdatapb.GetSchemaHistoryResponse{}
+	for _, v := range versions {
+		response.History = append(response.History, &tabletmanagerdatapb.SchemaHistoryEntry{
+			Id:          v.ID,
+			Position:    mysql.EncodePosition(v.Pos),
+			Ddl:         v.DDL,
+			TimeUpdated: v.TimeUpdated,
+			Table:       v.Table,
+		})
+	}
+
+	if request.Position != "" {
+		response.TableAtPosition, err = se.GetTableForPos(sqlparser.NewIdentifierCS(request.TableName), request.Position)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
 // ReloadSchema will reload the schema
 // This doesn't need the action mutex because periodic schema reloads happen
 // in the background anyway.
@@ -92,3 +125,58 @@ func (tm *TabletManager) ApplySchema(ctx context.Context, change *tmutils.Schema
 	tm.ReloadSchema(ctx, "") // nolint:errcheck
 	return scr, nil
 }
+
+// GetGCTables returns the tables awaiting garbage collection, and the progress
+// of their purge.
+func (tm *TabletManager) GetGCTables(ctx context.Context) ([]*tabletmanagerdatapb.GCTable, error) {
+	tableGC := tm.QueryServiceControl.TableGC()
+	if tableGC == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table garbage collection is not available on this tablet")
+	}
+
+	tables := []*tabletmanagerdatapb.GCTable{}
+	for _, status := range tableGC.Tables() {
+		table := &tabletmanagerdatapb.GCTable{
+			TableName:     status.TableName,
+			State:         string(status.State),
+			IsPaused:      status.IsPaused,
+			IsPurging:     status.IsPurging,
+			RowsPurged:    status.RowsPurged,
+			RowsRemaining: status.RowsRemaining,
+		}
+		if !status.PurgeStartedAt.IsZero() {
+			table.PurgeStartedAt = protoutil.TimeToProto(status.PurgeStartedAt)
+		}
+		if !status.EstimatedCompletion.IsZero() {
+			table.EstimatedCompletion = protoutil.TimeToProto(status.EstimatedCompletion)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// EstimateSchemaMigration estimates the impact of running a DDL statement as
+// an Online DDL migration, without running it.
+func (tm *TabletManager) EstimateSchemaMigration(ctx context.Context, request *tabletmanagerdatapb.EstimateSchemaMigrationRequest) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	return tm.QueryServiceControl.EstimateSchemaMigration(ctx, request.Sql, request.DdlStrategy)
+}
+
+// UpdateGCTable pauses, resumes or immediately drops a table awaiting garbage collection.
+func (tm *TabletManager) UpdateGCTable(ctx context.Context, tableName string, action tabletmanagerdatapb.UpdateGCTableRequest_Action) error {
+	tableGC := tm.QueryServiceControl.TableGC()
+	if tableGC == nil {
+		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "table garbage collection is not available on this tablet")
+	}
+
+	switch action {
+	case tabletmanagerdatapb.UpdateGCTableRequest_UNKNOWN:
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "missing action for table %v", tableName)
+	case tabletmanagerdatapb.UpdateGCTableRequest_PAUSE:
+		return tableGC.PauseTable(tableName)
+	case tabletmanagerdatapb.UpdateGCTableRequest_RESUME:
+		return tableGC.ResumeTable(tableName)
+	case tabletmanagerdatapb.UpdateGCTableRequest_DROP:
+		return tableGC.DropTable(ctx, tableName)
+	}
+	return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown action %v", action)
+}
@@ -0,0 +1,814 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+/*
+	The checksum and sampled modes of VDiff compare the rows of a table by chunks of consecutive PKs, rather than one
+	row at a time. A chunk covers the same PK range on both sides.
+
+	* In checksum mode, the row counts and the checksums of both sides of a chunk are compared first, and only the
+	  chunks whose counts or checksums differ are compared row by row. The checksums are computed on the bytes of the
+	  values, so values which are only equal by their collation make the chunk mismatch: its rows are then compared
+	  with their collations, and such a chunk is not reported as a difference.
+	* In sampled mode, each chunk is compared with a probability of sample_pct percent, and the rows of the chunks
+	  which are not compared are reported as skipped.
+
+	Both modes can be combined.
+
+	The chunks are pushed down to the tablets when the source query of the table selects plain columns without a
+	WHERE or GROUP BY clause, when no source tablet is a primary, and when the source is not an external cluster.
+	The bounds of each chunk are read with a LIMIT query, and each tablet computes the count and the checksum of its
+	side with a COUNT(*), BIT_XOR(CRC32(...)) query over the PK range of the chunk. The rows are only read for the
+	chunks which mismatch or are sampled, with a query over the same PK range. So that all these queries see the same
+	data, the replication of the source tablets is stopped at a position past the one of the target streams, the
+	target streams are run up to it, and both stay stopped until the table is diffed: RDONLY source tablets are
+	preferable for large tables.
+
+	Otherwise, e.g. when the rows are filtered by keyrange, which MySQL cannot evaluate, the rows are streamed from
+	the tablets like in a row by row diff, chunks of up to diffChunkSize source rows are made from them, and their
+	checksums are computed by the differ. Only the comparisons of the matching chunks are skipped.
+*/
+
+// diffChunkSize is the maximum number of source rows of a chunk.
+var diffChunkSize = 1000
+
+// rowIterator returns the rows of one side of the diff, in PK order, and nil after the last one.
+type rowIterator interface {
+	next() ([]sqltypes.Value, error)
+}
+
+// chunkChecksum is the row count and the checksum of one side of a chunk.
+type chunkChecksum struct {
+	rows     int64
+	checksum uint64
+}
+
+// chunk is a range of consecutive PKs of the table, on both sides.
+type chunk interface {
+	// checksums returns the row counts of both sides of the chunk, along with their checksums if withChecksums is set.
+	checksums(ctx context.Context, withChecksums bool) (source, target chunkChecksum, err error)
+	// rows returns the rows of both sides of the chunk, in PK order.
+	rows(ctx context.Context) (sourceRows, targetRows [][]sqltypes.Value, err error)
+	// lastRow returns a row with the last PK of the chunk, to save the progress of the diff, or nil if the chunk has
+	// no upper bound.
+	lastRow() []sqltypes.Value
+}
+
+// chunkReader splits a table into chunks.
+type chunkReader interface {
+	// nextChunk returns the next chunk, which has up to limit source rows, or nil after the last chunk.
+	nextChunk(ctx context.Context, limit int64) (chunk, error)
+}
+
+// chunkDiffer diffs a table by chunks of consecutive PKs.
+type chunkDiffer struct {
+	td       *tableDiffer
+	chunks   chunkReader
+	checksum bool
+
+	// sample decides whether a chunk is compared.
+	sample func() bool
+}
+
+func newChunkDiffer(td *tableDiffer, chunks chunkReader, checksum bool, samplePct int64) *chunkDiffer {
+	cd := &chunkDiffer{
+		td:       td,
+		chunks:   chunks,
+		checksum: checksum,
+		sample:   func() bool { return true },
+	}
+	if samplePct > 0 && samplePct < 100 {
+		cd.sample = func() bool { return rand.Int63n(100) < samplePct }
+	}
+	return cd
+}
+
+// diff compares the chunks of the table until the last one, or until rowsToCompare rows were processed.
+// The progress is saved at the chunk boundaries, so that a resumed diff starts with a new chunk.
+func (cd *chunkDiffer) diff(ctx context.Context, dbClient binlogplayer.DBClient, dr *DiffReport, mismatch bool, rowsToCompare int64, debug, onlyPks bool, maxExtraRowsToCompare int64) (*DiffReport, error) {
+	td := cd.td
+	var lastProcessedRow []sqltypes.Value
+
+	// Save our progress when we finish the run
+	defer func() {
+		if err := td.updateTableProgress(dbClient, dr, lastProcessedRow); err != nil {
+			log.Errorf("Failed to update vdiff progress on %s table: %v", td.table.Name, err)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, vterrors.Errorf(vtrpcpb.Code_CANCELED, "context has expired")
+		default:
+		}
+
+		if rowsToCompare <= 0 {
+			log.Infof("Stopping vdiff, specified limit reached")
+			return dr, nil
+		}
+		limit := int64(diffChunkSize)
+		if rowsToCompare < limit {
+			limit = rowsToCompare
+		}
+		ch, err := cd.chunks.nextChunk(ctx, limit)
+		if err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		if ch == nil {
+			return dr, nil
+		}
+
+		processedRows := dr.ProcessedRows
+		if err := cd.diffChunk(ctx, dr, ch, debug, onlyPks, maxExtraRowsToCompare); err != nil {
+			return nil, err
+		}
+		rowsToCompare -= dr.ProcessedRows - processedRows
+		if lastRow := ch.lastRow(); lastRow != nil {
+			lastProcessedRow = lastRow
+		}
+
+		if !mismatch && dr.MismatchedRows > 0 {
+			mismatch = true
+			log.Infof("Flagging mismatch for %s: %+v", td.table.Name, dr)
+			if err := updateTableMismatch(dbClient, td.wd.ct.id, td.table.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		// Update progress about every 10,000 rows, like the row by row diff does.
+		if dr.ProcessedRows/1e4 != processedRows/1e4 {
+			if err := td.updateTableProgress(dbClient, dr, lastProcessedRow); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// diffChunk compares the rows of a chunk, unless it is not sampled or its checksums match.
+func (cd *chunkDiffer) diffChunk(ctx context.Context, dr *DiffReport, ch chunk, debug, onlyPks bool, maxExtraRowsToCompare int64) error {
+	if !cd.sample() {
+		source, target, err := ch.checksums(ctx, false)
+		if err != nil {
+			return err
+		}
+		rows := source.rows
+		if target.rows > rows {
+			rows = target.rows
+		}
+		dr.ProcessedRows += rows
+		dr.SkippedRows += rows
+		return nil
+	}
+	if cd.checksum {
+		source, target, err := ch.checksums(ctx, true)
+		if err != nil {
+			return err
+		}
+		if source == target {
+			dr.ProcessedRows += source.rows
+			dr.MatchingRows += source.rows
+			return nil
+		}
+	}
+	sourceRows, targetRows, err := ch.rows(ctx)
+	if err != nil {
+		return err
+	}
+	return cd.compareRows(dr, sourceRows, targetRows, debug, onlyPks, maxExtraRowsToCompare)
+}
+
+// compareRows compares the rows of a chunk one by one, like the row by row diff does.
+func (cd *chunkDiffer) compareRows(dr *DiffReport, sourceRows, targetRows [][]sqltypes.Value, debug, onlyPks bool, maxExtraRowsToCompare int64) error {
+	td := cd.td
+	for len(sourceRows) > 0 || len(targetRows) > 0 {
+		dr.ProcessedRows++
+		if len(targetRows) == 0 {
+			if err := td.addExtraSourceRow(dr, sourceRows[0], debug, onlyPks, maxExtraRowsToCompare); err != nil {
+				return err
+			}
+			sourceRows = sourceRows[1:]
+			continue
+		}
+		if len(sourceRows) == 0 {
+			if err := td.addExtraTargetRow(dr, targetRows[0], debug, onlyPks, maxExtraRowsToCompare); err != nil {
+				return err
+			}
+			targetRows = targetRows[1:]
+			continue
+		}
+
+		sourceRow, targetRow := sourceRows[0], targetRows[0]
+		c, err := td.compare(sourceRow, targetRow, td.tablePlan.comparePKs, false)
+		switch {
+		case err != nil:
+			return err
+		case c < 0:
+			if err := td.addExtraSourceRow(dr, sourceRow, debug, onlyPks, maxExtraRowsToCompare); err != nil {
+				return err
+			}
+			sourceRows = sourceRows[1:]
+			continue
+		case c > 0:
+			if err := td.addExtraTargetRow(dr, targetRow, debug, onlyPks, maxExtraRowsToCompare); err != nil {
+				return err
+			}
+			targetRows = targetRows[1:]
+			continue
+		}
+
+		c, err = td.compare(sourceRow, targetRow, td.tablePlan.compareCols, true)
+		switch {
+		case err != nil:
+			return err
+		case c != 0:
+			if err := td.addMismatchedRow(dr, sourceRow, targetRow, debug, onlyPks); err != nil {
+				return err
+			}
+		default:
+			dr.MatchingRows++
+		}
+		sourceRows, targetRows = sourceRows[1:], targetRows[1:]
+	}
+	return nil
+}
+
+// streamedChunks makes the chunks from the rows streamed from both sides.
+type streamedChunks struct {
+	td             *tableDiffer
+	source, target rowIterator
+
+	// targetRow is the first target row after the current chunk, if it was read.
+	targetRow []sqltypes.Value
+}
+
+// nextChunk reads the rows of the next chunk: up to limit source rows, and the target rows up to the PK of the last
+// of them. Once the source is exhausted, the remaining target rows are returned by chunks of diffChunkSize rows.
+func (sc *streamedChunks) nextChunk(ctx context.Context, limit int64) (chunk, error) {
+	ch := &streamedChunk{td: sc.td}
+	for int64(len(ch.sourceRows)) < limit {
+		row, err := sc.source.next()
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			break
+		}
+		ch.sourceRows = append(ch.sourceRows, row)
+	}
+
+	for {
+		if sc.targetRow == nil {
+			var err error
+			if sc.targetRow, err = sc.target.next(); err != nil {
+				return nil, err
+			}
+			if sc.targetRow == nil {
+				break
+			}
+		}
+		if len(ch.sourceRows) == 0 {
+			if len(ch.targetRows) == diffChunkSize {
+				break
+			}
+		} else {
+			c, err := sc.td.compare(sc.targetRow, ch.sourceRows[len(ch.sourceRows)-1], sc.td.tablePlan.comparePKs, false)
+			if err != nil {
+				return nil, err
+			}
+			if c > 0 {
+				break
+			}
+		}
+		ch.targetRows = append(ch.targetRows, sc.targetRow)
+		sc.targetRow = nil
+	}
+	if len(ch.sourceRows) == 0 && len(ch.targetRows) == 0 {
+		return nil, nil
+	}
+	return ch, nil
+}
+
+// streamedChunk is a chunk whose rows were streamed.
+type streamedChunk struct {
+	td                     *tableDiffer
+	sourceRows, targetRows [][]sqltypes.Value
+}
+
+func (ch *streamedChunk) checksums(ctx context.Context, withChecksums bool) (source, target chunkChecksum, err error) {
+	source.rows, target.rows = int64(len(ch.sourceRows)), int64(len(ch.targetRows))
+	if withChecksums {
+		source.checksum, target.checksum = ch.hashRows(ch.sourceRows), ch.hashRows(ch.targetRows)
+	}
+	return source, target, nil
+}
+
+func (ch *streamedChunk) rows(ctx context.Context) (sourceRows, targetRows [][]sqltypes.Value, err error) {
+	return ch.sourceRows, ch.targetRows, nil
+}
+
+func (ch *streamedChunk) lastRow() []sqltypes.Value {
+	if len(ch.sourceRows) == 0 {
+		return nil
+	}
+	return ch.sourceRows[len(ch.sourceRows)-1]
+}
+
+// hashRows returns the hash of the compared columns of the rows. The values are prefixed by their length,
+// or by a marker for NULL, so that different rows cannot have the same encoding.
+func (ch *streamedChunk) hashRows(rows [][]sqltypes.Value) uint64 {
+	h := fnv.New64a()
+	var length [9]byte
+	for _, row := range rows {
+		for _, col := range ch.td.tablePlan.compareCols {
+			value := row[col.colIndex]
+			if value.IsNull() {
+				h.Write([]byte{0})
+				continue
+			}
+			length[0] = 1
+			binary.BigEndian.PutUint64(length[1:], uint64(value.Len()))
+			h.Write(length[:])
+			h.Write(value.Raw())
+		}
+	}
+	return h.Sum64()
+}
+
+// chunkQueries builds the queries of the chunks of one side of the diff.
+type chunkQueries struct {
+	from    sqlparser.TableExprs
+	columns []*sqlparser.ColName
+	pks     []*sqlparser.ColName
+}
+
+// newChunkQueries returns the chunk queries of one side of the diff, built from its select query. It returns false if
+// the query cannot be pushed down, i.e. if it selects anything else than plain columns, or if it has a WHERE or a
+// GROUP BY clause.
+func newChunkQueries(query string, pkCols []int) (*chunkQueries, bool) {
+	statement, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, false
+	}
+	sel, ok := statement.(*sqlparser.Select)
+	if !ok || sel.Where != nil || len(sel.GroupBy) != 0 || sel.Having != nil {
+		return nil, false
+	}
+	cq := &chunkQueries{from: sel.From}
+	for _, selExpr := range sel.SelectExprs {
+		aliasedExpr, ok := selExpr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, false
+		}
+		col, ok := aliasedExpr.Expr.(*sqlparser.ColName)
+		if !ok {
+			return nil, false
+		}
+		cq.columns = append(cq.columns, col)
+	}
+	for _, pkCol := range pkCols {
+		cq.pks = append(cq.pks, cq.columns[pkCol])
+	}
+	return cq, true
+}
+
+// boundaryQuery returns the query of the PK of the limit-th row after lower.
+func (cq *chunkQueries) boundaryQuery(lower []sqltypes.Value, limit int64) string {
+	buf := sqlparser.NewTrackedBuffer(nil)
+	buf.Myprintf("select ")
+	writeColumns(buf, cq.pks)
+	buf.Myprintf(" from %v", cq.from)
+	cq.writeWhere(buf, lower, nil)
+	buf.Myprintf(" order by ")
+	writeColumns(buf, cq.pks)
+	buf.Myprintf(" limit %d, 1", limit-1)
+	return buf.String()
+}
+
+// checksumQuery returns the query of the row count of a chunk, along with its checksum if withChecksum is set.
+// The NULL flags of the columns are appended to their values, as CONCAT_WS skips the NULL values.
+func (cq *chunkQueries) checksumQuery(lower, upper []sqltypes.Value, withChecksum bool) string {
+	buf := sqlparser.NewTrackedBuffer(nil)
+	buf.Myprintf("select count(*)")
+	if withChecksum {
+		buf.Myprintf(", bit_xor(crc32(concat_ws('#', ")
+		writeColumns(buf, cq.columns)
+		buf.Myprintf(", concat(")
+		for i, col := range cq.columns {
+			if i > 0 {
+				buf.Myprintf(", ")
+			}
+			buf.Myprintf("isnull(%v)", col)
+		}
+		buf.Myprintf("))))")
+	}
+	buf.Myprintf(" from %v", cq.from)
+	cq.writeWhere(buf, lower, upper)
+	return buf.String()
+}
+
+// rowsQuery returns the query of the rows of a chunk, in PK order.
+func (cq *chunkQueries) rowsQuery(lower, upper []sqltypes.Value) string {
+	buf := sqlparser.NewTrackedBuffer(nil)
+	buf.Myprintf("select ")
+	writeColumns(buf, cq.columns)
+	buf.Myprintf(" from %v", cq.from)
+	cq.writeWhere(buf, lower, upper)
+	buf.Myprintf(" order by ")
+	writeColumns(buf, cq.pks)
+	return buf.String()
+}
+
+// writeWhere writes the condition of the PKs which are greater than lower and not greater than upper, a nil bound
+// being ignored.
+func (cq *chunkQueries) writeWhere(buf *sqlparser.TrackedBuffer, lower, upper []sqltypes.Value) {
+	prefix := " where "
+	if lower != nil {
+		buf.Myprintf("%s", prefix)
+		cq.writeBound(buf, lower, ">", ">")
+		prefix = " and "
+	}
+	if upper != nil {
+		buf.Myprintf("%s", prefix)
+		cq.writeBound(buf, upper, "<", "<=")
+	}
+}
+
+// writeBound writes the comparison of the PKs with a bound, the last PK column being compared with lastOp and the
+// previous ones with op. Like in the row streamer, the comparison of composite PKs is expanded: for instance,
+// (pk1, pk2) > (1, 2) is written as ((pk1 = 1 and pk2 > 2) or (pk1 > 1)), since a tuple comparison ends up
+// being a full table scan for mysql.
+func (cq *chunkQueries) writeBound(buf *sqlparser.TrackedBuffer, bound []sqltypes.Value, op, lastOp string) {
+	buf.Myprintf("(")
+	prefix := ""
+	for lastcol := len(cq.pks) - 1; lastcol >= 0; lastcol-- {
+		buf.Myprintf("%s(", prefix)
+		prefix = " or "
+		for i, pk := range cq.pks[:lastcol] {
+			buf.Myprintf("%v = ", pk)
+			bound[i].EncodeSQL(buf)
+			buf.Myprintf(" and ")
+		}
+		colOp := op
+		if lastcol == len(cq.pks)-1 {
+			colOp = lastOp
+		}
+		buf.Myprintf("%v %s ", cq.pks[lastcol], colOp)
+		bound[lastcol].EncodeSQL(buf)
+		buf.Myprintf(")")
+	}
+	buf.Myprintf(")")
+}
+
+func writeColumns(buf *sqlparser.TrackedBuffer, cols []*sqlparser.ColName) {
+	for i, col := range cols {
+		if i > 0 {
+			buf.Myprintf(", ")
+		}
+		buf.Myprintf("%v", col)
+	}
+}
+
+// chunkTablet runs the chunk queries of one side of the diff on a tablet.
+type chunkTablet struct {
+	tablet  *topodatapb.Tablet
+	queries *chunkQueries
+	conn    queryservice.QueryService
+
+	// semiSync is whether the replication of a source tablet is restarted as a semi-sync replica.
+	semiSync bool
+}
+
+func (t *chunkTablet) execute(ctx context.Context, query string) (*sqltypes.Result, error) {
+	target := &querypb.Target{
+		Keyspace:   t.tablet.Keyspace,
+		Shard:      t.tablet.Shard,
+		TabletType: t.tablet.Type,
+	}
+	qr, err := t.conn.Execute(ctx, target, query, nil, 0, 0, nil)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "chunk query on tablet %v", topoproto.TabletAliasString(t.tablet.Alias))
+	}
+	return qr, nil
+}
+
+// checksum returns the row count of a chunk, along with its checksum if withChecksum is set.
+func (t *chunkTablet) checksum(ctx context.Context, lower, upper []sqltypes.Value, withChecksum bool) (chunkChecksum, error) {
+	var sum chunkChecksum
+	qr, err := t.execute(ctx, t.queries.checksumQuery(lower, upper, withChecksum))
+	if err != nil {
+		return sum, err
+	}
+	if len(qr.Rows) != 1 {
+		return sum, fmt.Errorf("unexpected checksum result on tablet %v: %v", topoproto.TabletAliasString(t.tablet.Alias), qr.Rows)
+	}
+	if sum.rows, err = qr.Rows[0][0].ToInt64(); err != nil {
+		return sum, err
+	}
+	if withChecksum {
+		if sum.checksum, err = qr.Rows[0][1].ToUint64(); err != nil {
+			return sum, err
+		}
+	}
+	return sum, nil
+}
+
+// pushedDownChunks reads the chunks with queries run by the tablets, which are pinned by pinTablets.
+type pushedDownChunks struct {
+	td      *tableDiffer
+	sources []*chunkTablet
+	target  *chunkTablet
+
+	// lower is the last PK of the previous chunk, or nil before the first chunk.
+	lower []sqltypes.Value
+	done  bool
+}
+
+// nextChunk returns the chunk from lower up to the smallest PK of the limit-th rows after lower on all the tablets,
+// so that the chunk has up to limit rows on each of them. The last chunk, which has no upper bound, is returned once
+// all the tablets have fewer rows.
+func (pc *pushedDownChunks) nextChunk(ctx context.Context, limit int64) (chunk, error) {
+	if pc.done {
+		return nil, nil
+	}
+	pks := make([]compareColInfo, len(pc.td.tablePlan.comparePKs))
+	for i, pk := range pc.td.tablePlan.comparePKs {
+		pks[i] = pk
+		pks[i].colIndex = i
+	}
+	var upper []sqltypes.Value
+	for _, tablet := range append(pc.sources, pc.target) {
+		qr, err := tablet.execute(ctx, tablet.queries.boundaryQuery(pc.lower, limit))
+		if err != nil {
+			return nil, err
+		}
+		if len(qr.Rows) == 0 {
+			continue
+		}
+		if upper != nil {
+			c, err := pc.td.compare(qr.Rows[0], upper, pks, false)
+			if err != nil {
+				return nil, err
+			}
+			if c >= 0 {
+				continue
+			}
+		}
+		upper = qr.Rows[0]
+	}
+	ch := &pushedDownChunk{chunks: pc, lower: pc.lower, upper: upper}
+	pc.lower = upper
+	pc.done = upper == nil
+	return ch, nil
+}
+
+// pushedDownChunk is a chunk whose PKs are greater than lower, and not greater than upper unless it is nil.
+type pushedDownChunk struct {
+	chunks       *pushedDownChunks
+	lower, upper []sqltypes.Value
+}
+
+func (ch *pushedDownChunk) checksums(ctx context.Context, withChecksums bool) (source, target chunkChecksum, err error) {
+	// The checksums of the source shards combine, as they are XORs of the row checksums.
+	for _, tablet := range ch.chunks.sources {
+		sum, err := tablet.checksum(ctx, ch.lower, ch.upper, withChecksums)
+		if err != nil {
+			return source, target, err
+		}
+		source.rows += sum.rows
+		source.checksum ^= sum.checksum
+	}
+	target, err = ch.chunks.target.checksum(ctx, ch.lower, ch.upper, withChecksums)
+	return source, target, err
+}
+
+func (ch *pushedDownChunk) rows(ctx context.Context) (sourceRows, targetRows [][]sqltypes.Value, err error) {
+	td := ch.chunks.td
+	for _, tablet := range ch.chunks.sources {
+		qr, err := tablet.execute(ctx, tablet.queries.rowsQuery(ch.lower, ch.upper))
+		if err != nil {
+			return nil, nil, err
+		}
+		sourceRows = append(sourceRows, qr.Rows...)
+	}
+	if len(ch.chunks.sources) > 1 {
+		sort.SliceStable(sourceRows, func(i, j int) bool {
+			c, compareErr := td.compare(sourceRows[i], sourceRows[j], td.tablePlan.comparePKs, false)
+			if compareErr != nil && err == nil {
+				err = compareErr
+			}
+			return c < 0
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	qr, err := ch.chunks.target.execute(ctx, ch.chunks.target.queries.rowsQuery(ch.lower, ch.upper))
+	if err != nil {
+		return nil, nil, err
+	}
+	return sourceRows, qr.Rows, nil
+}
+
+func (ch *pushedDownChunk) lastRow() []sqltypes.Value {
+	if ch.upper == nil {
+		return nil
+	}
+	row := make([]sqltypes.Value, len(ch.chunks.td.tablePlan.compareCols))
+	for i, pkCol := range ch.chunks.td.tablePlan.pkCols {
+		row[pkCol] = ch.upper[i]
+	}
+	return row
+}
+
+// diffsChunks is whether the table is diffed by chunks, in checksum or sampled mode.
+func (td *tableDiffer) diffsChunks() bool {
+	coreOptions := td.wd.opts.CoreOptions
+	return coreOptions.Checksum || (coreOptions.SamplePct > 0 && coreOptions.SamplePct < 100)
+}
+
+// chunkQueries returns the chunk queries of both sides of the diff, or false if the chunks cannot be pushed down to
+// the selected tablets.
+func (td *tableDiffer) chunkQueries() (sourceQueries, targetQueries *chunkQueries, ok bool) {
+	ct := td.wd.ct
+	if ct.externalCluster != "" {
+		return nil, nil, false
+	}
+	for _, source := range ct.sources {
+		if source.tablet.Type == topodatapb.TabletType_PRIMARY {
+			return nil, nil, false
+		}
+	}
+	if sourceQueries, ok = newChunkQueries(td.tablePlan.sourceQuery, td.tablePlan.pkCols); !ok {
+		return nil, nil, false
+	}
+	if targetQueries, ok = newChunkQueries(td.tablePlan.targetQuery, td.tablePlan.pkCols); !ok {
+		return nil, nil, false
+	}
+	return sourceQueries, targetQueries, true
+}
+
+// pinTablets stops the replication of the source tablets once they reach the positions of the stopped target
+// streams, runs the target streams up to the positions where the sources stopped, and connects to the tablets
+// to run the chunk queries. The tablets stay pinned until unpinTablets is called, once the table is diffed, or
+// if pinTablets fails.
+func (td *tableDiffer) pinTablets(ctx context.Context, sourceQueries, targetQueries *chunkQueries) (err error) {
+	ct := td.wd.ct
+	chunks := &pushedDownChunks{td: td}
+	td.pushedDownChunks = chunks
+	defer func() {
+		if err != nil {
+			td.unpinTablets()
+		}
+	}()
+
+	if td.lastPK != nil {
+		lastPK := sqltypes.Proto3ToResult(td.lastPK)
+		if len(lastPK.Rows) != 1 {
+			return fmt.Errorf("unexpected lastpk for table %s: %v", td.table.Name, td.lastPK)
+		}
+		chunks.lower = lastPK.Rows[0]
+	}
+
+	timeout := time.Duration(ct.options.CoreOptions.TimeoutSeconds * int64(time.Second))
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	if err := td.forEachSource(func(source *migrationSource) error {
+		tablet := &chunkTablet{tablet: source.tablet, queries: sourceQueries}
+		var err error
+		if tablet.semiSync, err = td.isSemiSyncReplica(waitCtx, source.tablet); err != nil {
+			return err
+		}
+		pos, err := ct.tmc.StopReplicationMinimum(waitCtx, source.tablet, mysql.EncodePosition(source.position), timeout)
+		if err != nil {
+			return vterrors.Wrapf(err, "StopReplicationMinimum for tablet %v", topoproto.TabletAliasString(source.tablet.Alias))
+		}
+		mu.Lock()
+		chunks.sources = append(chunks.sources, tablet)
+		mu.Unlock()
+		source.snapshotPosition = pos
+		tablet.conn, err = tabletconn.GetDialer()(source.tablet, false)
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := td.syncTargetStreams(ctx); err != nil {
+		return err
+	}
+
+	// The target tablet can be a replica of this tablet, which has to catch up with the stopped streams.
+	targetTablet := ct.targetShardStreamer.tablet
+	if !topoproto.TabletAliasEqual(targetTablet.Alias, ct.vde.thisTablet.Alias) {
+		pos, err := ct.tmc.PrimaryPosition(waitCtx, ct.vde.thisTablet)
+		if err != nil {
+			return err
+		}
+		if err := ct.tmc.WaitForPosition(waitCtx, targetTablet, pos); err != nil {
+			return vterrors.Wrapf(err, "WaitForPosition for tablet %v", topoproto.TabletAliasString(targetTablet.Alias))
+		}
+	}
+	conn, err := tabletconn.GetDialer()(targetTablet, false)
+	if err != nil {
+		return err
+	}
+	chunks.target = &chunkTablet{tablet: targetTablet, queries: targetQueries, conn: conn}
+	return nil
+}
+
+// unpinTablets closes the connections to the tablets, and restarts the replication of the source tablets and the
+// target streams.
+func (td *tableDiffer) unpinTablets() {
+	ct := td.wd.ct
+	chunks := td.pushedDownChunks
+	td.pushedDownChunks = nil
+
+	// We use a new context as we want to reset the state even
+	// when the parent context has timed out or been canceled.
+	ctx, cancel := context.WithTimeout(context.Background(), BackgroundOperationTimeout)
+	defer cancel()
+	for _, source := range chunks.sources {
+		if source.conn != nil {
+			source.conn.Close(ctx)
+		}
+		if err := ct.tmc.StartReplication(ctx, source.tablet, source.semiSync); err != nil {
+			log.Errorf("Could not restart replication on source tablet %v, please restart it manually: %v",
+				topoproto.TabletAliasString(source.tablet.Alias), err)
+		}
+	}
+	if chunks.target != nil {
+		chunks.target.conn.Close(ctx)
+	}
+	log.Infof("Restarting the %q VReplication workflow on target tablets in keyspace %q",
+		ct.workflow, ct.vde.thisTablet.Keyspace)
+	if err := td.restartTargetVReplicationStreams(ctx); err != nil {
+		log.Errorf("error restarting target streams: %v", err)
+	}
+}
+
+// isSemiSyncReplica returns whether a source tablet replicates as a semi-sync replica of its primary.
+func (td *tableDiffer) isSemiSyncReplica(ctx context.Context, tablet *topodatapb.Tablet) (bool, error) {
+	ts := td.wd.ct.ts
+	durabilityName, err := ts.GetKeyspaceDurability(ctx, tablet.Keyspace)
+	if err != nil {
+		return false, err
+	}
+	durability, err := reparentutil.GetDurabilityPolicy(durabilityName)
+	if err != nil {
+		return false, err
+	}
+	shard, err := ts.GetShard(ctx, tablet.Keyspace, tablet.Shard)
+	if err != nil {
+		return false, err
+	}
+	if !shard.HasPrimary() {
+		return false, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "no primary tablet for shard %v/%v", tablet.Keyspace, tablet.Shard)
+	}
+	primary, err := ts.GetTablet(ctx, shard.PrimaryAlias)
+	if err != nil {
+		return false, err
+	}
+	return reparentutil.IsReplicaSemiSync(durability, primary.Tablet, tablet), nil
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vdiff
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// fakeRowIterator returns its rows, and then nil.
+type fakeRowIterator struct {
+	rows [][]sqltypes.Value
+}
+
+func (it *fakeRowIterator) next() ([]sqltypes.Value, error) {
+	if len(it.rows) == 0 {
+		return nil, nil
+	}
+	row := it.rows[0]
+	it.rows = it.rows[1:]
+	return row, nil
+}
+
+func chunkDifferRows(rows ...string) [][]sqltypes.Value {
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("c1|c2", "int64|varchar"), rows...)
+	return result.Rows
+}
+
+func newTestTableDiffer() *tableDiffer {
+	return &tableDiffer{
+		tablePlan: &tablePlan{
+			sourceQuery: "select c1, c2 from t1 order by c1 asc",
+			targetQuery: "select c1, c2 from t1 order by c1 asc",
+			compareCols: []compareColInfo{{0, collations.Collation(nil), true, "c1"}, {1, collations.Collation(nil), false, "c2"}},
+			comparePKs:  []compareColInfo{{0, collations.Collation(nil), true, "c1"}},
+			pkCols:      []int{0},
+			selectPks:   []int{0},
+		},
+	}
+}
+
+func newTestChunkDiffer(checksum bool, samples ...bool) *chunkDiffer {
+	td := newTestTableDiffer()
+	chunks := &streamedChunks{
+		td:     td,
+		source: &fakeRowIterator{rows: chunkDifferRows("1|a", "2|b", "3|c", "5|e", "6|f")},
+		target: &fakeRowIterator{rows: chunkDifferRows("1|a", "2|b", "3|x", "4|d", "5|e", "6|f", "7|g", "8|h", "9|i")},
+	}
+	cd := newChunkDiffer(td, chunks, checksum, 100)
+	if len(samples) > 0 {
+		cd.sample = func() bool {
+			sample := samples[0]
+			samples = samples[1:]
+			return sample
+		}
+	}
+	return cd
+}
+
+func TestChunkDiffer(t *testing.T) {
+	defer func(chunkSize int) { diffChunkSize = chunkSize }(diffChunkSize)
+	diffChunkSize = 2
+
+	// The chunks cover the same PK ranges on both sides, and the target
+	// rows after the last source row are read by chunks too.
+	ctx := context.Background()
+	cd := newTestChunkDiffer(false)
+	var chunks [][2]int
+	for {
+		ch, err := cd.chunks.nextChunk(ctx, int64(diffChunkSize))
+		require.NoError(t, err)
+		if ch == nil {
+			break
+		}
+		sourceRows, targetRows, err := ch.rows(ctx)
+		require.NoError(t, err)
+		chunks = append(chunks, [2]int{len(sourceRows), len(targetRows)})
+	}
+	assert.Equal(t, [][2]int{{2, 2}, {2, 3}, {1, 1}, {0, 2}, {0, 1}}, chunks)
+
+	testcases := []struct {
+		name     string
+		checksum bool
+		samples  []bool
+		expected DiffReport
+	}{{
+		name:     "checksum",
+		checksum: true,
+		expected: DiffReport{ProcessedRows: 9, MatchingRows: 4, MismatchedRows: 1, ExtraRowsTarget: 4},
+	}, {
+		name:     "sampled",
+		samples:  []bool{false, true, false, true, false},
+		expected: DiffReport{ProcessedRows: 9, MatchingRows: 1, MismatchedRows: 1, ExtraRowsTarget: 3, SkippedRows: 4},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			cd := newTestChunkDiffer(tcase.checksum, tcase.samples...)
+			dr := &DiffReport{}
+			for {
+				ch, err := cd.chunks.nextChunk(ctx, int64(diffChunkSize))
+				require.NoError(t, err)
+				if ch == nil {
+					break
+				}
+				require.NoError(t, cd.diffChunk(ctx, dr, ch, false, true, 1000))
+			}
+			assert.Equal(t, tcase.expected.ProcessedRows, dr.ProcessedRows)
+			assert.Equal(t, tcase.expected.MatchingRows, dr.MatchingRows)
+			assert.Equal(t, tcase.expected.MismatchedRows, dr.MismatchedRows)
+			assert.Equal(t, tcase.expected.ExtraRowsSource, dr.ExtraRowsSource)
+			assert.Equal(t, tcase.expected.ExtraRowsTarget, dr.ExtraRowsTarget)
+			assert.Equal(t, tcase.expected.SkippedRows, dr.SkippedRows)
+			assert.Len(t, dr.MismatchedRowsDiffs, int(tcase.expected.MismatchedRows))
+			assert.Len(t, dr.ExtraRowsTargetDiffs, int(tcase.expected.ExtraRowsTarget))
+		})
+	}
+}
+
+func TestHashRows(t *testing.T) {
+	ch := &streamedChunk{td: newTestTableDiffer()}
+	rows := chunkDifferRows("1|a", "2|b")
+	assert.Equal(t, ch.hashRows(rows), ch.hashRows(chunkDifferRows("1|a", "2|b")))
+	assert.NotEqual(t, ch.hashRows(rows), ch.hashRows(chunkDifferRows("1|a", "2|c")))
+	assert.NotEqual(t, ch.hashRows(rows), ch.hashRows(chunkDifferRows("2|b", "1|a")))
+
+	// NULL and empty values, and values split differently across
+	// columns, have different checksums.
+	empty := [][]sqltypes.Value{{sqltypes.NewInt64(1), sqltypes.NewVarChar("")}}
+	null := [][]sqltypes.Value{{sqltypes.NewInt64(1), sqltypes.NULL}}
+	assert.NotEqual(t, ch.hashRows(empty), ch.hashRows(null))
+	split1 := [][]sqltypes.Value{{sqltypes.NewVarChar("1"), sqltypes.NewVarChar("23")}}
+	split2 := [][]sqltypes.Value{{sqltypes.NewVarChar("12"), sqltypes.NewVarChar("3")}}
+	assert.NotEqual(t, ch.hashRows(split1), ch.hashRows(split2))
+}
+
+func TestChunkQueries(t *testing.T) {
+	_, ok := newChunkQueries("select c1, c2 from t1 where in_keyrange(c1, 'hash', '-80') order by c1 asc", []int{0})
+	assert.False(t, ok)
+	_, ok = newChunkQueries("select c1, concat(c2, 'x') as c2 from t1 order by c1 asc", []int{0})
+	assert.False(t, ok)
+	_, ok = newChunkQueries("select c1, count(*) as c2 from t1 group by c1 order by c1 asc", []int{0})
+	assert.False(t, ok)
+
+	cq, ok := newChunkQueries("select c1, c2, c3 from t1 order by c1 asc, c2 asc", []int{0, 1})
+	require.True(t, ok)
+	lower := []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("a")}
+	upper := []sqltypes.Value{sqltypes.NewInt64(3), sqltypes.NewVarChar("c")}
+	assert.Equal(t, "select c1, c2 from t1 order by c1, c2 limit 999, 1", cq.boundaryQuery(nil, 1000))
+	assert.Equal(t, "select c1, c2 from t1 where ((c1 = 1 and c2 > 'a') or (c1 > 1)) order by c1, c2 limit 999, 1", cq.boundaryQuery(lower, 1000))
+	assert.Equal(t, "select count(*) from t1 where ((c1 = 3 and c2 <= 'c') or (c1 < 3))", cq.checksumQuery(nil, upper, false))
+	assert.Equal(t, "select count(*), bit_xor(crc32(concat_ws('#', c1, c2, c3, concat(isnull(c1), isnull(c2), isnull(c3))))) from t1 "+
+		"where ((c1 = 1 and c2 > 'a') or (c1 > 1)) and ((c1 = 3 and c2 <= 'c') or (c1 < 3))", cq.checksumQuery(lower, upper, true))
+	assert.Equal(t, "select c1, c2, c3 from t1 where ((c1 = 1 and c2 > 'a') or (c1 > 1)) order by c1, c2", cq.rowsQuery(lower, nil))
+}
+
+// fakeChunkConn answers the chunk queries with the results of its queries.
+type fakeChunkConn struct {
+	queryservice.QueryService
+	results map[string]*sqltypes.Result
+	queries []string
+}
+
+func (conn *fakeChunkConn) Execute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]*querypb.BindVariable, transactionID, reservedID int64, options *querypb.ExecuteOptions) (*sqltypes.Result, error) {
+	conn.queries = append(conn.queries, sql)
+	qr, ok := conn.results[sql]
+	if !ok {
+		return nil, fmt.Errorf("unexpected query: %s", sql)
+	}
+	return qr, nil
+}
+
+func TestPushedDownChunks(t *testing.T) {
+	defer func(chunkSize int) { diffChunkSize = chunkSize }(diffChunkSize)
+	diffChunkSize = 2
+
+	pkResult := func(rows ...string) *sqltypes.Result {
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("c1", "int64"), rows...)
+	}
+	checksumResult := func(rows, checksum string) *sqltypes.Result {
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)|checksum", "int64|uint64"), rows+"|"+checksum)
+	}
+	rowsResult := func(rows ...string) *sqltypes.Result {
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("c1|c2", "int64|varchar"), rows...)
+	}
+	// The source has the rows 1|a, 2|b and 3|c, and the target has the rows 1|a, 2|x and 3|c: the first
+	// chunk, up to 2, mismatches and its rows are read, and the second chunk, after 2, matches.
+	source := &fakeChunkConn{results: map[string]*sqltypes.Result{
+		"select c1 from t1 order by c1 limit 1, 1":                                                                          pkResult("2"),
+		"select c1 from t1 where ((c1 > 2)) order by c1 limit 1, 1":                                                         pkResult(),
+		"select count(*), bit_xor(crc32(concat_ws('#', c1, c2, concat(isnull(c1), isnull(c2))))) from t1 where ((c1 <= 2))": checksumResult("2", "10"),
+		"select count(*), bit_xor(crc32(concat_ws('#', c1, c2, concat(isnull(c1), isnull(c2))))) from t1 where ((c1 > 2))":  checksumResult("1", "3"),
+		"select c1, c2 from t1 where ((c1 <= 2)) order by c1":                                                               rowsResult("1|a", "2|b"),
+	}}
+	target := &fakeChunkConn{results: map[string]*sqltypes.Result{
+		"select c1 from t1 order by c1 limit 1, 1":                                                                          pkResult("2"),
+		"select c1 from t1 where ((c1 > 2)) order by c1 limit 1, 1":                                                         pkResult(),
+		"select count(*), bit_xor(crc32(concat_ws('#', c1, c2, concat(isnull(c1), isnull(c2))))) from t1 where ((c1 <= 2))": checksumResult("2", "12"),
+		"select count(*), bit_xor(crc32(concat_ws('#', c1, c2, concat(isnull(c1), isnull(c2))))) from t1 where ((c1 > 2))":  checksumResult("1", "3"),
+		"select c1, c2 from t1 where ((c1 <= 2)) order by c1":                                                               rowsResult("1|a", "2|x"),
+	}}
+
+	td := newTestTableDiffer()
+	cq, ok := newChunkQueries(td.tablePlan.sourceQuery, td.tablePlan.pkCols)
+	require.True(t, ok)
+	tablet := &topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "cell1", Uid: 100}}
+	chunks := &pushedDownChunks{
+		td:      td,
+		sources: []*chunkTablet{{tablet: tablet, queries: cq, conn: source}},
+		target:  &chunkTablet{tablet: tablet, queries: cq, conn: target},
+	}
+	cd := newChunkDiffer(td, chunks, true, 100)
+
+	ctx := context.Background()
+	dr := &DiffReport{}
+	var lastRows [][]sqltypes.Value
+	for {
+		ch, err := chunks.nextChunk(ctx, int64(diffChunkSize))
+		require.NoError(t, err)
+		if ch == nil {
+			break
+		}
+		require.NoError(t, cd.diffChunk(ctx, dr, ch, false, true, 1000))
+		lastRows = append(lastRows, ch.lastRow())
+	}
+	assert.Equal(t, int64(3), dr.ProcessedRows)
+	assert.Equal(t, int64(2), dr.MatchingRows)
+	assert.Equal(t, int64(1), dr.MismatchedRows)
+	assert.Equal(t, [][]sqltypes.Value{{sqltypes.NewInt64(2), sqltypes.NULL}, nil}, lastRows)
+
+	// The rows of the matching chunk are not read.
+	assert.NotContains(t, source.queries, "select c1, c2 from t1 where ((c1 > 2)) order by c1")
+	assert.Len(t, source.queries, 5)
+	assert.Len(t, target.queries, 5)
+}
@@ -41,6 +41,8 @@ type DiffReport struct {
 	MismatchedRows  int64
 	ExtraRowsSource int64
 	ExtraRowsTarget int64
+	// SkippedRows are the processed rows which were not compared, in sampled mode.
+	SkippedRows int64 `json:"SkippedRows,omitempty"`
 
 	// actual data for a few sample rows
 	ExtraRowsSourceDiffs []*RowDiff      `json:"ExtraRowsSourceSample,omitempty"`
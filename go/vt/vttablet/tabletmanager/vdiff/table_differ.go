@@ -72,6 +72,9 @@ type tableDiffer struct {
 	sourceQuery string
 	table       *tabletmanagerdatapb.TableDefinition
 	lastPK      *querypb.QueryResult
+
+	// pushedDownChunks is set when the tablets are pinned to push the chunks down to them, see chunk_differ.go.
+	pushedDownChunks *pushedDownChunks
 }
 
 func newTableDiffer(wd *workflowDiffer, table *tabletmanagerdatapb.TableDefinition, sourceQuery string) *tableDiffer {
@@ -109,7 +112,12 @@ func (td *tableDiffer) initialize(ctx context.Context) error {
 	if err := td.stopTargetVReplicationStreams(ctx, dbClient); err != nil {
 		return err
 	}
+	pinned := false
 	defer func() {
+		if pinned {
+			// The target streams are restarted by unpinTablets.
+			return
+		}
 		// We use a new context as we want to reset the state even
 		// when the parent context has timed out or been canceled.
 		log.Infof("Restarting the %q VReplication workflow on target tablets in keyspace %q",
@@ -124,6 +132,13 @@ func (td *tableDiffer) initialize(ctx context.Context) error {
 	if err := td.selectTablets(ctx, td.wd.opts.PickerOptions.SourceCell, td.wd.opts.PickerOptions.TabletTypes); err != nil {
 		return err
 	}
+	if td.diffsChunks() {
+		if sourceQueries, targetQueries, ok := td.chunkQueries(); ok {
+			pinned = true
+			return td.pinTablets(ctx, sourceQueries, targetQueries)
+		}
+		log.Infof("Streaming the rows of table %s, as its chunks cannot be pushed down to the tablets", td.table.Name)
+	}
 	if err := td.syncSourceStreams(ctx); err != nil {
 		return err
 	}
@@ -433,6 +448,9 @@ func (td *tableDiffer) setupRowSorters() {
 }
 
 func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onlyPks bool, maxExtraRowsToCompare int64) (*DiffReport, error) {
+	if td.pushedDownChunks != nil {
+		defer td.unpinTablets()
+	}
 	dbClient := td.wd.ct.dbClientFactory()
 	if err := dbClient.Connect(); err != nil {
 		return nil, err
@@ -464,8 +482,17 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 	}
 	dr.TableName = td.table.Name
 
+	if td.pushedDownChunks != nil {
+		cd := newChunkDiffer(td, td.pushedDownChunks, td.wd.opts.CoreOptions.Checksum, td.wd.opts.CoreOptions.SamplePct)
+		return cd.diff(ctx, dbClient, dr, mismatch, rowsToCompare, debug, onlyPks, maxExtraRowsToCompare)
+	}
 	sourceExecutor := newPrimitiveExecutor(ctx, td.sourcePrimitive, "source")
 	targetExecutor := newPrimitiveExecutor(ctx, td.targetPrimitive, "target")
+	if td.diffsChunks() {
+		chunks := &streamedChunks{td: td, source: sourceExecutor, target: targetExecutor}
+		cd := newChunkDiffer(td, chunks, td.wd.opts.CoreOptions.Checksum, td.wd.opts.CoreOptions.SamplePct)
+		return cd.diff(ctx, dbClient, dr, mismatch, rowsToCompare, debug, onlyPks, maxExtraRowsToCompare)
+	}
 	var sourceRow, lastProcessedRow, targetRow []sqltypes.Value
 	advanceSource := true
 	advanceTarget := true
@@ -560,25 +587,15 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 		case err != nil:
 			return nil, err
 		case c < 0:
-			if dr.ExtraRowsSource < maxExtraRowsToCompare {
-				diffRow, err := td.genRowDiff(td.tablePlan.sourceQuery, sourceRow, debug, onlyPks)
-				if err != nil {
-					return nil, vterrors.Wrap(err, "unexpected error generating diff")
-				}
-				dr.ExtraRowsSourceDiffs = append(dr.ExtraRowsSourceDiffs, diffRow)
+			if err := td.addExtraSourceRow(dr, sourceRow, debug, onlyPks, maxExtraRowsToCompare); err != nil {
+				return nil, err
 			}
-			dr.ExtraRowsSource++
 			advanceTarget = false
 			continue
 		case c > 0:
-			if dr.ExtraRowsTarget < maxExtraRowsToCompare {
-				diffRow, err := td.genRowDiff(td.tablePlan.targetQuery, targetRow, debug, onlyPks)
-				if err != nil {
-					return nil, vterrors.Wrap(err, "unexpected error generating diff")
-				}
-				dr.ExtraRowsTargetDiffs = append(dr.ExtraRowsTargetDiffs, diffRow)
+			if err := td.addExtraTargetRow(dr, targetRow, debug, onlyPks, maxExtraRowsToCompare); err != nil {
+				return nil, err
 			}
-			dr.ExtraRowsTarget++
 			advanceSource = false
 			continue
 		}
@@ -590,19 +607,9 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 		case err != nil:
 			return nil, err
 		case c != 0:
-			// We don't do a second pass to compare mismatched rows so we can cap the slice here
-			if dr.MismatchedRows < maxVDiffReportSampleRows {
-				sourceDiffRow, err := td.genRowDiff(td.tablePlan.targetQuery, sourceRow, debug, onlyPks)
-				if err != nil {
-					return nil, vterrors.Wrap(err, "unexpected error generating diff")
-				}
-				targetDiffRow, err := td.genRowDiff(td.tablePlan.targetQuery, targetRow, debug, onlyPks)
-				if err != nil {
-					return nil, vterrors.Wrap(err, "unexpected error generating diff")
-				}
-				dr.MismatchedRowsDiffs = append(dr.MismatchedRowsDiffs, &DiffMismatch{Source: sourceDiffRow, Target: targetDiffRow})
+			if err := td.addMismatchedRow(dr, sourceRow, targetRow, debug, onlyPks); err != nil {
+				return nil, err
 			}
-			dr.MismatchedRows++
 		default:
 			dr.MatchingRows++
 		}
@@ -618,6 +625,50 @@ func (td *tableDiffer) diff(ctx context.Context, rowsToCompare int64, debug, onl
 	}
 }
 
+// addExtraSourceRow accounts for a source row which is missing on the target.
+func (td *tableDiffer) addExtraSourceRow(dr *DiffReport, sourceRow []sqltypes.Value, debug, onlyPks bool, maxExtraRowsToCompare int64) error {
+	if dr.ExtraRowsSource < maxExtraRowsToCompare {
+		diffRow, err := td.genRowDiff(td.tablePlan.sourceQuery, sourceRow, debug, onlyPks)
+		if err != nil {
+			return vterrors.Wrap(err, "unexpected error generating diff")
+		}
+		dr.ExtraRowsSourceDiffs = append(dr.ExtraRowsSourceDiffs, diffRow)
+	}
+	dr.ExtraRowsSource++
+	return nil
+}
+
+// addExtraTargetRow accounts for a target row which is missing on the source.
+func (td *tableDiffer) addExtraTargetRow(dr *DiffReport, targetRow []sqltypes.Value, debug, onlyPks bool, maxExtraRowsToCompare int64) error {
+	if dr.ExtraRowsTarget < maxExtraRowsToCompare {
+		diffRow, err := td.genRowDiff(td.tablePlan.targetQuery, targetRow, debug, onlyPks)
+		if err != nil {
+			return vterrors.Wrap(err, "unexpected error generating diff")
+		}
+		dr.ExtraRowsTargetDiffs = append(dr.ExtraRowsTargetDiffs, diffRow)
+	}
+	dr.ExtraRowsTarget++
+	return nil
+}
+
+// addMismatchedRow accounts for a row whose PK is on both sides, with different values.
+func (td *tableDiffer) addMismatchedRow(dr *DiffReport, sourceRow, targetRow []sqltypes.Value, debug, onlyPks bool) error {
+	// We don't do a second pass to compare mismatched rows so we can cap the slice here
+	if dr.MismatchedRows < maxVDiffReportSampleRows {
+		sourceDiffRow, err := td.genRowDiff(td.tablePlan.targetQuery, sourceRow, debug, onlyPks)
+		if err != nil {
+			return vterrors.Wrap(err, "unexpected error generating diff")
+		}
+		targetDiffRow, err := td.genRowDiff(td.tablePlan.targetQuery, targetRow, debug, onlyPks)
+		if err != nil {
+			return vterrors.Wrap(err, "unexpected error generating diff")
+		}
+		dr.MismatchedRowsDiffs = append(dr.MismatchedRowsDiffs, &DiffMismatch{Source: sourceDiffRow, Target: targetDiffRow})
+	}
+	dr.MismatchedRows++
+	return nil
+}
+
 func (td *tableDiffer) compare(sourceRow, targetRow []sqltypes.Value, cols []compareColInfo, compareOnlyNonPKs bool) (int, error) {
 	for _, col := range cols {
 		if col.isPK && compareOnlyNonPKs {
@@ -239,7 +239,16 @@ func (vr *vreplicator) replicate(ctx context.Context) error {
 				return err
 			}
 		default:
-			if err := vr.resetFKCheckAfterCopy(vr.dbClient); err != nil {
+			if vr.WorkflowType == int32(binlogdatapb.VReplicationWorkflowType_OnlineDDL) {
+				// An Online DDL target table mirrors a source table whose FOREIGN KEY constraints are already enforced.
+				// The constraints are attached to the target table ahead of the cut-over, and checking them again would
+				// fail on rows whose parent rows were deleted since, so we keep the checks off. This relies on the
+				// executor refusing source tables with cascading FOREIGN KEY actions, whose changes are not binlogged.
+				if err := vr.clearFKCheck(vr.dbClient); err != nil {
+					log.Warningf("Unable to clear FK check %v", err)
+					return err
+				}
+			} else if err := vr.resetFKCheckAfterCopy(vr.dbClient); err != nil {
 				log.Warningf("Unable to reset FK check %v", err)
 				return err
 			}
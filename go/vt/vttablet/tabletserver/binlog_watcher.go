@@ -36,22 +36,24 @@ type VStreamer interface {
 
 // BinlogWatcher is a tabletserver service that watches the
 // replication stream.  It will trigger schema reloads if a DDL
-// is encountered.
+// is encountered, and invalidate the query result cache.
 type BinlogWatcher struct {
 	env              tabletenv.Env
 	watchReplication bool
 	vs               VStreamer
+	resultCache      *ResultCache
 
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
 
 // NewBinlogWatcher creates a new BinlogWatcher.
-func NewBinlogWatcher(env tabletenv.Env, vs VStreamer, config *tabletenv.TabletConfig) *BinlogWatcher {
+func NewBinlogWatcher(env tabletenv.Env, vs VStreamer, config *tabletenv.TabletConfig, resultCache *ResultCache) *BinlogWatcher {
 	return &BinlogWatcher{
 		env:              env,
 		vs:               vs,
-		watchReplication: config.WatchReplication || config.TrackSchemaVersions,
+		resultCache:      resultCache,
+		watchReplication: config.WatchReplication || config.TrackSchemaVersions || resultCache.Enabled(),
 	}
 }
 
@@ -92,8 +94,15 @@ func (blw *BinlogWatcher) process(ctx context.Context) {
 	for {
 		// VStreamer will reload the schema when it encounters a DDL.
 		err := blw.vs.Stream(ctx, "current", nil, filter, func(events []*binlogdatapb.VEvent) error {
+			if blw.resultCache.Enabled() {
+				blw.resultCache.processEvents(events)
+			}
 			return nil
 		})
+		// Cached results can't be invalidated while we're not streaming.
+		if blw.resultCache.Enabled() {
+			blw.resultCache.setServing(false)
+		}
 		log.Infof("ReplicationWatcher VStream ended: %v, retrying in 5 seconds", err)
 		select {
 		case <-ctx.Done():
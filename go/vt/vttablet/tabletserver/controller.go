@@ -23,14 +23,17 @@ import (
 	"vitess.io/vitess/go/vt/mysqlctl"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/gc"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 	"vitess.io/vitess/go/vt/vttablet/vexec"
 
 	"time"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
@@ -88,6 +91,20 @@ type Controller interface {
 	// SchemaEngine returns the SchemaEngine object used by this Controller
 	SchemaEngine() *schema.Engine
 
+	// TableGC returns the table garbage collector used by this Controller
+	TableGC() *gc.TableGC
+
+	// LagThrottler returns the lag throttler used by this Controller
+	LagThrottler() *throttle.Throttler
+
+	// UnresolvedTransactions returns the unresolved distributed transactions
+	// for which this tablet is the metadata manager.
+	UnresolvedTransactions(ctx context.Context, abandonAge time.Duration) ([]*querypb.TransactionMetadata, error)
+
+	// EstimateSchemaMigration estimates the impact of running the given DDL
+	// statement as an Online DDL migration, without running it.
+	EstimateSchemaMigration(ctx context.Context, sql string, ddlStrategy string) (*tabletmanagerdatapb.SchemaMigrationEstimate, error)
+
 	// BroadcastHealth sends the current health to all listeners
 	BroadcastHealth()
 
@@ -0,0 +1,597 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package driftcheck detects data drift between the primary and the replicas of a shard.
+
+The drift checker runs on the primary. It walks the tables in chunks of consecutive PKs, and for each chunk:
+  - it computes the row count and checksum of the chunk on the primary, and then reads the GTID position of the
+    primary;
+  - it waits for each replica to reach that position, and computes the row count and checksum of the same chunk
+    on the replica;
+  - when a replica differs, it computes the checksum of the primary again: if it changed, the chunk was written
+    to while it was checked, and it is checked again. Otherwise the rows of the chunk did not change on the
+    primary between its checksum and the checks of the replicas, which thus had the same rows once they reached
+    the position;
+  - it records the replicas whose row count or checksum differ in _vt.drift_check_mismatches, after checking
+    them again to rule out writes racing with the position.
+
+No lock is taken on the primary, and the replicas are waited for up to --drift_check_replica_timeout.
+The checker uses the tablet throttler to not add to the replication lag.
+*/
+package driftcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+const (
+	throttlerAppName = "driftcheck"
+	// recheckAttempts is how many times the replicas whose chunk mismatches are checked again, before the
+	// mismatch is recorded.
+	recheckAttempts = 2
+)
+
+// errChunkChanged is returned when the checksum of a chunk changed on the primary while the replicas were checked.
+var errChunkChanged = errors.New("the chunk changed on the primary while it was checked")
+
+var (
+	enabled        = false
+	checkInterval  = 24 * time.Hour
+	chunkSize      = 1000
+	replicaTimeout = 5 * time.Second
+)
+
+var (
+	chunksChecked   = stats.NewCountersWithSingleLabel("DriftCheckChunks", "Chunks compared between the primary and the replicas, by table", "Table")
+	mismatchesFound = stats.NewCountersWithSingleLabel("DriftCheckMismatches", "Chunks which differ between the primary and a replica, by table", "Table")
+	checkErrors     = stats.NewCounter("DriftCheckErrors", "Errors while comparing the data of the primary and the replicas")
+	passesCompleted = stats.NewCounter("DriftCheckPasses", "Completed passes over all the tables")
+)
+
+func init() {
+	servenv.OnParseFor("vtcombo", registerDriftCheckFlags)
+	servenv.OnParseFor("vttablet", registerDriftCheckFlags)
+}
+
+func registerDriftCheckFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&enabled, "enable_drift_check", enabled, "Continuously compare the data of the replicas with the data of the primary, when the tablet is primary")
+	fs.DurationVar(&checkInterval, "drift_check_interval", checkInterval, "Interval between the starts of the passes of the drift checker over all the tables")
+	fs.IntVar(&chunkSize, "drift_check_chunk_size", chunkSize, "Number of rows compared at a time by the drift checker")
+	fs.DurationVar(&replicaTimeout, "drift_check_replica_timeout", replicaTimeout, "How long the drift checker waits for a replica to reach the position of a chunk and compute its checksum")
+}
+
+// tabletManagerClient is the part of tmclient.TabletManagerClient used to compute the checksums on the replicas.
+type tabletManagerClient interface {
+	WaitForPosition(ctx context.Context, tablet *topodatapb.Tablet, pos string) error
+	ExecuteFetchAsDba(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, req *tabletmanagerdatapb.ExecuteFetchAsDbaRequest) (*querypb.QueryResult, error)
+	Close()
+}
+
+// Checker is the drift checker of a shard. It is open on the primary only.
+type Checker struct {
+	keyspace string
+	shard    string
+	dbName   string
+
+	isOpen          int64
+	cancelOperation context.CancelFunc
+	stateMutex      sync.Mutex
+
+	throttlerClient *throttle.Client
+
+	env tabletenv.Env
+	ts  *topo.Server
+	tmc tabletManagerClient
+
+	dbClientFactory func() binlogplayer.DBClient
+	// replicas returns the tablets to compare with the primary.
+	replicas func(ctx context.Context) ([]*topodatapb.Tablet, error)
+}
+
+// tableInfo describes a table to check.
+type tableInfo struct {
+	name      string
+	columns   []string
+	pkColumns []string
+}
+
+// chunkChecksum is the row count and checksum of a chunk.
+type chunkChecksum struct {
+	rowCount string
+	checksum string
+}
+
+// NewChecker creates a drift checker.
+func NewChecker(env tabletenv.Env, ts *topo.Server, lagThrottler *throttle.Throttler) *Checker {
+	c := &Checker{
+		throttlerClient: throttle.NewBackgroundClient(lagThrottler, throttlerAppName, throttle.ThrottleCheckPrimaryWrite),
+		env:             env,
+		ts:              ts,
+	}
+	c.dbClientFactory = func() binlogplayer.DBClient {
+		return binlogplayer.NewDBClient(c.env.Config().DB.DbaWithDB())
+	}
+	c.replicas = c.findReplicas
+	return c
+}
+
+// InitDBConfig initializes keyspace and shard
+func (c *Checker) InitDBConfig(keyspace, shard, dbName string) {
+	c.keyspace = keyspace
+	c.shard = shard
+	c.dbName = dbName
+}
+
+// Open starts checking the replicas, if the drift checker is enabled.
+func (c *Checker) Open() error {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	if c.isOpen > 0 {
+		// already open
+		return nil
+	}
+	if !enabled {
+		return nil
+	}
+
+	log.Info("DriftCheck: opening")
+	c.tmc = tmclient.NewTabletManagerClient()
+	atomic.StoreInt64(&c.isOpen, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancelOperation = cancel
+	go c.operate(ctx)
+	return nil
+}
+
+// Close stops checking the replicas.
+func (c *Checker) Close() {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+	if c.isOpen == 0 {
+		// not open
+		return
+	}
+
+	log.Info("DriftCheck: closing")
+	if c.cancelOperation != nil {
+		c.cancelOperation()
+	}
+	c.tmc.Close()
+	atomic.StoreInt64(&c.isOpen, 0)
+}
+
+// operate checks all the tables every checkInterval, until the context is canceled.
+func (c *Checker) operate(ctx context.Context) {
+	ticker := timer.NewSuspendableTicker(checkInterval, false)
+	defer ticker.Stop()
+	// Start the first pass now, rather than after a full interval.
+	go ticker.TickNow()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("DriftCheck: done operating")
+			return
+		case <-ticker.C:
+			if err := c.checkAll(ctx); err != nil {
+				checkErrors.Add(1)
+				log.Errorf("DriftCheck: pass failed: %v", err)
+			}
+		}
+	}
+}
+
+// findReplicas returns the replica and rdonly tablets of the shard.
+func (c *Checker) findReplicas(ctx context.Context) ([]*topodatapb.Tablet, error) {
+	tablets, err := c.ts.GetTabletMapForShard(ctx, c.keyspace, c.shard)
+	if err != nil {
+		return nil, err
+	}
+	var replicas []*topodatapb.Tablet
+	for _, ti := range tablets {
+		switch ti.Type {
+		case topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY:
+			replicas = append(replicas, ti.Tablet)
+		}
+	}
+	return replicas, nil
+}
+
+// checkAll compares all the tables with the replicas.
+func (c *Checker) checkAll(ctx context.Context) error {
+	replicas, err := c.replicas(ctx)
+	if err != nil {
+		return err
+	}
+	if len(replicas) == 0 {
+		log.Info("DriftCheck: no replicas to check")
+		return nil
+	}
+
+	dbClient := c.dbClientFactory()
+	if err := dbClient.Connect(); err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	for _, ddl := range applyDDL {
+		if _, err := dbClient.ExecuteFetch(ddl, 1); err != nil && !mysql.IsSchemaApplyError(err) {
+			return err
+		}
+	}
+	tables, err := listTables(dbClient)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("DriftCheck: checking %d tables on %d replicas", len(tables), len(replicas))
+	for _, table := range tables {
+		if err := c.checkTable(ctx, dbClient, table, replicas); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			// Move on to the other tables, the table may have been dropped or altered.
+			checkErrors.Add(1)
+			log.Errorf("DriftCheck: cannot check table %s: %v", table.name, err)
+		}
+	}
+	passesCompleted.Add(1)
+	return nil
+}
+
+// listTables returns the tables with a primary key, except for the internal tables.
+func listTables(dbClient binlogplayer.DBClient) ([]*tableInfo, error) {
+	qr, err := dbClient.ExecuteFetch(sqlListTableColumns, -1)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*tableInfo
+	tablesByName := make(map[string]*tableInfo)
+	for _, row := range qr.Rows {
+		name := row[0].ToString()
+		table, ok := tablesByName[name]
+		if !ok {
+			table = &tableInfo{name: name}
+			tablesByName[name] = table
+			tables = append(tables, table)
+		}
+		table.columns = append(table.columns, row[1].ToString())
+	}
+
+	qr, err = dbClient.ExecuteFetch(sqlListPrimaryKeyColumns, -1)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range qr.Rows {
+		if table, ok := tablesByName[row[0].ToString()]; ok {
+			table.pkColumns = append(table.pkColumns, row[1].ToString())
+		}
+	}
+
+	result := tables[:0]
+	for _, table := range tables {
+		if len(table.pkColumns) == 0 || schema.IsInternalOperationTableName(table.name) {
+			continue
+		}
+		result = append(result, table)
+	}
+	return result, nil
+}
+
+// checkTable compares a table with the replicas, chunk by chunk.
+func (c *Checker) checkTable(ctx context.Context, dbClient binlogplayer.DBClient, table *tableInfo, replicas []*topodatapb.Tablet) error {
+	size := chunkSize
+	if size <= 0 {
+		size = 1
+	}
+	var lower []sqltypes.Value
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !c.throttlerClient.ThrottleCheckOKOrWait(ctx) {
+			continue
+		}
+
+		// The upper bound of the chunk is the PK of its last row. The last chunk has no upper bound, so that it
+		// includes the rows inserted after the bound was read.
+		qr, err := dbClient.ExecuteFetch(chunkUpperBoundQuery(table, lower, size), 1)
+		if err != nil {
+			return err
+		}
+		var upper []sqltypes.Value
+		if len(qr.Rows) > 0 {
+			upper = qr.Rows[0]
+		}
+
+		if err := c.checkChunk(ctx, dbClient, table, lower, upper, replicas); err != nil {
+			return err
+		}
+		if upper == nil {
+			return nil
+		}
+		lower = upper
+	}
+}
+
+// checkChunk compares a chunk with the replicas, and records the replicas which still mismatch after being
+// checked again.
+func (c *Checker) checkChunk(ctx context.Context, dbClient binlogplayer.DBClient, table *tableInfo, lower, upper []sqltypes.Value, replicas []*topodatapb.Tablet) error {
+	query := chunkChecksumQuery(table, lower, upper)
+	chunksChecked.Add(table.name, 1)
+	for attempt := 0; ; attempt++ {
+		position, primary, mismatches, err := c.compareChunk(ctx, dbClient, query, replicas)
+		if err == errChunkChanged {
+			if attempt < recheckAttempts {
+				continue
+			}
+			// The chunk is written to too often to be checked now: it is checked again by the next pass.
+			log.Infof("DriftCheck: table %s changed between %s and %s while it was checked, skipping", table.name, formatBound(lower), formatBound(upper))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(mismatches) == 0 {
+			return nil
+		}
+		if attempt < recheckAttempts {
+			// A write to the chunk may have been visible on the primary before its GTID was part of the
+			// position read by the primary, in which case the replica was checked before it got the write.
+			replicas = make([]*topodatapb.Tablet, 0, len(mismatches))
+			for tablet := range mismatches {
+				replicas = append(replicas, tablet)
+			}
+			continue
+		}
+
+		for tablet, replica := range mismatches {
+			mismatchesFound.Add(table.name, 1)
+			log.Warningf("DriftCheck: table %s differs on replica %s between %s and %s: %s rows with checksum %s on the primary, %s rows with checksum %s on the replica",
+				table.name, topoproto.TabletAliasString(tablet.Alias), formatBound(lower), formatBound(upper), primary.rowCount, primary.checksum, replica.rowCount, replica.checksum)
+			query, err := sqlparser.ParseAndBind(sqlInsertMismatch,
+				sqltypes.StringBindVariable(table.name),
+				sqltypes.StringBindVariable(formatBound(lower)),
+				sqltypes.StringBindVariable(formatBound(upper)),
+				sqltypes.StringBindVariable(topoproto.TabletAliasString(tablet.Alias)),
+				sqltypes.StringBindVariable(position),
+				sqltypes.StringBindVariable(primary.rowCount),
+				sqltypes.StringBindVariable(primary.checksum),
+				sqltypes.StringBindVariable(replica.rowCount),
+				sqltypes.StringBindVariable(replica.checksum),
+			)
+			if err != nil {
+				return err
+			}
+			if _, err := dbClient.ExecuteFetch(query, 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// compareChunk computes the checksum of a chunk on the primary, reads the position of the primary, and computes
+// the checksums of the replicas once they reach the position. It returns the position, the checksum of the
+// primary and the checksums of the replicas which differ, or errChunkChanged if the checksum of the primary
+// changed by the time the replicas were checked.
+func (c *Checker) compareChunk(ctx context.Context, dbClient binlogplayer.DBClient, query string, replicas []*topodatapb.Tablet) (string, chunkChecksum, map[*topodatapb.Tablet]chunkChecksum, error) {
+	primary, err := primaryChecksum(dbClient, query)
+	if err != nil {
+		return "", primary, nil, err
+	}
+	qr, err := dbClient.ExecuteFetch(sqlGetGTIDExecuted, 1)
+	if err != nil {
+		return "", primary, nil, err
+	}
+	pos, err := mysql.ParsePosition(mysql.Mysql56FlavorID, qr.Rows[0][0].ToString())
+	if err != nil {
+		return "", primary, nil, err
+	}
+	position := mysql.EncodePosition(pos)
+
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		mismatches = make(map[*topodatapb.Tablet]chunkChecksum)
+	)
+	for _, tablet := range replicas {
+		wg.Add(1)
+		go func(tablet *topodatapb.Tablet) {
+			defer wg.Done()
+			replica, err := c.replicaChecksum(ctx, tablet, position, query)
+			if err != nil {
+				// The replica may be lagging, or unreachable: it is checked again by the next pass.
+				checkErrors.Add(1)
+				log.Warningf("DriftCheck: cannot check replica %s: %v", topoproto.TabletAliasString(tablet.Alias), err)
+				return
+			}
+			if replica != primary {
+				mu.Lock()
+				defer mu.Unlock()
+				mismatches[tablet] = replica
+			}
+		}(tablet)
+	}
+	wg.Wait()
+
+	// No lock is held on the primary while the replicas are checked, so the checksum of the primary is
+	// verified again: if it did not change, the rows of the chunk were the same on the primary from before
+	// the position until after the replicas were checked.
+	if len(mismatches) > 0 {
+		again, err := primaryChecksum(dbClient, query)
+		if err != nil {
+			return "", primary, nil, err
+		}
+		if again != primary {
+			return "", primary, nil, errChunkChanged
+		}
+	}
+	return position, primary, mismatches, nil
+}
+
+// primaryChecksum computes the checksum of a chunk on the primary.
+func primaryChecksum(dbClient binlogplayer.DBClient, query string) (chunkChecksum, error) {
+	qr, err := dbClient.ExecuteFetch(query, 1)
+	if err != nil {
+		return chunkChecksum{}, err
+	}
+	if len(qr.Rows) != 1 {
+		return chunkChecksum{}, fmt.Errorf("unexpected checksum result: %v", qr.Rows)
+	}
+	return checksumFromRow(qr.Rows[0]), nil
+}
+
+// replicaChecksum computes the checksum of a chunk on a replica, once it reaches the position.
+func (c *Checker) replicaChecksum(ctx context.Context, tablet *topodatapb.Tablet, position, query string) (chunkChecksum, error) {
+	ctx, cancel := context.WithTimeout(ctx, replicaTimeout)
+	defer cancel()
+	if err := c.tmc.WaitForPosition(ctx, tablet, position); err != nil {
+		return chunkChecksum{}, err
+	}
+	qr, err := c.tmc.ExecuteFetchAsDba(ctx, tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+		Query:   []byte(query),
+		DbName:  c.dbName,
+		MaxRows: 1,
+	})
+	if err != nil {
+		return chunkChecksum{}, err
+	}
+	result := sqltypes.Proto3ToResult(qr)
+	if len(result.Rows) != 1 {
+		return chunkChecksum{}, fmt.Errorf("unexpected checksum result: %v", result.Rows)
+	}
+	return checksumFromRow(result.Rows[0]), nil
+}
+
+func checksumFromRow(row []sqltypes.Value) chunkChecksum {
+	return chunkChecksum{rowCount: row[0].ToString(), checksum: row[1].ToString()}
+}
+
+// chunkUpperBoundQuery returns the query reading the PK of the last row of the chunk starting after lower.
+func chunkUpperBoundQuery(table *tableInfo, lower []sqltypes.Value, size int) string {
+	pkColumns := columnList(table.pkColumns)
+	return fmt.Sprintf(sqlChunkUpperBound, pkColumns, sqlparser.String(sqlparser.NewIdentifierCS(table.name)), rangeCondition(table, lower, nil), pkColumns, size-1)
+}
+
+// chunkChecksumQuery returns the query computing the row count and checksum of the chunk after lower, up to
+// and including upper.
+func chunkChecksumQuery(table *tableInfo, lower, upper []sqltypes.Value) string {
+	isNulls := make([]string, 0, len(table.columns))
+	for _, column := range table.columns {
+		isNulls = append(isNulls, "isnull("+sqlparser.String(sqlparser.NewIdentifierCI(column))+")")
+	}
+	return fmt.Sprintf(sqlChunkChecksum, columnList(table.columns), strings.Join(isNulls, ", "), sqlparser.String(sqlparser.NewIdentifierCS(table.name)), rangeCondition(table, lower, upper))
+}
+
+func columnList(columns []string) string {
+	escaped := make([]string, 0, len(columns))
+	for _, column := range columns {
+		escaped = append(escaped, sqlparser.String(sqlparser.NewIdentifierCI(column)))
+	}
+	return strings.Join(escaped, ", ")
+}
+
+// rangeCondition returns the condition on the PK of the rows after lower, up to and including upper. A nil
+// bound is not part of the condition.
+func rangeCondition(table *tableInfo, lower, upper []sqltypes.Value) string {
+	var conditions []string
+	if lower != nil {
+		conditions = append(conditions, boundCondition(table.pkColumns, lower, ">", ">"))
+	}
+	if upper != nil {
+		conditions = append(conditions, boundCondition(table.pkColumns, upper, "<", "<="))
+	}
+	if len(conditions) == 0 {
+		return "1 = 1"
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// boundCondition returns the comparison of the PK columns with a bound, the last column being compared with
+// lastOp and the previous ones with op. Like in the row streamer, the comparison of a composite PK is expanded:
+// for instance, (a, b) > (1, 2) is written as ((a = 1 and b > 2) or (a > 1)), since a tuple comparison ends up
+// being a full table scan for mysql.
+func boundCondition(pkColumns []string, bound []sqltypes.Value, op, lastOp string) string {
+	var sb strings.Builder
+	sb.WriteString("(")
+	for lastcol := len(pkColumns) - 1; lastcol >= 0; lastcol-- {
+		if lastcol < len(pkColumns)-1 {
+			sb.WriteString(" or ")
+		}
+		sb.WriteString("(")
+		for i, column := range pkColumns[:lastcol] {
+			sb.WriteString(sqlparser.String(sqlparser.NewIdentifierCI(column)) + " = ")
+			bound[i].EncodeSQL(&sb)
+			sb.WriteString(" and ")
+		}
+		columnOp := op
+		if lastcol == len(pkColumns)-1 {
+			columnOp = lastOp
+		}
+		sb.WriteString(sqlparser.String(sqlparser.NewIdentifierCI(pkColumns[lastcol])) + " " + columnOp + " ")
+		bound[lastcol].EncodeSQL(&sb)
+		sb.WriteString(")")
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// formatBound returns a bound as an SQL tuple, or an empty string for no bound.
+func formatBound(bound []sqltypes.Value) string {
+	if bound == nil {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("(")
+	for i, value := range bound {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		value.EncodeSQL(&sb)
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driftcheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// fakeTMClient returns the checksums of its checksum function.
+type fakeTMClient struct {
+	mu        sync.Mutex
+	positions []string
+	checksum  func(alias, query string) (string, string)
+}
+
+func (tmc *fakeTMClient) WaitForPosition(ctx context.Context, tablet *topodatapb.Tablet, pos string) error {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+	tmc.positions = append(tmc.positions, pos)
+	return nil
+}
+
+func (tmc *fakeTMClient) ExecuteFetchAsDba(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, req *tabletmanagerdatapb.ExecuteFetchAsDbaRequest) (*querypb.QueryResult, error) {
+	rowCount, checksum := tmc.checksum(topoproto.TabletAliasString(tablet.Alias), string(req.Query))
+	return sqltypes.ResultToProto3(checksumResult(rowCount, checksum)), nil
+}
+
+func (tmc *fakeTMClient) Close() {
+}
+
+func checksumResult(rowCount, checksum string) *sqltypes.Result {
+	return sqltypes.MakeTestResult(sqltypes.MakeTestFields("count|checksum", "int64|uint64"), rowCount+"|"+checksum)
+}
+
+func TestChunkQueries(t *testing.T) {
+	table := &tableInfo{name: "t1", columns: []string{"id", "name", "order"}, pkColumns: []string{"id"}}
+	assert.Equal(t, "select id from t1 where 1 = 1 order by id limit 1 offset 999", chunkUpperBoundQuery(table, nil, 1000))
+	assert.Equal(t, "select id from t1 where ((id > 10)) order by id limit 1 offset 99", chunkUpperBoundQuery(table, []sqltypes.Value{sqltypes.NewInt64(10)}, 100))
+	assert.Equal(t,
+		"select count(*), coalesce(bit_xor(cast(conv(substring(md5(concat_ws('#', id, `name`, `order`, concat(isnull(id), isnull(`name`), isnull(`order`)))), 1, 16), 16, 10) as unsigned)), 0) from t1 where ((id > 10)) and ((id <= 20))",
+		chunkChecksumQuery(table, []sqltypes.Value{sqltypes.NewInt64(10)}, []sqltypes.Value{sqltypes.NewInt64(20)}))
+
+	table = &tableInfo{name: "t2", columns: []string{"a", "b", "c"}, pkColumns: []string{"a", "b"}}
+	lower := []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("x'y")}
+	assert.Equal(t, "select a, b from t2 where ((a = 1 and b > 'x\\'y') or (a > 1)) order by a, b limit 1 offset 0", chunkUpperBoundQuery(table, lower, 1))
+	assert.Equal(t, "((a = 1 and b <= 'x\\'y') or (a < 1))", rangeCondition(table, nil, lower))
+	assert.Equal(t, "", formatBound(nil))
+}
+
+func TestListTables(t *testing.T) {
+	dbClient := binlogplayer.NewMockDbaClient(t)
+	dbClient.ExpectRequest(sqlListTableColumns, sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|column_name", "varchar|varchar"),
+		"t1|id", "t1|val", "nopk|val", "_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120410|id",
+	), nil)
+	dbClient.ExpectRequest(sqlListPrimaryKeyColumns, sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|column_name", "varchar|varchar"),
+		"t1|id", "_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120410|id",
+	), nil)
+
+	tables, err := listTables(dbClient)
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.Equal(t, &tableInfo{name: "t1", columns: []string{"id", "val"}, pkColumns: []string{"id"}}, tables[0])
+}
+
+func TestCheckTable(t *testing.T) {
+	defer func(size int) { chunkSize = size }(chunkSize)
+	chunkSize = 2
+
+	table := &tableInfo{name: "t1", columns: []string{"id", "val"}, pkColumns: []string{"id"}}
+	replicas := []*topodatapb.Tablet{
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}},
+		{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 102}},
+	}
+
+	// The first replica mismatches the first chunk once, as if it was checked before getting a write.
+	// The second replica always mismatches the second chunk.
+	var mu sync.Mutex
+	replicaChecks := make(map[string]int)
+	tmc := &fakeTMClient{checksum: func(alias, query string) (string, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := alias + query
+		replicaChecks[key]++
+		switch {
+		case alias == "zone1-0000000101" && strings.HasSuffix(query, "((id <= 2))") && replicaChecks[key] == 1:
+			return "2", "666"
+		case alias == "zone1-0000000102" && strings.HasSuffix(query, "((id > 2))"):
+			return "1", "777"
+		}
+		return "2", "123"
+	}}
+	c := NewChecker(nil, nil, nil)
+	c.tmc = tmc
+
+	dbClient := binlogplayer.NewMockDbaClient(t)
+	expectChunk := func(mismatch bool) {
+		dbClient.ExpectRequestRE("select count.*", checksumResult("2", "123"), nil)
+		dbClient.ExpectRequest(sqlGetGTIDExecuted, sqltypes.MakeTestResult(sqltypes.MakeTestFields("gtid", "varchar"), "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10"), nil)
+		if mismatch {
+			// The checksum of the primary is verified again.
+			dbClient.ExpectRequestRE("select count.*", checksumResult("2", "123"), nil)
+		}
+	}
+	dbClient.ExpectRequest("select id from t1 where 1 = 1 order by id limit 1 offset 1", sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "2"), nil)
+	expectChunk(true)
+	expectChunk(false)
+	dbClient.ExpectRequest("select id from t1 where ((id > 2)) order by id limit 1 offset 1", &sqltypes.Result{}, nil)
+	expectChunk(true)
+	expectChunk(true)
+	expectChunk(true)
+	dbClient.ExpectRequest("insert into _vt.drift_check_mismatches (table_name, lower_bound, upper_bound, tablet_alias, position, primary_row_count, primary_checksum, replica_row_count, replica_checksum) "+
+		"values ('t1', '(2)', '', 'zone1-0000000102', 'MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10', '2', '123', '1', '777')", &sqltypes.Result{}, nil)
+
+	require.NoError(t, c.checkTable(context.Background(), dbClient, table, replicas))
+	dbClient.Wait()
+
+	// The replicas waited for the position of the primary: both replicas for the first check of each chunk,
+	// and the mismatched replica for the checks again.
+	assert.Len(t, tmc.positions, 7)
+	for _, position := range tmc.positions {
+		assert.Equal(t, "MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10", position)
+	}
+}
+
+func TestCheckChunkChanged(t *testing.T) {
+	table := &tableInfo{name: "t1", columns: []string{"id", "val"}, pkColumns: []string{"id"}}
+	replicas := []*topodatapb.Tablet{{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}}}
+	tmc := &fakeTMClient{checksum: func(alias, query string) (string, string) {
+		return "2", "666"
+	}}
+	c := NewChecker(nil, nil, nil)
+	c.tmc = tmc
+
+	// The checksum of the primary changes every time it is computed, as if the chunk was written to while the
+	// replica was checked: the mismatch is not recorded.
+	dbClient := binlogplayer.NewMockDbaClient(t)
+	for attempt := 0; attempt <= recheckAttempts; attempt++ {
+		dbClient.ExpectRequestRE("select count.*", checksumResult("2", fmt.Sprint(2*attempt)), nil)
+		dbClient.ExpectRequest(sqlGetGTIDExecuted, sqltypes.MakeTestResult(sqltypes.MakeTestFields("gtid", "varchar"), "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-10"), nil)
+		dbClient.ExpectRequestRE("select count.*", checksumResult("2", fmt.Sprint(2*attempt+1)), nil)
+	}
+
+	require.NoError(t, c.checkChunk(context.Background(), dbClient, table, nil, nil, replicas))
+	dbClient.Wait()
+	assert.Len(t, tmc.positions, recheckAttempts+1)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driftcheck
+
+const (
+	sqlCreateSidecarDB                 = "create database if not exists _vt"
+	sqlCreateDriftCheckMismatchesTable = `CREATE TABLE IF NOT EXISTS _vt.drift_check_mismatches (
+		id bigint unsigned NOT NULL AUTO_INCREMENT,
+		table_name varbinary(128) NOT NULL,
+		lower_bound text NOT NULL,
+		upper_bound text NOT NULL,
+		tablet_alias varbinary(128) NOT NULL,
+		position text NOT NULL,
+		primary_row_count bigint unsigned NOT NULL,
+		primary_checksum varbinary(32) NOT NULL,
+		replica_row_count bigint unsigned NOT NULL,
+		replica_checksum varbinary(32) NOT NULL,
+		detected_timestamp timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (id),
+		KEY table_name_idx (table_name, id)
+	) ENGINE=InnoDB`
+
+	sqlListTableColumns = `select c.table_name, c.column_name
+		from information_schema.columns c join information_schema.tables t on t.table_schema = c.table_schema and t.table_name = c.table_name
+		where c.table_schema = database() and t.table_type = 'BASE TABLE'
+		order by c.table_name, c.ordinal_position`
+	sqlListPrimaryKeyColumns = `select table_name, column_name
+		from information_schema.key_column_usage
+		where table_schema = database() and constraint_name = 'PRIMARY'
+		order by table_name, ordinal_position`
+	sqlChunkUpperBound = `select %s from %s where %s order by %s limit 1 offset %d`
+	// sqlChunkChecksum hashes every row with the NULL flags of its columns, since concat_ws skips the NULLs,
+	// and xors the first 64 bits of the hashes.
+	sqlChunkChecksum   = `select count(*), coalesce(bit_xor(cast(conv(substring(md5(concat_ws('#', %s, concat(%s))), 1, 16), 16, 10) as unsigned)), 0) from %s where %s`
+	sqlGetGTIDExecuted = "select @@global.gtid_executed"
+	sqlInsertMismatch  = `insert into _vt.drift_check_mismatches (table_name, lower_bound, upper_bound, tablet_alias, position, primary_row_count, primary_checksum, replica_row_count, replica_checksum) values (%a, %a, %a, %a, %a, %a, %a, %a, %a)`
+)
+
+// applyDDL has the statements creating the tables of the drift checker.
+var applyDDL = []string{
+	sqlCreateSidecarDB,
+	sqlCreateDriftCheckMismatchesTable,
+}
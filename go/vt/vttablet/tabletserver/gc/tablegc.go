@@ -22,6 +22,7 @@ import (
 	"math"
 	"math/rand"
 	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -29,6 +30,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/vt/dbconnpool"
 	"vitess.io/vitess/go/vt/log"
@@ -51,6 +53,8 @@ var (
 	checkInterval           = 1 * time.Hour
 	purgeReentranceInterval = 1 * time.Minute
 	gcLifecycle             = "hold,purge,evac,drop"
+	purgeChunkSize          = 50
+	purgeRowsPerSecond      = 0
 )
 
 func init() {
@@ -65,10 +69,15 @@ func registerGCFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&purgeReentranceInterval, "gc_purge_check_interval", purgeReentranceInterval, "Interval between purge discovery checks")
 	// gcLifecycle is the sequence of steps the table goes through in the process of getting dropped
 	fs.StringVar(&gcLifecycle, "table_gc_lifecycle", gcLifecycle, "States for a DROP TABLE garbage collection cycle. Default is 'hold,purge,evac,drop', use any subset ('drop' implcitly always included)")
+	// purgeChunkSize is the number of rows deleted by each purge statement
+	fs.IntVar(&purgeChunkSize, "gc_purge_chunk_size", purgeChunkSize, "Number of rows deleted by each statement when purging a table")
+	// purgeRowsPerSecond limits the rate at which rows are purged, on top of the throttler
+	fs.IntVar(&purgeRowsPerSecond, "gc_purge_rate", purgeRowsPerSecond, "Maximum number of rows purged per second. 0 means no limit other than the throttler's")
 }
 
 var (
-	sqlPurgeTable       = `delete from %a limit 50`
+	sqlPurgeTable       = `delete from %a limit %a`
+	sqlTableRows        = `select table_rows from information_schema.tables where table_schema=database() and table_name=%a`
 	sqlShowVtTables     = `show full tables like '\_vt\_%'`
 	sqlDropTable        = "drop table if exists `%a`"
 	purgeReentranceFlag int64
@@ -112,6 +121,12 @@ type TableGC struct {
 	purgeMutex sync.Mutex
 
 	purgingTables map[string]bool
+	// purgeProgress tracks the progress of tables being purged
+	purgeProgress map[string]*purgeProgress
+	// pausedTables are neither purged nor transitioned until resumed
+	pausedTables map[string]bool
+	// gcTables are the GC tables found by the last table check, mapped to their state
+	gcTables map[string]schema.TableGCState
 	// lifecycleStates indicates what states a GC table goes through. The user can set
 	// this with --table_gc_lifecycle, such that some states can be skipped.
 	lifecycleStates map[schema.TableGCState]bool
@@ -128,6 +143,28 @@ type Status struct {
 	purgingTables []string
 }
 
+// purgeProgress tracks the purge of a single table
+type purgeProgress struct {
+	startedAt     time.Time
+	estimatedRows int64
+	rowsPurged    int64
+}
+
+// TableStatus describes a GC table and the progress of its purge, if any
+type TableStatus struct {
+	TableName string
+	State     schema.TableGCState
+	IsPaused  bool
+	// IsPurging is true when the table is being purged, or waiting to be purged
+	IsPurging  bool
+	RowsPurged int64
+	// RowsRemaining is an estimate based on the table's statistics at the time the purge started
+	RowsRemaining  int64
+	PurgeStartedAt time.Time
+	// EstimatedCompletion is extrapolated from the purge rate so far. It is zero if unknown.
+	EstimatedCompletion time.Time
+}
+
 // NewTableGC creates a table collector
 func NewTableGC(env tabletenv.Env, ts *topo.Server, lagThrottler *throttle.Throttler) *TableGC {
 	collector := &TableGC{
@@ -142,6 +179,9 @@ func NewTableGC(env tabletenv.Env, ts *topo.Server, lagThrottler *throttle.Throt
 		}),
 
 		purgingTables: map[string]bool{},
+		purgeProgress: map[string]*purgeProgress{},
+		pausedTables:  map[string]bool{},
+		gcTables:      map[string]schema.TableGCState{},
 	}
 
 	return collector
@@ -395,11 +435,22 @@ func (collector *TableGC) checkTables(ctx context.Context, dropTablesChan chan<-
 		return err
 	}
 
+	gcTables := map[string]schema.TableGCState{}
+	defer func() {
+		collector.purgeMutex.Lock()
+		defer collector.purgeMutex.Unlock()
+		collector.gcTables = gcTables
+	}()
+
 	for _, row := range res.Rows {
 		tableName := row[0].ToString()
 		tableType := row[1].ToString()
 		isBaseTable := (tableType == "BASE TABLE")
 
+		if isGCTable, state, _, _, _ := schema.AnalyzeGCTableName(tableName); isGCTable {
+			gcTables[tableName] = state
+		}
+
 		shouldTransition, state, uuid, err := collector.shouldTransitionTable(tableName)
 
 		if err != nil {
@@ -410,6 +461,10 @@ func (collector *TableGC) checkTables(ctx context.Context, dropTablesChan chan<-
 			// irrelevant table
 			continue
 		}
+		if collector.isPaused(tableName) {
+			log.Infof("TableGC: table %s is paused", tableName)
+			continue
+		}
 
 		log.Infof("TableGC: will operate on table %s", tableName)
 
@@ -502,19 +557,33 @@ func (collector *TableGC) purge(ctx context.Context) (tableName string, err erro
 		}
 	}()
 
+	if err := collector.initPurgeProgress(conn, tableName); err != nil {
+		return tableName, err
+	}
+
 	log.Infof("TableGC: purge begin for %s", tableName)
+	chunkSize := purgeChunkSize
+	if chunkSize <= 0 {
+		// "limit 0" would never delete a row, and the table would seem purged
+		chunkSize = 1
+	}
+	parsed := sqlparser.BuildParsedQuery(sqlPurgeTable, tableName, strconv.Itoa(chunkSize))
 	for {
 		if ctx.Err() != nil {
 			// cancelled
 			return tableName, err
 		}
+		if !collector.shouldPurge(tableName) {
+			// The table was paused or dropped on demand
+			log.Infof("TableGC: purge interrupted for %s", tableName)
+			return "", nil
+		}
 		if !collector.throttlerClient.ThrottleCheckOKOrWait(ctx) {
 			continue
 		}
 		// OK, we're clear to go!
 
 		// Issue a DELETE
-		parsed := sqlparser.BuildParsedQuery(sqlPurgeTable, tableName)
 		res, err := conn.ExecuteFetch(parsed.Query, 1, true)
 		if err != nil {
 			return tableName, err
@@ -523,9 +592,64 @@ func (collector *TableGC) purge(ctx context.Context) (tableName string, err erro
 			log.Infof("TableGC: purge complete for %s", tableName)
 			return tableName, nil
 		}
+		collector.addPurgedRows(tableName, int64(res.RowsAffected))
+		if purgeRowsPerSecond > 0 {
+			// Pace ourselves to not exceed the configured rate
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(res.RowsAffected) * time.Second / time.Duration(purgeRowsPerSecond)):
+			}
+		}
 	}
 }
 
+// initPurgeProgress starts tracking the purge progress of a table, unless already tracked by
+// a previous purge attempt. The number of rows is estimated from the table's statistics.
+func (collector *TableGC) initPurgeProgress(conn *dbconnpool.DBConnection, tableName string) error {
+	collector.purgeMutex.Lock()
+	_, ok := collector.purgeProgress[tableName]
+	collector.purgeMutex.Unlock()
+	if ok {
+		return nil
+	}
+
+	query, err := sqlparser.ParseAndBind(sqlTableRows, sqltypes.StringBindVariable(tableName))
+	if err != nil {
+		return err
+	}
+	res, err := conn.ExecuteFetch(query, 1, true)
+	if err != nil {
+		return err
+	}
+	progress := &purgeProgress{startedAt: time.Now()}
+	if len(res.Rows) > 0 {
+		progress.estimatedRows, _ = res.Rows[0][0].ToInt64()
+	}
+
+	collector.purgeMutex.Lock()
+	defer collector.purgeMutex.Unlock()
+	collector.purgeProgress[tableName] = progress
+	return nil
+}
+
+// addPurgedRows updates the purge progress of a table
+func (collector *TableGC) addPurgedRows(tableName string, rows int64) {
+	collector.purgeMutex.Lock()
+	defer collector.purgeMutex.Unlock()
+
+	if progress, ok := collector.purgeProgress[tableName]; ok {
+		progress.rowsPurged += rows
+	}
+}
+
+// shouldPurge returns true if the given table is still expected to be purged
+func (collector *TableGC) shouldPurge(tableName string) bool {
+	collector.purgeMutex.Lock()
+	defer collector.purgeMutex.Unlock()
+
+	return collector.purgingTables[tableName] && !collector.pausedTables[tableName]
+}
+
 // dropTable runs an actual DROP TABLE statement, and marks the end of the line for the
 // tables' GC lifecycle.
 func (collector *TableGC) dropTable(ctx context.Context, tableName string) error {
@@ -598,6 +722,15 @@ func (collector *TableGC) removePurgingTable(tableName string) {
 	defer collector.purgeMutex.Unlock()
 
 	delete(collector.purgingTables, tableName)
+	delete(collector.purgeProgress, tableName)
+}
+
+// isPaused returns true if the given table was paused on demand
+func (collector *TableGC) isPaused(tableName string) bool {
+	collector.purgeMutex.Lock()
+	defer collector.purgeMutex.Unlock()
+
+	return collector.pausedTables[tableName]
 }
 
 // nextTableToPurge returns the name of the next table we should start purging.
@@ -611,8 +744,14 @@ func (collector *TableGC) nextTableToPurge() (tableName string, ok bool) {
 	}
 	tableNames := []string{}
 	for tableName := range collector.purgingTables {
+		if collector.pausedTables[tableName] {
+			continue
+		}
 		tableNames = append(tableNames, tableName)
 	}
+	if len(tableNames) == 0 {
+		return "", false
+	}
 	sort.SliceStable(tableNames, func(i, j int) bool {
 		_, _, _, ti, _ := schema.AnalyzeGCTableName(tableNames[i])
 		_, _, _, tj, _ := schema.AnalyzeGCTableName(tableNames[j])
@@ -639,3 +778,99 @@ func (collector *TableGC) Status() *Status {
 
 	return status
 }
+
+// Tables returns the GC tables found by the last table check, along with the
+// progress of those being purged.
+func (collector *TableGC) Tables() []*TableStatus {
+	collector.purgeMutex.Lock()
+	defer collector.purgeMutex.Unlock()
+
+	now := time.Now()
+	tables := []*TableStatus{}
+	for tableName, state := range collector.gcTables {
+		status := &TableStatus{
+			TableName: tableName,
+			State:     state,
+			IsPaused:  collector.pausedTables[tableName],
+			IsPurging: collector.purgingTables[tableName],
+		}
+		if progress, ok := collector.purgeProgress[tableName]; ok {
+			status.RowsPurged = progress.rowsPurged
+			status.PurgeStartedAt = progress.startedAt
+			if progress.estimatedRows > progress.rowsPurged {
+				status.RowsRemaining = progress.estimatedRows - progress.rowsPurged
+			}
+			if progress.rowsPurged > 0 {
+				elapsed := now.Sub(progress.startedAt)
+				remaining := time.Duration(float64(elapsed) * float64(status.RowsRemaining) / float64(progress.rowsPurged))
+				status.EstimatedCompletion = now.Add(remaining)
+			}
+		}
+		tables = append(tables, status)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].TableName < tables[j].TableName
+	})
+	return tables
+}
+
+// PauseTable stops purging and transitioning the given GC table until it is resumed.
+// Paused tables are not persisted, and are resumed when the tablet restarts.
+func (collector *TableGC) PauseTable(tableName string) error {
+	if err := validateGCTableName(tableName); err != nil {
+		return err
+	}
+	collector.purgeMutex.Lock()
+	defer collector.purgeMutex.Unlock()
+
+	collector.pausedTables[tableName] = true
+	log.Infof("TableGC: paused table %s", tableName)
+	return nil
+}
+
+// ResumeTable resumes the garbage collection of a paused table.
+func (collector *TableGC) ResumeTable(tableName string) error {
+	if err := validateGCTableName(tableName); err != nil {
+		return err
+	}
+	collector.purgeMutex.Lock()
+	defer collector.purgeMutex.Unlock()
+
+	delete(collector.pausedTables, tableName)
+	log.Infof("TableGC: resumed table %s", tableName)
+	return nil
+}
+
+// DropTable immediately drops the given GC table, regardless of its state or
+// of whether it is being purged.
+func (collector *TableGC) DropTable(ctx context.Context, tableName string) error {
+	if err := validateGCTableName(tableName); err != nil {
+		return err
+	}
+	if atomic.LoadInt64(&collector.isOpen) == 0 {
+		return fmt.Errorf("TableGC is not open")
+	}
+	// Stop purging the table, if at all
+	collector.removePurgingTable(tableName)
+	if err := collector.dropTable(ctx, tableName); err != nil {
+		return err
+	}
+
+	collector.purgeMutex.Lock()
+	defer collector.purgeMutex.Unlock()
+	delete(collector.pausedTables, tableName)
+	delete(collector.gcTables, tableName)
+	return nil
+}
+
+// validateGCTableName returns an error if the given table is not a GC table.
+func validateGCTableName(tableName string) error {
+	isGCTable, _, _, _, err := schema.AnalyzeGCTableName(tableName)
+	if err != nil {
+		return err
+	}
+	if !isGCTable {
+		return fmt.Errorf("%s is not a GC table", tableName)
+	}
+	return nil
+}
@@ -18,10 +18,12 @@ package gc
 
 import (
 	"testing"
+	"time"
 
 	"vitess.io/vitess/go/vt/schema"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNextTableToPurge(t *testing.T) {
@@ -219,3 +221,85 @@ func TestShouldTransitionTable(t *testing.T) {
 		}
 	}
 }
+
+func TestPausedTables(t *testing.T) {
+	collector := &TableGC{
+		purgingTables: map[string]bool{},
+		pausedTables:  map[string]bool{},
+	}
+	tables := []string{
+		"_vt_PURGE_6ace8bcef73211ea87e9f875a4d24e90_20200915120410",
+		"_vt_PURGE_2ace8bcef73211ea87e9f875a4d24e90_20200915120411",
+	}
+	for _, table := range tables {
+		collector.purgingTables[table] = true
+	}
+
+	err := collector.PauseTable("t1")
+	assert.Error(t, err)
+
+	err = collector.PauseTable(tables[0])
+	assert.NoError(t, err)
+	assert.True(t, collector.isPaused(tables[0]))
+	assert.False(t, collector.shouldPurge(tables[0]))
+	next, ok := collector.nextTableToPurge()
+	assert.True(t, ok)
+	assert.Equal(t, tables[1], next)
+
+	err = collector.PauseTable(tables[1])
+	assert.NoError(t, err)
+	_, ok = collector.nextTableToPurge()
+	assert.False(t, ok)
+
+	err = collector.ResumeTable(tables[0])
+	assert.NoError(t, err)
+	assert.True(t, collector.shouldPurge(tables[0]))
+	next, ok = collector.nextTableToPurge()
+	assert.True(t, ok)
+	assert.Equal(t, tables[0], next)
+}
+
+func TestTables(t *testing.T) {
+	purgeTable := "_vt_PURGE_6ace8bcef73211ea87e9f875a4d24e90_20200915120410"
+	holdTable := "_vt_HOLD_2ace8bcef73211ea87e9f875a4d24e90_20200915120411"
+	startedAt := time.Now().Add(-time.Minute)
+	collector := &TableGC{
+		purgingTables: map[string]bool{purgeTable: true},
+		pausedTables:  map[string]bool{holdTable: true},
+		purgeProgress: map[string]*purgeProgress{
+			purgeTable: {startedAt: startedAt, estimatedRows: 1000, rowsPurged: 250},
+		},
+		gcTables: map[string]schema.TableGCState{
+			purgeTable: schema.PurgeTableGCState,
+			holdTable:  schema.HoldTableGCState,
+		},
+	}
+
+	tables := collector.Tables()
+	require.Len(t, tables, 2)
+
+	assert.Equal(t, holdTable, tables[0].TableName)
+	assert.Equal(t, schema.HoldTableGCState, tables[0].State)
+	assert.True(t, tables[0].IsPaused)
+	assert.False(t, tables[0].IsPurging)
+	assert.True(t, tables[0].EstimatedCompletion.IsZero())
+
+	assert.Equal(t, purgeTable, tables[1].TableName)
+	assert.Equal(t, schema.PurgeTableGCState, tables[1].State)
+	assert.False(t, tables[1].IsPaused)
+	assert.True(t, tables[1].IsPurging)
+	assert.Equal(t, int64(250), tables[1].RowsPurged)
+	assert.Equal(t, int64(750), tables[1].RowsRemaining)
+	assert.Equal(t, startedAt, tables[1].PurgeStartedAt)
+	// A quarter of the rows were purged in one minute, so three more minutes are expected
+	assert.WithinDuration(t, time.Now().Add(3*time.Minute), tables[1].EstimatedCompletion, 5*time.Second)
+
+	collector.addPurgedRows(purgeTable, 750)
+	tables = collector.Tables()
+	assert.Equal(t, int64(0), tables[1].RowsRemaining)
+
+	collector.removePurgingTable(purgeTable)
+	tables = collector.Tables()
+	assert.False(t, tables[1].IsPurging)
+	assert.Equal(t, int64(0), tables[1].RowsPurged)
+}
@@ -63,6 +63,8 @@ func analyzeSelect(sel *sqlparser.Select, tables map[string]*schema.Table) (plan
 		plan.PlanID = PlanSelectLockFunc
 		plan.NeedsReservedConn = true
 	}
+	plan.NonDeterministic = hasNonDeterministicFunc(sel)
+	plan.ReadTables = readTables(sel)
 	return plan, nil
 }
 
@@ -176,8 +176,17 @@ type Plan struct {
 	// FullStmt can be used when the query does not operate on tables
 	FullStmt sqlparser.Statement
 
+	// ReadTables are the names of all the tables read by a SELECT, including the tables of
+	// subqueries, derived tables and unions. It is nil if a table is qualified with a
+	// database name, since the tables of other databases are not tracked.
+	ReadTables []string
+
 	// NeedsReservedConn indicates at a reserved connection is needed to execute this plan
 	NeedsReservedConn bool
+
+	// NonDeterministic indicates that the result of a SELECT depends on more than the data
+	// it reads, e.g. on the current time or on session variables
+	NonDeterministic bool
 }
 
 // TableName returns the table name for the plan.
@@ -206,8 +215,9 @@ func Build(statement sqlparser.Statement, tables map[string]*schema.Table, dbNam
 	switch stmt := statement.(type) {
 	case *sqlparser.Union:
 		plan, err = &Plan{
-			PlanID:    PlanSelect,
-			FullQuery: GenerateLimitQuery(stmt),
+			PlanID:     PlanSelect,
+			FullQuery:  GenerateLimitQuery(stmt),
+			ReadTables: readTables(stmt),
 		}, nil
 	case *sqlparser.Select:
 		plan, err = analyzeSelect(stmt, tables)
@@ -324,6 +334,88 @@ func hasLockFunc(sel *sqlparser.Select) bool {
 	return found
 }
 
+// nonDeterministicFuncs are the functions whose results may differ between two
+// executions of a query over the same data.
+var nonDeterministicFuncs = map[string]bool{
+	"benchmark":         true,
+	"connection_id":     true,
+	"curdate":           true,
+	"current_date":      true,
+	"current_role":      true,
+	"current_time":      true,
+	"current_timestamp": true,
+	"current_user":      true,
+	"curtime":           true,
+	"database":          true,
+	"found_rows":        true,
+	"last_insert_id":    true,
+	"localtime":         true,
+	"localtimestamp":    true,
+	"now":               true,
+	"rand":              true,
+	"random_bytes":      true,
+	"row_count":         true,
+	"schema":            true,
+	"session_user":      true,
+	"sleep":             true,
+	"sysdate":           true,
+	"system_user":       true,
+	"unix_timestamp":    true,
+	"user":              true,
+	"utc_date":          true,
+	"utc_time":          true,
+	"utc_timestamp":     true,
+	"uuid":              true,
+	"uuid_short":        true,
+}
+
+// hasNonDeterministicFunc looks for functions, variables and locking functions whose
+// results do not only depend on the data read by the select query.
+func hasNonDeterministicFunc(sel *sqlparser.Select) bool {
+	var found bool
+	_ = sqlparser.Walk(func(in sqlparser.SQLNode) (bool, error) {
+		switch node := in.(type) {
+		case *sqlparser.CurTimeFuncExpr, *sqlparser.Variable, *sqlparser.LockingFunc:
+			found = true
+		case *sqlparser.FuncExpr:
+			found = nonDeterministicFuncs[node.Name.Lowered()]
+		}
+		return !found, nil
+	}, sel)
+	return found
+}
+
+// readTables returns the names of all the tables read by a select statement, or nil
+// if a table is qualified with a database name.
+func readTables(stmt sqlparser.SelectStatement) (tables []string) {
+	seen := map[string]bool{}
+	qualified := false
+	_ = sqlparser.Walk(func(in sqlparser.SQLNode) (bool, error) {
+		aliased, ok := in.(*sqlparser.AliasedTableExpr)
+		if !ok {
+			return true, nil
+		}
+		tableName, ok := aliased.Expr.(sqlparser.TableName)
+		if !ok {
+			// a derived table, whose tables are walked next
+			return true, nil
+		}
+		if !tableName.Qualifier.IsEmpty() {
+			qualified = true
+			return false, nil
+		}
+		if name := tableName.Name.String(); !seen[name] {
+			seen[name] = true
+			tables = append(tables, name)
+		}
+		return true, nil
+	}, stmt)
+	if qualified {
+		return nil
+	}
+	return tables
+}
+
 // BuildSettingQuery builds a query for system settings.
 func BuildSettingQuery(settings []string) (query string, resetQuery string, err error) {
 	if len(settings) == 0 {
@@ -369,3 +369,46 @@ func iterateExecFile(name string) (testCaseIterator chan testCase) {
 func locateFile(name string) string {
 	return "testdata/" + name
 }
+
+func TestReadTables(t *testing.T) {
+	testcases := []struct {
+		query string
+		want  []string
+	}{{
+		query: "select * from a",
+		want:  []string{"a"},
+	}, {
+		query: "select * from a join b on a.id = b.id where a.id = 1",
+		want:  []string{"a", "b"},
+	}, {
+		query: "select * from a where id in (select id from b)",
+		want:  []string{"a", "b"},
+	}, {
+		query: "select * from a where exists (select 1 from b where b.id = a.id)",
+		want:  []string{"a", "b"},
+	}, {
+		query: "select * from (select * from b) as a",
+		want:  []string{"b"},
+	}, {
+		query: "select * from a union select * from b",
+		want:  []string{"a", "b"},
+	}, {
+		query: "select (select max(id) from b), id from a, a as c",
+		want:  []string{"a", "b"},
+	}, {
+		query: "select * from otherdb.a",
+		want:  nil,
+	}, {
+		query: "select * from a where id in (select id from otherdb.b)",
+		want:  nil,
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			statement, err := sqlparser.Parse(tcase.query)
+			require.NoError(t, err)
+			plan, err := Build(statement, map[string]*schema.Table{}, "dbName", false)
+			require.NoError(t, err)
+			require.Equal(t, tcase.want, plan.ReadTables)
+		})
+	}
+}
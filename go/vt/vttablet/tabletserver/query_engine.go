@@ -153,6 +153,9 @@ type QueryEngine struct {
 	// that we start more than one transaction per hot row (range).
 	// For implementation details, please see BeginExecute() in tabletserver.go.
 	txSerializer *txserializer.TxSerializer
+	// resultCache caches the results of queries against the tables listed
+	// in --queryserver-config-result-cache-tables on non-primary tablets.
+	resultCache *ResultCache
 
 	// Vars
 	maxResultSize    sync2.AtomicInt64
@@ -208,6 +211,7 @@ func NewQueryEngine(env tabletenv.Env, se *schema.Engine) *QueryEngine {
 		log.Info("Stream consolidator is not enabled.")
 	}
 	qe.txSerializer = txserializer.New(env)
+	qe.resultCache = NewResultCache(env)
 
 	qe.strictTableACL = config.StrictTableACL
 	qe.enableTableACLDryRun = config.EnableTableACLDryRun
@@ -303,6 +307,7 @@ func (qe *QueryEngine) Close() {
 	// Close in reverse order of Open.
 	qe.se.UnregisterNotifier("qe")
 	qe.plans.Clear()
+	qe.resultCache.Clear()
 	qe.tables = make(map[string]*schema.Table)
 	qe.streamConns.Close()
 	qe.conns.Close()
@@ -431,6 +436,7 @@ func (qe *QueryEngine) schemaChanged(tables map[string]*schema.Table, created, a
 	qe.tables = tables
 	if len(altered) != 0 || len(dropped) != 0 {
 		qe.plans.Clear()
+		qe.resultCache.Clear()
 	}
 }
 
@@ -800,6 +800,40 @@ func (qre *QueryExecutor) execSelect() (*sqltypes.Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	if qre.shouldUseResultCache() {
+		tables := qre.plan.ReadTables
+		result, token := qre.tsv.qe.resultCache.Get(sqlWithoutComments, tables)
+		if result != nil {
+			qre.logStats.QuerySources |= tabletenv.QuerySourceResultCache
+			return result, nil
+		}
+		result, err = qre.execSelectFromMySQL(sql, sqlWithoutComments)
+		if err != nil {
+			return nil, err
+		}
+		qre.tsv.qe.resultCache.Set(sqlWithoutComments, tables, token, result)
+		return result, nil
+	}
+	return qre.execSelectFromMySQL(sql, sqlWithoutComments)
+}
+
+// shouldUseResultCache returns true if the result of the query can be
+// served from, and stored in, the result cache. Queries with system settings
+// are not cached, since their results depend on the settings of the session,
+// and neither are queries using e.g. NOW() or variables, whose results do not
+// only depend on the data they read. All the tables read by the query,
+// including in subqueries, must be cached tables of the local database.
+func (qre *QueryExecutor) shouldUseResultCache() bool {
+	if qre.tabletType == topodatapb.TabletType_PRIMARY || qre.plan.PlanID != p.PlanSelect {
+		return false
+	}
+	if qre.setting != nil || qre.plan.NonDeterministic {
+		return false
+	}
+	return qre.tsv.qe.resultCache.Cacheable(qre.plan.ReadTables)
+}
+
+func (qre *QueryExecutor) execSelectFromMySQL(sql, sqlWithoutComments string) (*sqltypes.Result, error) {
 	// Check tablet type.
 	if qre.shouldConsolidate() {
 		q, original := qre.tsv.qe.consolidator.Create(sqlWithoutComments)
@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"container/list"
+	"sync"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// ResultCache caches the results of SELECT queries that only read from a
+// configured set of tables. Entries are keyed by the final SQL sent to MySQL,
+// which contains both the normalized query and its bind variables.
+//
+// The cache is only serving while the BinlogWatcher is streaming the local
+// binary log: every row event for a cached table invalidates all results
+// that read from that table, and any DDL, or a break in the stream,
+// invalidates the whole cache. This guarantees that cached results are never
+// older than the position the watcher has processed, which may be behind the
+// position the replica has applied: a transaction is visible to MySQL reads as
+// soon as the replica applies it, but only invalidates the cache once the
+// watcher streams it from the binary log. In between, typically for a few milliseconds
+// and for longer if the watcher falls behind, a hit may return a result which
+// predates the transaction.
+//
+// A query is only cached if all the tables it reads, including in subqueries,
+// derived tables and unions, are configured. Tables qualified with a database
+// name are never cached, since the watcher only streams the local database.
+type ResultCache struct {
+	maxEntries int
+	tables     map[string]bool
+
+	mu      sync.Mutex
+	serving bool
+	lru     *list.List
+	entries map[string]*list.Element
+	byTable map[string]map[string]bool
+	// generations is bumped for a table every time it is invalidated.
+	// Results computed across an invalidation are not stored.
+	generations map[string]int64
+
+	hits, misses, invalidations *stats.Counter
+}
+
+type resultCacheEntry struct {
+	key    string
+	tables []string
+	result *sqltypes.Result
+}
+
+// NewResultCache creates a new ResultCache. The cache is disabled if
+// no tables are configured.
+func NewResultCache(env tabletenv.Env) *ResultCache {
+	config := env.Config()
+	rc := &ResultCache{
+		maxEntries:  config.ResultCacheSize,
+		tables:      make(map[string]bool, len(config.ResultCacheTables)),
+		lru:         list.New(),
+		entries:     make(map[string]*list.Element),
+		byTable:     make(map[string]map[string]bool),
+		generations: make(map[string]int64),
+	}
+	for _, table := range config.ResultCacheTables {
+		rc.tables[table] = true
+	}
+	rc.hits = env.Exporter().NewCounter("ResultCacheHits", "Result cache hits")
+	rc.misses = env.Exporter().NewCounter("ResultCacheMisses", "Result cache misses")
+	rc.invalidations = env.Exporter().NewCounter("ResultCacheInvalidations", "Result cache entries invalidated by the binlog stream")
+	env.Exporter().NewGaugeFunc("ResultCacheLength", "Result cache length", func() int64 {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		return int64(rc.lru.Len())
+	})
+	return rc
+}
+
+// Enabled returns true if at least one table is configured for caching.
+func (rc *ResultCache) Enabled() bool {
+	return rc != nil && len(rc.tables) > 0 && rc.maxEntries > 0
+}
+
+// Cacheable returns true if the results of a query reading from
+// the given tables can be cached.
+func (rc *ResultCache) Cacheable(tables []string) bool {
+	if !rc.Enabled() || len(tables) == 0 {
+		return false
+	}
+	for _, table := range tables {
+		if !rc.tables[table] {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns a copy of the cached result for key, if any. If there is no
+// result, it returns a token that must be passed to Set along with the result
+// computed from MySQL.
+func (rc *ResultCache) Get(key string, tables []string) (*sqltypes.Result, []int64) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if !rc.serving {
+		return nil, nil
+	}
+	if elem, ok := rc.entries[key]; ok {
+		rc.lru.MoveToFront(elem)
+		rc.hits.Add(1)
+		return elem.Value.(*resultCacheEntry).result.Copy(), nil
+	}
+	rc.misses.Add(1)
+	token := make([]int64, len(tables))
+	for i, table := range tables {
+		token[i] = rc.generations[table]
+	}
+	return nil, token
+}
+
+// Set stores a copy of result for key, unless one of the tables was
+// invalidated since the token was obtained through Get.
+func (rc *ResultCache) Set(key string, tables []string, token []int64, result *sqltypes.Result) {
+	if token == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if !rc.serving {
+		return
+	}
+	for i, table := range tables {
+		if rc.generations[table] != token[i] {
+			return
+		}
+	}
+	if _, ok := rc.entries[key]; ok {
+		return
+	}
+	entry := &resultCacheEntry{key: key, tables: tables, result: result.Copy()}
+	rc.entries[key] = rc.lru.PushFront(entry)
+	for _, table := range tables {
+		keys := rc.byTable[table]
+		if keys == nil {
+			keys = make(map[string]bool)
+			rc.byTable[table] = keys
+		}
+		keys[key] = true
+	}
+	for rc.lru.Len() > rc.maxEntries {
+		rc.removeLocked(rc.lru.Back().Value.(*resultCacheEntry))
+	}
+}
+
+// Invalidate removes all the cached results that read from table.
+func (rc *ResultCache) Invalidate(table string) {
+	if !rc.tables[table] {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.generations[table]++
+	for key := range rc.byTable[table] {
+		rc.removeLocked(rc.entries[key].Value.(*resultCacheEntry))
+		rc.invalidations.Add(1)
+	}
+}
+
+// setServing changes the serving state of the cache. The cache is
+// emptied on every transition.
+func (rc *ResultCache) setServing(serving bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.serving == serving {
+		return
+	}
+	log.Infof("Result cache: serving=%v", serving)
+	rc.serving = serving
+	rc.clearLocked()
+}
+
+// Clear empties the cache.
+func (rc *ResultCache) Clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.clearLocked()
+}
+
+func (rc *ResultCache) clearLocked() {
+	rc.invalidations.Add(int64(rc.lru.Len()))
+	rc.lru.Init()
+	rc.entries = make(map[string]*list.Element)
+	rc.byTable = make(map[string]map[string]bool)
+	for table := range rc.tables {
+		rc.generations[table]++
+	}
+}
+
+func (rc *ResultCache) removeLocked(entry *resultCacheEntry) {
+	rc.lru.Remove(rc.entries[entry.key])
+	delete(rc.entries, entry.key)
+	for _, table := range entry.tables {
+		delete(rc.byTable[table], entry.key)
+	}
+}
+
+// processEvents invalidates the cache based on the events
+// received by the BinlogWatcher.
+func (rc *ResultCache) processEvents(events []*binlogdatapb.VEvent) {
+	rc.setServing(true)
+	for _, event := range events {
+		switch event.Type {
+		case binlogdatapb.VEventType_ROW:
+			rc.Invalidate(event.RowEvent.TableName)
+		case binlogdatapb.VEventType_DDL:
+			rc.Clear()
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tabletserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/pools"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+func newTestResultCache(size int, tables ...string) *ResultCache {
+	config := tabletenv.NewDefaultConfig()
+	config.ResultCacheTables = tables
+	config.ResultCacheSize = size
+	return NewResultCache(tabletenv.NewEnv(config, "ResultCacheTest"))
+}
+
+func TestResultCacheCacheable(t *testing.T) {
+	rc := newTestResultCache(10, "t1", "t2")
+	assert.True(t, rc.Enabled())
+	assert.True(t, rc.Cacheable([]string{"t1"}))
+	assert.True(t, rc.Cacheable([]string{"t1", "t2"}))
+	assert.False(t, rc.Cacheable([]string{"t1", "t3"}))
+	assert.False(t, rc.Cacheable(nil))
+
+	rc = newTestResultCache(10)
+	assert.False(t, rc.Enabled())
+	assert.False(t, rc.Cacheable([]string{"t1"}))
+}
+
+func TestResultCacheGetSet(t *testing.T) {
+	rc := newTestResultCache(2, "t1", "t2")
+	tables := []string{"t1"}
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+
+	// Nothing is cached until the binlog stream is running.
+	result, token := rc.Get("select 1", tables)
+	assert.Nil(t, result)
+	assert.Nil(t, token)
+	rc.Set("select 1", tables, token, want)
+	rc.processEvents(nil)
+	result, token = rc.Get("select 1", tables)
+	assert.Nil(t, result)
+	require.NotNil(t, token)
+
+	rc.Set("select 1", tables, token, want)
+	result, _ = rc.Get("select 1", tables)
+	assert.Equal(t, want, result)
+
+	// Cached results are copies, which callers may modify.
+	result.Fields[0].Database = "ks"
+	result, _ = rc.Get("select 1", tables)
+	assert.Equal(t, want, result)
+
+	// Eviction of the least recently used entry.
+	_, token = rc.Get("select 2", tables)
+	rc.Set("select 2", tables, token, want)
+	_, token = rc.Get("select 3", []string{"t2"})
+	rc.Set("select 3", []string{"t2"}, token, want)
+	result, _ = rc.Get("select 1", tables)
+	assert.Nil(t, result)
+	result, _ = rc.Get("select 3", []string{"t2"})
+	assert.Equal(t, want, result)
+
+	// Stream stopped: the cache is emptied.
+	rc.setServing(false)
+	rc.setServing(true)
+	result, _ = rc.Get("select 3", []string{"t2"})
+	assert.Nil(t, result)
+}
+
+func TestResultCacheInvalidation(t *testing.T) {
+	rc := newTestResultCache(10, "t1", "t2")
+	rc.processEvents(nil)
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+
+	_, token := rc.Get("q1", []string{"t1"})
+	rc.Set("q1", []string{"t1"}, token, want)
+	_, token = rc.Get("q12", []string{"t1", "t2"})
+	rc.Set("q12", []string{"t1", "t2"}, token, want)
+	_, token = rc.Get("q2", []string{"t2"})
+	rc.Set("q2", []string{"t2"}, token, want)
+
+	rc.processEvents([]*binlogdatapb.VEvent{{
+		Type:     binlogdatapb.VEventType_ROW,
+		RowEvent: &binlogdatapb.RowEvent{TableName: "t2"},
+	}})
+	result, _ := rc.Get("q1", []string{"t1"})
+	assert.Equal(t, want, result)
+	result, _ = rc.Get("q12", []string{"t1", "t2"})
+	assert.Nil(t, result)
+	result, _ = rc.Get("q2", []string{"t2"})
+	assert.Nil(t, result)
+
+	// A result computed across an invalidation must not be stored.
+	_, token = rc.Get("q2", []string{"t2"})
+	rc.Invalidate("t2")
+	rc.Set("q2", []string{"t2"}, token, want)
+	result, _ = rc.Get("q2", []string{"t2"})
+	assert.Nil(t, result)
+
+	// DDLs invalidate everything.
+	rc.processEvents([]*binlogdatapb.VEvent{{Type: binlogdatapb.VEventType_DDL}})
+	result, _ = rc.Get("q1", []string{"t1"})
+	assert.Nil(t, result)
+}
+
+func TestQueryExecutorResultCache(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table"
+	finalQuery := "select * from test_table limit 10001"
+	want := &sqltypes.Result{
+		Fields: getTestTableFields(),
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewInt32(1),
+			sqltypes.NewInt32(2),
+			sqltypes.NewInt32(3),
+		}},
+	}
+	db.AddQuery(query, want)
+	db.AddQuery(finalQuery, want)
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+	tsv.qe.resultCache = newTestResultCache(10, "test_table")
+	tsv.qe.resultCache.processEvents(nil)
+
+	for i := 0; i < 3; i++ {
+		qre := newTestQueryExecutor(ctx, tsv, query, 0)
+		got, err := qre.Execute()
+		require.NoError(t, err)
+		utils.MustMatch(t, want, got)
+	}
+	assert.Equal(t, 1, db.GetQueryCalledNum(finalQuery))
+
+	tsv.qe.resultCache.Invalidate("test_table")
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	_, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, 2, db.GetQueryCalledNum(finalQuery))
+
+	// Queries with system settings are not cached.
+	qre = newTestQueryExecutor(ctx, tsv, query, 0)
+	qre.setting = pools.NewSetting("set @@time_zone = '+00:00'", "set @@time_zone = default")
+	assert.False(t, qre.shouldUseResultCache())
+
+	// Non-deterministic queries are not cached.
+	for _, query := range []string{
+		"select now() from test_table",
+		"select pk, rand() from test_table",
+		"select * from test_table where pk > @v",
+		"select uuid() from test_table",
+	} {
+		qre = newTestQueryExecutor(ctx, tsv, query, 0)
+		assert.False(t, qre.shouldUseResultCache(), query)
+	}
+
+	// Queries which read from other tables, including in subqueries, or from tables of other databases, are not cached.
+	for _, query := range []string{
+		"select * from test_table where pk in (select pk from other_table)",
+		"select * from test_table where exists (select 1 from other_table where other_table.pk = test_table.pk)",
+		"select * from (select * from other_table) as test_table",
+		"select * from test_table union select * from other_table",
+		"select * from otherdb.test_table",
+		"select * from test_table where pk in (select pk from otherdb.test_table)",
+	} {
+		qre = newTestQueryExecutor(ctx, tsv, query, 0)
+		assert.False(t, qre.shouldUseResultCache(), query)
+	}
+	qre = newTestQueryExecutor(ctx, tsv, "select * from test_table where pk in (select pk from test_table where name = 'a')", 0)
+	assert.True(t, qre.shouldUseResultCache())
+}
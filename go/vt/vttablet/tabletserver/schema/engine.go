@@ -516,6 +516,12 @@ func (se *Engine) RegisterVersionEvent() error {
 	return se.historian.RegisterVersionEvent()
 }
 
+// GetTableHistory returns the changes to the definition of a table recorded
+// by the schema tracker. It fails if schema versions are not being tracked.
+func (se *Engine) GetTableHistory(ctx context.Context, tableName string) ([]*TableVersion, error) {
+	return se.historian.GetTableHistory(ctx, tableName)
+}
+
 // GetTableForPos returns a best-effort schema for a specific gtid
 func (se *Engine) GetTableForPos(tableName sqlparser.IdentifierCS, gtid string) (*binlogdatapb.MinimalTable, error) {
 	mt, err := se.historian.GetTableForPos(tableName, gtid)
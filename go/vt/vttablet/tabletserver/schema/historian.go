@@ -28,6 +28,8 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/log"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
@@ -42,9 +44,23 @@ const vl = 10
 
 // trackedSchema has the snapshot of the table at a given pos (reached by ddl)
 type trackedSchema struct {
-	schema map[string]*binlogdatapb.MinimalTable
-	pos    mysql.Position
-	ddl    string
+	schema      map[string]*binlogdatapb.MinimalTable
+	pos         mysql.Position
+	ddl         string
+	id          int64
+	timeUpdated int64
+}
+
+// TableVersion is a change to the definition of a table recorded in the
+// schema_version table.
+type TableVersion struct {
+	ID          int64
+	Pos         mysql.Position
+	DDL         string
+	TimeUpdated int64
+	// Table is the definition of the table after the DDL, or nil if the
+	// DDL dropped the table.
+	Table *binlogdatapb.MinimalTable
 }
 
 // historian implements the Historian interface by calling schema.Engine for the underlying schema
@@ -156,6 +172,40 @@ func (h *historian) GetTableForPos(tableName sqlparser.IdentifierCS, gtid string
 	return t, nil
 }
 
+// GetTableHistory returns the changes to the definition of a table recorded
+// in the schema_version table, in ascending order of position. The history is
+// refreshed from the database before being returned.
+func (h *historian) GetTableHistory(ctx context.Context, tableName string) ([]*TableVersion, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.isOpen {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "schema version tracking is not enabled")
+	}
+	if err := h.loadFromDB(ctx); err != nil {
+		return nil, err
+	}
+
+	var (
+		versions []*TableVersion
+		prev     *binlogdatapb.MinimalTable
+	)
+	for _, ts := range h.schemas {
+		t := ts.schema[tableName]
+		if proto.Equal(t, prev) {
+			continue
+		}
+		versions = append(versions, &TableVersion{
+			ID:          ts.id,
+			Pos:         ts.pos,
+			DDL:         ts.ddl,
+			TimeUpdated: ts.timeUpdated,
+			Table:       t,
+		})
+		prev = t
+	}
+	return versions, nil
+}
+
 // loadFromDB loads all rows from the schema_version table that the historian does not have as yet
 // caller should have locked h.mu
 func (h *historian) loadFromDB(ctx context.Context) error {
@@ -217,9 +267,11 @@ func (h *historian) readRow(row []sqltypes.Value) (*trackedSchema, int64, error)
 		tables[t.Name] = t
 	}
 	tSchema := &trackedSchema{
-		schema: tables,
-		pos:    pos,
-		ddl:    ddl,
+		schema:      tables,
+		pos:         pos,
+		ddl:         ddl,
+		id:          id,
+		timeUpdated: timeUpdated,
 	}
 	return tSchema, id, nil
 }
@@ -17,12 +17,14 @@ limitations under the License.
 package schema
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/sqltypes"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -174,3 +176,80 @@ func TestHistorian(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, exp3, fmt.Sprintf("%v", tab))
 }
+
+func TestHistorianTableHistory(t *testing.T) {
+	se, db, cancel := getTestSchemaEngine(t)
+	defer cancel()
+
+	ctx := context.Background()
+	se.EnableHistorian(false)
+	_, err := se.GetTableHistory(ctx, "t1")
+	require.EqualError(t, err, "schema version tracking is not enabled")
+	se.EnableHistorian(true)
+
+	fields := []*querypb.Field{{
+		Name: "id",
+		Type: sqltypes.Int32,
+	}, {
+		Name: "pos",
+		Type: sqltypes.VarBinary,
+	}, {
+		Name: "ddl",
+		Type: sqltypes.VarBinary,
+	}, {
+		Name: "time_updated",
+		Type: sqltypes.Int32,
+	}, {
+		Name: "schemax",
+		Type: sqltypes.Blob,
+	}}
+	gtidPrefix := "MySQL56/7b04699f-f5e9-11e9-bf88-9cb6d089e1c3:"
+	t1v1 := getTable("t1", []string{"id1"}, []querypb.Type{querypb.Type_INT32}, []int64{0})
+	t1v2 := getTable("t1", []string{"id1", "id2"}, []querypb.Type{querypb.Type_INT32, querypb.Type_INT32}, []int64{0})
+	t2v1 := getTable("t2", []string{"id1"}, []querypb.Type{querypb.Type_INT32}, []int64{0})
+	t2v2 := getTable("t2", []string{"id1"}, []querypb.Type{querypb.Type_INT64}, []int64{0})
+	versions := []struct {
+		ddl    string
+		tables map[string]*binlogdatapb.MinimalTable
+	}{
+		{"create table t1 (id1 int)", map[string]*binlogdatapb.MinimalTable{"t1": t1v1}},
+		{"create table t2 (id1 int)", map[string]*binlogdatapb.MinimalTable{"t1": t1v1, "t2": t2v1}},
+		{"alter table t2 modify id1 bigint", map[string]*binlogdatapb.MinimalTable{"t1": t1v1, "t2": t2v2}},
+		{"alter table t1 add column id2 int", map[string]*binlogdatapb.MinimalTable{"t1": t1v2, "t2": t2v2}},
+		{"drop table t1", map[string]*binlogdatapb.MinimalTable{"t2": t2v2}},
+	}
+	result := &sqltypes.Result{Fields: fields}
+	for i, v := range versions {
+		result.Rows = append(result.Rows, []sqltypes.Value{
+			sqltypes.NewInt32(int32(i + 1)),
+			sqltypes.NewVarBinary(fmt.Sprintf("%s1-%d", gtidPrefix, (i+1)*10)),
+			sqltypes.NewVarBinary(v.ddl),
+			sqltypes.NewInt32(int32(1000 + i)),
+			sqltypes.NewVarBinary(getDbSchemaBlob(t, v.tables)),
+		})
+	}
+	db.AddQuery("select id, pos, ddl, time_updated, schemax from _vt.schema_version where id > 0 order by id asc", result)
+	db.AddQuery("select id, pos, ddl, time_updated, schemax from _vt.schema_version where id > 5 order by id asc", &sqltypes.Result{Fields: fields})
+
+	history, err := se.GetTableHistory(ctx, "t1")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	var ids []int64
+	var ddls []string
+	for _, v := range history {
+		ids = append(ids, v.ID)
+		ddls = append(ddls, v.DDL)
+	}
+	require.Equal(t, []int64{1, 4, 5}, ids)
+	require.Equal(t, []string{versions[0].ddl, versions[3].ddl, versions[4].ddl}, ddls)
+	require.Equal(t, gtidPrefix+"1-40", mysql.EncodePosition(history[1].Pos))
+	require.Equal(t, int64(1003), history[1].TimeUpdated)
+	require.Equal(t, []string{"id1", "id2"}, []string{history[1].Table.Fields[0].Name, history[1].Table.Fields[1].Name})
+	require.Nil(t, history[2].Table)
+
+	history, err = se.GetTableHistory(ctx, "t2")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, int64(2), history[0].ID)
+	require.Equal(t, int64(3), history[1].ID)
+}
@@ -119,6 +119,7 @@ type stateManager struct {
 	ddle        onlineDDLExecutor
 	throttler   lagThrottler
 	tableGC     tableGarbageCollector
+	driftCheck  driftChecker
 
 	// hcticks starts on initialiazation and runs forever.
 	hcticks *timer.Timer
@@ -185,6 +186,11 @@ type (
 		Open() error
 		Close()
 	}
+
+	driftChecker interface {
+		Open() error
+		Close()
+	}
 )
 
 // Init performs the second phase of initialization.
@@ -451,6 +457,7 @@ func (sm *stateManager) servePrimary() error {
 	sm.messager.Open()
 	sm.throttler.Open()
 	sm.tableGC.Open()
+	sm.driftCheck.Open()
 	sm.ddle.Open()
 	sm.setState(topodatapb.TabletType_PRIMARY, StateServing)
 	return nil
@@ -477,6 +484,7 @@ func (sm *stateManager) serveNonPrimary(wantTabletType topodatapb.TabletType) er
 	defer cancel()
 
 	sm.ddle.Close()
+	sm.driftCheck.Close()
 	sm.tableGC.Close()
 	sm.messager.Close()
 	sm.tracker.Close()
@@ -531,7 +539,9 @@ func (sm *stateManager) unserveCommon() {
 
 	log.Infof("Started online ddl executor close")
 	sm.ddle.Close()
-	log.Infof("Finished online ddl executor close. Started table garbage collector close")
+	log.Infof("Finished online ddl executor close. Started drift checker close")
+	sm.driftCheck.Close()
+	log.Infof("Finished drift checker close. Started table garbage collector close")
 	sm.tableGC.Close()
 	log.Infof("Finished table garbage collector close. Started lag throttler close")
 	sm.throttler.Close()
@@ -87,7 +87,8 @@ func TestStateManagerServePrimary(t *testing.T) {
 	verifySubcomponent(t, 9, sm.messager, testStateOpen)
 	verifySubcomponent(t, 10, sm.throttler, testStateOpen)
 	verifySubcomponent(t, 11, sm.tableGC, testStateOpen)
-	verifySubcomponent(t, 12, sm.ddle, testStateOpen)
+	verifySubcomponent(t, 12, sm.driftCheck, testStateOpen)
+	verifySubcomponent(t, 13, sm.ddle, testStateOpen)
 
 	assert.False(t, sm.se.(*testSchemaEngine).nonPrimary)
 	assert.True(t, sm.se.(*testSchemaEngine).ensureCalled)
@@ -103,19 +104,20 @@ func TestStateManagerServeNonPrimary(t *testing.T) {
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
-	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.messager, testStateClosed)
-	verifySubcomponent(t, 4, sm.tracker, testStateClosed)
+	verifySubcomponent(t, 2, sm.driftCheck, testStateClosed)
+	verifySubcomponent(t, 3, sm.tableGC, testStateClosed)
+	verifySubcomponent(t, 4, sm.messager, testStateClosed)
+	verifySubcomponent(t, 5, sm.tracker, testStateClosed)
 	assert.True(t, sm.se.(*testSchemaEngine).nonPrimary)
 
-	verifySubcomponent(t, 5, sm.se, testStateOpen)
-	verifySubcomponent(t, 6, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 7, sm.qe, testStateOpen)
-	verifySubcomponent(t, 8, sm.txThrottler, testStateOpen)
-	verifySubcomponent(t, 9, sm.te, testStateNonPrimary)
-	verifySubcomponent(t, 10, sm.rt, testStateNonPrimary)
-	verifySubcomponent(t, 11, sm.watcher, testStateOpen)
-	verifySubcomponent(t, 12, sm.throttler, testStateOpen)
+	verifySubcomponent(t, 6, sm.se, testStateOpen)
+	verifySubcomponent(t, 7, sm.vstreamer, testStateOpen)
+	verifySubcomponent(t, 8, sm.qe, testStateOpen)
+	verifySubcomponent(t, 9, sm.txThrottler, testStateOpen)
+	verifySubcomponent(t, 10, sm.te, testStateNonPrimary)
+	verifySubcomponent(t, 11, sm.rt, testStateNonPrimary)
+	verifySubcomponent(t, 12, sm.watcher, testStateOpen)
+	verifySubcomponent(t, 13, sm.throttler, testStateOpen)
 
 	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.target.TabletType)
 	assert.Equal(t, StateServing, sm.state)
@@ -128,19 +130,20 @@ func TestStateManagerUnservePrimary(t *testing.T) {
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
-	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.throttler, testStateClosed)
-	verifySubcomponent(t, 4, sm.messager, testStateClosed)
-	verifySubcomponent(t, 5, sm.te, testStateClosed)
+	verifySubcomponent(t, 2, sm.driftCheck, testStateClosed)
+	verifySubcomponent(t, 3, sm.tableGC, testStateClosed)
+	verifySubcomponent(t, 4, sm.throttler, testStateClosed)
+	verifySubcomponent(t, 5, sm.messager, testStateClosed)
+	verifySubcomponent(t, 6, sm.te, testStateClosed)
 
-	verifySubcomponent(t, 6, sm.tracker, testStateClosed)
-	verifySubcomponent(t, 7, sm.watcher, testStateClosed)
-	verifySubcomponent(t, 8, sm.se, testStateOpen)
-	verifySubcomponent(t, 9, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 10, sm.qe, testStateOpen)
-	verifySubcomponent(t, 11, sm.txThrottler, testStateOpen)
+	verifySubcomponent(t, 7, sm.tracker, testStateClosed)
+	verifySubcomponent(t, 8, sm.watcher, testStateClosed)
+	verifySubcomponent(t, 9, sm.se, testStateOpen)
+	verifySubcomponent(t, 10, sm.vstreamer, testStateOpen)
+	verifySubcomponent(t, 11, sm.qe, testStateOpen)
+	verifySubcomponent(t, 12, sm.txThrottler, testStateOpen)
 
-	verifySubcomponent(t, 12, sm.rt, testStatePrimary)
+	verifySubcomponent(t, 13, sm.rt, testStatePrimary)
 
 	assert.Equal(t, topodatapb.TabletType_PRIMARY, sm.target.TabletType)
 	assert.Equal(t, StateNotServing, sm.state)
@@ -153,21 +156,22 @@ func TestStateManagerUnserveNonPrimary(t *testing.T) {
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
-	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.throttler, testStateClosed)
-	verifySubcomponent(t, 4, sm.messager, testStateClosed)
-	verifySubcomponent(t, 5, sm.te, testStateClosed)
+	verifySubcomponent(t, 2, sm.driftCheck, testStateClosed)
+	verifySubcomponent(t, 3, sm.tableGC, testStateClosed)
+	verifySubcomponent(t, 4, sm.throttler, testStateClosed)
+	verifySubcomponent(t, 5, sm.messager, testStateClosed)
+	verifySubcomponent(t, 6, sm.te, testStateClosed)
 
-	verifySubcomponent(t, 6, sm.tracker, testStateClosed)
+	verifySubcomponent(t, 7, sm.tracker, testStateClosed)
 	assert.True(t, sm.se.(*testSchemaEngine).nonPrimary)
 
-	verifySubcomponent(t, 7, sm.se, testStateOpen)
-	verifySubcomponent(t, 8, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 9, sm.qe, testStateOpen)
-	verifySubcomponent(t, 10, sm.txThrottler, testStateOpen)
+	verifySubcomponent(t, 8, sm.se, testStateOpen)
+	verifySubcomponent(t, 9, sm.vstreamer, testStateOpen)
+	verifySubcomponent(t, 10, sm.qe, testStateOpen)
+	verifySubcomponent(t, 11, sm.txThrottler, testStateOpen)
 
-	verifySubcomponent(t, 11, sm.rt, testStateNonPrimary)
-	verifySubcomponent(t, 12, sm.watcher, testStateOpen)
+	verifySubcomponent(t, 12, sm.rt, testStateNonPrimary)
+	verifySubcomponent(t, 13, sm.watcher, testStateOpen)
 
 	assert.Equal(t, topodatapb.TabletType_RDONLY, sm.target.TabletType)
 	assert.Equal(t, StateNotServing, sm.state)
@@ -180,18 +184,19 @@ func TestStateManagerClose(t *testing.T) {
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
-	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.throttler, testStateClosed)
-	verifySubcomponent(t, 4, sm.messager, testStateClosed)
-	verifySubcomponent(t, 5, sm.te, testStateClosed)
-	verifySubcomponent(t, 6, sm.tracker, testStateClosed)
-
-	verifySubcomponent(t, 7, sm.txThrottler, testStateClosed)
-	verifySubcomponent(t, 8, sm.qe, testStateClosed)
-	verifySubcomponent(t, 9, sm.watcher, testStateClosed)
-	verifySubcomponent(t, 10, sm.vstreamer, testStateClosed)
-	verifySubcomponent(t, 11, sm.rt, testStateClosed)
-	verifySubcomponent(t, 12, sm.se, testStateClosed)
+	verifySubcomponent(t, 2, sm.driftCheck, testStateClosed)
+	verifySubcomponent(t, 3, sm.tableGC, testStateClosed)
+	verifySubcomponent(t, 4, sm.throttler, testStateClosed)
+	verifySubcomponent(t, 5, sm.messager, testStateClosed)
+	verifySubcomponent(t, 6, sm.te, testStateClosed)
+	verifySubcomponent(t, 7, sm.tracker, testStateClosed)
+
+	verifySubcomponent(t, 8, sm.txThrottler, testStateClosed)
+	verifySubcomponent(t, 9, sm.qe, testStateClosed)
+	verifySubcomponent(t, 10, sm.watcher, testStateClosed)
+	verifySubcomponent(t, 11, sm.vstreamer, testStateClosed)
+	verifySubcomponent(t, 12, sm.rt, testStateClosed)
+	verifySubcomponent(t, 13, sm.se, testStateClosed)
 
 	assert.Equal(t, topodatapb.TabletType_RDONLY, sm.target.TabletType)
 	assert.Equal(t, StateNotConnected, sm.state)
@@ -294,19 +299,20 @@ func TestStateManagerSetServingTypeNoChange(t *testing.T) {
 	require.NoError(t, err)
 
 	verifySubcomponent(t, 1, sm.ddle, testStateClosed)
-	verifySubcomponent(t, 2, sm.tableGC, testStateClosed)
-	verifySubcomponent(t, 3, sm.messager, testStateClosed)
-	verifySubcomponent(t, 4, sm.tracker, testStateClosed)
+	verifySubcomponent(t, 2, sm.driftCheck, testStateClosed)
+	verifySubcomponent(t, 3, sm.tableGC, testStateClosed)
+	verifySubcomponent(t, 4, sm.messager, testStateClosed)
+	verifySubcomponent(t, 5, sm.tracker, testStateClosed)
 	assert.True(t, sm.se.(*testSchemaEngine).nonPrimary)
 
-	verifySubcomponent(t, 5, sm.se, testStateOpen)
-	verifySubcomponent(t, 6, sm.vstreamer, testStateOpen)
-	verifySubcomponent(t, 7, sm.qe, testStateOpen)
-	verifySubcomponent(t, 8, sm.txThrottler, testStateOpen)
-	verifySubcomponent(t, 9, sm.te, testStateNonPrimary)
-	verifySubcomponent(t, 10, sm.rt, testStateNonPrimary)
-	verifySubcomponent(t, 11, sm.watcher, testStateOpen)
-	verifySubcomponent(t, 12, sm.throttler, testStateOpen)
+	verifySubcomponent(t, 6, sm.se, testStateOpen)
+	verifySubcomponent(t, 7, sm.vstreamer, testStateOpen)
+	verifySubcomponent(t, 8, sm.qe, testStateOpen)
+	verifySubcomponent(t, 9, sm.txThrottler, testStateOpen)
+	verifySubcomponent(t, 10, sm.te, testStateNonPrimary)
+	verifySubcomponent(t, 11, sm.rt, testStateNonPrimary)
+	verifySubcomponent(t, 12, sm.watcher, testStateOpen)
+	verifySubcomponent(t, 13, sm.throttler, testStateOpen)
 
 	assert.Equal(t, topodatapb.TabletType_REPLICA, sm.target.TabletType)
 	assert.Equal(t, StateServing, sm.state)
@@ -718,6 +724,7 @@ func newTestStateManager(t *testing.T) *stateManager {
 		ddle:        &testOnlineDDLExecutor{},
 		throttler:   &testLagThrottler{},
 		tableGC:     &testTableGC{},
+		driftCheck:  &testDriftCheck{},
 	}
 	sm.Init(env, &querypb.Target{})
 	sm.hs.InitDBConfig(&querypb.Target{}, fakesqldb.New(t).ConnParams())
@@ -937,3 +944,18 @@ func (te *testTableGC) Close() {
 	te.order = order.Add(1)
 	te.state = testStateClosed
 }
+
+type testDriftCheck struct {
+	testOrderState
+}
+
+func (te *testDriftCheck) Open() error {
+	te.order = order.Add(1)
+	te.state = testStateOpen
+	return nil
+}
+
+func (te *testDriftCheck) Close() {
+	te.order = order.Add(1)
+	te.state = testStateClosed
+}
@@ -165,6 +165,8 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	flagutil.DualFormatBoolVar(fs, &enableConsolidatorReplicas, "enable_consolidator_replicas", false, "This option enables the query consolidator only on replicas.")
 	fs.Int64Var(&currentConfig.ConsolidatorStreamQuerySize, "consolidator-stream-query-size", defaultConfig.ConsolidatorStreamQuerySize, "Configure the stream consolidator query size in bytes. Setting to 0 disables the stream consolidator.")
 	fs.Int64Var(&currentConfig.ConsolidatorStreamTotalSize, "consolidator-stream-total-size", defaultConfig.ConsolidatorStreamTotalSize, "Configure the stream consolidator total size in bytes. Setting to 0 disables the stream consolidator.")
+	fs.StringSliceVar(&currentConfig.ResultCacheTables, "queryserver-config-result-cache-tables", defaultConfig.ResultCacheTables, "Comma separated list of tables whose query results are cached on non-primary tablets. Cached results are invalidated once the local replication stream watcher sees a change, which may lag behind the replica's applied position. Setting this implies --watch_replication_stream.")
+	fs.IntVar(&currentConfig.ResultCacheSize, "queryserver-config-result-cache-size", defaultConfig.ResultCacheSize, "Maximum number of query results kept in the result cache.")
 	flagutil.DualFormatBoolVar(fs, &currentConfig.DeprecatedCacheResultFields, "enable_query_plan_field_caching", defaultConfig.DeprecatedCacheResultFields, "This option fetches & caches fields (columns) when storing query plans")
 	_ = fs.MarkDeprecated("enable_query_plan_field_caching", "it will be removed in a future release.")
 	_ = fs.MarkDeprecated("enable-query-plan-field-caching", "it will be removed in a future release.")
@@ -326,6 +328,9 @@ type TabletConfig struct {
 	RowStreamer RowStreamerConfig `json:"rowStreamer,omitempty"`
 
 	EnableViews bool `json:"-"`
+
+	ResultCacheTables []string `json:"-"`
+	ResultCacheSize   int      `json:"-"`
 }
 
 // ConnPoolConfig contains the config for a conn pool.
@@ -542,6 +547,7 @@ var defaultConfig = TabletConfig{
 	Consolidator:                Enable,
 	ConsolidatorStreamTotalSize: 128 * 1024 * 1024,
 	ConsolidatorStreamQuerySize: 2 * 1024 * 1024,
+	ResultCacheSize:             10000,
 	// The value for StreamBufferSize was chosen after trying out a few of
 	// them. Too small buffers force too many packets to be sent. Too big
 	// buffers force the clients to read them in multiple chunks and make
@@ -55,6 +55,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/onlineddl"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/driftcheck"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/gc"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/messager"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
@@ -70,6 +71,7 @@ import (
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
@@ -118,6 +120,7 @@ type TabletServer struct {
 	hs           *healthStreamer
 	lagThrottler *throttle.Throttler
 	tableGC      *gc.TableGC
+	driftCheck   *driftcheck.Checker
 
 	// sm manages state transitions.
 	sm                *stateManager
@@ -180,14 +183,15 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 	tsv.lagThrottler = throttle.NewThrottler(tsv, srvTopoServer, topoServer, alias.Cell, tsv.rt.HeartbeatWriter(), tabletTypeFunc)
 	tsv.vstreamer = vstreamer.NewEngine(tsv, srvTopoServer, tsv.se, tsv.lagThrottler, alias.Cell)
 	tsv.tracker = schema.NewTracker(tsv, tsv.vstreamer, tsv.se)
-	tsv.watcher = NewBinlogWatcher(tsv, tsv.vstreamer, tsv.config)
 	tsv.qe = NewQueryEngine(tsv, tsv.se)
+	tsv.watcher = NewBinlogWatcher(tsv, tsv.vstreamer, tsv.config, tsv.qe.resultCache)
 	tsv.txThrottler = txthrottler.NewTxThrottler(tsv.config, topoServer)
 	tsv.te = NewTxEngine(tsv)
 	tsv.messager = messager.NewEngine(tsv, tsv.se, tsv.vstreamer)
 
 	tsv.onlineDDLExecutor = onlineddl.NewExecutor(tsv, alias, topoServer, tsv.lagThrottler, tabletTypeFunc, tsv.onlineDDLExecutorToggleTableBuffer)
 	tsv.tableGC = gc.NewTableGC(tsv, topoServer, tsv.lagThrottler)
+	tsv.driftCheck = driftcheck.NewChecker(tsv, topoServer, tsv.lagThrottler)
 
 	tsv.sm = &stateManager{
 		statelessql: tsv.statelessql,
@@ -206,6 +210,7 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 		ddle:        tsv.onlineDDLExecutor,
 		throttler:   tsv.lagThrottler,
 		tableGC:     tsv.tableGC,
+		driftCheck:  tsv.driftCheck,
 	}
 
 	tsv.exporter.NewGaugeFunc("TabletState", "Tablet server state", func() int64 { return int64(tsv.sm.State()) })
@@ -226,6 +231,7 @@ func NewTabletServer(name string, config *tabletenv.TabletConfig, topoServer *to
 	tsv.registerTwopczHandler()
 	tsv.registerMigrationStatusHandler()
 	tsv.registerThrottlerHandlers()
+	tsv.registerTableGCHandlers()
 	tsv.registerDebugEnvHandler()
 
 	return tsv
@@ -269,6 +275,7 @@ func (tsv *TabletServer) InitDBConfig(target *querypb.Target, dbcfgs *dbconfigs.
 	tsv.onlineDDLExecutor.InitDBConfig(target.Keyspace, target.Shard, dbcfgs.DBName)
 	tsv.lagThrottler.InitDBConfig(target.Keyspace, target.Shard)
 	tsv.tableGC.InitDBConfig(target.Keyspace, target.Shard, dbcfgs.DBName)
+	tsv.driftCheck.InitDBConfig(target.Keyspace, target.Shard, dbcfgs.DBName)
 	return nil
 }
 
@@ -464,6 +471,12 @@ func (tsv *TabletServer) LagThrottler() *throttle.Throttler {
 	return tsv.lagThrottler
 }
 
+// EstimateSchemaMigration estimates the impact of running the given DDL
+// statement as an Online DDL migration, without running it.
+func (tsv *TabletServer) EstimateSchemaMigration(ctx context.Context, sql string, ddlStrategy string) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	return tsv.onlineDDLExecutor.EstimateMigration(ctx, sql, ddlStrategy)
+}
+
 // TableGC returns the tableDropper part of TabletServer.
 func (tsv *TabletServer) TableGC() *gc.TableGC {
 	return tsv.tableGC
@@ -716,6 +729,26 @@ func (tsv *TabletServer) ReadTransaction(ctx context.Context, target *querypb.Ta
 	return metadata, err
 }
 
+// UnresolvedTransactions returns the distributed transactions for which this
+// tablet is the metadata manager and that were created more than abandonAge ago.
+func (tsv *TabletServer) UnresolvedTransactions(ctx context.Context, abandonAge time.Duration) (transactions []*querypb.TransactionMetadata, err error) {
+	err = tsv.execRequest(
+		ctx, tsv.QueryTimeout.Get(),
+		"UnresolvedTransactions", "unresolved_transactions", nil,
+		tsv.sm.Target(), nil, true, /* allowOnShutdown */
+		func(ctx context.Context, logStats *tabletenv.LogStats) error {
+			txe := &TxExecutor{
+				ctx:      ctx,
+				logStats: logStats,
+				te:       tsv.te,
+			}
+			transactions, err = txe.ReadUnresolvedTransactions(abandonAge)
+			return err
+		},
+	)
+	return transactions, err
+}
+
 // Execute executes the query and returns the result as response.
 func (tsv *TabletServer) Execute(ctx context.Context, target *querypb.Target, sql string, bindVariables map[string]*querypb.BindVariable, transactionID, reservedID int64, options *querypb.ExecuteOptions) (result *sqltypes.Result, err error) {
 	span, ctx := trace.NewSpan(ctx, "TabletServer.Execute")
@@ -1863,6 +1896,43 @@ func (tsv *TabletServer) registerThrottlerHandlers() {
 	tsv.registerThrottlerThrottleAppHandler()
 }
 
+// registerTableGCHandlers registers the table garbage collector's status and control requests
+func (tsv *TabletServer) registerTableGCHandlers() {
+	tsv.exporter.HandleFunc("/debug/tablegc", func(w http.ResponseWriter, r *http.Request) {
+		if err := acl.CheckAccessHTTP(r, acl.DEBUGGING); err != nil {
+			acl.SendError(w, err)
+			return
+		}
+		b, err := json.Marshal(tsv.tableGC.Tables())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("not ok: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	})
+	handle := func(path string, f func(ctx context.Context, tableName string) error) {
+		tsv.exporter.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			if err := acl.CheckAccessHTTP(r, acl.ADMIN); err != nil {
+				acl.SendError(w, err)
+				return
+			}
+			if err := f(tabletenv.LocalContext(), r.URL.Query().Get("table")); err != nil {
+				http.Error(w, fmt.Sprintf("not ok: %v", err), http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte("ok"))
+		})
+	}
+	handle("/debug/tablegc/pause", func(ctx context.Context, tableName string) error {
+		return tsv.tableGC.PauseTable(tableName)
+	})
+	handle("/debug/tablegc/resume", func(ctx context.Context, tableName string) error {
+		return tsv.tableGC.ResumeTable(tableName)
+	})
+	handle("/debug/tablegc/drop", tsv.tableGC.DropTable)
+}
+
 func (tsv *TabletServer) registerDebugEnvHandler() {
 	tsv.exporter.HandleFunc("/debug/env", func(w http.ResponseWriter, r *http.Request) {
 		debugEnvHandler(tsv, w, r)
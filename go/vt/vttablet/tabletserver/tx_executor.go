@@ -26,6 +26,7 @@ import (
 	"vitess.io/vitess/go/vt/log"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
@@ -259,6 +260,40 @@ func (txe *TxExecutor) ReadTwopcInflight() (distributed []*tx.DistributedTx, pre
 	return distributed, prepared, failed, nil
 }
 
+// ReadUnresolvedTransactions returns the distributed transactions for which
+// this tablet is the metadata manager, and which were created more than
+// abandonAge ago.
+func (txe *TxExecutor) ReadUnresolvedTransactions(abandonAge time.Duration) ([]*querypb.TransactionMetadata, error) {
+	if !txe.te.twopcEnabled {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "2pc is not enabled")
+	}
+	distributed, err := txe.te.twoPC.ReadAllTransactions(txe.ctx)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_UNKNOWN, "Could not read transactions: %v", err)
+	}
+	abandonTime := time.Now().Add(-abandonAge)
+	var transactions []*querypb.TransactionMetadata
+	for _, dtx := range distributed {
+		if !dtx.Created.Before(abandonTime) {
+			continue
+		}
+		transaction := &querypb.TransactionMetadata{
+			Dtid:        dtx.Dtid,
+			State:       querypb.TransactionState(querypb.TransactionState_value[dtx.State]),
+			TimeCreated: dtx.Created.UnixNano(),
+		}
+		for i := range dtx.Participants {
+			transaction.Participants = append(transaction.Participants, &querypb.Target{
+				Keyspace:   dtx.Participants[i].Keyspace,
+				Shard:      dtx.Participants[i].Shard,
+				TabletType: topodatapb.TabletType_PRIMARY,
+			})
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
 func (txe *TxExecutor) inTransaction(f func(*StatefulConnection) error) error {
 	conn, _, _, err := txe.te.txPool.Begin(txe.ctx, &querypb.ExecuteOptions{}, false, 0, nil, nil)
 	if err != nil {
@@ -31,6 +31,7 @@ import (
 
 	"vitess.io/vitess/go/mysql/fakesqldb"
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/vt/vtgate/fakerpcvtgateconn"
 	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
@@ -397,6 +398,52 @@ func TestExecutorReadAllTransactions(t *testing.T) {
 	}
 }
 
+func TestExecutorReadUnresolvedTransactions(t *testing.T) {
+	txe, tsv, db := newTestTxExecutor(t)
+	defer db.Close()
+	defer tsv.StopService()
+
+	db.AddQuery(txe.te.twoPC.readAllTransactions, &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Type: sqltypes.VarChar},
+			{Type: sqltypes.Int64},
+			{Type: sqltypes.Int64},
+			{Type: sqltypes.VarChar},
+			{Type: sqltypes.VarChar},
+		},
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewVarBinary("dtid0"),
+			sqltypes.NewInt64(int64(querypb.TransactionState_PREPARE)),
+			sqltypes.NewVarBinary("1"),
+			sqltypes.NewVarBinary("ks01"),
+			sqltypes.NewVarBinary("shard01"),
+		}, {
+			sqltypes.NewVarBinary("dtid1"),
+			sqltypes.NewInt64(int64(querypb.TransactionState_COMMIT)),
+			sqltypes.NewVarBinary(fmt.Sprintf("%d", time.Now().UnixNano())),
+			sqltypes.NewVarBinary("ks01"),
+			sqltypes.NewVarBinary("shard01"),
+		}},
+	})
+	got, err := txe.ReadUnresolvedTransactions(time.Hour)
+	require.NoError(t, err)
+	want := []*querypb.TransactionMetadata{{
+		Dtid:        "dtid0",
+		State:       querypb.TransactionState_PREPARE,
+		TimeCreated: 1,
+		Participants: []*querypb.Target{{
+			Keyspace:   "ks01",
+			Shard:      "shard01",
+			TabletType: topodatapb.TabletType_PRIMARY,
+		}},
+	}}
+	utils.MustMatch(t, want, got)
+
+	got, err = txe.ReadUnresolvedTransactions(0)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+}
+
 // These vars and types are used only for TestExecutorResolveTransaction
 var dtidCh = make(chan string)
 
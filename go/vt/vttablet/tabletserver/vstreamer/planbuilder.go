@@ -81,6 +81,9 @@ const (
 	GreaterThanEqual
 	// NotEqual is used to filter a comparable column if != specific value
 	NotEqual
+	// Expression is used to filter on any other boolean expression,
+	// evaluated with the evalengine against the row of the table
+	Expression
 )
 
 // Filter contains opcodes for filtering.
@@ -97,6 +100,10 @@ type Filter struct {
 	Vindex        vindexes.Vindex
 	VindexColumns []int
 	KeyRange      *topodatapb.KeyRange
+
+	// Expr is the expression for Expression. The row matches if it
+	// is true.
+	Expr evalengine.Expr
 }
 
 // ColExpr represents a column expression.
@@ -111,6 +118,10 @@ type ColExpr struct {
 	Vindex        vindexes.Vindex
 	VindexColumns []int
 
+	// Expr, if set, is evaluated against the row of the table to
+	// generate the value. If so, ColNum is ignored.
+	Expr evalengine.Expr
+
 	Field *querypb.Field
 
 	FixedValue sqltypes.Value
@@ -133,6 +144,73 @@ func (ta *Table) FindColumn(name sqlparser.IdentifierCI) int {
 	return -1
 }
 
+// columnLookup is the evalengine.TranslationLookup of the expressions
+// evaluated against the rows of a table.
+type columnLookup struct {
+	table *Table
+
+	// err is the error of the last column lookup, if any.
+	err error
+}
+
+var _ evalengine.TranslationLookup = (*columnLookup)(nil)
+
+// ColumnLookup is part of the evalengine.TranslationLookup interface.
+func (cl *columnLookup) ColumnLookup(col *sqlparser.ColName) (int, error) {
+	if !col.Qualifier.IsEmpty() {
+		cl.err = fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(col))
+		return 0, cl.err
+	}
+	colnum, err := findColumn(cl.table, col.Name)
+	if err != nil {
+		cl.err = err
+		return 0, err
+	}
+	return colnum, nil
+}
+
+// CollationForExpr is part of the evalengine.TranslationLookup interface.
+func (cl *columnLookup) CollationForExpr(expr sqlparser.Expr) collations.ID {
+	col, ok := expr.(*sqlparser.ColName)
+	if !ok {
+		return collations.Unknown
+	}
+	colnum := cl.table.FindColumn(col.Name)
+	if colnum < 0 {
+		return collations.Unknown
+	}
+	return collations.ID(cl.table.Fields[colnum].Charset)
+}
+
+// DefaultCollation is part of the evalengine.TranslationLookup interface.
+func (cl *columnLookup) DefaultCollation() collations.ID {
+	return collations.Default()
+}
+
+// translate translates an expression to be evaluated against the rows
+// of the table. If it cannot be translated, the error of the column
+// lookup is returned if there was one, so that a missing column is
+// reported as such, or else the unsupported error.
+func (plan *Plan) translate(expr sqlparser.Expr, unsupported error) (evalengine.Expr, error) {
+	lookup := &columnLookup{table: plan.Table}
+	evalExpr, err := evalengine.Translate(expr, lookup)
+	if err != nil {
+		if lookup.err != nil {
+			return nil, lookup.err
+		}
+		return nil, unsupported
+	}
+	return evalExpr, nil
+}
+
+// newExpressionEnv returns the environment to evaluate the expressions of
+// the plan.
+func (plan *Plan) newExpressionEnv() *evalengine.ExpressionEnv {
+	env := evalengine.EmptyExpressionEnv()
+	env.Fields = plan.Table.Fields
+	return env
+}
+
 // fields returns the fields for the plan.
 func (plan *Plan) fields() []*querypb.Field {
 	fields := make([]*querypb.Field, len(plan.ColExprs))
@@ -216,8 +294,33 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 	if len(result) != len(plan.ColExprs) {
 		return false, fmt.Errorf("expected %d values in result slice", len(plan.ColExprs))
 	}
+	// env is only created if the plan has expressions to evaluate.
+	var env *evalengine.ExpressionEnv
+	evaluate := func(expr evalengine.Expr) (sqltypes.Value, error) {
+		if env == nil {
+			env = plan.newExpressionEnv()
+			env.Row = values
+		}
+		evalResult, err := env.Evaluate(expr)
+		if err != nil {
+			return sqltypes.NULL, err
+		}
+		return evalResult.Value(), nil
+	}
 	for _, filter := range plan.Filters {
 		switch filter.Opcode {
+		case Expression:
+			value, err := evaluate(filter.Expr)
+			if err != nil {
+				return false, err
+			}
+			match, err := value.ToInt64()
+			if err != nil {
+				return false, err
+			}
+			if match != 1 {
+				return false, nil
+			}
 		case VindexMatch:
 			ksid, err := getKeyspaceID(values, filter.Vindex, filter.VindexColumns, plan.Table.Fields)
 			if err != nil {
@@ -237,6 +340,21 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 		}
 	}
 	for i, colExpr := range plan.ColExprs {
+		if colExpr.Expr != nil {
+			value, err := evaluate(colExpr.Expr)
+			if err != nil {
+				return false, err
+			}
+			// The type of the column is planned from the types of the
+			// fields, and is kept for all the rows: a value of another
+			// type is cast to it, or fails the row if it cannot be.
+			value, err = evalengine.Cast(value, colExpr.Field.Type)
+			if err != nil {
+				return false, vterrors.Wrapf(err, "cannot cast the value of column %v to %v", colExpr.Field.Name, colExpr.Field.Type)
+			}
+			result[i] = value
+			continue
+		}
 		if colExpr.ColNum == -1 {
 			result[i] = colExpr.FixedValue
 			continue
@@ -508,57 +626,90 @@ func (plan *Plan) analyzeWhere(vschema *localVSchema, where *sqlparser.Where) er
 	for _, expr := range exprs {
 		switch expr := expr.(type) {
 		case *sqlparser.ComparisonExpr:
-			opcode, err := getOpcode(expr)
+			filter, ok, err := plan.analyzeComparison(expr)
 			if err != nil {
 				return err
 			}
-			qualifiedName, ok := expr.Left.(*sqlparser.ColName)
-			if !ok {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			if !qualifiedName.Qualifier.IsEmpty() {
-				return fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(qualifiedName))
-			}
-			colnum, err := findColumn(plan.Table, qualifiedName.Name)
-			if err != nil {
-				return err
-			}
-			val, ok := expr.Right.(*sqlparser.Literal)
-			if !ok {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			//StrVal is varbinary, we do not support varchar since we would have to implement all collation types
-			if val.Type != sqlparser.IntVal && val.Type != sqlparser.StrVal {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			pv, err := evalengine.Translate(val, semantics.EmptySemTable())
-			if err != nil {
-				return err
-			}
-			env := evalengine.EmptyExpressionEnv()
-			resolved, err := env.Evaluate(pv)
-			if err != nil {
-				return err
+			if ok {
+				plan.Filters = append(plan.Filters, filter)
+				continue
 			}
-			plan.Filters = append(plan.Filters, Filter{
-				Opcode: opcode,
-				ColNum: colnum,
-				Value:  resolved.Value(),
-			})
 		case *sqlparser.FuncExpr:
-			if !expr.Name.EqualString("in_keyrange") {
-				return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
-			}
-			if err := plan.analyzeInKeyRange(vschema, expr.Exprs); err != nil {
-				return err
+			if expr.Name.EqualString("in_keyrange") {
+				if err := plan.analyzeInKeyRange(vschema, expr.Exprs); err != nil {
+					return err
+				}
+				continue
 			}
-		default:
-			return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
+		}
+		if err := plan.analyzeExpression(expr); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// analyzeComparison returns the filter for a comparison of a column with
+// a literal, like "id = 1". It returns false for the other comparisons,
+// which are evaluated as expressions.
+func (plan *Plan) analyzeComparison(expr *sqlparser.ComparisonExpr) (Filter, bool, error) {
+	opcode, err := getOpcode(expr)
+	if err != nil {
+		return Filter{}, false, nil
+	}
+	qualifiedName, ok := expr.Left.(*sqlparser.ColName)
+	if !ok {
+		return Filter{}, false, nil
+	}
+	if !qualifiedName.Qualifier.IsEmpty() {
+		return Filter{}, false, fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(qualifiedName))
+	}
+	colnum, err := findColumn(plan.Table, qualifiedName.Name)
+	if err != nil {
+		return Filter{}, false, err
+	}
+	val, ok := expr.Right.(*sqlparser.Literal)
+	if !ok {
+		return Filter{}, false, nil
+	}
+	//StrVal is varbinary, we do not support varchar since we would have to implement all collation types
+	if val.Type != sqlparser.IntVal && val.Type != sqlparser.StrVal {
+		return Filter{}, false, nil
+	}
+	pv, err := evalengine.Translate(val, semantics.EmptySemTable())
+	if err != nil {
+		return Filter{}, false, err
+	}
+	env := evalengine.EmptyExpressionEnv()
+	resolved, err := env.Evaluate(pv)
+	if err != nil {
+		return Filter{}, false, err
+	}
+	return Filter{
+		Opcode: opcode,
+		ColNum: colnum,
+		Value:  resolved.Value(),
+	}, true, nil
+}
+
+// analyzeExpression adds a filter for any boolean expression, like
+// "status in ('paid', 'refunded') or amount is null". The expression is
+// evaluated with the evalengine, and the row matches if it is true.
+func (plan *Plan) analyzeExpression(expr sqlparser.Expr) error {
+	evalExpr, err := plan.translate(&sqlparser.IsExpr{
+		Left:  expr,
+		Right: sqlparser.IsTrueOp,
+	}, fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr)))
+	if err != nil {
+		return err
+	}
+	plan.Filters = append(plan.Filters, Filter{
+		Opcode: Expression,
+		Expr:   evalExpr,
+	})
+	return nil
+}
+
 // splitAndExpression breaks up the Expr into AND-separated conditions
 // and appends them to filters, which can be shuffled and recombined
 // as needed.
@@ -675,7 +826,7 @@ func (plan *Plan) analyzeExpr(vschema *localVSchema, selExpr sqlparser.SelectExp
 				Field:  field,
 			}, nil
 		default:
-			return ColExpr{}, fmt.Errorf("unsupported function: %v", sqlparser.String(inner))
+			return plan.analyzeComputedExpr(aliased, fmt.Errorf("unsupported function: %v", sqlparser.String(inner)))
 		}
 	case *sqlparser.Literal:
 		//allow only intval 1
@@ -709,11 +860,40 @@ func (plan *Plan) analyzeExpr(vschema *localVSchema, selExpr sqlparser.SelectExp
 			Field:  field,
 		}, nil
 	default:
-		log.Infof("Unsupported expression: %v", inner)
-		return ColExpr{}, fmt.Errorf("unsupported: %v", sqlparser.String(aliased.Expr))
+		return plan.analyzeComputedExpr(aliased, fmt.Errorf("unsupported: %v", sqlparser.String(aliased.Expr)))
 	}
 }
 
+// analyzeComputedExpr returns the column for an expression computed from
+// the columns of the table, like "concat(first_name, ' ', last_name)" or
+// "amount * 100". The expression is evaluated with the evalengine.
+func (plan *Plan) analyzeComputedExpr(aliased *sqlparser.AliasedExpr, unsupported error) (ColExpr, error) {
+	evalExpr, err := plan.translate(aliased.Expr, unsupported)
+	if err != nil {
+		log.Infof("Unsupported expression: %v", sqlparser.String(aliased.Expr))
+		return ColExpr{}, err
+	}
+	typ, err := plan.newExpressionEnv().TypeOf(evalExpr)
+	if err != nil {
+		return ColExpr{}, err
+	}
+	if typ == sqltypes.Null {
+		typ = sqltypes.VarBinary
+	}
+	name := aliased.As.String()
+	if name == "" {
+		name = sqlparser.String(aliased.Expr)
+	}
+	return ColExpr{
+		ColNum: -1,
+		Expr:   evalExpr,
+		Field: &querypb.Field{
+			Name: name,
+			Type: typ,
+		},
+	}, nil
+}
+
 // analyzeInKeyRange allows the following constructs: "in_keyrange('-80')",
 // "in_keyrange(col, 'hash', '-80')", "in_keyrange(col, 'local_vindex', '-80')", or
 // "in_keyrange(col, 'ks.external_vindex', '-80')".
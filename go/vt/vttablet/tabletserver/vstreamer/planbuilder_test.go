@@ -537,8 +537,20 @@ func TestPlanBuilder(t *testing.T) {
 		outErr:  `unsupported function: max(val)`,
 	}, {
 		inTable: t1,
-		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id+1, val from t1"},
-		outErr:  `unsupported: id + 1`,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, (select 1 from dual) from t1"},
+		outErr:  `unsupported: (select 1 from dual)`,
+	}, {
+		inTable: t1,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, val from t1 where id in (select id from t2)"},
+		outErr:  `unsupported constraint: id in (select id from t2)`,
+	}, {
+		inTable: t1,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, val from t1 where id = 1 or none = 2"},
+		outErr:  "column `none` not found in table t1",
+	}, {
+		inTable: t1,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, lower(none) from t1"},
+		outErr:  "column `none` not found in table t1",
 	}, {
 		inTable: t1,
 		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select t1.id, val from t1"},
@@ -663,6 +675,138 @@ func TestPlanBuilderFilterComparison(t *testing.T) {
 	}
 }
 
+func TestPlanBuilderExpressions(t *testing.T) {
+	t1 := &Table{
+		Name: "t1",
+		Fields: []*querypb.Field{{
+			Name: "id",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "status",
+			Type: sqltypes.VarChar,
+		}, {
+			Name: "amount",
+			Type: sqltypes.Int64,
+		}},
+	}
+	rows := [][]sqltypes.Value{
+		{sqltypes.NewInt64(1), sqltypes.NewVarChar("paid"), sqltypes.NewInt64(10)},
+		{sqltypes.NewInt64(2), sqltypes.NewVarChar("refunded"), sqltypes.NULL},
+		{sqltypes.NewInt64(3), sqltypes.NewVarChar("pending"), sqltypes.NewInt64(30)},
+		{sqltypes.NewInt64(4), sqltypes.NULL, sqltypes.NewInt64(40)},
+	}
+
+	testcases := []struct {
+		name     string
+		inFilter string
+		outIDs   []int64
+	}{{
+		name:     "in",
+		inFilter: "select * from t1 where status in ('paid', 'refunded')",
+		outIDs:   []int64{1, 2},
+	}, {
+		name:     "or",
+		inFilter: "select * from t1 where id = 1 or status = 'pending'",
+		outIDs:   []int64{1, 3},
+	}, {
+		name:     "is-null",
+		inFilter: "select * from t1 where amount is null or status is null",
+		outIDs:   []int64{2, 4},
+	}, {
+		name:     "like",
+		inFilter: "select * from t1 where status like 'p%'",
+		outIDs:   []int64{1, 3},
+	}, {
+		name:     "not",
+		inFilter: "select * from t1 where not (status = 'paid')",
+		outIDs:   []int64{2, 3},
+	}, {
+		name:     "function",
+		inFilter: "select * from t1 where length(status) = 4 or coalesce(amount, 0) = 0",
+		outIDs:   []int64{1, 2},
+	}, {
+		name:     "arithmetic",
+		inFilter: "select * from t1 where amount * 2 > 50",
+		outIDs:   []int64{3, 4},
+	}, {
+		name:     "combined-with-comparison",
+		inFilter: "select * from t1 where id >= 2 and status in ('refunded', 'pending')",
+		outIDs:   []int64{2, 3},
+	}, {
+		name:     "column",
+		inFilter: "select * from t1 where amount",
+		outIDs:   []int64{1, 3, 4},
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			plan, err := buildPlan(t1, testLocalVSchema, &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{Match: "t1", Filter: tcase.inFilter}},
+			})
+			require.NoError(t, err)
+
+			var ids []int64
+			for _, row := range rows {
+				result := make([]sqltypes.Value, len(plan.ColExprs))
+				ok, err := plan.filter(row, result, make([]collations.ID, len(row)))
+				require.NoError(t, err)
+				if ok {
+					id, err := result[0].ToInt64()
+					require.NoError(t, err)
+					ids = append(ids, id)
+				}
+			}
+			assert.Equal(t, tcase.outIDs, ids)
+		})
+	}
+
+	// Computed columns are evaluated for each row.
+	plan, err := buildPlan(t1, testLocalVSchema, &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{Match: "t1", Filter: "select id, amount * 100 as cents, coalesce(status, 'none'), id + 1 from t1 where status != 'pending' or status is null"}},
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, []*querypb.Field{{
+		Name: "id",
+		Type: sqltypes.Int64,
+	}, {
+		Name: "cents",
+		Type: sqltypes.Int64,
+	}, {
+		Name: "coalesce(`status`, 'none')",
+		Type: sqltypes.VarChar,
+	}, {
+		Name: "id + 1",
+		Type: sqltypes.Int64,
+	}}, plan.fields())
+
+	var got [][]sqltypes.Value
+	for _, row := range rows {
+		result := make([]sqltypes.Value, len(plan.ColExprs))
+		ok, err := plan.filter(row, result, make([]collations.ID, len(row)))
+		require.NoError(t, err)
+		if ok {
+			got = append(got, result)
+		}
+	}
+	assert.Equal(t, [][]sqltypes.Value{
+		{sqltypes.NewInt64(1), sqltypes.NewInt64(1000), sqltypes.NewVarChar("paid"), sqltypes.NewInt64(2)},
+		{sqltypes.NewInt64(2), sqltypes.NULL, sqltypes.NewVarChar("refunded"), sqltypes.NewInt64(3)},
+		{sqltypes.NewInt64(4), sqltypes.NewInt64(4000), sqltypes.NewVarChar("none"), sqltypes.NewInt64(5)},
+	}, got)
+
+	// Values of another type than the planned one are cast to it, or fail
+	// the row if they cannot be.
+	plan.ColExprs[1].Field.Type = sqltypes.Float64
+	result := make([]sqltypes.Value, len(plan.ColExprs))
+	ok, err := plan.filter(rows[0], result, make([]collations.ID, len(rows[0])))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, sqltypes.MakeTrusted(sqltypes.Float64, []byte("1000")), result[1])
+
+	plan.ColExprs[2].Field.Type = sqltypes.Int64
+	_, err = plan.filter(rows[0], result, make([]collations.ID, len(rows[0])))
+	assert.ErrorContains(t, err, "cannot cast the value of column coalesce(`status`, 'none') to INT64")
+}
+
 func TestCompare(t *testing.T) {
 	type testcase struct {
 		opcode                   Opcode
@@ -31,12 +31,15 @@ import (
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/gc"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 	"vitess.io/vitess/go/vt/vttablet/vexec"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
@@ -211,6 +214,26 @@ func (tqsc *Controller) SchemaEngine() *schema.Engine {
 	return nil
 }
 
+// TableGC is part of the tabletserver.Controller interface
+func (tqsc *Controller) TableGC() *gc.TableGC {
+	return nil
+}
+
+// LagThrottler is part of the tabletserver.Controller interface
+func (tqsc *Controller) LagThrottler() *throttle.Throttler {
+	return nil
+}
+
+// UnresolvedTransactions is part of the tabletserver.Controller interface
+func (tqsc *Controller) UnresolvedTransactions(ctx context.Context, abandonAge time.Duration) ([]*querypb.TransactionMetadata, error) {
+	return nil, nil
+}
+
+// EstimateSchemaMigration is part of the tabletserver.Controller interface
+func (tqsc *Controller) EstimateSchemaMigration(ctx context.Context, sql string, ddlStrategy string) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	return nil, nil
+}
+
 // BroadcastHealth is part of the tabletserver.Controller interface
 func (tqsc *Controller) BroadcastHealth() {
 	tqsc.mu.Lock()
@@ -72,9 +72,26 @@ type TabletManagerClient interface {
 	// GetSchema asks the remote tablet for its database schema
 	GetSchema(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaRequest) (*tabletmanagerdatapb.SchemaDefinition, error)
 
+	// GetSchemaHistory asks the remote tablet for the changes to a table's
+	// definition recorded by its schema tracker
+	GetSchemaHistory(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.GetSchemaHistoryRequest) (*tabletmanagerdatapb.GetSchemaHistoryResponse, error)
+
 	// GetPermissions asks the remote tablet for its permissions list
 	GetPermissions(ctx context.Context, tablet *topodatapb.Tablet) (*tabletmanagerdatapb.Permissions, error)
 
+	// GetUnresolvedTransactions asks the remote tablet for the distributed
+	// transactions it manages that were created more than abandonAge seconds
+	// ago and are not yet resolved.
+	GetUnresolvedTransactions(ctx context.Context, tablet *topodatapb.Tablet, abandonAge int64) ([]*querypb.TransactionMetadata, error)
+
+	// GetGCTables asks the remote tablet for the tables awaiting garbage
+	// collection, and the progress of their purge.
+	GetGCTables(ctx context.Context, tablet *topodatapb.Tablet) ([]*tabletmanagerdatapb.GCTable, error)
+
+	// EstimateSchemaMigration asks the remote tablet to estimate the impact of
+	// running a DDL statement as an Online DDL migration.
+	EstimateSchemaMigration(ctx context.Context, tablet *topodatapb.Tablet, request *tabletmanagerdatapb.EstimateSchemaMigrationRequest) (*tabletmanagerdatapb.SchemaMigrationEstimate, error)
+
 	//
 	// Various read-write methods
 	//
@@ -109,6 +126,10 @@ type TabletManagerClient interface {
 	// ApplySchema will apply a schema change
 	ApplySchema(ctx context.Context, tablet *topodatapb.Tablet, change *tmutils.SchemaChange) (*tabletmanagerdatapb.SchemaChangeResult, error)
 
+	// UpdateGCTable pauses, resumes or immediately drops a table awaiting
+	// garbage collection on the remote tablet.
+	UpdateGCTable(ctx context.Context, tablet *topodatapb.Tablet, tableName string, action tabletmanagerdatapb.UpdateGCTableRequest_Action) error
+
 	LockTables(ctx context.Context, tablet *topodatapb.Tablet) error
 
 	UnlockTables(ctx context.Context, tablet *topodatapb.Tablet) error
@@ -240,6 +261,11 @@ type TabletManagerClient interface {
 	// RestoreFromBackup deletes local data and restores database from backup
 	RestoreFromBackup(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.RestoreFromBackupRequest) (logutil.EventStream, error)
 
+	// SetBackupRateLimits changes the maximum rates, in bytes per second, at
+	// which the remote tablet's backups and restores read and write data.
+	// 0 means no limit.
+	SetBackupRateLimits(ctx context.Context, tablet *topodatapb.Tablet, read, write int64) error
+
 	//
 	// Management methods
 	//
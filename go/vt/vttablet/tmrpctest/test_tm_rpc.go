@@ -37,6 +37,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tabletmanager"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
@@ -300,6 +301,44 @@ func tmRPCTestGetSchemaPanic(ctx context.Context, t *testing.T, client tmclient.
 	expectHandleRPCPanic(t, "GetSchema", false /*verbose*/, err)
 }
 
+var testGetSchemaHistoryReq = &tabletmanagerdatapb.GetSchemaHistoryRequest{TableName: "table1", Position: "MariaDB/0-1-10"}
+var testGetSchemaHistoryReply = &tabletmanagerdatapb.GetSchemaHistoryResponse{
+	History: []*tabletmanagerdatapb.SchemaHistoryEntry{{
+		Id:          1,
+		Position:    "MariaDB/0-1-5",
+		Ddl:         "create table table1 (id int primary key)",
+		TimeUpdated: 1000,
+		Table: &binlogdatapb.MinimalTable{
+			Name:      "table1",
+			Fields:    []*querypb.Field{{Name: "id", Type: querypb.Type_INT32}},
+			PKColumns: []int64{0},
+		},
+	}},
+	TableAtPosition: &binlogdatapb.MinimalTable{
+		Name:      "table1",
+		Fields:    []*querypb.Field{{Name: "id", Type: querypb.Type_INT32}},
+		PKColumns: []int64{0},
+	},
+}
+
+func (fra *fakeRPCTM) GetSchemaHistory(ctx context.Context, request *tabletmanagerdatapb.GetSchemaHistoryRequest) (*tabletmanagerdatapb.GetSchemaHistoryResponse, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "GetSchemaHistory request", request, testGetSchemaHistoryReq)
+	return testGetSchemaHistoryReply, nil
+}
+
+func tmRPCTestGetSchemaHistory(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	result, err := client.GetSchemaHistory(ctx, tablet, testGetSchemaHistoryReq)
+	compareError(t, "GetSchemaHistory", err, result, testGetSchemaHistoryReply)
+}
+
+func tmRPCTestGetSchemaHistoryPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.GetSchemaHistory(ctx, tablet, testGetSchemaHistoryReq)
+	expectHandleRPCPanic(t, "GetSchemaHistory", false /*verbose*/, err)
+}
+
 var testGetPermissionsReply = &tabletmanagerdatapb.Permissions{
 	UserPermissions: []*tabletmanagerdatapb.UserPermission{
 		{
@@ -342,6 +381,98 @@ func tmRPCTestGetPermissionsPanic(ctx context.Context, t *testing.T, client tmcl
 	expectHandleRPCPanic(t, "GetPermissions", false /*verbose*/, err)
 }
 
+var testGetUnresolvedTransactionsAbandonAge = int64(60)
+
+var testGetUnresolvedTransactionsReply = []*querypb.TransactionMetadata{{
+	Dtid:        "ks:-80:1234",
+	State:       querypb.TransactionState_PREPARE,
+	TimeCreated: 1000,
+	Participants: []*querypb.Target{{
+		Keyspace:   "ks",
+		Shard:      "80-",
+		TabletType: topodatapb.TabletType_PRIMARY,
+	}},
+}}
+
+func (fra *fakeRPCTM) GetUnresolvedTransactions(ctx context.Context, abandonAge int64) ([]*querypb.TransactionMetadata, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "GetUnresolvedTransactions abandonAge", abandonAge, testGetUnresolvedTransactionsAbandonAge)
+	return testGetUnresolvedTransactionsReply, nil
+}
+
+func tmRPCTestGetUnresolvedTransactions(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	result, err := client.GetUnresolvedTransactions(ctx, tablet, testGetUnresolvedTransactionsAbandonAge)
+	compareError(t, "GetUnresolvedTransactions", err, result, testGetUnresolvedTransactionsReply)
+}
+
+func tmRPCTestGetUnresolvedTransactionsPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.GetUnresolvedTransactions(ctx, tablet, testGetUnresolvedTransactionsAbandonAge)
+	expectHandleRPCPanic(t, "GetUnresolvedTransactions", false /*verbose*/, err)
+}
+
+var testGetGCTablesReply = []*tabletmanagerdatapb.GCTable{{
+	TableName:           "_vt_PURGE_6ace8bcef73211ea87e9f875a4d24e90_20200915120410",
+	State:               "PURGE",
+	IsPurging:           true,
+	RowsPurged:          100,
+	RowsRemaining:       900,
+	PurgeStartedAt:      protoutil.TimeToProto(time.Unix(1600000000, 0)),
+	EstimatedCompletion: protoutil.TimeToProto(time.Unix(1600000900, 0)),
+}}
+
+func (fra *fakeRPCTM) GetGCTables(ctx context.Context) ([]*tabletmanagerdatapb.GCTable, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	return testGetGCTablesReply, nil
+}
+
+func tmRPCTestGetGCTables(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	result, err := client.GetGCTables(ctx, tablet)
+	compareError(t, "GetGCTables", err, result, testGetGCTablesReply)
+}
+
+func tmRPCTestGetGCTablesPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.GetGCTables(ctx, tablet)
+	expectHandleRPCPanic(t, "GetGCTables", false /*verbose*/, err)
+}
+
+var testEstimateSchemaMigrationRequest = &tabletmanagerdatapb.EstimateSchemaMigrationRequest{
+	Sql:         "alter table t add column i int",
+	DdlStrategy: "vitess",
+}
+
+var testEstimateSchemaMigrationReply = &tabletmanagerdatapb.SchemaMigrationEstimate{
+	Table:                "t",
+	DdlAction:            "alter",
+	TableRows:            1000,
+	TableSizeBytes:       65536,
+	RequiredDiskBytes:    65536,
+	FreeDiskBytes:        1 << 30,
+	CopyRowsPerSecond:    500,
+	EstimatedCopySeconds: 2,
+}
+
+func (fra *fakeRPCTM) EstimateSchemaMigration(ctx context.Context, request *tabletmanagerdatapb.EstimateSchemaMigrationRequest) (*tabletmanagerdatapb.SchemaMigrationEstimate, error) {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "EstimateSchemaMigration request", request, testEstimateSchemaMigrationRequest)
+	return testEstimateSchemaMigrationReply, nil
+}
+
+func tmRPCTestEstimateSchemaMigration(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	result, err := client.EstimateSchemaMigration(ctx, tablet, testEstimateSchemaMigrationRequest)
+	compareError(t, "EstimateSchemaMigration", err, result, testEstimateSchemaMigrationReply)
+}
+
+func tmRPCTestEstimateSchemaMigrationPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	_, err := client.EstimateSchemaMigration(ctx, tablet, testEstimateSchemaMigrationRequest)
+	expectHandleRPCPanic(t, "EstimateSchemaMigration", false /*verbose*/, err)
+}
+
 //
 // Various read-write methods
 //
@@ -581,6 +712,30 @@ func tmRPCTestApplySchemaPanic(ctx context.Context, t *testing.T, client tmclien
 	expectHandleRPCPanic(t, "ApplySchema", true /*verbose*/, err)
 }
 
+var testUpdateGCTableName = "_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120410"
+var testUpdateGCTableAction = tabletmanagerdatapb.UpdateGCTableRequest_DROP
+
+func (fra *fakeRPCTM) UpdateGCTable(ctx context.Context, tableName string, action tabletmanagerdatapb.UpdateGCTableRequest_Action) error {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "UpdateGCTable tableName", tableName, testUpdateGCTableName)
+	compare(fra.t, "UpdateGCTable action", action, testUpdateGCTableAction)
+	return nil
+}
+
+func tmRPCTestUpdateGCTable(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.UpdateGCTable(ctx, tablet, testUpdateGCTableName, testUpdateGCTableAction)
+	if err != nil {
+		t.Errorf("UpdateGCTable failed: %v", err)
+	}
+}
+
+func tmRPCTestUpdateGCTablePanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.UpdateGCTable(ctx, tablet, testUpdateGCTableName, testUpdateGCTableAction)
+	expectHandleRPCPanic(t, "UpdateGCTable", true /*verbose*/, err)
+}
+
 var testExecuteQueryQuery = []byte("drop table t")
 
 func (fra *fakeRPCTM) ExecuteQuery(ctx context.Context, req *tabletmanagerdatapb.ExecuteQueryRequest) (*querypb.QueryResult, error) {
@@ -1291,6 +1446,30 @@ func tmRPCTestRestoreFromBackupPanic(ctx context.Context, t *testing.T, client t
 	expectHandleRPCPanic(t, "RestoreFromBackup", true /*verbose*/, err)
 }
 
+var testSetBackupRateLimitsRead int64 = 10 * 1024 * 1024
+var testSetBackupRateLimitsWrite int64 = 20 * 1024 * 1024
+
+func (fra *fakeRPCTM) SetBackupRateLimits(ctx context.Context, read, write int64) error {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "SetBackupRateLimits read", read, testSetBackupRateLimitsRead)
+	compare(fra.t, "SetBackupRateLimits write", write, testSetBackupRateLimitsWrite)
+	return nil
+}
+
+func tmRPCTestSetBackupRateLimits(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.SetBackupRateLimits(ctx, tablet, testSetBackupRateLimitsRead, testSetBackupRateLimitsWrite)
+	if err != nil {
+		t.Errorf("SetBackupRateLimits failed: %v", err)
+	}
+}
+
+func tmRPCTestSetBackupRateLimitsPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.SetBackupRateLimits(ctx, tablet, testSetBackupRateLimitsRead, testSetBackupRateLimitsWrite)
+	expectHandleRPCPanic(t, "SetBackupRateLimits", true /*verbose*/, err)
+}
+
 //
 // RPC helpers
 //
@@ -1323,7 +1502,11 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	// Various read-only methods
 	tmRPCTestPing(ctx, t, client, tablet)
 	tmRPCTestGetSchema(ctx, t, client, tablet)
+	tmRPCTestGetSchemaHistory(ctx, t, client, tablet)
 	tmRPCTestGetPermissions(ctx, t, client, tablet)
+	tmRPCTestGetUnresolvedTransactions(ctx, t, client, tablet)
+	tmRPCTestGetGCTables(ctx, t, client, tablet)
+	tmRPCTestEstimateSchemaMigration(ctx, t, client, tablet)
 
 	// Various read-write methods
 	tmRPCTestSetReadOnly(ctx, t, client, tablet)
@@ -1335,6 +1518,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestReloadSchema(ctx, t, client, tablet)
 	tmRPCTestPreflightSchema(ctx, t, client, tablet)
 	tmRPCTestApplySchema(ctx, t, client, tablet)
+	tmRPCTestUpdateGCTable(ctx, t, client, tablet)
 	tmRPCTestExecuteFetch(ctx, t, client, tablet)
 
 	// Replication related methods
@@ -1371,6 +1555,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	// Backup / restore related methods
 	tmRPCTestBackup(ctx, t, client, tablet)
 	tmRPCTestRestoreFromBackup(ctx, t, client, tablet, restoreFromBackupRequest)
+	tmRPCTestSetBackupRateLimits(ctx, t, client, tablet)
 
 	//
 	// Tests panic handling everywhere now
@@ -1380,7 +1565,11 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	// Various read-only methods
 	tmRPCTestPingPanic(ctx, t, client, tablet)
 	tmRPCTestGetSchemaPanic(ctx, t, client, tablet)
+	tmRPCTestGetSchemaHistoryPanic(ctx, t, client, tablet)
 	tmRPCTestGetPermissionsPanic(ctx, t, client, tablet)
+	tmRPCTestGetUnresolvedTransactionsPanic(ctx, t, client, tablet)
+	tmRPCTestGetGCTablesPanic(ctx, t, client, tablet)
+	tmRPCTestEstimateSchemaMigrationPanic(ctx, t, client, tablet)
 
 	// Various read-write methods
 	tmRPCTestSetReadOnlyPanic(ctx, t, client, tablet)
@@ -1392,6 +1581,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestReloadSchemaPanic(ctx, t, client, tablet)
 	tmRPCTestPreflightSchemaPanic(ctx, t, client, tablet)
 	tmRPCTestApplySchemaPanic(ctx, t, client, tablet)
+	tmRPCTestUpdateGCTablePanic(ctx, t, client, tablet)
 	tmRPCTestExecuteFetchPanic(ctx, t, client, tablet)
 
 	// Replication related methods
@@ -1423,6 +1613,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	// Backup / restore related methods
 	tmRPCTestBackupPanic(ctx, t, client, tablet)
 	tmRPCTestRestoreFromBackupPanic(ctx, t, client, tablet, restoreFromBackupRequest)
+	tmRPCTestSetBackupRateLimitsPanic(ctx, t, client, tablet)
 
 	client.Close()
 }
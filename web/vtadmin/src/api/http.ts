@@ -840,3 +840,48 @@ export const validateVersionShard = async (params: ValidateVersionShardParams) =
 
     return vtctldata.ValidateVersionShardResponse.create(result);
 };
+
+export interface FetchUnresolvedTransactionsParams {
+    clusterID: string;
+    keyspace: string;
+    // Only return the transactions older than abandonAge seconds, when set.
+    abandonAge?: number;
+}
+
+export const fetchUnresolvedTransactions = async (params: FetchUnresolvedTransactionsParams) => {
+    const req = new URLSearchParams();
+
+    // Do not append `abandon_age` if undefined in order to fall back to server default
+    if (typeof params.abandonAge === 'number') {
+        req.append('abandon_age', params.abandonAge.toString());
+    }
+
+    const { result } = await vtfetch(`/api/keyspace/${params.clusterID}/${params.keyspace}/transactions?${req}`);
+
+    const err = vtctldata.ListUnresolvedTransactionsResponse.verify(result);
+    if (err) throw Error(err);
+
+    return vtctldata.ListUnresolvedTransactionsResponse.create(result);
+};
+
+export interface ConcludeTransactionParams {
+    clusterID: string;
+    dtid: string;
+    // One of "resolve" (the default), "commit" or "rollback".
+    action?: string;
+}
+
+export const concludeTransaction = async (params: ConcludeTransactionParams) => {
+    const { result } = await vtfetch(
+        `/api/transaction/${params.clusterID}/${encodeURIComponent(params.dtid)}/conclude`,
+        {
+            method: 'put',
+            body: JSON.stringify({ action: params.action }),
+        }
+    );
+
+    const err = vtctldata.ConcludeTransactionResponse.verify(result);
+    if (err) throw Error(err);
+
+    return vtctldata.ConcludeTransactionResponse.create(result);
+};
@@ -31,6 +31,7 @@ import { TabContainer } from '../../tabs/TabContainer';
 import { Advanced } from './Advanced';
 import style from './Keyspace.module.scss';
 import { KeyspaceShards } from './KeyspaceShards';
+import { KeyspaceTransactions } from './KeyspaceTransactions';
 import { KeyspaceVSchema } from './KeyspaceVSchema';
 
 interface RouteParams {
@@ -93,6 +94,7 @@ export const Keyspace = () => {
                 <TabContainer>
                     <Tab text="Shards" to={`${url}/shards`} />
                     <Tab text="VSchema" to={`${url}/vschema`} />
+                    <Tab text="Transactions" to={`${url}/transactions`} />
                     <Tab text="JSON" to={`${url}/json`} />
 
                     <ReadOnlyGate>
@@ -109,6 +111,10 @@ export const Keyspace = () => {
                         <KeyspaceVSchema clusterID={clusterID} name={name} />
                     </Route>
 
+                    <Route path={`${path}/transactions`}>
+                        <KeyspaceTransactions clusterID={clusterID} name={name} />
+                    </Route>
+
                     <Route path={`${path}/json`}>
                         <QueryLoadingPlaceholder query={kq} />
                         <Code code={JSON.stringify(keyspace, null, 2)} />
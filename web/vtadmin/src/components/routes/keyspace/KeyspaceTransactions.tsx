@@ -0,0 +1,107 @@
+/**
+ * Copyright 2023 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+import React, { useMemo } from 'react';
+import { orderBy } from 'lodash';
+
+import { query } from '../../../proto/vtadmin';
+import { useConcludeTransaction, useUnresolvedTransactions } from '../../../hooks/api';
+import { isReadOnlyMode } from '../../../util/env';
+import { formatDateTime } from '../../../util/time';
+import { DataTable } from '../../dataTable/DataTable';
+import { DataCell } from '../../dataTable/DataCell';
+import { QueryLoadingPlaceholder } from '../../placeholders/QueryLoadingPlaceholder';
+import { success, warn } from '../../Snackbar';
+
+interface Props {
+    clusterID: string;
+    name: string;
+}
+
+const COLUMNS = ['DTID', 'State', 'Created', 'Participants'];
+
+// Actions that conclude a transaction, as accepted by the ConcludeTransaction endpoint.
+const ACTIONS = [
+    { action: 'resolve', text: 'Resolve' },
+    { action: 'commit', text: 'Commit' },
+    { action: 'rollback', text: 'Rollback' },
+];
+
+export const KeyspaceTransactions = ({ clusterID, name }: Props) => {
+    const tq = useUnresolvedTransactions({ clusterID, keyspace: name });
+
+    const concludeMutation = useConcludeTransaction({
+        onError: (error, { dtid }) => warn(`There was an error concluding transaction ${dtid}: ${error}`),
+        onSuccess: (_, { dtid, action }) => {
+            success(`Successfully concluded transaction ${dtid} (${action}).`, { autoClose: 1600 });
+            tq.refetch();
+        },
+    });
+
+    const data = useMemo(() => {
+        const transactions = (tq.data?.transactions || []).map((t) => ({
+            dtid: t.dtid || '',
+            state: query.TransactionState[t.state || query.TransactionState.UNKNOWN],
+            // time_created is in nanoseconds since the epoch.
+            created: formatDateTime(Math.floor(Number(t.time_created || 0) / 1e9)),
+            participants: (t.participants || []).map((p) => `${p.keyspace}/${p.shard}`),
+        }));
+
+        return orderBy(transactions, ['created', 'dtid']);
+    }, [tq.data]);
+
+    const readOnly = isReadOnlyMode();
+    const columns = readOnly ? COLUMNS : [...COLUMNS, 'Actions'];
+
+    const renderRows = React.useCallback(
+        (rows: typeof data) => {
+            return rows.map((row) => (
+                <tr key={row.dtid}>
+                    <DataCell>{row.dtid}</DataCell>
+                    <DataCell>{row.state}</DataCell>
+                    <DataCell>{row.created}</DataCell>
+                    <DataCell>
+                        {row.participants.map((p) => (
+                            <div key={p}>{p}</div>
+                        ))}
+                    </DataCell>
+                    {!readOnly && (
+                        <DataCell>
+                            {ACTIONS.map(({ action, text }) => (
+                                <button
+                                    key={action}
+                                    className="btn btn-secondary btn-sm mr-2"
+                                    disabled={concludeMutation.isLoading}
+                                    onClick={() => concludeMutation.mutate({ clusterID, dtid: row.dtid, action })}
+                                    type="button"
+                                >
+                                    {text}
+                                </button>
+                            ))}
+                        </DataCell>
+                    )}
+                </tr>
+            ));
+        },
+        [clusterID, concludeMutation, readOnly]
+    );
+
+    return (
+        <div>
+            <QueryLoadingPlaceholder query={tq} />
+            {!tq.isLoading && !tq.error && <DataTable columns={columns} data={data} renderRows={renderRows} />}
+        </div>
+    );
+};
@@ -78,6 +78,10 @@ import {
     GetFullStatusParams,
     validateVersionShard,
     ValidateVersionShardParams,
+    fetchUnresolvedTransactions,
+    FetchUnresolvedTransactionsParams,
+    concludeTransaction,
+    ConcludeTransactionParams,
 } from '../api/http';
 import { vtadmin as pb, vtctldata } from '../proto/vtadmin';
 import { formatAlias } from '../util/tablets';
@@ -619,3 +623,25 @@ export const useValidateVersionShard = (
         return validateVersionShard(params);
     }, options);
 };
+
+/**
+ * useUnresolvedTransactions is a query hook that fetches the unresolved distributed
+ * transactions of a keyspace.
+ */
+export const useUnresolvedTransactions = (
+    params: FetchUnresolvedTransactionsParams,
+    options?: UseQueryOptions<vtctldata.ListUnresolvedTransactionsResponse, Error> | undefined
+) => useQuery(['unresolved_transactions', params], () => fetchUnresolvedTransactions(params), options);
+
+/**
+ * useConcludeTransaction is a mutate hook that resolves, commits or rolls back
+ * an unresolved distributed transaction.
+ */
+export const useConcludeTransaction = (
+    options?: UseMutationOptions<Awaited<ReturnType<typeof concludeTransaction>>, Error, ConcludeTransactionParams>
+) => {
+    return useMutation<Awaited<ReturnType<typeof concludeTransaction>>, Error, ConcludeTransactionParams>(
+        (params) => concludeTransaction(params),
+        options
+    );
+};